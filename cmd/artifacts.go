@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// The name of the manifest listing the artifacts generated in the output directory
+const manifestFileName = "manifest.json"
+
+// The directory where the generated artifacts are written, if any
+var fOutDir *string
+
+// artifact describes a file generated in the output directory
+type artifact struct {
+	// Path of the file, relative to the output directory
+	Path string `json:"path"`
+	// Kind of the file, e.g. "report-down" or "badge"
+	Type string `json:"type"`
+	// Command which generated the file, e.g. "report down"
+	Command string `json:"command"`
+	// Requirement filters applied to the content, by flag name
+	Filters map[string]string `json:"filters,omitempty"`
+	// Exported graphs or documents the file was generated from
+	Inputs []string `json:"inputs,omitempty"`
+	SHA256 string   `json:"sha256"`
+}
+
+// artifactManifest is the content of the manifest file of the output directory
+type artifactManifest struct {
+	Artifacts []artifact `json:"artifacts"`
+}
+
+// artifactFile is a file being generated, which is recorded in the manifest of the output directory when closed
+type artifactFile struct {
+	*os.File
+	artifact artifact
+}
+
+// outputPath returns where a generated file is written: relative paths are resolved against the output directory
+// when there is one.
+// @llr REQ-TRAQ-SWL-128
+func outputPath(path string) string {
+	if *fOutDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(*fOutDir, path)
+}
+
+// createArtifact creates the given file, in the output directory if there is one, for an artifact of the given type
+// generated by the command from the inputs, with the requirement filters applied.
+// @llr REQ-TRAQ-SWL-128
+func createArtifact(path, kind, command string, inputs []string, filters map[string]string) (*artifactFile, error) {
+	path = outputPath(path)
+	if *fOutDir != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactFile{File: f, artifact: artifact{Type: kind, Command: command, Inputs: inputs, Filters: filters}}, nil
+}
+
+// Close closes the file and records it in the manifest of the output directory, if there is one
+// @llr REQ-TRAQ-SWL-128
+func (f *artifactFile) Close() error {
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	if *fOutDir == "" {
+		return nil
+	}
+	return recordArtifact(*fOutDir, f.Name(), f.artifact)
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of the content of the file
+// @llr REQ-TRAQ-SWL-128
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// recordArtifact adds the file to the manifest of the output directory, replacing the previous entry of the same
+// file. The artifacts are sorted by path, so the manifest does not depend on the order the commands ran in.
+// @llr REQ-TRAQ-SWL-128
+func recordArtifact(outDir, path string, a artifact) error {
+	relPath, err := filepath.Rel(outDir, path)
+	if err != nil {
+		return err
+	}
+	a.Path = filepath.ToSlash(relPath)
+	if a.SHA256, err = fileChecksum(path); err != nil {
+		return err
+	}
+
+	manifestPath := filepath.Join(outDir, manifestFileName)
+	var manifest artifactManifest
+	content, err := os.ReadFile(manifestPath)
+	if err == nil {
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return errors.Wrapf(err, "invalid manifest `%s`", manifestPath)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	artifacts := []artifact{a}
+	for _, other := range manifest.Artifacts {
+		if other.Path != a.Path {
+			artifacts = append(artifacts, other)
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	manifest.Artifacts = artifacts
+
+	content, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, append(content, '\n'), 0644)
+}
+
+// graphInputs returns the inputs of the artifacts generated from the requirements graph: the exported graphs it
+// was loaded from, or the documents of the configured repositories
+// @llr REQ-TRAQ-SWL-128
+func graphInputs(args []string) []string {
+	if len(args) > 0 {
+		return args
+	}
+	inputs := []string{}
+	if reqtraqConfig == nil {
+		return inputs
+	}
+	for repoName, repo := range reqtraqConfig.Repos {
+		for _, doc := range repo.Documents {
+			inputs = append(inputs, string(repoName)+":"+doc.Path)
+		}
+	}
+	sort.Strings(inputs)
+	return inputs
+}
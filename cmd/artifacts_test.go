@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-128
+func TestCreateArtifact_Manifest(t *testing.T) {
+	outDir := t.TempDir()
+	*fOutDir = outDir
+	defer func() { *fOutDir = "" }()
+
+	writeArtifact := func(path, content string, filters map[string]string) {
+		of, err := createArtifact(path, "report-down", "report down", []string{"graph.json"}, filters)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = of.WriteString(content)
+		assert.NoError(t, err)
+		assert.NoError(t, of.Close())
+	}
+	writeArtifact("./reports/req-down.html", "old", nil)
+	writeArtifact("./req-down-filtered.html", "filtered", map[string]string{"id": "SWL"})
+	writeArtifact("./reports/req-down.html", "new", nil)
+
+	content, err := os.ReadFile(filepath.Join(outDir, "reports", "req-down.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	content, err = os.ReadFile(filepath.Join(outDir, manifestFileName))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var manifest artifactManifest
+	assert.NoError(t, json.Unmarshal(content, &manifest))
+	assert.Equal(t, []artifact{
+		{
+			Path:    "reports/req-down.html",
+			Type:    "report-down",
+			Command: "report down",
+			Inputs:  []string{"graph.json"},
+			SHA256:  "11507a0e2f5e69d5dfa40a62a1bd7b6ee57e6bcd85c67c9b8431b36fff21c437",
+		},
+		{
+			Path:    "req-down-filtered.html",
+			Type:    "report-down",
+			Command: "report down",
+			Filters: map[string]string{"id": "SWL"},
+			Inputs:  []string{"graph.json"},
+			SHA256:  "13a30363eb940c6c473c642531153b12d80078449bee3a8648db0575fb7de52d",
+		},
+	}, manifest.Artifacts)
+}
+
+// @llr REQ-TRAQ-SWL-128
+func TestOutputPath(t *testing.T) {
+	assert.Equal(t, "./req-up.html", outputPath("./req-up.html"))
+
+	*fOutDir = "out"
+	defer func() { *fOutDir = "" }()
+	assert.Equal(t, filepath.Join("out", "req-up.html"), outputPath("./req-up.html"))
+	assert.Equal(t, "/tmp/req-up.html", outputPath("/tmp/req-up.html"))
+}
+
+// @llr REQ-TRAQ-SWL-128
+func TestCreateArtifact_Exports(t *testing.T) {
+	outDir := t.TempDir()
+	*fOutDir = outDir
+	defer func() { *fOutDir = "" }()
+	previousConfig := reqtraqConfig
+	reqtraqConfig = nil
+	defer func() { reqtraqConfig = previousConfig }()
+
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Document: &sdd, RepoName: "repo",
+			Attributes: map[string]string{reqs.ForeignIDAttribute: "CUST-7"}},
+	}}
+	assert.NoError(t, exportReqsGraph(rg, "graphs/repo.json", true, "export", []string{"repo:TEST-138-SDD.md"}, nil))
+	assert.NoError(t, exportForeignIDs(rg, "graphs"))
+	assert.NoError(t, createIssuesReport([]diagnostics.Issue{{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 3,
+		Description: "Invalid", Severity: diagnostics.IssueSeverityMajor}}, "issues.json", []string{"graph.json"}))
+
+	content, err := os.ReadFile(filepath.Join(outDir, manifestFileName))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var manifest artifactManifest
+	assert.NoError(t, json.Unmarshal(content, &manifest))
+	var written []artifact
+	for _, a := range manifest.Artifacts {
+		_, err := os.Stat(filepath.Join(outDir, a.Path))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, a.SHA256)
+		a.SHA256 = ""
+		written = append(written, a)
+	}
+	assert.Equal(t, []artifact{
+		{Path: "graphs/foreign-ids.csv", Type: "foreign-ids", Command: "export"},
+		{Path: "graphs/repo.json", Type: "raw-graph", Command: "export", Inputs: []string{"repo:TEST-138-SDD.md"}},
+		{Path: "issues.json", Type: "issues", Command: "validate", Inputs: []string{"graph.json"}},
+	}, written)
+}
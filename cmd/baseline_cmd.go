@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	fBaselineDir   *string
+	fBaselineForce *bool
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Records the requirements of a release and compares the requirements against them",
+	Long: `Records the requirements of the graph, with their attributes and links, as a named baseline, e.g. for each
+release, and lists the changes of the requirements since a baseline for the change control of the next release. The
+baselines are versioned JSON files stored in the directory given with --dir.`,
+}
+
+var baselineCreateCmd = &cobra.Command{
+	Use:   "create NAME [GRAPH_JSON ...]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Records the requirements as a baseline",
+	Long: `Records the requirements of the current repository, or of the given exported graphs, as the baseline NAME.json
+in the baselines directory. An existing baseline is only replaced with --force.`,
+	RunE: RunAndHandleError(runBaselineCreate),
+}
+
+var baselineCompareCmd = &cobra.Command{
+	Use:   "compare NAME [GRAPH_JSON ...]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Lists the changes of the requirements since a baseline",
+	Long: `Lists the requirements added, modified and deleted since the baseline NAME, the changes of the attributes of the
+modified requirements and the links added and removed, for the requirements of the current repository or of the
+given exported graphs.`,
+	RunE: RunAndHandleError(runBaselineCompare),
+}
+
+// baselineNameRegexp matches the names of the baselines, which are used as file names
+var baselineNameRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Registers the baseline command and its subcommands
+// @llr REQ-TRAQ-SWL-193
+func init() {
+	fBaselineDir = baselineCmd.PersistentFlags().String("dir", "baselines", "Directory where the baselines are stored.")
+	fBaselineForce = baselineCreateCmd.Flags().Bool("force", false, "Replace the baseline if it exists.")
+	baselineCmd.AddCommand(baselineCreateCmd)
+	baselineCmd.AddCommand(baselineCompareCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+// baselinePath returns the path of the file of the baseline with the given name
+// @llr REQ-TRAQ-SWL-193
+func baselinePath(name string) (string, error) {
+	if !baselineNameRegexp.MatchString(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("Invalid baseline name `%s`, expected letters, digits, dots, dashes and underscores", name)
+	}
+	return filepath.Join(*fBaselineDir, name+".json"), nil
+}
+
+// runBaselineCreate records the requirements of the graph as a baseline, along with the revisions of the repositories
+// @llr REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-193
+func runBaselineCreate(command *cobra.Command, args []string) error {
+	path, err := baselinePath(args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(outputPath(path)); err == nil && !*fBaselineForce {
+		return fmt.Errorf("Baseline `%s` exists in `%s`, use --force to replace it", args[0], outputPath(path))
+	}
+
+	rg, err := loadReqGraph(args[1:])
+	if err != nil {
+		return err
+	}
+	created, err := exportTime()
+	if err != nil {
+		return err
+	}
+	revisions := make(map[repos.RepoName]string)
+	if rg.ReqtraqConfig != nil {
+		for repoName := range rg.ReqtraqConfig.Repos {
+			revision, err := repos.Revision(repoName)
+			if err != nil && !errors.Is(err, repos.ErrNoGit) {
+				return err
+			}
+			revisions[repoName] = revision
+		}
+	}
+	baseline := reqs.NewBaseline(rg, args[0], created.Format(time.RFC3339), revisions)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath(path)), 0755); err != nil {
+		return err
+	}
+	f, err := createArtifact(path, "baseline", "baseline create", graphInputs(args[1:]), nil)
+	if err != nil {
+		return err
+	}
+	if err := reqs.WriteBaseline(f, baseline); err != nil {
+		f.File.Close()
+		return errors.Wrapf(err, "write baseline `%s`", f.Name())
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d requirements in baseline `%s`\n", len(baseline.Requirements), f.Name())
+	return nil
+}
+
+// runBaselineCompare prints the changes of the requirements of the graph since a baseline
+// @llr REQ-TRAQ-SWL-193
+func runBaselineCompare(command *cobra.Command, args []string) error {
+	path, err := baselinePath(args[0])
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "open baseline `%s`", args[0])
+	}
+	baseline, err := reqs.ReadBaseline(f)
+	f.Close()
+	if err != nil {
+		return errors.Wrapf(err, "read `%s`", path)
+	}
+
+	rg, err := loadReqGraph(args[1:])
+	if err != nil {
+		return err
+	}
+	writeBaselineDiff(os.Stdout, baseline, baseline.Compare(rg))
+	return nil
+}
+
+// writeBaselineDiff prints the changes since the baseline: the requirements by kind of change, the attribute changes
+// under the modified requirements, then the link changes
+// @llr REQ-TRAQ-SWL-193
+func writeBaselineDiff(w io.Writer, baseline *reqs.Baseline, diff reqs.BaselineDiff) {
+	fmt.Fprintf(w, "Changes since baseline `%s` created %s:\n", baseline.Name, baseline.Created)
+	if len(diff.Changes) == 0 && len(diff.AddedLinks) == 0 && len(diff.RemovedLinks) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	for _, kind := range []reqs.ChangeKind{reqs.ChangeAdded, reqs.ChangeModified, reqs.ChangeDeleted} {
+		for _, change := range diff.Changes {
+			if change.Kind != kind {
+				continue
+			}
+			fmt.Fprintf(w, "%s %s\n", change.Kind, change.ID)
+			for _, attribute := range diff.AttributeChanges {
+				if attribute.ID == change.ID {
+					fmt.Fprintf(w, "  %s: %q -> %q\n", attribute.Attribute, attribute.Old, attribute.New)
+				}
+			}
+		}
+	}
+	for _, link := range diff.AddedLinks {
+		fmt.Fprintf(w, "Added link %s -> %s\n", link.Child, link.Parent)
+	}
+	for _, link := range diff.RemovedLinks {
+		fmt.Fprintf(w, "Removed link %s -> %s\n", link.Child, link.Parent)
+	}
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/code/parsers"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [JOB_FILE]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Runs several commands against a single build of the requirements graph",
+	Long: `Runs the commands read from JOB_FILE, or from the standard input, one after the other. The requirements
+graph is built once and shared by all the commands, so generating several reports, matrices and exports only
+pays the cost of parsing the documents and the code once.
+
+Commands are given one per line, without the leading "reqtraq", e.g. "report down --pfx out/req-". Empty lines
+and lines starting with # are ignored. Alternatively, a JSON job spec can be given:
+
+    {"commands": [["report", "down", "--pfx", "out/req-"], ["matrix", "--pfx", "out/req-"]]}
+
+The batch stops at the first command which fails.`,
+	RunE: RunAndHandleError(runBatchCmd),
+}
+
+// Registers the batch command
+// @llr REQ-TRAQ-SWL-107
+func init() {
+	rootCmd.AddCommand(batchCmd)
+}
+
+// A batch job, as given in a JSON job spec
+type batchJob struct {
+	Commands [][]string `json:"commands"`
+}
+
+// The settings a requirements graph was built with, which must match for the graph to be reused
+type batchGraphKey struct {
+	repoPath           string
+	variant            string
+	directDeps         bool
+	failFast           bool
+	noGit              bool
+	allowLinkedParsers bool
+	onlyRepo           repos.RepoName
+	// The paths of the graphs the other repositories are loaded from, one per line
+	otherRepoGraphs string
+}
+
+// Whether commands are run as part of a batch, in which case the configuration and the graph are reused
+var batchMode bool = false
+
+// The configurations and graphs built while running a batch, by the settings they were built with. Only the
+// repository path, the dependencies setting and whether git is used apply to configurations.
+var (
+	batchConfigs = map[batchGraphKey]*config.Config{}
+	batchGraphs  = map[batchGraphKey]*reqs.ReqGraph{}
+)
+
+// The repository the commands of the batch run in, once loaded by the first of them
+var batchRepoPath string
+
+// batchRepositoryLoaded returns whether the base repository was already loaded by an earlier command of the batch,
+// and otherwise records that the current command loads it. The base repository can only be loaded once, so all the
+// commands of a batch must use the same one.
+// @llr REQ-TRAQ-SWL-107
+func batchRepositoryLoaded() (bool, error) {
+	if !batchMode {
+		return false, nil
+	}
+	if batchRepoPath == "" {
+		batchRepoPath = *fRepoPath
+		return false, nil
+	}
+	if *fRepoPath != batchRepoPath {
+		return false, fmt.Errorf("all the commands of a batch must use the same repository, got %q after %q",
+			*fRepoPath, batchRepoPath)
+	}
+	return true, nil
+}
+
+// cachedConfiguration returns the configuration parsed earlier in the batch with the current settings, if any
+// @llr REQ-TRAQ-SWL-107
+func cachedConfiguration() *config.Config {
+	if !batchMode {
+		return nil
+	}
+	return batchConfigs[currentBatchConfigKey()]
+}
+
+// cacheConfiguration keeps the given configuration to be reused by the following commands of the batch
+// @llr REQ-TRAQ-SWL-107
+func cacheConfiguration(cfg *config.Config) {
+	if batchMode {
+		batchConfigs[currentBatchConfigKey()] = cfg
+	}
+}
+
+// currentBatchConfigKey returns the settings the configuration would be parsed with by the current command
+// @llr REQ-TRAQ-SWL-107
+func currentBatchConfigKey() batchGraphKey {
+	return batchGraphKey{repoPath: *fRepoPath, directDeps: config.DirectDependenciesOnly, noGit: repos.NoGit}
+}
+
+// cachedReqGraph returns a deep copy of the graph built earlier in the batch with the current settings, if any, so
+// commands modifying their graph don't affect the following ones
+// @llr REQ-TRAQ-SWL-107
+func cachedReqGraph() *reqs.ReqGraph {
+	if !batchMode {
+		return nil
+	}
+	rg, ok := batchGraphs[currentBatchGraphKey()]
+	if !ok {
+		return nil
+	}
+	return rg.Clone()
+}
+
+// cacheReqGraph keeps the given graph to be reused by the following commands of the batch
+// @llr REQ-TRAQ-SWL-107
+func cacheReqGraph(rg *reqs.ReqGraph) {
+	if batchMode {
+		batchGraphs[currentBatchGraphKey()] = rg
+	}
+}
+
+// currentBatchGraphKey returns the settings the graph would be built with by the current command
+// @llr REQ-TRAQ-SWL-107
+func currentBatchGraphKey() batchGraphKey {
+	return batchGraphKey{
+		repoPath:           *fRepoPath,
+		variant:            reqs.Variant,
+		directDeps:         config.DirectDependenciesOnly,
+		failFast:           reqs.FailFast,
+		noGit:              repos.NoGit,
+		allowLinkedParsers: parsers.AllowLinkedParsers,
+		onlyRepo:           reqs.OnlyRepo,
+		otherRepoGraphs:    strings.Join(reqs.OtherRepoGraphs, "\n"),
+	}
+}
+
+// splitCommandLine splits a line of a batch into arguments. Arguments are separated by whitespace, which can be
+// part of an argument when quoted with single or double quotes or escaped with a backslash.
+// @llr REQ-TRAQ-SWL-107
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", line)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// readBatchCommands reads the commands of a batch, either a JSON job spec or one command per line
+// @llr REQ-TRAQ-SWL-107
+func readBatchCommands(r io.Reader) ([][]string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		var job batchJob
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&job); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON job spec")
+		}
+		return job.Commands, nil
+	}
+
+	var commands [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lno := 1; scanner.Scan(); lno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := splitCommandLine(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lno)
+		}
+		commands = append(commands, args)
+	}
+	return commands, scanner.Err()
+}
+
+// resetFlags sets all the flags of the given command and its subcommands back to their default value, so the
+// flags given to a command of the batch don't leak into the following ones
+// @llr REQ-TRAQ-SWL-107
+func resetFlags(command *cobra.Command) error {
+	var err error
+	reset := func(flag *pflag.Flag) {
+		if !flag.Changed || err != nil {
+			return
+		}
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			err = sliceValue.Replace(nil)
+		} else {
+			err = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	command.Flags().VisitAll(reset)
+	command.PersistentFlags().VisitAll(reset)
+	for _, subcommand := range command.Commands() {
+		if err := resetFlags(subcommand); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// runBatchCmd runs the commands of the batch one after the other, sharing the requirements graph
+// @llr REQ-TRAQ-SWL-107
+func runBatchCmd(command *cobra.Command, args []string) error {
+	input := io.Reader(os.Stdin)
+	if len(args) == 1 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	commands, err := readBatchCommands(input)
+	if err != nil {
+		return errors.Wrap(err, "read batch commands")
+	}
+	for _, commandArgs := range commands {
+		if len(commandArgs) > 0 && commandArgs[0] == command.Name() {
+			return fmt.Errorf("batches cannot be nested")
+		}
+	}
+
+	// The flags given to the batch itself, e.g. --repo, apply to all its commands
+	var batchArgs []string
+	command.Flags().Visit(func(flag *pflag.Flag) {
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range sliceValue.GetSlice() {
+				batchArgs = append(batchArgs, "--"+flag.Name+"="+value)
+			}
+		} else {
+			batchArgs = append(batchArgs, "--"+flag.Name+"="+flag.Value.String())
+		}
+	})
+
+	batchMode = true
+	defer func() {
+		batchMode = false
+		batchConfigs = map[batchGraphKey]*config.Config{}
+		batchGraphs = map[batchGraphKey]*reqs.ReqGraph{}
+	}()
+	for _, commandArgs := range commands {
+		if err := resetFlags(rootCmd); err != nil {
+			return err
+		}
+		log.Print("Running ", linepipes.EscapeCommand("reqtraq", commandArgs...))
+		rootCmd.SetArgs(append(commandArgs, batchArgs...))
+		if err := rootCmd.Execute(); err != nil {
+			return errors.Wrapf(err, "run `%s`", strings.Join(commandArgs, " "))
+		}
+	}
+	return nil
+}
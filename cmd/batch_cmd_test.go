@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-107
+func TestSplitCommandLine(t *testing.T) {
+	args, err := splitCommandLine(`report down  --pfx "out dir/req-" --filter-title='a b' x\ y`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"report", "down", "--pfx", "out dir/req-", "--filter-title=a b", "x y"}, args)
+
+	args, err = splitCommandLine(`validate ""`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"validate", ""}, args)
+
+	_, err = splitCommandLine(`report down --pfx "out`)
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-107
+func TestReadBatchCommands(t *testing.T) {
+	commands, err := readBatchCommands(strings.NewReader("# Reports\nreport down --pfx out/\n\n  validate\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"report", "down", "--pfx", "out/"}, {"validate"}}, commands)
+
+	commands, err = readBatchCommands(strings.NewReader(`
+		{"commands": [["report", "down", "--pfx", "out dir/"], ["validate"]]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"report", "down", "--pfx", "out dir/"}, {"validate"}}, commands)
+
+	_, err = readBatchCommands(strings.NewReader(`{"cmds": [["validate"]]}`))
+	assert.Error(t, err)
+
+	_, err = readBatchCommands(strings.NewReader("validate\nreport down --pfx 'out\n"))
+	assert.EqualError(t, err, `line 2: unterminated quote or escape in "report down --pfx 'out"`)
+}
+
+// @llr REQ-TRAQ-SWL-107
+func TestResetFlags(t *testing.T) {
+	pfx := reportCmd.PersistentFlags().Lookup("pfx")
+	assert.NoError(t, reportCmd.PersistentFlags().Set("pfx", "out/"))
+	assert.Equal(t, "out/", pfx.Value.String())
+	assert.NoError(t, reportCmd.PersistentFlags().Set("attribute", "SAFETY IMPACT=.*"))
+	assert.Equal(t, []string{"SAFETY IMPACT=.*"}, *reportAttributeFilter)
+
+	assert.NoError(t, resetFlags(rootCmd))
+	assert.Equal(t, pfx.DefValue, pfx.Value.String())
+	assert.False(t, pfx.Changed)
+	assert.Empty(t, *reportAttributeFilter)
+}
+
+// @llr REQ-TRAQ-SWL-107
+func TestCurrentBatchGraphKey(t *testing.T) {
+	assert.NoError(t, resetFlags(rootCmd))
+	defaultKey := currentBatchGraphKey()
+	defaultConfigKey := currentBatchConfigKey()
+
+	// The graphs built with different settings are not reused
+	for _, flag := range [][2]string{{"only-repo", "projectA"}, {"other-graphs", "a.json,b.json"},
+		{"allow-linked-parsers", "true"}, {"no-git", "true"}} {
+		assert.NoError(t, rootCmd.PersistentFlags().Set(flag[0], flag[1]))
+		assert.NotEqual(t, defaultKey, currentBatchGraphKey(), flag[0])
+		assert.NoError(t, resetFlags(rootCmd))
+		assert.Equal(t, defaultKey, currentBatchGraphKey(), flag[0])
+	}
+	assert.NoError(t, rootCmd.PersistentFlags().Set("other-graphs", "a.json"))
+	otherGraphKey := currentBatchGraphKey()
+	assert.NoError(t, rootCmd.PersistentFlags().Set("other-graphs", "b.json"))
+	assert.NotEqual(t, otherGraphKey, currentBatchGraphKey())
+	assert.NoError(t, resetFlags(rootCmd))
+
+	// The configurations depend on whether git is used
+	assert.NoError(t, rootCmd.PersistentFlags().Set("no-git", "true"))
+	assert.NotEqual(t, defaultConfigKey, currentBatchConfigKey())
+	assert.NoError(t, resetFlags(rootCmd))
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	changelogSince      *string
+	changelogAttributes *[]string
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog CERTDOC_PATH",
+	Short: "Generates the revision history of the requirements of a document from git",
+	Long: `Generates the revision history of the requirements of a document from its git history, as a markdown
+table listing the requirements added, modified and deleted by each commit together with its date and author.
+The table can be pasted in the revision history section of the document. With --attributes, the changes of the
+values of the given attributes are listed instead, e.g. to find out when and by whom a requirement was downgraded.`,
+	Args:              cobra.ExactValidArgs(1),
+	ValidArgsFunction: completeCertdocFilename,
+	RunE:              RunAndHandleError(runChangelog),
+}
+
+// Registers the changelog command
+// @llr REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-137
+func init() {
+	changelogSince = changelogCmd.Flags().String("since", "", "Git reference, e.g. the tag of the last release, after which the changes are listed. Defaults to the whole history.")
+	changelogAttributes = changelogCmd.Flags().StringSlice("attributes", nil, "Attributes whose changes over the whole history are listed, e.g. `STATUS,SAFETY IMPACT`.")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+// runChangelog prints the changes of the requirements of a single document since the given git reference, or the
+// changes of the values of the given attributes
+// @llr REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-137
+func runChangelog(command *cobra.Command, args []string) error {
+	if err := setupConfiguration(); err != nil {
+		return err
+	}
+
+	filename := args[0]
+
+	var repoName repos.RepoName
+	var certdocConfig *config.Document
+	if repoName, certdocConfig = reqtraqConfig.FindCertdoc(filename); certdocConfig == nil {
+		return fmt.Errorf("Could not find document `%s` in the list of documents", filename)
+	}
+
+	if len(*changelogAttributes) > 0 {
+		if *changelogSince != "" {
+			return fmt.Errorf("--since cannot be combined with --attributes")
+		}
+		history, err := reqs.AttributeHistory(repoName, certdocConfig, *changelogAttributes)
+		if err != nil {
+			return err
+		}
+		return reqs.WriteAttributeHistory(os.Stdout, history)
+	}
+
+	entries, err := reqs.Changelog(repoName, certdocConfig, *changelogSince)
+	if err != nil {
+		return err
+	}
+	return reqs.WriteChangelog(os.Stdout, entries)
+}
+
+// loadAttributeHistory sets the history of the given attributes on the requirements of the graph, if any
+// @llr REQ-TRAQ-SWL-137
+func loadAttributeHistory(rg *reqs.ReqGraph, attributes []string) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return errors.Wrap(rg.LoadAttributeHistory(attributes), "load attribute history")
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Args:  cobra.NoArgs,
+	Short: "Removes the cached clones of the repositories",
+	Long: `Removes the clones of the other repositories which are cached across runs, in the directory given in the
+REQTRAQ_CACHE_DIR environment variable or in .reqtraq-cache in the temporary directory. The clones used by a running
+reqtraq are skipped.`,
+	RunE: RunAndHandleError(runCleanCmd),
+}
+
+// Registers the clean command
+// @llr REQ-TRAQ-SWL-149
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// runCleanCmd removes the cached clones which are not in use
+// @llr REQ-TRAQ-SWL-149
+func runCleanCmd(command *cobra.Command, args []string) error {
+	removed, inUse, err := repos.CleanCache()
+	for _, dir := range removed {
+		fmt.Printf("Removed %s\n", dir)
+	}
+	for _, dir := range inUse {
+		fmt.Printf("Skipped %s, used by a running reqtraq\n", dir)
+	}
+	return err
+}
@@ -6,11 +6,17 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"runtime/pprof"
 	"strings"
+	"time"
 
 	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/code/parsers"
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/profile"
+	"github.com/daedaleanai/reqtraq/report"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/daedaleanai/reqtraq/util"
@@ -20,37 +26,66 @@ import (
 // The base repo path specified in the command line.
 var fRepoPath *string
 
+// The file to write the CPU profile to, if any.
+var fProfileCPU *string
+
+// The redactions selecting the requirements whose text is replaced in the outputs, written as ATTRIBUTE=REGEXP.
+var fRedact *[]string
+
 var rootCmd = &cobra.Command{
 	Use:   "reqtraq",
 	Short: "Reqtraq is a requirements tracer.",
 	Long: `Reqtraq operates on certification documents and source code in a directory tree,
 usually in a git repo.  The certification documents are scanned for requirements,
 and the source code for references to them.`,
-	Version: fmt.Sprintf("%d.%d.%d", util.Version.Major, util.Version.Minor, util.Version.Revision),
+	Version: util.Version.String(),
 }
 var reqtraqConfig *config.Config
 
 // Sets up the global reqtraqConfig variable and registers the base repository
-// @llr REQ-TRAQ-SWL-60
+// @llr REQ-TRAQ-SWL-60, REQ-TRAQ-SWL-107, REQ-TRAQ-SWL-117, REQ-TRAQ-SWL-131, REQ-TRAQ-SWL-161
 func setupConfiguration() error {
-	config.LoadBaseRepoInfo(*fRepoPath)
+	loaded, err := batchRepositoryLoaded()
+	if err != nil {
+		return err
+	}
+	if !loaded {
+		config.LoadBaseRepoInfo(*fRepoPath)
+	}
 
 	// Register BaseRepository so that it is always accessible afterwards
 	baseRepoPath := repos.BaseRepoPath()
 	repos.RegisterRepository(repos.BaseRepoName(), baseRepoPath)
 
+	if cfg := cachedConfiguration(); cfg != nil {
+		reqtraqConfig = cfg
+		return nil
+	}
+
 	cfg, err := config.ParseConfig(baseRepoPath)
 	if err != nil {
 		return errors.Wrap(err, "Error parsing `reqtraq_config.json` file in current repo")
 	}
 
+	if err := parsers.RegisterExternal(cfg.CodeParsers, repos.BaseRepoName()); err != nil {
+		return err
+	}
+	if err := reqs.LoadRulePlugins(cfg.RulePlugins); err != nil {
+		return err
+	}
+	if err := report.LoadRequirementTemplates(cfg.RequirementTemplates); err != nil {
+		return err
+	}
+
 	reqtraqConfig = &cfg
+	cacheConfiguration(reqtraqConfig)
 	return nil
 }
 
 // loadReqGraph loads the requirements graph from the current repository or
-// from the specified paths of previously exported requirement graphs.
-// @llr REQ-TRAQ-SWL-1, REQ-TRAQ-SWL-80
+// from the specified paths of previously exported requirement graphs, with the text of the requirements selected by
+// --redact replaced.
+// @llr REQ-TRAQ-SWL-1, REQ-TRAQ-SWL-80, REQ-TRAQ-SWL-107, REQ-TRAQ-SWL-177
 func loadReqGraph(graphs_paths []string) (*reqs.ReqGraph, error) {
 	var err error
 	if err = setupConfiguration(); err != nil {
@@ -59,9 +94,12 @@ func loadReqGraph(graphs_paths []string) (*reqs.ReqGraph, error) {
 
 	var rg *reqs.ReqGraph
 	if len(graphs_paths) == 0 {
-		rg, err = reqs.BuildGraph(reqtraqConfig)
-		if err != nil {
-			return nil, errors.Wrap(err, "build graph")
+		if rg = cachedReqGraph(); rg == nil {
+			rg, err = reqs.BuildGraph(reqtraqConfig)
+			if err != nil {
+				return nil, errors.Wrap(err, "build graph")
+			}
+			cacheReqGraph(rg)
 		}
 	} else {
 		rg, err = reqs.LoadGraphs(graphs_paths)
@@ -69,7 +107,39 @@ func loadReqGraph(graphs_paths []string) (*reqs.ReqGraph, error) {
 			return nil, errors.Wrap(err, "load graphs")
 		}
 	}
-	return rg, nil
+	return redactReqGraph(rg)
+}
+
+// redactReqGraph returns a copy of the graph with the text of the requirements selected by --redact replaced, or the
+// graph itself if nothing is redacted
+// @llr REQ-TRAQ-SWL-177
+func redactReqGraph(rg *reqs.ReqGraph) (*reqs.ReqGraph, error) {
+	if len(*fRedact) == 0 {
+		return rg, nil
+	}
+	redactions, err := reqs.ParseRedactions(*fRedact)
+	if err != nil {
+		return nil, err
+	}
+	return rg.Redacted(redactions), nil
+}
+
+// issuesOfRepo returns the issues of the graph found in the given repository, or all of them if no repository is
+// given. The repository must be one of the configured repositories or own some of the requirements of the graph.
+// @llr REQ-TRAQ-SWL-116
+func issuesOfRepo(rg *reqs.ReqGraph, repoName string) ([]diagnostics.Issue, error) {
+	if repoName == "" {
+		return rg.Issues, nil
+	}
+	name := repos.RepoName(repoName)
+	_, known := reqtraqConfig.Repos[name]
+	for _, r := range rg.Reqs {
+		known = known || r.RepoName == name
+	}
+	if !known {
+		return nil, fmt.Errorf("Unknown repository `%s`", repoName)
+	}
+	return reqs.IssuesOfRepo(rg.Issues, name), nil
 }
 
 // Provides completions for certdocs
@@ -93,12 +163,63 @@ func completeCertdocFilename(cmd *cobra.Command, args []string, toComplete strin
 	return possibleCompletions, cobra.ShellCompDirectiveDefault
 }
 
+// Whether the running command is profiled, so the commands run by a batch are profiled as a whole
+var profiling bool = false
+
+// startProfiling starts measuring the time spent by the command when requested, and returns the function to call
+// when the command finishes to write the profile.
+// @llr REQ-TRAQ-SWL-108
+func startProfiling() (func(), error) {
+	if profiling || (!profile.Enabled && *fProfileCPU == "") {
+		return func() {}, nil
+	}
+
+	var cpuProfile *os.File
+	if *fProfileCPU != "" {
+		var err error
+		if cpuProfile, err = os.Create(*fProfileCPU); err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(cpuProfile); err != nil {
+			cpuProfile.Close()
+			return nil, err
+		}
+	}
+
+	profiling = true
+	profile.Reset()
+	start := time.Now()
+	return func() {
+		profiling = false
+		if cpuProfile != nil {
+			pprof.StopCPUProfile()
+			cpuProfile.Close()
+			log.Print("CPU profile written to ", cpuProfile.Name())
+		}
+		if profile.Enabled {
+			if err := profile.WriteReport(os.Stderr, time.Since(start)); err != nil {
+				log.Print("Failed to write the profile: ", err)
+			}
+		}
+	}, nil
+}
+
 // Initializes the root command flags
-// @llr REQ-TRAQ-SWL-32, REQ-TRAQ-SWL-59, REQ-TRAQ-SWL-81
+// @llr REQ-TRAQ-SWL-32, REQ-TRAQ-SWL-59, REQ-TRAQ-SWL-81, REQ-TRAQ-SWL-101, REQ-TRAQ-SWL-108, REQ-TRAQ-SWL-127, REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-153, REQ-TRAQ-SWL-177, REQ-TRAQ-SWL-182
 func init() {
 	fRepoPath = rootCmd.PersistentFlags().String("repo", ".", "Where from to get the config file.")
 	rootCmd.PersistentFlags().BoolVarP(&linepipes.Verbose, "verbose", "v", false, "Enable verbose logs.")
 	rootCmd.PersistentFlags().BoolVarP(&config.DirectDependenciesOnly, "direct-deps", "d", false, "Only checks the current repository and parents")
+	rootCmd.PersistentFlags().BoolVar(&parsers.AllowLinkedParsers, "allow-linked-parsers", false, "Runs the external code parsers declared in the configurations of the parent and child repositories.")
+	rootCmd.PersistentFlags().BoolVar(&repos.NoGit, "no-git", false, "Operates on plain directories instead of git repositories, e.g. source bundles, disabling the features depending on git.")
+	rootCmd.PersistentFlags().StringVar(&reqs.Variant, "variant", "", "Applies the overrides of the given product variant to the requirements.")
+	rootCmd.PersistentFlags().BoolVar(&profile.Enabled, "profile", false, "Reports the time spent in each stage of building the requirements graph.")
+	fProfileCPU = rootCmd.PersistentFlags().String("profile-cpu", "", "Writes a pprof CPU profile of the command to the given file.")
+	fNoColor = rootCmd.PersistentFlags().Bool("no-color", false, "Disables the colors and links in the output written to a terminal.")
+	rootCmd.PersistentFlags().StringVar((*string)(&reqs.OnlyRepo), "only-repo", "", "Only parses the documents and code of the given repository, loading the other repositories from the graphs given with --other-graphs.")
+	rootCmd.PersistentFlags().StringSliceVar(&reqs.OtherRepoGraphs, "other-graphs", nil, "Previously exported graphs the repositories other than the one given with --only-repo are loaded from.")
+	fRedact = rootCmd.PersistentFlags().StringSlice("redact", nil, "Replace the text of the requirements whose attribute matches, e.g. `EXPORT CONTROL=^Restricted$`, in the outputs, keeping their IDs and links.")
+	fOutDir = rootCmd.PersistentFlags().String("out-dir", "", "Writes the generated reports, badges, matrices and exports under the given directory, listed in its manifest.json.")
 }
 
 // Runs the root command and defers the cleanup of the temporary directories
@@ -111,7 +232,7 @@ func RunRootCommand() error {
 
 // RunAndHandleError returns a RunE function that runs the specified RunE
 // function and exits if it returns an error.
-// @llr REQ-TRAQ-SWL-59
+// @llr REQ-TRAQ-SWL-59, REQ-TRAQ-SWL-108
 func RunAndHandleError(runE func(cmd *cobra.Command, args []string) error) func(*cobra.Command, []string) error {
 	// Wrap the specified runE func in a new func with the same signature.
 	return func(cmd *cobra.Command, args []string) error {
@@ -120,7 +241,14 @@ func RunAndHandleError(runE func(cmd *cobra.Command, args []string) error) func(
 		// That's why we need to handle our errors ourselves and exit with an
 		// appropriate error code.
 		// See https://github.com/spf13/cobra/issues/914
-		if errRun := runE(cmd, args); errRun != nil {
+		stopProfiling, err := startProfiling()
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "start profiling"))
+			os.Exit(1)
+		}
+		errRun := runE(cmd, args)
+		stopProfiling()
+		if errRun != nil {
 			// For example: "github.com/daedaleanai/reqtraq/cmd.runValidate"
 			s := runtime.FuncForPC(reflect.ValueOf(runE).Pointer()).Name()
 			s = s[strings.LastIndex(s, "/")+1:]
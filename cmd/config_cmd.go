@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/pkg/errors"
+)
+
+var configUpgradeRewrite rewriteFlags
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Maintains the reqtraq_config.json configuration files",
+}
+
+var configUpgradeCmd = &cobra.Command{
+	Use:   "upgrade [REPO_PATH ...]",
+	Short: "Converts the configuration files to the current format",
+	Long: fmt.Sprintf(`Rewrites the reqtraq_config.json file of the repository given with --repo, or of the given repositories, so
+the implementation of each document is a list, as giving a single object is deprecated and rejected from reqtraq
+%s. The rest of the files is kept as is. With --dry-run or --diff the files are not modified and the command fails
+if any of them would be.`, config.LegacyImplementationRemovalVersion),
+	RunE: RunAndHandleError(runConfigUpgradeCmd),
+}
+
+// Registers the config command and its subcommands
+// @llr REQ-TRAQ-SWL-174, REQ-TRAQ-SWL-122
+func init() {
+	configUpgradeRewrite = addRewriteFlags(configUpgradeCmd)
+	configCmd.AddCommand(configUpgradeCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// runConfigUpgradeCmd converts the configuration files of the given repositories, or of the current one. The
+// configuration is not loaded, so files which are rejected can be converted.
+// @llr REQ-TRAQ-SWL-174, REQ-TRAQ-SWL-122
+func runConfigUpgradeCmd(command *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{*fRepoPath}
+	}
+	changed := 0
+	for _, repoPath := range args {
+		path := filepath.Join(repoPath, "reqtraq_config.json")
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		upgraded, err := config.UpgradeConfig(content)
+		if err != nil {
+			return errors.Wrapf(err, "upgrade `%s`", path)
+		}
+		modified, err := configUpgradeRewrite.rewriteDocument(os.Stdout, path, path, string(content), string(upgraded))
+		if err != nil {
+			return err
+		}
+		if modified {
+			changed++
+			if !configUpgradeRewrite.preview() {
+				fmt.Printf("Upgraded %s\n", path)
+			}
+		}
+	}
+	return configUpgradeRewrite.checkPreview(changed)
+}
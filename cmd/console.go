@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// ANSI escape sequences used to color the terminal output
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// Whether the output must not be colored, even when it is written to a terminal
+var fNoColor *bool
+
+// console writes the output of the commands. When the output goes to a terminal, the text is colored and the
+// issues are linked to the files they were found in.
+type console struct {
+	w     io.Writer
+	color bool
+}
+
+// newConsole returns the console writing to the given file, colored unless disabled with the `--no-color` flag,
+// the NO_COLOR environment variable (see https://no-color.org) or when the file is not a terminal
+// @llr REQ-TRAQ-SWL-127
+func newConsole(f *os.File) console {
+	color := isTerminal(f) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	if fNoColor != nil && *fNoColor {
+		color = false
+	}
+	return console{w: f, color: color}
+}
+
+// isTerminal returns whether the file is a character device, such as a terminal, rather than a pipe or a file
+// @llr REQ-TRAQ-SWL-127
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// style wraps the text in the given escape sequence when the console is colored
+// @llr REQ-TRAQ-SWL-127
+func (c console) style(sequence, text string) string {
+	if !c.color || text == "" {
+		return text
+	}
+	return sequence + text + ansiReset
+}
+
+// link turns the text into a hyperlink to the given file using the OSC 8 escape sequence, which supporting
+// terminals open on click. The text is unchanged when the console is not colored or the file cannot be found.
+// @llr REQ-TRAQ-SWL-127
+func (c console) link(repoName repos.RepoName, path string, text string) string {
+	if !c.color || path == "" {
+		return text
+	}
+	filePath, err := repos.PathInRepo(repoName, path)
+	if err != nil {
+		return text
+	}
+	if filePath, err = filepath.Abs(filePath); err != nil {
+		return text
+	}
+	return fmt.Sprintf("\x1b]8;;file://%s\x1b\\%s\x1b]8;;\x1b\\", filepath.ToSlash(filePath), text)
+}
+
+// severityColor returns the escape sequence coloring the issues of the given severity
+// @llr REQ-TRAQ-SWL-127
+func severityColor(severity diagnostics.IssueSeverity) string {
+	switch severity {
+	case diagnostics.IssueSeverityMajor:
+		return ansiRed
+	case diagnostics.IssueSeverityMinor:
+		return ansiYellow
+	}
+	return ansiCyan
+}
+
+// printIssue prints the description of the issue colored by severity and linked to the file it was found in
+// @llr REQ-TRAQ-SWL-127
+func (c console) printIssue(prefix string, issue diagnostics.Issue) {
+	text := c.link(issue.RepoName, issue.Path, c.style(severityColor(issue.Severity), issue.Description))
+	fmt.Fprintln(c.w, prefix+text)
+}
+
+// printColumns prints the rows with the cells of each column, except the last one, padded to the same width.
+// The styles are applied to the cells of the corresponding columns after padding, so they don't count in the width.
+// @llr REQ-TRAQ-SWL-127
+func (c console) printColumns(indent string, rows [][]string, styles []string) {
+	widths := []int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := len([]rune(cell)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(row)-1 {
+				cell += strings.Repeat(" ", widths[i]-len([]rune(cell)))
+			}
+			if i < len(styles) {
+				cell = c.style(styles[i], cell)
+			}
+			cells[i] = cell
+		}
+		fmt.Fprintln(c.w, indent+strings.Join(cells, "  "))
+	}
+}
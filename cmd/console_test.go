@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-127
+func TestConsole_NotTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	out := newConsole(f)
+	assert.False(t, out.color)
+	assert.Equal(t, "text", out.style(ansiRed, "text"))
+	assert.Equal(t, "text", out.link(repos.BaseRepoName(), "README.md", "text"))
+}
+
+// @llr REQ-TRAQ-SWL-127
+func TestConsole_NoColor(t *testing.T) {
+	noColor := true
+	fNoColor = &noColor
+	defer func() { fNoColor = nil }()
+	assert.False(t, newConsole(os.Stdout).color)
+
+	fNoColor = nil
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, newConsole(os.Stdout).color)
+}
+
+// @llr REQ-TRAQ-SWL-127
+func TestConsole_Colored(t *testing.T) {
+	repos.RegisterRepository(repos.BaseRepoName(), repos.BaseRepoPath())
+	var buf bytes.Buffer
+	out := console{w: &buf, color: true}
+
+	out.printIssue("  ", diagnostics.Issue{RepoName: repos.BaseRepoName(), Path: "README.md", Description: "Broken", Severity: diagnostics.IssueSeverityMajor})
+	absPath, err := filepath.Abs(filepath.Join(string(repos.BaseRepoPath()), "README.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "  \x1b]8;;file://"+filepath.ToSlash(absPath)+"\x1b\\\x1b[31mBroken\x1b[0m\x1b]8;;\x1b\\\n", buf.String())
+
+	// Files which cannot be found are not linked
+	buf.Reset()
+	out.printIssue("", diagnostics.Issue{RepoName: repos.BaseRepoName(), Path: "missing.md", Description: "Lint", Severity: diagnostics.IssueSeverityNote})
+	assert.Equal(t, "\x1b[36mLint\x1b[0m\n", buf.String())
+}
+
+// @llr REQ-TRAQ-SWL-127
+func TestConsole_PrintColumns(t *testing.T) {
+	var buf bytes.Buffer
+	out := console{w: &buf}
+	out.printColumns("  ", [][]string{{"repo:a.md", "90%"}, {"repo:longer.md", "80%"}}, []string{ansiBold})
+	assert.Equal(t, "  repo:a.md       90%\n  repo:longer.md  80%\n", buf.String())
+
+	buf.Reset()
+	out.color = true
+	out.printColumns("", [][]string{{"a", "1"}, {"bcd", "2"}}, []string{ansiBold})
+	assert.Equal(t, "\x1b[1ma  \x1b[0m  1\n\x1b[1mbcd\x1b[0m  2\n", buf.String())
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	deleteRationale *string
+	deleteReparent  *bool
+
+	deleteRewrite rewriteFlags
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete REQ_ID [--rationale RATIONALE] [--reparent]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Replaces a requirement with a DELETED placeholder",
+	Long: `Replaces the definition of a requirement of the current repository with a DELETED placeholder keeping its
+ID, so the sequence of the requirement IDs has no gaps, and records the rationale of the deletion as an attribute
+of the placeholder. With --reparent the children of the requirement defined in the current repository get the
+parents of the deleted requirement instead, unless they would be left without parents or the parents are not allowed
+by the links of their document. The children still linking to the deleted requirement are listed, as they are
+reported by validate. With --dry-run or --diff the documents are not modified and the command fails.`,
+	RunE: RunAndHandleError(runDeleteCmd),
+}
+
+// Registers the delete command
+// @llr REQ-TRAQ-SWL-140
+func init() {
+	deleteRationale = deleteCmd.Flags().String("rationale", "", "The reason why the requirement is deleted.")
+	deleteReparent = deleteCmd.Flags().Bool("reparent", false, "Replace the deleted requirement in the parents of its children with its own parents.")
+	deleteRewrite = addRewriteFlags(deleteCmd)
+	_ = deleteCmd.MarkFlagRequired("rationale")
+	rootCmd.AddCommand(deleteCmd)
+}
+
+// runDeleteCmd replaces the given requirement with a DELETED placeholder and updates or lists its children
+// @llr REQ-TRAQ-SWL-140, REQ-TRAQ-SWL-122, REQ-TRAQ-SWL-130
+func runDeleteCmd(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	repoName := repos.BaseRepoName()
+	r, ok := rg.Reqs[args[0]]
+	if !ok || r.Document == nil {
+		return fmt.Errorf("Unknown requirement %s", args[0])
+	}
+	if r.RepoName != repoName {
+		return fmt.Errorf("Requirement %s is defined in repository %s, not in the current repository", r.ID, r.RepoName)
+	}
+	if r.IsDeleted() {
+		return fmt.Errorf("Requirement %s is already deleted", r.ID)
+	}
+
+	// The files are only written once all the changes are made. Replacing the parents of a child keeps the lines in
+	// place, so the placeholder, which removes lines, is inserted last.
+	originals := make(map[string]string)
+	updated := make(map[string]string)
+	fileContent := func(file string) (string, error) {
+		if content, ok := updated[file]; ok {
+			return content, nil
+		}
+		path, err := repos.PathInRepo(repoName, file)
+		if err != nil {
+			return "", err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		originals[file] = string(content)
+		return string(content), nil
+	}
+
+	children := append([]*reqs.Req{}, r.Children...)
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+	for _, child := range children {
+		if child.IsDeleted() {
+			continue
+		}
+		if !*deleteReparent || child.RepoName != repoName || child.Document == nil {
+			fmt.Printf("Requirement %s still has %s as parent\n", child.ID, r.ID)
+			continue
+		}
+		if err := rg.CheckReplacementParents(child, r.ID, r.ParentIds); err != nil {
+			fmt.Printf("Requirement %s still has %s as parent: %v\n", child.ID, r.ID, err)
+			continue
+		}
+		file, line := child.Document.Locate(child.Position)
+		content, err := fileContent(file)
+		if err != nil {
+			return err
+		}
+		if updated[file], err = reqs.ReplaceParent(content, line, child.ID, r.ID, r.ParentIds); err != nil {
+			return errors.Wrapf(err, "update parents of %s in `%s`", child.ID, file)
+		}
+		fmt.Printf("Replaced parent %s of %s\n", r.ID, child.ID)
+	}
+	for _, tag := range r.Tags {
+		fmt.Printf("Code %s:%d %s still implements %s\n", tag.CodeFile.Path, tag.Line, tag.Tag, r.ID)
+	}
+
+	file, line := r.Document.Locate(r.Position)
+	content, err := fileContent(file)
+	if err != nil {
+		return err
+	}
+	if updated[file], err = reqs.TombstoneReq(content, line, r.ID, *deleteRationale); err != nil {
+		return errors.Wrapf(err, "delete requirement from `%s`", file)
+	}
+
+	files := make([]string, 0, len(updated))
+	for file := range updated {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		path, err := repos.PathInRepo(repoName, file)
+		if err != nil {
+			return err
+		}
+		if _, err := deleteRewrite.rewriteDocument(os.Stdout, path, file, originals[file], updated[file]); err != nil {
+			return err
+		}
+	}
+	if deleteRewrite.preview() {
+		fmt.Printf("Would delete %s from %s:%d\n", r.ID, file, line)
+		return deleteRewrite.checkPreview(len(files))
+	}
+	fmt.Printf("Deleted %s from %s:%d\n", r.ID, file, line)
+	return nil
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/code/parsers"
+	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/report"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+var fDoctorJson *bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Args:  cobra.NoArgs,
+	Short: "Checks the external tools reqtraq depends on",
+	Long: `Checks whether git, Universal Ctags, pandoc and libclang are available, with their versions, and lists the
+features enabled or disabled by each of them. Fails when a dependency required by the default features is missing,
+i.e. git unless --no-git is given, or Universal Ctags. With --json, the status is written as JSON for scripts.`,
+	RunE: RunAndHandleError(runDoctor),
+}
+
+// Registers the doctor command
+// @llr REQ-TRAQ-SWL-162
+func init() {
+	fDoctorJson = doctorCmd.Flags().Bool("json", false, "Write the status of the dependencies as JSON.")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// The status of an external dependency
+const (
+	dependencyAvailable = "available"
+	dependencyMissing   = "missing"
+	dependencyDisabled  = "disabled"
+)
+
+// dependency is the status of an external tool and of the features depending on it
+type dependency struct {
+	Name string `json:"name"`
+	// One of dependencyAvailable, dependencyMissing or dependencyDisabled
+	Status string `json:"status"`
+	// Whether the default features of reqtraq cannot work without it
+	Required bool   `json:"required"`
+	Version  string `json:"version,omitempty"`
+	// Why the dependency is missing or disabled, and how to fix it
+	Problem  string   `json:"problem,omitempty"`
+	Features []string `json:"features"`
+}
+
+// checkDependencies returns the status of the external tools reqtraq depends on
+// @llr REQ-TRAQ-SWL-162
+func checkDependencies() []dependency {
+	git := dependency{Name: "git", Required: !repos.NoGit, Features: []string{
+		"repository discovery and cloning", "attribute history", "code reviews", "related changes", "document history"}}
+	if repos.NoGit {
+		git.Status, git.Problem = dependencyDisabled, "disabled with --no-git"
+	} else if version, err := linepipes.Single(linepipes.Run("git", "--version")); err != nil {
+		git.Status, git.Problem = dependencyMissing, err.Error()
+	} else {
+		git.Status, git.Version = dependencyAvailable, version
+	}
+
+	ctags := dependency{Name: "ctags", Required: true, Features: []string{"ctags code parser"}}
+	if version, err := parsers.CtagsVersion(); err != nil {
+		ctags.Status, ctags.Problem = dependencyMissing, err.Error()
+	} else {
+		ctags.Status, ctags.Version = dependencyAvailable, version
+	}
+
+	pandoc := dependency{Name: "pandoc", Features: []string{"HTML rendering of the requirement bodies"}}
+	if version, err := report.PandocVersion(); err != nil {
+		pandoc.Status, pandoc.Problem = dependencyMissing, err.Error()+", the bodies are shown as plain text"
+	} else {
+		pandoc.Status, pandoc.Version = dependencyAvailable, version
+	}
+
+	libclang := dependency{Name: "libclang", Features: []string{"clang code parser"}}
+	if _, ok := code.FindCodeParser("clang"); ok {
+		libclang.Status = dependencyAvailable
+	} else {
+		libclang.Status, libclang.Problem = dependencyDisabled, "reqtraq was built without the `clang` build tag"
+	}
+
+	return []dependency{git, ctags, pandoc, libclang}
+}
+
+// runDoctor prints the status of the external tools and fails if a required one is missing
+// @llr REQ-TRAQ-SWL-162
+func runDoctor(command *cobra.Command, args []string) error {
+	dependencies := checkDependencies()
+	if *fDoctorJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(dependencies); err != nil {
+			return err
+		}
+	} else {
+		printDependencies(newConsole(os.Stdout), dependencies)
+	}
+
+	for _, d := range dependencies {
+		if d.Required && d.Status != dependencyAvailable {
+			return fmt.Errorf("required dependency %s is %s", d.Name, d.Status)
+		}
+	}
+	return nil
+}
+
+// printDependencies prints the status of each dependency followed by the features depending on it
+// @llr REQ-TRAQ-SWL-162
+func printDependencies(out console, dependencies []dependency) {
+	for _, d := range dependencies {
+		status := out.style(ansiGreen, d.Status)
+		if d.Status != dependencyAvailable {
+			style := ansiYellow
+			if d.Required {
+				style = ansiRed
+			}
+			status = out.style(style, d.Status)
+		}
+		line := fmt.Sprintf("%s: %s", out.style(ansiBold, d.Name), status)
+		if d.Version != "" {
+			line += " (" + d.Version + ")"
+		}
+		if d.Problem != "" {
+			line += ": " + d.Problem
+		}
+		fmt.Fprintln(out.w, line)
+		verb := "enabled"
+		if d.Status != dependencyAvailable {
+			verb = "disabled"
+		}
+		for _, feature := range d.Features {
+			fmt.Fprintf(out.w, "  %s %s\n", verb, feature)
+		}
+	}
+}
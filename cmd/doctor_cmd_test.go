@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-162
+func TestDoctor_PrintDependencies(t *testing.T) {
+	var buf bytes.Buffer
+	printDependencies(console{w: &buf}, []dependency{
+		{Name: "git", Status: dependencyAvailable, Required: true, Version: "git version 2.39.2", Features: []string{"attribute history"}},
+		{Name: "libclang", Status: dependencyDisabled, Problem: "reqtraq was built without the `clang` build tag", Features: []string{"clang code parser"}},
+	})
+	assert.Equal(t, "git: available (git version 2.39.2)\n"+
+		"  enabled attribute history\n"+
+		"libclang: disabled: reqtraq was built without the `clang` build tag\n"+
+		"  disabled clang code parser\n", buf.String())
+}
+
+// @llr REQ-TRAQ-SWL-162
+func TestDoctor_CheckDependencies(t *testing.T) {
+	dependencies := checkDependencies()
+	names := []string{}
+	for _, d := range dependencies {
+		names = append(names, d.Name)
+		assert.Contains(t, []string{dependencyAvailable, dependencyMissing, dependencyDisabled}, d.Status, d.Name)
+		assert.NotEmpty(t, d.Features, d.Name)
+	}
+	assert.Equal(t, []string{"git", "ctags", "pandoc", "libclang"}, names)
+}
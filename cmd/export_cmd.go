@@ -3,23 +3,55 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/report"
+	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/pkg/errors"
 )
 
-var fExportRaw *bool
+var (
+	fExportRaw       *bool
+	fExportMarkdown  *bool
+	fExportSourceURL *string
+	fExportFormat    *string
+	fExportDoc       *string
+	fExportPartition *bool
+	fExportHistory   *[]string
+	fExportReqIFOut  *string
+)
 
 var exportCmd = &cobra.Command{
 	Use:   "export OUT_DIR",
 	Args:  cobra.ExactArgs(1),
 	Short: "Export the parsed requirements as JSON",
-	Long:  `The parsed requirements exported as JSON can be analyzed, or aggregated with others to produce a complete graph.`,
-	RunE:  RunAndHandleError(runExport),
+	Long: `The parsed requirements exported as JSON can be analyzed, or aggregated with others to produce a complete graph.
+With --markdown, the requirements are exported as one markdown page per requirement instead, to be published in a
+wiki or with a static site generator. With --format=csv, the requirements of each document of the current repository,
+or only of the document given with --doc, are exported as one CSV file per document, with the attributes of the
+schema of the document as columns. With --format=foreign-ids, the table mapping the IDs of the requirements to the
+foreign IDs recorded in their Foreign-ID attribute is exported as foreign-ids.csv. With --partition-by-doc, the raw graph is exported as one file per document, which
+can be validated separately, e.g. by several CI runners, and merged by the commands accepting exported graphs.`,
+	RunE: RunAndHandleError(runExport),
+}
+
+var exportReqIFCmd = &cobra.Command{
+	Use:   "reqif",
+	Args:  cobra.NoArgs,
+	Short: "Export the requirements as ReqIF",
+	Long: `The requirements of all the documents are exported as a ReqIF 1.2 file, to be imported in requirement
+management tools such as DOORS or Polarion. The file is written to the path given with --out, or named after the
+target repository in the current directory.`,
+	RunE: RunAndHandleError(runExportReqIF),
 }
 
 // exportedReqsGraph is turned into JSON to be consumed by external clients.
@@ -32,10 +64,120 @@ type exportedReqsGraph struct {
 			Path string
 		}
 	}
+	// The completeness scores of all the documents and of each document
+	Scores struct {
+		Overall   reqs.Score
+		Documents []reqs.Score
+	}
+	// Where the graph comes from and what it holds, missing in graphs exported by older versions
+	Metadata *exportMetadata `json:",omitempty"`
+}
+
+// exportedRawGraph is the raw graph written by export, along with the metadata describing it. The metadata is
+// ignored when the graph is loaded back.
+type exportedRawGraph struct {
+	*reqs.ReqGraph
+	Metadata *exportMetadata `json:",omitempty"`
+}
+
+// exportMetadata describes the provenance and the content of an exported graph, so consumers can check the graphs
+// are complete before merging them and show where they come from
+type exportMetadata struct {
+	// The version of reqtraq which exported the graph
+	ToolVersion string
+	// The time of the export, in RFC 3339 format, or the time given by SOURCE_DATE_EPOCH for reproducible exports
+	Generated string
+	// The SHA-256 digest of the exported graph without its metadata, identical for graphs built from the same inputs
+	ContentHash string `json:",omitempty"`
+	// The commit checked out in each repository, empty when running without git
+	Revisions map[repos.RepoName]string
+	// The number of requirements and code tags of each document
+	Documents []exportedDocumentCounts
+}
+
+// exportedDocumentCounts holds the number of requirements and code tags of a document in an exported graph. The
+// code belonging to no document is counted with an empty path.
+type exportedDocumentCounts struct {
+	RepoName     repos.RepoName
+	Path         string
+	Requirements int
+	CodeTags     int
+}
+
+// exportTime returns the time recorded in the metadata of the exports: the current time, or the time given in seconds
+// since the epoch by the SOURCE_DATE_EPOCH environment variable, so identical inputs result in identical exports
+// @llr REQ-TRAQ-SWL-183
+func exportTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now().UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid SOURCE_DATE_EPOCH `%s`, expected a number of seconds", epoch)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// newExportMetadata collects the metadata of the given graph. Stubs of requirements defined in other partitions are
+// not counted.
+// @llr REQ-TRAQ-SWL-156, REQ-TRAQ-SWL-183
+func newExportMetadata(rg *reqs.ReqGraph) (exportMetadata, error) {
+	generated, err := exportTime()
+	if err != nil {
+		return exportMetadata{}, err
+	}
+	metadata := exportMetadata{
+		ToolVersion: rootCmd.Version,
+		Generated:   generated.Format(time.RFC3339),
+		Revisions:   make(map[repos.RepoName]string),
+	}
+	if rg.ReqtraqConfig != nil {
+		for repoName := range rg.ReqtraqConfig.Repos {
+			revision, err := repos.Revision(repoName)
+			if err != nil && !errors.Is(err, repos.ErrNoGit) {
+				return exportMetadata{}, err
+			}
+			metadata.Revisions[repoName] = revision
+		}
+	}
+
+	counts := make(map[exportedDocumentCounts]*exportedDocumentCounts)
+	countsOf := func(repoName repos.RepoName, doc *config.Document) *exportedDocumentCounts {
+		key := exportedDocumentCounts{RepoName: repoName}
+		if doc != nil {
+			key.Path = doc.Path
+		}
+		if _, ok := counts[key]; !ok {
+			entry := key
+			counts[key] = &entry
+		}
+		return counts[key]
+	}
+	for _, r := range rg.Reqs {
+		if !r.Stub {
+			countsOf(r.RepoName, r.Document).Requirements++
+		}
+	}
+	for repoName, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			countsOf(repoName, tag.Document).CodeTags++
+		}
+	}
+	for _, entry := range counts {
+		metadata.Documents = append(metadata.Documents, *entry)
+	}
+	sort.Slice(metadata.Documents, func(i, j int) bool {
+		if metadata.Documents[i].RepoName != metadata.Documents[j].RepoName {
+			return metadata.Documents[i].RepoName < metadata.Documents[j].RepoName
+		}
+		return metadata.Documents[i].Path < metadata.Documents[j].Path
+	})
+	return metadata, nil
 }
 
 // newExportedReqsGraph copies data out of the reqs graph to be exported.
-// @llr REQ-TRAQ-SWL-78
+// @llr REQ-TRAQ-SWL-78, REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-183
 func newExportedReqsGraph(reqs *reqs.ReqGraph) exportedReqsGraph {
 	data := exportedReqsGraph{
 		Reqs: nil,
@@ -61,47 +203,233 @@ func newExportedReqsGraph(reqs *reqs.ReqGraph) exportedReqsGraph {
 			},
 		})
 	}
+	data.Scores.Overall, data.Scores.Documents = reqs.Scores()
+	// The scores are rounded, so their formatting does not depend on the order of the floating point operations
+	data.Scores.Overall.Value = roundScore(data.Scores.Overall.Value)
+	for i := range data.Scores.Documents {
+		data.Scores.Documents[i].Value = roundScore(data.Scores.Documents[i].Value)
+	}
 	return data
 }
 
-// exportReqsGraph writes the specified requirements graph as JSON file.
-// @llr REQ-TRAQ-SWL-78
-func exportReqsGraph(reqs *reqs.ReqGraph, filePath string, raw bool) error {
-	fmt.Println("Exporting to:", filePath)
-	file, err := os.Create(filePath)
+// roundScore rounds a score, in percent, to hundredths of percent
+// @llr REQ-TRAQ-SWL-183
+func roundScore(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// exportReqsGraph writes the specified requirements graph as JSON file, with its metadata. The graph is
+// canonicalized first, so the same graph is always written identically, and its content hash is recorded in the
+// metadata. The file is an artifact generated by the command from the inputs, with the requirement filters applied.
+// @llr REQ-TRAQ-SWL-78, REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-156, REQ-TRAQ-SWL-183
+func exportReqsGraph(rg *reqs.ReqGraph, filePath string, raw bool, command string, inputs []string, filters map[string]string) error {
+	metadata, err := newExportMetadata(rg)
+	if err != nil {
+		return errors.Wrap(err, "graph metadata")
+	}
+	rg.Canonicalize()
+
+	kind := "graph"
+	if raw {
+		kind = "raw-graph"
+	}
+	file, err := createArtifact(filePath, kind, command, inputs, filters)
 	if err != nil {
 		return err
 	}
+	fmt.Println("Exporting to:", file.Name())
 	jsonWriter := json.NewEncoder(file)
 	jsonWriter.SetIndent("", "  ")
 	if raw {
-		if err := jsonWriter.Encode(reqs); err != nil {
+		graph := exportedRawGraph{ReqGraph: rg}
+		if metadata.ContentHash, err = reqs.ContentHash(graph); err != nil {
+			return errors.Wrap(err, "raw graph content hash")
+		}
+		graph.Metadata = &metadata
+		if err := jsonWriter.Encode(graph); err != nil {
+			file.File.Close()
 			return errors.Wrap(err, "raw graph JSON encoding")
 		}
 	} else {
-		data := newExportedReqsGraph(reqs)
+		data := newExportedReqsGraph(rg)
+		if metadata.ContentHash, err = reqs.ContentHash(data); err != nil {
+			return errors.Wrap(err, "processed graph content hash")
+		}
+		data.Metadata = &metadata
 		if err := jsonWriter.Encode(data); err != nil {
+			file.File.Close()
 			return errors.Wrap(err, "processed graph JSON encoding")
 		}
 	}
 	return file.Close()
 }
 
+// exportCSV writes the requirements of the given document, or of all the documents of the current repository, as
+// one CSV file per document named after the document
+// @llr REQ-TRAQ-SWL-132
+func exportCSV(rg *reqs.ReqGraph, exportDir string, docPath string) error {
+	repoName := repos.BaseRepoName()
+	var documents []*config.Document
+	if docPath == "" {
+		for i := range rg.ReqtraqConfig.Repos[repoName].Documents {
+			documents = append(documents, &rg.ReqtraqConfig.Repos[repoName].Documents[i])
+		}
+	} else if docRepoName, doc := rg.ReqtraqConfig.FindCertdoc(docPath); doc == nil || docRepoName != repoName {
+		return fmt.Errorf("Could not find document `%s` in the list of documents of the current repository", docPath)
+	} else {
+		documents = append(documents, doc)
+	}
+
+	if err := os.MkdirAll(outputPath(exportDir), 0755); err != nil {
+		return err
+	}
+	for _, doc := range documents {
+		filePath := path.Join(exportDir, strings.TrimSuffix(path.Base(doc.Path), ".md")+".csv")
+		file, err := createArtifact(filePath, "csv", "export", []string{string(repoName) + ":" + doc.Path}, nil)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Exporting to:", file.Name())
+		if err := report.WriteCSV(file, rg, repoName, doc); err != nil {
+			file.File.Close()
+			return errors.Wrapf(err, "export `%s` as CSV", doc.Path)
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportForeignIDs writes the table mapping the IDs of the requirements to their foreign IDs as foreign-ids.csv
+// @llr REQ-TRAQ-SWL-145
+func exportForeignIDs(rg *reqs.ReqGraph, exportDir string) error {
+	if err := os.MkdirAll(outputPath(exportDir), 0755); err != nil {
+		return err
+	}
+	file, err := createArtifact(path.Join(exportDir, "foreign-ids.csv"), "foreign-ids", "export", graphInputs(nil), nil)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Exporting to:", file.Name())
+	if err := report.WriteForeignIDs(file, rg); err != nil {
+		file.File.Close()
+		return errors.Wrap(err, "export foreign IDs")
+	}
+	return file.Close()
+}
+
+// exportReqIF writes the requirements of all the documents as a ReqIF file at the given path
+// @llr REQ-TRAQ-SWL-195
+func exportReqIF(rg *reqs.ReqGraph, filePath string) error {
+	created, err := exportTime()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(outputPath(filePath)), 0755); err != nil {
+		return err
+	}
+	file, err := createArtifact(filePath, "reqif", "export reqif", graphInputs(nil), nil)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Exporting to:", file.Name())
+	if err := report.WriteReqIF(file, rg, created); err != nil {
+		file.File.Close()
+		return errors.Wrap(err, "export ReqIF")
+	}
+	return file.Close()
+}
+
+// exportPartitions writes the raw graph of each document as a JSON file named after the repository and the path of
+// the document. The graphs of the code and issues belonging to no document are written as `<repo>.json`.
+// @llr REQ-TRAQ-SWL-136
+func exportPartitions(rg *reqs.ReqGraph, exportDir string) error {
+	if err := os.MkdirAll(outputPath(exportDir), 0755); err != nil {
+		return err
+	}
+	for _, partition := range rg.PartitionByDocument() {
+		name := string(partition.RepoName)
+		if partition.Document != nil {
+			name += "-" + strings.ReplaceAll(strings.TrimSuffix(partition.Document.Path, ".md"), "/", "_")
+		}
+		var inputs []string
+		if partition.Document != nil {
+			inputs = []string{string(partition.RepoName) + ":" + partition.Document.Path}
+		}
+		if err := exportReqsGraph(partition.Graph, path.Join(exportDir, name+".json"), true, "export", inputs, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the run command for export reqif
+// @llr REQ-TRAQ-SWL-195
+func runExportReqIF(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	filePath := *fExportReqIFOut
+	if filePath == "" {
+		filePath = string(rg.ReqtraqConfig.TargetRepo) + ".reqif"
+	}
+	return exportReqIF(rg, filePath)
+}
+
 // the run command for export
-// @llr REQ-TRAQ-SWL-78
+// @llr REQ-TRAQ-SWL-78, REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-132, REQ-TRAQ-SWL-136, REQ-TRAQ-SWL-137, REQ-TRAQ-SWL-145
 func runExport(command *cobra.Command, args []string) error {
-	if err := setupConfiguration(); err != nil {
-		return errors.Wrap(err, "setup configuration")
+	switch *fExportFormat {
+	case "json":
+	case "csv", "foreign-ids":
+		if *fExportMarkdown {
+			return fmt.Errorf("--markdown cannot be combined with --format=%s", *fExportFormat)
+		}
+	default:
+		return fmt.Errorf("Unknown export format `%s`, expected `json`, `csv` or `foreign-ids`", *fExportFormat)
+	}
+	if *fExportDoc != "" && *fExportFormat != "csv" {
+		return fmt.Errorf("--doc can only be used with --format=csv")
+	}
+	if *fExportPartition && (*fExportMarkdown || *fExportFormat != "json") {
+		return fmt.Errorf("--partition-by-doc cannot be combined with --markdown or --format=%s", *fExportFormat)
 	}
 
-	rg, err := reqs.BuildGraph(reqtraqConfig)
+	rg, err := loadReqGraph(nil)
 	if err != nil {
-		return errors.Wrap(err, "build graph")
+		return errors.Wrap(err, "load req graph")
+	}
+
+	if err := loadAttributeHistory(rg, *fExportHistory); err != nil {
+		return err
 	}
 
 	exportDir := args[0]
+	if *fExportFormat == "csv" {
+		return exportCSV(rg, exportDir, *fExportDoc)
+	}
+	if *fExportFormat == "foreign-ids" {
+		return exportForeignIDs(rg, exportDir)
+	}
+	if *fExportPartition {
+		if err := exportPartitions(rg, exportDir); err != nil {
+			return errors.Wrap(err, "export requirements graph partitions")
+		}
+		return nil
+	}
+	if *fExportMarkdown {
+		pages := report.MarkdownPages{SourceURL: *fExportSourceURL}
+		if err := pages.Export(rg, exportDir); err != nil {
+			return errors.Wrap(err, "export markdown pages")
+		}
+		return nil
+	}
+
 	filePath := path.Join(exportDir, string(rg.ReqtraqConfig.TargetRepo)+".json")
-	if err := exportReqsGraph(rg, filePath, *fExportRaw); err != nil {
+	if err := exportReqsGraph(rg, filePath, *fExportRaw, "export", graphInputs(nil), nil); err != nil {
 		return errors.Wrap(err, "export requirements graph")
 	}
 
@@ -109,8 +437,17 @@ func runExport(command *cobra.Command, args []string) error {
 }
 
 // Registers the export command
-// @llr REQ-TRAQ-SWL-78
+// @llr REQ-TRAQ-SWL-78, REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-132, REQ-TRAQ-SWL-136, REQ-TRAQ-SWL-137, REQ-TRAQ-SWL-145, REQ-TRAQ-SWL-195
 func init() {
 	fExportRaw = exportCmd.PersistentFlags().Bool("raw", false, "Export the raw ReqGraph so it can be aggregated with others. UNSTABLE API! Future reqtraq versions will fail to read it.")
+	fExportMarkdown = exportCmd.PersistentFlags().Bool("markdown", false, "Export one markdown page per requirement, with an index.md page, instead of JSON.")
+	fExportSourceURL = exportCmd.PersistentFlags().String("source-url", "", "Template of the links to the source files in the markdown pages, with the {repo}, {path} and {line} placeholders.")
+	fExportFormat = exportCmd.PersistentFlags().String("format", "json", "The format of the export, `json`, `csv` or `foreign-ids`.")
+	fExportDoc = exportCmd.PersistentFlags().String("doc", "", "With --format=csv, the certification document to export. All the documents of the current repository are exported when empty.")
+	fExportPartition = exportCmd.PersistentFlags().Bool("partition-by-doc", false, "Export the raw ReqGraph as one file per document, with stubs of the parents defined in other documents, to be merged when loaded.")
+	fExportHistory = exportCmd.PersistentFlags().StringSlice("attribute-history", nil, "Attributes whose changes over the git history of the documents are included, e.g. `STATUS,SAFETY IMPACT`.")
+	fExportReqIFOut = exportReqIFCmd.Flags().String("out", "", "The ReqIF file to write. Named after the target repository in the current directory when empty.")
+	_ = exportCmd.RegisterFlagCompletionFunc("doc", completeCertdocFilename)
+	exportCmd.AddCommand(exportReqIFCmd)
 	rootCmd.AddCommand(exportCmd)
 }
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 
+	"github.com/daedaleanai/reqtraq/code"
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +36,7 @@ func TestExport_CanBeReloaded(t *testing.T) {
 			rg.ReqtraqConfig.Repos[repo].Documents[i].Schema = config.Schema{}
 		}
 	}
+	rg.ReqtraqConfig.LevelParents = nil
 	for repo, codeTags := range rg.CodeTags {
 		for i := range codeTags {
 			rg.CodeTags[repo][i].Document = &config.Document{}
@@ -47,7 +52,7 @@ func TestExport_CanBeReloaded(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer os.Remove(file.Name())
-	err = exportReqsGraph(rg, file.Name(), true)
+	err = exportReqsGraph(rg, file.Name(), true, "export", nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -64,3 +69,88 @@ func TestExport_CanBeReloaded(t *testing.T) {
 	// differentiating element, because the ReqGraph is very large.
 	assert.Equal(t, rg, rg2)
 }
+
+// @llr REQ-TRAQ-SWL-156
+func TestExport_Metadata(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	rg := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{
+			"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Document: &srd, RepoName: "repo", Stub: true},
+			"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Document: &sdd, RepoName: "repo", ParentIds: []string{"REQ-TEST-SWH-1"}},
+			"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Document: &sdd, RepoName: "repo"},
+		},
+		CodeTags: map[repos.RepoName][]*code.Code{
+			"repo": {
+				{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Tag: "f", Document: &sdd},
+				{CodeFile: code.CodeFile{RepoName: "repo", Path: "b.go"}, Tag: "g"},
+			},
+		},
+	}
+
+	metadata, err := newExportMetadata(rg)
+	assert.NoError(t, err)
+	assert.Equal(t, rootCmd.Version, metadata.ToolVersion)
+	assert.NotEmpty(t, metadata.Generated)
+	assert.Empty(t, metadata.Revisions)
+	assert.Equal(t, []exportedDocumentCounts{
+		{RepoName: "repo", Path: "", CodeTags: 1},
+		{RepoName: "repo", Path: "TEST-138-SDD.md", Requirements: 2, CodeTags: 1},
+	}, metadata.Documents)
+
+	// The metadata is written along with the raw and the processed graphs, which can still be checked. Processed
+	// graphs are exported from complete graphs, without stubs.
+	delete(rg.Reqs, "REQ-TEST-SWH-1")
+	for _, raw := range []bool{true, false} {
+		filePath := t.TempDir() + "/graph.json"
+		assert.NoError(t, exportReqsGraph(rg, filePath, raw, "export", nil, nil))
+		content, err := os.ReadFile(filePath)
+		assert.NoError(t, err)
+		var exported struct{ Metadata exportMetadata }
+		assert.NoError(t, json.Unmarshal(content, &exported))
+		assert.Equal(t, metadata.Documents, exported.Metadata.Documents)
+		_, _, err = readExportedGraph(filePath)
+		assert.NoError(t, err)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-183
+func TestExport_Reproducible(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1714644000")
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	newGraph := func(order []int) *reqs.ReqGraph {
+		tags := []*code.Code{
+			{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Tag: "f", Line: 3, Document: &sdd},
+			{CodeFile: code.CodeFile{RepoName: "repo", Path: "b.go"}, Tag: "g", Line: 1, Document: &sdd},
+		}
+		issues := []diagnostics.Issue{
+			{RepoName: "repo", Path: "a.go", Line: 3, Description: "First"},
+			{RepoName: "repo", Path: "b.go", Line: 1, Description: "Second"},
+		}
+		return &reqs.ReqGraph{
+			Reqs:     map[string]*reqs.Req{"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Document: &sdd, RepoName: "repo"}},
+			CodeTags: map[repos.RepoName][]*code.Code{"repo": {tags[order[0]], tags[order[1]]}},
+			Issues:   []diagnostics.Issue{issues[order[0]], issues[order[1]]},
+		}
+	}
+
+	dir := t.TempDir()
+	var contents [][]byte
+	for i, order := range [][]int{{0, 1}, {1, 0}} {
+		filePath := fmt.Sprintf("%s/graph-%d.json", dir, i)
+		assert.NoError(t, exportReqsGraph(newGraph(order), filePath, true, "export", nil, nil))
+		content, err := os.ReadFile(filePath)
+		assert.NoError(t, err)
+		contents = append(contents, content)
+	}
+	assert.Equal(t, string(contents[0]), string(contents[1]))
+
+	var exported struct{ Metadata exportMetadata }
+	assert.NoError(t, json.Unmarshal(contents[0], &exported))
+	assert.Equal(t, "2024-05-02T10:00:00Z", exported.Metadata.Generated)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", exported.Metadata.ContentHash)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, err := newExportMetadata(newGraph([]int{0, 1}))
+	assert.EqualError(t, err, "Invalid SOURCE_DATE_EPOCH `yesterday`, expected a number of seconds")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+var fmtRewrite rewriteFlags
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [CERTDOC_PATH ...]",
+	Short: "Formats the attributes of the requirements in the certification documents",
+	Long: `Rewrites the certification documents of the current repository, or only the given ones, so the attributes of
+the requirements are in the order configured by attributeOrder and the attributes section is the last section of
+each requirement. With --dry-run or --diff the documents are not modified and the command fails if any of them is
+not formatted.`,
+	ValidArgsFunction: completeCertdocFilename,
+	RunE:              RunAndHandleError(runFmtCmd),
+}
+
+// Registers the fmt command
+// @llr REQ-TRAQ-SWL-143, REQ-TRAQ-SWL-122
+func init() {
+	fmtRewrite = addRewriteFlags(fmtCmd)
+	rootCmd.AddCommand(fmtCmd)
+}
+
+// runFmtCmd rewrites the given certification documents, or all of the current repository, with the attributes of
+// the requirements formatted
+// @llr REQ-TRAQ-SWL-143, REQ-TRAQ-SWL-122, REQ-TRAQ-SWL-130
+func runFmtCmd(command *cobra.Command, args []string) error {
+	if err := setupConfiguration(); err != nil {
+		return err
+	}
+
+	repoName := repos.BaseRepoName()
+	var documents []*config.Document
+	if len(args) == 0 {
+		for i := range reqtraqConfig.Repos[repoName].Documents {
+			documents = append(documents, &reqtraqConfig.Repos[repoName].Documents[i])
+		}
+	}
+	for _, filename := range args {
+		if docRepoName, certdocConfig := reqtraqConfig.FindCertdoc(filename); certdocConfig == nil || docRepoName != repoName {
+			return fmt.Errorf("Could not find document `%s` in the list of documents of the current repository", filename)
+		} else {
+			documents = append(documents, certdocConfig)
+		}
+	}
+
+	changed := 0
+	for _, doc := range documents {
+		// Documents split in several files are formatted one fragment at a time
+		for _, file := range doc.Files() {
+			path, err := repos.PathInRepo(repoName, file)
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			formatted := reqs.FormatAttributes(string(content), doc, reqtraqConfig.AttributeOrder)
+			modified, err := fmtRewrite.rewriteDocument(os.Stdout, path, file, string(content), formatted)
+			if err != nil {
+				return err
+			}
+			if modified {
+				changed++
+				if !fmtRewrite.preview() {
+					fmt.Printf("Formatted %s\n", file)
+				}
+			}
+		}
+	}
+	return fmtRewrite.checkPreview(changed)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	genTestsReqs    *[]string
+	genTestsLang    *string
+	genTestsPackage *string
+	genTestsOut     *string
+)
+
+var genTestsCmd = &cobra.Command{
+	Use:   "gentests --lang go|cpp --out FILE [--req REQ_ID ...]",
+	Args:  cobra.NoArgs,
+	Short: "Generates test skeletons for requirements",
+	Long: `Generates a test file with an empty test for each of the given requirements, annotated with the requirement
+it tests. By default the tests are generated for all the requirements of the current repository which are not
+tested yet.`,
+	RunE: RunAndHandleError(runGenTestsCmd),
+}
+
+// Registers the gentests command
+// @llr REQ-TRAQ-SWL-118
+func init() {
+	genTestsReqs = genTestsCmd.Flags().StringSlice("req", nil, "The IDs of the requirements to generate tests for.")
+	genTestsLang = genTestsCmd.Flags().String("lang", "", "The language of the tests: go or cpp.")
+	genTestsPackage = genTestsCmd.Flags().String("package", "test", "The package of the generated Go tests.")
+	genTestsOut = genTestsCmd.Flags().String("out", "", "The file to write the tests to, which must not exist.")
+	_ = genTestsCmd.MarkFlagRequired("lang")
+	_ = genTestsCmd.MarkFlagRequired("out")
+	_ = genTestsCmd.RegisterFlagCompletionFunc("lang", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return reqs.TestSkeletonLanguages, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(genTestsCmd)
+}
+
+// runGenTestsCmd generates the test skeletons of the requested requirements
+// @llr REQ-TRAQ-SWL-118
+func runGenTestsCmd(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	var requirements []*reqs.Req
+	if len(*genTestsReqs) == 0 {
+		requirements = rg.UntestedReqs(string(repos.BaseRepoName()))
+		if len(requirements) == 0 {
+			fmt.Println("All the requirements are tested")
+			return nil
+		}
+	}
+	for _, id := range *genTestsReqs {
+		r, ok := rg.Reqs[id]
+		if !ok || r.IsDeleted() {
+			return fmt.Errorf("Unknown requirement `%s`", id)
+		}
+		requirements = append(requirements, r)
+	}
+
+	tests, err := reqs.TestSkeletons(requirements, *genTestsLang, *genTestsPackage)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(*genTestsOut, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.WriteString(tests); err != nil {
+		return err
+	}
+	fmt.Printf("Generated %d tests in %s\n", len(requirements), *genTestsOut)
+	return nil
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Operations on exported requirements graphs",
+	Long:  `Operations on requirements graphs exported with the export command, which do not need access to the repositories.`,
+}
+
+var graphCheckCmd = &cobra.Command{
+	Use:   "check GRAPH_JSON...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Checks the consistency of exported requirements graphs",
+	Long: `Checks requirements graphs exported with the export command, either raw or processed, without access to the repositories.
+The files must follow the export schema, each requirement ID must be defined once and all parent references and code links
+must point to requirements defined in the given files.`,
+	RunE: RunAndHandleError(runGraphCheck),
+}
+
+// graphEntry is a requirement found in an exported graph, in a form common to raw and processed exports.
+type graphEntry struct {
+	ID        string
+	ParentIds []string
+	File      string
+}
+
+// graphLink is a reference to a requirement from something other than a requirement, e.g. code.
+type graphLink struct {
+	ID     string
+	Source string
+	File   string
+}
+
+// readExportedGraph reads an exported graph file, in raw or processed form, and returns the requirements
+// and links to requirements found in it. Returns an error if the file does not follow the export schema, or if it
+// does not hold the requirements and code tags counted in its metadata.
+// @llr REQ-TRAQ-SWL-96, REQ-TRAQ-SWL-156
+func readExportedGraph(filePath string) ([]graphEntry, []graphLink, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid JSON")
+	}
+	rawReqs, ok := top["Reqs"]
+	if !ok {
+		return nil, nil, fmt.Errorf("missing `Reqs` field")
+	}
+
+	strictDecoder := json.NewDecoder(bytes.NewReader(data))
+	strictDecoder.DisallowUnknownFields()
+
+	var entries []graphEntry
+	var links []graphLink
+	if trimmed := bytes.TrimSpace(rawReqs); len(trimmed) > 0 && trimmed[0] == '[' {
+		// Processed export
+		var graph exportedReqsGraph
+		if err := strictDecoder.Decode(&graph); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid processed graph")
+		}
+		reqCounts := make(map[string]int)
+		for _, r := range graph.Reqs {
+			entries = append(entries, graphEntry{ID: r.ID, ParentIds: r.ParentIds, File: filePath})
+			reqCounts[r.Document.Path]++
+		}
+		if err := checkExportMetadata(graph.Metadata, reqCounts, nil); err != nil {
+			return nil, nil, err
+		}
+		return entries, links, nil
+	}
+
+	// Raw export. Requirements are stored in an object by ID, so duplicates need to be found in the
+	// keys before decoding them into a map
+	keys, err := jsonObjectKeys(rawReqs)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid raw graph")
+	}
+	var graph reqs.ReqGraph
+	rawGraph := exportedRawGraph{ReqGraph: &graph}
+	if err := strictDecoder.Decode(&rawGraph); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid raw graph")
+	}
+	reqCounts := make(map[string]int)
+	tagCounts := make(map[string]int)
+	for _, key := range keys {
+		r := graph.Reqs[key]
+		if r == nil {
+			entries = append(entries, graphEntry{ID: key, File: filePath})
+			continue
+		}
+		if r.ID != key {
+			return nil, nil, fmt.Errorf("invalid raw graph: requirement `%s` is stored with key `%s`", r.ID, key)
+		}
+		entries = append(entries, graphEntry{ID: r.ID, ParentIds: r.ParentIds, File: filePath})
+		if !r.Stub && r.Document != nil {
+			reqCounts[r.Document.Path]++
+		}
+	}
+	for _, tags := range graph.CodeTags {
+		for _, tag := range tags {
+			if tag.Document != nil {
+				tagCounts[tag.Document.Path]++
+			} else {
+				tagCounts[""]++
+			}
+			for _, link := range tag.Links {
+				links = append(links, graphLink{
+					ID:     link.Id,
+					Source: fmt.Sprintf("function %s@%s:%d", tag.Tag, tag.CodeFile.String(), tag.Line),
+					File:   filePath,
+				})
+			}
+		}
+	}
+	if err := checkExportMetadata(rawGraph.Metadata, reqCounts, tagCounts); err != nil {
+		return nil, nil, err
+	}
+	return entries, links, nil
+}
+
+// checkExportMetadata returns an error if the number of requirements or code tags of a document, by path, differs
+// from the one recorded in the metadata of the graph, e.g. because the file was truncated or edited. The code tags
+// are not checked when their counts are nil, and graphs without metadata are not checked.
+// @llr REQ-TRAQ-SWL-156
+func checkExportMetadata(metadata *exportMetadata, reqCounts, tagCounts map[string]int) error {
+	if metadata == nil {
+		return nil
+	}
+	expectedReqs := make(map[string]int)
+	expectedTags := make(map[string]int)
+	for _, doc := range metadata.Documents {
+		expectedReqs[doc.Path] += doc.Requirements
+		expectedTags[doc.Path] += doc.CodeTags
+	}
+
+	check := func(what string, expected, found map[string]int) error {
+		paths := make([]string, 0, len(expected)+len(found))
+		for path := range expected {
+			paths = append(paths, path)
+		}
+		for path := range found {
+			if _, ok := expected[path]; !ok {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if expected[path] != found[path] {
+				return fmt.Errorf("document `%s` holds %d %s, the metadata records %d", path, found[path], what, expected[path])
+			}
+		}
+		return nil
+	}
+	if err := check("requirements", expectedReqs, reqCounts); err != nil {
+		return err
+	}
+	if tagCounts != nil {
+		return check("code tags", expectedTags, tagCounts)
+	}
+	return nil
+}
+
+// jsonObjectKeys returns the keys of a JSON object in the order they appear, including duplicates
+// @llr REQ-TRAQ-SWL-96
+func jsonObjectKeys(data json.RawMessage) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected an object but found `%v`", token)
+	}
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// checkExportedGraphs verifies the requirements from the given exported graphs are only defined once
+// and that all references to requirements can be resolved.
+// @llr REQ-TRAQ-SWL-96
+func checkExportedGraphs(entries []graphEntry, links []graphLink) []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	definedIn := make(map[string]string)
+
+	for _, entry := range entries {
+		if entry.ID == "" {
+			issues = append(issues, diagnostics.Issue{
+				Path:        entry.File,
+				Description: fmt.Sprintf("%s: requirement without ID.", entry.File),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidRequirementId,
+			})
+			continue
+		}
+		if file, ok := definedIn[entry.ID]; ok {
+			issues = append(issues, diagnostics.Issue{
+				Path:        entry.File,
+				Description: fmt.Sprintf("%s: requirement %s is already defined in %s.", entry.File, entry.ID, file),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidRequirementId,
+			})
+			continue
+		}
+		definedIn[entry.ID] = entry.File
+	}
+
+	for _, entry := range entries {
+		for _, parentID := range entry.ParentIds {
+			if _, ok := definedIn[parentID]; !ok {
+				issues = append(issues, diagnostics.Issue{
+					Path:        entry.File,
+					Description: fmt.Sprintf("%s: invalid parent of requirement %s: %s does not exist.", entry.File, entry.ID, parentID),
+					Severity:    diagnostics.IssueSeverityMajor,
+					Type:        diagnostics.IssueTypeInvalidParent,
+				})
+			}
+		}
+	}
+
+	for _, link := range links {
+		if _, ok := definedIn[link.ID]; !ok {
+			issues = append(issues, diagnostics.Issue{
+				Path:        link.File,
+				Description: fmt.Sprintf("%s: invalid reference in %s, %s does not exist.", link.File, link.Source, link.ID),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidRequirementInCode,
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+// the run command for graph check
+// @llr REQ-TRAQ-SWL-96
+func runGraphCheck(command *cobra.Command, args []string) error {
+	var entries []graphEntry
+	var links []graphLink
+	for _, filePath := range args {
+		fileEntries, fileLinks, err := readExportedGraph(filePath)
+		if err != nil {
+			return errors.Wrapf(err, "malformed graph `%s`", filePath)
+		}
+		entries = append(entries, fileEntries...)
+		links = append(links, fileLinks...)
+	}
+
+	issues := checkExportedGraphs(entries, links)
+	for _, issue := range issues {
+		fmt.Println(issue.Description)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("graph check failed: %d issues", len(issues))
+	}
+
+	fmt.Printf("Graph check passed! (%d requirements)\n", len(entries))
+	return nil
+}
+
+// Registers the graph command
+// @llr REQ-TRAQ-SWL-96
+func init() {
+	graphCmd.AddCommand(graphCheckCmd)
+	rootCmd.AddCommand(graphCmd)
+}
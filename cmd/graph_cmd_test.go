@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-96
+func writeGraphFile(t *testing.T, dir, name, content string) string {
+	filePath := filepath.Join(dir, name)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+// @llr REQ-TRAQ-SWL-96
+func TestGraphCheck_Processed(t *testing.T) {
+	dir := t.TempDir()
+	sys := writeGraphFile(t, dir, "sys.json", `{"Reqs": [
+		{"ID": "REQ-TEST-SYS-1", "ParentIds": [], "Document": {"Path": "TEST-100-ORD.md"}},
+		{"ID": "REQ-TEST-SYS-2", "ParentIds": null, "Document": {"Path": "TEST-100-ORD.md"}}
+	]}`)
+	swh := writeGraphFile(t, dir, "swh.json", `{"Reqs": [
+		{"ID": "REQ-TEST-SWH-1", "ParentIds": ["REQ-TEST-SYS-1"], "Document": {"Path": "TEST-137-SRD.md"}},
+		{"ID": "REQ-TEST-SWH-2", "ParentIds": ["REQ-TEST-SYS-3"], "Document": {"Path": "TEST-137-SRD.md"}},
+		{"ID": "REQ-TEST-SYS-2", "ParentIds": [], "Document": {"Path": "TEST-137-SRD.md"}}
+	]}`)
+
+	var entries []graphEntry
+	var links []graphLink
+	for _, filePath := range []string{sys, swh} {
+		fileEntries, fileLinks, err := readExportedGraph(filePath)
+		assert.NoError(t, err)
+		entries = append(entries, fileEntries...)
+		links = append(links, fileLinks...)
+	}
+	assert.Len(t, entries, 5)
+	assert.Empty(t, links)
+
+	issues := checkExportedGraphs(entries, links)
+	if assert.Len(t, issues, 2) {
+		assert.Equal(t, diagnostics.IssueTypeInvalidRequirementId, issues[0].Type)
+		assert.Equal(t, swh+": requirement REQ-TEST-SYS-2 is already defined in "+sys+".", issues[0].Description)
+		assert.Equal(t, diagnostics.IssueTypeInvalidParent, issues[1].Type)
+		assert.Equal(t, swh+": invalid parent of requirement REQ-TEST-SWH-2: REQ-TEST-SYS-3 does not exist.", issues[1].Description)
+	}
+
+	// Without the file defining the parents, all links are dangling
+	entries, links, err := readExportedGraph(swh)
+	assert.NoError(t, err)
+	assert.Len(t, checkExportedGraphs(entries, links), 2)
+}
+
+// @llr REQ-TRAQ-SWL-96
+func TestGraphCheck_Raw(t *testing.T) {
+	dir := t.TempDir()
+	raw := writeGraphFile(t, dir, "raw.json", `{
+		"Reqs": {
+			"REQ-TEST-SWL-1": {"ID": "REQ-TEST-SWL-1"},
+			"REQ-TEST-SWL-1": {"ID": "REQ-TEST-SWL-1"},
+			"REQ-TEST-SWL-3": {"ID": "REQ-TEST-SWL-3", "ParentIds": ["REQ-TEST-SWH-1"]}
+		},
+		"CodeTags": {
+			"repo": [
+				{"CodeFile": {"Path": "a.go"}, "Tag": "f", "Line": 3, "Links": [{"Id": "REQ-TEST-SWL-1"}, {"Id": "REQ-TEST-SWL-2"}]}
+			]
+		}
+	}`)
+
+	entries, links, err := readExportedGraph(raw)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Len(t, links, 2)
+
+	issues := checkExportedGraphs(entries, links)
+	if assert.Len(t, issues, 3) {
+		assert.Equal(t, diagnostics.IssueTypeInvalidRequirementId, issues[0].Type)
+		assert.Equal(t, diagnostics.IssueTypeInvalidParent, issues[1].Type)
+		assert.Equal(t, diagnostics.IssueTypeInvalidRequirementInCode, issues[2].Type)
+		assert.Contains(t, issues[2].Description, "REQ-TEST-SWL-2 does not exist")
+	}
+}
+
+// @llr REQ-TRAQ-SWL-96
+func TestGraphCheck_Malformed(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"not_json.json":   `{"Reqs": [`,
+		"no_reqs.json":    `{"Requirements": []}`,
+		"unknown.json":    `{"Reqs": [{"ID": "REQ-TEST-SYS-1", "Parents": []}]}`,
+		"bad_type.json":   `{"Reqs": [{"ID": 1}]}`,
+		"key_id.json":     `{"Reqs": {"REQ-TEST-SYS-1": {"ID": "REQ-TEST-SYS-2"}}}`,
+		"raw_object.json": `{"Reqs": "REQ-TEST-SYS-1"}`,
+	} {
+		_, _, err := readExportedGraph(writeGraphFile(t, dir, name, content))
+		assert.Error(t, err, name)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-156
+func TestGraphCheck_Metadata(t *testing.T) {
+	dir := t.TempDir()
+	metadata := `"Metadata": {"ToolVersion": "0.1.0", "Generated": "2024-05-02T10:00:00Z", "Revisions": {"repo": "abc"},
+		"Documents": [{"RepoName": "repo", "Path": "TEST-138-SDD.md", "Requirements": 2, "CodeTags": 1}]}`
+
+	raw := writeGraphFile(t, dir, "raw.json", `{
+		"Reqs": {
+			"REQ-TEST-SWH-1": {"ID": "REQ-TEST-SWH-1", "Stub": true},
+			"REQ-TEST-SWL-1": {"ID": "REQ-TEST-SWL-1", "ParentIds": ["REQ-TEST-SWH-1"], "Document": {"Path": "TEST-138-SDD.md"}},
+			"REQ-TEST-SWL-2": {"ID": "REQ-TEST-SWL-2", "Document": {"Path": "TEST-138-SDD.md"}}
+		},
+		"CodeTags": {
+			"repo": [{"CodeFile": {"Path": "a.go"}, "Tag": "f", "Line": 3, "Document": {"Path": "TEST-138-SDD.md"}}]
+		},
+		`+metadata+`
+	}`)
+	entries, _, err := readExportedGraph(raw)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	processed := writeGraphFile(t, dir, "processed.json", `{"Reqs": [
+		{"ID": "REQ-TEST-SWL-1", "ParentIds": [], "Document": {"Path": "TEST-138-SDD.md"}},
+		{"ID": "REQ-TEST-SWL-2", "ParentIds": [], "Document": {"Path": "TEST-138-SDD.md"}}
+	], `+metadata+`}`)
+	_, _, err = readExportedGraph(processed)
+	assert.NoError(t, err)
+
+	// A requirement is missing from the truncated graph
+	truncated := writeGraphFile(t, dir, "truncated.json", `{"Reqs": [
+		{"ID": "REQ-TEST-SWL-1", "ParentIds": [], "Document": {"Path": "TEST-138-SDD.md"}}
+	], `+metadata+`}`)
+	_, _, err = readExportedGraph(truncated)
+	assert.EqualError(t, err, "document `TEST-138-SDD.md` holds 1 requirements, the metadata records 2")
+}
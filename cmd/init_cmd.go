@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/pkg/errors"
+)
+
+var (
+	initName   *string
+	initPrefix *string
+)
+
+var initPrefixRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9]*$`)
+
+var initCmd = &cobra.Command{
+	Use:   "init [--name REPO_NAME] [--prefix PREFIX]",
+	Args:  cobra.NoArgs,
+	Short: "Creates an example project in the current repository",
+	Long: `Creates a reqtraq configuration, system, high-level and low-level requirements documents and source code
+implementing and testing the low-level requirements in the current git repository, as a starting point for
+tracing its requirements. Existing files are never overwritten.`,
+	RunE: RunAndHandleError(runInitCmd),
+}
+
+type initData struct {
+	RepoName string
+	Prefix   string
+}
+
+// The files of the example project by path, relative to the root of the repository
+var initTemplates = map[string]string{
+	"reqtraq_config.json": `{
+    "repoName": "{{ .RepoName }}",
+    "commonAttributes": [
+        {
+            "name": "Rationale",
+            "required": "any"
+        },
+        {
+            "name": "Verification",
+            "value": "(Demonstration|Inspection|Test)"
+        }
+    ],
+    "documents": [
+        {
+            "path": "certdocs/{{ .Prefix }}-100-ORD.md",
+            "prefix": "{{ .Prefix }}",
+            "level": "SYS"
+        },
+        {
+            "path": "certdocs/{{ .Prefix }}-137-SRD.md",
+            "prefix": "{{ .Prefix }}",
+            "level": "SWH",
+            "parent": {
+                "prefix": "{{ .Prefix }}",
+                "level": "SYS"
+            }
+        },
+        {
+            "path": "certdocs/{{ .Prefix }}-138-SDD.md",
+            "prefix": "{{ .Prefix }}",
+            "level": "SWL",
+            "parent": {
+                "prefix": "{{ .Prefix }}",
+                "level": "SWH"
+            },
+            "implementation": [
+                {
+                    "code": {
+                        "paths": ["src"],
+                        "matchingPattern": ".*\\.(c|h)$"
+                    },
+                    "tests": {
+                        "paths": ["test"],
+                        "matchingPattern": ".*_test\\.c$"
+                    }
+                }
+            ]
+        }
+    ]
+}
+`,
+	"certdocs/{{ .Prefix }}-100-ORD.md": `# Overall Requirements Document for {{ .RepoName }}
+
+## Introduction
+
+The system requirements describe what {{ .RepoName }} must achieve as a whole.
+
+## System Requirements
+
+### REQ-{{ .Prefix }}-SYS-1 Temperature monitoring
+
+The system SHALL warn the operator when the temperature is outside of the operating range.
+
+#### Attributes:
+- Rationale: Operating outside of the temperature range damages the equipment.
+- Verification: Test
+`,
+	"certdocs/{{ .Prefix }}-137-SRD.md": `# Software Requirements Document for {{ .RepoName }}
+
+## Introduction
+
+The high-level software requirements refine the system requirements of the ORD.
+
+## High-level Software Requirements
+
+### REQ-{{ .Prefix }}-SWH-1 Temperature range check
+
+The software SHALL report whether a temperature reading is within the operating range of 0 to 50 degrees Celsius.
+
+#### Attributes:
+- Parents: REQ-{{ .Prefix }}-SYS-1
+- Rationale: The warning is raised from the result of the check.
+- Verification: Test
+`,
+	"certdocs/{{ .Prefix }}-138-SDD.md": `# Software Design Document for {{ .RepoName }}
+
+## Introduction
+
+The low-level software requirements refine the high-level software requirements of the SRD and are implemented
+and tested by the code in ` + "`src`" + ` and ` + "`test`" + `.
+
+## Low-level Software Requirements
+
+### REQ-{{ .Prefix }}-SWL-1 Range limits
+
+The function ` + "`in_range`" + ` SHALL return true for temperatures from 0 to 50 degrees Celsius, limits included, and false otherwise.
+
+#### Attributes:
+- Parents: REQ-{{ .Prefix }}-SWH-1
+- Rationale: The limits are part of the operating range.
+- Verification: Test
+`,
+	"src/range.h": `#ifndef RANGE_H
+#define RANGE_H
+
+#include <stdbool.h>
+
+bool in_range(int celsius);
+
+#endif
+`,
+	"src/range.c": `#include "range.h"
+
+// Returns whether the temperature is within the operating range
+// @llr REQ-{{ .Prefix }}-SWL-1
+bool in_range(int celsius) {
+  return celsius >= 0 && celsius <= 50;
+}
+`,
+	"test/range_test.c": `#include <assert.h>
+
+#include "../src/range.h"
+
+// @llr REQ-{{ .Prefix }}-SWL-1
+void test_in_range(void) {
+  assert(in_range(0));
+  assert(in_range(50));
+  assert(!in_range(-1));
+  assert(!in_range(51));
+}
+
+int main(void) {
+  test_in_range();
+  return 0;
+}
+`,
+}
+
+// Registers the init command
+// @llr REQ-TRAQ-SWL-119
+func init() {
+	initName = initCmd.Flags().String("name", "", "The name of the repository. Defaults to the name of its directory.")
+	initPrefix = initCmd.Flags().String("prefix", "EX", "The prefix of the requirement IDs, in upper case.")
+	rootCmd.AddCommand(initCmd)
+}
+
+// writeExampleProject writes the files of the example project in the given directory and returns their paths.
+// Nothing is written if any of the files exists already.
+// @llr REQ-TRAQ-SWL-119
+func writeExampleProject(dir string, data initData) ([]string, error) {
+	if !initPrefixRegexp.MatchString(data.Prefix) {
+		return nil, fmt.Errorf("Invalid requirement prefix `%s`, expected upper case letters and digits", data.Prefix)
+	}
+
+	files := make(map[string]string)
+	for pathTemplate, contentTemplate := range initTemplates {
+		var path, content strings.Builder
+		if err := template.Must(template.New("path").Parse(pathTemplate)).Execute(&path, data); err != nil {
+			return nil, err
+		}
+		if err := template.Must(template.New("content").Parse(contentTemplate)).Execute(&content, data); err != nil {
+			return nil, err
+		}
+		files[path.String()] = content.String()
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(dir, path)); err == nil {
+			return nil, fmt.Errorf("File `%s` already exists", path)
+		}
+	}
+
+	for _, path := range paths {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(fullPath, []byte(files[path]), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// runInitCmd creates the example project at the root of the current git repository
+// @llr REQ-TRAQ-SWL-119
+func runInitCmd(command *cobra.Command, args []string) error {
+	toplevel, err := linepipes.Single(linepipes.Run("git", "-C", *fRepoPath, "rev-parse", "--show-toplevel"))
+	if err != nil {
+		return errors.Wrap(err, "reqtraq must be initialized in a git repository")
+	}
+
+	data := initData{RepoName: *initName, Prefix: *initPrefix}
+	if data.RepoName == "" {
+		data.RepoName = filepath.Base(toplevel)
+	}
+	paths, err := writeExampleProject(toplevel, data)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Println("Created", path)
+	}
+	fmt.Println("Run `reqtraq validate` to check the traceability of the example requirements")
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// @llr REQ-TRAQ-SWL-119
+func TestInit_ExampleProjectValidates(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := writeExampleProject(dir, initData{RepoName: "example", Prefix: "EX"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"certdocs/EX-100-ORD.md",
+		"certdocs/EX-137-SRD.md",
+		"certdocs/EX-138-SDD.md",
+		"reqtraq_config.json",
+		"src/range.c",
+		"src/range.h",
+		"test/range_test.c",
+	}, paths)
+
+	repos.RegisterRepository("example", repos.RepoPath(dir))
+	cfg, err := config.ParseConfig(repos.RepoPath(dir))
+	require.NoError(t, err)
+	rg, err := reqs.BuildGraph(&cfg)
+	require.NoError(t, err)
+	assert.Empty(t, rg.Issues)
+	assert.Len(t, rg.Reqs, 3)
+	require.Contains(t, rg.Reqs, "REQ-EX-SWL-1")
+	var tagged []string
+	for _, tag := range rg.Reqs["REQ-EX-SWL-1"].Tags {
+		tagged = append(tagged, tag.CodeFile.Path+":"+tag.Tag)
+	}
+	assert.ElementsMatch(t, []string{"src/range.c:in_range", "test/range_test.c:test_in_range"}, tagged)
+
+	// Existing files are not overwritten
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "src", "range.c"), []byte("// mine\n"), 0644))
+	_, err = writeExampleProject(dir, initData{RepoName: "example", Prefix: "EX"})
+	assert.EqualError(t, err, "File `certdocs/EX-100-ORD.md` already exists")
+
+	_, err = writeExampleProject(t.TempDir(), initData{RepoName: "example", Prefix: "ex-1"})
+	assert.EqualError(t, err, "Invalid requirement prefix `ex-1`, expected upper case letters and digits")
+}
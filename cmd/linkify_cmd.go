@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+var linkifyCheck *bool
+var linkifyRewrite rewriteFlags
+
+var linkifyCmd = &cobra.Command{
+	Use:   "linkify [CERTDOC_PATH ...]",
+	Short: "Links the parents of the requirements to their definition in the certification documents",
+	Long: `Rewrites the certification documents of the current repository, or only the given ones, so the
+requirement IDs in the Parents attributes link to the definition of the parent requirements. The links are
+relative, so the documents can be browsed in git web interfaces. Running it again updates the links.
+With --dry-run or --diff the documents are not modified and the command fails if any of them is not linkified.`,
+	ValidArgsFunction: completeCertdocFilename,
+	RunE:              RunAndHandleError(runLinkifyCmd),
+}
+
+// Registers the linkify command
+// @llr REQ-TRAQ-SWL-102, REQ-TRAQ-SWL-122
+func init() {
+	linkifyCheck = linkifyCmd.Flags().Bool("check", false, "Only check that the documents are linkified, without modifying them.")
+	linkifyRewrite = addRewriteFlags(linkifyCmd)
+	rootCmd.AddCommand(linkifyCmd)
+}
+
+// runLinkifyCmd rewrites the given certification documents, or all of the current repository, with the
+// parents of the requirements linked to their definition
+// @llr REQ-TRAQ-SWL-102, REQ-TRAQ-SWL-122, REQ-TRAQ-SWL-130
+func runLinkifyCmd(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	repoName := repos.BaseRepoName()
+	var documents []*config.Document
+	if len(args) == 0 {
+		for i := range reqtraqConfig.Repos[repoName].Documents {
+			documents = append(documents, &reqtraqConfig.Repos[repoName].Documents[i])
+		}
+	}
+	for _, filename := range args {
+		if docRepoName, certdocConfig := reqtraqConfig.FindCertdoc(filename); certdocConfig == nil || docRepoName != repoName {
+			return fmt.Errorf("Could not find document `%s` in the list of documents of the current repository", filename)
+		} else {
+			documents = append(documents, certdocConfig)
+		}
+	}
+
+	outdated := 0
+	for _, doc := range documents {
+		// Documents split in several files are linkified one fragment at a time
+		for _, file := range doc.Files() {
+			path, err := repos.PathInRepo(repoName, file)
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			linked, err := rg.Linkify(repoName, doc, file)
+			if err != nil {
+				return errors.Wrapf(err, "linkify `%s`", file)
+			}
+			if linked == string(content) {
+				continue
+			}
+
+			outdated++
+			if *linkifyCheck {
+				fmt.Printf("Document %s is not linkified\n", file)
+				continue
+			}
+			if _, err := linkifyRewrite.rewriteDocument(os.Stdout, path, file, string(content), linked); err != nil {
+				return err
+			}
+			if !linkifyRewrite.preview() {
+				fmt.Printf("Linkified %s\n", file)
+			}
+		}
+	}
+
+	if *linkifyCheck && outdated > 0 {
+		return fmt.Errorf("%d documents are not linkified", outdated)
+	}
+	return linkifyRewrite.checkPreview(outdated)
+}
@@ -64,8 +64,9 @@ func runListCmd(command *cobra.Command, args []string) error {
 }
 
 // printConcise prints to stdout the requirements which match the filter in a concise format (id, title and first line of body text)
-// @llr REQ-TRAQ-SWL-33, REQ-TRAQ-SWL-73
+// @llr REQ-TRAQ-SWL-33, REQ-TRAQ-SWL-73, REQ-TRAQ-SWL-127
 func printConcise(reqs []*reqs.Req, filter reqs.ReqFilter) {
+	out := newConsole(os.Stdout)
 	for _, r := range reqs {
 		if !filter.IsEmpty() && !r.Matches(&filter) {
 			continue
@@ -78,12 +79,16 @@ func printConcise(reqs []*reqs.Req, filter reqs.ReqFilter) {
 			}
 			body = append(body, line)
 		}
-		fmt.Printf("Requirement %s %s\n", r.ID, r.Title)
+		id := r.ID
+		if r.Document != nil {
+			id = out.link(r.RepoName, r.Document.Path, id)
+		}
+		fmt.Fprintf(out.w, "Requirement %s %s\n", out.style(ansiBold, id), r.Title)
 		// Check for empty body because deleted requirements have no body.
 		if len(body) > 0 {
-			fmt.Printf("%s…\n", body[0])
+			fmt.Fprintf(out.w, "%s…\n", body[0])
 		}
-		fmt.Println()
+		fmt.Fprintln(out.w)
 	}
 }
 
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/lsp"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Args:  cobra.NoArgs,
+	Short: "Starts a language server for editors, communicating over the standard input and output",
+	Long: `Starts a language server implementing the Language Server Protocol over the standard input and output, for
+editor integration: go to the definition of the requirement referenced by an @llr tag, show the title and body of
+the requirement when hovering the tag, and show the issues found by validate as diagnostics. The requirements graph
+is rebuilt when a file is saved.`,
+	RunE: RunAndHandleError(runLspCmd),
+}
+
+// Registers the lsp command
+// @llr REQ-TRAQ-SWL-179
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+// runLspCmd serves the editor on the standard input and output until it asks the server to exit
+// @llr REQ-TRAQ-SWL-179
+func runLspCmd(command *cobra.Command, args []string) error {
+	// The messages of the protocol are written to stdout, the progress messages of the graph builds go to stderr
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	if err := setupConfiguration(); err != nil {
+		return errors.Wrap(err, "setup configuration")
+	}
+	server := lsp.NewServer(func() (*reqs.ReqGraph, error) {
+		return reqs.BuildGraph(reqtraqConfig)
+	})
+	return server.Serve(os.Stdin, stdout)
+}
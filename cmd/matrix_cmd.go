@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/matrix"
+	"github.com/pkg/errors"
+)
+
+var matrixPrefix *string
+var matrixFormat *string
+
+var matrixFileNameRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+var matrixCmd = &cobra.Command{
+	Use:   "matrix [graph.json ...]",
+	Short: "Creates the trace matrices and a JSON summary of their gaps",
+	Long: `Creates an HTML file with the trace matrices between each pair of linked documents and between each document
+with implementation and its code, tests and model elements, if any, as shown in the web interface, and the matrix of
+the assumptions with their owning requirements, validation plans and checking code. With --format, the matrices are
+written as CSV files or Excel workbooks instead. A JSON summary of the gaps in all of them, such as requirements
+without children or code without parents, is also written to <pfx>matrix-gaps.json.`,
+	RunE: RunAndHandleError(runMatrixCmd),
+}
+
+// Registers the matrix command
+// @llr REQ-TRAQ-SWL-99, REQ-TRAQ-SWL-187
+func init() {
+	matrixPrefix = matrixCmd.Flags().String("pfx", "./req-", "Path and filename prefix for the matrices.")
+	matrixFormat = matrixCmd.Flags().String("format", matrix.FormatHTML, "Format of the matrices: html, csv or xlsx.")
+	rootCmd.AddCommand(matrixCmd)
+}
+
+// matrixFileName returns the name of the file where the matrix between the given items is written, with the
+// extension of the output format
+// @llr REQ-TRAQ-SWL-99, REQ-TRAQ-SWL-187
+func matrixFileName(from, to string) string {
+	name := fmt.Sprintf("%s %s", from, to)
+	return fmt.Sprintf("%smatrix-%s.%s", *matrixPrefix, strings.Trim(matrixFileNameRegexp.ReplaceAllString(name, "-"), "-"), *matrixFormat)
+}
+
+// writeMatrix writes a single matrix file generated from the given inputs using the given generator
+// @llr REQ-TRAQ-SWL-99, REQ-TRAQ-SWL-128
+func writeMatrix(fileName string, kind string, inputs []string, generate func(of *os.File) error) error {
+	of, err := createArtifact(fileName, kind, "matrix", inputs, nil)
+	if err != nil {
+		return err
+	}
+	log.Print("Creating ", of.Name())
+	if err := generate(of.File); err != nil {
+		of.File.Close()
+		return err
+	}
+	return of.Close()
+}
+
+// runMatrixCmd creates a requirements graph and writes the trace matrices and the JSON summary of their gaps
+// @llr REQ-TRAQ-SWL-99, REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-167, REQ-TRAQ-SWL-176, REQ-TRAQ-SWL-187
+func runMatrixCmd(command *cobra.Command, args []string) error {
+	if err := matrix.CheckFormat(*matrixFormat); err != nil {
+		return err
+	}
+	rg, err := loadReqGraph(args)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	for _, link := range rg.ReqtraqConfig.GetLinkedSpecs() {
+		link := link
+		err := writeMatrix(matrixFileName(link.Parent.String(), link.Child.String()), "matrix", graphInputs(args), func(of *os.File) error {
+			return matrix.GenerateTraceTables(rg, of, *matrixFormat, link.Parent, link.Child)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, spec := range matrix.CodeReqSpecs(rg.ReqtraqConfig) {
+		for _, codeType := range []code.CodeType{code.CodeTypeImplementation, code.CodeTypeTests} {
+			spec, codeType := spec, codeType
+			err := writeMatrix(matrixFileName(spec.String(), codeType.String()), "matrix", graphInputs(args), func(of *os.File) error {
+				return matrix.GenerateCodeTraceTables(rg, of, *matrixFormat, spec, codeType)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(rg.ModelElements) > 0 {
+			err := writeMatrix(matrixFileName(spec.String(), matrix.ModelColumn), "matrix", graphInputs(args), func(of *os.File) error {
+				return matrix.GenerateModelTraceTables(rg, of, *matrixFormat, spec)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(rg.AssumptionValidations()) > 0 {
+		err := writeMatrix(*matrixPrefix+"matrix-assumptions."+*matrixFormat, "matrix", graphInputs(args), func(of *os.File) error {
+			return matrix.GenerateAssumptionTable(rg, of, *matrixFormat)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeMatrix(*matrixPrefix+"matrix-gaps.json", "matrix-gaps", graphInputs(args), func(of *os.File) error {
+		return matrix.WriteGapsJSON(of, matrix.AllTraceGaps(rg))
+	})
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var fMigrateStub *bool
+var migrateRewrite rewriteFlags
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [CERTDOC_PATH ...]",
+	Short: "Helps migrating the certification documents to a new version of their schema",
+	Long: `Lists, for each certification document of the current repository or only the given ones, the requirements
+missing required attributes of the schema, e.g. after a new required attribute was configured, with the schema
+version recorded in the document and the one of the configuration. With --stub, a TODO placeholder is added for each
+missing attribute of the requirements defined in headings, and the schema version of the configuration is recorded
+in the documents with a <!-- reqtraq:schema-version N --> marker. With --dry-run or --diff the documents are not
+modified and the command fails if any of them would be.`,
+	ValidArgsFunction: completeCertdocFilename,
+	RunE:              RunAndHandleError(runMigrateCmd),
+}
+
+// Registers the migrate command
+// @llr REQ-TRAQ-SWL-164, REQ-TRAQ-SWL-122
+func init() {
+	fMigrateStub = migrateCmd.Flags().Bool("stub", false, "Add TODO placeholders for the missing attributes and record the schema version in the documents.")
+	migrateRewrite = addRewriteFlags(migrateCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// runMigrateCmd lists the requirements missing attributes in the given certification documents, or all of the
+// current repository, and adds placeholders for them if requested
+// @llr REQ-TRAQ-SWL-164, REQ-TRAQ-SWL-122
+func runMigrateCmd(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	repoName := repos.BaseRepoName()
+	var documents []*config.Document
+	if len(args) == 0 {
+		for i := range reqtraqConfig.Repos[repoName].Documents {
+			documents = append(documents, &reqtraqConfig.Repos[repoName].Documents[i])
+		}
+	}
+	for _, filename := range args {
+		if docRepoName, certdocConfig := reqtraqConfig.FindCertdoc(filename); certdocConfig == nil || docRepoName != repoName {
+			return fmt.Errorf("Could not find document `%s` in the list of documents of the current repository", filename)
+		} else {
+			documents = append(documents, certdocConfig)
+		}
+	}
+
+	printMigration(newConsole(os.Stdout), rg, repoName, documents)
+	if !*fMigrateStub {
+		return nil
+	}
+
+	changed := 0
+	for _, doc := range documents {
+		// Documents split in several files are migrated one fragment at a time
+		for _, file := range doc.Files() {
+			path, err := repos.PathInRepo(repoName, file)
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			stubbed, tables, err := reqs.StubMissingAttributes(repoName, doc, string(content))
+			if err != nil {
+				return errors.Wrapf(err, "migrate `%s`", file)
+			}
+			for _, r := range tables {
+				fmt.Printf("%s:%d: requirement %s is defined in a table, add its missing attributes as columns\n", file, r.Position, r.ID)
+			}
+			modified, err := migrateRewrite.rewriteDocument(os.Stdout, path, file, string(content), stubbed)
+			if err != nil {
+				return err
+			}
+			if modified {
+				changed++
+				if !migrateRewrite.preview() {
+					fmt.Printf("Migrated %s\n", file)
+				}
+			}
+		}
+	}
+	return migrateRewrite.checkPreview(changed)
+}
+
+// printMigration prints, for each document, its schema versions and its requirements missing required attributes,
+// with the names of the attributes
+// @llr REQ-TRAQ-SWL-164
+func printMigration(out console, rg *reqs.ReqGraph, repoName repos.RepoName, documents []*config.Document) {
+	missing := make(map[*config.Document][]*reqs.Req)
+	versions := make(map[*config.Document]int)
+	for _, r := range rg.Reqs {
+		if r.RepoName != repoName || r.Document == nil || r.IsDeleted() {
+			continue
+		}
+		versions[r.Document] = r.SchemaVersion
+		if len(r.MissingAttributes()) > 0 {
+			missing[r.Document] = append(missing[r.Document], r)
+		}
+	}
+
+	for _, doc := range documents {
+		requirements := missing[doc]
+		sort.Slice(requirements, func(i, j int) bool { return requirements[i].Position < requirements[j].Position })
+		fmt.Fprintf(out.w, "%s (schema version %d of %d): %d requirements missing attributes\n",
+			out.style(ansiBold, doc.Path), versions[doc], doc.Schema.Version, len(requirements))
+		for _, r := range requirements {
+			fmt.Fprintf(out.w, "  %s %s\n", out.link(repoName, doc.Path, r.ID), strings.Join(r.MissingAttributes(), ", "))
+		}
+	}
+}
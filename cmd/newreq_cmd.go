@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	newReqDoc    *string
+	newReqParent *string
+	newReqTitle  *string
+
+	newReqRewrite rewriteFlags
+)
+
+var newReqCmd = &cobra.Command{
+	Use:   "newreq --doc CERTDOC_PATH [--parent PARENT_ID] [--title TITLE]",
+	Args:  cobra.NoArgs,
+	Short: "Adds a new requirement to a certification document",
+	Long: `Inserts the skeleton of a new requirement in a certification document of the current repository, with the next
+available ID and placeholders for its body and for the attributes required by the schema of the document. The
+requirement is added after the last requirement with the same parent or, if there is none, at the end of the document.
+With --dry-run or --diff the document is not modified and the command fails.`,
+	RunE: RunAndHandleError(runNewReqCmd),
+}
+
+// Registers the newreq command
+// @llr REQ-TRAQ-SWL-113, REQ-TRAQ-SWL-122
+func init() {
+	newReqDoc = newReqCmd.Flags().String("doc", "", "The certification document to add the requirement to.")
+	newReqParent = newReqCmd.Flags().String("parent", "", "The ID of the parent of the new requirement.")
+	newReqTitle = newReqCmd.Flags().String("title", "", "The title of the new requirement. A placeholder is used when empty.")
+	newReqRewrite = addRewriteFlags(newReqCmd)
+	_ = newReqCmd.MarkFlagRequired("doc")
+	_ = newReqCmd.RegisterFlagCompletionFunc("doc", completeCertdocFilename)
+	rootCmd.AddCommand(newReqCmd)
+}
+
+// runNewReqCmd inserts a new requirement in the given certification document
+// @llr REQ-TRAQ-SWL-113, REQ-TRAQ-SWL-122, REQ-TRAQ-SWL-130
+func runNewReqCmd(command *cobra.Command, args []string) error {
+	if err := setupConfiguration(); err != nil {
+		return err
+	}
+
+	repoName := repos.BaseRepoName()
+	docRepoName, certdocConfig := reqtraqConfig.FindCertdoc(*newReqDoc)
+	if certdocConfig == nil || docRepoName != repoName {
+		return fmt.Errorf("Could not find document `%s` in the list of documents of the current repository", *newReqDoc)
+	}
+
+	if len(certdocConfig.Fragments) > 0 {
+		return newReqInFragments(repoName, certdocConfig)
+	}
+
+	path, err := repos.PathInRepo(repoName, certdocConfig.Path)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	updated, id, line, err := reqs.InsertReqSkeleton(repoName, certdocConfig, string(content), *newReqTitle, *newReqParent)
+	if err != nil {
+		return errors.Wrapf(err, "add requirement to `%s`", certdocConfig.Path)
+	}
+	return writeNewReq(path, certdocConfig.Path, string(content), updated, id, line)
+}
+
+// newReqInFragments inserts a new requirement in a document split in several files. The requirement is inserted
+// in the concatenation of the fragments, and the fragment containing the line it follows is rewritten.
+// @llr REQ-TRAQ-SWL-113, REQ-TRAQ-SWL-130
+func newReqInFragments(repoName repos.RepoName, certdocConfig *config.Document) error {
+	content, certdoc, err := reqs.ReadDocument(repoName, certdocConfig)
+	if err != nil {
+		return err
+	}
+	updated, id, line, err := reqs.InsertReqSkeleton(repoName, certdocConfig, content, *newReqTitle, *newReqParent)
+	if err != nil {
+		return errors.Wrapf(err, "add requirement to `%s`", certdocConfig.Path)
+	}
+
+	// The skeleton starts with an empty line, inserted after the line preceding the heading
+	after := line - 2
+	if after < 1 {
+		after = 1
+	}
+	fragmentPath, _ := certdoc.Locate(after)
+	start := certdoc.FragmentStart(fragmentPath)
+	fragmentLines := 0
+	for _, fragment := range certdoc.Fragments {
+		if fragment.Path == fragmentPath {
+			fragmentLines = fragment.Lines
+		}
+	}
+	updatedLines := strings.Split(updated, "\n")
+	inserted := len(updatedLines) - len(strings.Split(content, "\n"))
+	fragmentUpdated := strings.Join(updatedLines[start:start+fragmentLines+inserted], "\n") + "\n"
+
+	path, err := repos.PathInRepo(repoName, fragmentPath)
+	if err != nil {
+		return err
+	}
+	fragmentContent, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(string(fragmentContent), "\n\n") {
+		// The empty line separating the requirement from the following fragment is not kept at the end of the file
+		fragmentUpdated = strings.TrimRight(fragmentUpdated, "\n") + "\n"
+	}
+	return writeNewReq(path, fragmentPath, string(fragmentContent), fragmentUpdated, id, line-start)
+}
+
+// writeNewReq writes the file with the new requirement, or previews the change, and reports where it was added
+// @llr REQ-TRAQ-SWL-113, REQ-TRAQ-SWL-122
+func writeNewReq(path, displayPath, content, updated, id string, line int) error {
+	if _, err := newReqRewrite.rewriteDocument(os.Stdout, path, displayPath, content, updated); err != nil {
+		return err
+	}
+	if newReqRewrite.preview() {
+		fmt.Printf("Would add %s to %s:%d\n", id, displayPath, line)
+		return newReqRewrite.checkPreview(1)
+	}
+	fmt.Printf("Added %s to %s:%d\n", id, displayPath, line)
+	return nil
+}
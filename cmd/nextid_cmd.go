@@ -19,14 +19,8 @@ var nextIdCmd = &cobra.Command{
 }
 
 // runNextId parses a single markdown document for requirements and returns the next available ID
-// @llr REQ-TRAQ-SWL-34
+// @llr REQ-TRAQ-SWL-34, REQ-TRAQ-SWL-93
 func runNextId(command *cobra.Command, args []string) error {
-	var (
-		requirements  []*reqs.Req
-		greatestReqID int = 0
-		greatestAsmID int = 0
-	)
-
 	if err := setupConfiguration(); err != nil {
 		return err
 	}
@@ -44,20 +38,13 @@ func runNextId(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	// count existing REQ and ASM IDs
-	for _, r := range requirements {
-		if r.Variant == reqs.ReqVariantRequirement && r.IDNumber > greatestReqID {
-			greatestReqID = r.IDNumber
-		} else if r.Variant == reqs.ReqVariantAssumption && r.IDNumber > greatestAsmID {
-			greatestAsmID = r.IDNumber
-		}
-	}
-
-	fmt.Printf("REQ-%s-%s-%d\n", certdocConfig.ReqSpec.Prefix, certdocConfig.ReqSpec.Level, greatestReqID+1)
+	idFormat := certdocConfig.ReqSpec.Format()
+	fmt.Println(idFormat.Format("REQ", certdocConfig.ReqSpec.Prefix, certdocConfig.ReqSpec.Level,
+		reqs.NextIDNumber(requirements, reqs.ReqVariantRequirement)))
 
 	// don't bother reporting assumptions if none are defined yet
-	if greatestAsmID > 0 {
-		fmt.Printf("ASM-%s-%s-%d\n", certdocConfig.ReqSpec.Prefix, certdocConfig.ReqSpec.Level, greatestAsmID+1)
+	if nextAsmID := reqs.NextIDNumber(requirements, reqs.ReqVariantAssumption); nextAsmID > 1 {
+		fmt.Println(idFormat.Format("ASM", certdocConfig.ReqSpec.Prefix, certdocConfig.ReqSpec.Level, nextAsmID))
 	}
 
 	return nil
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/report"
+	"github.com/pkg/errors"
+)
+
+var (
+	fPublishFormat    *string
+	fPublishTitle     *string
+	fPublishSourceURL *string
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish OUT_DIR",
+	Args:  cobra.ExactArgs(1),
+	Short: "Publishes the requirements as a static documentation site",
+	Long: `Renders the requirements graph as a static documentation site, with one page per document and per requirement,
+a navigation sidebar listing the documents and their requirements, and a search of the requirements. With
+--format=html, plain HTML pages are written, which can be served as they are, e.g. by an internal pages server on
+each merge to the main branch. With --format=mkdocs, a MkDocs project is written instead, with the markdown pages in
+the docs directory and the navigation in mkdocs.yml, to be built with the theme of the pages server.`,
+	RunE: RunAndHandleError(runPublish),
+}
+
+// Registers the publish command
+// @llr REQ-TRAQ-SWL-151
+func init() {
+	fPublishFormat = publishCmd.Flags().String("format", report.SiteFormatHTML, "The format of the site, `html` or `mkdocs`.")
+	fPublishTitle = publishCmd.Flags().String("title", "", "The title of the site. The name of the current repository is used when empty.")
+	fPublishSourceURL = publishCmd.Flags().String("source-url", "", "Template of the links to the source files in the pages, with the {repo}, {path} and {line} placeholders.")
+	rootCmd.AddCommand(publishCmd)
+}
+
+// runPublish writes the site of the requirements to the given directory
+// @llr REQ-TRAQ-SWL-151
+func runPublish(command *cobra.Command, args []string) error {
+	if *fPublishFormat != report.SiteFormatHTML && *fPublishFormat != report.SiteFormatMkDocs {
+		return fmt.Errorf("Unknown site format `%s`, expected `html` or `mkdocs`", *fPublishFormat)
+	}
+
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	title := *fPublishTitle
+	if title == "" {
+		title = fmt.Sprintf("%s requirements", rg.ReqtraqConfig.TargetRepo)
+	}
+	site := report.Site{Title: title, Format: *fPublishFormat, SourceURL: *fPublishSourceURL}
+	if err := site.Publish(rg, args[0]); err != nil {
+		return errors.Wrap(err, "publish site")
+	}
+	return nil
+}
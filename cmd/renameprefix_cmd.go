@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	renamePrefixLocalRepos *map[string]string
+
+	renamePrefixRewrite rewriteFlags
+)
+
+var renamePrefixCmd = &cobra.Command{
+	Use:   "rename-prefix OLD NEW [--local-repo NAME=PATH ...]",
+	Args:  cobra.ExactArgs(2),
+	Short: "Renames the prefix of the requirement IDs in all the repositories",
+	Long: `Renames the prefix of the IDs of the requirements, assumptions and flow tags in the certification documents,
+the source code and the configuration files of all the configured repositories, e.g. when a project is rebranded.
+The other repositories are only rewritten when their local checkout is given with --local-repo, otherwise they are
+listed as skipped. All the files are rewritten only once the renaming succeeded for all of them. With --dry-run or
+--diff the files are not modified and the command fails if any of them would be.`,
+	RunE: RunAndHandleError(runRenamePrefixCmd),
+}
+
+// Registers the rename-prefix command
+// @llr REQ-TRAQ-SWL-141, REQ-TRAQ-SWL-122
+func init() {
+	renamePrefixLocalRepos = renamePrefixCmd.Flags().StringToString("local-repo", nil, "The local checkout of another configured repository to rewrite, as NAME=PATH.")
+	renamePrefixRewrite = addRewriteFlags(renamePrefixCmd)
+	rootCmd.AddCommand(renamePrefixCmd)
+}
+
+// renamedFile is a file of a repository whose content changes when renaming the prefix
+type renamedFile struct {
+	repoName repos.RepoName
+	path     string
+	original string
+	updated  string
+	renamed  int
+}
+
+// runRenamePrefixCmd renames the prefix of the requirement IDs in the files of all the repositories available locally
+// @llr REQ-TRAQ-SWL-141, REQ-TRAQ-SWL-122, REQ-TRAQ-SWL-130
+func runRenamePrefixCmd(command *cobra.Command, args []string) error {
+	// The local checkouts are registered before the configuration is parsed, so they are not cloned
+	local := map[repos.RepoName]bool{}
+	for name, path := range *renamePrefixLocalRepos {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		repos.RegisterRepository(repos.RepoName(name), repos.RepoPath(absPath))
+		local[repos.RepoName(name)] = true
+	}
+
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+	local[repos.BaseRepoName()] = true
+	renaming, err := reqs.NewPrefixRenaming(reqtraqConfig, config.ReqPrefix(args[0]), config.ReqPrefix(args[1]))
+	if err != nil {
+		return err
+	}
+
+	repoNames := make([]repos.RepoName, 0, len(reqtraqConfig.Repos))
+	for repoName := range reqtraqConfig.Repos {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Slice(repoNames, func(i, j int) bool { return repoNames[i] < repoNames[j] })
+
+	// All the files are renamed before any of them is written, so a failure leaves the repositories untouched
+	var files []renamedFile
+	for _, repoName := range repoNames {
+		if !local[repoName] {
+			fmt.Printf("Skipped repository %s, give its local checkout with --local-repo\n", repoName)
+			continue
+		}
+		paths := map[string]bool{"reqtraq_config.json": true}
+		for _, doc := range reqtraqConfig.Repos[repoName].Documents {
+			for _, file := range doc.Files() {
+				paths[file] = true
+			}
+		}
+		for _, tag := range rg.CodeTags[repoName] {
+			paths[tag.CodeFile.Path] = true
+		}
+		sorted := make([]string, 0, len(paths))
+		for path := range paths {
+			sorted = append(sorted, path)
+		}
+		sort.Strings(sorted)
+
+		for _, path := range sorted {
+			fullPath, err := repos.PathInRepo(repoName, path)
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(fullPath)
+			if err != nil {
+				return err
+			}
+			file := renamedFile{repoName: repoName, path: path, original: string(content)}
+			if path == "reqtraq_config.json" {
+				file.updated, file.renamed = renaming.RenameConfig(file.original)
+			} else {
+				file.updated, file.renamed = renaming.Rename(file.original)
+			}
+			if file.renamed > 0 {
+				files = append(files, file)
+			}
+		}
+	}
+
+	for _, file := range files {
+		fullPath, err := repos.PathInRepo(file.repoName, file.path)
+		if err != nil {
+			return err
+		}
+		if _, err := renamePrefixRewrite.rewriteDocument(os.Stdout, fullPath, file.path, file.original, file.updated); err != nil {
+			return err
+		}
+		verb := "Renamed"
+		if renamePrefixRewrite.preview() {
+			verb = "Would rename"
+		}
+		fmt.Printf("%s %d occurrences of %s in %s:%s\n", verb, file.renamed, args[0], file.repoName, file.path)
+	}
+	return renamePrefixRewrite.checkPreview(len(files))
+}
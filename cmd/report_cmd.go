@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/code"
 	"github.com/daedaleanai/reqtraq/report"
+	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/pkg/errors"
 )
@@ -16,8 +24,18 @@ var (
 	reportTitleFilter     *string
 	reportBodyFilter      *string
 	reportAttributeFilter *[]string
+	reportBadges          *bool
+	reportSplitBy         *string
+	reportRepo            *string
+	reportHistory         []string
+	reportCodeRepo        *string
+	reportCodePath        *string
+	reportCodeType        *string
+	reportCollapseFiles   *bool
 )
 
+var reportFileNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
 var reportCmd = &cobra.Command{
 	Use:   "report",
 	Short: "Creates an HTML traceability report",
@@ -44,31 +62,74 @@ var reportIssuesCmd = &cobra.Command{
 	RunE:  RunAndHandleError(runReportIssuesCmd),
 }
 
+var reportReviewsCmd = &cobra.Command{
+	Use:   "reviews [graph.json ...]",
+	Short: "Creates an HTML report with the open review comments of each requirement",
+	Long:  "Creates an HTML report with the open review comments of each requirement",
+	RunE:  RunAndHandleError(runReportReviewsCmd),
+}
+
+var reportSummaryCmd = &cobra.Command{
+	Use:   "summary [graph.json ...]",
+	Short: "Creates HTML and JSON reports with the coverage figures of each document and requirement level",
+	Long:  "Creates HTML and JSON reports with the number of requirements of each document and requirement level, the percentages implemented, tested and with parents, the number of deleted requirements and of open issues",
+	RunE:  RunAndHandleError(runReportSummaryCmd),
+}
+
+var reportTrendCmd = &cobra.Command{
+	Use:   "trend HISTORY_FILE",
+	Args:  cobra.ExactArgs(1),
+	Short: "Creates an HTML report showing the evolution of the completeness scores",
+	Long:  "Creates an HTML report showing the evolution of the completeness scores recorded with `validate --score-history`",
+	RunE:  RunAndHandleError(runReportTrendCmd),
+}
+
 // Registers the report commands
-// @llr REQ-TRAQ-SWL-35
+// @llr REQ-TRAQ-SWL-35, REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-116, REQ-TRAQ-SWL-137, REQ-TRAQ-SWL-160, REQ-TRAQ-SWL-181, REQ-TRAQ-SWL-190
 func init() {
 	reportPrefix = reportCmd.PersistentFlags().String("pfx", "./req-", "Path and filename prefix for reports.")
 	reportIdFilter = reportCmd.PersistentFlags().String("id", "", "Regular expression to filter by requirement id.")
 	reportTitleFilter = reportCmd.PersistentFlags().String("title", "", "Regular expression to filter by requirement title.")
 	reportBodyFilter = reportCmd.PersistentFlags().String("body", "", "Regular expression to filter by requirement body.")
 	reportAttributeFilter = reportCmd.PersistentFlags().StringSlice("attribute", nil, "Regular expression to filter by requirement attribute.")
+	reportBadges = reportCmd.PersistentFlags().Bool("badges", false, "Also write SVG badges with the traceability statistics next to the report.")
+	reportCmd.PersistentFlags().BoolVar(&report.CodeExcerpts, "code-excerpts", false, "Show the comment block and the signature of the tagged functions under their links.")
+
+	for _, c := range []*cobra.Command{reportDownCmd, reportUpCmd} {
+		c.Flags().StringSliceVar(&reportHistory, "attribute-history", nil, "Attributes whose changes over the git history of the documents are shown, e.g. `STATUS,SAFETY IMPACT`.")
+	}
+	reportRepo = reportIssuesCmd.Flags().String("repo-name", "", "Only report the issues found in the given repository.")
+	reportSplitBy = reportIssuesCmd.Flags().String("split-by", "", "Also write one issues report per value of the given attribute, named <pfx>issues-<value>.html.")
+	reportCodeRepo = reportUpCmd.Flags().String("code-repo", "", "Only list the code of the given repository.")
+	reportCodePath = reportUpCmd.Flags().String("code-path", "", "Only list the code files whose path, or one of its directories, matches the given glob, e.g. `src/*`.")
+	reportCodeType = reportUpCmd.Flags().String("code-type", "any", "Only list the code of the given type: `impl`, `test` or `any`.")
+	reportCollapseFiles = reportUpCmd.Flags().Bool("collapse-files", false, "List the functions of each code file together, with the requirements of all of them.")
 
 	reportCmd.AddCommand(reportUpCmd)
 	reportCmd.AddCommand(reportDownCmd)
 	reportCmd.AddCommand(reportIssuesCmd)
+	reportCmd.AddCommand(reportReviewsCmd)
+	reportCmd.AddCommand(reportSummaryCmd)
+	reportCmd.AddCommand(reportTrendCmd)
 	rootCmd.AddCommand(reportCmd)
 }
 
 // runReportDown creates a requirements graph (and if necessary for comparison a previous graph) and
 // generates a top-down html report, showing the implementation for each top-level requirement
-// @llr REQ-TRAQ-SWL-35
+// @llr REQ-TRAQ-SWL-35, REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-137
 func runReportDownCmd(command *cobra.Command, args []string) error {
 	rg, err := loadReqGraph(args)
 	if err != nil {
 		return errors.Wrap(err, "load req graph")
 	}
+	if err := loadAttributeHistory(rg, reportHistory); err != nil {
+		return err
+	}
+	if err := writeBadges(rg, "report down", args); err != nil {
+		return err
+	}
 
-	of, err := os.Create(*reportPrefix + "down.html")
+	of, err := createArtifact(*reportPrefix+"down.html", "report-down", "report down", graphInputs(args), nil)
 	if err != nil {
 		return err
 	}
@@ -76,14 +137,16 @@ func runReportDownCmd(command *cobra.Command, args []string) error {
 	if err := report.ReportDown(rg, of); err != nil {
 		return err
 	}
-	of.Close()
+	if err := of.Close(); err != nil {
+		return err
+	}
 
 	filter, err := reqs.CreateFilter(*reportIdFilter, *reportTitleFilter, *reportBodyFilter, *reportAttributeFilter)
 	if err != nil {
 		return err
 	}
 	if !filter.IsEmpty() {
-		of, err := os.Create(*reportPrefix + "down-filtered.html")
+		of, err := createArtifact(*reportPrefix+"down-filtered.html", "report-down", "report down", graphInputs(args), reportFilters())
 		if err != nil {
 			return err
 		}
@@ -91,7 +154,9 @@ func runReportDownCmd(command *cobra.Command, args []string) error {
 		if err := report.ReportDownFiltered(rg, of, &filter); err != nil {
 			return err
 		}
-		of.Close()
+		if err := of.Close(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -100,75 +165,331 @@ func runReportDownCmd(command *cobra.Command, args []string) error {
 
 // runReportIssues creates a requirements graph (and if necessary for comparison a previous graph) and
 // generates an issues html report, showing any validation problems
-// @llr REQ-TRAQ-SWL-36
+// @llr REQ-TRAQ-SWL-36, REQ-TRAQ-SWL-116, REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-192
 func runReportIssuesCmd(command *cobra.Command, args []string) error {
 	rg, err := loadReqGraph(args)
 	if err != nil {
 		return errors.Wrap(err, "load req graph")
 	}
+	if err := writeBadges(rg, "report issues", args); err != nil {
+		return err
+	}
+	if *reportRepo != "" {
+		// The graph may be cached for the next commands, so the issues are filtered in a copy
+		issues, err := issuesOfRepo(rg, *reportRepo)
+		if err != nil {
+			return err
+		}
+		repoGraph := *rg
+		repoGraph.Issues = issues
+		rg = &repoGraph
+	}
 
-	of, err := os.Create(*reportPrefix + "issues.html")
+	of, err := createArtifact(*reportPrefix+"issues.html", "report-issues", "report issues", graphInputs(args), issuesFilters(nil))
 	if err != nil {
 		return err
 	}
 	log.Print("Creating ", of.Name(), " (this may take a while)...")
-	if err := report.ReportIssues(rg, of); err != nil {
+	if err := report.ReportIssues(rg, of, issuesTopDownURL()); err != nil {
+		return err
+	}
+	if err := of.Close(); err != nil {
 		return err
 	}
-	of.Close()
 	filter, err := reqs.CreateFilter(*reportIdFilter, *reportTitleFilter, *reportBodyFilter, *reportAttributeFilter)
 	if err != nil {
 		return err
 	}
 	if !filter.IsEmpty() {
-		of, err := os.Create(*reportPrefix + "issues-filtered.html")
+		of, err := createArtifact(*reportPrefix+"issues-filtered.html", "report-issues", "report issues", graphInputs(args), issuesFilters(reportFilters()))
 		if err != nil {
 			return err
 		}
 		log.Print("Creating ", of.Name(), " (this may take a while)...")
-		if err := report.ReportIssuesFiltered(rg, of, &filter); err != nil {
+		if err := report.ReportIssuesFiltered(rg, of, &filter, issuesTopDownURL()); err != nil {
+			return err
+		}
+		if err := of.Close(); err != nil {
+			return err
+		}
+	}
+	if *reportSplitBy != "" {
+		if err := writeSplitIssuesReports(rg, *reportSplitBy, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runReportReviewsCmd creates a requirements graph and generates a html report with the open review comments
+// of each requirement
+// @llr REQ-TRAQ-SWL-103, REQ-TRAQ-SWL-128
+func runReportReviewsCmd(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(args)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	of, err := createArtifact(*reportPrefix+"reviews.html", "report-reviews", "report reviews", graphInputs(args), nil)
+	if err != nil {
+		return err
+	}
+	log.Print("Creating ", of.Name(), " (this may take a while)...")
+	if err := report.ReportReviews(rg, of); err != nil {
+		of.File.Close()
+		return err
+	}
+	return of.Close()
+}
+
+// runReportSummaryCmd creates a requirements graph and generates HTML and JSON reports with the coverage figures of
+// each document and requirement level
+// @llr REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-190
+func runReportSummaryCmd(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(args)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+	summary := report.ComputeSummary(rg)
+
+	for _, output := range []struct {
+		extension string
+		write     func(report.Summary, io.Writer) error
+	}{{"html", report.ReportSummary}, {"json", report.WriteSummaryJSON}} {
+		of, err := createArtifact(*reportPrefix+"summary."+output.extension, "report-summary", "report summary", graphInputs(args), nil)
+		if err != nil {
+			return err
+		}
+		log.Print("Creating ", of.Name())
+		if err := output.write(summary, of); err != nil {
+			of.File.Close()
+			return err
+		}
+		if err := of.Close(); err != nil {
 			return err
 		}
-		of.Close()
+	}
+	return nil
+}
+
+// runReportTrendCmd generates a html report with the completeness scores recorded in the given history file
+// @llr REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-128
+func runReportTrendCmd(command *cobra.Command, args []string) error {
+	history, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	records, err := report.ReadScoreHistory(history)
+	if err != nil {
+		return errors.Wrap(err, "read score history")
 	}
 
+	of, err := createArtifact(*reportPrefix+"trend.html", "report-trend", "report trend", args, nil)
+	if err != nil {
+		return err
+	}
+	log.Print("Creating ", of.Name(), " (this may take a while)...")
+	if err := report.ReportTrend(records, of); err != nil {
+		of.File.Close()
+		return err
+	}
+	return of.Close()
+}
+
+// issuesTopDownURL returns the URL the issues reports link the requirements of the issues to: the top down report
+// written with the same prefix, next to them
+// @llr REQ-TRAQ-SWL-192
+func issuesTopDownURL() string {
+	return filepath.Base(*reportPrefix + "down.html")
+}
+
+// writeSplitIssuesReports writes an issues report for each value of the given attribute. Issues which cannot
+// be attributed to any value are written to the `unassigned` report.
+// @llr REQ-TRAQ-SWL-98, REQ-TRAQ-SWL-128
+func writeSplitIssuesReports(rg *reqs.ReqGraph, attribute string, args []string) error {
+	written := make(map[string]string)
+	for _, group := range report.SplitIssuesByAttribute(rg, attribute) {
+		name := "unassigned"
+		if group.Value != "" {
+			name = reportFileName(group.Value)
+		}
+		if value, ok := written[name]; ok {
+			return fmt.Errorf("values `%s` and `%s` of attribute `%s` would be written to the same report", value, group.Value, attribute)
+		}
+		written[name] = group.Value
+
+		filters := issuesFilters(map[string]string{"split-by": attribute + "=" + group.Value})
+		of, err := createArtifact(*reportPrefix+"issues-"+name+".html", "report-issues", "report issues", graphInputs(args), filters)
+		if err != nil {
+			return err
+		}
+		log.Print("Creating ", of.Name(), " (this may take a while)...")
+		if err := report.ReportIssuesGroup(rg, of, attribute, group, issuesTopDownURL()); err != nil {
+			of.File.Close()
+			return err
+		}
+		if err := of.Close(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// reportFileName turns an attribute value into a lowercase name usable in file names
+// @llr REQ-TRAQ-SWL-98
+func reportFileName(value string) string {
+	name := strings.Trim(reportFileNameRegexp.ReplaceAllString(strings.ToLower(value), "-"), "-")
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// reportFilters returns the requirement filters given to the report commands, by flag name
+// @llr REQ-TRAQ-SWL-128
+func reportFilters() map[string]string {
+	filters := map[string]string{}
+	for name, value := range map[string]string{"id": *reportIdFilter, "title": *reportTitleFilter, "body": *reportBodyFilter} {
+		if value != "" {
+			filters[name] = value
+		}
+	}
+	if len(*reportAttributeFilter) > 0 {
+		filters["attribute"] = strings.Join(*reportAttributeFilter, ",")
+	}
+	return filters
+}
+
+// issuesFilters adds the repository the issues are reported for, if any, to the given filters
+// @llr REQ-TRAQ-SWL-128
+func issuesFilters(filters map[string]string) map[string]string {
+	if *reportRepo == "" {
+		return filters
+	}
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	filters["repo-name"] = *reportRepo
+	return filters
+}
+
+// reportCodeView returns the code listed in the bottom-up reports, as selected by the flags of the report up command
+// @llr REQ-TRAQ-SWL-160
+func reportCodeView() (report.CodeView, error) {
+	view := report.CodeView{RepoName: repos.RepoName(*reportCodeRepo), PathGlob: *reportCodePath, CollapseFiles: *reportCollapseFiles}
+	if _, err := path.Match(view.PathGlob, ""); err != nil {
+		return view, fmt.Errorf("invalid --code-path `%s`: %v", view.PathGlob, err)
+	}
+	switch *reportCodeType {
+	case "impl":
+		view.Type = code.CodeTypeImplementation
+	case "test":
+		view.Type = code.CodeTypeTests
+	case "any":
+		view.Type = code.CodeTypeAny
+	default:
+		return view, fmt.Errorf("invalid --code-type `%s`, expected `impl`, `test` or `any`", *reportCodeType)
+	}
+	return view, nil
+}
+
+// codeFilters adds the selection of the code listed in the bottom-up reports, if any, to the given filters
+// @llr REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-160
+func codeFilters(filters map[string]string) map[string]string {
+	selection := map[string]string{"code-repo": *reportCodeRepo, "code-path": *reportCodePath}
+	if *reportCodeType != "any" {
+		selection["code-type"] = *reportCodeType
+	}
+	if *reportCollapseFiles {
+		selection["collapse-files"] = "true"
+	}
+	for name, value := range selection {
+		if value == "" {
+			continue
+		}
+		if filters == nil {
+			filters = map[string]string{}
+		}
+		filters[name] = value
+	}
+	return filters
+}
+
 // runReportUp creates a requirements graph (and if necessary for comparison a previous graph) and
 // generates a bottom-up html report, showing the top-level requirement for each implemented function
-// @llr REQ-TRAQ-SWL-35
+// @llr REQ-TRAQ-SWL-35, REQ-TRAQ-SWL-128, REQ-TRAQ-SWL-137, REQ-TRAQ-SWL-160
 func runReportUpCmd(command *cobra.Command, args []string) error {
+	view, err := reportCodeView()
+	if err != nil {
+		return err
+	}
 	rg, err := loadReqGraph(args)
 	if err != nil {
 		return errors.Wrap(err, "load req graph")
 	}
+	if err := loadAttributeHistory(rg, reportHistory); err != nil {
+		return err
+	}
+	if err := writeBadges(rg, "report up", args); err != nil {
+		return err
+	}
 
-	of, err := os.Create(*reportPrefix + "up.html")
+	of, err := createArtifact(*reportPrefix+"up.html", "report-up", "report up", graphInputs(args), codeFilters(nil))
 	if err != nil {
 		return err
 	}
 	log.Print("Creating ", of.Name(), " (this may take a while)...")
-	if err = report.ReportUp(rg, of); err != nil {
+	if err = report.ReportUpCode(rg, of, nil, view); err != nil {
+		return err
+	}
+	if err := of.Close(); err != nil {
 		return err
 	}
-	of.Close()
 
 	filter, err := reqs.CreateFilter(*reportIdFilter, *reportTitleFilter, *reportBodyFilter, *reportAttributeFilter)
 	if err != nil {
 		return err
 	}
 	if !filter.IsEmpty() {
-		of, err := os.Create(*reportPrefix + "up-filtered.html")
+		of, err := createArtifact(*reportPrefix+"up-filtered.html", "report-up", "report up", graphInputs(args), codeFilters(reportFilters()))
 		if err != nil {
 			return err
 		}
 		log.Print("Creating ", of.Name(), " (this may take a while)...")
-		if err := report.ReportUpFiltered(rg, of, &filter); err != nil {
+		if err := report.ReportUpCode(rg, of, &filter, view); err != nil {
+			return err
+		}
+		if err := of.Close(); err != nil {
 			return err
 		}
-		of.Close()
 	}
 
 	return nil
 }
+
+// writeBadges writes the SVG badges with the traceability statistics of the requirements graph, if
+// requested, using the report prefix.
+// @llr REQ-TRAQ-SWL-97, REQ-TRAQ-SWL-128
+func writeBadges(rg *reqs.ReqGraph, command string, args []string) error {
+	if !*reportBadges {
+		return nil
+	}
+
+	for _, badge := range report.Badges(report.ComputeStats(rg)) {
+		of, err := createArtifact(fmt.Sprintf("%sbadge-%s.svg", *reportPrefix, badge.Name), "badge", command, graphInputs(args), nil)
+		if err != nil {
+			return err
+		}
+		log.Print("Creating ", of.Name())
+		if err := badge.Write(of); err != nil {
+			of.File.Close()
+			return errors.Wrapf(err, "writing badge `%s`", badge.Name)
+		}
+		if err := of.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+var reposCloneRef *string
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Shows how the configured repositories are accessed",
+	Long: `Shows where the repositories of the configuration are materialized, to diagnose documents or code which
+cannot be read. The repositories are looked up or cloned as when building the requirements graph.`,
+}
+
+var reposListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "Lists the repositories of the configuration",
+	Long: `Lists each repository of the configuration with the local path it was materialized at, the revision
+checked out and the remote it was cloned from, if any.`,
+	RunE: RunAndHandleError(runReposListCmd),
+}
+
+var reposPathCmd = &cobra.Command{
+	Use:   "path REPO_NAME [FILE]",
+	Args:  cobra.RangeArgs(1, 2),
+	Short: "Prints the local path of a repository or of a file in it",
+	Long: `Prints the local path of the given repository of the configuration, or of the given file in it, failing if
+the file cannot be accessed.`,
+	RunE: RunAndHandleError(runReposPathCmd),
+}
+
+var reposCloneCmd = &cobra.Command{
+	Use:   "clone REPO_NAME [--ref REF]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Clones a repository of the configuration again",
+	Long: `Clones the given repository of the configuration again from the remote it was cloned from, at the given git
+reference or at the default branch, and prints where it was cloned and at which revision. The cached clone is
+updated, unless another running reqtraq uses it, in which case a temporary clone removed on exit is made.`,
+	RunE: RunAndHandleError(runReposCloneCmd),
+}
+
+// Registers the repos command and its subcommands
+// @llr REQ-TRAQ-SWL-144
+func init() {
+	reposCloneRef = reposCloneCmd.Flags().String("ref", "", "The git reference to check out after cloning.")
+	reposCmd.AddCommand(reposListCmd)
+	reposCmd.AddCommand(reposPathCmd)
+	reposCmd.AddCommand(reposCloneCmd)
+	rootCmd.AddCommand(reposCmd)
+}
+
+// describeRepo returns the local path, revision and origin of a registered repository, as a line of the list
+// @llr REQ-TRAQ-SWL-144
+func describeRepo(repoName repos.RepoName) (string, error) {
+	path, err := repos.GetRepoPathByName(repoName)
+	if err != nil {
+		return "", err
+	}
+	revision, err := repos.Revision(repoName)
+	if err != nil {
+		revision = "unknown revision"
+	}
+	origin := "local"
+	if clone, ok := repos.ClonedFrom(repoName); ok {
+		origin = fmt.Sprintf("cloned from %s", clone.Remote)
+		if clone.Reference != "" {
+			origin += fmt.Sprintf(" at %s", clone.Reference)
+		}
+	}
+	return fmt.Sprintf("%s\t%s\t%s\t%s", repoName, path, revision, origin), nil
+}
+
+// runReposListCmd lists the repositories of the configuration with where they were materialized
+// @llr REQ-TRAQ-SWL-144
+func runReposListCmd(command *cobra.Command, args []string) error {
+	if err := setupConfiguration(); err != nil {
+		return err
+	}
+
+	for _, repoName := range repos.RegisteredRepositories() {
+		if _, ok := reqtraqConfig.Repos[repoName]; !ok {
+			continue
+		}
+		line, err := describeRepo(repoName)
+		if err != nil {
+			return err
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// runReposPathCmd prints the local path of a repository of the configuration or of a file in it
+// @llr REQ-TRAQ-SWL-144
+func runReposPathCmd(command *cobra.Command, args []string) error {
+	if err := setupConfiguration(); err != nil {
+		return err
+	}
+
+	repoName := repos.RepoName(args[0])
+	if _, ok := reqtraqConfig.Repos[repoName]; !ok {
+		return fmt.Errorf("Repository `%s` is not part of the configuration", repoName)
+	}
+	if len(args) == 1 {
+		path, err := repos.GetRepoPathByName(repoName)
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	}
+	path, err := repos.PathInRepo(repoName, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
+
+// runReposCloneCmd clones a repository of the configuration again and prints where it was cloned
+// @llr REQ-TRAQ-SWL-144
+func runReposCloneCmd(command *cobra.Command, args []string) error {
+	if err := setupConfiguration(); err != nil {
+		return err
+	}
+
+	repoName := repos.RepoName(args[0])
+	if _, ok := reqtraqConfig.Repos[repoName]; !ok {
+		return fmt.Errorf("Repository `%s` is not part of the configuration", repoName)
+	}
+	clone, ok := repos.ClonedFrom(repoName)
+	if !ok {
+		return fmt.Errorf("Repository `%s` is local and was not cloned", repoName)
+	}
+	if _, err := repos.GetRepo(repoName, clone.Remote, *reposCloneRef, true); err != nil {
+		return err
+	}
+	line, err := describeRepo(repoName)
+	if err != nil {
+		return err
+	}
+	fmt.Println(line)
+	return nil
+}
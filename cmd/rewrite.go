@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// rewriteFlags holds the flags of the commands rewriting certification documents which select whether the
+// changes are only previewed instead of written
+type rewriteFlags struct {
+	dryRun *bool
+	diff   *bool
+}
+
+// addRewriteFlags registers the --dry-run and --diff flags in a command rewriting certification documents
+// @llr REQ-TRAQ-SWL-122
+func addRewriteFlags(command *cobra.Command) rewriteFlags {
+	return rewriteFlags{
+		dryRun: command.Flags().Bool("dry-run", false, "Do not modify the documents and exit with error if they would be modified."),
+		diff:   command.Flags().Bool("diff", false, "Print a unified diff of the changes to the documents. Implies --dry-run."),
+	}
+}
+
+// preview returns whether the changes to the documents must not be written
+// @llr REQ-TRAQ-SWL-122
+func (f rewriteFlags) preview() bool {
+	return *f.dryRun || *f.diff
+}
+
+// rewriteDocument writes the updated content of the document with the given path in the repository to the file
+// at filePath, unless the changes are only previewed. The unified diff of the changes is written to w when
+// requested. Returns whether the content of the document changes.
+// @llr REQ-TRAQ-SWL-122
+func (f rewriteFlags) rewriteDocument(w io.Writer, filePath, path, original, updated string) (bool, error) {
+	if original == updated {
+		return false, nil
+	}
+	if *f.diff {
+		diff := difflib.UnifiedDiff{
+			A:        diffLines(original),
+			B:        diffLines(updated),
+			FromFile: "a/" + path,
+			ToFile:   "b/" + path,
+			Context:  3,
+		}
+		if err := difflib.WriteUnifiedDiff(w, diff); err != nil {
+			return true, err
+		}
+	}
+	if f.preview() {
+		return true, nil
+	}
+	return true, ioutil.WriteFile(filePath, []byte(updated), 0644)
+}
+
+// diffLines splits the text in lines for the unified diff, keeping their line endings
+// @llr REQ-TRAQ-SWL-122
+func diffLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// checkPreview returns an error when the changes were only previewed and some of the documents would be modified,
+// so checks of the documents fail in CI
+// @llr REQ-TRAQ-SWL-122
+func (f rewriteFlags) checkPreview(changed int) error {
+	if f.preview() && changed > 0 {
+		return fmt.Errorf("%d documents would be modified", changed)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-122
+func TestRewrite_PreviewChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "TEST-137-SRD.md")
+	original := "# SRD\n\n### REQ-TEST-SWH-1 Title\n\nBody.\n"
+	updated := "# SRD\n\n### REQ-TEST-SWH-1 Title\n\nUpdated body.\n"
+	assert.NoError(t, os.WriteFile(path, []byte(original), 0644))
+
+	dryRun, diff := false, true
+	flags := rewriteFlags{dryRun: &dryRun, diff: &diff}
+	var out bytes.Buffer
+	changed, err := flags.rewriteDocument(&out, path, "certdocs/TEST-137-SRD.md", original, updated)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "--- a/certdocs/TEST-137-SRD.md\n+++ b/certdocs/TEST-137-SRD.md\n"+
+		"@@ -2,4 +2,4 @@\n \n ### REQ-TEST-SWH-1 Title\n \n-Body.\n+Updated body.\n", out.String())
+	assert.EqualError(t, flags.checkPreview(1), "1 documents would be modified")
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	// Unchanged documents are neither reported nor written
+	out.Reset()
+	changed, err = flags.rewriteDocument(&out, path, "certdocs/TEST-137-SRD.md", original, original)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, out.String())
+	assert.NoError(t, flags.checkPreview(0))
+
+	// Without preview the changes are written
+	diff = false
+	changed, err = flags.rewriteDocument(&out, path, "certdocs/TEST-137-SRD.md", original, updated)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Empty(t, out.String())
+	assert.NoError(t, flags.checkPreview(1))
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, string(content))
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	fSampleSize            *int
+	fSampleSeed            *int64
+	fSampleStratify        *string
+	fSampleWeights         *[]string
+	fSampleIdFilter        *string
+	fSampleTitleFilter     *string
+	fSampleBodyFilter      *string
+	fSampleAttributeFilter *[]string
+)
+
+var sampleCmd = &cobra.Command{
+	Use:   "sample [graph.json ...]",
+	Short: "Draws a reproducible random sample of requirements and prints their trace",
+	Long: `Draws a random sample of the requirements, optionally restricted with a filter, and prints the full trace of
+each requirement drawn: its definition, the requirements above and below it and the code implementing and testing
+them. The same seed draws the same sample out of the same requirements, so the sample of a sampling-based review can
+be reproduced by the auditors. With --stratify, the sample is shared between the documents or the values of an
+attribute in proportion to their number of requirements. With --weight, the requirements whose attribute matches a
+pattern are drawn more often, e.g. --weight "Safety Impact=High:3".`,
+	RunE: RunAndHandleError(runSample),
+}
+
+// Registers the sample command
+// @llr REQ-TRAQ-SWL-157
+func init() {
+	fSampleSize = sampleCmd.Flags().Int("n", 20, "The number of requirements to draw.")
+	fSampleSeed = sampleCmd.Flags().Int64("seed", 0, "The seed of the random draw. A new seed is chosen and printed when not given.")
+	fSampleStratify = sampleCmd.Flags().String("stratify", "", "Share the sample between the documents with `document`, or between the values of the attribute with this name.")
+	fSampleWeights = sampleCmd.Flags().StringSlice("weight", nil, "Weight of the requirements whose attribute matches a pattern, as `ATTRIBUTE=REGEXP:WEIGHT`.")
+	fSampleIdFilter = sampleCmd.Flags().String("id", "", "Regular expression to filter by requirement id.")
+	fSampleTitleFilter = sampleCmd.Flags().String("title", "", "Regular expression to filter by requirement title.")
+	fSampleBodyFilter = sampleCmd.Flags().String("body", "", "Regular expression to filter by requirement body.")
+	fSampleAttributeFilter = sampleCmd.Flags().StringSlice("attribute", nil, "Regular expression to filter by requirement attribute.")
+	rootCmd.AddCommand(sampleCmd)
+}
+
+// runSample draws the sample of the requirements and prints their trace
+// @llr REQ-TRAQ-SWL-157
+func runSample(command *cobra.Command, args []string) error {
+	if *fSampleSize < 1 {
+		return fmt.Errorf("the size of the sample must be at least 1")
+	}
+	options := reqs.SampleOptions{Size: *fSampleSize, Seed: *fSampleSeed, StratifyBy: *fSampleStratify}
+	if !command.Flags().Changed("seed") {
+		options.Seed = time.Now().UnixNano()
+	}
+	for _, w := range *fSampleWeights {
+		weight, err := reqs.ParseSampleWeight(w)
+		if err != nil {
+			return err
+		}
+		options.Weights = append(options.Weights, weight)
+	}
+	filter, err := reqs.CreateFilter(*fSampleIdFilter, *fSampleTitleFilter, *fSampleBodyFilter, *fSampleAttributeFilter)
+	if err != nil {
+		return err
+	}
+
+	rg, err := loadReqGraph(args)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	printSample(newConsole(os.Stdout), rg.Sample(&filter, options), options)
+	return nil
+}
+
+// printSample prints the requirements drawn from each stratum with their trace, after the seed reproducing the sample
+// @llr REQ-TRAQ-SWL-157
+func printSample(out console, strata []reqs.SampleStratum, options reqs.SampleOptions) {
+	drawn, population := 0, 0
+	for _, stratum := range strata {
+		drawn += len(stratum.Reqs)
+		population += stratum.Population
+	}
+	fmt.Fprintf(out.w, "Sample of %d of %d requirements, drawn with --seed %d\n", drawn, population, options.Seed)
+
+	for _, stratum := range strata {
+		if options.StratifyBy != "" {
+			name := stratum.Name
+			if name == "" {
+				name = "(none)"
+			}
+			fmt.Fprintf(out.w, "\n%s %s: %d of %d requirements\n", options.StratifyBy, out.style(ansiBold, name), len(stratum.Reqs), stratum.Population)
+		}
+		for _, r := range stratum.Reqs {
+			fmt.Fprintln(out.w)
+			printTrace(out, r)
+		}
+	}
+}
+
+// printTrace prints the definition of the requirement, the requirements above it up to the top level ones and the
+// requirements and the code below it
+// @llr REQ-TRAQ-SWL-157
+func printTrace(out console, r *reqs.Req) {
+	id := r.ID
+	if r.Document != nil {
+		id = out.link(r.RepoName, r.Document.Path, id)
+	}
+	fmt.Fprintf(out.w, "%s %s\n", out.style(ansiBold, id), r.Title)
+	if r.Document != nil {
+		fmt.Fprintf(out.w, "  Defined in %s:%s:%d\n", r.RepoName, r.Document.Path, r.Position)
+	}
+
+	if len(r.Parents) > 0 {
+		fmt.Fprintln(out.w, "  Parents:")
+		var printUp func(parents []*reqs.Req, indent string)
+		printUp = func(parents []*reqs.Req, indent string) {
+			for _, parent := range parents {
+				fmt.Fprintf(out.w, "%s%s %s\n", indent, parent.ID, parent.Title)
+				printUp(parent.Parents, indent+"  ")
+			}
+		}
+		printUp(r.Parents, "    ")
+	}
+
+	var printCode func(req *reqs.Req, indent string)
+	printCode = func(req *reqs.Req, indent string) {
+		for _, tag := range req.Tags {
+			location := fmt.Sprintf("%s:%d", tag.CodeFile.String(), tag.Line)
+			fmt.Fprintf(out.w, "%s%s %s %s\n", indent, tag.CodeFile.Type, tag.Tag, out.link(tag.CodeFile.RepoName, tag.CodeFile.Path, location))
+		}
+	}
+	if len(r.Children) > 0 || len(r.Tags) > 0 {
+		fmt.Fprintln(out.w, "  Children:")
+		printCode(r, "    ")
+		var printDown func(children []*reqs.Req, indent string)
+		printDown = func(children []*reqs.Req, indent string) {
+			for _, child := range children {
+				fmt.Fprintf(out.w, "%s%s %s\n", indent, child.ID, child.Title)
+				printCode(child, indent+"  ")
+				printDown(child.Children, indent+"  ")
+			}
+		}
+		printDown(r.Children, "    ")
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-157
+func TestSample_PrintTrace(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	sys := &reqs.Req{ID: "REQ-TEST-SYS-1", Title: "System"}
+	swh := &reqs.Req{ID: "REQ-TEST-SWH-1", Title: "Logging", Document: &srd, RepoName: "repo", Position: 3, Parents: []*reqs.Req{sys}}
+	swl := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log rotation", Document: &sdd, RepoName: "repo", Position: 7, Parents: []*reqs.Req{swh}}
+	swl.Tags = []*code.Code{
+		{CodeFile: code.CodeFile{RepoName: "repo", Path: "log.go", Type: code.CodeTypeImplementation}, Tag: "rotate", Line: 12},
+		{CodeFile: code.CodeFile{RepoName: "repo", Path: "log_test.go", Type: code.CodeTypeTests}, Tag: "TestRotate", Line: 5},
+	}
+	swh.Children = []*reqs.Req{swl}
+
+	var buf bytes.Buffer
+	options := reqs.SampleOptions{Size: 1, Seed: 42, StratifyBy: reqs.SampleStratifyByDocument}
+	printSample(console{w: &buf}, []reqs.SampleStratum{{Name: "TEST-137-SRD.md", Population: 4, Reqs: []*reqs.Req{swh}}}, options)
+	assert.Equal(t, `Sample of 1 of 4 requirements, drawn with --seed 42
+
+document TEST-137-SRD.md: 1 of 4 requirements
+
+REQ-TEST-SWH-1 Logging
+  Defined in repo:TEST-137-SRD.md:3
+  Parents:
+    REQ-TEST-SYS-1 System
+  Children:
+    REQ-TEST-SWL-1 Log rotation
+      Implementation rotate repo: log.go:12
+      Tests TestRotate repo: log_test.go:5
+`, buf.String())
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/expr"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	setAttrFilter       *string
+	setAttrAllowUnknown *bool
+
+	setAttrRewrite rewriteFlags
+)
+
+var setAttrCmd = &cobra.Command{
+	Use:   "set-attr --filter FILTER NAME=VALUE...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Sets attributes of the requirements matching a filter",
+	Long: `Sets the given attributes of the requirements of the current repository which match the filter, an expression
+as used by the computed attributes, e.g. 'doc == TEST-138-SDD && attr(STATUS) == Draft'. Only the lines of the
+attributes are modified, the attributes missing from a requirement are appended to its attributes section. The
+attributes must be defined by the schema of the documents, unless --allow-unknown is given, and their values must be
+allowed by it, the required attributes not being emptied. Deleted requirements are never modified. With --dry-run or
+--diff the documents are not modified and the command fails if any of them would be.`,
+	RunE: RunAndHandleError(runSetAttrCmd),
+}
+
+// Registers the set-attr command
+// @llr REQ-TRAQ-SWL-194
+func init() {
+	setAttrFilter = setAttrCmd.Flags().String("filter", "", "Expression selecting the requirements to update, e.g. `attr(STATUS) == Draft`.")
+	setAttrAllowUnknown = setAttrCmd.Flags().Bool("allow-unknown", false, "Set the attributes which are not defined by the schema of the documents.")
+	setAttrRewrite = addRewriteFlags(setAttrCmd)
+	_ = setAttrCmd.MarkFlagRequired("filter")
+	rootCmd.AddCommand(setAttrCmd)
+}
+
+// attributeAssignment is an attribute value given on the command line
+type attributeAssignment struct {
+	name  string
+	value string
+}
+
+// parseAttributeAssignments parses the NAME=VALUE arguments of the set-attr command
+// @llr REQ-TRAQ-SWL-194
+func parseAttributeAssignments(args []string) ([]attributeAssignment, error) {
+	var assignments []attributeAssignment
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.Contains(parts[1], "\n") {
+			return nil, fmt.Errorf("Invalid assignment `%s`, expected NAME=VALUE", arg)
+		}
+		assignments = append(assignments, attributeAssignment{name: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1])})
+	}
+	return assignments, nil
+}
+
+// runSetAttrCmd sets the given attributes of the requirements matching the filter. The values are checked for all the
+// requirements before any document is written.
+// @llr REQ-TRAQ-SWL-194, REQ-TRAQ-SWL-122, REQ-TRAQ-SWL-130
+func runSetAttrCmd(command *cobra.Command, args []string) error {
+	assignments, err := parseAttributeAssignments(args)
+	if err != nil {
+		return err
+	}
+	filter, err := expr.Parse(*setAttrFilter)
+	if err != nil {
+		return errors.Wrap(err, "parse filter")
+	}
+	rg, err := loadReqGraph(nil)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+	repoName := repos.BaseRepoName()
+	matched, err := rg.Query(repoName, filter)
+	if err != nil {
+		return err
+	}
+
+	// The requirements are updated from the last one of each file, so the positions of the previous ones do not change
+	type location struct {
+		r    *reqs.Req
+		file string
+		line int
+	}
+	var locations []location
+	for _, r := range matched {
+		file, line := r.Document.Locate(r.Position)
+		locations = append(locations, location{r: r, file: file, line: line})
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].file != locations[j].file {
+			return locations[i].file < locations[j].file
+		}
+		return locations[i].line > locations[j].line
+	})
+
+	originals := make(map[string]string)
+	updated := make(map[string]string)
+	var messages []string
+	updatedReqs := make(map[string]bool)
+	for _, loc := range locations {
+		for _, assignment := range assignments {
+			if loc.r.SchemaAttribute(assignment.name) == nil && !*setAttrAllowUnknown {
+				return fmt.Errorf("Attribute `%s` is not defined by the schema of document `%s` of requirement %s, use --allow-unknown to set it anyway",
+					assignment.name, loc.r.Document.Path, loc.r.ID)
+			}
+			if err := loc.r.CheckAttributeValue(assignment.name, assignment.value); err != nil {
+				return err
+			}
+			if current, ok := loc.r.Attributes[strings.ToUpper(assignment.name)]; ok && current == assignment.value {
+				continue
+			}
+			content, ok := updated[loc.file]
+			if !ok {
+				path, err := repos.PathInRepo(repoName, loc.file)
+				if err != nil {
+					return err
+				}
+				raw, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				content = string(raw)
+				originals[loc.file] = content
+			}
+			if updated[loc.file], err = reqs.SetAttribute(content, loc.line, loc.r.ID, assignment.name, assignment.value); err != nil {
+				return errors.Wrapf(err, "update attributes in `%s`", loc.file)
+			}
+			updatedReqs[loc.r.ID] = true
+			messages = append(messages, fmt.Sprintf("%s %s: %s", loc.r.ID, assignment.name, assignment.value))
+		}
+	}
+
+	files := make([]string, 0, len(updated))
+	for file := range updated {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	changed := 0
+	for _, file := range files {
+		path, err := repos.PathInRepo(repoName, file)
+		if err != nil {
+			return err
+		}
+		modified, err := setAttrRewrite.rewriteDocument(os.Stdout, path, file, originals[file], updated[file])
+		if err != nil {
+			return err
+		}
+		if modified {
+			changed++
+		}
+	}
+
+	sort.Strings(messages)
+	verb := "Set"
+	if setAttrRewrite.preview() {
+		verb = "Would set"
+	}
+	for _, message := range messages {
+		fmt.Printf("%s %s\n", verb, message)
+	}
+	fmt.Printf("%d of %d matching requirements updated in %d documents\n", len(updatedReqs), len(matched), changed)
+	return setAttrRewrite.checkPreview(changed)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/report"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var (
+	subsetIdFilter        *string
+	subsetTitleFilter     *string
+	subsetBodyFilter      *string
+	subsetAttributeFilter *[]string
+)
+
+var subsetCmd = &cobra.Command{
+	Use:   "subset OUT_DIR [graph.json ...]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Extracts the requirements matching a filter, with their trace context, as a standalone graph",
+	Long: `Extracts the requirements matching a filter, e.g. --attribute "Safety Impact=High", together with all the
+requirements below them and all the requirements above any of these, the code linked to them and their issues.
+The subset is written to OUT_DIR as a raw exported graph, which can be loaded by other commands, along with its
+top down, bottom up and issues reports.`,
+	RunE: RunAndHandleError(runSubsetCmd),
+}
+
+// Registers the subset command
+// @llr REQ-TRAQ-SWL-100
+func init() {
+	subsetIdFilter = subsetCmd.Flags().String("id", "", "Regular expression to filter by requirement id.")
+	subsetTitleFilter = subsetCmd.Flags().String("title", "", "Regular expression to filter by requirement title.")
+	subsetBodyFilter = subsetCmd.Flags().String("body", "", "Regular expression to filter by requirement body.")
+	subsetAttributeFilter = subsetCmd.Flags().StringSlice("attribute", nil, "Regular expression to filter by requirement attribute.")
+	rootCmd.AddCommand(subsetCmd)
+}
+
+// subsetFilters returns the requirement filters selecting the subset, by flag name
+// @llr REQ-TRAQ-SWL-100, REQ-TRAQ-SWL-128
+func subsetFilters() map[string]string {
+	filters := map[string]string{}
+	for name, value := range map[string]string{"id": *subsetIdFilter, "title": *subsetTitleFilter, "body": *subsetBodyFilter} {
+		if value != "" {
+			filters[name] = value
+		}
+	}
+	if len(*subsetAttributeFilter) > 0 {
+		filters["attribute"] = strings.Join(*subsetAttributeFilter, ",")
+	}
+	return filters
+}
+
+// writeSubsetReport writes one of the reports of the subset in the output directory, as an artifact of the given
+// type generated from the inputs
+// @llr REQ-TRAQ-SWL-100, REQ-TRAQ-SWL-128
+func writeSubsetReport(filePath, kind string, inputs []string, generate func(w io.Writer) error) error {
+	of, err := createArtifact(filePath, kind, "subset", inputs, subsetFilters())
+	if err != nil {
+		return err
+	}
+	log.Print("Creating ", of.Name(), " (this may take a while)...")
+	if err := generate(of); err != nil {
+		of.File.Close()
+		return err
+	}
+	return of.Close()
+}
+
+// runSubsetCmd extracts the subset of the requirements graph matching the filter and writes it as an exported
+// graph together with its reports
+// @llr REQ-TRAQ-SWL-100
+func runSubsetCmd(command *cobra.Command, args []string) error {
+	filter, err := reqs.CreateFilter(*subsetIdFilter, *subsetTitleFilter, *subsetBodyFilter, *subsetAttributeFilter)
+	if err != nil {
+		return err
+	}
+	if filter.IsEmpty() {
+		return fmt.Errorf("a filter is required to select the requirements of the subset")
+	}
+
+	rg, err := loadReqGraph(args[1:])
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+
+	subset := rg.Subset(&filter)
+	fmt.Printf("Selected %d of %d requirements\n", len(subset.Reqs), len(rg.Reqs))
+
+	outDir := args[0]
+	inputs := graphInputs(args[1:])
+	if err := exportReqsGraph(subset, path.Join(outDir, "subset.json"), true, "subset", inputs, subsetFilters()); err != nil {
+		return errors.Wrap(err, "export requirements subset")
+	}
+
+	reports := []struct {
+		name     string
+		kind     string
+		generate func(rg *reqs.ReqGraph, w io.Writer) error
+	}{
+		{"req-down.html", "report-down", report.ReportDown},
+		{"req-up.html", "report-up", report.ReportUp},
+		{"req-issues.html", "report-issues", func(rg *reqs.ReqGraph, w io.Writer) error {
+			return report.ReportIssues(rg, w, "req-down.html")
+		}},
+	}
+	for _, r := range reports {
+		r := r
+		err := writeSubsetReport(path.Join(outDir, r.name), r.kind, inputs, func(w io.Writer) error {
+			return r.generate(subset, w)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+var fTriageWaivers *string
+
+var triageCmd = &cobra.Command{
+	Use:   "triage [graph.json ...]",
+	Short: "Triages the issues at an interactive prompt",
+	Long: `Lists the issues of the requirements graph of the current repository, or of the specified requirements graphs
+exported previously, grouped by type and by document, and prompts for commands, one per line, to walk through them:
+open the file of an issue at its line in $VISUAL or $EDITOR, append a waiver stub for an issue to the waivers file,
+or show the fingerprint of an issue, which is also copied to the clipboard of supporting terminals. Enter ? for the
+commands. The issues already waived in the waivers file are not listed, and validate --waivers stops reporting an
+issue once the reason of its waiver is filled in.`,
+	RunE: RunAndHandleError(runTriage),
+}
+
+// The help of the commands of a triage session
+const triageHelp = `Commands:
+  <n>  show issue n          n, enter  show the next issue
+  o    open in the editor    w         append a waiver stub
+  f    show the fingerprint  l         list the issues
+  ?    show this help        q         quit`
+
+// triageSession walks through the issues, reading commands from the input
+type triageSession struct {
+	// The issues in the order they are listed
+	issues  []diagnostics.Issue
+	in      *bufio.Scanner
+	out     console
+	waivers string
+	// Opens the file of the issue at its line
+	openEditor func(issue diagnostics.Issue) error
+	// Index of the current issue, -1 before the first one is shown
+	current int
+}
+
+// triageOrder returns the issues ordered by type, then by repository, document and line
+// @llr REQ-TRAQ-SWL-135
+func triageOrder(issues []diagnostics.Issue) []diagnostics.Issue {
+	sorted := append([]diagnostics.Issue{}, issues...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		if sorted[i].RepoName != sorted[j].RepoName {
+			return sorted[i].RepoName < sorted[j].RepoName
+		}
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+	return sorted
+}
+
+// list prints the issues grouped by type and by document, numbered from 1
+// @llr REQ-TRAQ-SWL-135
+func (s *triageSession) list() {
+	for i, issue := range s.issues {
+		if i == 0 || issue.Type != s.issues[i-1].Type {
+			count := 0
+			for _, other := range s.issues[i:] {
+				if other.Type == issue.Type {
+					count++
+				}
+			}
+			name, code := diagnostics.TypeInfo(issue.Type)
+			fmt.Fprintf(s.out.w, "%s (%s), %d issues\n", s.out.style(ansiBold, name), code, count)
+		}
+		if i == 0 || issue.Type != s.issues[i-1].Type || issue.RepoName != s.issues[i-1].RepoName || issue.Path != s.issues[i-1].Path {
+			fmt.Fprintf(s.out.w, "  %s:%s\n", issue.RepoName, issue.Path)
+		}
+		s.out.printIssue(fmt.Sprintf("    [%d] %d: ", i+1, issue.Line), issue)
+	}
+}
+
+// show prints the details of the current issue
+// @llr REQ-TRAQ-SWL-135
+func (s *triageSession) show() {
+	issue := s.issues[s.current]
+	name, code := diagnostics.TypeInfo(issue.Type)
+	fmt.Fprintf(s.out.w, "[%d/%d] %s (%s) at %s:%s:%d\n", s.current+1, len(s.issues), name, code, issue.RepoName, issue.Path, issue.Line)
+	s.out.printIssue("  ", issue)
+}
+
+// appendWaiver appends the waiver stub of the current issue to the waivers file
+// @llr REQ-TRAQ-SWL-135
+func (s *triageSession) appendWaiver() error {
+	stub, err := reqs.WaiverStub(s.issues[s.current])
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.waivers, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(stub); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out.w, "Waiver stub appended to %s\n", s.waivers)
+	return nil
+}
+
+// copyToClipboard copies the text to the clipboard using the OSC 52 escape sequence, which supporting terminals
+// handle. Nothing is written when the console is not colored, e.g. when the output is not a terminal.
+// @llr REQ-TRAQ-SWL-135
+func (c console) copyToClipboard(text string) {
+	if c.color {
+		fmt.Fprintf(c.w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	}
+}
+
+// run lists the issues and executes the commands read from the input until it quits or the input ends
+// @llr REQ-TRAQ-SWL-135
+func (s *triageSession) run() error {
+	if len(s.issues) == 0 {
+		fmt.Fprintln(s.out.w, "No issues to triage")
+		return nil
+	}
+	s.list()
+	fmt.Fprintln(s.out.w, triageHelp)
+	s.current = -1
+	for {
+		fmt.Fprint(s.out.w, "triage> ")
+		if !s.in.Scan() {
+			fmt.Fprintln(s.out.w)
+			return s.in.Err()
+		}
+		command := strings.TrimSpace(s.in.Text())
+		if n, err := strconv.Atoi(command); err == nil {
+			if n < 1 || n > len(s.issues) {
+				fmt.Fprintf(s.out.w, "No issue %d, the issues are numbered from 1 to %d\n", n, len(s.issues))
+				continue
+			}
+			s.current = n - 1
+			s.show()
+			continue
+		}
+		switch command {
+		case "", "n":
+			if s.current+1 >= len(s.issues) {
+				fmt.Fprintln(s.out.w, "No more issues")
+				continue
+			}
+			s.current++
+			s.show()
+		case "l":
+			s.list()
+		case "?", "h":
+			fmt.Fprintln(s.out.w, triageHelp)
+		case "q":
+			return nil
+		case "o", "w", "f":
+			if s.current < 0 {
+				fmt.Fprintln(s.out.w, "No issue selected, enter its number or n")
+				continue
+			}
+			issue := s.issues[s.current]
+			switch command {
+			case "o":
+				if err := s.openEditor(issue); err != nil {
+					fmt.Fprintf(s.out.w, "Cannot open %s: %v\n", issue.Path, err)
+				}
+			case "w":
+				if err := s.appendWaiver(); err != nil {
+					return errors.Wrap(err, "append waiver stub")
+				}
+			case "f":
+				fingerprint := reqs.IssueFingerprint(issue)
+				s.out.copyToClipboard(fingerprint)
+				fmt.Fprintln(s.out.w, fingerprint)
+			}
+		default:
+			fmt.Fprintf(s.out.w, "Unknown command `%s`\n%s\n", command, triageHelp)
+		}
+	}
+}
+
+// openInEditor opens the file of the issue at its line in the editor selected by the VISUAL or EDITOR environment
+// variables, vi by default. The line is given with the `+LINE` argument understood by most editors.
+// @llr REQ-TRAQ-SWL-135
+func openInEditor(issue diagnostics.Issue) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	path, err := repos.PathInRepo(issue.RepoName, issue.Path)
+	if err != nil {
+		return err
+	}
+	// The editor can be configured with arguments, e.g. `code --wait`
+	args := strings.Fields(editor)
+	if issue.Line > 0 {
+		args = append(args, fmt.Sprintf("+%d", issue.Line))
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// newTriageSession returns a session over the given issues reading commands from the input
+// @llr REQ-TRAQ-SWL-135
+func newTriageSession(issues []diagnostics.Issue, in io.Reader, out console, waivers string) *triageSession {
+	return &triageSession{
+		issues:     triageOrder(issues),
+		in:         bufio.NewScanner(in),
+		out:        out,
+		waivers:    waivers,
+		openEditor: openInEditor,
+		current:    -1,
+	}
+}
+
+// runTriage starts a triage session over the issues of the requirements graph which are not waived yet
+// @llr REQ-TRAQ-SWL-135
+func runTriage(command *cobra.Command, args []string) error {
+	rg, err := loadReqGraph(args)
+	if err != nil {
+		return errors.Wrap(err, "load req graph")
+	}
+	issues := rg.Issues
+	if _, err := os.Stat(*fTriageWaivers); err == nil {
+		waivers, err := reqs.ReadWaivers(*fTriageWaivers)
+		if err != nil {
+			return err
+		}
+		issues, _ = reqs.WaiveIssues(issues, waivers)
+	}
+	return newTriageSession(issues, os.Stdin, newConsole(os.Stdout), *fTriageWaivers).run()
+}
+
+// Registers the triage command
+// @llr REQ-TRAQ-SWL-135
+func init() {
+	fTriageWaivers = triageCmd.Flags().String("waivers", "waivers.yaml", "The file the waiver stubs are appended to, whose waived issues are not listed.")
+	rootCmd.AddCommand(triageCmd)
+}
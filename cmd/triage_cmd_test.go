@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-135
+func TestTriageSession(t *testing.T) {
+	notTested := diagnostics.Issue{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 12, Description: "Requirement REQ-TEST-SWL-2 is not tested.",
+		Severity: diagnostics.IssueSeverityMinor, Type: diagnostics.IssueTypeReqNotTested}
+	noParents := diagnostics.Issue{RepoName: "repo", Path: "log.go", Line: 4, Description: "Function rotate@repo: log.go:4 has no parents.",
+		Type: diagnostics.IssueTypeMissingRequirementInCode}
+	notTested1 := notTested
+	notTested1.Line, notTested1.Description = 5, "Requirement REQ-TEST-SWL-1 is not tested."
+
+	var out bytes.Buffer
+	waivers := filepath.Join(t.TempDir(), "waivers.yaml")
+	session := newTriageSession([]diagnostics.Issue{notTested, noParents, notTested1},
+		strings.NewReader("n\no\n3\nw\nf\n7\nx\n"), console{w: &out}, waivers)
+	var opened []diagnostics.Issue
+	session.openEditor = func(issue diagnostics.Issue) error {
+		opened = append(opened, issue)
+		return nil
+	}
+	assert.NoError(t, session.run())
+
+	// The issues are grouped by type, then by document and ordered by line
+	assert.Equal(t, []diagnostics.Issue{noParents, notTested1, notTested}, session.issues)
+	assert.Equal(t, []diagnostics.Issue{noParents}, opened)
+	fingerprint := reqs.IssueFingerprint(notTested)
+	assert.Equal(t, "Code without requirements (REQ5), 1 issues\n"+
+		"  repo:log.go\n"+
+		"    [1] 4: Function rotate@repo: log.go:4 has no parents.\n"+
+		"Requirement not tested (REQ11), 2 issues\n"+
+		"  repo:TEST-138-SDD.md\n"+
+		"    [2] 5: Requirement REQ-TEST-SWL-1 is not tested.\n"+
+		"    [3] 12: Requirement REQ-TEST-SWL-2 is not tested.\n"+
+		triageHelp+"\n"+
+		"triage> [1/3] Code without requirements (REQ5) at repo:log.go:4\n"+
+		"  Function rotate@repo: log.go:4 has no parents.\n"+
+		"triage> triage> [3/3] Requirement not tested (REQ11) at repo:TEST-138-SDD.md:12\n"+
+		"  Requirement REQ-TEST-SWL-2 is not tested.\n"+
+		"triage> Waiver stub appended to "+waivers+"\n"+
+		"triage> "+fingerprint+"\n"+
+		"triage> No issue 7, the issues are numbered from 1 to 3\n"+
+		"triage> Unknown command `x`\n"+triageHelp+"\n"+
+		"triage> \n", out.String())
+
+	stubs, err := reqs.ReadWaivers(waivers)
+	assert.NoError(t, err)
+	assert.Equal(t, []reqs.Waiver{{Fingerprint: fingerprint,
+		Issue: "repo:TEST-138-SDD.md:12 Requirement REQ-TEST-SWL-2 is not tested.", Reason: reqs.WaiverStubReason}}, stubs)
+}
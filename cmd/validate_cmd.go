@@ -3,18 +3,30 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/daedaleanai/cobra"
 	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/progress"
+	"github.com/daedaleanai/reqtraq/report"
 	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/pkg/errors"
 )
 
 var fValidateStrict *bool
 var fValidateJson *string
+var fValidateFormat *string
 var fPrintOnlyErrors *bool
+var fValidateFailFast *bool
+var fValidateScoreHistory *string
+var fValidateRepo *string
+var fValidateProgress *string
+var fValidateWaivers *string
 
 var validateCmd = &cobra.Command{
 	Use:   "validate [graph.json ...]",
@@ -33,20 +45,6 @@ type LintMessage struct {
 	Description string `json:"description"`
 }
 
-// Translates the severity code into a value valid for the json output
-// @llr REQ-TRAQ-SWL-66
-func translateSeverityCode(severity diagnostics.IssueSeverity) string {
-	switch severity {
-	case diagnostics.IssueSeverityMajor:
-		return "error"
-	case diagnostics.IssueSeverityMinor:
-		return "warning"
-	case diagnostics.IssueSeverityNote:
-		return "note"
-	}
-	return "error"
-}
-
 // Builds a Json file with the issues found after parsing the requirements and code. It only collects
 // information for the base repository.
 // @llr REQ-TRAQ-SWL-66
@@ -57,77 +55,15 @@ func buildJsonIssues(issues []diagnostics.Issue, jsonWriter *json.Encoder) error
 			continue
 		}
 
-		var name string
-		var code string
-		switch issue.Type {
-		case diagnostics.IssueTypeInvalidRequirementId:
-			name = "Invalid requirement ID"
-			code = "REQ1"
-		case diagnostics.IssueTypeInvalidParent:
-			name = "Invalid parent requirement"
-			code = "REQ2"
-		case diagnostics.IssueTypeInvalidRequirementReference:
-			name = "Invalid requirement reference"
-			code = "REQ3"
-		case diagnostics.IssueTypeInvalidRequirementInCode:
-			name = "Invalid requirement"
-			code = "REQ4"
-		case diagnostics.IssueTypeMissingRequirementInCode:
-			name = "Code without requirements"
-			code = "REQ5"
-		case diagnostics.IssueTypeMissingAttribute:
-			name = "Missing attribute"
-			code = "REQ6"
-		case diagnostics.IssueTypeUnknownAttribute:
-			name = "Unknown attribute"
-			code = "REQ7"
-		case diagnostics.IssueTypeInvalidAttributeValue:
-			name = "Invalid attribute"
-			code = "REQ8"
-		case diagnostics.IssueTypeReqTestedButNotImplemented:
-			name = "Requirement tested but not implemented"
-			code = "REQ9"
-		case diagnostics.IssueTypeReqNotImplemented:
-			name = "Requirement not implemented"
-			code = "REQ10"
-		case diagnostics.IssueTypeReqNotTested:
-			name = "Requirement not tested"
-			code = "REQ11"
-		case diagnostics.IssueTypeNoShallInBody:
-			name = "No shall statement in body"
-			code = "REQ12"
-		case diagnostics.IssueTypeManyShallInBody:
-			name = "Multiple shall statements in body"
-			code = "REQ13"
-		case diagnostics.IssueTypeShallInRationale:
-			name = "Shall statement in rationale attribute"
-			code = "REQ14"
-		case diagnostics.IssueTypeInvalidFlowId:
-			name = "Invalid Flow tag identifier"
-			code = "REQ15"
-		case diagnostics.IssueTypeFlowNotImplemented:
-			name = "Flow tag is not linked to a requirement"
-			code = "REQ16"
-		case diagnostics.IssueTypeDuplicateFlowId:
-			name = "Duplicate Flow tag identifier"
-			code = "REQ17"
-		case diagnostics.IssueTypeMissingFlowId:
-			name = "Missing Flow tag identifier"
-			code = "REQ18"
-		case diagnostics.IssueTypeInvalidFlowDirection:
-			name = "Invalid flow direction"
-			code = "REQ19"
-		case diagnostics.IssueTypeFlowIdOfDifferentItem:
-			name = "Requirement references flow tag of a different item"
-			code = "REQ20"
-		default:
+		name, code := diagnostics.TypeInfo(issue.Type)
+		if name == "" {
 			log.Fatal("Unhandled IssueType: %r", issue.Type)
 		}
 
 		message := LintMessage{
 			Name:        name,
 			Code:        code,
-			Severity:    translateSeverityCode(issue.Severity),
+			Severity:    issue.Severity.String(),
 			Path:        issue.Path,
 			Line:        issue.Line,
 			Char:        0,
@@ -140,68 +76,246 @@ func buildJsonIssues(issues []diagnostics.Issue, jsonWriter *json.Encoder) error
 	return nil
 }
 
-// createIssuesReport writes the specified requirements issues to a JSON file.
-// @llr REQ-TRAQ-SWL-36
-func createIssuesReport(issues []diagnostics.Issue, filePath string) error {
-	file, err := os.Create(filePath)
+// createIssuesReport writes the specified requirements issues to a JSON file, an artifact generated by the validate
+// command from the inputs.
+// @llr REQ-TRAQ-SWL-36, REQ-TRAQ-SWL-128
+func createIssuesReport(issues []diagnostics.Issue, filePath string, inputs []string) error {
+	var filters map[string]string
+	if *fValidateRepo != "" {
+		filters = map[string]string{"repo-name": *fValidateRepo}
+	}
+	file, err := createArtifact(filePath, "issues", "validate", inputs, filters)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
 	jsonWriter := json.NewEncoder(file)
-	return buildJsonIssues(issues, jsonWriter)
+	if err := buildJsonIssues(issues, jsonWriter); err != nil {
+		file.File.Close()
+		return err
+	}
+	return file.Close()
 }
 
-// validate prints the issues detected in the requirements graph.
-// Returns the count of critical issues and the count of lint messages.
-// @llr REQ-TRAQ-SWL-36
-func validate(issues []diagnostics.Issue, onlyErrors bool) (int, int) {
+// jsonIssue is an issue as written to the standard output by the validate command with --json
+type jsonIssue struct {
+	RepoName    repos.RepoName `json:"repo"`
+	Path        string         `json:"path"`
+	Line        int            `json:"line"`
+	Severity    string         `json:"severity"`
+	Type        string         `json:"type"`
+	Code        string         `json:"code"`
+	Description string         `json:"description"`
+	Archs       []string       `json:"archs,omitempty"`
+}
+
+// writeJsonIssues writes the issues as an indented JSON array, which is empty when there are no issues
+// @llr REQ-TRAQ-SWL-175
+func writeJsonIssues(w io.Writer, issues []diagnostics.Issue) error {
+	messages := make([]jsonIssue, 0, len(issues))
+	for _, issue := range issues {
+		name, code := diagnostics.TypeInfo(issue.Type)
+		messages = append(messages, jsonIssue{
+			RepoName:    issue.RepoName,
+			Path:        issue.Path,
+			Line:        issue.Line,
+			Severity:    issue.Severity.String(),
+			Type:        name,
+			Code:        code,
+			Description: issue.Description,
+			Archs:       issue.Archs,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(messages)
+}
+
+// countIssues returns the count of critical issues and the count of lint messages. The references to requirements
+// of the repositories which were not parsed and the notes are lint messages.
+// @llr REQ-TRAQ-SWL-36, REQ-TRAQ-SWL-175
+func countIssues(issues []diagnostics.Issue) (int, int) {
 	criticalErrorsCount := 0
 	lintErrorsCount := 0
 	for _, issue := range issues {
-		if issue.Severity == diagnostics.IssueSeverityNote {
+		if issue.Type == diagnostics.IssueTypeExternalReference || issue.Severity == diagnostics.IssueSeverityNote {
 			lintErrorsCount += 1
-			if onlyErrors {
-				continue
-			}
 		} else {
 			criticalErrorsCount += 1
 		}
-		fmt.Println(issue.Description)
 	}
-
 	return criticalErrorsCount, lintErrorsCount
 }
 
+// validate prints the issues detected in the requirements graph, followed by the references to requirements
+// of the repositories which were not parsed.
+// Returns the count of critical issues and the count of lint messages.
+// @llr REQ-TRAQ-SWL-36, REQ-TRAQ-SWL-120, REQ-TRAQ-SWL-127
+func validate(issues []diagnostics.Issue, onlyErrors bool) (int, int) {
+	out := newConsole(os.Stdout)
+	externalReferences := make([]diagnostics.Issue, 0)
+	for _, issue := range issues {
+		if issue.Type == diagnostics.IssueTypeExternalReference {
+			externalReferences = append(externalReferences, issue)
+			continue
+		}
+		if issue.Severity == diagnostics.IssueSeverityNote && onlyErrors {
+			continue
+		}
+		out.printIssue("", issue)
+	}
+
+	if len(externalReferences) > 0 && !onlyErrors {
+		fmt.Fprintln(out.w, out.style(ansiBold, "External references:"))
+		for _, issue := range externalReferences {
+			out.printIssue("  ", issue)
+		}
+	}
+
+	return countIssues(issues)
+}
+
+// untilFirstCritical returns the issues up to and including the first critical one.
+// @llr REQ-TRAQ-SWL-88
+func untilFirstCritical(issues []diagnostics.Issue) []diagnostics.Issue {
+	for idx, issue := range issues {
+		if issue.Severity != diagnostics.IssueSeverityNote {
+			return issues[:idx+1]
+		}
+	}
+	return issues
+}
+
+// printScores prints the completeness score of all the documents followed by the score of each document, aligned
+// @llr REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-127
+func printScores(overall reqs.Score, documents []reqs.Score) {
+	out := newConsole(os.Stdout)
+	fmt.Fprintln(out.w, out.style(ansiBold, "Completeness score:"), overall)
+	rows := make([][]string, 0, len(documents))
+	for _, score := range documents {
+		rows = append(rows, []string{fmt.Sprintf("%s:%s", score.RepoName, score.Path), score.String()})
+	}
+	out.printColumns("  ", rows, nil)
+}
+
+// recordScores appends the completeness scores to the score history file, together with the current date and
+// the commit of the validated repository, if known
+// @llr REQ-TRAQ-SWL-111
+func recordScores(path string, overall reqs.Score, documents []reqs.Score) error {
+	record := report.ScoreRecord{Date: time.Now().Format("2006-01-02"), Overall: overall, Documents: documents}
+	if commits, err := repos.AllCommits(repos.BaseRepoName()); err == nil && len(commits) > 0 {
+		record.Commit = strings.Fields(commits[0])[0]
+	}
+	return report.AppendScoreHistory(path, record)
+}
+
 // the run command for validate
-// @llr REQ-TRAQ-SWL-36
+// @llr REQ-TRAQ-SWL-36, REQ-TRAQ-SWL-88, REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-116, REQ-TRAQ-SWL-127, REQ-TRAQ-SWL-135, REQ-TRAQ-SWL-175, REQ-TRAQ-SWL-189
 func runValidate(command *cobra.Command, args []string) error {
+	reqs.FailFast = *fValidateFailFast
+	var jsonOutput bool
+	switch *fValidateFormat {
+	case "text":
+	case "json":
+		jsonOutput = true
+	default:
+		return fmt.Errorf("Unknown output format `%s`, expected `text` or `json`", *fValidateFormat)
+	}
+	switch *fValidateProgress {
+	case "":
+	case "json":
+		progress.Writer = os.Stderr
+		defer func() { progress.Writer = nil }()
+	default:
+		return fmt.Errorf("Unknown progress format `%s`, expected json", *fValidateProgress)
+	}
+	if jsonOutput {
+		// The messages printed while building the graph go to stderr, so stdout only holds the JSON.
+		// They are dropped when stderr holds the JSON progress events.
+		reqs.MessageWriter = os.Stderr
+		if progress.Writer != nil {
+			reqs.MessageWriter = io.Discard
+		}
+		defer func() { reqs.MessageWriter = os.Stdout }()
+	}
 	rg, err := loadReqGraph(args)
 	if err != nil {
 		return errors.Wrap(err, "load req graph")
 	}
 
+	issues, err := issuesOfRepo(rg, *fValidateRepo)
+	if err != nil {
+		return err
+	}
+	waivedCount := 0
+	if *fValidateWaivers != "" {
+		waivers, err := reqs.ReadWaivers(*fValidateWaivers)
+		if err != nil {
+			return err
+		}
+		issues, waivedCount = reqs.WaiveIssues(issues, waivers)
+	}
+	if *fValidateFailFast {
+		issues = untilFirstCritical(issues)
+	}
+
 	if *fValidateJson != "" {
-		if err := createIssuesReport(rg.Issues, *fValidateJson); err != nil {
+		if err := createIssuesReport(issues, *fValidateJson, graphInputs(args)); err != nil {
 			return errors.Wrap(err, "create report")
 		}
 	}
+	var criticalErrorsCount int
+	if jsonOutput {
+		if err := writeJsonIssues(os.Stdout, issues); err != nil {
+			return err
+		}
+		criticalErrorsCount, _ = countIssues(issues)
+	} else {
+		criticalErrorsCount, _ = validate(issues, *fPrintOnlyErrors)
+		if waivedCount > 0 {
+			fmt.Printf("%d issues waived in %s\n", waivedCount, *fValidateWaivers)
+		}
+	}
+	if *fValidateFailFast && criticalErrorsCount > 0 {
+		return fmt.Errorf("validation failed: stopped at the first critical issue")
+	}
+
+	// The issues are incomplete when failing fast, so the score would be misleading
+	if !*fValidateFailFast {
+		overall, documents := rg.Scores()
+		if !jsonOutput {
+			printScores(overall, documents)
+		}
+		if *fValidateScoreHistory != "" {
+			if err := recordScores(*fValidateScoreHistory, overall, documents); err != nil {
+				return errors.Wrap(err, "record scores")
+			}
+		}
+	}
 
-	criticalErrorsCount, _ := validate(rg.Issues, *fPrintOnlyErrors)
 	if *fValidateStrict && criticalErrorsCount > 0 {
 		return fmt.Errorf("validation failed: %d critical issues", criticalErrorsCount)
 	}
 
-	fmt.Println("Validation passed!")
+	if !jsonOutput {
+		out := newConsole(os.Stdout)
+		fmt.Fprintln(out.w, out.style(ansiGreen, "Validation passed!"))
+	}
 	return nil
 }
 
 // Registers the validate command
-// @llr REQ-TRAQ-SWL-36
+// @llr REQ-TRAQ-SWL-36, REQ-TRAQ-SWL-88, REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-116, REQ-TRAQ-SWL-135, REQ-TRAQ-SWL-175, REQ-TRAQ-SWL-189
 func init() {
 	fValidateStrict = validateCmd.PersistentFlags().Bool("strict", false, "Exit with error if any validation issues are found. Only issues with severity 'minor' or 'normal' are counted, linting messages are ignored.")
 	fValidateJson = validateCmd.PersistentFlags().String("json", "", "Additionally, create a JSON file with all errors and lint messages")
+	fValidateFormat = validateCmd.PersistentFlags().String("format", "text", "Write the issues to stdout as `text` or as a `json` array.")
 	fPrintOnlyErrors = validateCmd.PersistentFlags().Bool("only-errors", false, "Only output actual errors, skipping the lint messages")
+	fValidateFailFast = validateCmd.PersistentFlags().Bool("fail-fast", false, "Stop at the first critical issue and exit with error. Useful in pre-commit hooks.")
+	fValidateScoreHistory = validateCmd.PersistentFlags().String("score-history", "", "Append the completeness scores to the given file, for the trend report")
+	fValidateRepo = validateCmd.PersistentFlags().String("repo-name", "", "Only report the issues found in the given repository")
+	fValidateProgress = validateCmd.PersistentFlags().String("progress", "", "Write the progress of building the graph to stderr, as JSON events (stage, percent, document) one per line, with --progress=json")
+	fValidateWaivers = validateCmd.PersistentFlags().String("waivers", "", "Do not report the issues waived in the given file, e.g. the waivers.yaml written by triage, once the reason of their waiver is filled in.")
+	validateCmd.PersistentFlags().BoolVar(&reqs.ParallelBuild, "parallel", false, "Parse the documents in parallel and aggregate the issues found.")
 	rootCmd.AddCommand(validateCmd)
 }
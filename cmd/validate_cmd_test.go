@@ -11,6 +11,7 @@ import (
 
 	"github.com/daedaleanai/reqtraq/code/parsers"
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/stretchr/testify/assert"
@@ -256,6 +257,8 @@ func TestValidateCheckReqReferencesMarkdown(t *testing.T) {
 				},
 			},
 		},
+		// The titles repeated in the documents are tested by TestValidateDuplicateTitles
+		LintPolicy: map[string]config.LintSeverity{config.LintCheckDuplicateTitle: config.LintSeverityOff},
 	}
 
 	expected := `Invalid reference to non existent requirement REQ-TEST-SYS-22 in body of REQ-TEST-SWH-3.
@@ -267,6 +270,42 @@ Requirement 'REQ-TEST-SWH-7' is missing attribute 'SAFETY IMPACT'.`
 	checkValidate(t, &config, expected, "")
 }
 
+// @llr REQ-TRAQ-SWL-152
+func TestValidateDuplicateTitles(t *testing.T) {
+	notePolicy := map[string]config.LintSeverity{config.LintCheckDuplicateTitle: config.LintSeverityNote}
+	config := config.Config{
+		Repos: map[repos.RepoName]config.RepoConfig{
+			repos.BaseRepoName(): {
+				Documents: []config.Document{
+					{
+						Path: "testdata/TestValidateDuplicateTitles/TEST-100-ORD.md",
+						ReqSpec: config.ReqSpec{
+							Prefix: "TEST",
+							Level:  "SYS",
+						},
+						Schema: config.Schema{
+							Requirements: regexp.MustCompile(`REQ-TEST-SYS-(\d+)`),
+							Attributes: map[string]*config.Attribute{
+								"RATIONALE":     {Type: config.AttributeAny, Value: regexp.MustCompile(".*")},
+								"VERIFICATION":  {Type: config.AttributeRequired, Value: regexp.MustCompile("Demonstration")},
+								"SAFETY IMPACT": {Type: config.AttributeRequired, Value: regexp.MustCompile(".*")},
+							},
+						},
+						Implementation: []config.Implementation{},
+					},
+				},
+			},
+		},
+	}
+
+	expected := "Requirement `REQ-TEST-SYS-3` has the same title as requirement `REQ-TEST-SYS-1` of the same document: `navigation`."
+	checkValidate(t, &config, expected, "")
+
+	// The lint policy turns the warnings into notes, which are not reported with --only-errors
+	config.LintPolicy = notePolicy
+	checkValidate(t, &config, "", expected)
+}
+
 func splitLines(s string) (ret []string) {
 	for _, s := range strings.Split(s, "\n") {
 		if s != "" {
@@ -451,3 +490,32 @@ Data/control flow tag 'DF-OTH-1' has no linked requirements
 
 	checkValidate(t, &config, expected, expectedLints)
 }
+
+// @llr REQ-TRAQ-SWL-175
+func TestValidate_JsonIssues(t *testing.T) {
+	issues := []diagnostics.Issue{
+		{RepoName: "reqtraq", Path: "certdocs/TRAQ-138-SDD.md", Line: 12, Description: "Requirement `REQ-TRAQ-SWL-1` has no shall.",
+			Severity: diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeNoShallInBody},
+		{RepoName: "reqtraq", Path: "src/log.cc", Line: 4, Description: "Function rotate has no parents.",
+			Severity: diagnostics.IssueSeverityNote, Type: diagnostics.IssueTypeMissingRequirementInCode, Archs: []string{"arm"}},
+		{RepoName: "child", Path: "TEST-137-SRD.md", Line: 3, Description: "Reference to REQ-OTHER-SWH-1.",
+			Severity: diagnostics.IssueSeverityMinor, Type: diagnostics.IssueTypeExternalReference},
+	}
+	var out strings.Builder
+	assert.NoError(t, writeJsonIssues(&out, issues))
+	assert.JSONEq(t, `[
+		{"repo": "reqtraq", "path": "certdocs/TRAQ-138-SDD.md", "line": 12, "severity": "error",
+		 "type": "No shall statement in body", "code": "REQ12", "description": "Requirement `+"`REQ-TRAQ-SWL-1`"+` has no shall."},
+		{"repo": "reqtraq", "path": "src/log.cc", "line": 4, "severity": "note", "type": "Code without requirements",
+		 "code": "REQ5", "description": "Function rotate has no parents.", "archs": ["arm"]},
+		{"repo": "child", "path": "TEST-137-SRD.md", "line": 3, "severity": "warning", "type": "Reference to a repository which was not parsed",
+		 "code": "REQ24", "description": "Reference to REQ-OTHER-SWH-1."}
+	]`, out.String())
+	critical, lint := countIssues(issues)
+	assert.Equal(t, 1, critical)
+	assert.Equal(t, 2, lint)
+
+	out.Reset()
+	assert.NoError(t, writeJsonIssues(&out, nil))
+	assert.Equal(t, "[]\n", out.String())
+}
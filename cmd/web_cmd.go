@@ -1,33 +1,52 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/daedaleanai/cobra"
+	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/daedaleanai/reqtraq/web"
 	"github.com/pkg/errors"
 )
 
 var webAddr *string
+var webWatch *time.Duration
 
 var webCmd = &cobra.Command{
 	Use:   "web [graph.json ...]",
 	Short: "Starts a local web server to facilitate interaction with reqtraq",
-	Long:  "Starts a local web server to facilitate interaction with reqtraq",
-	RunE:  RunAndHandleError(runWebCmd),
+	Long: `Starts a local web server to facilitate interaction with reqtraq. With --watch, the requirements graph is
+rebuilt at the given interval and the webhooks of the configuration are notified when the requirements or the issues
+changed.`,
+	RunE: RunAndHandleError(runWebCmd),
 }
 
 // Starts the web server listening on the supplied address:port
-// @llr REQ-TRAQ-SWL-58
+// @llr REQ-TRAQ-SWL-58, REQ-TRAQ-SWL-168, REQ-TRAQ-SWL-177
 func runWebCmd(command *cobra.Command, args []string) error {
+	if *webWatch > 0 && len(args) > 0 {
+		return errors.New("--watch cannot be used with exported graphs, which are not rebuilt")
+	}
 	rg, err := loadReqGraph(args)
 	if err != nil {
 		return errors.Wrap(err, "load req graph")
 	}
+	if *webWatch > 0 {
+		go web.Watch(*webWatch, func() (*reqs.ReqGraph, error) {
+			rg, err := reqs.BuildGraph(reqtraqConfig)
+			if err != nil {
+				return nil, err
+			}
+			return redactReqGraph(rg)
+		})
+	}
 	return web.Serve(reqtraqConfig, rg, *webAddr)
 }
 
 // Registers the web command
-// @llr REQ-TRAQ-SWL-58
+// @llr REQ-TRAQ-SWL-58, REQ-TRAQ-SWL-168
 func init() {
 	webAddr = webCmd.PersistentFlags().String("addr", ":8080", "The ip:port where to serve.")
+	webWatch = webCmd.PersistentFlags().Duration("watch", 0, "Rebuild the graph at this interval, e.g. 30s, and notify the webhooks of the changes.")
 	rootCmd.AddCommand(webCmd)
 }
@@ -5,27 +5,59 @@ Functions which deal with source code files. Source code is discovered within a
 package code
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/profile"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/pkg/errors"
 )
 
-var (
+// referenceMatcher finds the references to requirements in the comments of the code, the identifiers of the
+// requirements following any of the configured formats
+type referenceMatcher struct {
 	// To detect a line containing low-level requirements. Can contain any of
 	// " */#" before the llr link to accomodate for languages with C-style code
-	// comments and python-style comments.
-	reLLRReferenceLine = regexp.MustCompile(`^[ \*#\/-]*(?:@|\\)llr +(?:REQ-\w+-\w+-\d+[, ]*)+$`)
-	// To capture requirements out of the line
-	reLLRReferences = regexp.MustCompile(`(REQ-\w+-\w+-\d+)`)
+	// comments and python-style comments. Assumptions can be referenced by the
+	// code checking them. Tests can reference an acceptance criterion of a
+	// requirement, e.g. REQ-PROJ-SWL-5.AC1.
+	line *regexp.Regexp
+	// To capture requirements and their optional acceptance criterion out of the line
+	references *regexp.Regexp
+}
+
+// newReferenceMatcher returns the matcher of the references to requirements whose identifiers follow any of the
+// given formats, or the default format if none is given
+// @llr REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-92
+func newReferenceMatcher(formats []*config.IDFormat) *referenceMatcher {
+	if len(formats) == 0 {
+		formats = []*config.IDFormat{config.DefaultIDFormat}
+	}
+	patterns := make([]string, 0, len(formats))
+	for _, format := range formats {
+		patterns = append(patterns, format.Pattern())
+	}
+	id := "(?:" + strings.Join(patterns, "|") + ")"
+	return &referenceMatcher{
+		line:       regexp.MustCompile(`^[ \*#\/-]*(?:@|\\)llr +(?:` + id + `(?:\.AC\d+)?[, ]*)+$`),
+		references: regexp.MustCompile(`(` + id + `)(?:\.(AC\d+))?`),
+	}
+}
+
+var (
 	// Blank line to stop search
 	reBlankLine = regexp.MustCompile(`^\s*$`)
+	// The size of the largest code file which is parsed, larger files are skipped, e.g. generated sources
+	MaxFileSize int64 = 4 << 20
 	// List of supported code parsers. ctags is always built-in. Other parsers will be registered
 	// during runtime by calling RegisterCodeParser
 	codeParsers = map[string]CodeParser{}
@@ -39,6 +71,24 @@ type CodeParser interface {
 		CompilerArguments []string) (map[CodeFile][]*Code, error)
 }
 
+// A code parser which can tag the functions defined by the expansions of the given macros after the invocation of
+// the macro, e.g. `TEST(Suite, Name)`, instead of the names of the functions generated by the macro
+type MacroCodeParser interface {
+	CodeParser
+	TagCodeWithMacros(repoName repos.RepoName,
+		codeFiles []CodeFile,
+		compilationDatabase string,
+		compilerArguments []string,
+		tagMacros []string) (map[CodeFile][]*Code, error)
+}
+
+// A code parser which only parses the files of some languages, recognized by the extensions of the files
+type LanguageCodeParser interface {
+	CodeParser
+	// Languages returns the lower case extensions, e.g. `.cc`, of the files of each language the parser supports
+	Languages() map[string][]string
+}
+
 // The type of code
 type CodeType uint
 
@@ -73,6 +123,13 @@ func RegisterCodeParser(name string, codeParser CodeParser) {
 	codeParsers[name] = codeParser
 }
 
+// Returns the code parser registered with the given name, if any
+// @llr REQ-TRAQ-SWL-117
+func FindCodeParser(name string) (CodeParser, bool) {
+	codeParser, ok := codeParsers[name]
+	return codeParser, ok
+}
+
 // Lists all available code parsers by name (key)
 // @llr REQ-TRAQ-SWL-65
 func availableCodeParsers() []string {
@@ -107,8 +164,10 @@ type Range struct {
 }
 
 type ReqLink struct {
-	Id    string
-	Range Range
+	Id string
+	// Criterion is the acceptance criterion of the requirement the link points to, e.g. "AC1", if any
+	Criterion string `json:",omitempty"`
+	Range     Range
 }
 
 // Code represents a code node in the graph of requirements.
@@ -127,6 +186,8 @@ type Code struct {
 	Document *config.Document
 	// Whether the code CAN link to a requirement, but does not have to.
 	Optional bool
+	// The architectures the code was parsed for, sorted, empty for code which does not depend on the architecture
+	Archs []config.Arch `json:",omitempty"`
 }
 
 // byFilenameTag provides sort functions to order code by their repo name, then path value, and then line number
@@ -159,16 +220,20 @@ func (a byFilenameTag) Less(i, j int) bool {
 // in the document implementation. The functions returns a map from each architecture to a slice
 // of CodeFile structs, and a slice of CodeFile structs for files that match the default matching rules,
 // but no architecture-specific rule
-// @llr REQ-TRAQ-SWL-78
+// @llr REQ-TRAQ-SWL-78, REQ-TRAQ-SWL-125
 func extractCodeFiles(repoName repos.RepoName, impl *config.Implementation) (map[config.Arch][]CodeFile, []CodeFile, error) {
 	archFilesMap := make(map[config.Arch][]CodeFile)
 	fileToArchMap := make(map[string]config.Arch)
-	for arch := range impl.Archs {
+	for arch, archImpl := range impl.Archs {
 		archFiles := make([]CodeFile, 0)
 		var otherArch config.Arch
 		var exists bool
 
-		for _, implFile := range impl.Archs[arch].CodeFiles {
+		codeFiles, testFiles, err := archImpl.ClassifiedFiles(repoName)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, implFile := range codeFiles {
 			otherArch, exists = fileToArchMap[implFile]
 			if exists {
 				message := fmt.Sprintf("The file %q is matched both by the rules of %q and %q", implFile, arch, otherArch)
@@ -183,7 +248,7 @@ func extractCodeFiles(repoName repos.RepoName, impl *config.Implementation) (map
 			})
 		}
 
-		for _, testFile := range impl.Archs[arch].TestFiles {
+		for _, testFile := range testFiles {
 			otherArch, exists = fileToArchMap[testFile]
 			if exists {
 				message := fmt.Sprintf("The file %q is matched both by the rules of %q and %q", testFile, arch, otherArch)
@@ -203,7 +268,11 @@ func extractCodeFiles(repoName repos.RepoName, impl *config.Implementation) (map
 
 	// Do the same thing for the arch-unaware matching rules
 	noArchFiles := make([]CodeFile, 0)
-	for _, implFile := range impl.CodeFiles {
+	codeFiles, testFiles, err := impl.ClassifiedFiles(repoName)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, implFile := range codeFiles {
 		var exists bool
 		_, exists = fileToArchMap[implFile]
 		if !exists {
@@ -215,7 +284,7 @@ func extractCodeFiles(repoName repos.RepoName, impl *config.Implementation) (map
 		}
 	}
 
-	for _, testFile := range impl.TestFiles {
+	for _, testFile := range testFiles {
 		var exists bool
 		_, exists = fileToArchMap[testFile]
 		if !exists {
@@ -234,80 +303,156 @@ func extractCodeFiles(repoName repos.RepoName, impl *config.Implementation) (map
 // for a given target architecture identified by code files, a compilation database, and compiler arguments.
 // The return value is the same as the one of ParseCode, a map from each discovered source code file to
 // a slice of Code structs representing the functions found within.
-// @llr REQ-TRAQ-SWL-79
-func parseCodeForArch(repoName repos.RepoName, document *config.Document, codeFiles []CodeFile, parser string, compDb string, compArgs []string) (map[CodeFile][]*Code, error) {
+// @llr REQ-TRAQ-SWL-79, REQ-TRAQ-SWL-105, REQ-TRAQ-SWL-108, REQ-TRAQ-SWL-138, REQ-TRAQ-SWL-148, REQ-TRAQ-SWL-170
+func parseCodeForArch(repoName repos.RepoName, document *config.Document, references *referenceMatcher, codeFiles []CodeFile, parser string, compDb string, compArgs []string, fileTagExtensions []string, tagMacros []string, languages []string) (map[CodeFile][]*Code, []SkippedFile, error) {
+	// Files which cannot be parsed are reported instead of aborting the parsing of the whole implementation
+	codeFiles, skipped, err := screenFiles(codeFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Files traced as a whole are not given to the code parser
+	tags, codeFiles, err := tagFiles(document, references, codeFiles, fileTagExtensions)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to tag files")
+	}
+
 	if len(codeFiles) == 0 {
 		// In order to avoid calling TagCode and having the default ctags parser
 		// check that ctags is installed we can simply return here.
 		// That way, those users that don't need ctags don't have to install it.
-		return make(map[CodeFile][]*Code), nil
+		return tags, skipped, nil
 	}
 
-	var tags map[CodeFile][]*Code
-	var err error
-
 	codeParser, ok := codeParsers[parser]
 	if !ok {
-		return nil, fmt.Errorf("No built-in support for code parser `%s`. Try maybe `go install --tags %s`. flag\n\tAvailable parsers: %s", parser, parser, strings.Join(availableCodeParsers(), ", "))
+		return nil, nil, fmt.Errorf("No built-in support for code parser `%s`. Try maybe `go install --tags %s`. flag\n\tAvailable parsers: %s", parser, parser, strings.Join(availableCodeParsers(), ", "))
+	}
+
+	codeFiles, unsupported, err := selectLanguages(parser, codeParser, codeFiles, languages)
+	if err != nil {
+		return nil, nil, err
+	}
+	skipped = append(skipped, unsupported...)
+	if len(codeFiles) == 0 {
+		return tags, skipped, nil
 	}
 
-	tags, err = codeParser.TagCode(repoName, codeFiles, compDb, compArgs)
+	var parsedTags map[CodeFile][]*Code
+	stopProfile := profile.Start(fmt.Sprintf("code tagging (%s)", parser), string(repoName), document.Path)
+	if len(tagMacros) > 0 {
+		macroParser, ok := codeParser.(MacroCodeParser)
+		if !ok {
+			stopProfile()
+			return nil, nil, fmt.Errorf("Code parser `%s` does not support `tagMacros`", parser)
+		}
+		parsedTags, err = macroParser.TagCodeWithMacros(repoName, codeFiles, compDb, compArgs, tagMacros)
+	} else {
+		parsedTags, err = codeParser.TagCode(repoName, codeFiles, compDb, compArgs)
+	}
+	stopProfile()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to tag code")
+		return nil, nil, errors.Wrap(err, "failed to tag code")
 	}
 
 	// Annotate the code procedures with the associated requirement IDs.
-	if err := parseComments(tags); err != nil {
-		return tags, errors.Wrap(err, "failed walking code")
+	if err := references.parseComments(parsedTags); err != nil {
+		return nil, nil, errors.Wrap(err, "failed walking code")
 	}
 
-	for codeFile := range tags {
-		for tagIdx := range tags[codeFile] {
-			tags[codeFile][tagIdx].Document = document
+	for codeFile := range parsedTags {
+		for tagIdx := range parsedTags[codeFile] {
+			parsedTags[codeFile][tagIdx].Document = document
 		}
+		tags[codeFile] = parsedTags[codeFile]
 	}
 
-	return tags, nil
+	return tags, skipped, nil
 }
 
 // ParseCode is the entry point for the code related functions. It parses all tags found in the
-// implementation for the given document. The return value is a map from each discovered source code
-// file to a slice of Code structs representing the functions found within.
-// @llr REQ-TRAQ-SWL-8 REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-69
-func ParseCode(repoName repos.RepoName, document *config.Document) (map[CodeFile][]*Code, error) {
+// implementation for the given document, the references to requirements following any of the given ID formats. The
+// return value is a map from each discovered source code file to a slice of Code structs representing the functions
+// found within, along with the files which were skipped because they cannot be parsed.
+// @llr REQ-TRAQ-SWL-8 REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-69, REQ-TRAQ-SWL-92, REQ-TRAQ-SWL-133, REQ-TRAQ-SWL-148
+func ParseCode(repoName repos.RepoName, document *config.Document, idFormats []*config.IDFormat) (map[CodeFile][]*Code, []SkippedFile, error) {
+	references := newReferenceMatcher(idFormats)
 	var archCodeFiles map[config.Arch][]CodeFile
 	var noArchCodeFiles []CodeFile
 	var err error
 
 	tags := make(map[CodeFile][]*Code)
+	var skipped []SkippedFile
+	// The same file can be skipped for several architectures, it is reported once
+	addSkipped := func(files []SkippedFile) {
+		for _, file := range files {
+			found := false
+			for _, other := range skipped {
+				if other.CodeFile == file.CodeFile {
+					found = true
+					break
+				}
+			}
+			if !found {
+				skipped = append(skipped, file)
+			}
+		}
+	}
 	for _, impl := range document.Implementation {
 		archCodeFiles, noArchCodeFiles, err = extractCodeFiles(repoName, &impl)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// First parse architecture specific code
 		for arch := range impl.Archs {
-			archTags, err := parseCodeForArch(repoName, document, archCodeFiles[arch], impl.CodeParser, impl.Archs[arch].CompilationDatabase, impl.Archs[arch].CompilerArguments)
+			archTags, archSkipped, err := parseCodeForArch(repoName, document, references, archCodeFiles[arch], impl.CodeParser, impl.Archs[arch].CompilationDatabase, impl.Archs[arch].CompilerArguments, impl.FileTagExtensions, impl.TagMacros, impl.Languages)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
+			addSkipped(archSkipped)
 			for k, v := range archTags {
-				tags[k] = v
+				// The same file can be parsed for the architectures of several implementations
+				tags[k] = mergeArchTags(tags[k], v, arch)
 			}
 		}
 
 		// Do the same thing for code that is independent of the architecture
-		noArchTags, err := parseCodeForArch(repoName, document, noArchCodeFiles, impl.CodeParser, impl.CompilationDatabase, impl.CompilerArguments)
+		noArchTags, noArchSkipped, err := parseCodeForArch(repoName, document, references, noArchCodeFiles, impl.CodeParser, impl.CompilationDatabase, impl.CompilerArguments, impl.FileTagExtensions, impl.TagMacros, impl.Languages)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		addSkipped(noArchSkipped)
 		for k, v := range noArchTags {
 			tags[k] = v
 		}
 	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].CodeFile.String() < skipped[j].CodeFile.String() })
 
-	return tags, nil
+	return tags, skipped, nil
+}
+
+// mergeArchTags adds the functions of a file parsed for the given architecture to the functions already found in the
+// file for other architectures. The functions found for several architectures are listed once, with all their
+// architectures.
+// @llr REQ-TRAQ-SWL-133
+func mergeArchTags(tags []*Code, archTags []*Code, arch config.Arch) []*Code {
+	for _, tag := range archTags {
+		merged := false
+		for _, other := range tags {
+			if len(other.Archs) > 0 && other.Symbol == tag.Symbol && other.Tag == tag.Tag && other.Line == tag.Line {
+				other.Archs = append(other.Archs, arch)
+				sort.Slice(other.Archs, func(i, j int) bool { return other.Archs[i] < other.Archs[j] })
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			tag.Archs = []config.Arch{arch}
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
 
 // Create a URL path to a code function by concatenating the repository name, the source code path
@@ -329,16 +474,81 @@ var SourceCodeFileExtensions = map[string][]string{
 	"Robot":         {".robot"},
 }
 
+// LanguageOf returns the language, among the given languages with their extensions, of the file with the given path,
+// or an empty string if it has none of the extensions
+// @llr REQ-TRAQ-SWL-170
+func LanguageOf(languages map[string][]string, path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for language, extensions := range languages {
+		for _, e := range extensions {
+			if e == ext {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+// selectLanguages returns the code files which the given code parser can parse and whose language is one of the
+// given languages, if any, and the files skipped because the parser does not support their language. The files of
+// the languages which are not listed are left out silently, and all the files are kept for the code parsers which do
+// not support languages.
+// @llr REQ-TRAQ-SWL-170
+func selectLanguages(parserName string, codeParser CodeParser, codeFiles []CodeFile, languages []string) ([]CodeFile, []SkippedFile, error) {
+	languageParser, ok := codeParser.(LanguageCodeParser)
+	if !ok {
+		if len(languages) > 0 {
+			return nil, nil, fmt.Errorf("Code parser `%s` does not support `languages`", parserName)
+		}
+		return codeFiles, nil, nil
+	}
+
+	supported := languageParser.Languages()
+	names := make([]string, 0, len(supported))
+	for name := range supported {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// The languages are matched ignoring case, as ctags does
+	allowed := make(map[string]bool)
+	for _, language := range languages {
+		found := false
+		for _, name := range names {
+			if strings.EqualFold(name, language) {
+				allowed[name], found = true, true
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("Language `%s` of `languages` is not supported by code parser `%s`, expected one of %s", language, parserName, strings.Join(names, ", "))
+		}
+	}
+
+	accepted := make([]CodeFile, 0, len(codeFiles))
+	var skipped []SkippedFile
+	for _, codeFile := range codeFiles {
+		language := LanguageOf(supported, codeFile.Path)
+		if language == "" {
+			skipped = append(skipped, SkippedFile{CodeFile: codeFile, Reason: fmt.Sprintf("code parser `%s` does not support its language", parserName)})
+			continue
+		}
+		if len(allowed) > 0 && !allowed[language] {
+			continue
+		}
+		accepted = append(accepted, codeFile)
+	}
+	return accepted, skipped, nil
+}
+
 // parseComments updates the specified tags with the requirement IDs discovered in the codeFiles.
-// @llr REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-75
-func parseComments(codeTags map[CodeFile][]*Code) error {
+// @llr REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-75, REQ-TRAQ-SWL-110
+func (references *referenceMatcher) parseComments(codeTags map[CodeFile][]*Code) error {
 	for codeFile := range codeTags {
 		fsPath, err := repos.PathInRepo(codeFile.RepoName, codeFile.Path)
 		if err != nil {
 			return err
 		}
 		isTestFile := codeFile.Type.Matches(CodeTypeTests)
-		if err := parseFileComments(fsPath, codeTags[codeFile], isTestFile); err != nil {
+		if err := references.parseFileComments(fsPath, codeTags[codeFile], isTestFile); err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed comments discovery for %s - %s", codeFile.RepoName, codeFile.Path))
 		}
 	}
@@ -346,20 +556,29 @@ func parseComments(codeTags map[CodeFile][]*Code) error {
 }
 
 // parseFileComments detects comments in the specified source code file, parses them for requirements IDs and
-// associates them with the tags detected in the same file.
-// @llr REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-75
-func parseFileComments(absolutePath string, tags []*Code, isTestFile bool) error {
-	// Read in the source code and break into string slice
-	sourceRaw, err := os.ReadFile(absolutePath)
+// associates them with the tags detected in the same file. The file is scanned line by line, so only the comments
+// preceding the next tag are kept in memory.
+// @llr REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-75, REQ-TRAQ-SWL-148
+func (references *referenceMatcher) parseFileComments(absolutePath string, tags []*Code, isTestFile bool) error {
+	file, err := os.Open(absolutePath)
 	if err != nil {
 		return err
 	}
-	sourceLines := strings.Split(string(sourceRaw), "\n")
+	defer file.Close()
+	scanner := newLineScanner(file)
 
 	// Sort the tags so they're in line number order
 	sort.Sort(byFilenameTag(tags))
 
-	// For each tag, search through the source code backwards looking for requirement references
+	// The requirement references found since the last blank line, by line index
+	type reference struct {
+		lineNo int
+		links  []ReqLink
+	}
+	var block []reference
+	lineNo := -1
+
+	// For each tag, collect the references above it, up to a blank line or to the previous tag
 	previousTag := 0
 	for i := range tags {
 		if isTestFile {
@@ -371,29 +590,189 @@ func parseFileComments(absolutePath string, tags []*Code, isTestFile bool) error
 			tags[i].Links = tags[i-1].Links
 			continue
 		}
+		for lineNo < tags[i].Line-1 && scanner.Scan() {
+			lineNo++
+			line := scanner.Text()
+			if references.line.MatchString(line) {
+				block = append(block, reference{lineNo, references.parseReqLinks(line, lineNo)})
+			} else if reBlankLine.MatchString(line) {
+				block = nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		// The references closest to the tag come first
 		tags[i].Links = []ReqLink{}
-		for lineNo := tags[i].Line - 1; lineNo > previousTag; lineNo-- {
-			if reLLRReferenceLine.MatchString(sourceLines[lineNo]) {
-				// Looks good, extract all references straight into the tag
-				matches := reLLRReferences.FindAllStringIndex(sourceLines[lineNo], -1)
-				for _, match := range matches {
-					link := ReqLink{
-						Id: sourceLines[lineNo][match[0]:match[1]],
-						Range: Range{
-							Start: Position{Line: uint(lineNo), Character: uint(match[0])},
-							End:   Position{Line: uint(lineNo), Character: uint(match[1])},
-						},
-					}
-					tags[i].Links = append(tags[i].Links, link)
-				}
-			} else if reBlankLine.MatchString(sourceLines[lineNo]) {
-				// We've hit a blank line
+		for j := len(block) - 1; j >= 0 && block[j].lineNo > previousTag; j-- {
+			tags[i].Links = append(tags[i].Links, block[j].links...)
+		}
+		previousTag = tags[i].Line
+	}
+
+	return nil
+}
+
+// newLineScanner returns a scanner reading the lines of a code file, accepting lines as long as the largest file
+// which is parsed
+// @llr REQ-TRAQ-SWL-148
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(MaxFileSize)+1)
+	return scanner
+}
+
+// parseReqLinks extracts the requirement references, along with their optional acceptance criterion, from a line of
+// source code. The line must have been matched against the reference lines already.
+// @llr REQ-TRAQ-SWL-9, REQ-TRAQ-SWL-126
+func (references *referenceMatcher) parseReqLinks(line string, lineNo int) []ReqLink {
+	var links []ReqLink
+	for _, match := range references.references.FindAllStringSubmatchIndex(line, -1) {
+		link := ReqLink{
+			Id: line[match[2]:match[3]],
+			Range: Range{
+				Start: Position{Line: uint(lineNo), Character: uint(match[0])},
+				End:   Position{Line: uint(lineNo), Character: uint(match[1])},
+			},
+		}
+		if match[4] >= 0 {
+			link.Criterion = line[match[4]:match[5]]
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// tagFiles finds the files with one of the given extensions which are tagged as a whole, i.e. which have
+// requirement references in their header: the comment lines at the beginning of the file, up to the first
+// blank line. A single Code entry spanning the whole file is returned for each of them, along with the
+// remaining files which have to be parsed for functions.
+// @llr REQ-TRAQ-SWL-105
+func tagFiles(document *config.Document, references *referenceMatcher, codeFiles []CodeFile, extensions []string) (map[CodeFile][]*Code, []CodeFile, error) {
+	tags := make(map[CodeFile][]*Code)
+	if len(extensions) == 0 {
+		return tags, codeFiles, nil
+	}
+
+	remaining := make([]CodeFile, 0, len(codeFiles))
+	for _, codeFile := range codeFiles {
+		hasExtension := false
+		for _, extension := range extensions {
+			if strings.EqualFold(filepath.Ext(codeFile.Path), extension) {
+				hasExtension = true
 				break
 			}
+		}
+		if !hasExtension {
+			remaining = append(remaining, codeFile)
+			continue
+		}
 
+		fsPath, err := repos.PathInRepo(codeFile.RepoName, codeFile.Path)
+		if err != nil {
+			return nil, nil, err
 		}
-		previousTag = tags[i].Line
+		links, err := references.parseFileHeader(fsPath)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, fmt.Sprintf("failed header discovery for %s - %s", codeFile.RepoName, codeFile.Path))
+		}
+		if len(links) == 0 {
+			remaining = append(remaining, codeFile)
+			continue
+		}
+		tags[codeFile] = []*Code{{
+			CodeFile: codeFile,
+			Tag:      filepath.Base(codeFile.Path),
+			Symbol:   codeFile.Path,
+			Line:     1,
+			Links:    links,
+			Document: document,
+			Optional: codeFile.Type.Matches(CodeTypeTests),
+		}}
 	}
+	return tags, remaining, nil
+}
 
-	return nil
+// parseFileHeader returns the requirement references found in the header of a file, up to the first blank line
+// @llr REQ-TRAQ-SWL-105, REQ-TRAQ-SWL-110, REQ-TRAQ-SWL-148
+func (references *referenceMatcher) parseFileHeader(absolutePath string) ([]ReqLink, error) {
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var links []ReqLink
+	scanner := newLineScanner(file)
+	for lineNo := 0; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if reBlankLine.MatchString(line) {
+			break
+		}
+		if !references.line.MatchString(line) {
+			continue
+		}
+		links = append(links, references.parseReqLinks(line, lineNo)...)
+	}
+	return links, scanner.Err()
+}
+
+// The number of bytes at the beginning of a file which are checked for binary content, as done by git
+const binaryCheckLength = 8000
+
+// SkippedFile is a code file which was not parsed, e.g. because it is too large or binary
+type SkippedFile struct {
+	CodeFile CodeFile
+	Reason   string
+}
+
+// screenFiles returns the code files which can be parsed, and the files skipped because they are larger than
+// MaxFileSize or because they are binary, i.e. they contain a NUL byte at their beginning.
+// @llr REQ-TRAQ-SWL-148
+func screenFiles(codeFiles []CodeFile) ([]CodeFile, []SkippedFile, error) {
+	accepted := make([]CodeFile, 0, len(codeFiles))
+	var skipped []SkippedFile
+	for _, codeFile := range codeFiles {
+		fsPath, err := repos.PathInRepo(codeFile.RepoName, codeFile.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		reason, err := unparsableReason(fsPath)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to check %s - %s", codeFile.RepoName, codeFile.Path)
+		}
+		if reason != "" {
+			skipped = append(skipped, SkippedFile{CodeFile: codeFile, Reason: reason})
+			continue
+		}
+		accepted = append(accepted, codeFile)
+	}
+	return accepted, skipped, nil
+}
+
+// unparsableReason returns why the file cannot be parsed, empty if it can be
+// @llr REQ-TRAQ-SWL-148
+func unparsableReason(absolutePath string) (string, error) {
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > MaxFileSize {
+		return fmt.Sprintf("it is larger than %d bytes", MaxFileSize), nil
+	}
+	head := make([]byte, binaryCheckLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if bytes.IndexByte(head[:n], 0) >= 0 {
+		return "it is a binary file", nil
+	}
+	return "", nil
 }
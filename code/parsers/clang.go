@@ -100,11 +100,74 @@ func isDeleted(cursor clang.Cursor) bool {
 	return cursor.IsFunctionInlined() && cursor.Definition().IsNull() && !cursor.CXXMethod_IsDefaulted()
 }
 
-// Traverses the AST obtained from libclang to find any code and returns a map of files to a map of lines to code tags
-// @llr REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-62, REQ-TRAQ-SWL-63, REQ-TRAQ-SWL-69
-func visitAstNodes(cursor clang.Cursor, repoName repos.RepoName, repoPath string, path string, fileMap map[string]code.CodeFile) map[string]map[uint]*code.Code {
+// Formats the tokens of a macro invocation as the tag of the code defined by its expansion, e.g. `TEST(Suite, Name)`
+// @llr REQ-TRAQ-SWL-138
+func macroInvocation(tu clang.TranslationUnit, cursor clang.Cursor) string {
+	var invocation strings.Builder
+	for _, token := range tu.Tokenize(cursor.Extent()) {
+		spelling := tu.TokenSpelling(token)
+		if spelling == "," {
+			spelling = ", "
+		}
+		invocation.WriteString(spelling)
+	}
+	return invocation.String()
+}
+
+// Finds the invocations of the given macros in the files of the file map and returns a map of files to a map of lines
+// to the tags of the code defined by their expansions. The translation unit must have been parsed with a detailed
+// preprocessing record, for the macro expansions to be part of the AST.
+// @llr REQ-TRAQ-SWL-138
+func findMacroInvocations(tu clang.TranslationUnit, repoPath string, fileMap map[string]code.CodeFile, tagMacros []string) map[string]map[uint]string {
+	invocations := map[string]map[uint]string{}
+	if len(tagMacros) == 0 {
+		return invocations
+	}
+	macros := make(map[string]bool, len(tagMacros))
+	for _, macro := range tagMacros {
+		macros[macro] = true
+	}
+
+	tu.TranslationUnitCursor().Visit(func(cursor, parent clang.Cursor) clang.ChildVisitResult {
+		if cursor.Kind() != clang.Cursor_MacroExpansion || !macros[cursor.Spelling()] {
+			return clang.ChildVisit_Continue
+		}
+		file, line, _, _ := cursor.Location().FileLocation()
+		relativePath, err := filepath.Rel(repoPath, file.TryGetRealPathName())
+		if err != nil {
+			return clang.ChildVisit_Continue
+		}
+		if _, ok := fileMap[relativePath]; !ok {
+			return clang.ChildVisit_Continue
+		}
+		if _, ok := invocations[relativePath]; !ok {
+			invocations[relativePath] = make(map[uint]string)
+		}
+		invocations[relativePath][uint(line)] = macroInvocation(tu, cursor)
+		return clang.ChildVisit_Continue
+	})
+	return invocations
+}
+
+// Traverses the AST obtained from libclang to find any code and returns a map of files to a map of lines to code tags.
+// The code defined by the expansion of the macro invocations found with findMacroInvocations is tagged after the
+// invocation, at the line of the invocation.
+// @llr REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-62, REQ-TRAQ-SWL-63, REQ-TRAQ-SWL-69, REQ-TRAQ-SWL-138
+func visitAstNodes(cursor clang.Cursor, repoName repos.RepoName, repoPath string, path string, fileMap map[string]code.CodeFile, invocations map[string]map[uint]string) map[string]map[uint]*code.Code {
 	codeMap := map[string]map[uint]*code.Code{}
 
+	// Returns where the macro whose expansion defines the code of the cursor was invoked, if it is one of the
+	// tagged macros, and the tag of the code
+	macroInvocationOf := func(cursor clang.Cursor) (clang.File, uint32, string, bool) {
+		file, line, _, _ := cursor.Location().ExpansionLocation()
+		relativePath, err := filepath.Rel(repoPath, file.TryGetRealPathName())
+		if err != nil {
+			return file, line, "", false
+		}
+		tag, ok := invocations[relativePath][uint(line)]
+		return file, line, tag, ok
+	}
+
 	storeTag := func(cursor clang.Cursor, optional bool) {
 		if strings.TrimSpace(cursor.Spelling()) == "" {
 			// Ignore empty symbols
@@ -112,6 +175,11 @@ func visitAstNodes(cursor clang.Cursor, repoName repos.RepoName, repoPath string
 		}
 
 		file, line, _, _ := cursor.Location().FileLocation()
+		tag := cursor.Spelling()
+		macroFile, macroLine, macroTag, fromMacro := macroInvocationOf(cursor)
+		if fromMacro {
+			file, line, tag = macroFile, macroLine, macroTag
+		}
 
 		// Try to get relative path to the repo
 		relativePath, err := filepath.Rel(repoPath, file.TryGetRealPathName())
@@ -132,9 +200,14 @@ func visitAstNodes(cursor clang.Cursor, repoName repos.RepoName, repoPath string
 			codeMap[relativePath] = make(map[uint]*code.Code)
 		}
 
+		if existing, ok := codeMap[relativePath][uint(line)]; ok && fromMacro && !existing.Optional {
+			// A macro expansion defining both types and functions must be traced like the functions
+			optional = false
+		}
+
 		codeMap[relativePath][uint(line)] = &code.Code{
 			CodeFile: codeFile,
-			Tag:      cursor.Spelling(),
+			Tag:      tag,
 			Symbol:   cursor.USR(),
 			Line:     int(line),
 			Optional: optional,
@@ -146,6 +219,16 @@ func visitAstNodes(cursor clang.Cursor, repoName repos.RepoName, repoPath string
 			return clang.ChildVisit_Continue
 		}
 
+		if _, _, _, fromMacro := macroInvocationOf(cursor); fromMacro {
+			switch cursor.Kind() {
+			case clang.Cursor_CXXMethod, clang.Cursor_FunctionDecl, clang.Cursor_FunctionTemplate, clang.Cursor_Constructor:
+				// Functions defined by tagged macros are tagged whatever their access, e.g. the private body of a
+				// test case
+				storeTag(cursor, false)
+				return clang.ChildVisit_Continue
+			}
+		}
+
 		switch cursor.Kind() {
 		case clang.Cursor_UnexposedDecl:
 			// libclang exposes concepts via Cursor_UnexposedDecl
@@ -243,8 +326,8 @@ func translateCommand(command *clang.CompileCommand) []string {
 }
 
 // Parses a single file as a translation unit, providing tags from all included files that are listed in the file map
-// @llr REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-62, REQ-TRAQ-SWL-63
-func parseSingleFile(index *clang.Index, codeFile code.CodeFile, commands clang.CompileCommands, compilerArgs []string, fileMap map[string]code.CodeFile) (map[string]map[uint]*code.Code, error) {
+// @llr REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-62, REQ-TRAQ-SWL-63, REQ-TRAQ-SWL-138
+func parseSingleFile(index *clang.Index, codeFile code.CodeFile, commands clang.CompileCommands, compilerArgs []string, fileMap map[string]code.CodeFile, tagMacros []string) (map[string]map[uint]*code.Code, error) {
 	repoPath, err := repos.GetRepoPathByName(codeFile.RepoName)
 	if err != nil {
 		return map[string]map[uint]*code.Code{}, err
@@ -282,13 +365,19 @@ func parseSingleFile(index *clang.Index, codeFile code.CodeFile, commands clang.
 	}
 	defer os.Chdir(originalDir)
 
+	var options uint32
+	if len(tagMacros) > 0 {
+		// The macro expansions are only part of the AST with a detailed preprocessing record
+		options = uint32(clang.TranslationUnit_DetailedPreprocessingRecord)
+	}
+
 	var tu clang.TranslationUnit
 	var clangErr clang.ErrorCode
 	cmdline := translateCommand(command)
 	if len(cmdline) != 0 {
-		clangErr = index.ParseTranslationUnit2FullArgv("", cmdline, nil, 0, &tu)
+		clangErr = index.ParseTranslationUnit2FullArgv("", cmdline, nil, options, &tu)
 	} else {
-		clangErr = index.ParseTranslationUnit2(pathInRepo, compilerArgs, nil, 0, &tu)
+		clangErr = index.ParseTranslationUnit2(pathInRepo, compilerArgs, nil, options, &tu)
 	}
 	if clangErr != clang.Error_Success {
 		return map[string]map[uint]*code.Code{}, fmt.Errorf("Error parsing translation unit `%s`, %v\n", codeFile.Path, clangErr)
@@ -302,7 +391,8 @@ func parseSingleFile(index *clang.Index, codeFile code.CodeFile, commands clang.
 		return map[string]map[uint]*code.Code{}, fmt.Errorf("Diagnostic errors parsing translation unit `%s`\n", codeFile.Path)
 	}
 
-	return visitAstNodes(tu.TranslationUnitCursor(), codeFile.RepoName, absRepoPath, codeFile.Path, fileMap), nil
+	invocations := findMacroInvocations(tu, absRepoPath, fileMap, tagMacros)
+	return visitAstNodes(tu.TranslationUnitCursor(), codeFile.RepoName, absRepoPath, codeFile.Path, fileMap, invocations), nil
 
 }
 
@@ -314,7 +404,15 @@ type clangCodeParser struct{}
 // but collect tagged data from all included files. This helps to tag code from header files that normally is
 // not found in the compilation database (because it is only part of a translation unit as a result of being included from other files)
 // @llr REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-62, REQ-TRAQ-SWL-63
-func (clangCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArgs []string) (map[code.CodeFile][]*code.Code, error) {
+func (p clangCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArgs []string) (map[code.CodeFile][]*code.Code, error) {
+	return p.TagCodeWithMacros(repoName, codeFiles, compilationDatabase, compilerArgs, nil)
+}
+
+// Tags the code in the given repository using libclang like TagCode. The functions defined by the expansion of the
+// given macros, e.g. test cases defined with `TEST(Suite, Name)`, are tagged after the invocation of the macro, at
+// its line, so they can be linked to requirements with the comment above the invocation.
+// @llr REQ-TRAQ-SWL-61, REQ-TRAQ-SWL-138
+func (clangCodeParser) TagCodeWithMacros(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArgs []string, tagMacros []string) (map[code.CodeFile][]*code.Code, error) {
 	codeMap := make(map[string]map[uint]*code.Code)
 	tagsPerFile := make(map[code.CodeFile][]*code.Code)
 
@@ -346,7 +444,7 @@ func (clangCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFil
 	}
 
 	for _, codeFile := range codeFiles {
-		codeFromFile, err := parseSingleFile(&index, codeFile, commands, compilerArgs, fileMap)
+		codeFromFile, err := parseSingleFile(&index, codeFile, commands, compilerArgs, fileMap, tagMacros)
 		if err != nil {
 			return tagsPerFile, err
 		}
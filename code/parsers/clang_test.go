@@ -77,3 +77,25 @@ func TestTagCodeLibClang(t *testing.T) {
 	}
 	LookFor(t, repoName, "test/a/a_test.cc", code.CodeTypeTests, tags, expectedTags)
 }
+
+// @llr REQ-TRAQ-SWL-138
+func TestTagCodeLibClang_Macros(t *testing.T) {
+	repoName := repos.RepoName("libclangtest")
+	repos.RegisterRepository(repoName, repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/libclangtest")))
+
+	codeFiles := []code.CodeFile{
+		{RepoName: repoName, Path: "macros/macros.cc", Type: code.CodeTypeTests},
+	}
+
+	tags, err := clangCodeParser{}.TagCodeWithMacros(repoName, codeFiles, "", []string{"-std=c++20"}, []string{"TEST"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The private test body defined by the macro is tagged after the invocation of the macro
+	expectedTags := []TagMatch{
+		{"TEST(Suite, DoesThings)", 11, nil, false},
+		{"plainFunction", 16, nil, false},
+	}
+	LookFor(t, repoName, "macros/macros.cc", code.CodeTypeTests, tags, expectedTags)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -17,9 +18,31 @@ import (
 
 type ctagsCodeParser struct{}
 
-// TagCode runs ctags over the specified code files and parses the generated tags file.
-// @llr REQ-TRAQ-SWL-8
+// Languages returns the languages ctags is enabled for, with their extensions
+// @llr REQ-TRAQ-SWL-170
+func (ctagsCodeParser) Languages() map[string][]string {
+	return code.SourceCodeFileExtensions
+}
+
+// TagCode runs ctags over the specified code files of the supported languages and parses the generated tags file.
+// @llr REQ-TRAQ-SWL-8, REQ-TRAQ-SWL-170
 func (ctagsCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArguments []string) (map[code.CodeFile][]*code.Code, error) {
+	// Only the languages of the files are enabled, so ctags doesn't parse the others
+	enabled := make(map[string]bool)
+	for _, codeFile := range codeFiles {
+		if language := code.LanguageOf(code.SourceCodeFileExtensions, codeFile.Path); language != "" {
+			enabled[language] = true
+		}
+	}
+	if len(enabled) == 0 {
+		return map[code.CodeFile][]*code.Code{}, nil
+	}
+	languages := make([]string, 0, len(enabled))
+	for l := range enabled {
+		languages = append(languages, l)
+	}
+	sort.Strings(languages)
+
 	r, w := io.Pipe()
 	errChannel := make(chan error)
 	go func(errChannel chan error) {
@@ -34,11 +57,6 @@ func (ctagsCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFil
 		w.Close()
 	}(errChannel)
 
-	languages := make([]string, 0, len(code.SourceCodeFileExtensions))
-	for l := range code.SourceCodeFileExtensions {
-		languages = append(languages, l)
-	}
-
 	if err := checkCtagsAvailable(); err != nil {
 		return nil, errors.Wrap(err, "need to use Universal ctags to tag the code")
 	}
@@ -140,14 +158,22 @@ const installUniversalCtags = "Make sure to install Universal ctags (NOT Exubera
 // checkCtagsAvailable returns an error when Universal Ctags cannot be found.
 // @llr REQ-TRAQ-SWL-8
 func checkCtagsAvailable() error {
+	_, err := CtagsVersion()
+	return err
+}
+
+// CtagsVersion returns the first line of the version of Universal Ctags, or an error when it cannot be found or the
+// `ctags` tool found is not Universal Ctags.
+// @llr REQ-TRAQ-SWL-8, REQ-TRAQ-SWL-162
+func CtagsVersion() (string, error) {
 	out, err := linepipes.All(linepipes.Run(findCtags(), "--version"))
 	if err != nil {
-		return errors.Wrap(err, "universal-ctags not available. "+installUniversalCtags)
+		return "", errors.Wrap(err, "universal-ctags not available. "+installUniversalCtags)
 	}
 	if !strings.Contains(out, "Universal Ctags") {
-		return fmt.Errorf("`ctags` tool is not universal-ctags. " + installUniversalCtags)
+		return "", fmt.Errorf("`ctags` tool is not universal-ctags. " + installUniversalCtags)
 	}
-	return nil
+	return strings.SplitN(out, "\n", 2)[0], nil
 }
 
 // findCtags returns the location of the Universal Ctags executable.
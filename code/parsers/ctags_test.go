@@ -133,10 +133,11 @@ func TestReqGraph_ParseCode(t *testing.T) {
 		},
 	}
 
-	codeTags, err := code.ParseCode(repoName, &doc)
+	codeTags, skipped, err := code.ParseCode(repoName, &doc, nil)
 	if !assert.NoError(t, err) {
 		return
 	}
+	assert.Empty(t, skipped)
 
 	expectedTags := []TagMatch{
 		{"SeparateCommentsForLLrs",
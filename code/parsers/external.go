@@ -0,0 +1,171 @@
+/*
+Runs code parsers implemented by external programs declared in the configuration. The program is given the files
+to parse as JSON on its standard input and writes the functions found in them as JSON to its standard output:
+
+	{"repoPath": "/path/to/repo", "files": [{"path": "src/a.adb", "type": "implementation"}],
+	 "compilationDatabase": "", "compilerArguments": []}
+
+	{"tags": [{"path": "src/a.adb", "tag": "Initialize", "line": 12, "symbol": "", "optional": false}]}
+
+The paths are relative to the root of the repository, which is also the working directory of the program. The
+requirements referenced by the comments above each function are collected by reqtraq.
+
+The configurations of the parent and child repositories are cloned from their remotes, so the programs they declare
+are only run when explicitly allowed with AllowLinkedParsers.
+*/
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+type externalFile struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+type externalRequest struct {
+	RepoPath            string         `json:"repoPath"`
+	Files               []externalFile `json:"files"`
+	CompilationDatabase string         `json:"compilationDatabase"`
+	CompilerArguments   []string       `json:"compilerArguments"`
+}
+
+type externalTag struct {
+	Path     string `json:"path"`
+	Tag      string `json:"tag"`
+	Line     int    `json:"line"`
+	Symbol   string `json:"symbol"`
+	Optional bool   `json:"optional"`
+}
+
+type externalResponse struct {
+	Tags []externalTag `json:"tags"`
+}
+
+// Whether the code parsers declared in the configurations of the repositories other than the base repository run
+var AllowLinkedParsers bool
+
+type externalCodeParser struct {
+	parser config.ExternalCodeParser
+	// Whether the parser is declared by a repository other than the base repository and is not allowed to run
+	disallowed bool
+}
+
+// RegisterExternal registers the code parsers implemented by external programs. They cannot replace the
+// built-in parsers. The parsers declared by repositories other than the given base repository fail when used
+// unless AllowLinkedParsers is set.
+// @llr REQ-TRAQ-SWL-117
+func RegisterExternal(parsers []config.ExternalCodeParser, baseRepoName repos.RepoName) error {
+	for _, parser := range parsers {
+		if existing, ok := code.FindCodeParser(parser.Name); ok {
+			if _, external := existing.(externalCodeParser); !external {
+				return fmt.Errorf("Code parser `%s` declared in config for repo `%s` is a built-in code parser", parser.Name, parser.RepoName)
+			}
+		}
+		disallowed := parser.RepoName != baseRepoName && !AllowLinkedParsers
+		code.RegisterCodeParser(parser.Name, externalCodeParser{parser: parser, disallowed: disallowed})
+	}
+	return nil
+}
+
+// command returns the path of the program implementing the parser. Relative paths to a file are resolved in the
+// repository declaring the parser, other commands are looked up in the PATH.
+// @llr REQ-TRAQ-SWL-117
+func (p externalCodeParser) command() (string, error) {
+	if filepath.IsAbs(p.parser.Command) || !strings.ContainsRune(p.parser.Command, '/') {
+		return p.parser.Command, nil
+	}
+	return repos.PathInRepo(p.parser.RepoName, p.parser.Command)
+}
+
+// TagCode runs the external program over the specified code files and parses the functions it found.
+// @llr REQ-TRAQ-SWL-117
+func (p externalCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArguments []string) (map[code.CodeFile][]*code.Code, error) {
+	if p.disallowed {
+		return nil, fmt.Errorf("code parser `%s` is declared in config for repo `%s`, which is not the current repository. Use --allow-linked-parsers to run `%s`",
+			p.parser.Name, p.parser.RepoName, p.parser.Command)
+	}
+	repoPath, err := repos.GetRepoPathByName(repoName)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := p.command()
+	if err != nil {
+		return nil, err
+	}
+
+	request := externalRequest{
+		RepoPath:            string(repoPath),
+		Files:               make([]externalFile, 0, len(codeFiles)),
+		CompilationDatabase: compilationDatabase,
+		CompilerArguments:   compilerArguments,
+	}
+	if request.CompilerArguments == nil {
+		request.CompilerArguments = []string{}
+	}
+	codeFilesMap := map[string]code.CodeFile{}
+	for _, codeFile := range codeFiles {
+		codeFilesMap[codeFile.Path] = codeFile
+		fileType := "implementation"
+		if codeFile.Type == code.CodeTypeTests {
+			fileType = "tests"
+		}
+		request.Files = append(request.Files, externalFile{Path: codeFile.Path, Type: fileType})
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	escapedCommand := linepipes.EscapeCommand(prog, p.parser.Arguments...)
+	if linepipes.Verbose {
+		log.Println("Executing:", escapedCommand)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(prog, p.parser.Arguments...)
+	cmd.Dir = string(repoPath)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("code parser `%s` failed: %s: %s\n%s", p.parser.Name, err, escapedCommand, strings.TrimSpace(stderr.String()))
+	}
+
+	var response externalResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, errors.Wrapf(err, "invalid output of code parser `%s`", p.parser.Name)
+	}
+
+	tagsByFile := make(map[code.CodeFile][]*code.Code)
+	for _, tag := range response.Tags {
+		codeFile, ok := codeFilesMap[tag.Path]
+		if !ok {
+			return nil, fmt.Errorf("code parser `%s` found function `%s` in file `%s`, which it was not asked to parse", p.parser.Name, tag.Tag, tag.Path)
+		}
+		if tag.Tag == "" || tag.Line < 1 {
+			return nil, fmt.Errorf("code parser `%s` found a function without name or line in file `%s`", p.parser.Name, tag.Path)
+		}
+		tagsByFile[codeFile] = append(tagsByFile[codeFile], &code.Code{
+			CodeFile: codeFile,
+			Tag:      tag.Tag,
+			Symbol:   tag.Symbol,
+			Line:     tag.Line,
+			Optional: tag.Optional,
+		})
+	}
+	return tagsByFile, nil
+}
@@ -0,0 +1,271 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeExternalParser writes a shell script which saves its input next to it and prints the given output
+// @llr REQ-TRAQ-SWL-117
+func writeExternalParser(t *testing.T, dir string, output string) {
+	script := "#!/bin/sh\ncat > request.json\ncat <<'EOF'\n" + output + "\nEOF\n"
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "tools"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tools", "tagger"), []byte(script), 0755))
+}
+
+// @llr REQ-TRAQ-SWL-117
+func TestExternalCodeParser_TagCode(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.RegisterRepository("external", repos.RepoPath(repoPath))
+	writeExternalParser(t, repoPath, `{"tags": [
+		{"path": "src/log.adb", "tag": "Initialize", "line": 12},
+		{"path": "test/log_test.adb", "tag": "Test_Initialize", "line": 4, "optional": true}
+	]}`)
+
+	parserConfig := config.ExternalCodeParser{Name: "ada", Command: "tools/tagger", RepoName: "external"}
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{parserConfig}, "external"))
+	// Registering the same configuration again, e.g. in batch mode, is allowed
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{parserConfig}, "external"))
+	parser, ok := code.FindCodeParser("ada")
+	assert.True(t, ok)
+
+	impl := code.CodeFile{RepoName: "external", Path: "src/log.adb", Type: code.CodeTypeImplementation}
+	test := code.CodeFile{RepoName: "external", Path: "test/log_test.adb", Type: code.CodeTypeTests}
+	tags, err := parser.TagCode("external", []code.CodeFile{impl, test}, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[code.CodeFile][]*code.Code{
+		impl: {{CodeFile: impl, Tag: "Initialize", Line: 12}},
+		test: {{CodeFile: test, Tag: "Test_Initialize", Line: 4, Optional: true}},
+	}, tags)
+
+	request, err := os.ReadFile(filepath.Join(repoPath, "request.json"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"repoPath": "`+repoPath+`", "files": [
+		{"path": "src/log.adb", "type": "implementation"},
+		{"path": "test/log_test.adb", "type": "tests"}
+	], "compilationDatabase": "", "compilerArguments": []}`, string(request))
+
+	// Functions of files which were not requested are rejected
+	_, err = parser.TagCode("external", []code.CodeFile{impl}, "", nil)
+	assert.EqualError(t, err, "code parser `ada` found function `Test_Initialize` in file `test/log_test.adb`, which it was not asked to parse")
+
+	writeExternalParser(t, repoPath, "not json")
+	_, err = parser.TagCode("external", []code.CodeFile{impl}, "", nil)
+	assert.Error(t, err)
+
+	err = RegisterExternal([]config.ExternalCodeParser{{Name: "ctags", Command: "tagger", RepoName: "external"}}, "external")
+	assert.EqualError(t, err, "Code parser `ctags` declared in config for repo `external` is a built-in code parser")
+
+	// The parsers declared by other repositories only run when allowed
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{parserConfig}, "base"))
+	parser, _ = code.FindCodeParser("ada")
+	_, err = parser.TagCode("external", []code.CodeFile{impl}, "", nil)
+	assert.EqualError(t, err, "code parser `ada` is declared in config for repo `external`, which is not the current repository. Use --allow-linked-parsers to run `tools/tagger`")
+
+	AllowLinkedParsers = true
+	defer func() { AllowLinkedParsers = false }()
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{parserConfig}, "base"))
+	parser, _ = code.FindCodeParser("ada")
+	_, err = parser.TagCode("external", []code.CodeFile{impl}, "", nil)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "--allow-linked-parsers")
+}
+
+// @llr REQ-TRAQ-SWL-148
+func TestParseCode_SkippedFiles(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.RegisterRepository("skipping", repos.RepoPath(repoPath))
+	writeExternalParser(t, repoPath, `{"tags": [{"path": "src/log.adb", "tag": "Initialize", "line": 4}]}`)
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{{Name: "ada-skipping", Command: "tools/tagger", RepoName: "skipping"}}, "skipping"))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, "src"), 0755))
+	files := map[string]string{
+		"src/log.adb":       "with Ada.Text_IO;\n\n-- @llr REQ-TEST-SWL-1\nprocedure Initialize is\n",
+		"src/blob.adb":      "-- @llr REQ-TEST-SWL-1\x00\x01\x02",
+		"src/generated.adb": "-- @llr REQ-TEST-SWL-1\n" + strings.Repeat("X", 200) + "\n",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, path), []byte(content), 0644))
+	}
+	maxFileSize := code.MaxFileSize
+	code.MaxFileSize = 100
+	defer func() { code.MaxFileSize = maxFileSize }()
+
+	doc := config.Document{
+		Path:   "TEST-138-SDD.md",
+		Schema: config.Schema{Requirements: regexp.MustCompile(`REQ-TEST-SWL-(\d+)`)},
+		Implementation: []config.Implementation{{
+			ArchImplementation: config.ArchImplementation{CodeFiles: []string{"src/blob.adb", "src/generated.adb", "src/log.adb"}},
+			CodeParser:         "ada-skipping",
+		}},
+	}
+	tags, skipped, err := code.ParseCode("skipping", &doc, nil)
+	assert.NoError(t, err)
+
+	logFile := code.CodeFile{RepoName: "skipping", Path: "src/log.adb", Type: code.CodeTypeImplementation}
+	if assert.Len(t, tags[logFile], 1) {
+		assert.Equal(t, []code.ReqLink{{Id: "REQ-TEST-SWL-1", Range: code.Range{
+			Start: code.Position{Line: 2, Character: 8},
+			End:   code.Position{Line: 2, Character: 22},
+		}}}, tags[logFile][0].Links)
+	}
+	assert.Equal(t, []code.SkippedFile{
+		{CodeFile: code.CodeFile{RepoName: "skipping", Path: "src/blob.adb", Type: code.CodeTypeImplementation}, Reason: "it is a binary file"},
+		{CodeFile: code.CodeFile{RepoName: "skipping", Path: "src/generated.adb", Type: code.CodeTypeImplementation}, Reason: "it is larger than 100 bytes"},
+	}, skipped)
+}
+
+// @llr REQ-TRAQ-SWL-92
+func TestParseCode_IDFormats(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.RegisterRepository("formats", repos.RepoPath(repoPath))
+	writeExternalParser(t, repoPath, `{"tags": [{"path": "src/log.adb", "tag": "Initialize", "line": 4}]}`)
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{{Name: "ada-formats", Command: "tools/tagger", RepoName: "formats"}}, "formats"))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, "src"), 0755))
+	source := "with Ada.Text_IO;\n\n-- @llr REQ_TEST.SWL.1, SRS_0012, REQ-TEST-SWH-1\nprocedure Initialize is\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "src/log.adb"), []byte(source), 0644))
+
+	doc := config.Document{
+		Path:   "TEST-138-SDD.md",
+		Schema: config.Schema{Requirements: regexp.MustCompile(`REQ_TEST\.SWL\.(\d+)`)},
+		Implementation: []config.Implementation{{
+			ArchImplementation: config.ArchImplementation{CodeFiles: []string{"src/log.adb"}},
+			CodeParser:         "ada-formats",
+		}},
+	}
+	logFile := code.CodeFile{RepoName: "formats", Path: "src/log.adb", Type: code.CodeTypeImplementation}
+	ids := func(tags map[code.CodeFile][]*code.Code) []string {
+		var ids []string
+		for _, tag := range tags[logFile] {
+			for _, link := range tag.Links {
+				ids = append(ids, link.Id)
+			}
+		}
+		return ids
+	}
+
+	// The references follow any of the configured formats
+	formats := []*config.IDFormat{config.DefaultIDFormat, config.MustParseIDFormat("SRS_{N:4}"),
+		config.MustParseIDFormat("{VARIANT}_{PREFIX}.{LEVEL}.{N}")}
+	tags, _, err := code.ParseCode("formats", &doc, formats)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"REQ_TEST.SWL.1", "SRS_0012", "REQ-TEST-SWH-1"}, ids(tags))
+
+	// The line is not a reference line with the default format only
+	tags, _, err = code.ParseCode("formats", &doc, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, ids(tags))
+}
+
+// @llr REQ-TRAQ-SWL-133
+func TestParseCode_SeveralArchs(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.RegisterRepository("archs", repos.RepoPath(repoPath))
+	writeExternalParser(t, repoPath, `{"tags": [{"path": "src/log.adb", "tag": "Initialize", "line": 4}]}`)
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{{Name: "ada-archs", Command: "tools/tagger", RepoName: "archs"}}, "archs"))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, "src"), 0755))
+	source := "with Ada.Text_IO;\n\n-- @llr REQ-TEST-SWL-1\nprocedure Initialize is\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "src/log.adb"), []byte(source), 0644))
+
+	// The same file is parsed for the architectures of two implementations
+	implementation := func(arch config.Arch) config.Implementation {
+		return config.Implementation{
+			Archs:      map[config.Arch]config.ArchImplementation{arch: {CodeFiles: []string{"src/log.adb"}}},
+			CodeParser: "ada-archs",
+		}
+	}
+	doc := config.Document{
+		Path:           "TEST-138-SDD.md",
+		Schema:         config.Schema{Requirements: regexp.MustCompile(`REQ-TEST-SWL-(\d+)`)},
+		Implementation: []config.Implementation{implementation("x86"), implementation("arm")},
+	}
+	tags, _, err := code.ParseCode("archs", &doc, nil)
+	assert.NoError(t, err)
+
+	// The function is listed once, with both architectures
+	logFile := code.CodeFile{RepoName: "archs", Path: "src/log.adb", Type: code.CodeTypeImplementation}
+	if assert.Len(t, tags[logFile], 1) {
+		assert.Equal(t, "Initialize", tags[logFile][0].Tag)
+		assert.Equal(t, []config.Arch{"arm", "x86"}, tags[logFile][0].Archs)
+		assert.Len(t, tags[logFile][0].Links, 1)
+	}
+}
+
+// languageParser is a code parser supporting languages which records the files it is asked to parse
+type languageParser struct {
+	files *[]string
+}
+
+// @llr REQ-TRAQ-SWL-170
+func (p languageParser) Languages() map[string][]string {
+	return map[string][]string{"Ada": {".adb"}, "C": {".c", ".h"}}
+}
+
+// @llr REQ-TRAQ-SWL-170
+func (p languageParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArguments []string) (map[code.CodeFile][]*code.Code, error) {
+	for _, codeFile := range codeFiles {
+		*p.files = append(*p.files, codeFile.Path)
+	}
+	return map[code.CodeFile][]*code.Code{}, nil
+}
+
+// @llr REQ-TRAQ-SWL-170
+func TestParseCode_Languages(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.RegisterRepository("languages", repos.RepoPath(repoPath))
+	paths := []string{"src/log.adb", "src/log.c", "src/log.h", "src/log.py"}
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, "src"), 0755))
+	for _, path := range paths {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, path), []byte("\n"), 0644))
+	}
+	var parsed []string
+	code.RegisterCodeParser("languages", languageParser{&parsed})
+
+	impl := config.Implementation{
+		ArchImplementation: config.ArchImplementation{CodeFiles: paths},
+		CodeParser:         "languages",
+	}
+	doc := config.Document{
+		Path:           "TEST-138-SDD.md",
+		Schema:         config.Schema{Requirements: regexp.MustCompile(`REQ-TEST-SWL-(\d+)`)},
+		Implementation: []config.Implementation{impl},
+	}
+	unsupported := []code.SkippedFile{{
+		CodeFile: code.CodeFile{RepoName: "languages", Path: "src/log.py", Type: code.CodeTypeImplementation},
+		Reason:   "code parser `languages` does not support its language",
+	}}
+
+	// All the supported languages are parsed by default
+	_, skipped, err := code.ParseCode("languages", &doc, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"src/log.adb", "src/log.c", "src/log.h"}, parsed)
+	assert.Equal(t, unsupported, skipped)
+
+	// Only the listed languages are parsed, ignoring case
+	parsed = nil
+	doc.Implementation[0].Languages = []string{"c"}
+	_, skipped, err = code.ParseCode("languages", &doc, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"src/log.c", "src/log.h"}, parsed)
+	assert.Equal(t, unsupported, skipped)
+
+	doc.Implementation[0].Languages = []string{"Python"}
+	_, _, err = code.ParseCode("languages", &doc, nil)
+	assert.EqualError(t, err, "Language `Python` of `languages` is not supported by code parser `languages`, expected one of Ada, C")
+
+	// The code parsers which do not support languages parse all the files
+	assert.NoError(t, RegisterExternal([]config.ExternalCodeParser{{Name: "ada-languages", Command: "tools/tagger", RepoName: "languages"}}, "languages"))
+	doc.Implementation[0].CodeParser = "ada-languages"
+	_, _, err = code.ParseCode("languages", &doc, nil)
+	assert.EqualError(t, err, "Code parser `ada-languages` does not support `languages`")
+}
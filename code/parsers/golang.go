@@ -0,0 +1,218 @@
+/*
+Parses Go code with the go/parser package of the standard library, so Go repositories do not need ctags. The
+symbols of the functions are qualified with the import path of their package, like the Go toolchain names them.
+*/
+
+package parsers
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+type goCodeParser struct{}
+
+// Languages returns the only language supported by the parser, with the extension of its files
+// @llr REQ-TRAQ-SWL-184
+func (goCodeParser) Languages() map[string][]string {
+	return map[string][]string{"GO": {".go"}}
+}
+
+// TagCode parses the given Go files and returns their functions, methods and function literals. The compilation
+// database and the compiler arguments are not used.
+// @llr REQ-TRAQ-SWL-184
+func (goCodeParser) TagCode(repoName repos.RepoName, codeFiles []code.CodeFile, compilationDatabase string, compilerArguments []string) (map[code.CodeFile][]*code.Code, error) {
+	modules := make(map[string]string)
+	tagsByFile := make(map[code.CodeFile][]*code.Code)
+	for _, codeFile := range codeFiles {
+		codePath, err := repos.PathInRepo(repoName, codeFile.Path)
+		if err != nil {
+			return nil, err
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, codePath, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse Go file `%s`", codeFile.Path)
+		}
+		packagePath, err := goPackagePath(filepath.Dir(codePath), file.Name.Name, modules)
+		if err != nil {
+			return nil, err
+		}
+		tagsByFile[codeFile] = goFileTags(fset, file, codeFile, packagePath)
+	}
+	return tagsByFile, nil
+}
+
+// goModulePath returns the directory and the module path of the go.mod file found in the given directory or in the
+// closest of its parents, with an empty path if there is none. The modules found are remembered by directory.
+// @llr REQ-TRAQ-SWL-184
+func goModulePath(dir string, modules map[string]string) (string, string, error) {
+	for current := dir; ; current = filepath.Dir(current) {
+		if modulePath, ok := modules[current]; ok {
+			return current, modulePath, nil
+		}
+		f, err := os.Open(filepath.Join(current, "go.mod"))
+		if err == nil {
+			modulePath := ""
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				fields := strings.Fields(scanner.Text())
+				if len(fields) >= 2 && fields[0] == "module" {
+					modulePath = fields[1]
+					if unquoted, err := strconv.Unquote(modulePath); err == nil {
+						modulePath = unquoted
+					}
+					break
+				}
+			}
+			f.Close()
+			if err := scanner.Err(); err != nil {
+				return "", "", err
+			}
+			modules[current] = modulePath
+			return current, modulePath, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		if parent := filepath.Dir(current); parent == current {
+			return "", "", nil
+		}
+	}
+}
+
+// goPackagePath returns the import path of the package of the files of the given directory, the name of the package
+// if the directory is not part of a module. The external test packages are suffixed with `_test`.
+// @llr REQ-TRAQ-SWL-184
+func goPackagePath(dir string, packageName string, modules map[string]string) (string, error) {
+	moduleDir, modulePath, err := goModulePath(dir, modules)
+	if err != nil {
+		return "", err
+	}
+	packagePath := packageName
+	if modulePath != "" {
+		rel, err := filepath.Rel(moduleDir, dir)
+		if err != nil {
+			return "", err
+		}
+		packagePath = modulePath
+		if rel != "." {
+			packagePath += "/" + filepath.ToSlash(rel)
+		}
+		if strings.HasSuffix(packageName, "_test") {
+			packagePath += "_test"
+		}
+	}
+	return packagePath, nil
+}
+
+// goReceiverName returns the name of the type of a method receiver without its type parameters, e.g. `*List` for
+// `*List[T]`
+// @llr REQ-TRAQ-SWL-184
+func goReceiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + goReceiverName(t.X)
+	case *ast.ParenExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return types.ExprString(expr)
+}
+
+// goFileTags returns the functions and methods of a file, the variables of the package initialized with function
+// literals and the function literals found in them. The function literals are named after the function containing
+// them followed by `funcN`, numbered in the order they appear, and do not need to be linked to requirements.
+// @llr REQ-TRAQ-SWL-184
+func goFileTags(fset *token.FileSet, file *ast.File, codeFile code.CodeFile, packagePath string) []*code.Code {
+	var tags []*code.Code
+	addTag := func(name, symbol string, pos token.Pos, optional bool) {
+		tag := &code.Code{
+			CodeFile: codeFile,
+			Tag:      name,
+			Line:     fset.Position(pos).Line,
+			Optional: optional,
+		}
+		// The init functions and the functions named `_` can be declared several times in a package, they are
+		// identified by their location instead
+		if root := strings.SplitN(symbol, ".", 2)[0]; root != "init" && root != "_" {
+			tag.Symbol = packagePath + "." + symbol
+		}
+		tags = append(tags, tag)
+	}
+	addLiterals := func(name, symbol string, node ast.Node) {
+		count := 0
+		ast.Inspect(node, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.FuncLit); ok && n != node {
+				count++
+				addTag(fmt.Sprintf("%s.func%d", name, count), fmt.Sprintf("%s.func%d", symbol, count), lit.Pos(), true)
+			}
+			return true
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name, symbol := d.Name.Name, d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				receiver := goReceiverName(d.Recv.List[0].Type)
+				name = strings.TrimPrefix(receiver, "*") + "." + d.Name.Name
+				if strings.HasPrefix(receiver, "*") {
+					symbol = "(" + receiver + ")." + d.Name.Name
+				} else {
+					symbol = name
+				}
+			}
+			addTag(name, symbol, d.Pos(), false)
+			if d.Body != nil {
+				addLiterals(name, symbol, d.Body)
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range d.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, value := range valueSpec.Values {
+					lit, ok := value.(*ast.FuncLit)
+					if !ok || i >= len(valueSpec.Names) {
+						continue
+					}
+					// The comment of a variable declared alone precedes the `var` keyword
+					pos := valueSpec.Pos()
+					if !d.Lparen.IsValid() {
+						pos = d.Pos()
+					}
+					name := valueSpec.Names[i].Name
+					addTag(name, name, pos, false)
+					addLiterals(name, name, lit)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// Registers the Go parser
+// @llr REQ-TRAQ-SWL-184
+func init() {
+	code.RegisterCodeParser("go", goCodeParser{})
+}
@@ -0,0 +1,85 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-184
+func TestGoCodeParser_TagCode(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "lists"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/project\n\ngo 1.21\n"), 0644))
+	source := `package lists
+
+// List is a generic list
+type List[T any] struct{ items []T }
+
+// LLR REQ-TEST-SWL-1
+func (l *List[T]) Push(item T) {
+	l.items = append(l.items, item)
+}
+
+// LLR REQ-TEST-SWL-2
+func (l List[T]) Each(f func(T)) {
+	for _, item := range l.items {
+		func() {
+			f(item)
+		}()
+	}
+}
+
+// LLR REQ-TEST-SWL-3
+var Less = func(a, b int) bool {
+	return a < b
+}
+
+// LLR REQ-TEST-SWL-4
+func New[T any]() *List[T] {
+	return &List[T]{}
+}
+
+func init() {}
+`
+	// The references are written in the file only, so they are not parsed as references of the test
+	source = strings.ReplaceAll(source, "LLR", "@llr")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "lists", "lists.go"), []byte(source), 0644))
+	repos.RegisterRepository("goproject", repos.RepoPath(dir))
+
+	codeFile := code.CodeFile{RepoName: "goproject", Path: "lists/lists.go", Type: code.CodeTypeImplementation}
+	parser, ok := code.FindCodeParser("go")
+	assert.True(t, ok)
+	tags, err := parser.TagCode("goproject", []code.CodeFile{codeFile}, "", nil)
+	assert.NoError(t, err)
+
+	type tagInfo struct {
+		Tag      string
+		Symbol   string
+		Line     int
+		Optional bool
+	}
+	var found []tagInfo
+	for _, tag := range tags[codeFile] {
+		found = append(found, tagInfo{tag.Tag, tag.Symbol, tag.Line, tag.Optional})
+	}
+	assert.Equal(t, []tagInfo{
+		{"List.Push", "example.com/project/lists.(*List).Push", 7, false},
+		{"List.Each", "example.com/project/lists.List.Each", 12, false},
+		{"List.Each.func1", "example.com/project/lists.List.Each.func1", 14, true},
+		{"Less", "example.com/project/lists.Less", 21, false},
+		{"New", "example.com/project/lists.New", 26, false},
+		{"init", "", 30, false},
+	}, found)
+
+	// Files which are not valid Go are reported
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package broken\nfunc {"), 0644))
+	broken := code.CodeFile{RepoName: "goproject", Path: "broken.go", Type: code.CodeTypeImplementation}
+	_, err = parser.TagCode("goproject", []code.CodeFile{broken}, "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The markers which force the classification of the file containing them, alone in a comment line, e.g.
+// `// reqtraq:test-file`
+var reFileClassMarker = regexp.MustCompile(`(?m)^\s*(?://|#|--|/\*|\*)\s*reqtraq:(test|implementation)-file\s*(?:\*/)?\s*$`)
+
+// pathClass returns whether the file is forced into the tests or the implementation by the longest of the configured
+// paths containing it. Returns an empty string when it is not forced into any.
+// @llr REQ-TRAQ-SWL-125
+func (impl *jsonImplementation) pathClass(path string) string {
+	class, longest := "", -1
+	for _, override := range []struct {
+		class string
+		paths []string
+	}{{"test", impl.TestPaths}, {"implementation", impl.ImplementationPaths}} {
+		for _, prefix := range override.paths {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if (path == prefix || strings.HasPrefix(path, prefix+"/")) && len(prefix) > longest {
+				class, longest = override.class, len(prefix)
+			}
+		}
+	}
+	return class
+}
+
+// classifyPaths moves the files matched by the code and test queries which are forced into the other class by a
+// configured path. The files matched by both queries which are not forced into either are kept in both, they are
+// only read for their markers when the code is parsed. Returns the code files and the test files, sorted.
+// @llr REQ-TRAQ-SWL-125
+func (impl *jsonImplementation) classifyPaths(codeFiles, testFiles []string) ([]string, []string) {
+	isCode := make(map[string]bool)
+	isTest := make(map[string]bool)
+	for _, path := range codeFiles {
+		isCode[path] = true
+	}
+	for _, path := range testFiles {
+		isTest[path] = true
+	}
+
+	classifiedCode, classifiedTests := []string{}, []string{}
+	for _, path := range sortedUnion(isCode, isTest) {
+		switch impl.pathClass(path) {
+		case "test":
+			classifiedTests = append(classifiedTests, path)
+		case "implementation":
+			classifiedCode = append(classifiedCode, path)
+		default:
+			if isCode[path] {
+				classifiedCode = append(classifiedCode, path)
+			}
+			if isTest[path] {
+				classifiedTests = append(classifiedTests, path)
+			}
+		}
+	}
+	return classifiedCode, classifiedTests
+}
+
+// markedClass returns whether the file is forced into the tests or the implementation by a marker in the file.
+// Returns an empty string when it has no marker.
+// @llr REQ-TRAQ-SWL-125
+func markedClass(repoName repos.RepoName, path string) (string, error) {
+	fsPath, err := repos.PathInRepo(repoName, path)
+	if err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadFile(fsPath)
+	if err != nil {
+		return "", err
+	}
+	if marker := reFileClassMarker.FindSubmatch(content); marker != nil {
+		return string(marker[1]), nil
+	}
+	return "", nil
+}
+
+// ClassifiedFiles returns the code files and the test files of the implementation, the files marked as test or
+// implementation files being moved into their class. The files are read for their markers here rather than when the
+// configuration is parsed, so only the commands parsing the code read them.
+// @llr REQ-TRAQ-SWL-125
+func (impl *ArchImplementation) ClassifiedFiles(repoName repos.RepoName) ([]string, []string, error) {
+	isCode := make(map[string]bool)
+	isTest := make(map[string]bool)
+	for _, path := range impl.CodeFiles {
+		isCode[path] = true
+	}
+	for _, path := range impl.TestFiles {
+		isTest[path] = true
+	}
+
+	codeFiles, testFiles := []string{}, []string{}
+	for _, path := range sortedUnion(isCode, isTest) {
+		class, err := markedClass(repoName, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if class == "implementation" || (class == "" && isCode[path]) {
+			codeFiles = append(codeFiles, path)
+		}
+		if class == "test" || (class == "" && isTest[path]) {
+			testFiles = append(testFiles, path)
+		}
+	}
+	return codeFiles, testFiles, nil
+}
+
+// AmbiguousFiles returns the files of the implementation matched by both the code and the test queries which are
+// neither marked as test or implementation files nor in a configured path, sorted. Only these files are read.
+// @llr REQ-TRAQ-SWL-125
+func (impl *ArchImplementation) AmbiguousFiles(repoName repos.RepoName) ([]string, error) {
+	isTest := make(map[string]bool)
+	for _, path := range impl.TestFiles {
+		isTest[path] = true
+	}
+
+	var ambiguous []string
+	for _, path := range impl.CodeFiles {
+		if !isTest[path] {
+			continue
+		}
+		class, err := markedClass(repoName, path)
+		if err != nil {
+			return nil, err
+		}
+		if class == "" {
+			ambiguous = append(ambiguous, path)
+		}
+	}
+	sort.Strings(ambiguous)
+	return ambiguous, nil
+}
+
+// sortedUnion returns the paths of both sets, sorted
+// @llr REQ-TRAQ-SWL-125
+func sortedUnion(first, second map[string]bool) []string {
+	paths := make([]string, 0, len(first)+len(second))
+	for path := range first {
+		paths = append(paths, path)
+	}
+	for path := range second {
+		if !first[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
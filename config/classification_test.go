@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-125
+func TestConfig_ClassifyFiles(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"src/log.c":             "int log(void); // not a reqtraq:test-file\n",
+		"src/log_helpers.c":     "// reqtraq:test-file\nint helper(void);\n",
+		"src/fixtures/data.c":   "int data(void);\n",
+		"src/fixtures/keep/a.c": "int a(void);\n",
+		"src/mixed.c":           "int mixed(void);\n",
+		"test/log_test.c":       "/* reqtraq:implementation-file */\nint main(void);\n",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+	repos.RegisterRepository("classify", repos.RepoPath(dir))
+
+	impl := jsonImplementation{TestPaths: []string{"src/fixtures/"}, ImplementationPaths: []string{"src/fixtures/keep"}}
+	codeFiles, testFiles := impl.classifyPaths(
+		[]string{"src/log.c", "src/log_helpers.c", "src/fixtures/data.c", "src/fixtures/keep/a.c", "src/mixed.c"},
+		[]string{"src/mixed.c", "test/log_test.c"})
+	// The configured paths are applied without reading the files
+	assert.Equal(t, []string{"src/fixtures/keep/a.c", "src/log.c", "src/log_helpers.c", "src/mixed.c"}, codeFiles)
+	assert.Equal(t, []string{"src/fixtures/data.c", "src/mixed.c", "test/log_test.c"}, testFiles)
+
+	// The markers are applied when the files are read
+	archImpl := ArchImplementation{CodeFiles: codeFiles, TestFiles: testFiles}
+	codeFiles, testFiles, err := archImpl.ClassifiedFiles("classify")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"src/fixtures/keep/a.c", "src/log.c", "src/mixed.c", "test/log_test.c"}, codeFiles)
+	assert.Equal(t, []string{"src/fixtures/data.c", "src/log_helpers.c", "src/mixed.c"}, testFiles)
+	ambiguous, err := archImpl.AmbiguousFiles("classify")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"src/mixed.c"}, ambiguous)
+
+	// Forcing the file to one of the classes resolves the ambiguity
+	impl.TestPaths = append(impl.TestPaths, "src/mixed.c")
+	codeFiles, testFiles = impl.classifyPaths([]string{"src/mixed.c"}, []string{"src/mixed.c"})
+	assert.Empty(t, codeFiles)
+	assert.Equal(t, []string{"src/mixed.c"}, testFiles)
+	archImpl = ArchImplementation{CodeFiles: codeFiles, TestFiles: testFiles}
+	ambiguous, err = archImpl.AmbiguousFiles("classify")
+	assert.NoError(t, err)
+	assert.Empty(t, ambiguous)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "src/mixed.c"), []byte("// reqtraq:implementation-file\n"), 0644))
+	archImpl = ArchImplementation{CodeFiles: []string{"src/mixed.c"}, TestFiles: []string{"src/mixed.c"}}
+	ambiguous, err = archImpl.AmbiguousFiles("classify")
+	assert.NoError(t, err)
+	assert.Empty(t, ambiguous)
+
+	// A file which cannot be read fails the classification
+	archImpl = ArchImplementation{CodeFiles: []string{"src/missing.c"}}
+	_, _, err = archImpl.ClassifiedFiles("classify")
+	assert.Error(t, err)
+}
@@ -8,11 +8,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/daedaleanai/reqtraq/expr"
 	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/profile"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/pkg/errors"
 )
@@ -26,12 +32,19 @@ type Arch string
 type jsonRepoLink struct {
 	RepoName   repos.RepoName   `json:"repoName"`
 	RemotePath repos.RemotePath `json:"repoUrl"`
+	Prefixes   []ReqPrefix      `json:"prefixes"`
 }
 
 type jsonAttribute struct {
 	Name     string `json:"name"`
 	Required string `json:"required"`
 	Value    string `json:"value"`
+	Since    int    `json:"since"`
+}
+
+type jsonComputedAttribute struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
 }
 
 type jsonFileQueryBase struct {
@@ -57,6 +70,17 @@ type jsonImplementation struct {
 	CodeParser          string                        `json:"codeParser"`
 	CompilationDatabase string                        `json:"compilationDatabase"`
 	CompilerArguments   []string                      `json:"compilerArguments"`
+	FileTagExtensions   []string                      `json:"fileTagExtensions"`
+	TagMacros           []string                      `json:"tagMacros"`
+	Languages           []string                      `json:"languages"`
+	TestPaths           []string                      `json:"testPaths"`
+	ImplementationPaths []string                      `json:"implementationPaths"`
+}
+
+type jsonCodeParser struct {
+	Name      string   `json:"name"`
+	Command   string   `json:"command"`
+	Arguments []string `json:"arguments"`
 }
 
 type jsonParent struct {
@@ -74,18 +98,86 @@ type jsonDoc struct {
 	Path           string              `json:"path"`
 	Prefix         ReqPrefix           `json:"prefix"`
 	Level          ReqLevel            `json:"level"`
+	IDFormat       string              `json:"idFormat"`
 	Parent         jsonParents         `json:"parent"`
 	Attributes     []jsonAttribute     `json:"attributes"`
 	AsmAttributes  []jsonAttribute     `json:"asmAttributes"`
+	FlowColumns    []jsonAttribute     `json:"flowColumns"`
 	Implementation jsonImplementations `json:"implementation"`
+	Frozen         bool                `json:"frozen"`
+	Preset         string              `json:"preset"`
+	Fragments      []string            `json:"fragments"`
+	SchemaVersion  int                 `json:"schemaVersion"`
+	Doxygen        string              `json:"doxygen"`
+}
+
+type jsonOverride struct {
+	Variant string    `json:"variant"`
+	Path    string    `json:"path"`
+	Prefix  ReqPrefix `json:"prefix"`
+	Level   ReqLevel  `json:"level"`
 }
 
 type jsonConfig struct {
-	RepoName         repos.RepoName  `json:"repoName"`
-	CommonAttributes []jsonAttribute `json:"commonAttributes"`
-	ParentRepo       jsonRepoLink    `json:"parentRepository"`
-	ChildrenRepos    []jsonRepoLink  `json:"childrenRepositories"`
-	Docs             []jsonDoc       `json:"documents"`
+	RepoName           repos.RepoName          `json:"repoName"`
+	CommonAttributes   []jsonAttribute         `json:"commonAttributes"`
+	ComputedAttributes []jsonComputedAttribute `json:"computedAttributes"`
+	ParentRepo         jsonRepoLink            `json:"parentRepository"`
+	ChildrenRepos      []jsonRepoLink          `json:"childrenRepositories"`
+	Docs               []jsonDoc               `json:"documents"`
+	Overrides          []jsonOverride          `json:"overrides"`
+	CodeParsers        []jsonCodeParser        `json:"codeParsers"`
+	RulePlugins        []string                `json:"rulePlugins"`
+	// HTML templates rendered below each requirement of the reports
+	RequirementTemplates []string `json:"requirementTemplates"`
+	// Pointer, so the default threshold is used when it is not configured
+	DuplicateTextThreshold *float64            `json:"duplicateTextThreshold"`
+	ScoreWeights           *jsonScoreWeights   `json:"scoreWeights"`
+	CodeReviews            *jsonCodeReviews    `json:"codeReviews"`
+	RelatedChanges         *jsonRelatedChanges `json:"relatedChanges"`
+	BuildArtifacts         *jsonBuildArtifacts `json:"buildArtifacts"`
+	TestCoverage           *jsonTestCoverage   `json:"testCoverage"`
+	DocumentLimits         *jsonDocumentLimits `json:"documentLimits"`
+	ModelManifests         []string            `json:"modelManifests"`
+	AttributeOrder         []string            `json:"attributeOrder"`
+	AllocationAttribute    string              `json:"allocationAttribute"`
+	LintPolicy             map[string]string   `json:"lintPolicy"`
+	Webhooks               []jsonWebhook       `json:"webhooks"`
+}
+
+type jsonWebhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+type jsonCodeReviews struct {
+	NotesRef        string `json:"notesRef"`
+	AcceptedPattern string `json:"acceptedPattern"`
+}
+
+type jsonRelatedChanges struct {
+	Since string `json:"since"`
+}
+
+type jsonBuildArtifacts struct {
+	Manifest string `json:"manifest"`
+}
+
+type jsonTestCoverage struct {
+	Report string `json:"report"`
+}
+
+type jsonDocumentLimits struct {
+	MaxRequirements int `json:"maxRequirements"`
+	MaxBodyLength   int `json:"maxBodyLength"`
+}
+
+// Pointers, so the default weights are used for the criteria which are not configured
+type jsonScoreWeights struct {
+	Implemented *float64 `json:"implemented"`
+	Tested      *float64 `json:"tested"`
+	Reviewed    *float64 `json:"reviewed"`
+	NoIssues    *float64 `json:"noIssues"`
 }
 
 /// Types exported for application use
@@ -108,11 +200,22 @@ const (
 type Attribute struct {
 	Type  AttributeType
 	Value *regexp.Regexp
+	// The schema version which introduced the attribute, 0 for the attributes of the first version
+	Since int `json:",omitempty"`
+}
+
+// An attribute which is not written in the certification documents but derived from the requirements
+// graph by evaluating an expression
+type ComputedAttribute struct {
+	Name       string
+	Expression *expr.Expression
 }
 
 // A structure describing the implementation for a given certification document,
 // for architecture-dependent codebases
 type ArchImplementation struct {
+	// The files matched by the code and test queries, classified by the configured paths only. The files matched by
+	// both queries are in both until ClassifiedFiles reads their markers.
 	CodeFiles           []string
 	TestFiles           []string
 	CompilationDatabase string
@@ -124,13 +227,39 @@ type Implementation struct {
 	ArchImplementation
 	CodeParser string
 	Archs      map[Arch]ArchImplementation
+	// Extensions, e.g. `.yaml`, of the files which can be traced as a whole with a file-level tag
+	FileTagExtensions []string
+	// Names of the macros, e.g. `TEST`, whose expansions define functions which are tagged after the invocation of
+	// the macro, for the code parsers supporting it
+	TagMacros []string `json:",omitempty"`
+	// Languages, e.g. `C++`, of the files which are parsed, for the code parsers supporting languages. All the
+	// languages supported by the code parser are parsed when empty.
+	Languages []string `json:",omitempty"`
 }
 
 // The schema for requirements inside a certification document
 type Schema struct {
-	Requirements  *regexp.Regexp
-	Attributes    map[string]*Attribute
-	AsmAttributes map[string]*Attribute
+	Requirements *regexp.Regexp
+	Attributes   map[string]*Attribute
+	// The names of the attributes in the order they are declared: the attributes of the document, followed by the
+	// ones of its preset and the common attributes. The implicit Parents attribute is not listed.
+	AttributeNames []string `json:",omitempty"`
+	AsmAttributes  map[string]*Attribute
+	// The columns of the data and control flow tables in addition to the standard ones
+	FlowAttributes map[string]*Attribute `json:",omitempty"`
+	// The version of the schema, increased when attributes are introduced so the documents can be migrated one at a
+	// time
+	Version int `json:",omitempty"`
+}
+
+// The columns of the data and control flow tables which are always present, except the direction which is only
+// present in data flow tables
+var standardFlowColumns = map[string]bool{"CALLER": true, "FLOW TAG": true, "CALLEE": true, "DIRECTION": true, "DESCRIPTION": true, "DIAGRAM": true}
+
+// IsStandardFlowColumn returns whether the given upper case column name is a standard column of the flow tables
+// @llr REQ-TRAQ-SWL-114, REQ-TRAQ-SWL-185
+func IsStandardFlowColumn(name string) bool {
+	return standardFlowColumns[name]
 }
 
 // A requirement specification. Identifies the form of requirements in a document
@@ -140,6 +269,215 @@ type ReqSpec struct {
 	Re      *regexp.Regexp
 	AttrKey string
 	AttrVal *regexp.Regexp
+	// The format of the identifiers of the requirements, nil when using the default format
+	IDFormat *IDFormat `json:",omitempty"`
+}
+
+// The template of requirement identifiers used unless a document specifies its own, which results in
+// identifiers such as REQ-TRAQ-SWL-1 and ASM-TRAQ-SWL-1
+const DefaultIDTemplate = "{VARIANT}-{PREFIX}-{LEVEL}-{N}"
+
+// The format of requirement identifiers used unless a document specifies its own
+var DefaultIDFormat = MustParseIDFormat(DefaultIDTemplate)
+
+// The grammar of requirement identifiers in a document, described by a template made of literal text
+// and the placeholders `{VARIANT}` (REQ or ASM), `{PREFIX}`, `{LEVEL}` and `{N}` (the sequence number).
+// The sequence number can be given a fixed width with leading zeros, as in `SRS_{N:4}`.
+type IDFormat struct {
+	Template string
+	// Matches identifiers with any prefix and level
+	re *regexp.Regexp
+	// Index of the submatch of each placeholder in re
+	groups map[string]int
+	width  int
+}
+
+// The components of a requirement identifier. Components which are not part of the format are empty,
+// except for the variant which defaults to REQ.
+type IDParts struct {
+	ID      string
+	Variant string
+	Prefix  string
+	Level   string
+	Number  string
+	// Offset of the identifier in the parsed text
+	Offset int
+}
+
+var reIDPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+var reIDPlaceholderName = regexp.MustCompile(`^(VARIANT|PREFIX|LEVEL|N)(?::(\d+))?$`)
+
+// ParseIDFormat parses a template of requirement identifiers, making sure every placeholder is known
+// and the sequence number is present exactly once
+// @llr REQ-TRAQ-SWL-92
+func ParseIDFormat(template string) (*IDFormat, error) {
+	f := &IDFormat{Template: template, groups: map[string]int{}}
+
+	group := 1
+	for _, m := range reIDPlaceholder.FindAllStringSubmatch(template, -1) {
+		parts := reIDPlaceholderName.FindStringSubmatch(m[1])
+		if parts == nil {
+			return nil, fmt.Errorf("Unknown placeholder `%s` in requirement ID format `%s`", m[0], template)
+		}
+		if _, ok := f.groups[parts[1]]; ok {
+			return nil, fmt.Errorf("Placeholder `%s` appears more than once in requirement ID format `%s`", m[0], template)
+		}
+		if parts[2] != "" {
+			if parts[1] != "N" {
+				return nil, fmt.Errorf("Only the sequence number can have a width in requirement ID format `%s`", template)
+			}
+			f.width, _ = strconv.Atoi(parts[2])
+		}
+		f.groups[parts[1]] = group
+		group++
+	}
+	if _, ok := f.groups["N"]; !ok {
+		return nil, fmt.Errorf("Requirement ID format `%s` has no sequence number `{N}`", template)
+	}
+
+	var err error
+	f.re, err = regexp.Compile(f.render(regexp.QuoteMeta, `(REQ|ASM)`, `(\w+)`, `(\w+)`, `(\d+)`))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid requirement ID format `%s`", template)
+	}
+	return f, nil
+}
+
+// MustParseIDFormat parses a template of requirement identifiers and panics if it is invalid
+// @llr REQ-TRAQ-SWL-92
+func MustParseIDFormat(template string) *IDFormat {
+	f, err := ParseIDFormat(template)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// render replaces each placeholder of the template with the given values, applying the literal function
+// to the text in between
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) render(literal func(string) string, variant, prefix, level, number string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range reIDPlaceholder.FindAllStringSubmatchIndex(f.Template, -1) {
+		sb.WriteString(literal(f.Template[last:m[0]]))
+		switch name := strings.SplitN(f.Template[m[2]:m[3]], ":", 2)[0]; name {
+		case "VARIANT":
+			sb.WriteString(variant)
+		case "PREFIX":
+			sb.WriteString(prefix)
+		case "LEVEL":
+			sb.WriteString(level)
+		case "N":
+			sb.WriteString(number)
+		}
+		last = m[1]
+	}
+	sb.WriteString(literal(f.Template[last:]))
+	return sb.String()
+}
+
+// Regexp returns a regular expression matching identifiers of this format with any prefix and level
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) Regexp() *regexp.Regexp {
+	return f.re
+}
+
+// Pattern returns a regular expression, without capturing groups, matching requirements and assumptions of this format
+// with any prefix and level, to be combined with other expressions
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) Pattern() string {
+	return f.render(regexp.QuoteMeta, `(?:REQ|ASM)`, `\w+`, `\w+`, `\d+`)
+}
+
+// RequirementsRegexp returns a regular expression matching requirements and assumptions of this format
+// with the given prefix and level
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) RequirementsRegexp(prefix ReqPrefix, level ReqLevel) *regexp.Regexp {
+	return regexp.MustCompile(f.render(regexp.QuoteMeta, `(REQ|ASM)`,
+		regexp.QuoteMeta(string(prefix)), regexp.QuoteMeta(string(level)), `(\d+)`))
+}
+
+// LinkRegexp returns a regular expression matching requirements (but not assumptions) of this format
+// with the given prefix and level
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) LinkRegexp(prefix ReqPrefix, level ReqLevel) *regexp.Regexp {
+	return regexp.MustCompile(f.render(regexp.QuoteMeta, "REQ",
+		regexp.QuoteMeta(string(prefix)), regexp.QuoteMeta(string(level)), `(\d+)`))
+}
+
+// Format returns the identifier with the given components
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) Format(variant string, prefix ReqPrefix, level ReqLevel, number int) string {
+	identity := func(s string) string { return s }
+	return f.render(identity, variant, string(prefix), string(level), fmt.Sprintf("%0*d", f.width, number))
+}
+
+// Has returns true if the format contains the given placeholder (VARIANT, PREFIX, LEVEL or N). Formats
+// without a VARIANT do not allow assumptions.
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) Has(placeholder string) bool {
+	_, ok := f.groups[placeholder]
+	return ok
+}
+
+// Width returns the number of digits of the sequence number, or 0 if it has no fixed width
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) Width() int {
+	return f.width
+}
+
+// Find returns the components of the first identifier of this format found in the given text
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) Find(text string) (IDParts, bool) {
+	m := f.re.FindStringSubmatchIndex(text)
+	if m == nil {
+		return IDParts{}, false
+	}
+	submatch := func(name string) string {
+		if group, ok := f.groups[name]; ok {
+			return text[m[2*group]:m[2*group+1]]
+		}
+		return ""
+	}
+	parts := IDParts{
+		ID:      text[m[0]:m[1]],
+		Variant: submatch("VARIANT"),
+		Prefix:  submatch("PREFIX"),
+		Level:   submatch("LEVEL"),
+		Number:  submatch("N"),
+		Offset:  m[0],
+	}
+	if parts.Variant == "" {
+		parts.Variant = "REQ"
+	}
+	return parts, true
+}
+
+// MarshalText implements encoding.TextMarshaler, so that formats are stored by their template
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) MarshalText() ([]byte, error) {
+	return []byte(f.Template), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the stored template again
+// @llr REQ-TRAQ-SWL-92
+func (f *IDFormat) UnmarshalText(text []byte) error {
+	parsed, err := ParseIDFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*f = *parsed
+	return nil
+}
+
+// Format returns the format of the identifiers of the requirements matching the specification
+// @llr REQ-TRAQ-SWL-92
+func (req ReqSpec) Format() *IDFormat {
+	if req.IDFormat == nil {
+		return DefaultIDFormat
+	}
+	return req.IDFormat
 }
 
 // A link specification. Identifies valid child and parent ends of a link.
@@ -156,34 +494,302 @@ type Document struct {
 	LinkSpecs      []LinkSpec
 	Schema         Schema
 	Implementation []Implementation
+	// Frozen documents must not have open review comments
+	Frozen bool `json:",omitempty"`
+	// The files making up the document when its path is a directory, in order
+	Fragments []Fragment `json:",omitempty"`
+	// The directory of the Doxygen XML output the requirements of the document are also read from, relative to the
+	// root of the repository, empty if there is none
+	Doxygen string `json:",omitempty"`
+	// Whether the document has no markdown file, its requirements being only read from the Doxygen XML output
+	Virtual bool `json:",omitempty"`
+	// The attributes provided by the preset of the document, which common attributes replace
+	presetAttributes []string
+}
+
+// A document overriding the title, body and attributes of some requirements of a base document for a
+// variant of the product, e.g. a customer or product line
+type Override struct {
+	Variant string
+	Path    string
+	// The specification of the document defining the overridden requirements
+	Base ReqSpec
 }
 
 // A configuration for a single repository, which is made of documents.
 type RepoConfig struct {
 	Documents []Document
+	Overrides []Override `json:",omitempty"`
+}
+
+// A code parser implemented by an external program, which is declared in the configuration of a repository so
+// parsers can be distributed separately from reqtraq
+type ExternalCodeParser struct {
+	Name string
+	// The program to run, relative to the root of the repository declaring it if it is a relative path to a file
+	Command   string
+	Arguments []string
+	// The repository declaring the parser
+	RepoName repos.RepoName
+}
+
+// A Go plugin registering validation rules when loaded, declared in the configuration of a repository so
+// projects can ship their own checks
+type RulePlugin struct {
+	// The plugin file, relative to the root of the repository declaring it if it is a relative path
+	Path string
+	// The repository declaring the plugin
+	RepoName repos.RepoName
+}
+
+// A HTML template rendered below each requirement of the reports, declared in the configuration of a repository so
+// projects can show their own content, e.g. links to dashboards keyed by requirement ID
+type RequirementTemplate struct {
+	// The template file, relative to the root of the repository declaring it if it is a relative path
+	Path string
+	// The repository declaring the template
+	RepoName repos.RepoName
 }
 
 // A global configuration structure for a repo, its parents and its children.
 type Config struct {
 	TargetRepo repos.RepoName
 	Repos      map[repos.RepoName]RepoConfig
+	// Attributes computed for every requirement, in the order in which they must be evaluated
+	ComputedAttributes []ComputedAttribute
+	// Similarity of the body of a requirement to the body of a parent above which it is reported as a copy of
+	// it, 0 disables the check
+	DuplicateTextThreshold float64
+	// Weights of the criteria making up the completeness score of the documents
+	ScoreWeights ScoreWeights
+	// How to find out whether the changes of the implementation code were reviewed, nil if they are not checked
+	CodeReviews *CodeReviews `json:",omitempty"`
+	// Which commits are searched for the IDs of the requirements they relate to, nil if they are not searched
+	RelatedChanges *RelatedChanges `json:",omitempty"`
+	// The manifest of the build artifacts and of their sources, nil if the artifacts are not traced
+	BuildArtifacts *BuildArtifacts `json:",omitempty"`
+	// The report of the functions covered by each test, nil if the tests of the requirements are not inferred from
+	// the coverage
+	TestCoverage *TestCoverage `json:",omitempty"`
+	// The manifests of the model-based design artifacts linked to requirements, e.g. Simulink or SCADE blocks
+	ModelManifests []ModelManifest `json:",omitempty"`
+	// The soft limits on the size of the documents, beyond which splitting them is recommended
+	DocumentLimits DocumentLimits
+	// The canonical order of the attributes of the requirements, by uppercase name. The attributes which are not
+	// listed follow the listed ones. Empty if the order and the location of the attributes are not checked.
+	AttributeOrder []string `json:",omitempty"`
+	// The uppercase name of the attribute listing the architectures a requirement is allocated to, empty if the
+	// allocation of the requirements is not checked
+	AllocationAttribute string `json:",omitempty"`
+	// The endpoints notified when the graph served by the web interface is rebuilt
+	Webhooks []Webhook `json:",omitempty"`
+	// The severity of the issues of the lint checks, by check name. The checks which are not listed report their
+	// issues with their default severity.
+	LintPolicy map[string]LintSeverity `json:",omitempty"`
+	// Code parsers implemented by external programs, declared by any of the repositories
+	CodeParsers []ExternalCodeParser `json:",omitempty"`
+	// Go plugins registering validation rules, declared by any of the repositories
+	RulePlugins []RulePlugin `json:",omitempty"`
+	// Templates rendered below each requirement of the HTML reports, declared by any of the repositories
+	RequirementTemplates []RequirementTemplate `json:",omitempty"`
+	// The repositories declaring each requirement prefix, for the children repositories which were not parsed
+	// because only direct dependencies were selected
+	ExternalPrefixes map[ReqPrefix]repos.RepoName `json:",omitempty"`
+	// The levels declared as parents of each level of the document hierarchy, e.g. TEST-SWL, computed once when
+	// the configuration is parsed and derived again when it is loaded back, so it is left out of the exported graphs
+	LevelParents map[string][]string `json:"-"`
+	// The names of the common attributes in the order they are declared in the configuration files, only set while
+	// the configuration files are parsed
+	commonAttributeNames []string
+}
+
+// The similarity threshold used when the configuration of the target repository doesn't specify one
+const DefaultDuplicateTextThreshold = 0.9
+
+// LintSeverity is the severity of the issues of a lint check configured in the lint policy
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityNote    LintSeverity = "note"
+	// The check is disabled
+	LintSeverityOff LintSeverity = "off"
+)
+
+// The lint checks whose severity can be configured in the lint policy
+const (
+	// Requirements of the same document with the same title
+	LintCheckDuplicateTitle = "duplicateTitle"
+	// Assumptions without validation plan nor code checking them
+	LintCheckUnvalidatedAssumption = "unvalidatedAssumption"
+	// Requirements whose implementation is not part of any shipped build artifact
+	LintCheckUnshippedImplementation = "unshippedImplementation"
+	// Documents and requirement bodies exceeding the document limits
+	LintCheckDocumentLimits = "documentLimits"
+)
+
+// The names of the lint checks which can be configured in the lint policy
+var lintChecks = []string{LintCheckDuplicateTitle, LintCheckUnvalidatedAssumption, LintCheckUnshippedImplementation, LintCheckDocumentLimits}
+
+// DocumentLimits holds the soft limits on the size of each document, to keep them reviewable. A limit of 0 is not
+// checked.
+type DocumentLimits struct {
+	// The maximum number of requirements of a document which are not deleted
+	MaxRequirements int `json:",omitempty"`
+	// The maximum number of characters of the body of a requirement
+	MaxBodyLength int `json:",omitempty"`
+}
+
+// ScoreWeights holds the weight of each criterion in the completeness score of a document. A criterion with
+// a weight of 0 does not count.
+type ScoreWeights struct {
+	// Requirements with children requirements or, in documents with implementation, with implementation code
+	Implemented float64
+	// Requirements of documents with implementation which have test code
+	Tested float64
+	// Requirements without open review comments, whose implementation was last changed by accepted commits
+	Reviewed float64
+	// Requirements without issues
+	NoIssues float64
+}
+
+// CodeReviews describes how the review of a commit is recorded in its message or in its git notes, e.g. the
+// `Reviewed By:` line added by Phabricator or the `Code-Review+2:` line of the Gerrit review notes
+type CodeReviews struct {
+	// The git notes reference holding the review notes of the commits
+	NotesRef string
+	// Matches the message or the notes of the commits which were reviewed and accepted
+	Accepted *regexp.Regexp
+}
+
+// RelatedChanges describes which commits of the repositories are searched for the IDs of the requirements their
+// message mentions
+type RelatedChanges struct {
+	// Only the commits after this git reference are searched, all of them when empty
+	Since string `json:",omitempty"`
+}
+
+// Webhook is an HTTP endpoint to which a summary of the changes is posted when the graph served by the web interface
+// is rebuilt
+type Webhook struct {
+	URL string
+	// Additional headers of the requests, e.g. for authentication
+	Headers map[string]string `json:",omitempty"`
+}
+
+// BuildArtifacts locates the manifest produced by the build, listing the artifacts, e.g. binaries, and the source
+// files they are built from
+type BuildArtifacts struct {
+	// The manifest file, relative to the root of the repository declaring it if it is a relative path
+	Manifest string
+	// The repository declaring the manifest
+	RepoName repos.RepoName
+}
+
+// TestCoverage locates the coverage report produced by running the tests, listing the functions with their coverage
+// and the tests covering them
+type TestCoverage struct {
+	// The report file, relative to the root of the repository declaring it if it is a relative path
+	Report string
+	// The repository declaring the report
+	RepoName repos.RepoName
+}
+
+// ModelManifest locates a manifest generated by model-based design tooling, listing the blocks of a model, e.g. a
+// Simulink or SCADE model, with the requirements they implement
+type ModelManifest struct {
+	// The manifest file, relative to the root of the repository declaring it if it is a relative path
+	Path string
+	// The repository declaring the manifest
+	RepoName repos.RepoName
 }
 
+// The notes reference and the pattern of accepted commits used when the configuration of the target repository
+// doesn't specify them
+const (
+	DefaultReviewNotesRef        = "refs/notes/review"
+	DefaultReviewAcceptedPattern = `(?m)^(Reviewed-by|Reviewed By|Code-Review\+2):`
+)
+
+// The weights used when the configuration of the target repository doesn't specify them
+var DefaultScoreWeights = ScoreWeights{Implemented: 1, Tested: 1, Reviewed: 1, NoIssues: 1}
+
 // Selects whether all children of the parent repositories should be traversed as part of the
 // configuration or only parents are traversed
 var DirectDependenciesOnly bool = false
 
 // Top level function to parse the configuration file from the given path in the current repository
-// @llr REQ-TRAQ-SWL-53
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-106, REQ-TRAQ-SWL-108, REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-120, REQ-TRAQ-SWL-139, REQ-TRAQ-SWL-143, REQ-TRAQ-SWL-146, REQ-TRAQ-SWL-152, REQ-TRAQ-SWL-158, REQ-TRAQ-SWL-169, REQ-TRAQ-SWL-171, REQ-TRAQ-SWL-176, REQ-TRAQ-SWL-186, REQ-TRAQ-SWL-191
 func ParseConfig(repoPath repos.RepoPath) (Config, error) {
 	jsonConfig, err := readJsonConfigFromRepo(repoPath)
 	if err != nil {
 		return Config{}, errors.Wrapf(err, "The requested config path `%s` does not contain a valid repository", repoPath)
 	}
+	defer profile.Start("config parse", string(jsonConfig.RepoName), "")()
 
 	config := Config{
-		TargetRepo: jsonConfig.RepoName,
-		Repos:      make(map[repos.RepoName]RepoConfig),
+		TargetRepo:             jsonConfig.RepoName,
+		Repos:                  make(map[repos.RepoName]RepoConfig),
+		DuplicateTextThreshold: DefaultDuplicateTextThreshold,
+		ScoreWeights:           DefaultScoreWeights,
+	}
+	if jsonConfig.DuplicateTextThreshold != nil {
+		config.DuplicateTextThreshold = *jsonConfig.DuplicateTextThreshold
+		if config.DuplicateTextThreshold < 0 || config.DuplicateTextThreshold > 1 {
+			return Config{}, fmt.Errorf("The duplicate text threshold must be between 0 and 1, got %v", config.DuplicateTextThreshold)
+		}
+	}
+	if jsonConfig.ScoreWeights != nil {
+		if err := jsonConfig.ScoreWeights.apply(&config.ScoreWeights); err != nil {
+			return Config{}, err
+		}
+	}
+	if jsonConfig.CodeReviews != nil {
+		if config.CodeReviews, err = jsonConfig.CodeReviews.parse(); err != nil {
+			return Config{}, err
+		}
+	}
+	if jsonConfig.RelatedChanges != nil {
+		config.RelatedChanges = &RelatedChanges{Since: strings.TrimSpace(jsonConfig.RelatedChanges.Since)}
+	}
+	if jsonConfig.BuildArtifacts != nil {
+		manifest := strings.TrimSpace(jsonConfig.BuildArtifacts.Manifest)
+		if manifest == "" {
+			return Config{}, fmt.Errorf("The `manifest` of the build artifacts is required")
+		}
+		config.BuildArtifacts = &BuildArtifacts{Manifest: manifest, RepoName: jsonConfig.RepoName}
+	}
+	if jsonConfig.TestCoverage != nil {
+		report := strings.TrimSpace(jsonConfig.TestCoverage.Report)
+		if report == "" {
+			return Config{}, fmt.Errorf("The `report` of the test coverage is required")
+		}
+		config.TestCoverage = &TestCoverage{Report: report, RepoName: jsonConfig.RepoName}
+	}
+	if jsonConfig.DocumentLimits != nil {
+		if jsonConfig.DocumentLimits.MaxRequirements < 0 || jsonConfig.DocumentLimits.MaxBodyLength < 0 {
+			return Config{}, fmt.Errorf("The document limits must not be negative")
+		}
+		config.DocumentLimits = DocumentLimits(*jsonConfig.DocumentLimits)
+	}
+	for _, manifest := range jsonConfig.ModelManifests {
+		manifest = strings.TrimSpace(manifest)
+		if manifest == "" {
+			return Config{}, fmt.Errorf("The path of a model manifest is required")
+		}
+		config.ModelManifests = append(config.ModelManifests, ModelManifest{Path: manifest, RepoName: jsonConfig.RepoName})
+	}
+
+	if config.AttributeOrder, err = parseAttributeOrder(jsonConfig.AttributeOrder); err != nil {
+		return Config{}, err
+	}
+	config.AllocationAttribute = strings.ToUpper(strings.TrimSpace(jsonConfig.AllocationAttribute))
+	if config.LintPolicy, err = parseLintPolicy(jsonConfig.LintPolicy); err != nil {
+		return Config{}, err
+	}
+	if config.Webhooks, err = parseWebhooks(jsonConfig.Webhooks); err != nil {
+		return Config{}, err
 	}
 
 	commonAttributes := make(map[string]*Attribute)
@@ -193,11 +799,162 @@ func ParseConfig(repoPath repos.RepoPath) (Config, error) {
 		return Config{}, err
 	}
 
-	config.appendCommonAttributes(&commonAttributes)
+	config.appendCommonAttributes(&commonAttributes, config.commonAttributeNames)
+	config.commonAttributeNames = nil
+	config.resolveParentIDFormats()
+
+	// A child repository might have been parsed anyway, e.g. when it is the target repository
+	for prefix, repoName := range config.ExternalPrefixes {
+		if _, ok := config.Repos[repoName]; ok {
+			delete(config.ExternalPrefixes, prefix)
+		}
+	}
+
+	if err := config.checkHierarchy(); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.checkOverrides(); err != nil {
+		return Config{}, err
+	}
+
+	// Computed attributes must not shadow attributes written in the documents
+	for _, computedAttr := range config.ComputedAttributes {
+		for _, repoConfig := range config.Repos {
+			for _, doc := range repoConfig.Documents {
+				if _, ok := doc.Schema.Attributes[computedAttr.Name]; ok {
+					return Config{}, fmt.Errorf("Computed attribute with name `%s` is also an attribute of document `%s`",
+						computedAttr.Name, doc.Path)
+				}
+			}
+		}
+	}
 
 	return config, nil
 }
 
+// apply overrides the given weights with the configured ones, which must not be negative
+// @llr REQ-TRAQ-SWL-111
+func (w *jsonScoreWeights) apply(weights *ScoreWeights) error {
+	for _, weight := range []struct {
+		name       string
+		configured *float64
+		weight     *float64
+	}{
+		{"implemented", w.Implemented, &weights.Implemented},
+		{"tested", w.Tested, &weights.Tested},
+		{"reviewed", w.Reviewed, &weights.Reviewed},
+		{"noIssues", w.NoIssues, &weights.NoIssues},
+	} {
+		if weight.configured == nil {
+			continue
+		}
+		if *weight.configured < 0 {
+			return fmt.Errorf("The score weight `%s` must not be negative, got %v", weight.name, *weight.configured)
+		}
+		*weight.weight = *weight.configured
+	}
+	if weights.Implemented+weights.Tested+weights.Reviewed+weights.NoIssues == 0 {
+		return fmt.Errorf("At least one score weight must be positive")
+	}
+	return nil
+}
+
+// parse returns the code review settings, using the defaults for the settings which are not configured
+// @llr REQ-TRAQ-SWL-139
+func (c *jsonCodeReviews) parse() (*CodeReviews, error) {
+	reviews := &CodeReviews{NotesRef: c.NotesRef}
+	if reviews.NotesRef == "" {
+		reviews.NotesRef = DefaultReviewNotesRef
+	}
+	pattern := c.AcceptedPattern
+	if pattern == "" {
+		pattern = DefaultReviewAcceptedPattern
+	}
+	accepted, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid `acceptedPattern` of the code reviews")
+	}
+	reviews.Accepted = accepted
+	return reviews, nil
+}
+
+// parseAttributeOrder returns the canonical order of the attributes by uppercase name, accepting Parent for Parents
+// as the documents do
+// @llr REQ-TRAQ-SWL-143
+func parseAttributeOrder(names []string) ([]string, error) {
+	var order []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		key := strings.ToUpper(strings.TrimSpace(name))
+		if key == "PARENT" {
+			key = "PARENTS"
+		}
+		if key == "" {
+			return nil, fmt.Errorf("Empty attribute name in `attributeOrder`")
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("Attribute `%s` is listed more than once in `attributeOrder`", name)
+		}
+		seen[key] = true
+		order = append(order, key)
+	}
+	return order, nil
+}
+
+// parseWebhooks returns the configured webhooks, failing for URLs which are not absolute HTTP or HTTPS URLs
+// @llr REQ-TRAQ-SWL-169
+func parseWebhooks(webhooks []jsonWebhook) ([]Webhook, error) {
+	var parsed []Webhook
+	for _, webhook := range webhooks {
+		address := strings.TrimSpace(webhook.URL)
+		u, err := url.Parse(address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Invalid webhook URL `%s`, expected an absolute http or https URL", webhook.URL)
+		}
+		parsed = append(parsed, Webhook{URL: address, Headers: webhook.Headers})
+	}
+	return parsed, nil
+}
+
+// parseLintPolicy returns the severity of the configured lint checks by check name, failing for unknown checks and
+// severities
+// @llr REQ-TRAQ-SWL-152
+func parseLintPolicy(policy map[string]string) (map[string]LintSeverity, error) {
+	if len(policy) == 0 {
+		return nil, nil
+	}
+	severities := make(map[string]LintSeverity)
+	for check, value := range policy {
+		known := false
+		for _, lintCheck := range lintChecks {
+			known = known || check == lintCheck
+		}
+		if !known {
+			return nil, fmt.Errorf("Unknown lint check `%s` in `lintPolicy`, expected one of %s", check, strings.Join(lintChecks, ", "))
+		}
+		severity := LintSeverity(strings.ToLower(strings.TrimSpace(value)))
+		switch severity {
+		case LintSeverityError, LintSeverityWarning, LintSeverityNote, LintSeverityOff:
+		default:
+			return nil, fmt.Errorf("Invalid severity `%s` of lint check `%s`, expected `error`, `warning`, `note` or `off`", value, check)
+		}
+		severities[check] = severity
+	}
+	return severities, nil
+}
+
+// HasComputedAttribute returns true if a computed attribute with the given name is configured
+// @llr REQ-TRAQ-SWL-90
+func (config *Config) HasComputedAttribute(name string) bool {
+	for _, computedAttr := range config.ComputedAttributes {
+		if computedAttr.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Returns true if the document has associated implementation
 // @llr REQ-TRAQ-SWL-56
 func (doc *Document) HasImplementation() bool {
@@ -240,6 +997,199 @@ func (config *Config) FindCertdoc(path string) (repos.RepoName, *Document) {
 	return "", nil
 }
 
+// Finds the Document defining the requirements of the given specification or a nil document if it
+// is not found
+// @llr REQ-TRAQ-SWL-54
+func (config *Config) FindDocumentBySpec(reqSpec ReqSpec) *Document {
+	for repoName := range config.Repos {
+		for docIdx := range config.Repos[repoName].Documents {
+			if config.Repos[repoName].Documents[docIdx].MatchesSpec(reqSpec) {
+				return &config.Repos[repoName].Documents[docIdx]
+			}
+		}
+	}
+	return nil
+}
+
+// Checks that the overrides of every repository refer to an existing document and that no document is used as
+// override more than once.
+// @llr REQ-TRAQ-SWL-101
+func (config *Config) checkOverrides() error {
+	seen := make(map[string]bool)
+	for repoName, repoConfig := range config.Repos {
+		for _, override := range repoConfig.Overrides {
+			if config.FindDocumentBySpec(override.Base) == nil {
+				return fmt.Errorf("Override `%s` for variant `%s` in repo `%s` refers to document `%s` which is not configured",
+					override.Path, override.Variant, repoName, hierarchyName(override.Base))
+			}
+			key := fmt.Sprintf("%s:%s", repoName, override.Path)
+			if seen[key] {
+				return fmt.Errorf("Override `%s` in repo `%s` is configured more than once", override.Path, repoName)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// Returns the names of the variants with overrides in any repository, sorted alphabetically
+// @llr REQ-TRAQ-SWL-101
+func (config *Config) Variants() []string {
+	seen := make(map[string]bool)
+	variants := []string{}
+	for _, repoConfig := range config.Repos {
+		for _, override := range repoConfig.Overrides {
+			if !seen[override.Variant] {
+				seen[override.Variant] = true
+				variants = append(variants, override.Variant)
+			}
+		}
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// Returns the name of the level of the given specification in the document hierarchy, e.g. TEST-SYS
+// @llr REQ-TRAQ-SWL-94
+func hierarchyName(reqSpec ReqSpec) string {
+	return fmt.Sprintf("%s-%s", reqSpec.Prefix, reqSpec.Level)
+}
+
+// Builds a map from each level in the document hierarchy to the levels declared as its parents. Links
+// within the same level are not part of the hierarchy.
+// @llr REQ-TRAQ-SWL-94
+func (config *Config) hierarchyParents() map[string][]string {
+	parents := make(map[string][]string)
+	for _, link := range config.GetLinkedSpecs() {
+		child, parent := hierarchyName(link.Child), hierarchyName(link.Parent)
+		if child == parent {
+			continue
+		}
+		found := false
+		for _, p := range parents[child] {
+			found = found || p == parent
+		}
+		if !found {
+			parents[child] = append(parents[child], parent)
+		}
+	}
+	for child := range parents {
+		sort.Strings(parents[child])
+	}
+	return parents
+}
+
+// Returns the levels declared as parents of each level of the document hierarchy, computed once per configuration
+// @llr REQ-TRAQ-SWL-94
+func (config *Config) levelParents() map[string][]string {
+	if config.LevelParents == nil {
+		config.LevelParents = config.hierarchyParents()
+	}
+	return config.LevelParents
+}
+
+// Checks that the parent links between documents describe a hierarchy, i.e. that no document declares
+// a parent from a document below it, and returns an error describing the cycle of links otherwise, e.g.
+// `TEST-SYS -> TEST-SWL -> TEST-SWH -> TEST-SYS`.
+// @llr REQ-TRAQ-SWL-94
+func (config *Config) checkHierarchy() error {
+	config.LevelParents = config.hierarchyParents()
+	parents := config.LevelParents
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(level string) error
+	visit = func(level string) error {
+		state[level] = visiting
+		path = append(path, level)
+		for _, parent := range parents[level] {
+			switch state[parent] {
+			case visiting:
+				// Found a cycle: the path from the parent down to the current level, which declares the parent
+				// as its own parent again
+				for i := range path {
+					if path[i] == parent {
+						cycle := append(append([]string{}, path[i:]...), parent)
+						return fmt.Errorf("Invalid document hierarchy: the parent links form the cycle `%s`, each document declaring the next one as parent. "+
+							"Documents can only declare parents from documents above them.",
+							strings.Join(cycle, " -> "))
+					}
+				}
+			case unvisited:
+				if err := visit(parent); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[level] = visited
+		return nil
+	}
+
+	levels := make([]string, 0, len(parents))
+	for level := range parents {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	for _, level := range levels {
+		if state[level] == unvisited {
+			if err := visit(level); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns true if the documents matching the spec are below the ones matching the other spec in the
+// document hierarchy, i.e. the other spec is one of their direct or indirect parents
+// @llr REQ-TRAQ-SWL-94
+func (config *Config) IsBelow(reqSpec ReqSpec, other ReqSpec) bool {
+	parents := config.levelParents()
+	target := hierarchyName(other)
+	seen := make(map[string]bool)
+	pending := []string{hierarchyName(reqSpec)}
+	for len(pending) > 0 {
+		level := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, parent := range parents[level] {
+			if parent == target {
+				return true
+			}
+			if !seen[parent] {
+				seen[parent] = true
+				pending = append(pending, parent)
+			}
+		}
+	}
+	return false
+}
+
+// Describes the expected hierarchy from the top level document down to the given spec, e.g.
+// `TEST-SYS > TEST-SWH > TEST-SWL`. When a level has several parents, the first one in alphabetical
+// order is followed.
+// @llr REQ-TRAQ-SWL-94
+func (config *Config) DescribeHierarchy(reqSpec ReqSpec) string {
+	parents := config.levelParents()
+	chain := []string{hierarchyName(reqSpec)}
+	seen := map[string]bool{chain[0]: true}
+	for {
+		levelParents := parents[chain[0]]
+		if len(levelParents) == 0 || seen[levelParents[0]] {
+			break
+		}
+		seen[levelParents[0]] = true
+		chain = append([]string{levelParents[0]}, chain...)
+	}
+	return strings.Join(chain, " > ")
+}
+
 // Builds a map of the linked child -> parent requirement specification to know what specs are related by a
 // parent/children relationship
 // @llr REQ-TRAQ-SWL-54
@@ -260,13 +1210,26 @@ func (config *Config) GetLinkedSpecs() []LinkSpec {
 	return links
 }
 
-// Loads the information for the base repository from git
-// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-81
+// Loads the information for the base repository from git, or from the given directory when git is disabled
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-81, REQ-TRAQ-SWL-153
 func LoadBaseRepoInfo(repoPath string) {
+	if repos.NoGit {
+		absPath, err := filepath.Abs(repoPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		config, err := readJsonConfigFromRepo(repos.RepoPath(absPath))
+		if err != nil {
+			log.Fatalf("Error reading configuration in path `%s`: %v", absPath, err)
+		}
+		repos.SetBaseRepoInfo(repos.RepoPath(absPath), config.RepoName)
+		return
+	}
+
 	// See details about "working directory" in https://git-scm.com/docs/githooks
 	bare, err := linepipes.Single(linepipes.Run("git", "-C", repoPath, "rev-parse", "--is-bare-repository"))
 	if err != nil {
-		log.Fatalf("Failed to check Git repository type. Are you running reqtraq in a Git repo?\n%s", err)
+		log.Fatalf("Failed to check Git repository type. Are you running reqtraq in a Git repo? Use --no-git for plain directories.\n%s", err)
 	}
 	if bare == "true" {
 		log.Fatal("Reqtraq cannot be used in bare checkouts")
@@ -290,7 +1253,7 @@ func LoadBaseRepoInfo(repoPath string) {
 
 // Reads a json configuration file from the specified repository path.
 // The file is always located at reqtraq_config.json
-// @llr REQ-TRAQ-SWL-53
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-173
 func readJsonConfigFromRepo(repoPath repos.RepoPath) (jsonConfig, error) {
 	// Read parent config and parse that
 	configPath := filepath.Join(string(repoPath), "reqtraq_config.json")
@@ -306,6 +1269,9 @@ func readJsonConfigFromRepo(repoPath repos.RepoPath) (jsonConfig, error) {
 	if err := decoder.Decode(&config); err != nil {
 		return jsonConfig{}, errors.Wrapf(err, "Error while parsing configuration file `%s`", configPath)
 	}
+	if err := checkLegacyImplementations(configPath, data); err != nil {
+		return jsonConfig{}, err
+	}
 	return config, nil
 }
 
@@ -347,7 +1313,8 @@ func (op *jsonParents) UnmarshalJSON(data []byte) error {
 }
 
 // UnmarshalJSON implements the Unmarshaler interface for the jsonImplementations type, allowing the
-// 'Implementation' field to be a single struct or array of structs when defined in the config file.
+// 'Implementation' field to be a single struct or array of structs when defined in the config file. The single
+// struct is the legacy format, which is rejected from LegacyImplementationRemovalVersion.
 // @llr REQ-TRAQ-SWL-87
 func (impls *jsonImplementations) UnmarshalJSON(data []byte) error {
 	if len(data) == 0 {
@@ -379,9 +1346,13 @@ func (impls *jsonImplementations) UnmarshalJSON(data []byte) error {
 }
 
 // Parses an a single attribute from its json description
-// @llr REQ-TRAQ-SWL-53
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-165
 func parseAttribute(rawAttribute jsonAttribute) (string, Attribute, error) {
 	var attribute Attribute
+	if rawAttribute.Since < 0 {
+		return "", Attribute{}, fmt.Errorf("Attribute `%s` has a negative `since` schema version", rawAttribute.Name)
+	}
+	attribute.Since = rawAttribute.Since
 	switch rawAttribute.Required {
 	case "true":
 		attribute.Type = AttributeRequired
@@ -408,6 +1379,21 @@ func parseAttribute(rawAttribute jsonAttribute) (string, Attribute, error) {
 	return strings.ToUpper(rawAttribute.Name), attribute, nil
 }
 
+// parseComputedAttribute parses the expression of a computed attribute from its json description
+// @llr REQ-TRAQ-SWL-90
+func parseComputedAttribute(rawAttribute jsonComputedAttribute) (ComputedAttribute, error) {
+	if rawAttribute.Name == "" {
+		return ComputedAttribute{}, fmt.Errorf("Computed attribute with expression `%s` has no name", rawAttribute.Expression)
+	}
+
+	expression, err := expr.Parse(rawAttribute.Expression)
+	if err != nil {
+		return ComputedAttribute{}, errors.Wrapf(err, "Unable to parse expression of computed attribute `%s`", rawAttribute.Name)
+	}
+
+	return ComputedAttribute{Name: strings.ToUpper(rawAttribute.Name), Expression: expression}, nil
+}
+
 // parseParent creates a link specification from a json description
 // @llr REQ-TRAQ-SWL-53
 func parseParent(rawParent jsonParent, childPrefix ReqPrefix, childLevel ReqLevel) (LinkSpec, error) {
@@ -492,7 +1478,7 @@ func (fileQuery *jsonFileQuery) findAllMatchingFiles(repoName repos.RepoName, ar
 }
 
 // Parses an implementation of a document, returning it or an error if the parsing failed
-// @llr REQ-TRAQ-SWL-56, REQ-TRAQ-SWL-64, REQ-TRAQ-SWL-87
+// @llr REQ-TRAQ-SWL-56, REQ-TRAQ-SWL-64, REQ-TRAQ-SWL-87, REQ-TRAQ-SWL-105, REQ-TRAQ-SWL-125, REQ-TRAQ-SWL-138, REQ-TRAQ-SWL-170
 func parseImplementation(repoName repos.RepoName, impl *jsonImplementation) (*Implementation, error) {
 	parsedImpl := Implementation{
 		Archs: map[Arch]ArchImplementation{},
@@ -533,6 +1519,8 @@ func parseImplementation(repoName repos.RepoName, impl *jsonImplementation) (*Im
 			return nil, err
 		}
 
+		newArchEntry.CodeFiles, newArchEntry.TestFiles = impl.classifyPaths(codeFiles, testFiles)
+
 		parsedImpl.Archs[arch] = newArchEntry
 	}
 
@@ -547,6 +1535,7 @@ func parseImplementation(repoName repos.RepoName, impl *jsonImplementation) (*Im
 	if err != nil {
 		return nil, err
 	}
+	parsedImpl.CodeFiles, parsedImpl.TestFiles = impl.classifyPaths(codeFiles, testFiles)
 	parsedImpl.CompilationDatabase = impl.CompilationDatabase
 	parsedImpl.CompilerArguments = impl.CompilerArguments
 	if parsedImpl.CompilerArguments == nil {
@@ -557,12 +1546,34 @@ func parseImplementation(repoName repos.RepoName, impl *jsonImplementation) (*Im
 		// Default to ctags parser, which is always built-in
 		parsedImpl.CodeParser = "ctags"
 	}
+	for _, extension := range impl.FileTagExtensions {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension == "" || extension == "." {
+			return nil, fmt.Errorf("Invalid empty extension in `fileTagExtensions`")
+		}
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		parsedImpl.FileTagExtensions = append(parsedImpl.FileTagExtensions, extension)
+	}
+	for _, macro := range impl.TagMacros {
+		if strings.TrimSpace(macro) == "" {
+			return nil, fmt.Errorf("Invalid empty macro name in `tagMacros`")
+		}
+		parsedImpl.TagMacros = append(parsedImpl.TagMacros, strings.TrimSpace(macro))
+	}
+	for _, language := range impl.Languages {
+		if strings.TrimSpace(language) == "" {
+			return nil, fmt.Errorf("Invalid empty language in `languages`")
+		}
+		parsedImpl.Languages = append(parsedImpl.Languages, strings.TrimSpace(language))
+	}
 	return &parsedImpl, nil
 }
 
 // Parses a document, appending it to the list of documents for the repoConfig instance or returning
 // an error if the document is invalid.
-// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-56, REQ-TRAQ-SWL-64, REQ-TRAQ-SWL-87
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-56, REQ-TRAQ-SWL-64, REQ-TRAQ-SWL-87, REQ-TRAQ-SWL-114, REQ-TRAQ-SWL-123, REQ-TRAQ-SWL-130, REQ-TRAQ-SWL-165, REQ-TRAQ-SWL-188
 func (rc *RepoConfig) parseDocument(repoName repos.RepoName, doc jsonDoc) error {
 	var err error
 	parsedDoc := Document{
@@ -575,17 +1586,37 @@ func (rc *RepoConfig) parseDocument(repoName repos.RepoName, doc jsonDoc) error
 		Implementation: []Implementation{},
 	}
 
+	parsedDoc.Doxygen = strings.TrimSpace(doc.Doxygen)
 	_, err = repos.PathInRepo(repoName, doc.Path)
-	if err != nil {
+	if err != nil && os.IsNotExist(errors.Cause(err)) && parsedDoc.Doxygen != "" && len(doc.Fragments) == 0 {
+		// The requirements of the document are only read from the Doxygen XML output
+		parsedDoc.Virtual = true
+	} else if err != nil {
 		return errors.Wrapf(err, "Document with path `%s` in repo `%s` cannot be read", doc.Path, repoName)
+	} else {
+		parsedDoc.Fragments, err = documentFragments(repoName, doc.Path, doc.Fragments)
+		if err != nil {
+			return errors.Wrapf(err, "Document with path `%s` in repo `%s` cannot be read", doc.Path, repoName)
+		}
 	}
 
-	parsedDoc.ReqSpec = ReqSpec{Prefix: doc.Prefix, Level: doc.Level}
-	parsedDoc.Schema.Requirements, err = regexp.Compile(fmt.Sprintf("(REQ|ASM)-%s-%s-(\\d+)", parsedDoc.ReqSpec.Prefix, parsedDoc.ReqSpec.Level))
+	parsedDoc.presetAttributes, err = doc.applyPreset()
 	if err != nil {
 		return err
 	}
 
+	parsedDoc.ReqSpec = ReqSpec{Prefix: doc.Prefix, Level: doc.Level}
+	parsedDoc.Frozen = doc.Frozen
+	parsedDoc.Schema.Version = doc.SchemaVersion
+	if doc.IDFormat != "" {
+		parsedDoc.ReqSpec.IDFormat, err = ParseIDFormat(doc.IDFormat)
+		if err != nil {
+			return errors.Wrapf(err, "Document with path `%s` in repo `%s` has an invalid ID format", doc.Path, repoName)
+		}
+	}
+	idFormat := parsedDoc.ReqSpec.Format()
+	parsedDoc.Schema.Requirements = idFormat.RequirementsRegexp(parsedDoc.ReqSpec.Prefix, parsedDoc.ReqSpec.Level)
+
 	for _, rawAttribute := range doc.Attributes {
 		parsedName, parsedAttr, err := parseAttribute(rawAttribute)
 		if err != nil {
@@ -596,7 +1627,14 @@ func (rc *RepoConfig) parseDocument(repoName repos.RepoName, doc jsonDoc) error
 			return fmt.Errorf(`Invalid attribute Parents specified in reqtraq_config.json.
 The parents attribute is implicit from the parent declaration in the document`)
 		}
+		if parsedAttr.Since > doc.SchemaVersion {
+			return fmt.Errorf("Attribute `%s` of document `%s` in repo `%s` is introduced in schema version %d, after the schema version %d of the document",
+				rawAttribute.Name, doc.Path, repoName, parsedAttr.Since, doc.SchemaVersion)
+		}
 
+		if _, ok := parsedDoc.Schema.Attributes[parsedName]; !ok {
+			parsedDoc.Schema.AttributeNames = append(parsedDoc.Schema.AttributeNames, parsedName)
+		}
 		parsedDoc.Schema.Attributes[parsedName] = &parsedAttr
 	}
 
@@ -607,6 +1645,8 @@ The parents attribute is implicit from the parent declaration in the document`)
 			if err != nil {
 				return err
 			}
+			link.Child.IDFormat = parsedDoc.ReqSpec.IDFormat
+			link.Child.Re = idFormat.LinkRegexp(doc.Prefix, doc.Level)
 			parsedDoc.LinkSpecs = append(parsedDoc.LinkSpecs, link)
 		}
 
@@ -632,10 +1672,26 @@ The parents attribute for assumptions is implicit and refers to requirements in
 		parsedDoc.Schema.AsmAttributes[parsedName] = &parsedAttr
 	}
 
+	for _, rawColumn := range doc.FlowColumns {
+		parsedName, parsedAttr, err := parseAttribute(rawColumn)
+		if err != nil {
+			return err
+		}
+
+		if IsStandardFlowColumn(parsedName) {
+			return fmt.Errorf("Flow column `%s` of document `%s` is a standard column of the flow tables", rawColumn.Name, doc.Path)
+		}
+
+		if parsedDoc.Schema.FlowAttributes == nil {
+			parsedDoc.Schema.FlowAttributes = make(map[string]*Attribute)
+		}
+		parsedDoc.Schema.FlowAttributes[parsedName] = &parsedAttr
+	}
+
 	// Add parents attribute for assumptions
 	parsedDoc.Schema.AsmAttributes["PARENTS"] = &Attribute{
 		Type:  AttributeRequired,
-		Value: regexp.MustCompile(fmt.Sprintf("REQ-%s-%s-(\\d+)", parsedDoc.ReqSpec.Prefix, parsedDoc.ReqSpec.Level)),
+		Value: idFormat.LinkRegexp(parsedDoc.ReqSpec.Prefix, parsedDoc.ReqSpec.Level),
 	}
 
 	for _, impl := range doc.Implementation {
@@ -651,16 +1707,21 @@ The parents attribute for assumptions is implicit and refers to requirements in
 	return nil
 }
 
-// Appends the common attributes to the document and exits with an error if some attribute is
-// already defined by the document's attributes.
-// @llr REQ-TRAQ-SWL-53
-func (doc *Document) appendCommonAttributes(commonAttributes *map[string]*Attribute) error {
-	for attrName := range *commonAttributes {
-		if _, ok := doc.Schema.Attributes[attrName]; ok {
+// Appends the common attributes, whose names are given in the order they are declared, to the document and exits
+// with an error if some attribute is already defined by the document's attributes. Attributes provided by the preset
+// of the document are replaced, keeping their position.
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-123, REQ-TRAQ-SWL-132
+func (doc *Document) appendCommonAttributes(commonAttributes *map[string]*Attribute, names []string) error {
+	for _, attrName := range names {
+		_, ok := doc.Schema.Attributes[attrName]
+		if ok && !doc.isPresetAttribute(attrName) {
 			return fmt.Errorf("Document with path `%s` redefines attribute with name `%s`, but it is listed as a common attribute",
 				doc.Path, attrName)
 		}
 
+		if !ok {
+			doc.Schema.AttributeNames = append(doc.Schema.AttributeNames, attrName)
+		}
 		doc.Schema.Attributes[attrName] = (*commonAttributes)[attrName]
 	}
 	return nil
@@ -668,7 +1729,7 @@ func (doc *Document) appendCommonAttributes(commonAttributes *map[string]*Attrib
 
 // Parses a configuration file into the config instance, recursing into each child (if `DirectDependenciesOnly` is not selected)
 // until all configuration files have been parsed. It also parses parent repositories (if any).
-// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-52, REQ-TRAQ-SWL-68
+// @llr REQ-TRAQ-SWL-53, REQ-TRAQ-SWL-52, REQ-TRAQ-SWL-68, REQ-TRAQ-SWL-117, REQ-TRAQ-SWL-120, REQ-TRAQ-SWL-131, REQ-TRAQ-SWL-161
 func (config *Config) parseConfigFile(jsonConfig jsonConfig, commonAttributes *map[string]*Attribute) error {
 	repoConfig := RepoConfig{}
 
@@ -692,6 +1753,46 @@ func (config *Config) parseConfigFile(jsonConfig jsonConfig, commonAttributes *m
 		}
 
 		(*commonAttributes)[parsedName] = &parsedAttr
+		config.commonAttributeNames = append(config.commonAttributeNames, parsedName)
+	}
+
+	for _, computedAttr := range jsonConfig.ComputedAttributes {
+		parsedAttr, err := parseComputedAttribute(computedAttr)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid computed attribute in config for repo `%s`", jsonConfig.RepoName)
+		}
+
+		if config.HasComputedAttribute(parsedAttr.Name) {
+			return fmt.Errorf("Computed attribute with name `%s` found in config for repo `%s` is already defined elsewhere",
+				parsedAttr.Name, jsonConfig.RepoName)
+		}
+
+		config.ComputedAttributes = append(config.ComputedAttributes, parsedAttr)
+	}
+
+	for _, codeParser := range jsonConfig.CodeParsers {
+		if codeParser.Name == "" || codeParser.Command == "" {
+			return fmt.Errorf("Code parser in config for repo `%s` must specify a name and a command", jsonConfig.RepoName)
+		}
+		for _, other := range config.CodeParsers {
+			if other.Name == codeParser.Name {
+				return fmt.Errorf("Code parser with name `%s` found in config for repo `%s` is already defined elsewhere",
+					codeParser.Name, jsonConfig.RepoName)
+			}
+		}
+		config.CodeParsers = append(config.CodeParsers, ExternalCodeParser{
+			Name:      codeParser.Name,
+			Command:   codeParser.Command,
+			Arguments: codeParser.Arguments,
+			RepoName:  jsonConfig.RepoName,
+		})
+	}
+
+	for _, path := range jsonConfig.RulePlugins {
+		config.RulePlugins = append(config.RulePlugins, RulePlugin{Path: path, RepoName: jsonConfig.RepoName})
+	}
+	for _, path := range jsonConfig.RequirementTemplates {
+		config.RequirementTemplates = append(config.RequirementTemplates, RequirementTemplate{Path: path, RepoName: jsonConfig.RepoName})
 	}
 
 	for _, doc := range jsonConfig.Docs {
@@ -701,6 +1802,17 @@ func (config *Config) parseConfigFile(jsonConfig jsonConfig, commonAttributes *m
 		}
 	}
 
+	for _, override := range jsonConfig.Overrides {
+		if override.Variant == "" || override.Path == "" {
+			return fmt.Errorf("Override in config for repo `%s` must specify a variant and a path", jsonConfig.RepoName)
+		}
+		repoConfig.Overrides = append(repoConfig.Overrides, Override{
+			Variant: override.Variant,
+			Path:    override.Path,
+			Base:    ReqSpec{Prefix: override.Prefix, Level: override.Level},
+		})
+	}
+
 	config.Repos[jsonConfig.RepoName] = repoConfig
 
 	// Parse any children it has if we are not just checking direct dependencies
@@ -727,6 +1839,15 @@ func (config *Config) parseConfigFile(jsonConfig jsonConfig, commonAttributes *m
 				return err
 			}
 		}
+	} else {
+		for _, childRepo := range jsonConfig.ChildrenRepos {
+			for _, prefix := range childRepo.Prefixes {
+				if config.ExternalPrefixes == nil {
+					config.ExternalPrefixes = make(map[ReqPrefix]repos.RepoName)
+				}
+				config.ExternalPrefixes[prefix] = childRepo.RepoName
+			}
+		}
 	}
 
 	// Parse the parent if there is one
@@ -752,14 +1873,59 @@ func (config *Config) parseConfigFile(jsonConfig jsonConfig, commonAttributes *m
 	return config.parseConfigFile(parentConfig, commonAttributes)
 }
 
+// Updates the parent end of the link specifications of every document with the ID format of the
+// document defining the parent requirements, which is only known once all configurations are parsed
+// @llr REQ-TRAQ-SWL-92
+func (config *Config) resolveParentIDFormats() {
+	for _, repoConfig := range config.Repos {
+		for docIdx := range repoConfig.Documents {
+			linkSpecs := repoConfig.Documents[docIdx].LinkSpecs
+			for linkIdx := range linkSpecs {
+				parent := &linkSpecs[linkIdx].Parent
+				parentDoc := config.FindDocumentBySpec(*parent)
+				if parentDoc == nil || parentDoc.ReqSpec.IDFormat == nil {
+					continue
+				}
+				parent.IDFormat = parentDoc.ReqSpec.IDFormat
+				parent.Re = parent.IDFormat.LinkRegexp(parent.Prefix, parent.Level)
+			}
+		}
+	}
+}
+
+// IDFormats returns the formats of the identifiers of the requirements of all the documents, once each and ordered by
+// template. The default format is always included, as the code can reference requirements of repositories which are
+// not configured.
+// @llr REQ-TRAQ-SWL-92
+func (config *Config) IDFormats() []*IDFormat {
+	formats := map[string]*IDFormat{DefaultIDFormat.Template: DefaultIDFormat}
+	for _, repoConfig := range config.Repos {
+		for _, doc := range repoConfig.Documents {
+			if doc.ReqSpec.IDFormat != nil {
+				formats[doc.ReqSpec.IDFormat.Template] = doc.ReqSpec.IDFormat
+			}
+		}
+	}
+	templates := make([]string, 0, len(formats))
+	for template := range formats {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	result := make([]*IDFormat, 0, len(templates))
+	for _, template := range templates {
+		result = append(result, formats[template])
+	}
+	return result
+}
+
 // Appends common attributes to each of the document's attributes to build a comprehensive list of
 // attributes per document. If any of the documents already contrains the attribute it will exit
 // with an error to let the user know about this duplication
 // @llr REQ-TRAQ-SWL-53
-func (config *Config) appendCommonAttributes(commonAttributes *map[string]*Attribute) error {
+func (config *Config) appendCommonAttributes(commonAttributes *map[string]*Attribute, names []string) error {
 	for repoName := range config.Repos {
 		for docIndex := range config.Repos[repoName].Documents {
-			err := config.Repos[repoName].Documents[docIndex].appendCommonAttributes(commonAttributes)
+			err := config.Repos[repoName].Documents[docIndex].appendCommonAttributes(commonAttributes, names)
 			if err != nil {
 				return err
 			}
@@ -57,6 +57,7 @@ func TestConfig_ParseConfig(t *testing.T) {
 					"VERIFICATION":  commonAttributes["VERIFICATION"],
 					"SAFETY IMPACT": commonAttributes["SAFETY IMPACT"],
 				},
+				AttributeNames: []string{"RATIONALE", "VERIFICATION", "SAFETY IMPACT"},
 				AsmAttributes: map[string]*Attribute{
 					"PARENTS": {
 						Value: regexp.MustCompile("REQ-TEST-SYS-(\\d+)"),
@@ -99,6 +100,7 @@ func TestConfig_ParseConfig(t *testing.T) {
 						Type:  AttributeAny,
 					},
 				},
+				AttributeNames: []string{"RATIONALE", "VERIFICATION", "SAFETY IMPACT"},
 				AsmAttributes: map[string]*Attribute{
 					"PARENTS": {
 						Value: regexp.MustCompile("REQ-TEST-SWH-(\\d+)"),
@@ -262,6 +264,7 @@ func TestConfig_ParseConfigOnlyDirectDeps(t *testing.T) {
 					"VERIFICATION":  commonAttributes["VERIFICATION"],
 					"SAFETY IMPACT": commonAttributes["SAFETY IMPACT"],
 				},
+				AttributeNames: []string{"RATIONALE", "VERIFICATION", "SAFETY IMPACT"},
 				AsmAttributes: map[string]*Attribute{
 					"PARENTS": {
 						Value: regexp.MustCompile("REQ-TEST-SYS-(\\d+)"),
@@ -304,6 +307,7 @@ func TestConfig_ParseConfigOnlyDirectDeps(t *testing.T) {
 						Type:  AttributeAny,
 					},
 				},
+				AttributeNames: []string{"RATIONALE", "VERIFICATION", "SAFETY IMPACT"},
 				AsmAttributes: map[string]*Attribute{
 					"PARENTS": {
 						Value: regexp.MustCompile("REQ-TEST-SWH-(\\d+)"),
@@ -415,6 +419,7 @@ func TestConfig_ParseConfigLibClang(t *testing.T) {
 					"VERIFICATION":  commonAttributes["VERIFICATION"],
 					"SAFETY IMPACT": commonAttributes["SAFETY IMPACT"],
 				},
+				AttributeNames: []string{"RATIONALE", "VERIFICATION", "SAFETY IMPACT"},
 				AsmAttributes: map[string]*Attribute{
 					"PARENTS": {
 						Value: regexp.MustCompile("REQ-TEST-SYS-(\\d+)"),
@@ -459,6 +464,7 @@ func TestConfig_ParseConfigLibClang(t *testing.T) {
 						Type:  AttributeAny,
 					},
 				},
+				AttributeNames: []string{"RATIONALE", "VERIFICATION", "SAFETY IMPACT"},
 				AsmAttributes: map[string]*Attribute{
 					"PARENTS": {
 						Value: regexp.MustCompile("REQ-TEST-SWH-(\\d+)"),
@@ -533,3 +539,478 @@ func TestConfig_ParseConfigLibClang(t *testing.T) {
 	assert.Equal(t, config.Repos["libclangtest"].Documents[2].Implementation[1].CompilationDatabase, "")
 	assert.Equal(t, config.Repos["libclangtest"].Documents[2].Implementation[1].CompilerArguments, []string{})
 }
+
+// @llr REQ-TRAQ-SWL-90
+func TestConfig_ParseComputedAttributes(t *testing.T) {
+	config := Config{Repos: make(map[repos.RepoName]RepoConfig)}
+	commonAttributes := make(map[string]*Attribute)
+
+	err := config.parseConfigFile(jsonConfig{
+		RepoName: "repo",
+		ComputedAttributes: []jsonComputedAttribute{
+			{Name: "Traced", Expression: "implemented && tested"},
+			{Name: "Untraced", Expression: "!attr(TRACED)"},
+		},
+	}, &commonAttributes)
+	assert.NoError(t, err)
+	assert.Len(t, config.ComputedAttributes, 2)
+	assert.Equal(t, "TRACED", config.ComputedAttributes[0].Name)
+	assert.Equal(t, "implemented && tested", config.ComputedAttributes[0].Expression.String())
+	assert.Equal(t, "UNTRACED", config.ComputedAttributes[1].Name)
+	assert.True(t, config.HasComputedAttribute("TRACED"))
+	assert.False(t, config.HasComputedAttribute("VERIFICATION"))
+
+	err = config.parseConfigFile(jsonConfig{
+		RepoName: "other",
+		ComputedAttributes: []jsonComputedAttribute{
+			{Name: "traced", Expression: "true"},
+		},
+	}, &commonAttributes)
+	assert.EqualError(t, err, "Computed attribute with name `TRACED` found in config for repo `other` is already defined elsewhere")
+
+	_, err = parseComputedAttribute(jsonComputedAttribute{Name: "Broken", Expression: "implemented &&"})
+	assert.Error(t, err)
+
+	_, err = parseComputedAttribute(jsonComputedAttribute{Expression: "true"})
+	assert.EqualError(t, err, "Computed attribute with expression `true` has no name")
+}
+
+// @llr REQ-TRAQ-SWL-111
+func TestConfig_ScoreWeights(t *testing.T) {
+	zero, two, negative := 0.0, 2.0, -1.0
+
+	weights := DefaultScoreWeights
+	assert.NoError(t, (&jsonScoreWeights{Tested: &two, Reviewed: &zero}).apply(&weights))
+	assert.Equal(t, ScoreWeights{Implemented: 1, Tested: 2, Reviewed: 0, NoIssues: 1}, weights)
+
+	weights = DefaultScoreWeights
+	assert.EqualError(t, (&jsonScoreWeights{NoIssues: &negative}).apply(&weights),
+		"The score weight `noIssues` must not be negative, got -1")
+
+	weights = DefaultScoreWeights
+	assert.EqualError(t, (&jsonScoreWeights{Implemented: &zero, Tested: &zero, Reviewed: &zero, NoIssues: &zero}).apply(&weights),
+		"At least one score weight must be positive")
+}
+
+// @llr REQ-TRAQ-SWL-92
+func TestConfig_ParseIDFormat(t *testing.T) {
+	assert.Equal(t, `(REQ|ASM)-(\w+)-(\w+)-(\d+)`, DefaultIDFormat.Regexp().String())
+	assert.Equal(t, `(REQ|ASM)-TEST-SYS-(\d+)`, DefaultIDFormat.RequirementsRegexp("TEST", "SYS").String())
+	assert.Equal(t, `REQ-TEST-SYS-(\d+)`, DefaultIDFormat.LinkRegexp("TEST", "SYS").String())
+	assert.Equal(t, "ASM-TEST-SYS-12", DefaultIDFormat.Format("ASM", "TEST", "SYS", 12))
+
+	f, err := ParseIDFormat("SRS_{N:4}")
+	assert.NoError(t, err)
+	assert.False(t, f.Has("VARIANT"))
+	assert.Equal(t, 4, f.Width())
+	assert.Equal(t, "SRS_0012", f.Format("REQ", "PART", "SYS", 12))
+	assert.Equal(t, `SRS_(\d+)`, f.LinkRegexp("PART", "SYS").String())
+	assert.Equal(t, `SRS_\d+`, f.Pattern())
+	assert.Equal(t, `(?:REQ|ASM)-\w+-\w+-\d+`, DefaultIDFormat.Pattern())
+
+	// The formats of all the documents are listed once, with the default one
+	config := Config{Repos: map[repos.RepoName]RepoConfig{
+		"a": {Documents: []Document{{ReqSpec: ReqSpec{IDFormat: f}}, {}}},
+		"b": {Documents: []Document{{ReqSpec: ReqSpec{IDFormat: MustParseIDFormat("SRS_{N:4}")}}}},
+	}}
+	formats := config.IDFormats()
+	if assert.Len(t, formats, 2) {
+		assert.Equal(t, "SRS_{N:4}", formats[0].Template)
+		assert.Same(t, DefaultIDFormat, formats[1])
+	}
+
+	parts, ok := f.Find("see SRS_0012 for details")
+	assert.True(t, ok)
+	assert.Equal(t, IDParts{ID: "SRS_0012", Variant: "REQ", Number: "0012", Offset: 4}, parts)
+
+	f, err = ParseIDFormat("{LEVEL}.{VARIANT}.{PREFIX}.{N}")
+	assert.NoError(t, err)
+	parts, ok = f.Find("SWL.ASM.TEST.3")
+	assert.True(t, ok)
+	assert.Equal(t, IDParts{ID: "SWL.ASM.TEST.3", Variant: "ASM", Prefix: "TEST", Level: "SWL", Number: "3"}, parts)
+	_, ok = f.Find("SWL-ASM-TEST-3")
+	assert.False(t, ok)
+
+	for _, template := range []string{"SRS_", "{N}-{N}", "{PREFIX:2}-{N}", "{NUMBER}"} {
+		_, err := ParseIDFormat(template)
+		assert.Error(t, err, template)
+	}
+
+	text, err := f.MarshalText()
+	assert.NoError(t, err)
+	var reloaded IDFormat
+	assert.NoError(t, reloaded.UnmarshalText(text))
+	assert.Equal(t, *f, reloaded)
+}
+
+// @llr REQ-TRAQ-SWL-94
+func TestConfig_CheckHierarchy(t *testing.T) {
+	spec := func(level ReqLevel) ReqSpec {
+		return ReqSpec{Prefix: "TEST", Level: level}
+	}
+	document := func(level ReqLevel, parents ...ReqLevel) Document {
+		doc := Document{ReqSpec: spec(level)}
+		for _, parent := range parents {
+			doc.LinkSpecs = append(doc.LinkSpecs, LinkSpec{Child: spec(level), Parent: spec(parent)})
+		}
+		return doc
+	}
+
+	config := Config{Repos: map[repos.RepoName]RepoConfig{
+		"repo": {Documents: []Document{
+			document("SYS", "SYS"),
+			document("SWH", "SYS"),
+			document("SWL", "SWH"),
+		}},
+	}}
+	assert.NoError(t, config.checkHierarchy())
+	assert.True(t, config.IsBelow(spec("SWL"), spec("SYS")))
+	assert.True(t, config.IsBelow(spec("SWH"), spec("SYS")))
+	assert.False(t, config.IsBelow(spec("SYS"), spec("SWL")))
+	assert.False(t, config.IsBelow(spec("SYS"), spec("SYS")))
+	assert.Equal(t, "TEST-SYS > TEST-SWH > TEST-SWL", config.DescribeHierarchy(spec("SWL")))
+
+	// The system level declares a software level as parent
+	config.Repos["repo"].Documents[0] = document("SYS", "SWL")
+	assert.EqualError(t, config.checkHierarchy(),
+		"Invalid document hierarchy: the parent links form the cycle `TEST-SWH -> TEST-SYS -> TEST-SWL -> TEST-SWH`, each document declaring the next one as parent. "+
+			"Documents can only declare parents from documents above them.")
+}
+
+// @llr REQ-TRAQ-SWL-101
+func TestConfig_CheckOverrides(t *testing.T) {
+	config := Config{Repos: map[repos.RepoName]RepoConfig{
+		"repo": {
+			Documents: []Document{{Path: "TEST-137-SRD.md", ReqSpec: ReqSpec{Prefix: "TEST", Level: "SWH"}}},
+			Overrides: []Override{
+				{Variant: "ACME", Path: "acme/TEST-137-SRD.md", Base: ReqSpec{Prefix: "TEST", Level: "SWH"}},
+				{Variant: "BETA", Path: "beta/TEST-137-SRD.md", Base: ReqSpec{Prefix: "TEST", Level: "SWH"}},
+				{Variant: "ACME", Path: "acme/extra.md", Base: ReqSpec{Prefix: "TEST", Level: "SWH"}},
+			},
+		},
+	}}
+	assert.NoError(t, config.checkOverrides())
+	assert.Equal(t, []string{"ACME", "BETA"}, config.Variants())
+
+	repoConfig := config.Repos["repo"]
+	repoConfig.Overrides = append(repoConfig.Overrides, Override{Variant: "ACME", Path: "acme/TEST-138-SDD.md", Base: ReqSpec{Prefix: "TEST", Level: "SWL"}})
+	config.Repos["repo"] = repoConfig
+	assert.EqualError(t, config.checkOverrides(),
+		"Override `acme/TEST-138-SDD.md` for variant `ACME` in repo `repo` refers to document `TEST-SWL` which is not configured")
+
+	repoConfig.Overrides[3] = Override{Variant: "BETA", Path: "acme/extra.md", Base: ReqSpec{Prefix: "TEST", Level: "SWH"}}
+	assert.EqualError(t, config.checkOverrides(), "Override `acme/extra.md` in repo `repo` is configured more than once")
+}
+
+// @llr REQ-TRAQ-SWL-114
+func TestConfig_FlowColumns(t *testing.T) {
+	repos.RegisterRepository(repos.RepoName("projectA"), repos.RepoPath("../testdata/projectA"))
+
+	var rc RepoConfig
+	assert.NoError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Level: "SWH",
+		FlowColumns: []jsonAttribute{{Name: "Protocol", Value: "^(CAN|UART)$"}, {Name: "Rate", Required: "false"}}}))
+	columns := rc.Documents[0].Schema.FlowAttributes
+	assert.Len(t, columns, 2)
+	assert.Equal(t, AttributeRequired, columns["PROTOCOL"].Type)
+	assert.Equal(t, "^(CAN|UART)$", columns["PROTOCOL"].Value.String())
+	assert.Equal(t, AttributeOptional, columns["RATE"].Type)
+
+	assert.EqualError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Level: "SWH",
+		FlowColumns: []jsonAttribute{{Name: "Direction"}}}),
+		"Flow column `Direction` of document `TEST-137-SRD.md` is a standard column of the flow tables")
+}
+
+// @llr REQ-TRAQ-SWL-165
+func TestConfig_SchemaVersion(t *testing.T) {
+	repos.RegisterRepository(repos.RepoName("projectA"), repos.RepoPath("../testdata/projectA"))
+
+	var rc RepoConfig
+	assert.NoError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Level: "SWH", SchemaVersion: 2,
+		Attributes: []jsonAttribute{{Name: "Safety Impact"}, {Name: "Verification", Since: 2}}}))
+	schema := rc.Documents[0].Schema
+	assert.Equal(t, 2, schema.Version)
+	assert.Equal(t, 0, schema.Attributes["SAFETY IMPACT"].Since)
+	assert.Equal(t, 2, schema.Attributes["VERIFICATION"].Since)
+
+	assert.EqualError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Level: "SWH", SchemaVersion: 1,
+		Attributes: []jsonAttribute{{Name: "Verification", Since: 2}}}),
+		"Attribute `Verification` of document `TEST-137-SRD.md` in repo `projectA` is introduced in schema version 2, after the schema version 1 of the document")
+	assert.EqualError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Level: "SWH",
+		Attributes: []jsonAttribute{{Name: "Verification", Since: -1}}}),
+		"Attribute `Verification` has a negative `since` schema version")
+}
+
+// @llr REQ-TRAQ-SWL-117
+func TestConfig_ParseCodeParsers(t *testing.T) {
+	config := Config{Repos: make(map[repos.RepoName]RepoConfig)}
+	commonAttributes := make(map[string]*Attribute)
+
+	err := config.parseConfigFile(jsonConfig{
+		RepoName:    "repo",
+		CodeParsers: []jsonCodeParser{{Name: "ada", Command: "tools/ada-tagger", Arguments: []string{"--json"}}},
+	}, &commonAttributes)
+	assert.NoError(t, err)
+	assert.Equal(t, []ExternalCodeParser{
+		{Name: "ada", Command: "tools/ada-tagger", Arguments: []string{"--json"}, RepoName: "repo"},
+	}, config.CodeParsers)
+
+	err = config.parseConfigFile(jsonConfig{
+		RepoName:    "other",
+		CodeParsers: []jsonCodeParser{{Name: "ada", Command: "ada-tagger"}},
+	}, &commonAttributes)
+	assert.EqualError(t, err, "Code parser with name `ada` found in config for repo `other` is already defined elsewhere")
+
+	err = config.parseConfigFile(jsonConfig{
+		RepoName:    "third",
+		CodeParsers: []jsonCodeParser{{Name: "ada"}},
+	}, &commonAttributes)
+	assert.EqualError(t, err, "Code parser in config for repo `third` must specify a name and a command")
+}
+
+// @llr REQ-TRAQ-SWL-120
+func TestConfig_ExternalPrefixes(t *testing.T) {
+	DirectDependenciesOnly = true
+	defer func() { DirectDependenciesOnly = false }()
+
+	config := Config{Repos: make(map[repos.RepoName]RepoConfig)}
+	commonAttributes := make(map[string]*Attribute)
+	err := config.parseConfigFile(jsonConfig{
+		RepoName: "parent",
+		ChildrenRepos: []jsonRepoLink{
+			{RepoName: "child", RemotePath: "child", Prefixes: []ReqPrefix{"CHILD", "DRV"}},
+			{RepoName: "other", RemotePath: "other"},
+		},
+	}, &commonAttributes)
+	assert.NoError(t, err)
+	assert.Equal(t, map[ReqPrefix]repos.RepoName{"CHILD": "child", "DRV": "child"}, config.ExternalPrefixes)
+}
+
+// @llr REQ-TRAQ-SWL-123
+func TestConfig_DocumentPresets(t *testing.T) {
+	repos.RegisterRepository(repos.RepoName("projectA"), repos.RepoPath("../testdata/projectA"))
+
+	var rc RepoConfig
+	assert.NoError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Preset: "HRS",
+		Attributes: []jsonAttribute{{Name: "derived", Value: "^(Y|N)$"}}}))
+	doc := rc.Documents[0]
+	assert.Equal(t, ReqLevel("HRS"), doc.ReqSpec.Level)
+	assert.Len(t, doc.LinkSpecs, 1)
+	assert.Equal(t, ReqSpec{Prefix: "TEST", Level: "SYS"}, ReqSpec{Prefix: doc.LinkSpecs[0].Parent.Prefix, Level: doc.LinkSpecs[0].Parent.Level})
+	assert.Equal(t, AttributeAny, doc.Schema.Attributes["RATIONALE"].Type)
+	assert.Equal(t, AttributeRequired, doc.Schema.Attributes["VERIFICATION"].Type)
+	assert.True(t, doc.Schema.Attributes["VERIFICATION"].Value.MatchString("Simulation"))
+	// The attributes of the document replace the ones of the preset
+	assert.Equal(t, "^(Y|N)$", doc.Schema.Attributes["DERIVED"].Value.String())
+
+	// Common attributes replace the ones of the preset, but not the ones of the document
+	commonAttributes := map[string]*Attribute{"VERIFICATION": {Type: AttributeOptional, Value: regexp.MustCompile(".*")}}
+	assert.NoError(t, doc.appendCommonAttributes(&commonAttributes, []string{"VERIFICATION"}))
+	assert.Equal(t, AttributeOptional, doc.Schema.Attributes["VERIFICATION"].Type)
+	assert.Equal(t, []string{"DERIVED", "RATIONALE", "VERIFICATION"}, doc.Schema.AttributeNames)
+	commonAttributes = map[string]*Attribute{"DERIVED": {Type: AttributeOptional, Value: regexp.MustCompile(".*")}}
+	assert.Error(t, doc.appendCommonAttributes(&commonAttributes, []string{"DERIVED"}))
+
+	// The HDL code is implementing the design, except for the testbenches testing it
+	assert.NoError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Preset: "HDD",
+		Implementation: jsonImplementations{{Code: jsonFileQuery{jsonFileQueryBase: jsonFileQueryBase{Paths: []string{"code"}}}}}}))
+	doc = rc.Documents[1]
+	assert.Equal(t, ReqLevel("HDD"), doc.ReqSpec.Level)
+	assert.Equal(t, ReqLevel("HRS"), doc.LinkSpecs[0].Parent.Level)
+	assert.Len(t, doc.Implementation, 1)
+
+	// The logic blocks refine the design and are implemented in HDL as well
+	assert.NoError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Preset: "CLB"}))
+	doc = rc.Documents[2]
+	assert.Equal(t, ReqLevel("CLB"), doc.ReqSpec.Level)
+	assert.Equal(t, ReqLevel("HDD"), doc.LinkSpecs[0].Parent.Level)
+	assert.Equal(t, AttributeRequired, doc.Schema.Attributes["VERIFICATION"].Type)
+	assert.Len(t, doc.Implementation, 1)
+
+	assert.EqualError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Preset: "PCB"}),
+		"Document with path `TEST-137-SRD.md` selects unknown preset `PCB`, the presets are [CLB HDD HRS]")
+	assert.EqualError(t, rc.parseDocument("projectA", jsonDoc{Path: "TEST-137-SRD.md", Prefix: "TEST", Level: "SWH", Preset: "HRS"}),
+		"Document with path `TEST-137-SRD.md` has level `SWH`, but its preset `HRS` is for level `HRS`")
+}
+
+// @llr REQ-TRAQ-SWL-123
+func TestConfig_ApplyPresetImplementation(t *testing.T) {
+	doc := jsonDoc{Path: "HDD.md", Prefix: "FPGA", Preset: "HDD"}
+	_, err := doc.applyPreset()
+	assert.NoError(t, err)
+	assert.Equal(t, jsonImplementations{*documentPresets["HDD"].implementation}, doc.Implementation)
+
+	doc = jsonDoc{Path: "HDD.md", Prefix: "FPGA", Preset: "HDD", Implementation: jsonImplementations{{
+		Code: jsonFileQuery{jsonFileQueryBase: jsonFileQueryBase{Paths: []string{"rtl"}, IgnoredPatterns: []string{".*/vendor/.*"}}},
+	}}}
+	_, err = doc.applyPreset()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rtl"}, doc.Implementation[0].Code.Paths)
+	assert.Equal(t, hdlFilesPattern, doc.Implementation[0].Code.MatchingPattern)
+	assert.Equal(t, []string{".*/vendor/.*", hdlTestbenchesPattern}, doc.Implementation[0].Code.IgnoredPatterns)
+	assert.Equal(t, hdlTestbenchesPattern, doc.Implementation[0].Tests.MatchingPattern)
+}
+
+// @llr REQ-TRAQ-SWL-130
+func TestConfig_DocumentFragments(t *testing.T) {
+	repos.RegisterRepository(repos.RepoName("fragments"), repos.RepoPath("../testdata/fragments"))
+
+	var rc RepoConfig
+	assert.NoError(t, rc.parseDocument("fragments", jsonDoc{Path: "TEST-137-SRD", Prefix: "TEST", Level: "SWH"}))
+	doc := rc.Documents[0]
+	assert.Equal(t, []Fragment{
+		{Path: "TEST-137-SRD/1-introduction.md"},
+		{Path: "TEST-137-SRD/2-storage.md"},
+	}, doc.Fragments)
+	assert.Equal(t, []string{"TEST-137-SRD/1-introduction.md", "TEST-137-SRD/2-storage.md"}, doc.Files())
+
+	// The lines are counted in the contents read, the configured document is left unchanged
+	counted := doc.WithContents([]string{"1\n2\n3\n4\n5\n", "1\n2\n3\n4\n5\n6\n7"})
+	assert.Equal(t, []Fragment{
+		{Path: "TEST-137-SRD/1-introduction.md", Lines: 5},
+		{Path: "TEST-137-SRD/2-storage.md", Lines: 7},
+	}, counted.Fragments)
+	assert.Equal(t, 0, doc.Fragments[0].Lines)
+	doc = *counted
+	path, line := doc.Locate(3)
+	assert.Equal(t, "TEST-137-SRD/1-introduction.md", path)
+	assert.Equal(t, 3, line)
+	path, line = doc.Locate(10)
+	assert.Equal(t, "TEST-137-SRD/2-storage.md", path)
+	assert.Equal(t, 5, line)
+	assert.Equal(t, 5, doc.FragmentStart("TEST-137-SRD/2-storage.md"))
+
+	// The listed fragments are used in the given order
+	assert.NoError(t, rc.parseDocument("fragments", jsonDoc{Path: "TEST-138-SDD", Prefix: "TEST", Level: "SWL",
+		Fragments: []string{"storage.md", "logging.md"}}))
+	assert.Equal(t, []string{"TEST-138-SDD/storage.md", "TEST-138-SDD/logging.md"}, rc.Documents[1].Files())
+
+	err := rc.parseDocument("fragments", jsonDoc{Path: "TEST-138-SDD", Prefix: "TEST", Level: "SWL",
+		Fragments: []string{"design.md"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Fragment `design.md` of document `TEST-138-SDD` cannot be read")
+	}
+	assert.EqualError(t, rc.parseDocument("fragments", jsonDoc{Path: "reqtraq_config.json", Prefix: "TEST", Level: "SWL",
+		Fragments: []string{"logging.md"}}),
+		"Document with path `reqtraq_config.json` in repo `fragments` cannot be read: Document `reqtraq_config.json` has fragments but its path is not a directory")
+
+	// The last line of a file may lack a newline
+	assert.Equal(t, 0, CountLines(""))
+	assert.Equal(t, 2, CountLines("a\nb"))
+	assert.Equal(t, "a\nb\nc\n", JoinFragments([]string{"a\nb", "c\n"}))
+}
+
+// @llr REQ-TRAQ-SWL-131
+func TestConfig_RulePlugins(t *testing.T) {
+	config := Config{Repos: make(map[repos.RepoName]RepoConfig)}
+	commonAttributes := make(map[string]*Attribute)
+
+	err := config.parseConfigFile(jsonConfig{RepoName: "repo", RulePlugins: []string{"tools/naming.so"}}, &commonAttributes)
+	assert.NoError(t, err)
+	assert.Equal(t, []RulePlugin{{Path: "tools/naming.so", RepoName: "repo"}}, config.RulePlugins)
+}
+
+// @llr REQ-TRAQ-SWL-161
+func TestConfig_RequirementTemplates(t *testing.T) {
+	config := Config{Repos: make(map[repos.RepoName]RepoConfig)}
+	commonAttributes := make(map[string]*Attribute)
+
+	err := config.parseConfigFile(jsonConfig{RepoName: "repo", RequirementTemplates: []string{"tools/dashboard.html"}}, &commonAttributes)
+	assert.NoError(t, err)
+	assert.Equal(t, []RequirementTemplate{{Path: "tools/dashboard.html", RepoName: "repo"}}, config.RequirementTemplates)
+}
+
+// @llr REQ-TRAQ-SWL-139
+func TestConfig_CodeReviews(t *testing.T) {
+	reviews, err := (&jsonCodeReviews{}).parse()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultReviewNotesRef, reviews.NotesRef)
+	assert.True(t, reviews.Accepted.MatchString("Add a\n\nReviewed By: john\n"))
+	assert.True(t, reviews.Accepted.MatchString("Code-Review+2: John Roe <john@example.com>\n"))
+	assert.False(t, reviews.Accepted.MatchString("Code-Review+1: John Roe <john@example.com>\n"))
+
+	reviews, err = (&jsonCodeReviews{NotesRef: "refs/notes/approvals", AcceptedPattern: "(?m)^Approved:"}).parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "refs/notes/approvals", reviews.NotesRef)
+	assert.True(t, reviews.Accepted.MatchString("Approved: jane"))
+
+	_, err = (&jsonCodeReviews{AcceptedPattern: "("}).parse()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Invalid `acceptedPattern` of the code reviews")
+	}
+}
+
+// @llr REQ-TRAQ-SWL-143
+func TestConfig_AttributeOrder(t *testing.T) {
+	order, err := parseAttributeOrder([]string{"Parent", "Rationale", " safety impact"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PARENTS", "RATIONALE", "SAFETY IMPACT"}, order)
+
+	order, err = parseAttributeOrder(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, order)
+
+	_, err = parseAttributeOrder([]string{"Parents", "Rationale", "PARENTS"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "listed more than once")
+	}
+	_, err = parseAttributeOrder([]string{""})
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-152
+func TestConfig_LintPolicy(t *testing.T) {
+	policy, err := parseLintPolicy(map[string]string{"duplicateTitle": " Error"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]LintSeverity{LintCheckDuplicateTitle: LintSeverityError}, policy)
+
+	policy, err = parseLintPolicy(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, policy)
+
+	_, err = parseLintPolicy(map[string]string{"duplicateBody": "off"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Unknown lint check `duplicateBody`")
+	}
+	_, err = parseLintPolicy(map[string]string{"duplicateTitle": "fatal"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Invalid severity `fatal`")
+	}
+}
+
+// @llr REQ-TRAQ-SWL-169
+func TestConfig_Webhooks(t *testing.T) {
+	webhooks, err := parseWebhooks([]jsonWebhook{
+		{URL: " https://chat.example.com/hooks/reqtraq ", Headers: map[string]string{"Authorization": "Bearer token"}},
+		{URL: "http://localhost:9000/"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Webhook{
+		{URL: "https://chat.example.com/hooks/reqtraq", Headers: map[string]string{"Authorization": "Bearer token"}},
+		{URL: "http://localhost:9000/"},
+	}, webhooks)
+
+	for _, address := range []string{"chat.example.com/hooks", "ftp://example.com", "https://"} {
+		_, err = parseWebhooks([]jsonWebhook{{URL: address}})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "Invalid webhook URL")
+		}
+	}
+}
+
+// @llr REQ-TRAQ-SWL-188
+func TestConfig_DoxygenDocument(t *testing.T) {
+	repos.RegisterRepository(repos.RepoName("doxygen"), repos.RepoPath("../testdata/doxygen"))
+
+	// Only the missing documents are virtual
+	var rc RepoConfig
+	assert.NoError(t, rc.parseDocument("doxygen", jsonDoc{Path: "TEST-138-SDD.md", Prefix: "TEST", Level: "SWL",
+		Doxygen: "doxygen/xml"}))
+	assert.True(t, rc.Documents[0].Virtual)
+
+	err := rc.parseDocument("doxygen", jsonDoc{Path: "reqtraq_config.json/TEST-138-SDD.md", Prefix: "TEST",
+		Level: "SWL", Doxygen: "doxygen/xml"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Document with path `reqtraq_config.json/TEST-138-SDD.md` in repo `doxygen` cannot be read")
+	}
+	assert.Len(t, rc.Documents, 1)
+}
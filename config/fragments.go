@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// A markdown file making up part of a document split across several files
+type Fragment struct {
+	// Path of the file, relative to the root of the repository
+	Path string
+	// Number of lines of the file when the document was read, 0 in the configuration, see WithContents
+	Lines int `json:",omitempty"`
+}
+
+// documentFragments returns the files making up a document whose path is a directory: the given file names in
+// that order or, if none is given, all the markdown files of the directory ordered by name. Nil is returned for
+// documents made of a single file.
+// @llr REQ-TRAQ-SWL-130
+func documentFragments(repoName repos.RepoName, docPath string, names []string) ([]Fragment, error) {
+	fsPath, err := repos.PathInRepo(repoName, docPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(fsPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if len(names) > 0 {
+			return nil, fmt.Errorf("Document `%s` has fragments but its path is not a directory", docPath)
+		}
+		return nil, nil
+	}
+
+	if len(names) == 0 {
+		entries, err := os.ReadDir(fsPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("Document directory `%s` contains no markdown files", docPath)
+	}
+
+	fragments := make([]Fragment, 0, len(names))
+	for _, name := range names {
+		fragmentPath := path.Join(docPath, name)
+		if _, err := repos.PathInRepo(repoName, fragmentPath); err != nil {
+			return nil, errors.Wrapf(err, "Fragment `%s` of document `%s` cannot be read", name, docPath)
+		}
+		fragments = append(fragments, Fragment{Path: fragmentPath})
+	}
+	return fragments, nil
+}
+
+// CountLines returns the number of lines of the content of a file, the last one not necessarily terminated by a
+// newline
+// @llr REQ-TRAQ-SWL-130
+func CountLines(content string) int {
+	lines := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		lines++
+	}
+	return lines
+}
+
+// JoinFragments concatenates the contents of the fragments of a document, terminating each of them with a newline
+// so the lines of the fragments follow each other
+// @llr REQ-TRAQ-SWL-130
+func JoinFragments(contents []string) string {
+	var joined strings.Builder
+	for _, content := range contents {
+		joined.WriteString(content)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			joined.WriteString("\n")
+		}
+	}
+	return joined.String()
+}
+
+// WithContents returns a copy of the document with the line counts of its fragments, given their contents in order.
+// The line counts are those of the contents read when parsing the document, so they are not kept in the
+// configuration, which outlives the edits of the fragments, e.g. when the web interface rebuilds the graph.
+// @llr REQ-TRAQ-SWL-130
+func (doc *Document) WithContents(contents []string) *Document {
+	counted := *doc
+	counted.Fragments = make([]Fragment, len(doc.Fragments))
+	for i, fragment := range doc.Fragments {
+		counted.Fragments[i] = Fragment{Path: fragment.Path, Lines: CountLines(contents[i])}
+	}
+	return &counted
+}
+
+// Files returns the paths of the files making up the document, in order, none for virtual documents
+// @llr REQ-TRAQ-SWL-130, REQ-TRAQ-SWL-188
+func (doc *Document) Files() []string {
+	if doc.Virtual {
+		return nil
+	}
+	if len(doc.Fragments) == 0 {
+		return []string{doc.Path}
+	}
+	files := make([]string, 0, len(doc.Fragments))
+	for _, fragment := range doc.Fragments {
+		files = append(files, fragment.Path)
+	}
+	return files
+}
+
+// Locate returns the file and the line within it of the given line of the document, which for documents split in
+// fragments is the line within the concatenation of the fragments. The line counts of the fragments must be known,
+// see WithContents.
+// @llr REQ-TRAQ-SWL-130
+func (doc *Document) Locate(line int) (string, int) {
+	if len(doc.Fragments) == 0 {
+		return doc.Path, line
+	}
+	for _, fragment := range doc.Fragments {
+		if line <= fragment.Lines {
+			return fragment.Path, line
+		}
+		line -= fragment.Lines
+	}
+	// Past the end, e.g. the line following the last one
+	last := doc.Fragments[len(doc.Fragments)-1]
+	return last.Path, last.Lines + line
+}
+
+// FragmentStart returns the line of the document preceding the first line of the given fragment, 0 for the first
+// fragment or for documents made of a single file
+// @llr REQ-TRAQ-SWL-130
+func (doc *Document) FragmentStart(fragmentPath string) int {
+	start := 0
+	for _, fragment := range doc.Fragments {
+		if fragment.Path == fragmentPath {
+			return start
+		}
+		start += fragment.Lines
+	}
+	return 0
+}
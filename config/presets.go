@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// documentPreset holds the level, parent level, attributes and implementation provided to the documents which
+// select it, so documents of common chains need not be configured by hand
+type documentPreset struct {
+	level       ReqLevel
+	parentLevel ReqLevel
+	attributes  []jsonAttribute
+	// The code and tests of the implementation, used for the matching patterns the implementation of the document
+	// does not configure and as its implementation if it has none
+	implementation *jsonImplementation
+}
+
+// The verification methods of hardware requirements
+const hardwareVerificationMethods = "^(Test|Simulation|Analysis|Inspection|Review)$"
+
+// The HDL files, which are tagged by ctags
+const hdlFilesPattern = `.*\.(vhd|vhdl|v|vh|sv|svh)$`
+
+// The testbenches, which test the HDL code
+const hdlTestbenchesPattern = `.*_tb\.(vhd|vhdl|v|sv)$`
+
+// The implementation of the hardware documents in HDL, with the testbenches as tests
+var hdlImplementation = &jsonImplementation{
+	Code: jsonFileQuery{jsonFileQueryBase: jsonFileQueryBase{
+		Paths:           []string{"."},
+		MatchingPattern: hdlFilesPattern,
+		IgnoredPatterns: []string{hdlTestbenchesPattern},
+	}},
+	Tests: jsonFileQuery{jsonFileQueryBase: jsonFileQueryBase{
+		Paths:           []string{"."},
+		MatchingPattern: hdlTestbenchesPattern,
+	}},
+}
+
+// The presets of the hardware document chain SYS > HRS > HDD > CLB, by name
+var documentPresets = map[string]documentPreset{
+	// Hardware Requirements Specification, refining the system requirements
+	"HRS": {
+		level:       "HRS",
+		parentLevel: "SYS",
+		attributes: []jsonAttribute{
+			{Name: "Rationale", Required: "any"},
+			{Name: "Verification", Value: hardwareVerificationMethods},
+			{Name: "Derived", Required: "false", Value: "^(Yes|No)$"},
+		},
+	},
+	// Hardware Design Document, refining the hardware requirements and implemented in HDL
+	"HDD": {
+		level:       "HDD",
+		parentLevel: "HRS",
+		attributes: []jsonAttribute{
+			{Name: "Rationale", Required: "any"},
+			{Name: "Verification", Value: hardwareVerificationMethods},
+			{Name: "Derived", Required: "false", Value: "^(Yes|No)$"},
+		},
+		implementation: hdlImplementation,
+	},
+	// Configurable Logic Block requirements, refining the hardware design of the blocks of the device and
+	// implemented in HDL
+	"CLB": {
+		level:       "CLB",
+		parentLevel: "HDD",
+		attributes: []jsonAttribute{
+			{Name: "Rationale", Required: "any"},
+			{Name: "Verification", Value: hardwareVerificationMethods},
+			{Name: "Derived", Required: "false", Value: "^(Yes|No)$"},
+		},
+		implementation: hdlImplementation,
+	},
+}
+
+// DocumentPresets returns the names of the presets which documents can select, sorted
+// @llr REQ-TRAQ-SWL-123
+func DocumentPresets() []string {
+	names := make([]string, 0, len(documentPresets))
+	for name := range documentPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyPreset completes the document with the level, parent, attributes and implementation of the preset it
+// selects, if any. Returns the names of the attributes provided by the preset.
+// @llr REQ-TRAQ-SWL-123
+func (doc *jsonDoc) applyPreset() ([]string, error) {
+	if doc.Preset == "" {
+		return nil, nil
+	}
+	preset, ok := documentPresets[doc.Preset]
+	if !ok {
+		return nil, fmt.Errorf("Document with path `%s` selects unknown preset `%s`, the presets are %v",
+			doc.Path, doc.Preset, DocumentPresets())
+	}
+
+	if doc.Level == "" {
+		doc.Level = preset.level
+	} else if doc.Level != preset.level {
+		return nil, fmt.Errorf("Document with path `%s` has level `%s`, but its preset `%s` is for level `%s`",
+			doc.Path, doc.Level, doc.Preset, preset.level)
+	}
+
+	if doc.Parent == nil && preset.parentLevel != "" {
+		doc.Parent = jsonParents{{Prefix: doc.Prefix, Level: preset.parentLevel}}
+	}
+
+	var presetAttributes []string
+	for _, presetAttr := range preset.attributes {
+		defined := false
+		for _, attr := range doc.Attributes {
+			defined = defined || strings.EqualFold(attr.Name, presetAttr.Name)
+		}
+		if !defined {
+			doc.Attributes = append(doc.Attributes, presetAttr)
+			presetAttributes = append(presetAttributes, strings.ToUpper(presetAttr.Name))
+		}
+	}
+
+	if preset.implementation != nil {
+		if len(doc.Implementation) == 0 {
+			doc.Implementation = jsonImplementations{*preset.implementation}
+		}
+		for i := range doc.Implementation {
+			impl := &doc.Implementation[i]
+			if impl.Code.MatchingPattern == "" {
+				impl.Code.MatchingPattern = preset.implementation.Code.MatchingPattern
+				impl.Code.IgnoredPatterns = append(impl.Code.IgnoredPatterns, preset.implementation.Code.IgnoredPatterns...)
+			}
+			if impl.Tests.MatchingPattern == "" {
+				impl.Tests.MatchingPattern = preset.implementation.Tests.MatchingPattern
+			}
+		}
+	}
+
+	return presetAttributes, nil
+}
+
+// isPresetAttribute returns whether the attribute with the given name is provided by the preset of the document
+// @llr REQ-TRAQ-SWL-123
+func (doc *Document) isPresetAttribute(name string) bool {
+	for _, presetAttr := range doc.presetAttributes {
+		if presetAttr == name {
+			return true
+		}
+	}
+	return false
+}
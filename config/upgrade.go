@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/util"
+	"github.com/pkg/errors"
+)
+
+// The release from which the implementation of a document can no longer be given as a single object instead of a
+// list, the legacy format of the configuration
+var LegacyImplementationRemovalVersion = util.VersionType{Major: 1, Minor: 0, Revision: 0}
+
+// The version of reqtraq, replaced by the tests
+var currentVersion = util.Version
+
+// The configuration files which were already warned about, so the warning is printed once per file, e.g. when the
+// configuration is read several times in batch mode
+var warnedLegacyConfigs = map[string]bool{}
+
+// legacyImplementation is the implementation of a document given in the legacy format, as a single object
+type legacyImplementation struct {
+	// The path of the document
+	document string
+	// The offsets of the beginning and of the end of the object in the configuration file
+	start, end int
+}
+
+// expectDelim reads the next token and fails if it is not the given delimiter
+// @llr REQ-TRAQ-SWL-173
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("malformed JSON, expected '%v', got %v", delim, token)
+	}
+	return nil
+}
+
+// findLegacyImplementations returns the implementations of the documents of the configuration file which are given
+// as single objects, in the order of the file
+// @llr REQ-TRAQ-SWL-173, REQ-TRAQ-SWL-174
+func findLegacyImplementations(data []byte) ([]legacyImplementation, error) {
+	var legacy []legacyImplementation
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key != "documents" {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return nil, err
+		}
+		for decoder.More() {
+			if err := expectDelim(decoder, '{'); err != nil {
+				return nil, err
+			}
+			var document string
+			var found []legacyImplementation
+			for decoder.More() {
+				docKey, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				// The value starts after the colon following the key
+				start := int(decoder.InputOffset())
+				for start < len(data) && (data[start] == ':' || strings.ContainsRune(" \t\r\n", rune(data[start]))) {
+					start++
+				}
+				var value json.RawMessage
+				if err := decoder.Decode(&value); err != nil {
+					return nil, err
+				}
+				switch {
+				case docKey == "path":
+					_ = json.Unmarshal(value, &document)
+				case docKey == "implementation" && len(value) > 0 && value[0] == '{':
+					found = append(found, legacyImplementation{start: start, end: int(decoder.InputOffset())})
+				}
+			}
+			for _, impl := range found {
+				impl.document = document
+				legacy = append(legacy, impl)
+			}
+			if err := expectDelim(decoder, '}'); err != nil {
+				return nil, err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return nil, err
+		}
+	}
+	return legacy, nil
+}
+
+// checkLegacyImplementations warns about the documents of the configuration file whose implementation is given as a
+// single object, and fails from the release removing the legacy format
+// @llr REQ-TRAQ-SWL-173
+func checkLegacyImplementations(configPath string, data []byte) error {
+	legacy, err := findLegacyImplementations(data)
+	if err != nil {
+		return errors.Wrapf(err, "Error while parsing configuration file `%s`", configPath)
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+	documents := make([]string, 0, len(legacy))
+	for _, impl := range legacy {
+		documents = append(documents, "`"+impl.document+"`")
+	}
+	if !currentVersion.Before(LegacyImplementationRemovalVersion) {
+		return fmt.Errorf("The implementation of documents %s in configuration file `%s` is a single object, which is no longer supported since reqtraq %s. Run `reqtraq config upgrade` to convert it to a list.",
+			strings.Join(documents, ", "), configPath, LegacyImplementationRemovalVersion)
+	}
+	if !warnedLegacyConfigs[configPath] {
+		warnedLegacyConfigs[configPath] = true
+		fmt.Printf("Warning: the implementation of documents %s in configuration file `%s` is a single object, which is deprecated and rejected from reqtraq %s. Run `reqtraq config upgrade` to convert it to a list.\n",
+			strings.Join(documents, ", "), configPath, LegacyImplementationRemovalVersion)
+	}
+	return nil
+}
+
+// UpgradeConfig returns the content of a configuration file with the implementations of the documents given as
+// single objects wrapped in lists. The rest of the file is kept as is.
+// @llr REQ-TRAQ-SWL-174
+func UpgradeConfig(data []byte) ([]byte, error) {
+	legacy, err := findLegacyImplementations(data)
+	if err != nil {
+		return nil, err
+	}
+	var upgraded bytes.Buffer
+	last := 0
+	for _, impl := range legacy {
+		upgraded.Write(data[last:impl.start])
+		upgraded.WriteByte('[')
+		upgraded.Write(data[impl.start:impl.end])
+		upgraded.WriteByte(']')
+		last = impl.end
+	}
+	upgraded.Write(data[last:])
+	return upgraded.Bytes(), nil
+}
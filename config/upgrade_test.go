@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyConfig = `{
+    "repoName": "legacy",
+    "implementation": {"ignored": true},
+    "documents": [
+        {
+            "path": "TEST-137-SRD.md",
+            "implementation": [{"code": {"paths": ["lib"]}}]
+        },
+        {
+            "implementation": {
+                "code": {"paths": ["src"]}
+            },
+            "path": "TEST-138-SDD.md"
+        }
+    ]
+}
+`
+
+// @llr REQ-TRAQ-SWL-173, REQ-TRAQ-SWL-174
+func TestConfig_UpgradeConfig(t *testing.T) {
+	upgraded, err := UpgradeConfig([]byte(legacyConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+    "repoName": "legacy",
+    "implementation": {"ignored": true},
+    "documents": [
+        {
+            "path": "TEST-137-SRD.md",
+            "implementation": [{"code": {"paths": ["lib"]}}]
+        },
+        {
+            "implementation": [{
+                "code": {"paths": ["src"]}
+            }],
+            "path": "TEST-138-SDD.md"
+        }
+    ]
+}
+`, string(upgraded))
+
+	// Upgrading again doesn't change anything
+	again, err := UpgradeConfig(upgraded)
+	assert.NoError(t, err)
+	assert.Equal(t, string(upgraded), string(again))
+
+	legacy, err := findLegacyImplementations([]byte(legacyConfig))
+	assert.NoError(t, err)
+	start := strings.Index(legacyConfig, `{
+                "code": {"paths": ["src"]}`)
+	end := strings.Index(legacyConfig, `,
+            "path": "TEST-138-SDD.md"`)
+	assert.Equal(t, []legacyImplementation{{document: "TEST-138-SDD.md", start: start, end: end}}, legacy)
+
+	_, err = UpgradeConfig([]byte(`{"documents": {}}`))
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-173
+func TestConfig_LegacyImplementationDeprecation(t *testing.T) {
+	defer func() { currentVersion = util.Version }()
+
+	currentVersion = util.VersionType{Major: 0, Minor: 9, Revision: 3}
+	assert.NoError(t, checkLegacyImplementations("legacy/reqtraq_config.json", []byte(legacyConfig)))
+	assert.True(t, warnedLegacyConfigs["legacy/reqtraq_config.json"])
+
+	currentVersion = util.VersionType{Major: 1, Minor: 0, Revision: 0}
+	assert.EqualError(t, checkLegacyImplementations("legacy/reqtraq_config.json", []byte(legacyConfig)),
+		"The implementation of documents `TEST-138-SDD.md` in configuration file `legacy/reqtraq_config.json` is a single object, which is no longer supported since reqtraq 1.0.0. Run `reqtraq config upgrade` to convert it to a list.")
+
+	upgraded, err := UpgradeConfig([]byte(legacyConfig))
+	assert.NoError(t, err)
+	assert.NoError(t, checkLegacyImplementations("legacy/reqtraq_config.json", upgraded))
+}
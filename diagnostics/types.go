@@ -25,6 +25,27 @@ const (
 	IssueTypeMissingFlowId
 	IssueTypeInvalidFlowDirection
 	IssueTypeFlowIdOfDifferentItem
+	IssueTypeOpenReviewComment
+	IssueTypeDuplicatedParentText
+	IssueTypeApprovedTextChanged
+	IssueTypeExternalReference
+	IssueTypeInconsistentGraph
+	IssueTypeAmbiguousCodeFile
+	IssueTypeRuleViolation
+	IssueTypeAttributeLayout
+	IssueTypeDuplicateForeignID
+	IssueTypeMisallocatedCode
+	IssueTypeSkippedCodeFile
+	IssueTypeDuplicateTitle
+	IssueTypeUnvalidatedAssumption
+	IssueTypeUnshippedImplementation
+	IssueTypeInvalidRequirementInModel
+	IssueTypeImplementationInProgress
+	IssueTypeMissingFlowDiagram
+	IssueTypeTestInferredFromCoverage
+	IssueTypeDocumentTooLarge
+	IssueTypeBodyTooLong
+	IssueTypeVirtualDocument
 )
 
 type IssueSeverity uint
@@ -42,4 +63,111 @@ type Issue struct {
 	Description string
 	Severity    IssueSeverity
 	Type        IssueType
+	// The architectures of the code the issue was found in, empty if it does not depend on the architecture
+	Archs []string `json:",omitempty"`
+}
+
+// String returns the name of the severity, as reported in the JSON output and the issues report
+// @llr REQ-TRAQ-SWL-66
+func (severity IssueSeverity) String() string {
+	switch severity {
+	case IssueSeverityMajor:
+		return "error"
+	case IssueSeverityMinor:
+		return "warning"
+	case IssueSeverityNote:
+		return "note"
+	}
+	return "error"
+}
+
+// TypeInfo returns the name and the code of the issues of the given type, as reported in the JSON output and the
+// issues report. The name is empty for unknown types.
+// @llr REQ-TRAQ-SWL-66
+func TypeInfo(issueType IssueType) (string, string) {
+	switch issueType {
+	case IssueTypeInvalidRequirementId:
+		return "Invalid requirement ID", "REQ1"
+	case IssueTypeInvalidParent:
+		return "Invalid parent requirement", "REQ2"
+	case IssueTypeInvalidRequirementReference:
+		return "Invalid requirement reference", "REQ3"
+	case IssueTypeInvalidRequirementInCode:
+		return "Invalid requirement", "REQ4"
+	case IssueTypeMissingRequirementInCode:
+		return "Code without requirements", "REQ5"
+	case IssueTypeMissingAttribute:
+		return "Missing attribute", "REQ6"
+	case IssueTypeUnknownAttribute:
+		return "Unknown attribute", "REQ7"
+	case IssueTypeInvalidAttributeValue:
+		return "Invalid attribute", "REQ8"
+	case IssueTypeReqTestedButNotImplemented:
+		return "Requirement tested but not implemented", "REQ9"
+	case IssueTypeReqNotImplemented:
+		return "Requirement not implemented", "REQ10"
+	case IssueTypeReqNotTested:
+		return "Requirement not tested", "REQ11"
+	case IssueTypeNoShallInBody:
+		return "No shall statement in body", "REQ12"
+	case IssueTypeManyShallInBody:
+		return "Multiple shall statements in body", "REQ13"
+	case IssueTypeShallInRationale:
+		return "Shall statement in rationale attribute", "REQ14"
+	case IssueTypeInvalidFlowId:
+		return "Invalid Flow tag identifier", "REQ15"
+	case IssueTypeFlowNotImplemented:
+		return "Flow tag is not linked to a requirement", "REQ16"
+	case IssueTypeDuplicateFlowId:
+		return "Duplicate Flow tag identifier", "REQ17"
+	case IssueTypeMissingFlowId:
+		return "Missing Flow tag identifier", "REQ18"
+	case IssueTypeInvalidFlowDirection:
+		return "Invalid flow direction", "REQ19"
+	case IssueTypeFlowIdOfDifferentItem:
+		return "Requirement references flow tag of a different item", "REQ20"
+	case IssueTypeOpenReviewComment:
+		return "Open review comment in frozen document", "REQ21"
+	case IssueTypeDuplicatedParentText:
+		return "Requirement text duplicated from parent", "REQ22"
+	case IssueTypeApprovedTextChanged:
+		return "Approved requirement text changed", "REQ23"
+	case IssueTypeExternalReference:
+		return "Reference to a repository which was not parsed", "REQ24"
+	case IssueTypeInconsistentGraph:
+		return "Inconsistent requirements graph", "REQ25"
+	case IssueTypeAmbiguousCodeFile:
+		return "File matched both as code and as test", "REQ26"
+	case IssueTypeRuleViolation:
+		return "Project validation rule violated", "REQ27"
+	case IssueTypeAttributeLayout:
+		return "Attributes out of order or not last", "REQ28"
+	case IssueTypeDuplicateForeignID:
+		return "Duplicate foreign ID", "REQ29"
+	case IssueTypeMisallocatedCode:
+		return "Code outside the allocation of its requirement", "REQ30"
+	case IssueTypeSkippedCodeFile:
+		return "Code file not parsed", "REQ31"
+	case IssueTypeDuplicateTitle:
+		return "Duplicate title", "REQ32"
+	case IssueTypeUnvalidatedAssumption:
+		return "Unvalidated assumption", "REQ33"
+	case IssueTypeUnshippedImplementation:
+		return "Implementation not shipped", "REQ34"
+	case IssueTypeInvalidRequirementInModel:
+		return "Invalid requirement in model", "REQ35"
+	case IssueTypeImplementationInProgress:
+		return "Implementation in progress", "REQ36"
+	case IssueTypeMissingFlowDiagram:
+		return "Missing flow diagram", "REQ37"
+	case IssueTypeTestInferredFromCoverage:
+		return "Test inferred from coverage", "REQ38"
+	case IssueTypeDocumentTooLarge:
+		return "Document too large", "REQ39"
+	case IssueTypeBodyTooLong:
+		return "Requirement body too long", "REQ40"
+	case IssueTypeVirtualDocument:
+		return "Document only made of Doxygen requirements", "REQ41"
+	}
+	return "", ""
 }
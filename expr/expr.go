@@ -0,0 +1,488 @@
+/*
+A small expression language used to compute values out of the requirements graph.
+
+Expressions are made of:
+  - Literals: "quoted strings", true and false.
+  - Variables: bare identifiers such as `implemented`, provided by the evaluation environment. Identifiers
+    which are not known by the environment evaluate to themselves as strings, so `doc == TEST-138-SDD`
+    does not require quoting.
+  - Function calls: `attr("SAFETY IMPACT")`, provided by the evaluation environment.
+  - Comparisons: `==`, `!=` and `=~` (regular expression match).
+  - Logical operators: `!`, `&&` and `||`, as well as parentheses for grouping.
+
+Values are either booleans or strings. Strings are considered true when they are not empty and not "false".
+*/
+
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// A value resulting of the evaluation of an expression. It can hold a string or a boolean
+type Value struct {
+	isBool bool
+	b      bool
+	s      string
+}
+
+// Bool creates a boolean value
+// @llr REQ-TRAQ-SWL-89
+func Bool(b bool) Value {
+	return Value{isBool: true, b: b}
+}
+
+// String creates a string value
+// @llr REQ-TRAQ-SWL-89
+func String(s string) Value {
+	return Value{s: s}
+}
+
+// Truthy returns the value interpreted as a boolean
+// @llr REQ-TRAQ-SWL-89
+func (v Value) Truthy() bool {
+	if v.isBool {
+		return v.b
+	}
+	return v.s != "" && v.s != "false"
+}
+
+// String returns the value interpreted as a string
+// @llr REQ-TRAQ-SWL-89
+func (v Value) String() string {
+	if v.isBool {
+		if v.b {
+			return "true"
+		}
+		return "false"
+	}
+	return v.s
+}
+
+// The environment in which an expression is evaluated. It provides the values of variables and functions.
+type Env interface {
+	// Variable returns the value of a variable and whether the variable is known
+	Variable(name string) (Value, bool)
+	// Call invokes the function with the given name and arguments
+	Call(name string, args []Value) (Value, error)
+}
+
+// A parsed expression, ready to be evaluated
+type Expression struct {
+	source string
+	root   node
+}
+
+type node interface {
+	eval(env Env) (Value, error)
+}
+
+type literalNode struct {
+	value Value
+}
+
+type variableNode struct {
+	name string
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+type notNode struct {
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+	// Compiled pattern when the right hand side of a `=~` operator is a literal
+	re *regexp.Regexp
+}
+
+// Parse parses the given source into an expression, returning an error if it is malformed
+// @llr REQ-TRAQ-SWL-89
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected `%s` in expression `%s`", p.peek().text, source)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// MustParse parses the given source into an expression and panics if it is malformed
+// @llr REQ-TRAQ-SWL-89
+func MustParse(source string) *Expression {
+	e, err := Parse(source)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Eval evaluates the expression in the given environment
+// @llr REQ-TRAQ-SWL-89
+func (e *Expression) Eval(env Env) (Value, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return Value{}, fmt.Errorf("evaluating `%s`: %s", e.source, err)
+	}
+	return v, nil
+}
+
+// String returns the source of the expression
+// @llr REQ-TRAQ-SWL-89
+func (e *Expression) String() string {
+	return e.source
+}
+
+// MarshalText implements encoding.TextMarshaler, so that expressions are stored by their source
+// @llr REQ-TRAQ-SWL-89
+func (e *Expression) MarshalText() ([]byte, error) {
+	return []byte(e.source), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the stored source again
+// @llr REQ-TRAQ-SWL-89
+func (e *Expression) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*e = *parsed
+	return nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (n literalNode) eval(env Env) (Value, error) {
+	return n.value, nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (n variableNode) eval(env Env) (Value, error) {
+	if v, ok := env.Variable(n.name); ok {
+		return v, nil
+	}
+	return String(n.name), nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (n callNode) eval(env Env) (Value, error) {
+	args := make([]Value, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return Value{}, err
+		}
+		args = append(args, v)
+	}
+	return env.Call(n.name, args)
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (n notNode) eval(env Env) (Value, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return Value{}, err
+	}
+	return Bool(!v.Truthy()), nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (n binaryNode) eval(env Env) (Value, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return Value{}, err
+	}
+
+	// Logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if !left.Truthy() {
+			return Bool(false), nil
+		}
+	case "||":
+		if left.Truthy() {
+			return Bool(true), nil
+		}
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return Bool(right.Truthy()), nil
+	case "==":
+		return Bool(left.String() == right.String()), nil
+	case "!=":
+		return Bool(left.String() != right.String()), nil
+	case "=~":
+		re := n.re
+		if re == nil {
+			re, err = regexp.Compile(right.String())
+			if err != nil {
+				return Value{}, err
+			}
+		}
+		return Bool(re.MatchString(left.String())), nil
+	}
+	return Value{}, fmt.Errorf("unknown operator `%s`", n.op)
+}
+
+/// Tokenizer
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{"&&", "||", "==", "!=", "=~", "!", "(", ")", ","}
+
+// isIdentRune returns true if the rune can be part of a bare identifier
+// @llr REQ-TRAQ-SWL-89
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == '/'
+}
+
+// tokenize splits the source of an expression into tokens
+// @llr REQ-TRAQ-SWL-89
+func tokenize(source string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+
+		if r == '"' {
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression `%s`", source)
+			}
+			tokens = append(tokens, token{tokenString, sb.String()})
+			i = j + 1
+			continue
+		}
+
+		if isIdentRune(r) {
+			j := i
+			for j < len(runes) && isIdentRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[i:j])})
+			i = j
+			continue
+		}
+
+		matched := false
+		for _, op := range operators {
+			if strings.HasPrefix(string(runes[i:]), op) {
+				tokens = append(tokens, token{tokenOperator, op})
+				i += len([]rune(op))
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character `%c` in expression `%s`", r, source)
+		}
+	}
+	return tokens, nil
+}
+
+/// Recursive descent parser
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token if it is the given operator
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) accept(op string) bool {
+	if !p.done() && p.peek().kind == tokenOperator && p.peek().text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "=~"} {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		n := binaryNode{op: op, left: left, right: right}
+		if lit, ok := right.(literalNode); ok && op == "=~" {
+			n.re, err = regexp.Compile(lit.value.String())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return n, nil
+	}
+	return left, nil
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) parsePrimary() (node, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+
+	tok := p.peek()
+	switch tok.kind {
+	case tokenString:
+		p.pos++
+		return literalNode{String(tok.text)}, nil
+	case tokenIdent:
+		p.pos++
+		switch tok.text {
+		case "true":
+			return literalNode{Bool(true)}, nil
+		case "false":
+			return literalNode{Bool(false)}, nil
+		}
+		if !p.accept("(") {
+			return variableNode{tok.text}, nil
+		}
+		call := callNode{name: tok.text}
+		if p.accept(")") {
+			return call, nil
+		}
+		for {
+			arg, err := p.parseCallArgument()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.accept(")") {
+				return call, nil
+			}
+			if !p.accept(",") {
+				return nil, fmt.Errorf("expected `,` or `)` in arguments of `%s`", tok.text)
+			}
+		}
+	}
+	return nil, fmt.Errorf("unexpected `%s`", tok.text)
+}
+
+// parseCallArgument parses an argument of a function call. Bare identifiers are taken literally, so
+// that `attr(STATUS)` refers to the attribute named STATUS and not to a variable.
+// @llr REQ-TRAQ-SWL-89
+func (p *parser) parseCallArgument() (node, error) {
+	if !p.done() && p.peek().kind == tokenIdent && p.pos+1 < len(p.tokens) {
+		tok, next := p.peek(), p.tokens[p.pos+1]
+		if next.kind == tokenOperator && (next.text == "," || next.text == ")") {
+			p.pos++
+			return literalNode{String(tok.text)}, nil
+		}
+	}
+	return p.parseOr()
+}
@@ -0,0 +1,106 @@
+package expr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEnv map[string]Value
+
+// @llr REQ-TRAQ-SWL-89
+func (e testEnv) Variable(name string) (Value, bool) {
+	v, ok := e[name]
+	return v, ok
+}
+
+// @llr REQ-TRAQ-SWL-89
+func (e testEnv) Call(name string, args []Value) (Value, error) {
+	if name == "upper" && len(args) == 1 {
+		return String(fmt.Sprintf("<%s>", args[0].String())), nil
+	}
+	return Value{}, fmt.Errorf("unknown function `%s`", name)
+}
+
+// @llr REQ-TRAQ-SWL-89
+func TestExpression_Eval(t *testing.T) {
+	env := testEnv{
+		"implemented": Bool(true),
+		"tested":      Bool(false),
+		"doc":         String("TEST-138-SDD"),
+		"empty":       String(""),
+	}
+
+	for _, tc := range []struct {
+		source   string
+		expected string
+	}{
+		{"implemented", "true"},
+		{"implemented && tested", "false"},
+		{"implemented || tested", "true"},
+		{"!tested", "true"},
+		{"!(implemented && !tested)", "false"},
+		{"doc == TEST-138-SDD", "true"},
+		{`doc != "TEST-138-SDD"`, "false"},
+		{`doc =~ "SDD$"`, "true"},
+		{"doc =~ doc", "true"},
+		{"empty || false", "false"},
+		{"doc", "TEST-138-SDD"},
+		{"unknown", "unknown"},
+		{"upper(STATUS)", "<STATUS>"},
+		{`upper("SAFETY IMPACT") == "<SAFETY IMPACT>"`, "true"},
+		{"upper(doc == TEST-138-SDD)", "<true>"},
+		{"tested == false && implemented == true", "true"},
+	} {
+		e, err := Parse(tc.source)
+		if !assert.NoError(t, err, tc.source) {
+			continue
+		}
+		v, err := e.Eval(env)
+		assert.NoError(t, err, tc.source)
+		assert.Equal(t, tc.expected, v.String(), tc.source)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-89
+func TestExpression_ParseErrors(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"implemented &&",
+		"(implemented",
+		"implemented tested",
+		`"unterminated`,
+		"implemented & tested",
+		`doc =~ "("`,
+		"upper(a b)",
+	} {
+		_, err := Parse(source)
+		assert.Error(t, err, source)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-89
+func TestExpression_EvalErrors(t *testing.T) {
+	_, err := MustParse("missing(x)").Eval(testEnv{})
+	assert.EqualError(t, err, "evaluating `missing(x)`: unknown function `missing`")
+
+	_, err = MustParse("a =~ b").Eval(testEnv{"b": String("(")})
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-89
+func TestExpression_MarshalText(t *testing.T) {
+	e := MustParse("implemented && tested")
+	text, err := e.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "implemented && tested", string(text))
+
+	var parsed Expression
+	assert.NoError(t, parsed.UnmarshalText(text))
+	v, err := parsed.Eval(testEnv{"implemented": Bool(true), "tested": Bool(true)})
+	assert.NoError(t, err)
+	assert.True(t, v.Truthy())
+
+	assert.Error(t, parsed.UnmarshalText([]byte("(")))
+}
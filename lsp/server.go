@@ -0,0 +1,272 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// Server is a language server answering the requests of an editor about the requirements graph: the definition of
+// the requirement referenced by a code tag, the title and body of the requirement when hovering the reference, and
+// the issues of the graph as diagnostics of the files they are found in.
+type Server struct {
+	build func() (*reqs.ReqGraph, error)
+	out   io.Writer
+	rg    *reqs.ReqGraph
+	// The code tags of the graph by absolute path of their file
+	codeByPath map[string][]*code.Code
+	// The files diagnostics were published for, so they are cleared once their issues are resolved
+	published map[string]bool
+	// Whether the editor asked the server to shut down
+	shutdown bool
+}
+
+// NewServer returns a server answering from the graph returned by build. The graph is built when the editor
+// initializes the server and rebuilt when a file is saved.
+// @llr REQ-TRAQ-SWL-179
+func NewServer(build func() (*reqs.ReqGraph, error)) *Server {
+	return &Server{build: build, published: make(map[string]bool)}
+}
+
+// Serve reads the messages of the editor from r and writes the responses and notifications to w, until the editor
+// asks the server to exit or closes the input
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	in := bufio.NewReader(r)
+	for {
+		content, err := readMessage(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var message incomingMessage
+		if err := json.Unmarshal(content, &message); err != nil {
+			return fmt.Errorf("invalid message: %v", err)
+		}
+		if message.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit requested without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(message); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers a request or processes a notification. Only the errors writing to the editor are returned, the
+// others are sent to the editor.
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) handle(message incomingMessage) error {
+	var result interface{}
+	var err error
+	switch message.Method {
+	case "initialize":
+		s.rebuild()
+		var initialize initializeResult
+		initialize.Capabilities = serverCapabilities{
+			TextDocumentSync:   textDocumentSyncOptions{OpenClose: true, Save: true},
+			DefinitionProvider: true,
+			HoverProvider:      true,
+		}
+		initialize.ServerInfo.Name = "reqtraq"
+		result = initialize
+	case "initialized":
+		return s.publishDiagnostics()
+	case "textDocument/didSave":
+		s.rebuild()
+		return s.publishDiagnostics()
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(message.Params, &params); err == nil {
+			result = s.definition(params)
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(message.Params, &params); err == nil {
+			result = s.hover(params)
+		}
+	case "shutdown":
+		s.shutdown = true
+	default:
+		if message.ID == nil {
+			// Notifications which are not supported are ignored
+			return nil
+		}
+		return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: message.ID,
+			Error: responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method `%s` is not supported", message.Method)}})
+	}
+
+	if message.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: message.ID,
+			Error: responseError{Code: codeInvalidParams, Message: err.Error()}})
+	}
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: message.ID, Result: result})
+}
+
+// rebuild builds the graph again. The previous graph is kept if the build fails.
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) rebuild() {
+	rg, err := s.build()
+	if err != nil {
+		log.Print("Failed to build the requirements graph: ", err)
+		return
+	}
+	s.rg = rg
+	s.codeByPath = make(map[string][]*code.Code)
+	for _, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			if path, ok := absolutePath(tag.CodeFile.RepoName, tag.CodeFile.Path); ok {
+				s.codeByPath[path] = append(s.codeByPath[path], tag)
+			}
+		}
+	}
+}
+
+// absolutePath returns the absolute path of a file of a repository, if the repository is known
+// @llr REQ-TRAQ-SWL-179
+func absolutePath(repoName repos.RepoName, path string) (string, bool) {
+	repoPath, err := repos.GetRepoPathByName(repoName)
+	if err != nil || path == "" {
+		return "", false
+	}
+	absolute, err := filepath.Abs(filepath.Join(string(repoPath), path))
+	return absolute, err == nil
+}
+
+// linkAt returns the requirement reference of a code tag at the given position of a file, if any
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) linkAt(params textDocumentPositionParams) (*code.ReqLink, *reqs.Req) {
+	path, err := pathFromURI(params.TextDocument.URI)
+	if err != nil || s.rg == nil {
+		return nil, nil
+	}
+	for _, tag := range s.codeByPath[path] {
+		for i, link := range tag.Links {
+			// The ranges of the links are zero-based, as the positions of the protocol
+			if link.Range.Start.Line != params.Position.Line ||
+				params.Position.Character < link.Range.Start.Character || params.Position.Character >= link.Range.End.Character {
+				continue
+			}
+			return &tag.Links[i], s.rg.Reqs[link.Id]
+		}
+	}
+	return nil, nil
+}
+
+// definition returns the location of the requirement referenced at the given position, nil if there is none
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) definition(params textDocumentPositionParams) *location {
+	_, r := s.linkAt(params)
+	if r == nil || r.Document == nil {
+		return nil
+	}
+	path, ok := absolutePath(r.RepoName, r.Document.Path)
+	if !ok {
+		return nil
+	}
+	// The positions of the requirements are one-based
+	start := position{Line: uint(r.Position - 1)}
+	return &location{URI: uriFromPath(path), Range: textRange{Start: start, End: start}}
+}
+
+// hover returns the title and the body of the requirement referenced at the given position, nil if there is none
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) hover(params textDocumentPositionParams) *hover {
+	link, r := s.linkAt(params)
+	if r == nil {
+		return nil
+	}
+	text := fmt.Sprintf("**%s %s**", r.ID, r.Title)
+	if r.IsDeleted() {
+		text += " (deleted)"
+	}
+	if body := strings.TrimSpace(r.Body); body != "" {
+		text += "\n\n" + body
+	}
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: text},
+		Range: &textRange{
+			Start: position{Line: link.Range.Start.Line, Character: link.Range.Start.Character},
+			End:   position{Line: link.Range.End.Line, Character: link.Range.End.Character},
+		},
+	}
+}
+
+// newDiagnostic returns the diagnostic reporting an issue
+// @llr REQ-TRAQ-SWL-179
+func newDiagnostic(issue diagnostics.Issue) diagnostic {
+	line := uint(0)
+	if issue.Line > 0 {
+		line = uint(issue.Line - 1)
+	}
+	severity := severityError
+	switch issue.Severity {
+	case diagnostics.IssueSeverityMinor:
+		severity = severityWarning
+	case diagnostics.IssueSeverityNote:
+		severity = severityInformation
+	}
+	_, code := diagnostics.TypeInfo(issue.Type)
+	return diagnostic{
+		Range:    textRange{Start: position{Line: line}, End: position{Line: line}},
+		Severity: severity,
+		Code:     code,
+		Source:   "reqtraq",
+		Message:  issue.Description,
+	}
+}
+
+// publishDiagnostics sends the issues of the graph as the diagnostics of the files they are found in, ordered by
+// path, and clears the diagnostics of the files which no longer have issues
+// @llr REQ-TRAQ-SWL-179
+func (s *Server) publishDiagnostics() error {
+	if s.rg == nil {
+		return nil
+	}
+	byPath := make(map[string][]diagnostic)
+	for _, issue := range s.rg.Issues {
+		if path, ok := absolutePath(issue.RepoName, issue.Path); ok {
+			byPath[path] = append(byPath[path], newDiagnostic(issue))
+		}
+	}
+	for path := range s.published {
+		if _, ok := byPath[path]; !ok {
+			byPath[path] = []diagnostic{}
+		}
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	s.published = make(map[string]bool)
+	for _, path := range paths {
+		if len(byPath[path]) > 0 {
+			s.published[path] = true
+		}
+		params := publishDiagnosticsParams{URI: uriFromPath(path), Diagnostics: byPath[path]}
+		if err := writeMessage(s.out, notification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: params}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-179
+func TestServer(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.ClearAllRepositories()
+	repos.RegisterRepository("repo", repos.RepoPath(repoPath))
+	defer repos.ClearAllRepositories()
+
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	swl := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Logging", Body: "The software shall log the events.", Document: doc,
+		RepoName: "repo", Position: 12}
+	// `// @llr REQ-TEST-SWL-1` on the fourth line of the file
+	tag := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "src/log.go"}, Tag: "Log", Line: 5,
+		Links: []code.ReqLink{{Id: swl.ID, Range: code.Range{Start: code.Position{Line: 3, Character: 8}, End: code.Position{Line: 3, Character: 22}}}}}
+	builds := []*reqs.ReqGraph{
+		{
+			Reqs:     map[string]*reqs.Req{swl.ID: swl},
+			CodeTags: map[repos.RepoName][]*code.Code{"repo": {tag}},
+			Issues: []diagnostics.Issue{{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 12, Description: "Missing attribute",
+				Severity: diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeMissingAttribute}},
+		},
+		{
+			Reqs:     map[string]*reqs.Req{swl.ID: swl},
+			CodeTags: map[repos.RepoName][]*code.Code{"repo": {tag}},
+		},
+	}
+	server := NewServer(func() (*reqs.ReqGraph, error) {
+		rg := builds[0]
+		builds = builds[1:]
+		return rg, nil
+	})
+
+	codeURI := uriFromPath(filepath.Join(repoPath, "src/log.go"))
+	docURI := uriFromPath(filepath.Join(repoPath, "TEST-138-SDD.md"))
+	var in bytes.Buffer
+	for _, message := range []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`,
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 2, "method": "textDocument/definition", "params": {"textDocument": {"uri": %q}, "position": {"line": 3, "character": 10}}}`, codeURI),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 3, "method": "textDocument/hover", "params": {"textDocument": {"uri": %q}, "position": {"line": 3, "character": 21}}}`, codeURI),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "id": 4, "method": "textDocument/hover", "params": {"textDocument": {"uri": %q}, "position": {"line": 3, "character": 22}}}`, codeURI),
+		fmt.Sprintf(`{"jsonrpc": "2.0", "method": "textDocument/didSave", "params": {"textDocument": {"uri": %q}}}`, docURI),
+		`{"jsonrpc": "2.0", "id": 5, "method": "workspace/symbol", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "shutdown"}`,
+		`{"jsonrpc": "2.0", "method": "exit"}`,
+	} {
+		assert.NoError(t, writeMessage(&in, json.RawMessage(message)))
+	}
+	var out bytes.Buffer
+	assert.NoError(t, server.Serve(&in, &out))
+
+	var messages []string
+	reader := bufio.NewReader(&out)
+	for {
+		content, err := readMessage(reader)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		messages = append(messages, string(content))
+	}
+	assert.Equal(t, []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"capabilities":{"textDocumentSync":{"openClose":true,"change":0,"save":true},"definitionProvider":true,"hoverProvider":true},"serverInfo":{"name":"reqtraq"}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"` + docURI + `","diagnostics":[{"range":{"start":{"line":11,"character":0},"end":{"line":11,"character":0}},"severity":1,"code":"REQ6","source":"reqtraq","message":"Missing attribute"}]}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"uri":"` + docURI + `","range":{"start":{"line":11,"character":0},"end":{"line":11,"character":0}}}}`,
+		`{"jsonrpc":"2.0","id":3,"result":{"contents":{"kind":"markdown","value":"**REQ-TEST-SWL-1 Logging**\n\nThe software shall log the events."},"range":{"start":{"line":3,"character":8},"end":{"line":3,"character":22}}}}`,
+		`{"jsonrpc":"2.0","id":4,"result":null}`,
+		// The diagnostics of the resolved issues are cleared
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"` + docURI + `","diagnostics":[]}}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32601,"message":"method ` + "`workspace/symbol`" + ` is not supported"}}`,
+		`{"jsonrpc":"2.0","id":6,"result":null}`,
+	}, messages)
+}
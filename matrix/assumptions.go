@@ -0,0 +1,46 @@
+package matrix
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// GenerateAssumptionTable generates the table, in the given format, listing each assumption with the requirements
+// owning it, its validation plan and the code checking it
+// @llr REQ-TRAQ-SWL-167, REQ-TRAQ-SWL-187
+func GenerateAssumptionTable(rg *reqs.ReqGraph, w io.Writer, format string) error {
+	if format != FormatHTML {
+		return writeRecords(w, format, "Assumptions", assumptionRecords(rg.AssumptionValidations()))
+	}
+	return assumptionTmpl.ExecuteTemplate(w, "ASSUMPTIONS", rg.AssumptionValidations())
+}
+
+var assumptionTmpl = template.Must(template.Must(template.New("").Parse(headerFooterTmplText)).Parse(assumptionTmplText))
+
+var assumptionTmplText = `
+{{ define "ASSUMPTIONS" }}
+	{{template "HEADER"}}
+	<h1>Trace Matrix of the Assumptions</h1>
+
+	<div class="trace-matrix-table" style="margin-top: 1em;">
+		<div>
+			<div><strong>Assumption</strong></div><div><strong>Owning requirements</strong></div>
+			<div><strong>Validation</strong></div><div><strong>Checked by</strong></div>
+		</div>
+	{{- range . }}
+		<div>
+			<div class="assumption">{{ .Assumption.ID }} {{ .Assumption.Title }}</div>
+			<div>{{ range .Owners }}{{ .ID }}<br>{{ end }}</div>
+			<div>{{ if .Plan }}{{ .Plan }}{{ else if not .IsValidated }}<span class="text-danger">No validation plan</span>{{ end }}</div>
+			<div>{{ range .Evidence }}{{ .CodeFile.RepoName }}: {{ .CodeFile.Path }} - {{ .Tag }}<br>{{ end }}</div>
+		</div>
+	{{- else }}
+		<div><div>No assumptions</div></div>
+	{{- end }}
+	</div>
+
+	{{ template "FOOTER" }}
+{{ end }}
+`
@@ -0,0 +1,31 @@
+package matrix
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-167
+func TestMatrix_AssumptionTable(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	swl1 := &reqs.Req{ID: "REQ-TEST-SWL-1", Document: doc, RepoName: "repo", Position: 3}
+	check := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a_test.go", Type: code.CodeTypeTests}, Tag: "TestRange"}
+	checked := &reqs.Req{ID: "ASM-TEST-SWL-1", Title: "Range", Variant: reqs.ReqVariantAssumption, Document: doc, RepoName: "repo",
+		Position: 9, Parents: []*reqs.Req{swl1}, Tags: []*code.Code{check}}
+	unvalidated := &reqs.Req{ID: "ASM-TEST-SWL-2", Title: "Speed", Variant: reqs.ReqVariantAssumption, Document: doc, RepoName: "repo",
+		Position: 15, Parents: []*reqs.Req{swl1}}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{swl1.ID: swl1, checked.ID: checked, unvalidated.ID: unvalidated}}
+
+	var out bytes.Buffer
+	assert.NoError(t, GenerateAssumptionTable(rg, &out, FormatHTML))
+	html := out.String()
+	assert.Contains(t, html, "ASM-TEST-SWL-1 Range")
+	assert.Contains(t, html, "repo: a_test.go - TestRange")
+	assert.Contains(t, html, "REQ-TEST-SWL-1<br>")
+	assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("No validation plan")))
+}
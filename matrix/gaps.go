@@ -0,0 +1,132 @@
+package matrix
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// GapItem is a requirement, a code function or a model element which is not linked to anything in the other side of a matrix.
+type GapItem struct {
+	// Name of the item as shown in the matrix, the ID for requirements
+	Name     string
+	RepoName repos.RepoName
+	// Path to the document, code file or model manifest defining the item and the line where it is defined
+	Path string
+	Line int
+}
+
+// MatrixGaps lists the gaps of the trace matrices between two sets of items
+type MatrixGaps struct {
+	From, To string
+	// Items of the From set not linked to any item of the To set
+	Downstream []GapItem
+	// Items of the To set not linked to any item of the From set
+	Upstream []GapItem
+}
+
+// newGapItem returns the gap item for the given matrix cell.
+// @llr REQ-TRAQ-SWL-99, REQ-TRAQ-SWL-176
+func newGapItem(cell *TableCell) GapItem {
+	if cell.code != nil {
+		return GapItem{Name: cell.Name, RepoName: cell.code.CodeFile.RepoName, Path: cell.code.CodeFile.Path, Line: cell.code.Line}
+	}
+	if cell.model != nil {
+		return GapItem{Name: cell.Name, RepoName: cell.model.RepoName, Path: cell.model.Manifest}
+	}
+	item := GapItem{Name: cell.Name, RepoName: cell.req.RepoName, Line: cell.req.Position}
+	if cell.req.Document != nil {
+		item.Path = cell.req.Document.Path
+	}
+	return item
+}
+
+// matrixGaps returns the items in the first column of the rows which have no item in the second column.
+// @llr REQ-TRAQ-SWL-99
+func matrixGaps(matrix []TableRow) []GapItem {
+	gaps := make([]GapItem, 0)
+	for _, row := range matrix {
+		if row[1] == nil {
+			gaps = append(gaps, newGapItem(row[0]))
+		}
+	}
+	return gaps
+}
+
+// TraceGaps returns the gaps in the trace matrices between the two specified node types.
+// @llr REQ-TRAQ-SWL-99
+func TraceGaps(rg *reqs.ReqGraph, nodeTypeA, nodeTypeB config.ReqSpec) MatrixGaps {
+	itemsAB := createDownstreamMatrix(rg, nodeTypeA, nodeTypeB)
+	itemsBA := createUpstreamMatrix(rg, nodeTypeB, nodeTypeA)
+	sortMatrices(rg, itemsAB, itemsBA)
+
+	return MatrixGaps{
+		From:       nodeTypeA.String(),
+		To:         nodeTypeB.String(),
+		Downstream: matrixGaps(itemsAB),
+		Upstream:   matrixGaps(itemsBA),
+	}
+}
+
+// CodeTraceGaps returns the gaps in the trace matrices between the specified node type and code.
+// @llr REQ-TRAQ-SWL-99
+func CodeTraceGaps(rg *reqs.ReqGraph, reqSpec config.ReqSpec, codeType code.CodeType) MatrixGaps {
+	itemsAB := createSWLCodeMatrix(rg, reqSpec, codeType)
+	itemsBA := createCodeSWLMatrix(rg, reqSpec, codeType)
+	sortMatrices(rg, itemsAB, itemsBA)
+
+	return MatrixGaps{
+		From:       reqSpec.String(),
+		To:         codeType.String(),
+		Downstream: matrixGaps(itemsAB),
+		Upstream:   matrixGaps(itemsBA),
+	}
+}
+
+// CodeReqSpecs returns the requirement specifications of the documents with implementation, ready to be
+// used in code trace matrices.
+// @llr REQ-TRAQ-SWL-99
+func CodeReqSpecs(reqtraqConfig *config.Config) []config.ReqSpec {
+	var specs []config.ReqSpec
+	for _, repo := range reqtraqConfig.Repos {
+		for _, document := range repo.Documents {
+			if !document.HasImplementation() {
+				continue
+			}
+			spec := document.ReqSpec
+			spec.Re = spec.Format().RequirementsRegexp(spec.Prefix, spec.Level)
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// AllTraceGaps returns the gaps in all the trace matrices of the requirements graph: between linked
+// documents and between documents with implementation and their implementation, tests and model elements, if any.
+// @llr REQ-TRAQ-SWL-99, REQ-TRAQ-SWL-176
+func AllTraceGaps(rg *reqs.ReqGraph) []MatrixGaps {
+	var gaps []MatrixGaps
+	for _, link := range rg.ReqtraqConfig.GetLinkedSpecs() {
+		gaps = append(gaps, TraceGaps(rg, link.Parent, link.Child))
+	}
+	for _, spec := range CodeReqSpecs(rg.ReqtraqConfig) {
+		gaps = append(gaps, CodeTraceGaps(rg, spec, code.CodeTypeImplementation))
+		gaps = append(gaps, CodeTraceGaps(rg, spec, code.CodeTypeTests))
+		if len(rg.ModelElements) > 0 {
+			gaps = append(gaps, ModelTraceGaps(rg, spec))
+		}
+	}
+	return gaps
+}
+
+// WriteGapsJSON writes the given matrix gaps as JSON.
+// @llr REQ-TRAQ-SWL-99
+func WriteGapsJSON(w io.Writer, gaps []MatrixGaps) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(gaps)
+}
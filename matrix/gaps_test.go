@@ -0,0 +1,65 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-99
+func TestMatrix_TraceGaps(t *testing.T) {
+	swhSpec := config.ReqSpec{Prefix: "TEST", Level: "SWH", Re: regexp.MustCompile(`REQ-TEST-SWH-(\d+)`)}
+	swlSpec := config.ReqSpec{Prefix: "TEST", Level: "SWL", Re: regexp.MustCompile(`REQ-TEST-SWL-(\d+)`)}
+	swhDoc := config.Document{Path: "TEST-137-SRD.md", ReqSpec: swhSpec}
+	swlDoc := config.Document{Path: "TEST-138-SDD.md", ReqSpec: swlSpec}
+
+	swh1 := &reqs.Req{ID: "REQ-TEST-SWH-1", IDNumber: 1, Document: &swhDoc, RepoName: "repo", Position: 3}
+	swh2 := &reqs.Req{ID: "REQ-TEST-SWH-2", IDNumber: 2, Document: &swhDoc, RepoName: "repo", Position: 9}
+	swl1 := &reqs.Req{ID: "REQ-TEST-SWL-1", IDNumber: 1, Document: &swlDoc, RepoName: "repo", Position: 5, Parents: []*reqs.Req{swh1}}
+	swl2 := &reqs.Req{ID: "REQ-TEST-SWL-2", IDNumber: 2, Document: &swlDoc, RepoName: "repo", Position: 12}
+	swh1.Children = []*reqs.Req{swl1}
+
+	impl := &code.Code{
+		CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go", Type: code.CodeTypeImplementation},
+		Tag:      "f",
+		Line:     7,
+		Links:    []code.ReqLink{{Id: "REQ-TEST-SWL-1"}},
+	}
+	orphan := &code.Code{
+		CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go", Type: code.CodeTypeImplementation},
+		Tag:      "g",
+		Line:     15,
+	}
+	swl1.Tags = []*code.Code{impl}
+
+	rg := &reqs.ReqGraph{
+		Reqs:     map[string]*reqs.Req{swh1.ID: swh1, swh2.ID: swh2, swl1.ID: swl1, swl2.ID: swl2},
+		CodeTags: map[repos.RepoName][]*code.Code{"repo": {impl, orphan}},
+	}
+
+	gaps := TraceGaps(rg, swhSpec, swlSpec)
+	assert.Equal(t, "REQ-TEST-SWH", gaps.From)
+	assert.Equal(t, "REQ-TEST-SWL", gaps.To)
+	assert.Equal(t, []GapItem{{Name: "REQ-TEST-SWH-2", RepoName: "repo", Path: "TEST-137-SRD.md", Line: 9}}, gaps.Downstream)
+	assert.Equal(t, []GapItem{{Name: "REQ-TEST-SWL-2", RepoName: "repo", Path: "TEST-138-SDD.md", Line: 12}}, gaps.Upstream)
+
+	gaps = CodeTraceGaps(rg, swlSpec, code.CodeTypeImplementation)
+	assert.Equal(t, "Implementation", gaps.To)
+	assert.Equal(t, []GapItem{{Name: "REQ-TEST-SWL-2", RepoName: "repo", Path: "TEST-138-SDD.md", Line: 12}}, gaps.Downstream)
+	assert.Equal(t, []GapItem{{Name: "repo: a.go - g", RepoName: "repo", Path: "a.go", Line: 15}}, gaps.Upstream)
+
+	// Matrices without gaps are written with empty lists
+	gaps = CodeTraceGaps(rg, swhSpec, code.CodeTypeTests)
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGapsJSON(&buf, []MatrixGaps{gaps}))
+	var decoded []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []interface{}{}, decoded[0]["Upstream"])
+}
@@ -55,6 +55,14 @@ var headerFooterTmplText = `
 				display: table-cell;
 				padding: 0em 0.5em;
 			}
+			div.trace-matrix-table > div > div.assumption {
+				font-style: italic;
+			}
+			div.trace-matrix-table .rationale {
+				display: block;
+				color: #555;
+				font-size: smaller;
+			}
 		</style>
 		<!-- Load MathJax for rendering of equations -->
 		<script type="text/javascript" async
@@ -71,13 +79,11 @@ var headerFooterTmplText = `
 {{end}}
 `
 
-// GenerateTraceTables generates HTML for inspecting the gaps in the mappings between the two specified node types.
-// @llr REQ-TRAQ-SWL-14
-func GenerateTraceTables(rg *reqs.ReqGraph, w io.Writer, nodeTypeA, nodeTypeB config.ReqSpec) error {
-	data := struct {
-		From, To         string
-		ItemsAB, ItemsBA []TableRow
-	}{
+// GenerateTraceTables generates the trace matrices, in the given format, for inspecting the gaps in the mappings
+// between the two specified node types.
+// @llr REQ-TRAQ-SWL-14, REQ-TRAQ-SWL-187
+func GenerateTraceTables(rg *reqs.ReqGraph, w io.Writer, format string, nodeTypeA, nodeTypeB config.ReqSpec) error {
+	data := traceTables{
 		From: nodeTypeA.String(),
 		To:   nodeTypeB.String(),
 	}
@@ -86,17 +92,14 @@ func GenerateTraceTables(rg *reqs.ReqGraph, w io.Writer, nodeTypeA, nodeTypeB co
 	data.ItemsBA = createUpstreamMatrix(rg, nodeTypeB, nodeTypeA)
 
 	sortMatrices(rg, data.ItemsAB, data.ItemsBA)
-	return matrixTmpl.ExecuteTemplate(w, "MATRIX", data)
+	return writeTraceTables(w, format, data)
 }
 
-// GenerateCodeTraceTables generates HTML for inspecting the gaps in the mappings between the specified
-// node type and code
-// @llr REQ-TRAQ-SWL-15, REQ-TRAQ-SWL-71, REQ-TRAQ-SWL-72
-func GenerateCodeTraceTables(rg *reqs.ReqGraph, w io.Writer, reqSpec config.ReqSpec, codeType code.CodeType) error {
-	data := struct {
-		From, To         string
-		ItemsAB, ItemsBA []TableRow
-	}{
+// GenerateCodeTraceTables generates the trace matrices, in the given format, for inspecting the gaps in the
+// mappings between the specified node type and code
+// @llr REQ-TRAQ-SWL-15, REQ-TRAQ-SWL-71, REQ-TRAQ-SWL-72, REQ-TRAQ-SWL-187
+func GenerateCodeTraceTables(rg *reqs.ReqGraph, w io.Writer, format string, reqSpec config.ReqSpec, codeType code.CodeType) error {
+	data := traceTables{
 		From: reqSpec.String(),
 		To:   codeType.String(),
 	}
@@ -105,7 +108,7 @@ func GenerateCodeTraceTables(rg *reqs.ReqGraph, w io.Writer, reqSpec config.ReqS
 	data.ItemsBA = createCodeSWLMatrix(rg, reqSpec, codeType)
 
 	sortMatrices(rg, data.ItemsAB, data.ItemsBA)
-	return matrixTmpl.ExecuteTemplate(w, "MATRIX", data)
+	return writeTraceTables(w, format, data)
 }
 
 var matrixTmpl = template.Must(template.Must(template.New("").Parse(headerFooterTmplText)).Parse(matrixTmplText))
@@ -117,7 +120,9 @@ var matrixTmplText = `
 	<div>
 	{{- range . }}
 		{{ if . -}}
-			<div>{{ .Name }}</div>
+			<div{{ if .IsAssumption }} class="assumption" title="Assumption"{{ end }}>{{ .Name }}
+				{{- with .Rationale }}<span class="rationale">{{ . }}</span>{{ end -}}
+			</div>
 		{{- else -}}
 			<div class="hole"></div>
 		{{- end -}}
@@ -146,12 +151,14 @@ var matrixTmplText = `
 {{ end }}
 `
 
-// TableCell is a cell in a two-columns matrix, it can be a requirement or a code function.
+// TableCell is a cell in a two-columns matrix, it can be a requirement, a code function or a model element.
 type TableCell struct {
-	Name        string     // Name represents this item in the matrix.
-	OrderNumber int        // OrderNumber can be used to order the items in a column ascending.
-	req         *reqs.Req  // req is the represented requirement.
-	code        *code.Code // code is the represented code tag.
+	Name        string             // Name represents this item in the matrix.
+	OrderNumber int                // OrderNumber can be used to order the items in a column ascending.
+	Rationale   string             // Rationale explains how the child requirement of the row satisfies the parent one.
+	req         *reqs.Req          // req is the represented requirement.
+	code        *code.Code         // code is the represented code tag.
+	model       *reqs.ModelElement // model is the represented model element.
 }
 
 // TableRow is a pair of TableCell
@@ -175,6 +182,12 @@ func newReqTableCell(req *reqs.Req) *TableCell {
 	return item
 }
 
+// IsAssumption returns whether the cell represents an assumption, which code can only reference to check it
+// @llr REQ-TRAQ-SWL-110
+func (cell *TableCell) IsAssumption() bool {
+	return cell.req != nil && cell.req.IsAssumption()
+}
+
 // CodeOrderInfo contains everything needed to set the order number of a
 // TableCell mapping a code item. We need to be able to order the code items
 // first by repo and file name alphabetically and finally by line number.
@@ -264,8 +277,9 @@ func createCodeSWLMatrix(rg *reqs.ReqGraph, reqSpec config.ReqSpec, codeType cod
 	return items
 }
 
-// createDownstreamMatrix returns a Trace Matrix from a set of requirements to a lower level set of requirements.
-// @llr REQ-TRAQ-SWL-14
+// createDownstreamMatrix returns a Trace Matrix from a set of requirements to a lower level set of requirements,
+// with the rationales of the children for satisfying them.
+// @llr REQ-TRAQ-SWL-14, REQ-TRAQ-SWL-154
 func createDownstreamMatrix(rg *reqs.ReqGraph, from, to config.ReqSpec) []TableRow {
 	reqsHigh := reqsWithSpec(rg, from)
 	items := make([]TableRow, 0, len(reqsHigh))
@@ -276,7 +290,9 @@ func createDownstreamMatrix(rg *reqs.ReqGraph, from, to config.ReqSpec) []TableR
 				if to.AttrKey != "" && !to.AttrVal.MatchString(childReq.Attributes[to.AttrKey]) {
 					continue
 				}
-				row := TableRow{newReqTableCell(r), newReqTableCell(childReq)}
+				child := newReqTableCell(childReq)
+				child.Rationale = childReq.SatisfactionRationale(r.ID)
+				row := TableRow{newReqTableCell(r), child}
 				items = append(items, row)
 				count++
 			}
@@ -314,8 +330,9 @@ func createSWLCodeMatrix(rg *reqs.ReqGraph, reqSpec config.ReqSpec, codeType cod
 	return items
 }
 
-// createUpstreamMatrix returns a Trace Matrix from a set of requirements to an upper level set of requirements.
-// @llr REQ-TRAQ-SWL-14
+// createUpstreamMatrix returns a Trace Matrix from a set of requirements to an upper level set of requirements,
+// with the rationales of the requirements for satisfying them.
+// @llr REQ-TRAQ-SWL-14, REQ-TRAQ-SWL-154
 func createUpstreamMatrix(rg *reqs.ReqGraph, from, to config.ReqSpec) []TableRow {
 	reqsLow := reqsWithSpec(rg, from)
 	items := make([]TableRow, 0, len(reqsLow))
@@ -326,7 +343,9 @@ func createUpstreamMatrix(rg *reqs.ReqGraph, from, to config.ReqSpec) []TableRow
 				if to.AttrKey != "" && !to.AttrVal.MatchString(parentReq.Attributes[to.AttrKey]) {
 					continue
 				}
-				row := TableRow{newReqTableCell(r), newReqTableCell(parentReq)}
+				parent := newReqTableCell(parentReq)
+				parent.Rationale = r.SatisfactionRationale(parentReq.ID)
+				row := TableRow{newReqTableCell(r), parent}
 				items = append(items, row)
 				count++
 			}
@@ -355,9 +374,14 @@ func reqsWithSpec(rg *reqs.ReqGraph, spec config.ReqSpec) map[string]*reqs.Req {
 }
 
 // sortMatrices prepares the sort info and sorts the specified matrices.
-// @llr REQ-TRAQ-SWL-42, REQ-TRAQ-SWL-43, REQ-TRAQ-SWL-44
+// @llr REQ-TRAQ-SWL-42, REQ-TRAQ-SWL-43, REQ-TRAQ-SWL-44, REQ-TRAQ-SWL-176
 func sortMatrices(rg *reqs.ReqGraph, matrices ...[]TableRow) {
 	codeOrderInfo := codeOrderInfo(rg)
+	// The model elements of the graph are already ordered by repository, model and path
+	modelOrder := make(map[*reqs.ModelElement]int, len(rg.ModelElements))
+	for i, element := range rg.ModelElements {
+		modelOrder[element] = i
+	}
 	for _, matrix := range matrices {
 		for _, row := range matrix {
 			// We calculate the OrderNumber of both cells of the row because we
@@ -377,8 +401,10 @@ func sortMatrices(rg *reqs.ReqGraph, matrices ...[]TableRow) {
 						} else {
 							panic("Code file could not be found in filesIndex. This is a bug")
 						}
+					} else if item.model != nil {
+						item.OrderNumber = modelOrder[item.model]
 					} else {
-						panic("Matrix element with no valid code or requirements. This should never happen")
+						panic("Matrix element with no valid code, model or requirements. This should never happen")
 					}
 				}
 			}
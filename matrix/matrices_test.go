@@ -214,3 +214,50 @@ func TestMatrix_createMatrix(t *testing.T) {
 		},
 		matrixRows(rg, createUpstreamMatrix(rg, swlReqSpec, swhReqSpec)))
 }
+
+// @llr REQ-TRAQ-SWL-110
+func TestMatrix_AssumptionCells(t *testing.T) {
+	doc := config.Document{Path: "path/to/sdd.md"}
+	asm := &reqs.Req{ID: "ASM-TEST-SWL-1", Variant: reqs.ReqVariantAssumption, Document: &doc}
+	req := &reqs.Req{ID: "REQ-TEST-SWL-1", Variant: reqs.ReqVariantRequirement, Document: &doc}
+
+	assert.True(t, newReqTableCell(asm).IsAssumption())
+	assert.False(t, newReqTableCell(req).IsAssumption())
+
+	var out strings.Builder
+	assert.NoError(t, matrixTmpl.ExecuteTemplate(&out, "MATRIXTABLE", []TableRow{
+		{newReqTableCell(asm), nil},
+		{newReqTableCell(req), nil},
+	}))
+	assert.Contains(t, out.String(), `<div class="assumption" title="Assumption">ASM-TEST-SWL-1</div>`)
+	assert.Contains(t, out.String(), `<div>REQ-TEST-SWL-1</div>`)
+}
+
+// @llr REQ-TRAQ-SWL-154
+func TestMatrix_SatisfactionRationale(t *testing.T) {
+	swhSpec := config.ReqSpec{Prefix: "TEST", Level: "SWH", Re: regexp.MustCompile(`REQ-TEST-SWH-\d+`)}
+	swlSpec := config.ReqSpec{Prefix: "TEST", Level: "SWL", Re: regexp.MustCompile(`REQ-TEST-SWL-\d+`)}
+	srd := config.Document{Path: "path/to/srd.md", ReqSpec: swhSpec}
+	sdd := config.Document{Path: "path/to/sdd.md", ReqSpec: swlSpec}
+	swh1 := &reqs.Req{ID: "REQ-TEST-SWH-1", IDNumber: 1, Document: &srd}
+	swh2 := &reqs.Req{ID: "REQ-TEST-SWH-2", IDNumber: 2, Document: &srd}
+	swl := &reqs.Req{ID: "REQ-TEST-SWL-1", IDNumber: 1, Document: &sdd, ParentIds: []string{swh1.ID, swh2.ID},
+		Attributes: map[string]string{"SATISFIES REQ-TEST-SWH-1": "Rotates the logs <daily>."}}
+	swl.Parents = []*reqs.Req{swh1, swh2}
+	swh1.Children = []*reqs.Req{swl}
+	swh2.Children = []*reqs.Req{swl}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{swh1.ID: swh1, swh2.ID: swh2, swl.ID: swl}}
+
+	downstream := createDownstreamMatrix(rg, swhSpec, swlSpec)
+	sortMatrices(rg, downstream)
+	assert.Equal(t, "Rotates the logs <daily>.", downstream[0][1].Rationale)
+	assert.Empty(t, downstream[1][1].Rationale)
+	upstream := createUpstreamMatrix(rg, swlSpec, swhSpec)
+	sortMatrices(rg, upstream)
+	assert.Equal(t, "Rotates the logs <daily>.", upstream[0][1].Rationale)
+	assert.Empty(t, upstream[1][1].Rationale)
+
+	var out strings.Builder
+	assert.NoError(t, GenerateTraceTables(rg, &out, FormatHTML, swhSpec, swlSpec))
+	assert.Contains(t, out.String(), `<div>REQ-TEST-SWL-1<span class="rationale">Rotates the logs &lt;daily&gt;.</span></div>`)
+}
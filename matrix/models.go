@@ -0,0 +1,92 @@
+package matrix
+
+import (
+	"io"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// ModelColumn is the name of the column of the model elements in the matrices
+const ModelColumn = "Model"
+
+// GenerateModelTraceTables generates the trace matrices, in the given format, for inspecting the gaps in the
+// mappings between the specified node type and the model elements
+// @llr REQ-TRAQ-SWL-176, REQ-TRAQ-SWL-187
+func GenerateModelTraceTables(rg *reqs.ReqGraph, w io.Writer, format string, reqSpec config.ReqSpec) error {
+	data := traceTables{
+		From: reqSpec.String(),
+		To:   ModelColumn,
+	}
+
+	data.ItemsAB = createReqModelMatrix(rg, reqSpec)
+	data.ItemsBA = createModelReqMatrix(rg, reqSpec)
+
+	sortMatrices(rg, data.ItemsAB, data.ItemsBA)
+	return writeTraceTables(w, format, data)
+}
+
+// ModelTraceGaps returns the gaps in the trace matrices between the specified node type and the model elements.
+// @llr REQ-TRAQ-SWL-176
+func ModelTraceGaps(rg *reqs.ReqGraph, reqSpec config.ReqSpec) MatrixGaps {
+	itemsAB := createReqModelMatrix(rg, reqSpec)
+	itemsBA := createModelReqMatrix(rg, reqSpec)
+	sortMatrices(rg, itemsAB, itemsBA)
+
+	return MatrixGaps{
+		From:       reqSpec.String(),
+		To:         ModelColumn,
+		Downstream: matrixGaps(itemsAB),
+		Upstream:   matrixGaps(itemsBA),
+	}
+}
+
+// newModelTableCell creates a new matrix cell from a model element
+// @llr REQ-TRAQ-SWL-176
+func newModelTableCell(element *reqs.ModelElement) *TableCell {
+	return &TableCell{Name: element.Name(), model: element}
+}
+
+// createReqModelMatrix creates a downstream matrix mapping requirements to the model elements implementing them.
+// @llr REQ-TRAQ-SWL-176
+func createReqModelMatrix(rg *reqs.ReqGraph, reqSpec config.ReqSpec) []TableRow {
+	elements := make(map[string][]*reqs.ModelElement)
+	for _, element := range rg.ModelElements {
+		for _, id := range element.ReqIds {
+			elements[id] = append(elements[id], element)
+		}
+	}
+
+	reqs := reqsWithSpec(rg, reqSpec)
+	items := make([]TableRow, 0, len(reqs))
+	for _, r := range reqs {
+		for _, element := range elements[r.ID] {
+			items = append(items, TableRow{newReqTableCell(r), newModelTableCell(element)})
+		}
+		if len(elements[r.ID]) == 0 {
+			items = append(items, TableRow{newReqTableCell(r), nil})
+		}
+	}
+	return items
+}
+
+// createModelReqMatrix creates an upstream matrix mapping model elements to the requirements they implement.
+// @llr REQ-TRAQ-SWL-176
+func createModelReqMatrix(rg *reqs.ReqGraph, reqSpec config.ReqSpec) []TableRow {
+	reqs := reqsWithSpec(rg, reqSpec)
+	items := make([]TableRow, 0, len(rg.ModelElements))
+	for _, element := range rg.ModelElements {
+		count := 0
+		for _, id := range element.ReqIds {
+			if r, ok := reqs[id]; ok {
+				items = append(items, TableRow{newModelTableCell(element), newReqTableCell(r)})
+				count++
+			}
+		}
+		if count == 0 {
+			// The model element does not implement any requirement matching the reqSpec. Display it with a gap.
+			items = append(items, TableRow{newModelTableCell(element), nil})
+		}
+	}
+	return items
+}
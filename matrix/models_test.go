@@ -0,0 +1,37 @@
+package matrix
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-176
+func TestMatrix_ModelTraceTables(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	swl1 := &reqs.Req{ID: "REQ-TEST-SWL-1", IDNumber: 1, Document: doc, RepoName: "repo", Position: 3}
+	swl2 := &reqs.Req{ID: "REQ-TEST-SWL-2", IDNumber: 2, Document: doc, RepoName: "repo", Position: 9}
+	pid := &reqs.ModelElement{Model: "autopilot.slx", Path: "autopilot/PID", ReqIds: []string{"REQ-TEST-SWL-1"}, RepoName: "repo", Manifest: "models/autopilot.json"}
+	unlinked := &reqs.ModelElement{Model: "autopilot.slx", Path: "autopilot/Scope", RepoName: "repo", Manifest: "models/autopilot.json"}
+	rg := &reqs.ReqGraph{
+		Reqs:          map[string]*reqs.Req{swl1.ID: swl1, swl2.ID: swl2},
+		ModelElements: []*reqs.ModelElement{pid, unlinked},
+	}
+	spec := config.ReqSpec{Prefix: "TEST", Level: "SWL", Re: regexp.MustCompile(`^REQ-TEST-SWL-\d+$`)}
+
+	var out bytes.Buffer
+	assert.NoError(t, GenerateModelTraceTables(rg, &out, FormatHTML, spec))
+	assert.Contains(t, out.String(), "Trace Matrices REQ-TEST-SWL &ndash; Model")
+	assert.Contains(t, out.String(), "repo: autopilot.slx - autopilot/PID")
+
+	assert.Equal(t, MatrixGaps{
+		From:       "REQ-TEST-SWL",
+		To:         "Model",
+		Downstream: []GapItem{{Name: "REQ-TEST-SWL-2", RepoName: "repo", Path: "TEST-138-SDD.md", Line: 9}},
+		Upstream:   []GapItem{{Name: "repo: autopilot.slx - autopilot/Scope", RepoName: "repo", Path: "models/autopilot.json"}},
+	}, ModelTraceGaps(rg, spec))
+}
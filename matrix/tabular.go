@@ -0,0 +1,176 @@
+package matrix
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// The formats the matrices can be written in: HTML, as shown in the web interface, or CSV and Excel workbooks to be
+// reviewed in spreadsheets
+const (
+	FormatHTML = "html"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// CheckFormat returns an error if the given format is not one the matrices can be written in
+// @llr REQ-TRAQ-SWL-187
+func CheckFormat(format string) error {
+	switch format {
+	case FormatHTML, FormatCSV, FormatXLSX:
+		return nil
+	}
+	return fmt.Errorf("Unknown matrix format `%s`, expected one of %s, %s or %s", format, FormatHTML, FormatCSV, FormatXLSX)
+}
+
+// traceTables holds both directions of a trace matrix, the rows of the downstream one starting with the items of the
+// From column and the rows of the upstream one with the items of the To column
+type traceTables struct {
+	From, To         string
+	ItemsAB, ItemsBA []TableRow
+}
+
+// writeTraceTables writes a trace matrix in the given format
+// @llr REQ-TRAQ-SWL-14, REQ-TRAQ-SWL-187
+func writeTraceTables(w io.Writer, format string, data traceTables) error {
+	if format == FormatHTML {
+		return matrixTmpl.ExecuteTemplate(w, "MATRIX", data)
+	}
+	return writeRecords(w, format, "Trace matrix", data.records())
+}
+
+// records returns the rows of a trace matrix as spreadsheet rows, after a header row: the direction, the items of
+// the From and To columns, empty for the gaps, and the rationale of the link
+// @llr REQ-TRAQ-SWL-187
+func (data traceTables) records() [][]string {
+	records := [][]string{{"Direction", data.From, data.To, "Rationale"}}
+	name := func(cell *TableCell) string {
+		if cell == nil {
+			return ""
+		}
+		return cell.Name
+	}
+	rationale := func(row TableRow) string {
+		for _, cell := range row {
+			if cell != nil && cell.Rationale != "" {
+				return cell.Rationale
+			}
+		}
+		return ""
+	}
+	for _, row := range data.ItemsAB {
+		records = append(records, []string{"Downstream", name(row[0]), name(row[1]), rationale(row)})
+	}
+	for _, row := range data.ItemsBA {
+		records = append(records, []string{"Upstream", name(row[1]), name(row[0]), rationale(row)})
+	}
+	return records
+}
+
+// assumptionRecords returns the assumptions as spreadsheet rows, after a header row, with the requirements owning
+// them, their validation plan and the code checking them
+// @llr REQ-TRAQ-SWL-167, REQ-TRAQ-SWL-187
+func assumptionRecords(validations []reqs.AssumptionValidation) [][]string {
+	records := [][]string{{"Assumption", "Owning requirements", "Validation", "Checked by"}}
+	for _, v := range validations {
+		var owners, evidence []string
+		for _, owner := range v.Owners {
+			owners = append(owners, owner.ID)
+		}
+		for _, tag := range v.Evidence {
+			evidence = append(evidence, fmt.Sprintf("%s: %s - %s", tag.CodeFile.RepoName, tag.CodeFile.Path, tag.Tag))
+		}
+		records = append(records, []string{v.Assumption.ID + " " + v.Assumption.Title, strings.Join(owners, "\n"), v.Plan, strings.Join(evidence, "\n")})
+	}
+	return records
+}
+
+// writeRecords writes spreadsheet rows in the given format, CSV or an Excel workbook with a single sheet of the given
+// name. The CSV lines end with CRLF as expected by spreadsheets.
+// @llr REQ-TRAQ-SWL-187
+func writeRecords(w io.Writer, format, sheet string, records [][]string) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.UseCRLF = true
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatXLSX:
+		return writeXLSX(w, sheet, records)
+	}
+	return CheckFormat(format)
+}
+
+// The parts of a minimal Excel workbook with a single sheet, whose cells hold inline strings
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+)
+
+// xlsxColumn returns the name of a column of a sheet from its zero-based index, e.g. `AB` for 27
+// @llr REQ-TRAQ-SWL-187
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// xmlEscape returns the text escaped to be written in XML, the characters which are not allowed being replaced
+// @llr REQ-TRAQ-SWL-187
+func xmlEscape(text string) string {
+	var buf bytes.Buffer
+	// Only fails if writing to the buffer fails
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// writeXLSX writes the rows as an Excel workbook with a single sheet of the given name. The files of the archive
+// have no modification time, so the same rows always give the same workbook.
+// @llr REQ-TRAQ-SWL-187
+func writeXLSX(w io.Writer, sheet string, records [][]string) error {
+	var rows strings.Builder
+	for i, record := range records {
+		fmt.Fprintf(&rows, `<row r="%d">`, i+1)
+		for j, value := range record {
+			fmt.Fprintf(&rows, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, xlsxColumn(j), i+1, xmlEscape(value))
+		}
+		rows.WriteString("</row>")
+	}
+	worksheet := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + rows.String() + `</sheetData></worksheet>`
+
+	archive := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheet))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", worksheet},
+	} {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,58 @@
+package matrix
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-187
+func TestMatrix_WriteRecords(t *testing.T) {
+	parent := &TableCell{Name: "REQ-TEST-SWH-1"}
+	child := &TableCell{Name: "REQ-TEST-SWL-1", Rationale: "Splits the parsing"}
+	data := traceTables{
+		From:    "REQ-TEST-SWH",
+		To:      "REQ-TEST-SWL",
+		ItemsAB: []TableRow{{parent, child}, {&TableCell{Name: "REQ-TEST-SWH-2"}, nil}},
+		ItemsBA: []TableRow{{child, parent}, {&TableCell{Name: "REQ-TEST-SWL-2"}, nil}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeTraceTables(&buf, FormatCSV, data))
+	assert.Equal(t, "Direction,REQ-TEST-SWH,REQ-TEST-SWL,Rationale\r\n"+
+		"Downstream,REQ-TEST-SWH-1,REQ-TEST-SWL-1,Splits the parsing\r\n"+
+		"Downstream,REQ-TEST-SWH-2,,\r\n"+
+		"Upstream,REQ-TEST-SWH-1,REQ-TEST-SWL-1,Splits the parsing\r\n"+
+		"Upstream,,REQ-TEST-SWL-2,\r\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, writeRecords(&buf, FormatXLSX, "Trace matrix", [][]string{{"Name", "Value"}, {"a < b", "x"}}))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		parts[f.Name] = string(content)
+	}
+	assert.Len(t, parts, 5)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Trace matrix" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<row r="2"><c r="A2" t="inlineStr"><is><t xml:space="preserve">a &lt; b</t></is></c>`+
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">x</t></is></c></row>`)
+
+	// The same rows give the same workbook
+	var again bytes.Buffer
+	assert.NoError(t, writeRecords(&again, FormatXLSX, "Trace matrix", [][]string{{"Name", "Value"}, {"a < b", "x"}}))
+	assert.Equal(t, buf.Bytes(), again.Bytes())
+
+	assert.Equal(t, "A", xlsxColumn(0))
+	assert.Equal(t, "Z", xlsxColumn(25))
+	assert.Equal(t, "AB", xlsxColumn(27))
+	assert.NoError(t, CheckFormat(FormatXLSX))
+	assert.EqualError(t, CheckFormat("pdf"), "Unknown matrix format `pdf`, expected one of html, csv or xlsx")
+}
@@ -0,0 +1,140 @@
+/*
+Measures the time spent in the stages of building the requirements graph, to find out what makes the
+build slow on large projects.
+*/
+
+package profile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Whether the stages are measured. When disabled, measuring a stage costs nothing.
+var Enabled = false
+
+// The number of slowest measurements listed by the report
+const slowestCount = 10
+
+// A Measurement is the time spent in a stage, for a repository and an optional detail such as the
+// path of the document being processed
+type Measurement struct {
+	Stage    string
+	Repo     string
+	Detail   string
+	Duration time.Duration
+}
+
+var (
+	measurementsMutex sync.Mutex
+	measurements      []Measurement
+)
+
+// Start starts measuring a stage and returns the function to call when the stage is finished. It is
+// meant to be used as `defer profile.Start(stage, repo, detail)()`. Stages can run concurrently.
+// @llr REQ-TRAQ-SWL-108
+func Start(stage, repo, detail string) func() {
+	if !Enabled {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		measurement := Measurement{Stage: stage, Repo: repo, Detail: detail, Duration: time.Since(start)}
+		measurementsMutex.Lock()
+		defer measurementsMutex.Unlock()
+		measurements = append(measurements, measurement)
+	}
+}
+
+// Measurements returns the measurements recorded so far, in the order the stages finished
+// @llr REQ-TRAQ-SWL-108
+func Measurements() []Measurement {
+	measurementsMutex.Lock()
+	defer measurementsMutex.Unlock()
+	return append([]Measurement{}, measurements...)
+}
+
+// Reset forgets the measurements recorded so far
+// @llr REQ-TRAQ-SWL-108
+func Reset() {
+	measurementsMutex.Lock()
+	defer measurementsMutex.Unlock()
+	measurements = nil
+}
+
+// A total of the measurements of a stage or of a repository
+type total struct {
+	name     string
+	count    int
+	duration time.Duration
+}
+
+// totals sums the durations of the measurements by the given key, slowest first
+// @llr REQ-TRAQ-SWL-108
+func totals(measurements []Measurement, key func(Measurement) string) []total {
+	byKey := map[string]*total{}
+	for _, measurement := range measurements {
+		name := key(measurement)
+		if _, ok := byKey[name]; !ok {
+			byKey[name] = &total{name: name}
+		}
+		byKey[name].count++
+		byKey[name].duration += measurement.Duration
+	}
+	result := make([]total, 0, len(byKey))
+	for _, t := range byKey {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].duration != result[j].duration {
+			return result[i].duration > result[j].duration
+		}
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// WriteReport writes the time spent per stage and per repository, followed by the slowest measurements.
+// The stages run concurrently and the configuration parsing includes the cloning of the repositories, so the
+// totals can add up to more than the elapsed time.
+// @llr REQ-TRAQ-SWL-108
+func WriteReport(w io.Writer, elapsed time.Duration) error {
+	all := Measurements()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Profile (elapsed %s)\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "\nSTAGE\tCOUNT\tTIME\n")
+	for _, t := range totals(all, func(m Measurement) string { return m.Stage }) {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", t.name, t.count, t.duration.Round(time.Millisecond))
+	}
+
+	fmt.Fprintf(tw, "\nREPOSITORY\tCOUNT\tTIME\n")
+	for _, t := range totals(all, func(m Measurement) string { return m.Repo }) {
+		if t.name == "" {
+			t.name = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", t.name, t.count, t.duration.Round(time.Millisecond))
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Duration > all[j].Duration })
+	if len(all) > slowestCount {
+		all = all[:slowestCount]
+	}
+	fmt.Fprintf(tw, "\nSLOWEST\tREPOSITORY\tTIME\n")
+	for _, m := range all {
+		name := m.Stage
+		if m.Detail != "" {
+			name += " " + m.Detail
+		}
+		repo := m.Repo
+		if repo == "" {
+			repo = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, repo, m.Duration.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
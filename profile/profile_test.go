@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-108
+func TestStart(t *testing.T) {
+	Reset()
+	Enabled = false
+	Start("resolve", "", "")()
+	assert.Empty(t, Measurements())
+
+	Enabled = true
+	defer func() { Enabled = false }()
+	Start("markdown parse", "repo", "TEST-137-SRD.md")()
+	measurements := Measurements()
+	assert.Len(t, measurements, 1)
+	assert.Equal(t, "markdown parse", measurements[0].Stage)
+	assert.Equal(t, "repo", measurements[0].Repo)
+	assert.Equal(t, "TEST-137-SRD.md", measurements[0].Detail)
+
+	Reset()
+	assert.Empty(t, Measurements())
+}
+
+// @llr REQ-TRAQ-SWL-108
+func TestWriteReport(t *testing.T) {
+	Reset()
+	defer Reset()
+	measurements = []Measurement{
+		{Stage: "markdown parse", Repo: "repo", Detail: "TEST-137-SRD.md", Duration: 2 * time.Millisecond},
+		{Stage: "markdown parse", Repo: "other", Detail: "TEST-138-SDD.md", Duration: 3 * time.Millisecond},
+		{Stage: "code tagging (ctags)", Repo: "repo", Detail: "TEST-138-SDD.md", Duration: 20 * time.Millisecond},
+		{Stage: "resolve", Duration: time.Millisecond},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteReport(&out, 30*time.Millisecond))
+	assert.Equal(t, `Profile (elapsed 30ms)
+
+STAGE                 COUNT  TIME
+code tagging (ctags)  1      20ms
+markdown parse        2      5ms
+resolve               1      1ms
+
+REPOSITORY  COUNT  TIME
+repo        2      22ms
+other       1      3ms
+-           1      1ms
+
+SLOWEST                               REPOSITORY  TIME
+code tagging (ctags) TEST-138-SDD.md  repo        20ms
+markdown parse TEST-138-SDD.md        other       3ms
+markdown parse TEST-137-SRD.md        repo        2ms
+resolve                               -           1ms
+`, out.String())
+}
@@ -0,0 +1,69 @@
+/*
+Reports the progress of building the requirements graph as newline-delimited JSON events, so that the editors
+running a long validation can show a progress bar.
+*/
+
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Writer receives the progress events, one JSON object per line. When nil, reporting progress costs nothing.
+var Writer io.Writer
+
+// An Event is the stage being run, the percentage of the work done so far and the document being processed, if any
+type Event struct {
+	Stage    string `json:"stage"`
+	Percent  int    `json:"percent"`
+	Document string `json:"document,omitempty"`
+}
+
+var (
+	writerMutex sync.Mutex
+	lastPercent int
+)
+
+// Report writes a progress event. The percentage never decreases, so events reported out of order by concurrent
+// stages keep the progress bar moving forward. Errors writing the event are ignored.
+// @llr REQ-TRAQ-SWL-189
+func Report(stage string, percent int, document string) {
+	if Writer == nil {
+		return
+	}
+	writerMutex.Lock()
+	defer writerMutex.Unlock()
+	if percent < lastPercent {
+		percent = lastPercent
+	}
+	lastPercent = percent
+	_ = json.NewEncoder(Writer).Encode(Event{Stage: stage, Percent: percent, Document: document})
+}
+
+// Steps returns the function to call when each of the given count of steps of a stage is done, e.g. when a document
+// is parsed, which reports the progress from the first to the last given percentage. Steps can be done concurrently.
+// @llr REQ-TRAQ-SWL-189
+func Steps(stage string, from, to, count int) func(document string) {
+	var mutex sync.Mutex
+	done := 0
+	return func(document string) {
+		if Writer == nil {
+			return
+		}
+		mutex.Lock()
+		done++
+		percent := from + (to-from)*done/count
+		mutex.Unlock()
+		Report(stage, percent, document)
+	}
+}
+
+// Reset starts reporting the progress of a new build from zero
+// @llr REQ-TRAQ-SWL-189
+func Reset() {
+	writerMutex.Lock()
+	defer writerMutex.Unlock()
+	lastPercent = 0
+}
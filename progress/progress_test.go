@@ -0,0 +1,48 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-189
+func TestReport(t *testing.T) {
+	Reset()
+	Writer = nil
+	Report("resolve", 85, "")
+
+	var out bytes.Buffer
+	Writer = &out
+	defer func() { Writer = nil }()
+	Report("parse", 40, "certdocs/TEST-137-SRD.md")
+	Report("resolve", 85, "")
+	// The percentage never decreases
+	Report("code reviews", 50, "")
+	assert.Equal(t, `{"stage":"parse","percent":40,"document":"certdocs/TEST-137-SRD.md"}
+{"stage":"resolve","percent":85}
+{"stage":"code reviews","percent":85}
+`, out.String())
+
+	Reset()
+	out.Reset()
+	Report("parse", 10, "")
+	assert.Equal(t, "{\"stage\":\"parse\",\"percent\":10}\n", out.String())
+}
+
+// @llr REQ-TRAQ-SWL-189
+func TestSteps(t *testing.T) {
+	Reset()
+	var out bytes.Buffer
+	Writer = &out
+	defer func() { Writer = nil }()
+	documentParsed := Steps("parse", 0, 80, 3)
+	documentParsed("a.md")
+	documentParsed("b.md")
+	documentParsed("c.md")
+	assert.Equal(t, `{"stage":"parse","percent":26,"document":"a.md"}
+{"stage":"parse","percent":53,"document":"b.md"}
+{"stage":"parse","percent":80,"document":"c.md"}
+`, out.String())
+}
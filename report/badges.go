@@ -0,0 +1,147 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// Stats holds the traceability figures shown in the badges
+type Stats struct {
+	// Requirements is the number of requirements which are not deleted
+	Requirements int
+	// Traced is the number of requirements with children requirements or, for documents with
+	// implementation, with implementation code
+	Traced int
+	// Testable is the number of requirements in documents with implementation
+	Testable int
+	// Tested is the number of testable requirements with test code
+	Tested int
+	// Issues is the number of issues found in the graph
+	Issues int
+}
+
+// Badge is a small SVG image showing a label and a value, meant to be embedded in dashboards
+type Badge struct {
+	// Name identifies the badge and is used to name its file
+	Name  string
+	Label string
+	Value string
+	Color string
+}
+
+const (
+	badgeColorGood    = "#4c1"
+	badgeColorWarning = "#dfb317"
+	badgeColorBad     = "#e05d44"
+)
+
+// ComputeStats counts the traced and tested requirements and the issues of the requirements graph.
+// @llr REQ-TRAQ-SWL-97
+func ComputeStats(rg *reqs.ReqGraph) Stats {
+	stats := Stats{Issues: len(rg.Issues)}
+	for _, r := range rg.Reqs {
+		if r.IsDeleted() {
+			continue
+		}
+		stats.Requirements++
+
+		implemented, tested := false, false
+		for _, tag := range r.Tags {
+			implemented = implemented || tag.CodeFile.Type.Matches(code.CodeTypeImplementation)
+			tested = tested || tag.CodeFile.Type.Matches(code.CodeTypeTests)
+		}
+
+		if r.Document != nil && r.Document.HasImplementation() {
+			stats.Testable++
+			if implemented {
+				stats.Traced++
+			}
+			if tested {
+				stats.Tested++
+			}
+			continue
+		}
+
+		for _, child := range r.Children {
+			if !child.IsDeleted() {
+				stats.Traced++
+				break
+			}
+		}
+	}
+	return stats
+}
+
+// percentage returns the integer percentage of part in total, 100 if there is nothing to count
+// @llr REQ-TRAQ-SWL-97
+func percentage(part, total int) int {
+	if total == 0 {
+		return 100
+	}
+	return part * 100 / total
+}
+
+// percentageColor returns the badge color for the given percentage
+// @llr REQ-TRAQ-SWL-97
+func percentageColor(percent int) string {
+	switch {
+	case percent >= 90:
+		return badgeColorGood
+	case percent >= 60:
+		return badgeColorWarning
+	}
+	return badgeColorBad
+}
+
+// Badges returns the badges for the given statistics: traced requirements, requirements with tests and
+// issues count.
+// @llr REQ-TRAQ-SWL-97
+func Badges(stats Stats) []Badge {
+	traced := percentage(stats.Traced, stats.Requirements)
+	tested := percentage(stats.Tested, stats.Testable)
+	issuesColor := badgeColorGood
+	if stats.Issues > 0 {
+		issuesColor = badgeColorBad
+	}
+
+	return []Badge{
+		{Name: "traced", Label: "requirements traced", Value: fmt.Sprintf("%d%%", traced), Color: percentageColor(traced)},
+		{Name: "tested", Label: "tests linked", Value: fmt.Sprintf("%d%%", tested), Color: percentageColor(tested)},
+		{Name: "issues", Label: "open issues", Value: fmt.Sprintf("%d", stats.Issues), Color: issuesColor},
+	}
+}
+
+// badgeTextWidth approximates the width in pixels of the text rendered in a badge
+// @llr REQ-TRAQ-SWL-97
+func badgeTextWidth(text string) int {
+	return len(text)*7 + 10
+}
+
+// Write renders the badge as an SVG image.
+// @llr REQ-TRAQ-SWL-97
+func (b Badge) Write(w io.Writer) error {
+	labelWidth := badgeTextWidth(b.Label)
+	valueWidth := badgeTextWidth(b.Value)
+	return badgeTmpl.Execute(w, struct {
+		Badge
+		LabelWidth, ValueWidth, Width int
+	}{b, labelWidth, valueWidth, labelWidth + valueWidth})
+}
+
+var badgeTmpl = template.Must(template.New("badge").Funcs(template.FuncMap{
+	"half": func(n int) int { return n / 2 },
+	"add":  func(a, b int) int { return a + b },
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Value}}">
+<title>{{.Label}}: {{.Value}}</title>
+<rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+<rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Color}}"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{half .LabelWidth}}" y="14">{{.Label}}</text>
+<text x="{{add .LabelWidth (half .ValueWidth)}}" y="14">{{.Value}}</text>
+</g>
+</svg>
+`))
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-97
+func TestBadges_ComputeStats(t *testing.T) {
+	hlDoc := config.Document{}
+	llDoc := config.Document{Implementation: []config.Implementation{{ArchImplementation: config.ArchImplementation{CodeFiles: []string{"a.go"}}}}}
+
+	swl1 := &reqs.Req{ID: "REQ-TEST-SWL-1", Document: &llDoc, Tags: []*code.Code{
+		{CodeFile: code.CodeFile{Type: code.CodeTypeImplementation}},
+		{CodeFile: code.CodeFile{Type: code.CodeTypeTests}},
+	}}
+	swl2 := &reqs.Req{ID: "REQ-TEST-SWL-2", Document: &llDoc, Tags: []*code.Code{
+		{CodeFile: code.CodeFile{Type: code.CodeTypeImplementation}},
+	}}
+	swl3 := &reqs.Req{ID: "REQ-TEST-SWL-3", Document: &llDoc}
+	deleted := &reqs.Req{ID: "REQ-TEST-SWL-4", Document: &llDoc, Title: "DELETED"}
+	swh1 := &reqs.Req{ID: "REQ-TEST-SWH-1", Document: &hlDoc, Children: []*reqs.Req{swl1, swl2}}
+	swh2 := &reqs.Req{ID: "REQ-TEST-SWH-2", Document: &hlDoc, Children: []*reqs.Req{deleted}}
+
+	rg := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{
+			swl1.ID: swl1, swl2.ID: swl2, swl3.ID: swl3, deleted.ID: deleted, swh1.ID: swh1, swh2.ID: swh2,
+		},
+		Issues: []diagnostics.Issue{{Description: "issue"}},
+	}
+
+	stats := ComputeStats(rg)
+	assert.Equal(t, Stats{Requirements: 5, Traced: 3, Testable: 3, Tested: 1, Issues: 1}, stats)
+
+	badges := Badges(stats)
+	assert.Equal(t, []Badge{
+		{Name: "traced", Label: "requirements traced", Value: "60%", Color: badgeColorWarning},
+		{Name: "tested", Label: "tests linked", Value: "33%", Color: badgeColorBad},
+		{Name: "issues", Label: "open issues", Value: "1", Color: badgeColorBad},
+	}, badges)
+
+	// An empty graph is fully traced
+	assert.Equal(t, "100%", Badges(Stats{})[0].Value)
+	assert.Equal(t, badgeColorGood, Badges(Stats{})[2].Color)
+}
+
+// @llr REQ-TRAQ-SWL-97
+func TestBadges_Write(t *testing.T) {
+	var buf bytes.Buffer
+	badge := Badge{Name: "traced", Label: "requirements traced", Value: "60%", Color: badgeColorWarning}
+	assert.NoError(t, badge.Write(&buf))
+
+	svg := buf.String()
+	assert.Contains(t, svg, `<svg xmlns="http://www.w3.org/2000/svg" width="174" height="20"`)
+	assert.Contains(t, svg, `<title>requirements traced: 60%</title>`)
+	assert.Contains(t, svg, `fill="#dfb317"`)
+	assert.Contains(t, svg, `<text x="158" y="14">60%</text>`)
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// CodeView selects the code listed in the bottom-up reports and how it is listed
+type CodeView struct {
+	// Only the code of this repository is listed, the code of all the repositories when empty
+	RepoName repos.RepoName
+	// Only the code files whose path, or the path of one of their directories, matches this pattern are listed, see
+	// path.Match for the syntax. All the files are listed when empty.
+	PathGlob string
+	// The type of code listed
+	Type code.CodeType
+	// Whether the functions of a file are listed together, with the requirements of all of them
+	CollapseFiles bool
+}
+
+// AllCode lists all the code in the bottom-up reports, one function at a time
+var AllCode = CodeView{Type: code.CodeTypeAny}
+
+// Matches returns whether the code file is listed
+// @llr REQ-TRAQ-SWL-160
+func (v CodeView) Matches(codeFile code.CodeFile) bool {
+	if v.RepoName != "" && codeFile.RepoName != v.RepoName {
+		return false
+	}
+	if !codeFile.Type.Matches(v.Type) {
+		return false
+	}
+	if v.PathGlob == "" {
+		return true
+	}
+	for p := codeFile.Path; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if matched, _ := path.Match(v.PathGlob, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// bottomUpEntry is a function, or a file with all its functions when collapsed, listed in the bottom-up reports
+type bottomUpEntry struct {
+	CodeFile code.CodeFile
+	// The names of the functions
+	Tags []string
+	// The link to the function, or to the first function of the file when collapsed
+	URL string
+	// The requirements the functions link to, without duplicates
+	Parents []*reqs.Req
+}
+
+// Names returns the names of the functions of the entry
+// @llr REQ-TRAQ-SWL-160
+func (e bottomUpEntry) Names() string {
+	return strings.Join(e.Tags, ", ")
+}
+
+// BottomUpEntries returns the code listed in the bottom-up reports, ordered by repository, with the entries of the
+// files collapsed when requested. Optional code which is not linked to any requirement is not listed.
+// @llr REQ-TRAQ-SWL-13, REQ-TRAQ-SWL-160
+func (report reportData) BottomUpEntries() []*bottomUpEntry {
+	repoNames := make([]repos.RepoName, 0, len(report.Reqs.CodeTags))
+	for repoName := range report.Reqs.CodeTags {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Slice(repoNames, func(i, j int) bool { return repoNames[i] < repoNames[j] })
+
+	var entries []*bottomUpEntry
+	files := make(map[code.CodeFile]*bottomUpEntry)
+	for _, repoName := range repoNames {
+		for _, tag := range report.Reqs.CodeTags[repoName] {
+			if !shouldShowTag(tag, report.Reqs) || !report.Code.Matches(tag.CodeFile) {
+				continue
+			}
+			entry, ok := files[tag.CodeFile]
+			if !ok || !report.Code.CollapseFiles {
+				entry = &bottomUpEntry{CodeFile: tag.CodeFile, URL: tag.URL()}
+				files[tag.CodeFile] = entry
+				entries = append(entries, entry)
+			}
+			entry.Tags = append(entry.Tags, tag.Tag)
+			for _, parent := range listCodeParents(tag.Links, report.Reqs) {
+				if !containsReq(entry.Parents, parent) {
+					entry.Parents = append(entry.Parents, parent)
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// containsReq returns whether the requirement is in the list
+// @llr REQ-TRAQ-SWL-160
+func containsReq(list []*reqs.Req, r *reqs.Req) bool {
+	for _, item := range list {
+		if item == r {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportUpCode generates a HTML report of bottom up trace information of the code selected by the view, filtered by
+// the supplied parameters unless nil.
+// @llr REQ-TRAQ-SWL-13, REQ-TRAQ-SWL-21, REQ-TRAQ-SWL-39, REQ-TRAQ-SWL-160
+func ReportUpCode(rg *reqs.ReqGraph, w io.Writer, f *reqs.ReqFilter, view CodeView) error {
+	data := newReportData(rg, f)
+	data.Code = view
+	if f == nil {
+		return executeReport(rg, w, "BOTTOMUP", data)
+	}
+	return executeReport(rg, w, "BOTTOMUPFILT", data)
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// csvAttributes returns the names of the attributes of the schema of the document, in the order of the columns of
+// the CSV export: the parents first, followed by the attributes in the order they are declared. The attributes whose
+// declaration order is unknown come last, sorted by name.
+// @llr REQ-TRAQ-SWL-132
+func csvAttributes(doc *config.Document) []string {
+	names := []string{}
+	if _, ok := doc.Schema.Attributes["PARENTS"]; ok {
+		names = append(names, "PARENTS")
+	}
+	listed := map[string]bool{"PARENTS": true}
+	for _, name := range doc.Schema.AttributeNames {
+		if _, ok := doc.Schema.Attributes[name]; ok && !listed[name] {
+			listed[name] = true
+			names = append(names, name)
+		}
+	}
+	var others []string
+	for name := range doc.Schema.Attributes {
+		if !listed[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// csvParents returns the value of the Parents column of a requirement
+// @llr REQ-TRAQ-SWL-132
+func csvParents(r *reqs.Req) string {
+	if r.Derived {
+		return reqs.DerivedParents
+	}
+	return strings.Join(r.ParentIds, ", ")
+}
+
+// WriteCSV writes the requirements of the given document which are not deleted as CSV, in the order they are
+// defined, one row per requirement with its ID, title, body and the attributes of the schema of the document.
+// Values containing separators, quotes or newlines are quoted, and the lines end with CRLF as expected by
+// spreadsheets.
+// @llr REQ-TRAQ-SWL-132
+func WriteCSV(w io.Writer, rg *reqs.ReqGraph, repoName repos.RepoName, doc *config.Document) error {
+	var requirements []*reqs.Req
+	for _, r := range rg.Reqs {
+		if r.RepoName == repoName && r.Document != nil && r.Document.Path == doc.Path &&
+			r.Variant == reqs.ReqVariantRequirement && !r.IsDeleted() {
+			requirements = append(requirements, r)
+		}
+	}
+	sort.Slice(requirements, func(i, j int) bool { return requirements[i].Position < requirements[j].Position })
+
+	attributes := csvAttributes(doc)
+	header := []string{"ID", "Title", "Body"}
+	for _, name := range attributes {
+		header = append(header, strings.Title(strings.ToLower(name)))
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, r := range requirements {
+		row := []string{r.ID, r.Title, strings.TrimSpace(r.Body)}
+		for _, name := range attributes {
+			if name == "PARENTS" {
+				row = append(row, csvParents(r))
+			} else {
+				row = append(row, r.Attributes[name])
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteForeignIDs writes the table mapping the IDs of the requirements to their foreign IDs as CSV, one row per
+// requirement with a foreign ID, ordered by requirement ID
+// @llr REQ-TRAQ-SWL-145
+func WriteForeignIDs(w io.Writer, rg *reqs.ReqGraph) error {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	if err := writer.Write([]string{"ID", "Foreign ID", "Repository", "Document"}); err != nil {
+		return err
+	}
+	for _, mapping := range rg.ForeignIDs() {
+		if err := writer.Write([]string{mapping.ID, mapping.ForeignID, string(mapping.RepoName), mapping.Document}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,63 @@
+package report
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-132
+func TestWriteCSV(t *testing.T) {
+	anyValue := regexp.MustCompile(".*")
+	sdd := config.Document{Path: "TEST-138-SDD.md", Schema: config.Schema{Attributes: map[string]*config.Attribute{
+		"RATIONALE":     {Type: config.AttributeAny, Value: anyValue},
+		"VERIFICATION":  {Type: config.AttributeRequired, Value: anyValue},
+		"SAFETY IMPACT": {Type: config.AttributeRequired, Value: anyValue},
+		"NOTE":          {Type: config.AttributeOptional, Value: anyValue},
+		"PARENTS":       {Type: config.AttributeAny, Value: anyValue},
+	}, AttributeNames: []string{"VERIFICATION", "SAFETY IMPACT", "RATIONALE", "NOTE"}}}
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	rotation := &reqs.Req{ID: "REQ-TEST-SWL-2", Variant: reqs.ReqVariantRequirement, Title: "Log rotation",
+		Body: "\nThe logs SHALL be rotated \"daily\",\nat midnight.\n\n", Document: &sdd, RepoName: "repo", Position: 9,
+		ParentIds:  []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2"},
+		Attributes: map[string]string{"VERIFICATION": "Test", "SAFETY IMPACT": "None", "RATIONALE": "Disk, space"}}
+	format := &reqs.Req{ID: "REQ-TEST-SWL-1", Variant: reqs.ReqVariantRequirement, Title: "Log format",
+		Body: "The logs SHALL be JSON.", Document: &sdd, RepoName: "repo", Position: 3, Derived: true,
+		Attributes: map[string]string{"VERIFICATION": "Review", "SAFETY IMPACT": "None", "NOTE": "Derived"}}
+	deleted := &reqs.Req{ID: "REQ-TEST-SWL-3", Variant: reqs.ReqVariantRequirement, Title: "DELETED", Document: &sdd,
+		RepoName: "repo", Position: 15}
+	assumption := &reqs.Req{ID: "ASM-TEST-SWL-1", Variant: reqs.ReqVariantAssumption, Title: "Disk",
+		Document: &sdd, RepoName: "repo", Position: 20}
+	other := &reqs.Req{ID: "REQ-TEST-SWH-1", Variant: reqs.ReqVariantRequirement, Title: "Logging", Document: &srd,
+		RepoName: "repo", Position: 3}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{}}
+	for _, r := range []*reqs.Req{rotation, format, deleted, assumption, other} {
+		rg.Reqs[r.ID] = r
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteCSV(&out, rg, "repo", &sdd))
+	assert.Equal(t, "ID,Title,Body,Parents,Verification,Safety Impact,Rationale,Note\r\n"+
+		"REQ-TEST-SWL-1,Log format,The logs SHALL be JSON.,-,Review,None,,Derived\r\n"+
+		"REQ-TEST-SWL-2,Log rotation,\"The logs SHALL be rotated \"\"daily\"\",\r\nat midnight.\",\"REQ-TEST-SWH-1, REQ-TEST-SWH-2\",Test,None,\"Disk, space\",\r\n",
+		out.String())
+}
+
+// @llr REQ-TRAQ-SWL-145
+func TestWriteForeignIDs(t *testing.T) {
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Title: "Log format", Document: &sdd, RepoName: "repo",
+			Attributes: map[string]string{reqs.ForeignIDAttribute: "CUST-7"}},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Title: "Log rotation", Document: &sdd, RepoName: "repo",
+			Attributes: map[string]string{}},
+	}}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteForeignIDs(&out, rg))
+	assert.Equal(t, "ID,Foreign ID,Repository,Document\r\nREQ-TEST-SWL-1,CUST-7,repo,TEST-138-SDD.md\r\n", out.String())
+}
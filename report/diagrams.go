@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// The extensions of the diagram files embedded as images, and of the text diagrams shown as their source
+var imageDiagramExtensions = map[string]bool{".svg": true, ".png": true, ".jpg": true, ".jpeg": true, ".gif": true}
+var textDiagramExtensions = map[string]bool{".puml": true, ".plantuml": true, ".pu": true, ".iuml": true, ".mmd": true, ".dot": true}
+
+// flowDiagram is a diagram file shown in the flow section of the report, with the flow tags referencing it
+type flowDiagram struct {
+	Anchor   string
+	RepoName repos.RepoName
+	Path     string
+	Flows    []string
+	Content  template.HTML
+}
+
+// flowDiagrams returns the diagrams referenced by the given flow tags, once each, in the order they are first
+// referenced
+// @llr REQ-TRAQ-SWL-185
+func flowDiagrams(flows []*reqs.Flow) []*flowDiagram {
+	var diagrams []*flowDiagram
+	byLocation := make(map[string]*flowDiagram)
+	for _, f := range flows {
+		diagramPath := f.DiagramPath()
+		if diagramPath == "" {
+			continue
+		}
+		location := fmt.Sprintf("%s/%s", f.RepoName, diagramPath)
+		diagram, ok := byLocation[location]
+		if !ok {
+			diagram = &flowDiagram{
+				Anchor:   fmt.Sprintf("diagram-%d", len(diagrams)+1),
+				RepoName: f.RepoName,
+				Path:     diagramPath,
+				Content:  diagramContent(f.RepoName, diagramPath),
+			}
+			byLocation[location] = diagram
+			diagrams = append(diagrams, diagram)
+		}
+		diagram.Flows = append(diagram.Flows, f.ID)
+	}
+	return diagrams
+}
+
+// flowDiagramAnchor returns the anchor of the diagram of a flow tag in the report, empty if it has none
+// @llr REQ-TRAQ-SWL-185
+func flowDiagramAnchor(diagrams []*flowDiagram, f *reqs.Flow) string {
+	for _, diagram := range diagrams {
+		if diagram.RepoName == f.RepoName && diagram.Path == f.DiagramPath() {
+			return diagram.Anchor
+		}
+	}
+	return ""
+}
+
+// diagramContent returns the diagram embedded in the report: the images are inlined, the text diagrams such as
+// PlantUML are shown as their source and the other diagrams are linked. Nothing is returned if the file cannot be read.
+// @llr REQ-TRAQ-SWL-185
+func diagramContent(repoName repos.RepoName, diagramPath string) template.HTML {
+	filePath, err := repos.PathInRepo(repoName, diagramPath)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(diagramPath))
+	if !imageDiagramExtensions[ext] && !textDiagramExtensions[ext] {
+		url := template.HTMLEscapeString(fmt.Sprintf("/code/%s/%s", repoName, diagramPath))
+		return template.HTML(fmt.Sprintf(`<a href="%s" target="_blank">Open the diagram</a>`, url))
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+	if textDiagramExtensions[ext] {
+		return template.HTML(`<pre class="flow-diagram">` + template.HTMLEscapeString(string(content)) + "</pre>")
+	}
+	return template.HTML(fmt.Sprintf(`<img class="flow-diagram" src="data:%s;base64,%s">`, mime.TypeByExtension(ext), base64.StdEncoding.EncodeToString(content)))
+}
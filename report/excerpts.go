@@ -0,0 +1,211 @@
+package report
+
+import (
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// CodeExcerpts is whether the code tags listed by the reports are shown with an excerpt of their source: the comment
+// block with the references to the requirements and the signature of the function
+var CodeExcerpts bool
+
+// The maximum number of comment lines shown above the signature of a function
+const maxExcerptCommentLines = 20
+
+// The style of the excerpts, the same as the code shown by the web interface
+var excerptStyle = styles.Get("vs")
+
+// sourceSpan is a part of a line of code, from the start to the end byte, shown with the given CSS style
+type sourceSpan struct {
+	start, end int
+	css        string
+}
+
+// sourceFile holds the lines of a code file and the highlighted spans of each line
+type sourceFile struct {
+	lines []string
+	spans [][]sourceSpan
+}
+
+// codeSources reads and highlights the code files the excerpts are taken from. The files are read once for all the
+// reports of a graph, the sources being created along with the templates of the graph.
+type codeSources struct {
+	mutex sync.Mutex
+	// The code files read so far, nil for the files which cannot be read
+	files map[code.CodeFile]*sourceFile
+}
+
+// newCodeSources returns the sources of the code excerpts of the reports of a graph, with no file read yet
+// @llr REQ-TRAQ-SWL-181
+func newCodeSources() *codeSources {
+	return &codeSources{files: make(map[code.CodeFile]*sourceFile)}
+}
+
+// read returns the lines of a code file and their highlighted spans, reading each file once
+// @llr REQ-TRAQ-SWL-181
+func (sources *codeSources) read(codeFile code.CodeFile) *sourceFile {
+	sources.mutex.Lock()
+	defer sources.mutex.Unlock()
+	if file, ok := sources.files[codeFile]; ok {
+		return file
+	}
+	var file *sourceFile
+	if path, err := repos.PathInRepo(codeFile.RepoName, codeFile.Path); err == nil {
+		if content, err := os.ReadFile(path); err == nil {
+			file = highlightSource(codeFile.Path, strings.ReplaceAll(string(content), "\r\n", "\n"))
+		}
+	}
+	sources.files[codeFile] = file
+	return file
+}
+
+// highlightSource splits the source of a code file into lines, highlighted with the lexer of the language of the file
+// if any. The lines are left plain if the source cannot be tokenised.
+// @llr REQ-TRAQ-SWL-181
+func highlightSource(path, source string) *sourceFile {
+	file := &sourceFile{lines: strings.Split(source, "\n")}
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		return file
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, source)
+	if err != nil {
+		return file
+	}
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		var spans []sourceSpan
+		start := 0
+		for _, token := range tokens {
+			end := start + len(strings.TrimSuffix(token.Value, "\n"))
+			entry := excerptStyle.Get(token.Type)
+			// The background of the report is kept
+			entry.Background, entry.Border = 0, 0
+			if css := html.StyleEntryToCSS(entry); css != "" && end > start {
+				spans = append(spans, sourceSpan{start: start, end: end, css: css})
+			}
+			start = end
+		}
+		file.spans = append(file.spans, spans)
+	}
+	return file
+}
+
+// highlightLine returns the HTML of a line of code, with the highlighted spans styled and the references to the
+// requirements linking to the requirements in the report
+// @llr REQ-TRAQ-SWL-181
+func highlightLine(line string, spans []sourceSpan, links []code.ReqLink) string {
+	sort.Slice(links, func(i, j int) bool { return links[i].Range.Start.Character < links[j].Range.Start.Character })
+	var valid []code.ReqLink
+	last := 0
+	for _, link := range links {
+		start, end := int(link.Range.Start.Character), int(link.Range.End.Character)
+		if start < last || end > len(line) || start >= end {
+			continue
+		}
+		valid = append(valid, link)
+		last = end
+	}
+
+	// The line is cut at the bounds of the spans and of the links, each part having a single style and link
+	cuts := []int{0, len(line)}
+	for _, span := range spans {
+		cuts = append(cuts, span.start, span.end)
+	}
+	for _, link := range valid {
+		cuts = append(cuts, int(link.Range.Start.Character), int(link.Range.End.Character))
+	}
+	sort.Ints(cuts)
+
+	var out strings.Builder
+	for i := 0; i+1 < len(cuts); i++ {
+		start, end := cuts[i], cuts[i+1]
+		if start == end || end > len(line) {
+			continue
+		}
+		for _, link := range valid {
+			if int(link.Range.Start.Character) == start {
+				out.WriteString(`<a href="#` + template.HTMLEscapeString(link.Id) + `">`)
+			}
+		}
+		text := template.HTMLEscapeString(line[start:end])
+		for _, span := range spans {
+			if span.start <= start && end <= span.end {
+				text = `<span style="` + template.HTMLEscapeString(span.css) + `">` + text + "</span>"
+				break
+			}
+		}
+		out.WriteString(text)
+		for _, link := range valid {
+			if int(link.Range.End.Character) == end {
+				out.WriteString("</a>")
+			}
+		}
+	}
+	return out.String()
+}
+
+// codeExcerpt returns the excerpt of the source of a code tag, made of the comment block above the function, with the
+// references to the requirements linking to them, and the signature of the function, highlighted according to the
+// language of the file. Nothing is returned when the excerpts are disabled or the file cannot be read.
+// @llr REQ-TRAQ-SWL-181
+func (sources *codeSources) codeExcerpt(tag *code.Code) template.HTML {
+	if !CodeExcerpts {
+		return ""
+	}
+	file := sources.read(tag.CodeFile)
+	if file == nil {
+		return ""
+	}
+	lines := file.lines
+	// The line of the tag is one-based, the ranges of the links zero-based
+	signature := tag.Line - 1
+	if signature < 0 || signature >= len(lines) {
+		return ""
+	}
+	first, last := signature, signature
+	for first > 0 && signature-first < maxExcerptCommentLines && strings.TrimSpace(lines[first-1]) != "" {
+		first--
+	}
+	linksByLine := make(map[int][]code.ReqLink)
+	for _, link := range tag.Links {
+		line := int(link.Range.Start.Line)
+		if line >= len(lines) {
+			continue
+		}
+		// The references of the tags of whole files may be found below the line of the tag
+		if line < first {
+			first = line
+		}
+		if line > last {
+			last = line
+		}
+		linksByLine[line] = append(linksByLine[line], link)
+	}
+
+	var out strings.Builder
+	out.WriteString(`<pre class="code-excerpt">`)
+	for i := first; i <= last; i++ {
+		var spans []sourceSpan
+		if i < len(file.spans) {
+			spans = file.spans[i]
+		}
+		line := highlightLine(lines[i], spans, linksByLine[i])
+		if i == signature {
+			out.WriteString(`<span class="code-signature">` + line + "</span>\n")
+		} else {
+			out.WriteString(line + "\n")
+		}
+	}
+	out.WriteString("</pre>")
+	return template.HTML(out.String())
+}
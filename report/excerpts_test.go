@@ -0,0 +1,62 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-181
+func TestCodeExcerpt(t *testing.T) {
+	dir := t.TempDir()
+	source := "package a\n\n// Checks the <input>\n// @llr REQ-TEST-SWL-1, REQ-TEST-SWL-2\nfunc Check(a int) bool {\n\treturn a > 0\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte(source), 0644))
+	repos.ClearAllRepositories()
+	repos.RegisterRepository("excerpts", repos.RepoPath(dir))
+	t.Cleanup(repos.ClearAllRepositories)
+
+	tag := &code.Code{
+		CodeFile: code.CodeFile{RepoName: "excerpts", Path: "a.go"},
+		Tag:      "Check",
+		Line:     5,
+		Links: []code.ReqLink{
+			{Id: "REQ-TEST-SWL-1", Range: code.Range{Start: code.Position{Line: 3, Character: 8}, End: code.Position{Line: 3, Character: 22}}},
+			{Id: "REQ-TEST-SWL-2", Range: code.Range{Start: code.Position{Line: 3, Character: 24}, End: code.Position{Line: 3, Character: 38}}},
+		},
+	}
+
+	// Disabled by default
+	sources := newCodeSources()
+	assert.Empty(t, sources.codeExcerpt(tag))
+
+	CodeExcerpts = true
+	t.Cleanup(func() { CodeExcerpts = false })
+	// The code is highlighted according to the language of the file
+	excerpt := `<pre class="code-excerpt">` +
+		`<span style="color: #008000">// Checks the &lt;input&gt;</span>` + "\n" +
+		`<span style="color: #008000">// @llr </span><a href="#REQ-TEST-SWL-1"><span style="color: #008000">REQ-TEST-SWL-1</span></a>` +
+		`<span style="color: #008000">, </span><a href="#REQ-TEST-SWL-2"><span style="color: #008000">REQ-TEST-SWL-2</span></a>` + "\n" +
+		`<span class="code-signature"><span style="color: #0000ff">func</span> Check(a <span style="color: #2b91af">int</span>) ` +
+		`<span style="color: #2b91af">bool</span> {</span>` + "\n" +
+		`</pre>`
+	assert.Equal(t, excerpt, string(sources.codeExcerpt(tag)))
+
+	// The files are read once by the sources of a graph, the sources of the next graph read them again
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\n\n\nfunc Check() {\n"), 0644))
+	assert.Equal(t, excerpt, string(sources.codeExcerpt(tag)))
+	assert.Equal(t, `<pre class="code-excerpt"><span class="code-signature"><span style="color: #0000ff">func</span> Check() {</span>`+"\n"+`</pre>`,
+		string(newCodeSources().codeExcerpt(&code.Code{CodeFile: tag.CodeFile, Tag: "Check", Line: 5})))
+
+	// The files of unknown languages are shown as plain text
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.unknown"), []byte("<check>\n"), 0644))
+	plain := &code.Code{CodeFile: code.CodeFile{RepoName: "excerpts", Path: "notes.unknown"}, Tag: "check", Line: 1}
+	assert.Equal(t, `<pre class="code-excerpt"><span class="code-signature">&lt;check&gt;</span>`+"\n"+`</pre>`, string(sources.codeExcerpt(plain)))
+
+	// Nothing is shown for the files which cannot be read
+	missing := &code.Code{CodeFile: code.CodeFile{RepoName: "excerpts", Path: "missing.go"}, Tag: "Missing", Line: 1}
+	assert.Empty(t, sources.codeExcerpt(missing))
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// issueLinks links the issues of a graph to the requirements they concern, and the requirements to their issues
+type issueLinks struct {
+	// The requirements affected by the issues reported at a location
+	byLocation map[reqs.IssueLocation][]*reqs.Req
+	// The issues of each requirement, by ID
+	byReq map[string][]diagnostics.Issue
+	// The code files with code tags, by repository and path
+	codeFiles map[reqs.IssueLocation]bool
+}
+
+// newIssueLinks indexes the issues of the graph by the requirements they concern, as the issues are attributed to
+// requirements when splitting the issues report
+// @llr REQ-TRAQ-SWL-192
+func newIssueLinks(rg *reqs.ReqGraph) *issueLinks {
+	links := &issueLinks{byLocation: rg.IssueRequirements(), byReq: make(map[string][]diagnostics.Issue), codeFiles: make(map[reqs.IssueLocation]bool)}
+	for _, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			links.codeFiles[reqs.IssueLocation{RepoName: tag.CodeFile.RepoName, Path: tag.CodeFile.Path}] = true
+		}
+	}
+	for _, issue := range rg.Issues {
+		for _, r := range links.requirements(issue) {
+			links.byReq[r.ID] = append(links.byReq[r.ID], issue)
+		}
+	}
+	return links
+}
+
+// requirements returns the requirements concerned by the issue, once each and ordered by ID
+// @llr REQ-TRAQ-SWL-192
+func (links *issueLinks) requirements(issue diagnostics.Issue) []*reqs.Req {
+	seen := make(map[string]bool)
+	var affected []*reqs.Req
+	for _, r := range links.byLocation[reqs.LocationOf(issue)] {
+		if !seen[r.ID] {
+			seen[r.ID] = true
+			affected = append(affected, r)
+		}
+	}
+	sort.Slice(affected, func(i, j int) bool { return affected[i].ID < affected[j].ID })
+	return affected
+}
+
+// issueLinksHTML returns the links of an issue of the issues report: to the requirements it concerns in the top down
+// report at the given URL, unless empty, and to its location when it was found in code
+// @llr REQ-TRAQ-SWL-192
+func (links *issueLinks) issueLinksHTML(topDownURL string, issue diagnostics.Issue) template.HTML {
+	html := ""
+	if topDownURL != "" {
+		for _, r := range links.requirements(issue) {
+			html += fmt.Sprintf(` <a class="issue-requirement" href="%s#%s">%s</a>`, template.HTMLEscapeString(topDownURL), template.HTMLEscapeString(r.ID), template.HTMLEscapeString(r.ID))
+		}
+	}
+	if links.codeFiles[reqs.IssueLocation{RepoName: issue.RepoName, Path: issue.Path}] {
+		url := fmt.Sprintf("/code/%s/%s#L%d", issue.RepoName, issue.Path, issue.Line)
+		html += fmt.Sprintf(` <a class="issue-code" href="%s" target="_blank">%s:%d</a>`, template.HTMLEscapeString(url), template.HTMLEscapeString(issue.Path), issue.Line)
+	}
+	return template.HTML(html)
+}
+
+// requirementIssues returns the issues concerning the requirement, listed under it in the reports
+// @llr REQ-TRAQ-SWL-192
+func (links *issueLinks) requirementIssues(r *reqs.Req) []diagnostics.Issue {
+	return links.byReq[r.ID]
+}
+
+// funcs returns the template functions linking the issues and the requirements of the report
+// @llr REQ-TRAQ-SWL-192
+func (links *issueLinks) funcs() template.FuncMap {
+	return template.FuncMap{
+		"issueLinks":        links.issueLinksHTML,
+		"requirementIssues": links.requirementIssues,
+	}
+}
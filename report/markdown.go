@@ -0,0 +1,375 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// The name of the page listing all the exported requirements
+const markdownIndexName = "index.md"
+
+// The name of the search index of the exported requirements
+const searchIndexName = "search-index.json"
+
+// MarkdownPages exports the requirements as markdown pages, one per requirement, for publishing them in a wiki
+// or with a static site generator.
+type MarkdownPages struct {
+	// Template of the links to the source files, with the {repo}, {path} and {line} placeholders, e.g.
+	// `https://github.com/org/{repo}/blob/main/{path}#L{line}`. Source locations are not linked when empty.
+	SourceURL string
+}
+
+// markdownDocument holds the requirements of a document, for the index page
+type markdownDocument struct {
+	RepoName repos.RepoName
+	Path     string
+	Reqs     []*reqs.Req
+}
+
+// searchEntry is a requirement in the search index, as a document to be indexed by client-side search libraries
+type searchEntry struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Repo     string   `json:"repo"`
+	Document string   `json:"document"`
+	Parents  []string `json:"parents,omitempty"`
+	// The page of the requirement, relative to the search index
+	URL string `json:"url"`
+}
+
+// markdownAttribute is an attribute of a requirement, as listed in its page
+type markdownAttribute struct {
+	Name     string
+	Value    string
+	Computed bool
+}
+
+// Export writes the page of each requirement which is not deleted to the given directory, named after the ID of
+// the requirement, together with an index page listing them by document and a search index.
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-110, REQ-TRAQ-SWL-134
+func (m MarkdownPages) Export(rg *reqs.ReqGraph, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	requirements := sortedLiveReqs(rg)
+	if err := checkPageNames(requirements, markdownPageName); err != nil {
+		return err
+	}
+	tmpl := m.template()
+	for _, r := range requirements {
+		if err := writeMarkdownPage(tmpl, filepath.Join(dir, markdownPageName(r)), "PAGE", r); err != nil {
+			return err
+		}
+	}
+	documents := groupByDocument(requirements)
+	if err := writeMarkdownPage(tmpl, filepath.Join(dir, markdownIndexName), "INDEX", documents); err != nil {
+		return err
+	}
+	return writeSearchIndex(filepath.Join(dir, searchIndexName), requirements)
+}
+
+// sortedLiveReqs returns the requirements of the graph which are not deleted, sorted by repository, document and
+// position in the document
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-151
+func sortedLiveReqs(rg *reqs.ReqGraph) []*reqs.Req {
+	var requirements []*reqs.Req
+	for _, r := range rg.Reqs {
+		if !r.IsDeleted() {
+			requirements = append(requirements, r)
+		}
+	}
+	sort.Slice(requirements, func(i, j int) bool {
+		if requirements[i].RepoName != requirements[j].RepoName {
+			return requirements[i].RepoName < requirements[j].RepoName
+		}
+		if requirements[i].Document.Path != requirements[j].Document.Path {
+			return requirements[i].Document.Path < requirements[j].Document.Path
+		}
+		return requirements[i].Position < requirements[j].Position
+	})
+	return requirements
+}
+
+// groupByDocument groups the given requirements, sorted as by sortedLiveReqs, by document
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-151
+func groupByDocument(requirements []*reqs.Req) []*markdownDocument {
+	var documents []*markdownDocument
+	for _, r := range requirements {
+		if len(documents) == 0 || documents[len(documents)-1].RepoName != r.RepoName ||
+			documents[len(documents)-1].Path != r.Document.Path {
+			documents = append(documents, &markdownDocument{RepoName: r.RepoName, Path: r.Document.Path})
+		}
+		documents[len(documents)-1].Reqs = append(documents[len(documents)-1].Reqs, r)
+	}
+	return documents
+}
+
+// writeSearchIndex writes the given requirements as a JSON array of documents, which client-side search libraries
+// such as lunr or MiniSearch can index when the pages are published, without the web interface running.
+// @llr REQ-TRAQ-SWL-134
+func writeSearchIndex(path string, requirements []*reqs.Req) error {
+	content, err := json.MarshalIndent(searchEntries(requirements, markdownPageName), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// searchEntries returns the entries of the search index of the given requirements, linking the pages named by the
+// given function
+// @llr REQ-TRAQ-SWL-134, REQ-TRAQ-SWL-151
+func searchEntries(requirements []*reqs.Req, pageName func(*reqs.Req) string) []searchEntry {
+	entries := make([]searchEntry, 0, len(requirements))
+	for _, r := range requirements {
+		entries = append(entries, searchEntry{
+			ID:       r.ID,
+			Title:    r.Title,
+			Body:     strings.TrimSpace(r.Body),
+			Repo:     string(r.RepoName),
+			Document: r.Document.Path,
+			Parents:  r.ParentIds,
+			URL:      pageName(r),
+		})
+	}
+	return entries
+}
+
+// writeMarkdownPage writes a markdown page by executing the given template
+// @llr REQ-TRAQ-SWL-109
+func writeMarkdownPage(tmpl *template.Template, path string, templateName string, data interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.ExecuteTemplate(file, templateName, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// template returns the templates of the pages, with the functions depending on the export settings
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-137
+func (m MarkdownPages) template() *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{
+		"pageName":   markdownPageName,
+		"attributes": markdownAttributes,
+		"sourceLink": m.sourceLink,
+		"isImpl":     isImpl,
+		"isTest":     isTest,
+		"live":       liveReqs,
+		"trim":       strings.TrimSpace,
+		"cell":       markdownCell,
+	}).Parse(markdownTmplText))
+}
+
+// markdownPageName returns the name of the page of a requirement
+// @llr REQ-TRAQ-SWL-109
+func markdownPageName(r *reqs.Req) string {
+	return reqPageBaseName(r) + ".md"
+}
+
+// reqPageBaseName returns the name of the pages of a requirement without extension: its ID, with the characters
+// which are not safe in file names and URLs, such as path separators, replaced by underscores
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-151
+func reqPageBaseName(r *reqs.Req) string {
+	return strings.Trim(reSitePageSeparators.ReplaceAllString(r.ID, "_"), "_")
+}
+
+// checkPageNames returns an error if the pages of two requirements would have the same name. The names are compared
+// ignoring the case, as the pages may be written to case-insensitive file systems.
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-151
+func checkPageNames(requirements []*reqs.Req, pageName func(*reqs.Req) string) error {
+	owners := make(map[string]*reqs.Req, len(requirements))
+	for _, r := range requirements {
+		name := pageName(r)
+		if owner, ok := owners[strings.ToLower(name)]; ok {
+			return fmt.Errorf("Requirements %s and %s would both be written to the page `%s`", owner.ID, r.ID, name)
+		}
+		owners[strings.ToLower(name)] = r
+	}
+	return nil
+}
+
+// markdownAttributes returns the attributes of a requirement sorted by name, followed by its computed attributes.
+// The parents are left out, as they are listed as links, and the values are escaped for a markdown table.
+// @llr REQ-TRAQ-SWL-109
+func markdownAttributes(r *reqs.Req) []markdownAttribute {
+	escaper := strings.NewReplacer("|", "\\|", "\n", " ")
+	attributes := sortedAttributes(r)
+	for i := range attributes {
+		attributes[i].Value = escaper.Replace(attributes[i].Value)
+	}
+	return attributes
+}
+
+// sortedAttributes returns the attributes of a requirement sorted by name, followed by its computed attributes,
+// leaving out the parents
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-151
+func sortedAttributes(r *reqs.Req) []markdownAttribute {
+	var attributes []markdownAttribute
+	for _, computed := range []bool{false, true} {
+		values := r.Attributes
+		if computed {
+			values = r.ComputedAttributes
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			if name != "PARENTS" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			attributes = append(attributes, markdownAttribute{Name: name, Value: values[name], Computed: computed})
+		}
+	}
+	return attributes
+}
+
+// markdownCell returns the value escaped for a markdown table, or "*unset*" for empty values
+// @llr REQ-TRAQ-SWL-137
+func markdownCell(value string) string {
+	if value == "" {
+		return "*unset*"
+	}
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
+
+// liveReqs returns the given requirements which are not deleted, as deleted requirements have no page
+// @llr REQ-TRAQ-SWL-109
+func liveReqs(requirements []*reqs.Req) []*reqs.Req {
+	var live []*reqs.Req
+	for _, r := range requirements {
+		if !r.IsDeleted() {
+			live = append(live, r)
+		}
+	}
+	return live
+}
+
+// sourceLink returns the location of a line in a file of a repository, as a markdown link if a source URL template
+// is configured
+// @llr REQ-TRAQ-SWL-109
+func (m MarkdownPages) sourceLink(repoName repos.RepoName, path string, line int) string {
+	location := fmt.Sprintf("`%s:%s:%d`", repoName, path, line)
+	if m.SourceURL == "" {
+		return location
+	}
+	return fmt.Sprintf("[%s](%s)", location, sourceURL(m.SourceURL, repoName, path, line))
+}
+
+// sourceURL returns the link to a line in a file of a repository, made from a template with the {repo}, {path} and
+// {line} placeholders
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-151
+func sourceURL(urlTemplate string, repoName repos.RepoName, path string, line int) string {
+	return strings.NewReplacer("{repo}", string(repoName), "{path}", path, "{line}", strconv.Itoa(line)).Replace(urlTemplate)
+}
+
+var markdownTmplText = `
+{{- define "PAGE" -}}
+# {{ .ID }} {{ .Title }}
+
+Defined in {{ sourceLink .RepoName .Document.Path .Position }}
+{{- with trim .Body }}
+
+{{ . }}
+{{- end }}
+{{- with attributes . }}
+
+## Attributes
+
+| Attribute | Value |
+| --- | --- |
+{{- range . }}
+| {{ if .Computed }}*{{ .Name }}*{{ else }}{{ .Name }}{{ end }} | {{ .Value }} |
+{{- end }}
+{{- end }}
+{{- with .AttributeHistory }}
+
+## Attribute history
+
+| Date | Author | Commit | Attribute | Old | New |
+| --- | --- | --- | --- | --- | --- |
+{{- range . }}
+| {{ .Commit.Date }} | {{ cell .Commit.Author }} | {{ .Commit.ID }} | {{ .Attribute }} | {{ cell .Old }} | {{ cell .New }} |
+{{- end }}
+{{- end }}
+{{- with live .Parents }}
+
+## Parents
+{{ range . }}
+- [{{ .ID }} {{ .Title }}]({{ pageName . }})
+{{- end }}
+{{- end }}
+{{- with live .Children }}
+
+## Children
+{{ range . }}
+- [{{ .ID }} {{ .Title }}]({{ pageName . }})
+{{- end }}
+{{- end }}
+{{- if .Tags }}
+
+## Code
+{{ range .Tags }}
+{{- if isImpl .CodeFile }}
+- {{ if $.IsAssumption }}Assumption check{{ else }}Implementation{{ end }}: {{ .Tag }} in {{ sourceLink .CodeFile.RepoName .CodeFile.Path .Line }}
+{{- end }}
+{{- end }}
+{{- range .Tags }}
+{{- if isTest .CodeFile }}
+- {{ if $.IsAssumption }}Assumption check test{{ else }}Test{{ end }}: {{ .Tag }} in {{ sourceLink .CodeFile.RepoName .CodeFile.Path .Line }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .Artifacts }}
+
+## Build artifacts
+{{ range . }}
+- {{ .Name }}{{ if not .Shipped }} (not shipped){{ end }}
+{{- end }}
+{{- end }}
+{{- with .RelatedChanges }}
+
+## Related changes
+
+| Date | Author | Repository | Commit | Subject |
+| --- | --- | --- | --- | --- |
+{{- range . }}
+| {{ .Commit.Date }} | {{ cell .Commit.Author }} | {{ .RepoName }} | {{ .Commit.ID }} | {{ cell .Commit.Subject }} |
+{{- end }}
+{{- end }}
+{{ end -}}
+
+{{- define "INDEX" -}}
+# Requirements
+{{- range . }}
+
+## {{ .Path }} ({{ .RepoName }})
+{{ range .Reqs }}
+- [{{ .ID }} {{ .Title }}]({{ pageName . }})
+{{- end }}
+{{- end }}
+{{ end -}}
+
+{{- define "DOCUMENT" -}}
+# {{ .Path }}
+
+Repository: {{ .RepoName }}
+{{ range .Reqs }}
+- [{{ .ID }} {{ .Title }}]({{ pageName . }})
+{{- end }}
+{{ end -}}
+`
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-109, REQ-TRAQ-SWL-134, REQ-TRAQ-SWL-137, REQ-TRAQ-SWL-158, REQ-TRAQ-SWL-171
+func TestMarkdownPages_Export(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	swh := &reqs.Req{ID: "REQ-TEST-SWH-1", Title: "Logging", Body: "\nThe system SHALL log.\n\n", Document: &srd,
+		RepoName: "repo", Position: 3, Attributes: map[string]string{"RATIONALE": "Debugging | support"},
+		AttributeHistory: []reqs.AttributeChange{
+			{Commit: repos.Commit{ID: "1a2b3c4", Date: "2024-03-01", Author: "Jane Doe"}, Attribute: "RATIONALE", New: "Debugging | support"},
+		}}
+	swl := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log rotation", Body: "The logs SHALL be rotated daily.",
+		Document: &sdd, RepoName: "repo", Position: 7, ParentIds: []string{"REQ-TEST-SWH-1"},
+		Attributes:         map[string]string{"PARENTS": "REQ-TEST-SWH-1", "VERIFICATION": "Test"},
+		ComputedAttributes: map[string]string{"STATUS": "Implemented"},
+		RelatedChanges: []reqs.RelatedChange{
+			{Commit: repos.Commit{ID: "5d6e7f8", Date: "2024-04-02", Author: "John Roe", Subject: "Rotate the logs of the CI | nightly"}, RepoName: "ci"},
+		},
+		Artifacts: []reqs.BuildArtifact{{Name: "//app:server", Shipped: true}, {Name: "//tools:replay"}}}
+	deleted := &reqs.Req{ID: "REQ-TEST-SWL-2", Title: "DELETED", Document: &sdd, RepoName: "repo", Position: 12}
+	swh.Children = []*reqs.Req{swl, deleted}
+	swl.Parents = []*reqs.Req{swh}
+	swl.Tags = []*code.Code{
+		{CodeFile: code.CodeFile{RepoName: "repo", Path: "log_test.go", Type: code.CodeTypeTests}, Tag: "TestRotate", Line: 9},
+		{CodeFile: code.CodeFile{RepoName: "repo", Path: "log.go", Type: code.CodeTypeImplementation}, Tag: "rotate", Line: 4},
+	}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{swh.ID: swh, swl.ID: swl, deleted.ID: deleted}}
+
+	dir := t.TempDir()
+	pages := MarkdownPages{SourceURL: "https://git.example.com/{repo}/blob/main/{path}#L{line}"}
+	assert.NoError(t, pages.Export(rg, dir))
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(content)
+	}
+	assert.Equal(t, "# REQ-TEST-SWH-1 Logging\n\n"+
+		"Defined in [`repo:TEST-137-SRD.md:3`](https://git.example.com/repo/blob/main/TEST-137-SRD.md#L3)\n\n"+
+		"The system SHALL log.\n\n"+
+		"## Attributes\n\n| Attribute | Value |\n| --- | --- |\n| RATIONALE | Debugging \\| support |\n\n"+
+		"## Attribute history\n\n| Date | Author | Commit | Attribute | Old | New |\n| --- | --- | --- | --- | --- | --- |\n"+
+		"| 2024-03-01 | Jane Doe | 1a2b3c4 | RATIONALE | *unset* | Debugging \\| support |\n\n"+
+		"## Children\n\n- [REQ-TEST-SWL-1 Log rotation](REQ-TEST-SWL-1.md)\n", read("REQ-TEST-SWH-1.md"))
+	assert.Equal(t, "# REQ-TEST-SWL-1 Log rotation\n\n"+
+		"Defined in [`repo:TEST-138-SDD.md:7`](https://git.example.com/repo/blob/main/TEST-138-SDD.md#L7)\n\n"+
+		"The logs SHALL be rotated daily.\n\n"+
+		"## Attributes\n\n| Attribute | Value |\n| --- | --- |\n| VERIFICATION | Test |\n| *STATUS* | Implemented |\n\n"+
+		"## Parents\n\n- [REQ-TEST-SWH-1 Logging](REQ-TEST-SWH-1.md)\n\n"+
+		"## Code\n\n"+
+		"- Implementation: rotate in [`repo:log.go:4`](https://git.example.com/repo/blob/main/log.go#L4)\n"+
+		"- Test: TestRotate in [`repo:log_test.go:9`](https://git.example.com/repo/blob/main/log_test.go#L9)\n\n"+
+		"## Build artifacts\n\n- //app:server\n- //tools:replay (not shipped)\n\n"+
+		"## Related changes\n\n| Date | Author | Repository | Commit | Subject |\n| --- | --- | --- | --- | --- |\n"+
+		"| 2024-04-02 | John Roe | ci | 5d6e7f8 | Rotate the logs of the CI \\| nightly |\n",
+		read("REQ-TEST-SWL-1.md"))
+	assert.Equal(t, "# Requirements\n\n"+
+		"## TEST-137-SRD.md (repo)\n\n- [REQ-TEST-SWH-1 Logging](REQ-TEST-SWH-1.md)\n\n"+
+		"## TEST-138-SDD.md (repo)\n\n- [REQ-TEST-SWL-1 Log rotation](REQ-TEST-SWL-1.md)\n", read("index.md"))
+	assert.NoFileExists(t, filepath.Join(dir, "REQ-TEST-SWL-2.md"))
+
+	// The search index lists the pages in the same order, without the deleted requirement
+	var index []searchEntry
+	assert.NoError(t, json.Unmarshal([]byte(read("search-index.json")), &index))
+	assert.Equal(t, []searchEntry{
+		{ID: "REQ-TEST-SWH-1", Title: "Logging", Body: "The system SHALL log.", Repo: "repo", Document: "TEST-137-SRD.md",
+			URL: "REQ-TEST-SWH-1.md"},
+		{ID: "REQ-TEST-SWL-1", Title: "Log rotation", Body: "The logs SHALL be rotated daily.", Repo: "repo",
+			Document: "TEST-138-SDD.md", Parents: []string{"REQ-TEST-SWH-1"}, URL: "REQ-TEST-SWL-1.md"},
+	}, index)
+
+	// Without a source URL template, the locations are not linked
+	assert.Equal(t, "`repo:log.go:4`", MarkdownPages{}.sourceLink("repo", "log.go", 4))
+}
+
+// @llr REQ-TRAQ-SWL-109
+func TestMarkdownPages_ExportPageNames(t *testing.T) {
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	nested := &reqs.Req{ID: "REQ-TEST/SWL-1", Title: "Nested", Document: &sdd, RepoName: "repo", Position: 3}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{nested.ID: nested}}
+
+	// The unsafe characters of the IDs are replaced, so the pages stay in the export directory
+	dir := t.TempDir()
+	assert.NoError(t, MarkdownPages{}.Export(rg, dir))
+	assert.FileExists(t, filepath.Join(dir, "REQ-TEST_SWL-1.md"))
+	assert.Equal(t, "REQ-TEST_SWL-1.html", htmlPageName(nested))
+
+	// Requirements whose pages would overwrite each other are rejected
+	colliding := &reqs.Req{ID: "REQ-TEST:SWL-1", Title: "Colliding", Document: &sdd, RepoName: "repo", Position: 7}
+	rg.Reqs[colliding.ID] = colliding
+	assert.EqualError(t, MarkdownPages{}.Export(rg, t.TempDir()),
+		"Requirements REQ-TEST/SWL-1 and REQ-TEST:SWL-1 would both be written to the page `REQ-TEST_SWL-1.md`")
+}
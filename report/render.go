@@ -0,0 +1,207 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"log"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/profile"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+// bodySeparator is written between the bodies converted by a single pandoc run. Pandoc copies raw HTML comments to
+// its output, where the bodies are split at the separators.
+const bodySeparator = "<!-- reqtraq-body-separator -->"
+
+// notSelfContained matches the footnotes and the definitions of reference links, which pandoc resolves across the
+// whole document, so the bodies using them are converted on their own.
+var notSelfContained = regexp.MustCompile(`\[\^|\^\[|(?m)^ {0,3}\[[^\]]+\]:`)
+
+// renderCounts counts the work done to convert the bodies of the requirements to HTML
+type renderCounts struct {
+	// The number of bodies converted by pandoc, the number of pandoc runs and the time spent in them
+	Converted  int
+	PandocRuns int
+	PandocTime time.Duration
+	// The number of bodies used by the templates which were already converted
+	Reused int
+}
+
+var (
+	renderMutex sync.Mutex
+	// The HTML of the bodies converted so far, by markdown text. The bodies are converted once for all the reports
+	// of the graph they were converted for, e.g. by a batch.
+	renderedBodies = map[string]template.HTML{}
+	// The graph of the last report, whose bodies are kept in renderedBodies
+	renderedGraph *reqs.ReqGraph
+	renderStats   renderCounts
+)
+
+// runPandoc converts the given markdown to HTML with pandoc
+// @llr REQ-TRAQ-SWL-41, REQ-TRAQ-SWL-178
+func runPandoc(markdown string) (string, error) {
+	defer profile.Start("pandoc", "", "")()
+	start := time.Now()
+	cmd := exec.Command("pandoc", "--mathjax")
+	cmd.Stdin = strings.NewReader(markdown)
+	out, err := cmd.CombinedOutput()
+	renderStats.PandocRuns++
+	renderStats.PandocTime += time.Since(start)
+	if err != nil {
+		return "", errors.Wrapf(err, "pandoc failed: %s", out)
+	}
+	return string(out), nil
+}
+
+// forgetOtherBodies drops the converted bodies which are not bodies of the requirements of the given graph, so the
+// bodies of the previous graphs are not kept when the graph is rebuilt, e.g. by the web interface.
+// @llr REQ-TRAQ-SWL-178
+func forgetOtherBodies(rg *reqs.ReqGraph) {
+	if rg == renderedGraph {
+		return
+	}
+	renderedGraph = rg
+	kept := make(map[string]template.HTML)
+	for _, r := range rg.Reqs {
+		if html, ok := renderedBodies[r.Body]; ok {
+			kept[r.Body] = html
+		}
+	}
+	renderedBodies = kept
+}
+
+// renderBodies converts the bodies of the requirements of the graph which were not converted yet in a single pandoc
+// run. The bodies with footnotes or reference links are left to be converted one by one when the templates use
+// them, as are all the bodies when the output cannot be split back, e.g. because a body leaves a code block open.
+// @llr REQ-TRAQ-SWL-178
+func renderBodies(rg *reqs.ReqGraph) {
+	if _, err := exec.LookPath("pandoc"); err != nil {
+		return
+	}
+	renderMutex.Lock()
+	defer renderMutex.Unlock()
+	forgetOtherBodies(rg)
+
+	seen := make(map[string]bool)
+	var bodies []string
+	for _, r := range rg.Reqs {
+		if _, ok := renderedBodies[r.Body]; ok || seen[r.Body] || notSelfContained.MatchString(r.Body) {
+			continue
+		}
+		seen[r.Body] = true
+		bodies = append(bodies, r.Body)
+	}
+	if len(bodies) < 2 {
+		return
+	}
+	sort.Strings(bodies)
+
+	out, err := runPandoc(strings.Join(bodies, "\n\n"+bodySeparator+"\n\n"))
+	if err != nil {
+		log.Print("Warning: converting the bodies of the requirements in a single pandoc run failed, converting them one by one: ", err)
+		return
+	}
+	parts := strings.Split(out, bodySeparator)
+	if len(parts) != len(bodies) {
+		if linepipes.Verbose {
+			log.Printf("Converting the bodies of the requirements one by one, the pandoc output has %d bodies instead of %d", len(parts), len(bodies))
+		}
+		return
+	}
+	for i, body := range bodies {
+		renderedBodies[body] = template.HTML(strings.TrimSpace(parts[i]) + "\n")
+	}
+	renderStats.Converted += len(bodies)
+}
+
+// formatBodyAsHTML converts a string containing markdown to HTML using pandoc, or to escaped plain text if pandoc
+// cannot be found. The converted bodies are remembered, so each body is converted once.
+// @llr REQ-TRAQ-SWL-41, REQ-TRAQ-SWL-163, REQ-TRAQ-SWL-178
+func formatBodyAsHTML(txt string) template.HTML {
+	if _, err := exec.LookPath("pandoc"); err != nil {
+		warnPandocMissing.Do(func() {
+			log.Print("Warning: pandoc not found, the bodies of the requirements are shown as plain text: ", err)
+		})
+		return template.HTML(`<pre style="white-space: pre-wrap;">` + template.HTMLEscapeString(txt) + "</pre>")
+	}
+
+	renderMutex.Lock()
+	defer renderMutex.Unlock()
+	if html, ok := renderedBodies[txt]; ok {
+		renderStats.Reused++
+		return html
+	}
+	out, err := runPandoc(txt)
+	if err != nil {
+		log.Fatal("Error while running pandoc: ", err)
+	}
+	renderedBodies[txt] = template.HTML(out)
+	renderStats.Converted++
+	return template.HTML(out)
+}
+
+var (
+	templatesMutex sync.Mutex
+	// The templates of the reports of the last graph, whose template functions link the issues of the graph to its
+	// requirements and show excerpts of its code. The templates are cloned once for all the reports of a graph.
+	graphTemplates *template.Template
+	templatesGraph *reqs.ReqGraph
+)
+
+// templatesOf returns the templates of the reports of the graph, with the issues linked to the requirements they
+// concern and the code excerpts read from the files of the graph. The web interface can generate reports of different
+// graphs concurrently, so the functions are set in a copy of the templates.
+// @llr REQ-TRAQ-SWL-181, REQ-TRAQ-SWL-192
+func templatesOf(rg *reqs.ReqGraph) (*template.Template, error) {
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+	if rg == templatesGraph {
+		return graphTemplates, nil
+	}
+	tmpl, err := reportTmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	funcs := newIssueLinks(rg).funcs()
+	funcs["codeExcerpt"] = newCodeSources().codeExcerpt
+	graphTemplates, templatesGraph = tmpl.Funcs(funcs), rg
+	return graphTemplates, nil
+}
+
+// executeReport converts the bodies of the requirements of the graph, then executes the given template of the
+// report. The time spent is measured for --profile and reported with --verbose, along with the bodies converted.
+// @llr REQ-TRAQ-SWL-39, REQ-TRAQ-SWL-178, REQ-TRAQ-SWL-192
+func executeReport(rg *reqs.ReqGraph, w io.Writer, name string, data interface{}) error {
+	defer profile.Start("report", "", name)()
+	start := time.Now()
+	renderMutex.Lock()
+	before := renderStats
+	renderMutex.Unlock()
+
+	renderBodies(rg)
+	tmpl, err := templatesOf(rg)
+	if err != nil {
+		return err
+	}
+	stopTemplate := profile.Start("report template", "", name)
+	err = tmpl.ExecuteTemplate(w, name, data)
+	stopTemplate()
+
+	if linepipes.Verbose {
+		renderMutex.Lock()
+		after := renderStats
+		renderMutex.Unlock()
+		log.Printf("Rendered %s in %s: %d bodies converted by %d pandoc runs in %s, %d bodies reused", name,
+			time.Since(start).Round(time.Millisecond), after.Converted-before.Converted, after.PandocRuns-before.PandocRuns,
+			(after.PandocTime - before.PandocTime).Round(time.Millisecond), after.Reused-before.Reused)
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// usePandocStub puts a pandoc copying its input to its output first in the PATH and forgets the converted bodies
+// @llr REQ-TRAQ-SWL-178
+func usePandocStub(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pandoc"), []byte("#!/bin/sh\ncat\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	renderedBodies = map[string]template.HTML{}
+	renderedGraph = nil
+	renderStats = renderCounts{}
+}
+
+// @llr REQ-TRAQ-SWL-178
+func TestRenderBodies(t *testing.T) {
+	usePandocStub(t)
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Body: "First body."},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Body: "Second body."},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", Body: "First body."},
+	}}
+
+	renderBodies(rg)
+	assert.Equal(t, renderCounts{Converted: 2, PandocRuns: 1, PandocTime: renderStats.PandocTime}, renderStats)
+	assert.Equal(t, template.HTML("First body.\n"), formatBodyAsHTML("First body."))
+	assert.Equal(t, template.HTML("Second body.\n"), formatBodyAsHTML("Second body."))
+	assert.Equal(t, 2, renderStats.Reused)
+
+	// The bodies are converted once for all the reports
+	renderBodies(rg)
+	assert.Equal(t, 1, renderStats.PandocRuns)
+
+	// A body which cannot be split back is converted on its own
+	rg.Reqs["REQ-TEST-SWL-4"] = &reqs.Req{ID: "REQ-TEST-SWL-4", Body: "Third " + bodySeparator}
+	rg.Reqs["REQ-TEST-SWL-5"] = &reqs.Req{ID: "REQ-TEST-SWL-5", Body: "Fourth body."}
+	renderBodies(rg)
+	assert.Equal(t, 2, renderStats.PandocRuns)
+	assert.Equal(t, 2, renderStats.Converted)
+	assert.Equal(t, template.HTML("Fourth body."), formatBodyAsHTML("Fourth body."))
+	assert.Equal(t, 3, renderStats.PandocRuns)
+}
+
+// @llr REQ-TRAQ-SWL-178
+func TestRenderBodies_NotSelfContained(t *testing.T) {
+	usePandocStub(t)
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Body: "First body."},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Body: "Second body[^1].\n\n[^1]: A footnote."},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", Body: "Third body, see [the spec][spec].\n\n  [spec]: http://example.com"},
+		"REQ-TEST-SWL-4": {ID: "REQ-TEST-SWL-4", Body: "Fourth body^[An inline note]."},
+		"REQ-TEST-SWL-5": {ID: "REQ-TEST-SWL-5", Body: "Fifth body."},
+	}}
+
+	// Only the bodies without footnotes and reference links are converted together
+	renderBodies(rg)
+	assert.Equal(t, 2, renderStats.Converted)
+	assert.Equal(t, 1, renderStats.PandocRuns)
+	_, converted := renderedBodies[rg.Reqs["REQ-TEST-SWL-2"].Body]
+	assert.False(t, converted)
+
+	// The bodies of the previous graph are forgotten when the report of another graph is rendered
+	rebuilt := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Body: "First body."},
+		"REQ-TEST-SWL-6": {ID: "REQ-TEST-SWL-6", Body: "Sixth body."},
+		"REQ-TEST-SWL-7": {ID: "REQ-TEST-SWL-7", Body: "Seventh body."},
+	}}
+	renderBodies(rebuilt)
+	assert.Equal(t, 2, renderStats.PandocRuns)
+	assert.Equal(t, map[string]template.HTML{"First body.": "First body.\n",
+		"Sixth body.": "Sixth body.\n", "Seventh body.": "Seventh body.\n"}, renderedBodies)
+}
+
+// @llr REQ-TRAQ-SWL-163
+func TestFormatBodyAsHTML_PandocMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	renderedBodies = map[string]template.HTML{}
+	renderedGraph = nil
+	renderStats = renderCounts{}
+	warnPandocMissing = sync.Once{}
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	// The bodies are not converted, but shown as escaped plain text keeping their line breaks
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Body: "First *body* with <b>HTML</b> & more.\nSecond line."},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Body: "Second body."},
+	}}
+	renderBodies(rg)
+	assert.Equal(t, renderCounts{}, renderStats)
+	assert.Empty(t, renderedBodies)
+	assert.Equal(t, template.HTML(`<pre style="white-space: pre-wrap;">First *body* with &lt;b&gt;HTML&lt;/b&gt; &amp; more.
+Second line.</pre>`), formatBodyAsHTML(rg.Reqs["REQ-TEST-SWL-1"].Body))
+	assert.Equal(t, template.HTML(`<pre style="white-space: pre-wrap;">Second body.</pre>`), formatBodyAsHTML("Second body."))
+	assert.Equal(t, renderCounts{}, renderStats)
+
+	// The missing pandoc is warned about once
+	assert.Equal(t, 1, strings.Count(logged.String(), "Warning: pandoc not found"))
+}
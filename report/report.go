@@ -8,54 +8,167 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"log"
 	"os/exec"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
 )
 
 type reportData struct {
 	Reqs   reqs.ReqGraph
 	Filter *reqs.ReqFilter
 	Once   Oncer
+	// The statistics of the documents, by repository and path
+	Stats map[reqs.IssueLocation]reqs.DocumentStats
+	// The code listed in the bottom-up reports
+	Code CodeView
+	// The URL of the top down report the issues reports link the requirements of the issues to, e.g.
+	// `req-down.html`. The requirements are not linked when empty.
+	TopDownURL string
+}
+
+// newReportData returns the data of a report of the given graph, filtered by the given filter unless nil
+// @llr REQ-TRAQ-SWL-39, REQ-TRAQ-SWL-142
+func newReportData(rg *reqs.ReqGraph, f *reqs.ReqFilter) reportData {
+	stats := make(map[reqs.IssueLocation]reqs.DocumentStats)
+	for _, s := range rg.DocumentStats() {
+		stats[reqs.IssueLocation{RepoName: s.RepoName, Path: s.Path}] = s
+	}
+	return reportData{Reqs: *rg, Filter: f, Once: Oncer{}, Stats: stats, Code: AllCode}
+}
+
+// AssumptionValidations returns the validation of the assumptions of the report
+// @llr REQ-TRAQ-SWL-167
+func (report reportData) AssumptionValidations() []reqs.AssumptionValidation {
+	return report.Reqs.AssumptionValidations()
+}
+
+// DocumentStats returns the statistics of the given document, nil if it is not a configured document
+// @llr REQ-TRAQ-SWL-142
+func (report reportData) DocumentStats(repoName repos.RepoName, path string) *reqs.DocumentStats {
+	if s, ok := report.Stats[reqs.IssueLocation{RepoName: repoName, Path: path}]; ok {
+		return &s
+	}
+	return nil
+}
+
+// issueFilterOption is a value of a filter of the issues report, with the number of issues having it
+type issueFilterOption struct {
+	Value string
+	Label string
+	Count int
+}
+
+// issueFilterOptions are the values of the filters of the issues report
+type issueFilterOptions struct {
+	Severities []issueFilterOption
+	Types      []issueFilterOption
+	Repos      []issueFilterOption
+	Documents  []issueFilterOption
+}
+
+// addIssueFilterOption counts an issue for the given value of a filter, adding the value if new
+// @llr REQ-TRAQ-SWL-150
+func addIssueFilterOption(options []issueFilterOption, value, label string) []issueFilterOption {
+	for i := range options {
+		if options[i].Value == value {
+			options[i].Count++
+			return options
+		}
+	}
+	return append(options, issueFilterOption{Value: value, Label: label, Count: 1})
+}
+
+// IssueFilters returns the values of the severity, type, repository and document filters of the issues report,
+// with the number of issues having them. Severities are sorted by decreasing severity, the others by value.
+// @llr REQ-TRAQ-SWL-150
+func (report reportData) IssueFilters() issueFilterOptions {
+	var options issueFilterOptions
+	for _, issue := range report.Reqs.Issues {
+		options.Severities = addIssueFilterOption(options.Severities, issue.Severity.String(), issue.Severity.String())
+		name, code := diagnostics.TypeInfo(issue.Type)
+		options.Types = addIssueFilterOption(options.Types, issueTypeCode(issue.Type), fmt.Sprintf("%s %s", code, name))
+		options.Repos = addIssueFilterOption(options.Repos, string(issue.RepoName), string(issue.RepoName))
+		document := fmt.Sprintf("%s:%s", issue.RepoName, issue.Path)
+		options.Documents = addIssueFilterOption(options.Documents, document, document)
+	}
+	severityRank := map[string]int{}
+	for _, severity := range []diagnostics.IssueSeverity{diagnostics.IssueSeverityMajor, diagnostics.IssueSeverityMinor, diagnostics.IssueSeverityNote} {
+		severityRank[severity.String()] = int(severity)
+	}
+	sort.Slice(options.Severities, func(i, j int) bool {
+		return severityRank[options.Severities[i].Value] < severityRank[options.Severities[j].Value]
+	})
+	for _, values := range [][]issueFilterOption{options.Types, options.Repos, options.Documents} {
+		sort.Slice(values, func(i, j int) bool { return values[i].Value < values[j].Value })
+	}
+	return options
+}
+
+// issueTypeCode returns the code of the given issue type, e.g. REQ3, used to filter the issues report. Unknown
+// types are identified by their number.
+// @llr REQ-TRAQ-SWL-150
+func issueTypeCode(issueType diagnostics.IssueType) string {
+	if _, code := diagnostics.TypeInfo(issueType); code != "" {
+		return code
+	}
+	return fmt.Sprintf("%d", issueType)
 }
 
 // ReportDown generates a HTML report of top down trace information.
 // @llr REQ-TRAQ-SWL-12, REQ-TRAQ-SWL-39
 func ReportDown(rg *reqs.ReqGraph, w io.Writer) error {
-	return reportTmpl.ExecuteTemplate(w, "TOPDOWN", reportData{*rg, nil, Oncer{}})
+	return executeReport(rg, w, "TOPDOWN", newReportData(rg, nil))
 }
 
 // ReportUp generates a HTML report of bottom up trace information.
 // @llr REQ-TRAQ-SWL-13, REQ-TRAQ-SWL-39
 func ReportUp(rg *reqs.ReqGraph, w io.Writer) error {
-	return reportTmpl.ExecuteTemplate(w, "BOTTOMUP", reportData{*rg, nil, Oncer{}})
+	return ReportUpCode(rg, w, nil, AllCode)
+}
+
+// ReportIssues generates a HTML report showing attribute and trace errors, linking the requirements of the issues to
+// the top down report at the given URL unless empty.
+// @llr REQ-TRAQ-SWL-30, REQ-TRAQ-SWL-39, REQ-TRAQ-SWL-116, REQ-TRAQ-SWL-192
+func ReportIssues(rg *reqs.ReqGraph, w io.Writer, topDownURL string) error {
+	data := newReportData(rg, nil)
+	data.TopDownURL = topDownURL
+	return executeReport(rg, w, "ISSUES", data)
 }
 
-// ReportIssues generates a HTML report showing attribute and trace errors.
-// @llr REQ-TRAQ-SWL-30, REQ-TRAQ-SWL-39
-func ReportIssues(rg *reqs.ReqGraph, w io.Writer) error {
-	return reportTmpl.ExecuteTemplate(w, "ISSUES", reportData{*rg, nil, Oncer{}})
+// ReportReviews generates a HTML report showing the open review comments of each requirement, followed by the
+// requirements whose implementation was changed without being accepted.
+// @llr REQ-TRAQ-SWL-103, REQ-TRAQ-SWL-139
+func ReportReviews(rg *reqs.ReqGraph, w io.Writer) error {
+	return executeReport(rg, w, "REVIEWS", newReportData(rg, nil))
 }
 
 // ReportDownFiltered generates a HTML report of top down trace information, which has been filtered by the supplied parameters.
 // @llr REQ-TRAQ-SWL-20, REQ-TRAQ-SWL-39
 func ReportDownFiltered(rg *reqs.ReqGraph, w io.Writer, f *reqs.ReqFilter) error {
-	return reportTmpl.ExecuteTemplate(w, "TOPDOWNFILT", reportData{*rg, f, Oncer{}})
+	return executeReport(rg, w, "TOPDOWNFILT", newReportData(rg, f))
 }
 
 // ReportUpFiltered generates a HTML report of bottom up trace information, which has been filtered by the supplied parameters.
 // @llr REQ-TRAQ-SWL-21, REQ-TRAQ-SWL-39
 func ReportUpFiltered(rg *reqs.ReqGraph, w io.Writer, f *reqs.ReqFilter) error {
-	return reportTmpl.ExecuteTemplate(w, "BOTTOMUPFILT", reportData{*rg, f, Oncer{}})
+	return ReportUpCode(rg, w, f, AllCode)
 }
 
 // ReportIssuesFiltered generates a HTML report showing attribute and trace errors, which has been filtered by the supplied parameters.
-// @llr REQ-TRAQ-SWL-31, REQ-TRAQ-SWL-39
-func ReportIssuesFiltered(rg *reqs.ReqGraph, w io.Writer, f *reqs.ReqFilter) error {
+// The requirements of the issues are linked to the top down report at the given URL unless empty.
+// @llr REQ-TRAQ-SWL-31, REQ-TRAQ-SWL-39, REQ-TRAQ-SWL-116, REQ-TRAQ-SWL-192
+func ReportIssuesFiltered(rg *reqs.ReqGraph, w io.Writer, f *reqs.ReqFilter, topDownURL string) error {
 	// TODO apply filter in ISSUESFILT template
-	return reportTmpl.ExecuteTemplate(w, "ISSUESFILT", reportData{*rg, f, Oncer{}})
+	data := newReportData(rg, f)
+	data.TopDownURL = topDownURL
+	return executeReport(rg, w, "ISSUESFILT", data)
 }
 
 // Prints a filter in a nicely formatted manner to be shown in the report
@@ -75,7 +188,7 @@ func (report reportData) PrintFilter() string {
 		if report.Filter.AnyAttributeRegexp != nil {
 			filterString = fmt.Sprintf("%s (Any Attribute: \"%s\")", filterString, report.Filter.AnyAttributeRegexp)
 		}
-		if report.Filter.TitleRegexp != nil {
+		if report.Filter.AttributeRegexp != nil {
 			filterString = fmt.Sprintf("%s (Attributes: \"%v\")", filterString, report.Filter.AttributeRegexp)
 		}
 		return filterString
@@ -137,6 +250,15 @@ var headerFooterTmplText = `
 				display: table-cell;
 				padding: 0em 0.5em;
 			}
+			pre.code-excerpt {
+				margin: 0.3em 0em 0.8em 0em;
+			}
+			pre.code-excerpt span.code-signature {
+				font-weight: bold;
+			}
+			img.flow-diagram {
+				max-width: 100%;
+			}
 		</style>
 		<!-- Load MathJax for rendering of equations -->
 		<script type="text/javascript" async
@@ -153,35 +275,35 @@ var headerFooterTmplText = `
 {{end}}
 `
 
-// formatBodyAsHTML converts a string containing markdown to HTML using pandoc.
-// @llr REQ-TRAQ-SWL-41
-func formatBodyAsHTML(txt string) template.HTML {
-	cmd := exec.Command("pandoc", "--mathjax")
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		log.Fatal("Couldn't get input pipe for pandoc: ", err)
-	}
-
-	go func() {
-		defer stdin.Close()
-		io.WriteString(stdin, txt)
-	}()
+// Warns once that the bodies of the requirements are shown as plain text because pandoc cannot be found
+var warnPandocMissing sync.Once
 
-	out, err := cmd.CombinedOutput()
+// PandocVersion returns the first line of the version of pandoc, or an error if it cannot be run
+// @llr REQ-TRAQ-SWL-162
+func PandocVersion() (string, error) {
+	out, err := exec.Command("pandoc", "--version").Output()
 	if err != nil {
-		log.Fatal("Error while running pandoc: ", err)
+		return "", errors.Wrap(err, "pandoc not available")
 	}
-
-	return template.HTML(out)
+	return strings.SplitN(string(out), "\n", 2)[0], nil
 }
 
 var functionMap = template.FuncMap{
-	"formatBodyAsHTML": formatBodyAsHTML,
-	"codeFileToString": codeFileToString,
-	"isImpl":           isImpl,
-	"isTest":           isTest,
-	"shouldShowTag":    shouldShowTag,
-	"listCodeParents":  listCodeParents,
+	"formatBodyAsHTML":  formatBodyAsHTML,
+	"codeFileToString":  codeFileToString,
+	"isImpl":            isImpl,
+	"isTest":            isTest,
+	"shouldShowTag":     shouldShowTag,
+	"listCodeParents":   listCodeParents,
+	"issueFingerprint":  reqs.IssueFingerprint,
+	"issueTypeCode":     issueTypeCode,
+	"sections":          renderRequirementSections,
+	"flowDiagrams":      flowDiagrams,
+	"flowDiagramAnchor": flowDiagramAnchor,
+	// Replaced by the links and the code of the graph of each report
+	"issueLinks":        (&issueLinks{}).issueLinksHTML,
+	"requirementIssues": (&issueLinks{}).requirementIssues,
+	"codeExcerpt":       newCodeSources().codeExcerpt,
 }
 var reportTmpl = template.Must(template.Must(template.New("").Funcs(functionMap).Parse(headerFooterTmplText)).Parse(reportTmplText))
 
@@ -230,30 +352,145 @@ var reportTmplText = `
 			{{ end }}
 			</ul>
 		{{ end }}
+		{{ if .ComputedAttributes }}
+			<ul style="list-style: none; padding: 0; margin: 0;">
+			{{ range $k, $v := .ComputedAttributes }}
+				<li><strong><em>{{ $k }}</em></strong>: {{ $v }}</li>
+			{{ end }}
+			</ul>
+		{{ end }}
+		{{ with .AttributeHistory }}
+			<p>Attribute history:</p>
+			<ul>
+			{{ range . }}
+				<li>{{ .Commit.Date }} {{ .Commit.Author }} ({{ .Commit.ID }}): <strong>{{ .Attribute }}</strong> {{ if .Old }}{{ .Old }}{{ else }}<em>unset</em>{{ end }} &rarr; {{ if .New }}{{ .New }}{{ else }}<em>unset</em>{{ end }}</li>
+			{{ end }}
+			</ul>
+		{{ end }}
+		{{ with .Override }}
+			<p><em>Overridden for variant {{ .Variant }} in {{ .Path }}:{{ .Position }}</em></p>
+		{{ end }}
+		{{ template "REVIEWCOMMENTS" . }}
+		{{ template "CODEREVIEWS" . }}
+		{{ template "RELATEDCHANGES" . }}
+		{{ template "ARTIFACTS" . }}
+		{{ template "ACCEPTANCECRITERIA" . }}
+		{{ template "REQUIREMENTISSUES" . }}
+		{{ sections . }}
 	{{ else }}
 		<h3><a href="#{{ .ID }}">{{ .ID }} {{ .Title }}</a></h3>
  	{{end}}
 {{ end }}
 
-{{ define "CODETAGS"}}
-	{{ if . }}
-		<p>Code Implementation:
+{{ define "CODEREVIEWS" }}
+	{{ with .CodeReviews }}
+		<p>Last changes of the implementation:</p>
+		<ul>
+		{{ range . }}
+			<li>{{ .RepoName }}:{{ .Path }} changed in {{ .Commit.ID }} by {{ .Commit.Author }} on {{ .Commit.Date }}:
+				{{ if .Accepted }}<span class="text-success">accepted</span>{{ else }}<span class="text-danger">not reviewed</span>{{ end }}
+				{{ with .Review }}(<a href="{{ . }}">review</a>){{ end }}
+			</li>
+		{{ end }}
+		</ul>
+	{{ end }}
+{{ end }}
+
+{{ define "RELATEDCHANGES" }}
+	{{ with .RelatedChanges }}
+		<p>Related changes:</p>
+		<ul>
+		{{ range . }}
+			<li>{{ .Commit.Date }} {{ .Commit.Author }} ({{ .RepoName }} {{ .Commit.ID }}): {{ .Commit.Subject }}</li>
+		{{ end }}
+		</ul>
+	{{ end }}
+{{ end }}
+
+{{ define "ARTIFACTS" }}
+	{{ with .Artifacts }}
+		<p>Build artifacts:
+		{{ range . }}
+			<code>{{ .Name }}</code>{{ if not .Shipped }} <em>(not shipped)</em>{{ end }}
+		{{ end }}
+		</p>
+	{{ end }}
+{{ end }}
+
+{{ define "REQUIREMENTISSUES" }}
+	{{ with requirementIssues . }}
+		<p>Open issues:</p>
+		<ul>
+		{{ range . }}
+			<li class="requirement-issue"><span class="label label-{{ if eq .Severity.String "error" }}danger{{ else if eq .Severity.String "warning" }}warning{{ else }}info{{ end }}">{{ .Severity }}</span> {{ .Description }}</li>
+		{{ end }}
+		</ul>
+	{{ end }}
+{{ end }}
+
+{{ define "REVIEWCOMMENTS" }}
+	{{ with .OpenReviewComments }}
+		<p>Open review comments:</p>
+		<ul>
+		{{ range . }}
+			<li class="text-warning"><strong>{{ .Author }}</strong> (line {{ .Line }}): {{ .Comment }}</li>
+		{{ end }}
+		</ul>
+	{{ end }}
+{{ end }}
+
+{{ define "ACCEPTANCECRITERIA" }}
+	{{ with .CriteriaStatus }}
+		<p>Acceptance criteria:</p>
+		<ul>
 		{{ range . }}
+			<li><strong>{{ .Criterion.ID }}</strong>: {{ .Criterion.Text }}
+			{{ if .Verified }}
+				<span class="text-success">verified by
+				{{ range .Tests }}
+					<a href="{{ .URL }}" target="_blank">{{ codeFileToString .CodeFile }} - {{ .Tag }}</a>
+				{{ end }}
+				</span>
+			{{ else }}
+				<span class="text-danger">not verified</span>
+			{{ end }}
+			</li>
+		{{ end }}
+		</ul>
+	{{ end }}
+{{ end }}
+
+{{ define "CODETAGS"}}
+	{{ if .Tags }}
+		<p>{{ if .IsAssumption }}Assumption Checks:{{ else }}Code Implementation:{{ end }}
+		{{ range .Tags }}
 			{{ if isImpl .CodeFile }}
 				<a href="{{ .URL }}" target="_blank">{{ codeFileToString .CodeFile }} - {{ .Tag }}</a>
+				{{ codeExcerpt . }}
 			{{ end }}
 		{{ end }}
 		</p>
-		<p>Code Tests:
-		{{ range . }}
+		<p>{{ if .IsAssumption }}Assumption Check Tests:{{ else }}Code Tests:{{ end }}
+		{{ range .Tags }}
 			{{ if isTest .CodeFile }}
 				<a href="{{ .URL }}" target="_blank">{{ codeFileToString .CodeFile }} - {{ .Tag }}</a>
+				{{ codeExcerpt . }}
 			{{ end }}
 		{{ end }}
 		</p>
 	{{ end }}
 {{ end }}
 
+{{ define "DOCSTATS" }}
+	<div class="well well-sm">
+		<strong>Requirements:</strong> {{ .Requirements }}{{ if .Assumptions }} ({{ .Assumptions }} assumptions){{ end }},
+		{{ .Implemented }} implemented, {{ .Tested }} tested, {{ .Deleted }} deleted.
+		<strong>Issues:</strong> {{ .Errors }} errors, {{ .Warnings }} warnings, {{ .Lint }} lint.
+		<strong>Completeness:</strong> {{ printf "%.1f" .Score }}%.
+		{{ with .LastCommit }}<br><strong>Last changed:</strong> {{ .Date }} {{ .ID }} {{ .Subject }} ({{ .Author }}){{ end }}
+	</div>
+{{ end }}
+
 {{ define "CHANGELIST" }}
 	{{ if . }}
 		<p>Changelists:
@@ -268,8 +505,11 @@ var reportTmplText = `
 	{{template "HEADER"}}
 	<h1>Top Down Tracing</h1>
 
+	{{ range .Reqs.OrdsByDocument }}
+	<h2>{{ .RepoName }}: {{ .Path }}</h2>
+	{{ with $.DocumentStats .RepoName .Path }}{{ template "DOCSTATS" . }}{{ end }}
 	<ul style="list-style: none; padding: 0; margin: 0;">
-		{{ range .Reqs.OrdsByPosition }}
+		{{ range .Reqs }}
 			<li>
 				{{ template "REQUIREMENT" . }}
 				<!-- HLRs -->
@@ -283,7 +523,7 @@ var reportTmplText = `
 								<li>
 									{{ with ($.Once.Once .) }}
 										{{ template "REQUIREMENT" . }}
-										{{ template "CODETAGS" .Tags }}
+										{{ template "CODETAGS" . }}
 										{{ template "CHANGELIST" .Changelists }}
 									{{ end }}
 								</li>
@@ -297,10 +537,53 @@ var reportTmplText = `
 					{{ end }}
 				</ul>
 			</li>
-		{{ else }}
-			<li  class="text-danger">Empty graph</li>
 		{{ end }}
 	</ul>
+	{{ else }}
+	<p class="text-danger">Empty graph</p>
+	{{ end }}
+
+	{{ with .Reqs.FlowsByPosition }}
+	{{ $diagrams := flowDiagrams . }}
+	<h2>Data and Control Flows</h2>
+	<table class="table table-sm">
+		<tr>
+			<th>Caller</th><th>Flow Tag</th><th>Callee</th><th>Direction</th><th>Description</th>
+			{{ range $.Reqs.FlowColumns }}<th>{{ . }}</th>{{ end }}
+			{{ if $diagrams }}<th>Diagram</th>{{ end }}
+			<th>Requirements</th>
+		</tr>
+		{{ range . }}
+		{{ $flow := . }}
+		<tr>
+			<td>{{ .Caller }}</td><td>{{ .ID }}</td><td>{{ .Callee }}</td><td>{{ .Direction }}</td><td>{{ .Description }}</td>
+			{{ range $.Reqs.FlowColumns }}<td>{{ index $flow.Attributes . }}</td>{{ end }}
+			{{ if $diagrams }}<td>{{ with flowDiagramAnchor $diagrams . }}<a href="#{{ . }}">{{ $flow.Diagram }}</a>{{ end }}</td>{{ end }}
+			<td>{{ range .Reqs }}<a href="#{{ .ID }}">{{ .ID }}</a> {{ end }}</td>
+		</tr>
+		{{ end }}
+	</table>
+	{{ range $diagrams }}
+	<h3 id="{{ .Anchor }}">{{ .Path }}</h3>
+	<p>Flow tags: {{ range .Flows }}{{ . }} {{ end }}</p>
+	{{ if .Content }}{{ .Content }}{{ else }}<p class="text-danger">The diagram cannot be read</p>{{ end }}
+	{{ end }}
+	{{ end }}
+
+	{{ with .AssumptionValidations }}
+	<h2>Assumptions</h2>
+	<table class="table table-sm">
+		<tr><th>Assumption</th><th>Owning requirements</th><th>Validation</th><th>Checked by</th></tr>
+		{{ range . }}
+		<tr>
+			<td><a href="#{{ .Assumption.ID }}">{{ .Assumption.ID }}</a> {{ .Assumption.Title }}</td>
+			<td>{{ range .Owners }}<a href="#{{ .ID }}">{{ .ID }}</a> {{ end }}</td>
+			<td>{{ if .Plan }}{{ .Plan }}{{ else if not .IsValidated }}<span class="text-danger">No validation plan</span>{{ end }}</td>
+			<td>{{ range .Evidence }}<a href="{{ .URL }}" target="_blank">{{ codeFileToString .CodeFile }} - {{ .Tag }}</a><br>{{ end }}</td>
+		</tr>
+		{{ end }}
+	</table>
+	{{ end }}
 	{{template "FOOTER"}}
 {{end}}
 
@@ -309,19 +592,17 @@ var reportTmplText = `
 	<h1>Bottom Up Tracing</h1>
 
 	<ul style="list-style: none; padding: 0; margin: 0;">
-		{{ range .Reqs.CodeTags }}
-		{{ range . }}
-		{{ if shouldShowTag . $.Reqs }}
+		{{ range .BottomUpEntries }}
 			<li>
 				{{ if isImpl .CodeFile }}
-					<h3><a href="{{ .URL }}" target="_blank">Impl: {{ codeFileToString .CodeFile }} - {{ .Tag }}</a></h3>
+					<h3><a href="{{ .URL }}" target="_blank">Impl: {{ codeFileToString .CodeFile }} - {{ .Names }}</a></h3>
 				{{ else }}
-					<h3><a href="{{ .URL }}" target="_blank">Test: {{ codeFileToString .CodeFile }} - {{ .Tag }}</a></h3>
+					<h3><a href="{{ .URL }}" target="_blank">Test: {{ codeFileToString .CodeFile }} - {{ .Names }}</a></h3>
 				{{ end }}
 
 				<!-- LLRs -->
 				<ul>
-					{{ range listCodeParents .Links $.Reqs }}
+					{{ range .Parents }}
 					{{ with ($.Once.Once .) }}
 					<li>
 						{{ template "REQUIREMENT" . }}
@@ -359,8 +640,6 @@ var reportTmplText = `
 					{{ end }}
 				</ul>
 			</li>
-		{{ end }}
-		{{ end }}
 		{{ else }}
 			<li class="text-danger">Empty graph</li>
 		{{ end }}
@@ -372,15 +651,166 @@ var reportTmplText = `
 	{{template "HEADER"}}
 	<h1>Issues</h1>
 
+	{{ if .Reqs.Issues }}
+		{{ template "ISSUELIST" . }}
+	{{ else }}
+		<p class="text-success">No basic errors found.</p>
+	{{ end }}
+	{{ template "FOOTER" }}
+{{ end }}
+
+{{ define "ISSUELIST" }}
+	{{ $groups := .Reqs.IssuesByRepo }}
+	{{ with .IssueFilters }}
+	<form id="issue-filters" class="form-inline">
+		<select class="form-control" data-filter="severity">
+			<option value="">All severities</option>
+			{{ range .Severities }}<option value="{{ .Value }}">{{ .Label }} ({{ .Count }})</option>{{ end }}
+		</select>
+		<select class="form-control" data-filter="type">
+			<option value="">All types</option>
+			{{ range .Types }}<option value="{{ .Value }}">{{ .Label }} ({{ .Count }})</option>{{ end }}
+		</select>
+		<select class="form-control" data-filter="repo">
+			<option value="">All repositories</option>
+			{{ range .Repos }}<option value="{{ .Value }}">{{ .Label }} ({{ .Count }})</option>{{ end }}
+		</select>
+		<select class="form-control" data-filter="document">
+			<option value="">All documents</option>
+			{{ range .Documents }}<option value="{{ .Value }}">{{ .Label }} ({{ .Count }})</option>{{ end }}
+		</select>
+		<span>Showing <span id="issue-shown-count">{{ len $.Reqs.Issues }}</span> of {{ len $.Reqs.Issues }} issues</span>
+	</form>
+	{{ end }}
+
 	<ul>
-	{{ range .Reqs.Issues }}
+	{{ range $groups }}
+		{{ $repo := .RepoName }}
+		<li><a href="#{{ $repo }}">{{ $repo }}</a> ({{ .Count }})
+			<ul>
+			{{ range .Documents }}
+				<li><a href="#{{ $repo }}:{{ .Path }}">{{ .Path }}</a> ({{ len .Issues }})</li>
+			{{ end }}
+			</ul>
+		</li>
+	{{ end }}
+	</ul>
+
+	{{ range $groups }}
+		{{ $repo := .RepoName }}
+		<details class="issue-group" open>
+		<summary><h2><a name="{{ $repo }}"></a>{{ $repo }} ({{ .Count }})</h2> <span class="issue-group-count"></span></summary>
+		{{ range .Documents }}
+			{{ $document := printf "%s:%s" $repo .Path }}
+			<details class="issue-group" open>
+			<summary><h3><a name="{{ $document }}"></a>{{ .Path }} ({{ len .Issues }})</h3> <span class="issue-group-count"></span></summary>
+			{{ with $.DocumentStats $repo .Path }}{{ template "DOCSTATS" . }}{{ end }}
+			<ul>
+			{{ range .Issues }}
+				{{ $fingerprint := issueFingerprint . }}
+				{{ $anchor := printf "issue-%s-%d" $fingerprint .Line }}
+				<li class="issue" id="{{ $anchor }}" data-severity="{{ .Severity }}" data-type="{{ issueTypeCode .Type }}" data-repo="{{ $repo }}" data-document="{{ $document }}">
+					<span class="label label-{{ if eq .Severity.String "error" }}danger{{ else if eq .Severity.String "warning" }}warning{{ else }}info{{ end }}">{{ .Severity }}</span>
+					{{ .Description }}
+					{{ issueLinks $.TopDownURL . }}
+					<a class="issue-anchor" href="#{{ $anchor }}" title="Link to this issue">#{{ $fingerprint }}</a>
+				</li>
+			{{ end }}
+			</ul>
+			</details>
+		{{ end }}
+		</details>
+	{{ end }}
+
+	<style>
+		details.issue-group > summary > h2, details.issue-group > summary > h3 {
+			display: inline;
+		}
+		li.issue:target {
+			background-color: #fcf8e3;
+		}
+	</style>
+	<script>
+		(function() {
+			var selects = document.querySelectorAll("#issue-filters select");
+			// Shows the issues matching all the selected filters and updates the counts of the groups
+			function applyFilters() {
+				var shown = 0;
+				document.querySelectorAll("li.issue").forEach(function(issue) {
+					var visible = true;
+					selects.forEach(function(select) {
+						if (select.value !== "" && issue.dataset[select.dataset.filter] !== select.value) {
+							visible = false;
+						}
+					});
+					issue.style.display = visible ? "" : "none";
+					if (visible) {
+						shown++;
+					}
+				});
+				document.getElementById("issue-shown-count").textContent = shown;
+				document.querySelectorAll("details.issue-group").forEach(function(group) {
+					var total = group.querySelectorAll("li.issue").length;
+					var visible = 0;
+					group.querySelectorAll("li.issue").forEach(function(issue) {
+						if (issue.style.display !== "none") {
+							visible++;
+						}
+					});
+					group.style.display = visible === 0 ? "none" : "";
+					group.querySelector(".issue-group-count").textContent = visible === total ? "" : visible + " shown";
+				});
+			}
+			selects.forEach(function(select) {
+				select.addEventListener("change", applyFilters);
+			});
+			// A linked issue is shown even if its group was collapsed
+			function openTarget() {
+				var target = window.location.hash ? document.getElementById(window.location.hash.substring(1)) : null;
+				for (var node = target; node; node = node.parentElement) {
+					if (node.tagName === "DETAILS") {
+						node.open = true;
+					}
+				}
+				if (target) {
+					target.scrollIntoView();
+				}
+			}
+			window.addEventListener("hashchange", openTarget);
+			openTarget();
+		})();
+	</script>
+{{ end }}
+
+{{ define "REVIEWS" }}
+	{{template "HEADER"}}
+	<h1>Open Review Comments</h1>
+
+	<ul style="list-style: none; padding: 0; margin: 0;">
+	{{ range .Reqs.ReqsWithOpenReviewComments }}
 		<li>
-			{{ .Description }}
+			<h3><a name="{{ .ID }}"></a>{{ .ID }} {{ .Title }}</h3>
+			<p><em>{{ .Document.Path }}:{{ .Position }}{{ if .Document.Frozen }} (frozen){{ end }}</em></p>
+			{{ template "REVIEWCOMMENTS" . }}
 		</li>
 	{{ else }}
-		<li class="text-success">No basic errors found.</li>
+		<li class="text-success">No open review comments.</li>
 	{{ end }}
 	</ul>
+
+	{{ with .Reqs.ReqsWithUnreviewedCode }}
+		<h1>Unreviewed Code Changes</h1>
+
+		<ul style="list-style: none; padding: 0; margin: 0;">
+		{{ range . }}
+			<li>
+				<h3><a name="{{ .ID }}-code"></a>{{ .ID }} {{ .Title }}</h3>
+				<p><em>{{ .Document.Path }}:{{ .Position }}</em></p>
+				{{ template "CODEREVIEWS" . }}
+			</li>
+		{{ end }}
+		</ul>
+	{{ end }}
 	{{ template "FOOTER" }}
 {{ end }}
 
@@ -389,8 +819,11 @@ var reportTmplText = `
 	<h1>Top Down Tracing</h1>
 
 	<h3><em>Filter Criteria: {{ .PrintFilter }} </em></h3>
+	{{ range .Reqs.OrdsByDocument }}
+	<h2>{{ .RepoName }}: {{ .Path }}</h2>
+	{{ with $.DocumentStats .RepoName .Path }}{{ template "DOCSTATS" . }}{{ end }}
 	<ul style="list-style: none; padding: 0; margin: 0;">
-		{{ range .Reqs.OrdsByPosition }}
+		{{ range .Reqs }}
 			{{ if .Matches $.Filter }}{{ template "REQUIREMENT" ($.Once.Once .) }}{{ end }}
 			{{ range .Children }}
 				{{ if .Matches $.Filter }}{{ template "REQUIREMENT" ($.Once.Once .) }}{{ end }}
@@ -398,7 +831,7 @@ var reportTmplText = `
 					{{ if .Matches $.Filter }}
 						{{ with ($.Once.Once .) }}
 							{{ template "REQUIREMENT" . }}
-							{{ template "CODETAGS" .Tags }}
+							{{ template "CODETAGS" . }}
 							{{ template "CHANGELIST" .Changelists }}
 						{{ end }}
 					{{ end }}
@@ -406,6 +839,7 @@ var reportTmplText = `
 			{{ end }}
 		{{ end }}
 	</ul>
+	{{ end }}
 	{{ template "FOOTER" }}
 {{ end }}
 
@@ -415,14 +849,12 @@ var reportTmplText = `
 
 	<h3><em>Filter Criteria: {{ .PrintFilter }} </em></h3>
 	<ul style="list-style: none; padding: 0; margin: 0;">
-		{{ range .Reqs.CodeTags }}
-		{{ range . }}
-		{{ if shouldShowTag . $.Reqs }}
-			{{ range listCodeParents .Links $.Reqs }}
+		{{ range .BottomUpEntries }}
+			{{ range .Parents }}
 				{{ if .Matches $.Filter }}
 					{{ with ($.Once.Once .) }}
 						{{ template "REQUIREMENT" . }}
-						{{ template "CODETAGS" .Tags }}
+						{{ template "CODETAGS" . }}
 						{{ template "CHANGELIST" .Changelists }}
 					{{ end }}
 				{{ end }}
@@ -434,8 +866,6 @@ var reportTmplText = `
 				{{ end }}
 			{{ end }}
 		{{ end }}
-		{{ end }}
-		{{ end }}
 	</ul>
 	{{ template "FOOTER" }}
 {{ end }}
@@ -445,13 +875,7 @@ var reportTmplText = `
 	<h1>Issues</h1>
 
 	<h3><em>Filter Criteria: {{ .PrintFilter }} </em></h3>
-	<ul>
-	{{ range .Reqs.Issues }}
-		<li>
-			{{ .Description }}
-		</li>
-	{{ end }}
-	</ul>
+	{{ template "ISSUELIST" . }}
 	{{ template "FOOTER" }}
 {{ end }}
 `
@@ -1,15 +1,20 @@
 package report
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/daedaleanai/reqtraq/code"
 	"github.com/daedaleanai/reqtraq/code/parsers"
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/daedaleanai/reqtraq/reqs"
 	"github.com/stretchr/testify/assert"
@@ -53,7 +58,7 @@ func TestReports(t *testing.T) {
 		}
 	}
 	{
-		if err := ReportIssues(rg, ioutil.Discard); err != nil {
+		if err := ReportIssues(rg, ioutil.Discard, ""); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -100,7 +105,7 @@ func checkFilteredReports(t *testing.T, rg *reqs.ReqGraph, filter *reqs.ReqFilte
 		}
 	}
 	{
-		if err := ReportIssuesFiltered(rg, ioutil.Discard, filter); err != nil {
+		if err := ReportIssuesFiltered(rg, ioutil.Discard, filter, ""); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -158,3 +163,273 @@ func TestReport_Matches_filter(t *testing.T) {
 		}
 	}
 }
+
+// @llr REQ-TRAQ-SWL-114
+func TestReport_FlowColumns(t *testing.T) {
+	doc := &config.Document{Path: "TEST-137-SRD.md"}
+	req := &reqs.Req{ID: "REQ-TEST-SWH-1", Title: "Telemetry", Document: doc}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{req.ID: req}, FlowTags: map[string]*reqs.Flow{
+		"TEST-CF-2": {ID: "TEST-CF-2", Caller: "Radio", Callee: "Logger", Direction: "Out", Document: doc, Position: 8,
+			Attributes: map[string]string{"RATE": "10 Hz"}},
+		"TEST-DF-1": {ID: "TEST-DF-1", Caller: "Sensor", Callee: "Radio", Direction: "In", Document: doc, Position: 4,
+			Attributes: map[string]string{"PROTOCOL": "CAN"}, Reqs: []*reqs.Req{req}},
+		"TEST-DF-3": {ID: "TEST-DF-3", Deleted: true, Document: doc, Attributes: map[string]string{"UNUSED": "x"}},
+	}}
+
+	assert.Equal(t, []string{"PROTOCOL", "RATE"}, rg.FlowColumns())
+	flows := rg.FlowsByPosition()
+	assert.Len(t, flows, 2)
+	assert.Equal(t, "TEST-DF-1", flows[0].ID)
+	assert.Equal(t, "TEST-CF-2", flows[1].ID)
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportDown(rg, &buf))
+	assert.Contains(t, buf.String(), "<th>PROTOCOL</th><th>RATE</th>")
+	assert.Regexp(t, `<td>TEST-DF-1</td>.*\s*<td>CAN</td><td></td>\s*<td><a href="#REQ-TEST-SWH-1">`, buf.String())
+	assert.NotContains(t, buf.String(), "TEST-DF-3")
+}
+
+// @llr REQ-TRAQ-SWL-185
+func TestReport_FlowDiagrams(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "telemetry.puml"), []byte("@startuml\nSensor -> Radio\n@enduml\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "logging.svg"), []byte("<svg/>"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "overview.drawio"), []byte("<mxfile/>"), 0644))
+	repos.RegisterRepository("diagrams", repos.RepoPath(dir))
+
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	rg := &reqs.ReqGraph{FlowTags: map[string]*reqs.Flow{
+		"TEST-CF-1": {ID: "TEST-CF-1", Document: doc, RepoName: "diagrams", Position: 4, Diagram: "telemetry.puml"},
+		"TEST-CF-2": {ID: "TEST-CF-2", Document: doc, RepoName: "diagrams", Position: 5, Diagram: "logging.svg"},
+		"TEST-CF-3": {ID: "TEST-CF-3", Document: doc, RepoName: "diagrams", Position: 6, Diagram: "telemetry.puml"},
+		"TEST-CF-4": {ID: "TEST-CF-4", Document: doc, RepoName: "diagrams", Position: 7, Diagram: "overview.drawio"},
+		"TEST-CF-5": {ID: "TEST-CF-5", Document: doc, RepoName: "diagrams", Position: 8},
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportDown(rg, &buf))
+	html := buf.String()
+	assert.Contains(t, html, "<th>Diagram</th>")
+	assert.Regexp(t, `<td>TEST-CF-3</td>.*\s*<td><a href="#diagram-1">telemetry.puml</a></td>`, html)
+	assert.Contains(t, html, `<h3 id="diagram-1">telemetry.puml</h3>`)
+	assert.Contains(t, html, "Flow tags: TEST-CF-1 TEST-CF-3")
+	assert.Contains(t, html, `<pre class="flow-diagram">@startuml
+Sensor -&gt; Radio`)
+	assert.Contains(t, html, `<img class="flow-diagram" src="data:image/svg+xml;base64,PHN2Zy8+">`)
+	assert.Contains(t, html, `<a href="/code/diagrams/overview.drawio" target="_blank">Open the diagram</a>`)
+	assert.Equal(t, 3, strings.Count(html, "<h3 id=\"diagram-"))
+
+	// Without diagrams, the column is not shown
+	buf.Reset()
+	assert.NoError(t, ReportDown(&reqs.ReqGraph{FlowTags: map[string]*reqs.Flow{"TEST-CF-5": rg.FlowTags["TEST-CF-5"]}}, &buf))
+	assert.NotContains(t, buf.String(), "<th>Diagram</th>")
+}
+
+// @llr REQ-TRAQ-SWL-116
+func TestReport_IssuesByRepo(t *testing.T) {
+	rg := &reqs.ReqGraph{Issues: []diagnostics.Issue{
+		{RepoName: "projectB", Path: "TEST-137-SRD.md", Line: 3, Description: "Issue of B"},
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 5, Description: "Issue of A"},
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportIssues(rg, &buf, ""))
+	html := buf.String()
+	assert.Contains(t, html, `<a href="#projectA:TEST-138-SDD.md">TEST-138-SDD.md</a> (1)`)
+	assert.Contains(t, html, `<h2><a name="projectB"></a>projectB (1)</h2>`)
+	assert.Less(t, strings.Index(html, "Issue of A"), strings.Index(html, "Issue of B"))
+
+	buf.Reset()
+	assert.NoError(t, ReportIssues(&reqs.ReqGraph{}, &buf, ""))
+	assert.Contains(t, buf.String(), "No basic errors found.")
+}
+
+// @llr REQ-TRAQ-SWL-150
+func TestReport_IssueFilters(t *testing.T) {
+	issues := []diagnostics.Issue{
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 5, Description: "Missing parent",
+			Severity: diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeInvalidParent},
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 7, Description: "No shall",
+			Severity: diagnostics.IssueSeverityNote, Type: diagnostics.IssueTypeNoShallInBody},
+		{RepoName: "projectB", Path: "TEST-137-SRD.md", Line: 3, Description: "Another missing parent",
+			Severity: diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeInvalidParent},
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 9, Description: "No shall",
+			Severity: diagnostics.IssueSeverityNote, Type: diagnostics.IssueTypeNoShallInBody},
+	}
+	rg := &reqs.ReqGraph{Issues: issues}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportIssues(rg, &buf, ""))
+	html := buf.String()
+	assert.Contains(t, html, `<option value="error">error (2)</option>`)
+	assert.Contains(t, html, `<option value="note">note (2)</option>`)
+	assert.Less(t, strings.Index(html, `<option value="error">`), strings.Index(html, `<option value="note">`))
+	assert.Contains(t, html, `<option value="projectA:TEST-138-SDD.md">projectA:TEST-138-SDD.md (3)</option>`)
+	assert.Contains(t, html, `<option value="projectB">projectB (1)</option>`)
+
+	fingerprint := reqs.IssueFingerprint(issues[1])
+	_, code := diagnostics.TypeInfo(diagnostics.IssueTypeNoShallInBody)
+	assert.Contains(t, html, fmt.Sprintf(`<li class="issue" id="issue-%s-7" data-severity="note" data-type="%s" data-repo="projectA" data-document="projectA:TEST-138-SDD.md">`, fingerprint, code))
+	assert.Contains(t, html, fmt.Sprintf(`<a class="issue-anchor" href="#issue-%s-7" title="Link to this issue">#%s</a>`, fingerprint, fingerprint))
+
+	// The issues with the same fingerprint have their own anchors
+	assert.Equal(t, fingerprint, reqs.IssueFingerprint(issues[3]))
+	assert.Contains(t, html, fmt.Sprintf(`<li class="issue" id="issue-%s-9" data-severity="note"`, fingerprint))
+}
+
+// @llr REQ-TRAQ-SWL-142
+func TestReport_DocumentStats(t *testing.T) {
+	doc := config.Document{Path: "TEST-137-SRD.md"}
+	rg := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{
+			"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Title: "Logging", RepoName: "projectA", Document: &doc, Position: 3},
+		},
+		Issues: []diagnostics.Issue{
+			{RepoName: "projectA", Path: "TEST-137-SRD.md", Line: 3, Description: "Issue of SWH-1", Severity: diagnostics.IssueSeverityMinor},
+			{RepoName: "projectA", Path: "a.go", Line: 5, Description: "Issue of code"},
+		},
+		ReqtraqConfig: &config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+			"projectA": {Documents: []config.Document{doc}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportIssues(rg, &buf, ""))
+	html := buf.String()
+	// Only the sections of documents have a summary
+	assert.Equal(t, 1, strings.Count(html, `<div class="well well-sm">`))
+	assert.Less(t, strings.Index(html, `<a name="projectA:TEST-137-SRD.md">`), strings.Index(html, `<div class="well well-sm">`))
+	assert.Contains(t, html, "<strong>Issues:</strong> 0 errors, 1 warnings, 0 lint.")
+	assert.Contains(t, html, "<strong>Requirements:</strong> 1,\n\t\t0 implemented, 0 tested, 0 deleted.")
+}
+
+// @llr REQ-TRAQ-SWL-121
+func TestReport_OrdsByDocument(t *testing.T) {
+	ord := &config.Document{Path: "TEST-100-ORD.md"}
+	other := &config.Document{Path: "OTHER-100-ORD.md"}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{}}
+	for _, r := range []*reqs.Req{
+		{ID: "REQ-TEST-SYS-2", Title: "Second", RepoName: "projectA", Document: ord, Position: 1},
+		{ID: "REQ-TEST-SYS-1", Title: "First", RepoName: "projectA", Document: ord, Position: 7},
+		{ID: "REQ-OTHER-SYS-1", Title: "Other", RepoName: "projectA", Document: other, Position: 3},
+	} {
+		rg.Reqs[r.ID] = r
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportDown(rg, &buf))
+	html := buf.String()
+	assert.Less(t, strings.Index(html, "<h2>projectA: OTHER-100-ORD.md</h2>"), strings.Index(html, "<h2>projectA: TEST-100-ORD.md</h2>"))
+	assert.Less(t, strings.Index(html, "<h2>projectA: TEST-100-ORD.md</h2>"), strings.Index(html, "REQ-TEST-SYS-2"))
+	assert.Less(t, strings.Index(html, "REQ-TEST-SYS-2"), strings.Index(html, "REQ-TEST-SYS-1"))
+
+	buf.Reset()
+	assert.NoError(t, ReportDown(&reqs.ReqGraph{}, &buf))
+	assert.Contains(t, buf.String(), "Empty graph")
+}
+
+// @llr REQ-TRAQ-SWL-192
+func TestReport_IssueLinks(t *testing.T) {
+	doc := &config.Document{Path: "TEST-137-SRD.md"}
+	tag := &code.Code{CodeFile: code.CodeFile{RepoName: "projectA", Path: "log.go", Type: code.CodeTypeImplementation}, Tag: "Log", Line: 7,
+		Links: []code.ReqLink{{Id: "REQ-TEST-SWH-1"}}}
+	req := &reqs.Req{ID: "REQ-TEST-SWH-1", Title: "Logging", RepoName: "projectA", Document: doc, Position: 3, Tags: []*code.Code{tag}}
+	rg := &reqs.ReqGraph{
+		Reqs:     map[string]*reqs.Req{req.ID: req},
+		CodeTags: map[repos.RepoName][]*code.Code{"projectA": {tag}},
+		Issues: []diagnostics.Issue{
+			{RepoName: "projectA", Path: "TEST-137-SRD.md", Line: 3, Description: "Issue of SWH-1", Severity: diagnostics.IssueSeverityMinor},
+			{RepoName: "projectA", Path: "log.go", Line: 7, Description: "Issue of code", Severity: diagnostics.IssueSeverityMajor},
+			{RepoName: "projectA", Path: "TEST-137-SRD.md", Line: 20, Description: "Issue of document"},
+		},
+	}
+
+	// The requirements are only linked when the URL of the top down report is known
+	var buf bytes.Buffer
+	assert.NoError(t, ReportIssues(rg, &buf, ""))
+	assert.NotContains(t, buf.String(), `class="issue-requirement"`)
+	assert.Contains(t, buf.String(), `<a class="issue-code" href="/code/projectA/log.go#L7" target="_blank">log.go:7</a>`)
+
+	buf.Reset()
+	assert.NoError(t, ReportIssues(rg, &buf, "req-down.html"))
+	html := buf.String()
+	assert.Regexp(t, `Issue of SWH-1\s*<a class="issue-requirement" href="req-down.html#REQ-TEST-SWH-1">REQ-TEST-SWH-1</a>\s*<a class="issue-anchor"`, html)
+	assert.Regexp(t, `Issue of code\s*<a class="issue-requirement" href="req-down.html#REQ-TEST-SWH-1">REQ-TEST-SWH-1</a> <a class="issue-code"`, html)
+	assert.Regexp(t, `Issue of document\s*<a class="issue-anchor"`, html)
+
+	// The top down report lists the issues of each requirement under it
+	buf.Reset()
+	assert.NoError(t, ReportDown(rg, &buf))
+	html = buf.String()
+	assert.Contains(t, html, `<li class="requirement-issue"><span class="label label-warning">warning</span> Issue of SWH-1</li>`)
+	assert.Contains(t, html, `<li class="requirement-issue"><span class="label label-danger">error</span> Issue of code</li>`)
+	assert.NotContains(t, html, "Issue of document")
+
+	// The templates are cloned once for all the reports of the graph
+	tmpl, err := templatesOf(rg)
+	assert.NoError(t, err)
+	again, err := templatesOf(rg)
+	assert.NoError(t, err)
+	assert.Same(t, tmpl, again)
+}
+
+// @llr REQ-TRAQ-SWL-126
+func TestReport_AcceptanceCriteria(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	test := &code.Code{
+		CodeFile: code.CodeFile{RepoName: "projectA", Path: "log_test.go", Type: code.CodeTypeTests},
+		Tag:      "TestLogLevel",
+		Line:     12,
+		Links:    []code.ReqLink{{Id: "REQ-TEST-SWL-1", Criterion: "AC2"}},
+	}
+	req := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log level", Document: doc,
+		AcceptanceCriteria: []reqs.AcceptanceCriterion{{ID: "AC1", Text: "The default is info."}, {ID: "AC2", Text: "Debug can be enabled."}},
+		Tags:               []*code.Code{test}}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{req.ID: req}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportDown(rg, &buf))
+	html := buf.String()
+	assert.Regexp(t, `<strong>AC1</strong>: The default is info.\s*<span class="text-danger">not verified</span>`, html)
+	assert.Regexp(t, `<strong>AC2</strong>: Debug can be enabled.\s*<span class="text-success">verified by\s*<a href="/code/projectA/log_test.go#L12"`, html)
+}
+
+// @llr REQ-TRAQ-SWL-160
+func TestReport_UpCodeView(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	rotate := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log rotation", Document: doc}
+	level := &reqs.Req{ID: "REQ-TEST-SWL-2", Title: "Log level", Document: doc}
+	logFile := code.CodeFile{RepoName: "projectA", Path: "src/log/log.go", Type: code.CodeTypeImplementation}
+	rg := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{rotate.ID: rotate, level.ID: level},
+		CodeTags: map[repos.RepoName][]*code.Code{
+			"projectA": {
+				{CodeFile: logFile, Tag: "rotate", Line: 12, Links: []code.ReqLink{{Id: rotate.ID}}},
+				{CodeFile: logFile, Tag: "setLevel", Line: 30, Links: []code.ReqLink{{Id: level.ID}, {Id: rotate.ID}}},
+				{CodeFile: code.CodeFile{RepoName: "projectA", Path: "src/log/log_test.go", Type: code.CodeTypeTests}, Tag: "TestRotate", Line: 5, Links: []code.ReqLink{{Id: rotate.ID}}},
+			},
+			"projectB": {
+				{CodeFile: code.CodeFile{RepoName: "projectB", Path: "main.go", Type: code.CodeTypeImplementation}, Tag: "main", Line: 3, Links: []code.ReqLink{{Id: level.ID}}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportUp(rg, &buf))
+	html := buf.String()
+	assert.Contains(t, html, `<a href="/code/projectA/src/log/log.go#L12" target="_blank">Impl: projectA: src/log/log.go - rotate</a>`)
+	assert.Contains(t, html, "Test: projectA: src/log/log_test.go - TestRotate")
+	assert.Less(t, strings.Index(html, "src/log/log.go - rotate"), strings.Index(html, "projectB: main.go - main"))
+
+	buf.Reset()
+	assert.NoError(t, ReportUpCode(rg, &buf, nil, CodeView{RepoName: "projectA", PathGlob: "src/log", Type: code.CodeTypeImplementation, CollapseFiles: true}))
+	html = buf.String()
+	assert.Contains(t, html, `<a href="/code/projectA/src/log/log.go#L12" target="_blank">Impl: projectA: src/log/log.go - rotate, setLevel</a>`)
+	assert.Contains(t, html, "REQ-TEST-SWL-2 Log level")
+	assert.NotContains(t, html, "TestRotate")
+	assert.NotContains(t, html, "main.go")
+
+	buf.Reset()
+	assert.NoError(t, ReportUpCode(rg, &buf, nil, CodeView{PathGlob: "*.c", Type: code.CodeTypeAny}))
+	assert.Contains(t, buf.String(), "Empty graph")
+}
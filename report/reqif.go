@@ -0,0 +1,293 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// The namespace of the ReqIF 1.2 schema, whose files still declare version 1.0 in their header
+const reqifNamespace = "http://www.omg.org/spec/ReqIF/20110401/reqif.xsd"
+
+// The identifiers of the types shared by all the exported requirements
+const (
+	reqifStringType       = "DT-STRING"
+	reqifRequirementType  = "SOT-REQUIREMENT"
+	reqifParentType       = "SRT-PARENT"
+	reqifDocumentType     = "ST-DOCUMENT"
+	reqifIDAttribute      = "AD-ID"
+	reqifTitleAttribute   = "AD-TITLE"
+	reqifBodyAttribute    = "AD-BODY"
+	reqifAttributesPrefix = "AD-ATTR-"
+)
+
+// reReqIFInvalid matches the characters which are not allowed in the identifiers of the ReqIF elements
+var reReqIFInvalid = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// reqifIdentifiers assigns the identifiers of the ReqIF elements, which must be unique although different names can
+// have the same valid identifier, e.g. `SAFETY IMPACT` and `SAFETY_IMPACT`
+type reqifIdentifiers struct {
+	// The identifiers by prefix and name
+	assigned map[[2]string]string
+	used     map[string]bool
+}
+
+// identifier returns the identifier of the ReqIF element with the given prefix and name, made of the valid characters
+// of the name, followed by a numeric suffix if the identifier is already used by another element
+// @llr REQ-TRAQ-SWL-195
+func (ids *reqifIdentifiers) identifier(prefix, name string) string {
+	if ids.assigned == nil {
+		ids.assigned = make(map[[2]string]string)
+		ids.used = make(map[string]bool)
+	}
+	key := [2]string{prefix, name}
+	if identifier, ok := ids.assigned[key]; ok {
+		return identifier
+	}
+	base := prefix + reReqIFInvalid.ReplaceAllString(name, "_")
+	identifier := base
+	for suffix := 2; ids.used[identifier]; suffix++ {
+		identifier = fmt.Sprintf("%s-%d", base, suffix)
+	}
+	ids.assigned[key] = identifier
+	ids.used[identifier] = true
+	return identifier
+}
+
+type reqifFile struct {
+	XMLName xml.Name     `xml:"REQ-IF"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Header  reqifHeader  `xml:"THE-HEADER>REQ-IF-HEADER"`
+	Content reqifContent `xml:"CORE-CONTENT>REQ-IF-CONTENT"`
+}
+
+type reqifHeader struct {
+	Identifier   string `xml:"IDENTIFIER,attr"`
+	CreationTime string `xml:"CREATION-TIME"`
+	ReqIFToolID  string `xml:"REQ-IF-TOOL-ID"`
+	ReqIFVersion string `xml:"REQ-IF-VERSION"`
+	SourceToolID string `xml:"SOURCE-TOOL-ID"`
+	Title        string `xml:"TITLE"`
+}
+
+type reqifContent struct {
+	Datatypes      reqifDatatypes       `xml:"DATATYPES"`
+	SpecTypes      reqifSpecTypes       `xml:"SPEC-TYPES"`
+	SpecObjects    []reqifSpecObject    `xml:"SPEC-OBJECTS>SPEC-OBJECT"`
+	SpecRelations  []reqifSpecRelation  `xml:"SPEC-RELATIONS>SPEC-RELATION"`
+	Specifications []reqifSpecification `xml:"SPECIFICATIONS>SPECIFICATION"`
+}
+
+// reqifIdentifiable holds the attributes common to all the identifiable ReqIF elements
+type reqifIdentifiable struct {
+	Identifier string `xml:"IDENTIFIER,attr"`
+	LastChange string `xml:"LAST-CHANGE,attr"`
+	LongName   string `xml:"LONG-NAME,attr,omitempty"`
+}
+
+type reqifDatatypes struct {
+	Strings []reqifStringDatatype `xml:"DATATYPE-DEFINITION-STRING"`
+}
+
+type reqifStringDatatype struct {
+	reqifIdentifiable
+	MaxLength int `xml:"MAX-LENGTH,attr"`
+}
+
+type reqifSpecTypes struct {
+	SpecObjectTypes    []reqifSpecObjectType `xml:"SPEC-OBJECT-TYPE"`
+	SpecRelationTypes  []reqifIdentifiable   `xml:"SPEC-RELATION-TYPE"`
+	SpecificationTypes []reqifIdentifiable   `xml:"SPECIFICATION-TYPE"`
+}
+
+type reqifSpecObjectType struct {
+	reqifIdentifiable
+	Attributes []reqifAttributeDefinition `xml:"SPEC-ATTRIBUTES>ATTRIBUTE-DEFINITION-STRING"`
+}
+
+type reqifAttributeDefinition struct {
+	reqifIdentifiable
+	Type string `xml:"TYPE>DATATYPE-DEFINITION-STRING-REF"`
+}
+
+type reqifSpecObject struct {
+	reqifIdentifiable
+	Values []reqifAttributeValue `xml:"VALUES>ATTRIBUTE-VALUE-STRING"`
+	Type   string                `xml:"TYPE>SPEC-OBJECT-TYPE-REF"`
+}
+
+type reqifAttributeValue struct {
+	Value      string `xml:"THE-VALUE,attr"`
+	Definition string `xml:"DEFINITION>ATTRIBUTE-DEFINITION-STRING-REF"`
+}
+
+type reqifSpecRelation struct {
+	reqifIdentifiable
+	Source string `xml:"SOURCE>SPEC-OBJECT-REF"`
+	Target string `xml:"TARGET>SPEC-OBJECT-REF"`
+	Type   string `xml:"TYPE>SPEC-RELATION-TYPE-REF"`
+}
+
+type reqifSpecification struct {
+	reqifIdentifiable
+	Type     string               `xml:"TYPE>SPECIFICATION-TYPE-REF"`
+	Children []reqifSpecHierarchy `xml:"CHILDREN>SPEC-HIERARCHY"`
+}
+
+type reqifSpecHierarchy struct {
+	reqifIdentifiable
+	Object string `xml:"OBJECT>SPEC-OBJECT-REF"`
+}
+
+// WriteReqIF writes the requirements of all the configured documents which are not deleted as a ReqIF 1.2 file, to be
+// imported in requirement management tools. Each requirement is a specification object with its ID, title, body and
+// attributes as string values, the ID, title and body using the standard ReqIF.ForeignID, ReqIF.Name and ReqIF.Text
+// names. Each link to a parent is a specification relation from the child to the parent, and each document with
+// requirements is a specification listing them in the order they are defined. The given time is recorded as the creation
+// and last change time of all the elements.
+// @llr REQ-TRAQ-SWL-195
+func WriteReqIF(w io.Writer, rg *reqs.ReqGraph, created time.Time) error {
+	lastChange := created.Format(time.RFC3339)
+	var ids reqifIdentifiers
+	identifiable := func(identifier, longName string) reqifIdentifiable {
+		return reqifIdentifiable{Identifier: identifier, LastChange: lastChange, LongName: longName}
+	}
+
+	type documentKey struct {
+		repoName repos.RepoName
+		path     string
+	}
+	byDocument := make(map[documentKey][]*reqs.Req)
+	attributeNames := make(map[string]bool)
+	for _, r := range rg.Reqs {
+		if r.Stub || r.Document == nil || r.IsDeleted() {
+			continue
+		}
+		key := documentKey{r.RepoName, r.Document.Path}
+		byDocument[key] = append(byDocument[key], r)
+		for name := range r.Attributes {
+			if name != "PARENTS" {
+				attributeNames[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(attributeNames))
+	for name := range attributeNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	definitions := []reqifAttributeDefinition{
+		{identifiable(reqifIDAttribute, "ReqIF.ForeignID"), reqifStringType},
+		{identifiable(reqifTitleAttribute, "ReqIF.Name"), reqifStringType},
+		{identifiable(reqifBodyAttribute, "ReqIF.Text"), reqifStringType},
+	}
+	for _, name := range names {
+		definitions = append(definitions,
+			reqifAttributeDefinition{identifiable(ids.identifier(reqifAttributesPrefix, name), name), reqifStringType})
+	}
+
+	file := reqifFile{
+		Xmlns: reqifNamespace,
+		Header: reqifHeader{
+			Identifier:   "reqtraq-export",
+			CreationTime: lastChange,
+			ReqIFToolID:  "reqtraq",
+			ReqIFVersion: "1.0",
+			SourceToolID: "reqtraq",
+			Title:        "Requirements",
+		},
+		Content: reqifContent{
+			Datatypes: reqifDatatypes{Strings: []reqifStringDatatype{
+				{identifiable(reqifStringType, "String"), 1 << 20},
+			}},
+			SpecTypes: reqifSpecTypes{
+				SpecObjectTypes:    []reqifSpecObjectType{{identifiable(reqifRequirementType, "Requirement"), definitions}},
+				SpecRelationTypes:  []reqifIdentifiable{identifiable(reqifParentType, "Parent")},
+				SpecificationTypes: []reqifIdentifiable{identifiable(reqifDocumentType, "Document")},
+			},
+		},
+	}
+	if rg.ReqtraqConfig != nil {
+		file.Header.Title = "Requirements of " + string(rg.ReqtraqConfig.TargetRepo)
+	}
+
+	var keys []documentKey
+	for key := range byDocument {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].repoName != keys[j].repoName {
+			return keys[i].repoName < keys[j].repoName
+		}
+		return keys[i].path < keys[j].path
+	})
+	exported := make(map[string]bool)
+	var requirements []*reqs.Req
+	for _, key := range keys {
+		documentReqs := byDocument[key]
+		sort.Slice(documentReqs, func(i, j int) bool { return documentReqs[i].Position < documentReqs[j].Position })
+		specification := reqifSpecification{
+			reqifIdentifiable: identifiable(ids.identifier("DOC-", string(key.repoName)+"-"+key.path), key.path),
+			Type:              reqifDocumentType,
+		}
+		for _, r := range documentReqs {
+			exported[r.ID] = true
+			requirements = append(requirements, r)
+
+			object := reqifSpecObject{
+				reqifIdentifiable: identifiable(ids.identifier("SO-", r.ID), r.Title),
+				Type:              reqifRequirementType,
+				Values: []reqifAttributeValue{
+					{r.ID, reqifIDAttribute},
+					{r.Title, reqifTitleAttribute},
+					{strings.TrimSpace(r.Body), reqifBodyAttribute},
+				},
+			}
+			for _, name := range names {
+				if value, ok := r.Attributes[name]; ok {
+					object.Values = append(object.Values, reqifAttributeValue{value, ids.identifier(reqifAttributesPrefix, name)})
+				}
+			}
+			file.Content.SpecObjects = append(file.Content.SpecObjects, object)
+			specification.Children = append(specification.Children, reqifSpecHierarchy{
+				reqifIdentifiable: identifiable(ids.identifier("SH-", r.ID), ""),
+				Object:            object.Identifier,
+			})
+		}
+		file.Content.Specifications = append(file.Content.Specifications, specification)
+	}
+
+	// Only the links between exported requirements are written, the links to deleted or missing parents are dropped
+	for _, r := range requirements {
+		for _, parentID := range r.ParentIds {
+			if !exported[parentID] {
+				continue
+			}
+			file.Content.SpecRelations = append(file.Content.SpecRelations, reqifSpecRelation{
+				reqifIdentifiable: identifiable(ids.identifier("SR-", r.ID+"-"+parentID), ""),
+				Source:            ids.identifier("SO-", r.ID),
+				Target:            ids.identifier("SO-", parentID),
+				Type:              reqifParentType,
+			})
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
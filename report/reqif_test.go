@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// @llr REQ-TRAQ-SWL-195
+func TestWriteReqIF(t *testing.T) {
+	srd := config.Document{Path: "certdocs/TEST-137-SRD.md"}
+	sdd := config.Document{Path: "certdocs/TEST-138-SDD.md"}
+	rg := &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Title: "Logging", Body: "The tool SHALL log.\n", Document: &srd,
+			RepoName: "repo", Position: 3},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Title: "Log <rotation>", Body: "The logs SHALL be rotated\n\"daily\".",
+			Document: &sdd, RepoName: "repo", Position: 9, ParentIds: []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-9"},
+			Attributes: map[string]string{"PARENTS": "REQ-TEST-SWH-1, REQ-TEST-SWH-9", "SAFETY IMPACT": "None"}},
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Title: "Log format", Body: "The logs SHALL be JSON.", Document: &sdd,
+			RepoName: "repo", Position: 3, ParentIds: []string{"REQ-TEST-SWH-1"},
+			Attributes: map[string]string{"VERIFICATION": "Test", "SAFETY_IMPACT": "Low"}},
+		"REQ-TEST-SWL-3":  {ID: "REQ-TEST-SWL-3", Title: "DELETED", Document: &sdd, RepoName: "repo", Position: 15},
+		"REQ-OTHER-SWH-1": {ID: "REQ-OTHER-SWH-1", Stub: true},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteReqIF(&out, rg, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.Contains(t, out.String(), `<REQ-IF xmlns="http://www.omg.org/spec/ReqIF/20110401/reqif.xsd">`)
+	assert.Contains(t, out.String(), `THE-VALUE="The logs SHALL be rotated&#xA;&#34;daily&#34;."`)
+
+	var file reqifFile
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &file))
+	assert.Equal(t, "2024-01-02T03:04:05Z", file.Header.CreationTime)
+
+	var attributes []string
+	for _, definition := range file.Content.SpecTypes.SpecObjectTypes[0].Attributes {
+		attributes = append(attributes, definition.LongName)
+	}
+	assert.Equal(t, []string{"ReqIF.ForeignID", "ReqIF.Name", "ReqIF.Text", "SAFETY IMPACT", "SAFETY_IMPACT", "VERIFICATION"}, attributes)
+
+	// The names with the same valid identifier are given unique identifiers
+	assert.Equal(t, "AD-ATTR-SAFETY_IMPACT", file.Content.SpecTypes.SpecObjectTypes[0].Attributes[3].Identifier)
+	assert.Equal(t, "AD-ATTR-SAFETY_IMPACT-2", file.Content.SpecTypes.SpecObjectTypes[0].Attributes[4].Identifier)
+	assert.Equal(t, []reqifAttributeValue{
+		{"REQ-TEST-SWL-1", reqifIDAttribute},
+		{"Log format", reqifTitleAttribute},
+		{"The logs SHALL be JSON.", reqifBodyAttribute},
+		{"Low", "AD-ATTR-SAFETY_IMPACT-2"},
+		{"Test", "AD-ATTR-VERIFICATION"},
+	}, file.Content.SpecObjects[1].Values)
+
+	var objects []string
+	for _, object := range file.Content.SpecObjects {
+		objects = append(objects, object.Identifier)
+	}
+	assert.Equal(t, []string{"SO-REQ-TEST-SWH-1", "SO-REQ-TEST-SWL-1", "SO-REQ-TEST-SWL-2"}, objects)
+	assert.Equal(t, []reqifAttributeValue{
+		{"REQ-TEST-SWL-2", reqifIDAttribute},
+		{"Log <rotation>", reqifTitleAttribute},
+		{"The logs SHALL be rotated\n\"daily\".", reqifBodyAttribute},
+		{"None", "AD-ATTR-SAFETY_IMPACT"},
+	}, file.Content.SpecObjects[2].Values)
+
+	var relations [][2]string
+	for _, relation := range file.Content.SpecRelations {
+		relations = append(relations, [2]string{relation.Source, relation.Target})
+	}
+	assert.Equal(t, [][2]string{
+		{"SO-REQ-TEST-SWL-1", "SO-REQ-TEST-SWH-1"},
+		{"SO-REQ-TEST-SWL-2", "SO-REQ-TEST-SWH-1"},
+	}, relations)
+
+	require.Len(t, file.Content.Specifications, 2)
+	assert.Equal(t, "certdocs/TEST-138-SDD.md", file.Content.Specifications[1].LongName)
+	assert.Equal(t, "SO-REQ-TEST-SWL-1", file.Content.Specifications[1].Children[0].Object)
+	assert.Equal(t, "SO-REQ-TEST-SWL-2", file.Content.Specifications[1].Children[1].Object)
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+// A project specific section rendered below each requirement of the HTML reports, e.g. links to simulation
+// dashboards keyed by requirement ID. Sections rendering nothing for a requirement are omitted.
+type RequirementSection interface {
+	Render(r *reqs.Req) (template.HTML, error)
+}
+
+// The requirement sections by name. Sections are registered by the init functions of packages compiled in with build
+// tags or of the plugins listed in the configuration, and by the templates listed in the configuration.
+var requirementSections = map[string]RequirementSection{}
+
+// RegisterRequirementSection registers a requirement section with the given name, replacing any section with the same
+// name
+// @llr REQ-TRAQ-SWL-161
+func RegisterRequirementSection(name string, section RequirementSection) {
+	requirementSections[name] = section
+}
+
+// RegisteredRequirementSections returns the names of the registered requirement sections, sorted
+// @llr REQ-TRAQ-SWL-161
+func RegisteredRequirementSections() []string {
+	names := make([]string, 0, len(requirementSections))
+	for name := range requirementSections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateSection is a requirement section rendered by a HTML template declared in the configuration, executed with
+// the requirement
+type templateSection struct {
+	tmpl *template.Template
+}
+
+// Render executes the template with the requirement
+// @llr REQ-TRAQ-SWL-161
+func (s templateSection) Render(r *reqs.Req) (template.HTML, error) {
+	var out strings.Builder
+	if err := s.tmpl.Execute(&out, r); err != nil {
+		return "", err
+	}
+	return template.HTML(out.String()), nil
+}
+
+// The functions available to the requirement templates besides the built-in ones
+var sectionFunctionMap = template.FuncMap{
+	"attribute": func(r *reqs.Req, name string) string {
+		value, _ := r.Attribute(strings.ToUpper(name))
+		return value
+	},
+}
+
+// LoadRequirementTemplates parses the requirement templates declared in the configuration and registers them as
+// requirement sections named after the repository and the path declaring them. Relative paths are resolved in the
+// repository declaring the template.
+// @llr REQ-TRAQ-SWL-161
+func LoadRequirementTemplates(templates []config.RequirementTemplate) error {
+	for _, t := range templates {
+		path := t.Path
+		if !filepath.IsAbs(path) {
+			var err error
+			if path, err = repos.PathInRepo(t.RepoName, t.Path); err != nil {
+				return errors.Wrapf(err, "Requirement template `%s` declared in config for repo `%s` cannot be found", t.Path, t.RepoName)
+			}
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "Requirement template `%s` declared in config for repo `%s` cannot be read", t.Path, t.RepoName)
+		}
+		tmpl, err := template.New(t.Path).Funcs(sectionFunctionMap).Parse(string(text))
+		if err != nil {
+			return errors.Wrapf(err, "Requirement template `%s` declared in config for repo `%s` cannot be parsed", t.Path, t.RepoName)
+		}
+		RegisterRequirementSection(string(t.RepoName)+":"+t.Path, templateSection{tmpl: tmpl})
+	}
+	return nil
+}
+
+// renderRequirementSections renders the registered requirement sections of the requirement, in the order of their
+// names, each in its own div
+// @llr REQ-TRAQ-SWL-161
+func renderRequirementSections(r *reqs.Req) (template.HTML, error) {
+	var out strings.Builder
+	for _, name := range RegisteredRequirementSections() {
+		html, err := requirementSections[name].Render(r)
+		if err != nil {
+			return "", errors.Wrapf(err, "render section `%s` of requirement %s", name, r.ID)
+		}
+		if strings.TrimSpace(string(html)) == "" {
+			continue
+		}
+		out.WriteString(`<div class="requirement-section" data-section="`)
+		out.WriteString(template.HTMLEscapeString(name))
+		out.WriteString(`">`)
+		out.WriteString(string(html))
+		out.WriteString("</div>\n")
+	}
+	return template.HTML(out.String()), nil
+}
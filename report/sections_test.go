@@ -0,0 +1,42 @@
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// emptySection renders nothing for any requirement
+type emptySection struct{}
+
+// @llr REQ-TRAQ-SWL-161
+func (emptySection) Render(r *reqs.Req) (template.HTML, error) {
+	return "", nil
+}
+
+// @llr REQ-TRAQ-SWL-161
+func TestReport_RequirementSections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dashboard.html")
+	assert.NoError(t, os.WriteFile(path, []byte(`<a href="https://sim.example.com/{{ .ID }}">Simulations of {{ attribute . "Verification" }}</a>`), 0644))
+	assert.NoError(t, LoadRequirementTemplates([]config.RequirementTemplate{{Path: path, RepoName: "repo"}}))
+	defer delete(requirementSections, "repo:"+path)
+	RegisterRequirementSection("empty", emptySection{})
+	defer delete(requirementSections, "empty")
+	assert.Equal(t, []string{"empty", "repo:" + path}, RegisteredRequirementSections())
+
+	req := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log rotation", Document: &config.Document{Path: "TEST-138-SDD.md"},
+		Attributes: map[string]string{"VERIFICATION": "Test"}}
+	var buf bytes.Buffer
+	assert.NoError(t, ReportDown(&reqs.ReqGraph{Reqs: map[string]*reqs.Req{req.ID: req}}, &buf))
+	html := buf.String()
+	assert.Contains(t, html, `<div class="requirement-section" data-section="repo:`+path+`"><a href="https://sim.example.com/REQ-TEST-SWL-1">Simulations of Test</a></div>`)
+	assert.NotContains(t, html, `data-section="empty"`)
+
+	assert.Error(t, LoadRequirementTemplates([]config.RequirementTemplate{{Path: filepath.Join(t.TempDir(), "missing.html"), RepoName: "repo"}}))
+}
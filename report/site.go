@@ -0,0 +1,369 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// The formats of the published sites
+const (
+	// Plain HTML pages, which can be served as they are
+	SiteFormatHTML = "html"
+	// A MkDocs project, with the markdown pages in the docs directory and the navigation in mkdocs.yml
+	SiteFormatMkDocs = "mkdocs"
+)
+
+// The name of the script holding the search index of the HTML sites
+const siteSearchScriptName = "search-index.js"
+
+// Characters which are not kept in the names of the pages of the documents
+var reSitePageSeparators = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Site publishes the requirements as a static documentation site, with one page per document and per requirement,
+// a navigation sidebar and a search, to be deployed to a pages server.
+type Site struct {
+	// The title of the site
+	Title string
+	// The format of the site, SiteFormatHTML or SiteFormatMkDocs
+	Format string
+	// Template of the links to the source files, as for MarkdownPages
+	SourceURL string
+}
+
+// sitePage is a page of an HTML site: the index page, the page of a document or the page of a requirement
+type sitePage struct {
+	Title     string
+	Documents []*markdownDocument
+	// The document of the page, nil for the index page
+	Document *markdownDocument
+	// The requirement of the page, nil for the index and document pages
+	Req *reqs.Req
+}
+
+// Publish writes the site of the requirements of the graph which are not deleted to the given directory
+// @llr REQ-TRAQ-SWL-151
+func (s Site) Publish(rg *reqs.ReqGraph, dir string) error {
+	switch s.Format {
+	case SiteFormatHTML:
+		return s.publishHTML(rg, dir)
+	case SiteFormatMkDocs:
+		return s.publishMkDocs(rg, dir)
+	}
+	return fmt.Errorf("Unknown site format `%s`, expected `%s` or `%s`", s.Format, SiteFormatHTML, SiteFormatMkDocs)
+}
+
+// documentPageName returns the name of the page of a document, with the given extension
+// @llr REQ-TRAQ-SWL-151
+func documentPageName(document *markdownDocument, extension string) string {
+	name := fmt.Sprintf("%s-%s", document.RepoName, strings.TrimSuffix(document.Path, ".md"))
+	return "doc-" + strings.Trim(reSitePageSeparators.ReplaceAllString(name, "_"), "_") + extension
+}
+
+// htmlPageName returns the name of the HTML page of a requirement
+// @llr REQ-TRAQ-SWL-151
+func htmlPageName(r *reqs.Req) string {
+	return reqPageBaseName(r) + ".html"
+}
+
+// publishMkDocs writes the markdown pages of the requirements and of the documents to the docs directory, and the
+// mkdocs.yml configuration with the navigation by document and the search plugin
+// @llr REQ-TRAQ-SWL-151
+func (s Site) publishMkDocs(rg *reqs.ReqGraph, dir string) error {
+	docsDir := filepath.Join(dir, "docs")
+	pages := MarkdownPages{SourceURL: s.SourceURL}
+	if err := pages.Export(rg, docsDir); err != nil {
+		return err
+	}
+
+	tmpl := pages.template()
+	documents := groupByDocument(sortedLiveReqs(rg))
+	for _, document := range documents {
+		if err := writeMarkdownPage(tmpl, filepath.Join(docsDir, documentPageName(document, ".md")), "DOCUMENT", document); err != nil {
+			return err
+		}
+	}
+
+	// Strings are quoted as JSON, which is valid YAML
+	quote := func(value string) string {
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	}
+	var config strings.Builder
+	fmt.Fprintf(&config, "site_name: %s\ndocs_dir: docs\nuse_directory_urls: false\nplugins:\n  - search\nnav:\n", quote(s.Title))
+	fmt.Fprintf(&config, "  - Home: %s\n", markdownIndexName)
+	for _, document := range documents {
+		fmt.Fprintf(&config, "  - %s:\n", quote(fmt.Sprintf("%s (%s)", document.Path, document.RepoName)))
+		fmt.Fprintf(&config, "    - Overview: %s\n", documentPageName(document, ".md"))
+		for _, r := range document.Reqs {
+			fmt.Fprintf(&config, "    - %s: %s\n", quote(fmt.Sprintf("%s %s", r.ID, r.Title)), markdownPageName(r))
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, "mkdocs.yml"), []byte(config.String()), 0644)
+}
+
+// publishHTML writes the HTML pages of the index, of the documents and of the requirements, and the search index
+// loaded by the search box of the pages
+// @llr REQ-TRAQ-SWL-151, REQ-TRAQ-SWL-178
+func (s Site) publishHTML(rg *reqs.ReqGraph, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	renderBodies(rg)
+
+	requirements := sortedLiveReqs(rg)
+	if err := checkPageNames(requirements, htmlPageName); err != nil {
+		return err
+	}
+	documents := groupByDocument(requirements)
+	tmpl := s.template()
+	write := func(name string, page sitePage) error {
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := tmpl.ExecuteTemplate(file, "SITEPAGE", page); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}
+
+	if err := write("index.html", sitePage{Title: s.Title, Documents: documents}); err != nil {
+		return err
+	}
+	for _, document := range documents {
+		if err := write(documentPageName(document, ".html"), sitePage{Title: s.Title, Documents: documents, Document: document}); err != nil {
+			return err
+		}
+		for _, r := range document.Reqs {
+			if err := write(htmlPageName(r), sitePage{Title: s.Title, Documents: documents, Document: document, Req: r}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The index is loaded as a script rather than fetched, so the search also works with pages opened from disk
+	content, err := json.Marshal(searchEntries(requirements, htmlPageName))
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf("var searchIndex = %s;\n", content)
+	return os.WriteFile(filepath.Join(dir, siteSearchScriptName), []byte(script), 0644)
+}
+
+// template returns the templates of the HTML pages, with the functions depending on the site settings
+// @llr REQ-TRAQ-SWL-151
+func (s Site) template() *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{
+		"formatBodyAsHTML": formatBodyAsHTML,
+		"documentPage":     func(document *markdownDocument) string { return documentPageName(document, ".html") },
+		"pageName":         htmlPageName,
+		"attributes":       sortedAttributes,
+		"sourceLink":       s.sourceLink,
+		"isImpl":           isImpl,
+		"isTest":           isTest,
+		"live":             liveReqs,
+		"sections":         renderRequirementSections,
+	}).Parse(siteTmplText))
+}
+
+// sourceLink returns the location of a line in a file of a repository, as a link if a source URL template is
+// configured
+// @llr REQ-TRAQ-SWL-151
+func (s Site) sourceLink(repoName repos.RepoName, path string, line int) template.HTML {
+	location := fmt.Sprintf("<code>%s</code>", template.HTMLEscapeString(fmt.Sprintf("%s:%s:%d", repoName, path, line)))
+	if s.SourceURL == "" {
+		return template.HTML(location)
+	}
+	url := template.HTMLEscapeString(sourceURL(s.SourceURL, repoName, path, line))
+	return template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, url, location))
+}
+
+var siteTmplText = `
+{{- define "SITEPAGE" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{ if .Req }}{{ .Req.ID }} {{ .Req.Title }} - {{ else if .Document }}{{ .Document.Path }} - {{ end }}{{ .Title }}</title>
+	<style>
+		body {
+			font-family: Roboto, Arial, sans-serif;
+			margin: 0;
+			display: flex;
+		}
+		nav {
+			width: 22em;
+			flex-shrink: 0;
+			height: 100vh;
+			overflow-y: auto;
+			position: sticky;
+			top: 0;
+			padding: 1em;
+			box-sizing: border-box;
+			background-color: #f5f5f5;
+			border-right: 1px solid #ddd;
+			font-size: 0.9em;
+		}
+		nav ul {
+			padding-left: 1em;
+		}
+		nav .current {
+			font-weight: bold;
+		}
+		nav input {
+			width: 100%;
+			box-sizing: border-box;
+		}
+		main {
+			padding: 1em 2em;
+			max-width: 60em;
+		}
+		a {
+			text-decoration: none;
+		}
+		table {
+			border-collapse: collapse;
+		}
+		td, th {
+			border: 1px solid #ddd;
+			padding: 0.2em 0.5em;
+			text-align: left;
+		}
+	</style>
+	<script src="search-index.js"></script>
+</head>
+<body>
+	<nav>
+		<h3><a href="index.html">{{ .Title }}</a></h3>
+		<input id="search" type="search" placeholder="Search requirements">
+		<ul id="search-results"></ul>
+		<ul>
+		{{- $current := .Document }}
+		{{- $currentReq := .Req }}
+		{{- range .Documents }}
+			<li><a href="{{ documentPage . }}"{{ if eq . $current }} class="current"{{ end }}>{{ .Path }}</a> ({{ .RepoName }})
+			{{- if eq . $current }}
+				<ul>
+				{{- range .Reqs }}
+					<li><a href="{{ pageName . }}"{{ if eq . $currentReq }} class="current"{{ end }}>{{ .ID }}</a> {{ .Title }}</li>
+				{{- end }}
+				</ul>
+			{{- end }}
+			</li>
+		{{- end }}
+		</ul>
+	</nav>
+	<main>
+	{{- if .Req }}
+		{{ template "SITEREQ" .Req }}
+	{{- else if .Document }}
+		<h1>{{ .Document.Path }}</h1>
+		<p>Repository: {{ .Document.RepoName }}</p>
+		<table>
+			<tr><th>ID</th><th>Title</th></tr>
+		{{- range .Document.Reqs }}
+			<tr><td><a href="{{ pageName . }}">{{ .ID }}</a></td><td>{{ .Title }}</td></tr>
+		{{- end }}
+		</table>
+	{{- else }}
+		<h1>{{ .Title }}</h1>
+		<table>
+			<tr><th>Document</th><th>Repository</th><th>Requirements</th></tr>
+		{{- range .Documents }}
+			<tr><td><a href="{{ documentPage . }}">{{ .Path }}</a></td><td>{{ .RepoName }}</td><td>{{ len .Reqs }}</td></tr>
+		{{- end }}
+		</table>
+	{{- end }}
+	</main>
+	<script>
+		(function() {
+			var input = document.getElementById("search");
+			var results = document.getElementById("search-results");
+			// Lists the requirements whose ID, title or body contain all the words of the query
+			input.addEventListener("input", function() {
+				results.innerHTML = "";
+				var words = input.value.toLowerCase().split(/\s+/).filter(function(word) { return word !== ""; });
+				if (words.length === 0 || typeof searchIndex === "undefined") {
+					return;
+				}
+				var matches = searchIndex.filter(function(entry) {
+					var text = (entry.id + " " + entry.title + " " + entry.body).toLowerCase();
+					return words.every(function(word) { return text.indexOf(word) !== -1; });
+				});
+				matches.slice(0, 50).forEach(function(entry) {
+					var item = document.createElement("li");
+					var link = document.createElement("a");
+					link.href = entry.url;
+					link.textContent = entry.id + " " + entry.title;
+					item.appendChild(link);
+					results.appendChild(item);
+				});
+				if (matches.length === 0) {
+					results.textContent = "No requirement found.";
+				}
+			});
+		})();
+	</script>
+</body>
+</html>
+{{ end -}}
+
+{{- define "SITEREQ" -}}
+<h1>{{ .ID }} {{ .Title }}</h1>
+<p>Defined in {{ sourceLink .RepoName .Document.Path .Position }}</p>
+{{- if .Body }}
+{{ formatBodyAsHTML .Body }}
+{{- end }}
+{{- with attributes . }}
+<h2>Attributes</h2>
+<table>
+	<tr><th>Attribute</th><th>Value</th></tr>
+{{- range . }}
+	<tr><td>{{ if .Computed }}<em>{{ .Name }}</em>{{ else }}{{ .Name }}{{ end }}</td><td>{{ .Value }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{ sections . }}
+{{- with live .Parents }}
+<h2>Parents</h2>
+<ul>
+{{- range . }}
+	<li><a href="{{ pageName . }}">{{ .ID }}</a> {{ .Title }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- with live .Children }}
+<h2>Children</h2>
+<ul>
+{{- range . }}
+	<li><a href="{{ pageName . }}">{{ .ID }}</a> {{ .Title }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Tags }}
+<h2>Code</h2>
+<ul>
+{{- range .Tags }}
+{{- if isImpl .CodeFile }}
+	<li>{{ if $.IsAssumption }}Assumption check{{ else }}Implementation{{ end }}: {{ .Tag }} in {{ sourceLink .CodeFile.RepoName .CodeFile.Path .Line }}</li>
+{{- end }}
+{{- end }}
+{{- range .Tags }}
+{{- if isTest .CodeFile }}
+	<li>{{ if $.IsAssumption }}Assumption check test{{ else }}Test{{ end }}: {{ .Tag }} in {{ sourceLink .CodeFile.RepoName .CodeFile.Path .Line }}</li>
+{{- end }}
+{{- end }}
+</ul>
+{{- end }}
+{{ end -}}
+`
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// siteTestGraph returns a graph of a requirement and its child, without bodies so the pages are rendered without
+// pandoc, and a deleted requirement
+// @llr REQ-TRAQ-SWL-151
+func siteTestGraph() *reqs.ReqGraph {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	swh := &reqs.Req{ID: "REQ-TEST-SWH-1", Title: "Logging", Document: &srd, RepoName: "repo", Position: 3}
+	swl := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log rotation", Document: &sdd, RepoName: "repo", Position: 7,
+		ParentIds: []string{"REQ-TEST-SWH-1"}, Attributes: map[string]string{"VERIFICATION": "Test"}}
+	deleted := &reqs.Req{ID: "REQ-TEST-SWL-2", Title: "DELETED", Document: &sdd, RepoName: "repo", Position: 12}
+	swh.Children = []*reqs.Req{swl, deleted}
+	swl.Parents = []*reqs.Req{swh}
+	return &reqs.ReqGraph{Reqs: map[string]*reqs.Req{swh.ID: swh, swl.ID: swl, deleted.ID: deleted}}
+}
+
+// @llr REQ-TRAQ-SWL-151
+func TestSite_PublishHTML(t *testing.T) {
+	dir := t.TempDir()
+	site := Site{Title: "Test site", Format: SiteFormatHTML, SourceURL: "https://git.example.com/{repo}/{path}#L{line}"}
+	assert.NoError(t, site.Publish(siteTestGraph(), dir))
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(content)
+	}
+	index := read("index.html")
+	assert.Contains(t, index, `<tr><td><a href="doc-repo-TEST-137-SRD.html">TEST-137-SRD.md</a></td><td>repo</td><td>1</td></tr>`)
+	assert.NotContains(t, index, `class="current"`)
+
+	document := read("doc-repo-TEST-138-SDD.html")
+	assert.Contains(t, document, `<a href="doc-repo-TEST-138-SDD.html" class="current">TEST-138-SDD.md</a>`)
+	assert.Contains(t, document, `<tr><td><a href="REQ-TEST-SWL-1.html">REQ-TEST-SWL-1</a></td><td>Log rotation</td></tr>`)
+
+	page := read("REQ-TEST-SWL-1.html")
+	assert.Contains(t, page, `<a href="REQ-TEST-SWL-1.html" class="current">REQ-TEST-SWL-1</a>`)
+	assert.Contains(t, page, `Defined in <a href="https://git.example.com/repo/TEST-138-SDD.md#L7"><code>repo:TEST-138-SDD.md:7</code></a>`)
+	assert.Contains(t, page, `<tr><td>VERIFICATION</td><td>Test</td></tr>`)
+	assert.Contains(t, page, `<li><a href="REQ-TEST-SWH-1.html">REQ-TEST-SWH-1</a> Logging</li>`)
+	assert.NoFileExists(t, filepath.Join(dir, "REQ-TEST-SWL-2.html"))
+
+	assert.Contains(t, read(siteSearchScriptName), `"id":"REQ-TEST-SWL-1","title":"Log rotation"`)
+	assert.Contains(t, read(siteSearchScriptName), `"url":"REQ-TEST-SWL-1.html"`)
+}
+
+// @llr REQ-TRAQ-SWL-151
+func TestSite_PublishMkDocs(t *testing.T) {
+	dir := t.TempDir()
+	site := Site{Title: "Test site", Format: SiteFormatMkDocs}
+	assert.NoError(t, site.Publish(siteTestGraph(), dir))
+
+	mkdocs, err := os.ReadFile(filepath.Join(dir, "mkdocs.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "site_name: \"Test site\"\ndocs_dir: docs\nuse_directory_urls: false\nplugins:\n  - search\nnav:\n"+
+		"  - Home: index.md\n"+
+		"  - \"TEST-137-SRD.md (repo)\":\n    - Overview: doc-repo-TEST-137-SRD.md\n    - \"REQ-TEST-SWH-1 Logging\": REQ-TEST-SWH-1.md\n"+
+		"  - \"TEST-138-SDD.md (repo)\":\n    - Overview: doc-repo-TEST-138-SDD.md\n    - \"REQ-TEST-SWL-1 Log rotation\": REQ-TEST-SWL-1.md\n",
+		string(mkdocs))
+
+	document, err := os.ReadFile(filepath.Join(dir, "docs", "doc-repo-TEST-138-SDD.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# TEST-138-SDD.md\n\nRepository: repo\n\n- [REQ-TEST-SWL-1 Log rotation](REQ-TEST-SWL-1.md)\n", string(document))
+	assert.FileExists(t, filepath.Join(dir, "docs", "REQ-TEST-SWL-1.md"))
+	assert.FileExists(t, filepath.Join(dir, "docs", searchIndexName))
+
+	assert.Error(t, Site{Format: "hugo"}.Publish(siteTestGraph(), t.TempDir()))
+}
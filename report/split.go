@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// IssueGroup holds the issues of the requirements sharing the same value of an attribute
+type IssueGroup struct {
+	// Value of the attribute, empty for issues which cannot be attributed to a requirement with the attribute
+	Value  string
+	Issues []diagnostics.Issue
+}
+
+// SplitIssuesByAttribute groups the issues of the requirements graph by the value of the given attribute in
+// the requirements they affect. Issues affecting requirements with different values are part of each group.
+// Groups are sorted by value, with the group of issues without value last.
+// @llr REQ-TRAQ-SWL-98
+func SplitIssuesByAttribute(rg *reqs.ReqGraph, attribute string) []IssueGroup {
+	attribute = strings.ToUpper(attribute)
+	index := rg.IssueRequirements()
+
+	issuesByValue := make(map[string][]diagnostics.Issue)
+	for _, issue := range rg.Issues {
+		values := make(map[string]bool)
+		for _, r := range index[reqs.LocationOf(issue)] {
+			if value, ok := r.Attribute(attribute); ok && value != "" {
+				values[value] = true
+			}
+		}
+		if len(values) == 0 {
+			values[""] = true
+		}
+		for value := range values {
+			issuesByValue[value] = append(issuesByValue[value], issue)
+		}
+	}
+
+	groups := make([]IssueGroup, 0, len(issuesByValue))
+	for value, issues := range issuesByValue {
+		groups = append(groups, IssueGroup{Value: value, Issues: issues})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Value == "" || groups[j].Value == "" {
+			return groups[j].Value == ""
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	return groups
+}
+
+// ReportIssuesGroup generates a HTML report showing the issues of a group split by the given attribute, linking the
+// requirements of the issues to the top down report at the given URL unless empty.
+// @llr REQ-TRAQ-SWL-98, REQ-TRAQ-SWL-192
+func ReportIssuesGroup(rg *reqs.ReqGraph, w io.Writer, attribute string, group IssueGroup, topDownURL string) error {
+	groupGraph := *rg
+	groupGraph.Issues = group.Issues
+	filter := reqs.ReqFilter{AttributeRegexp: map[string]*regexp.Regexp{
+		strings.ToUpper(attribute): regexp.MustCompile(fmt.Sprintf("^%s$", regexp.QuoteMeta(group.Value))),
+	}}
+	data := newReportData(&groupGraph, &filter)
+	data.TopDownURL = topDownURL
+	return executeReport(&groupGraph, w, "ISSUESFILT", data)
+}
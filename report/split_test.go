@@ -0,0 +1,60 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-98
+func TestReport_SplitIssuesByAttribute(t *testing.T) {
+	doc := config.Document{Path: "TEST-138-SDD.md"}
+	rg := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{
+			"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Document: &doc, RepoName: "repo", Position: 10,
+				Attributes: map[string]string{"COMPONENT ALLOCATION": "Flight Control"}},
+			"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Document: &doc, RepoName: "repo", Position: 20,
+				Attributes: map[string]string{"COMPONENT ALLOCATION": "Navigation"}},
+			"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", Document: &doc, RepoName: "repo", Position: 30},
+		},
+		CodeTags: map[repos.RepoName][]*code.Code{
+			"repo": {
+				{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Line: 5,
+					Links: []code.ReqLink{{Id: "REQ-TEST-SWL-1"}, {Id: "REQ-TEST-SWL-2"}}},
+			},
+		},
+		Issues: []diagnostics.Issue{
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 10, Description: "first"},
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 20, Description: "second"},
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 30, Description: "third"},
+			{RepoName: "repo", Path: "a.go", Line: 5, Description: "code"},
+			{RepoName: "repo", Path: "b.go", Line: 1, Description: "unknown"},
+		},
+	}
+
+	groups := SplitIssuesByAttribute(rg, "Component Allocation")
+	descriptions := map[string][]string{}
+	var values []string
+	for _, group := range groups {
+		values = append(values, group.Value)
+		for _, issue := range group.Issues {
+			descriptions[group.Value] = append(descriptions[group.Value], issue.Description)
+		}
+	}
+	assert.Equal(t, []string{"Flight Control", "Navigation", ""}, values)
+	assert.Equal(t, []string{"first", "code"}, descriptions["Flight Control"])
+	assert.Equal(t, []string{"second", "code"}, descriptions["Navigation"])
+	assert.Equal(t, []string{"third", "unknown"}, descriptions[""])
+
+	var buf bytes.Buffer
+	assert.NoError(t, ReportIssuesGroup(rg, &buf, "Component Allocation", groups[1], ""))
+	assert.Contains(t, buf.String(), "second")
+	assert.NotContains(t, buf.String(), "first")
+	assert.Contains(t, buf.String(), "^Navigation$")
+}
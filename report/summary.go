@@ -0,0 +1,194 @@
+package report
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"math"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// SummaryRow holds the coverage figures of the requirements of a document, of a requirement level or of all the
+// documents. The percentages are computed over the requirements which are neither deleted nor assumptions.
+type SummaryRow struct {
+	RepoName repos.RepoName  `json:"repoName,omitempty"`
+	Path     string          `json:"path,omitempty"`
+	Level    config.ReqLevel `json:"level,omitempty"`
+	// The requirements which are not deleted, assumptions included
+	Requirements int     `json:"requirements"`
+	Implemented  float64 `json:"implementedPercent"`
+	Tested       float64 `json:"testedPercent"`
+	WithParents  float64 `json:"withParentsPercent"`
+	Deleted      int     `json:"deleted"`
+	// The number of issues, lint messages included
+	Issues int `json:"openIssues"`
+
+	assumptions, implemented, tested, withParents int
+}
+
+// Summary holds the coverage figures of each document, of each requirement level, in the order the levels first
+// appear in the documents, and of all the documents
+type Summary struct {
+	Documents []SummaryRow `json:"documents"`
+	Levels    []SummaryRow `json:"levels"`
+	Total     SummaryRow   `json:"total"`
+}
+
+// add counts the requirements and the issues of a document in the row
+// @llr REQ-TRAQ-SWL-190
+func (row *SummaryRow) add(stats reqs.DocumentStats) {
+	row.Requirements += stats.Requirements
+	row.Deleted += stats.Deleted
+	row.Issues += stats.Errors + stats.Warnings + stats.Lint
+	row.assumptions += stats.Assumptions
+	row.implemented += stats.Implemented
+	row.tested += stats.Tested
+	row.withParents += stats.WithParents
+}
+
+// computePercentages computes the percentages of the row from the counts of its requirements
+// @llr REQ-TRAQ-SWL-190
+func (row *SummaryRow) computePercentages() {
+	percent := func(part int) float64 {
+		total := row.Requirements - row.assumptions
+		if total == 0 {
+			return 100
+		}
+		return math.Round(float64(part)*1000/float64(total)) / 10
+	}
+	row.Implemented = percent(row.implemented)
+	row.Tested = percent(row.tested)
+	row.WithParents = percent(row.withParents)
+}
+
+// ComputeSummary returns the coverage figures of the documents of the requirements graph. The requirements are
+// implemented and tested as counted for the completeness score.
+// @llr REQ-TRAQ-SWL-190
+func ComputeSummary(rg *reqs.ReqGraph) Summary {
+	levels := make(map[reqs.IssueLocation]config.ReqLevel)
+	if rg.ReqtraqConfig != nil {
+		for repoName, repo := range rg.ReqtraqConfig.Repos {
+			for _, doc := range repo.Documents {
+				levels[reqs.IssueLocation{RepoName: repoName, Path: doc.Path}] = doc.ReqSpec.Level
+			}
+		}
+	}
+
+	summary := Summary{}
+	byLevel := make(map[config.ReqLevel]int)
+	for _, stats := range rg.DocumentStats() {
+		level := levels[reqs.IssueLocation{RepoName: stats.RepoName, Path: stats.Path}]
+		row := SummaryRow{RepoName: stats.RepoName, Path: stats.Path, Level: level}
+		row.add(stats)
+		summary.Documents = append(summary.Documents, row)
+
+		idx, ok := byLevel[level]
+		if !ok {
+			idx = len(summary.Levels)
+			byLevel[level] = idx
+			summary.Levels = append(summary.Levels, SummaryRow{Level: level})
+		}
+		summary.Levels[idx].add(stats)
+		summary.Total.add(stats)
+	}
+	for i := range summary.Documents {
+		summary.Documents[i].computePercentages()
+	}
+	for i := range summary.Levels {
+		summary.Levels[i].computePercentages()
+	}
+	summary.Total.computePercentages()
+	return summary
+}
+
+// WriteSummaryJSON writes the coverage figures as an indented JSON object
+// @llr REQ-TRAQ-SWL-190
+func WriteSummaryJSON(summary Summary, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
+
+// ReportSummary generates a HTML report with the coverage figures of each document, of each requirement level and
+// of all the documents
+// @llr REQ-TRAQ-SWL-190
+func ReportSummary(summary Summary, w io.Writer) error {
+	return summaryTmpl.ExecuteTemplate(w, "SUMMARY", summary)
+}
+
+var summaryTmpl = template.Must(template.Must(template.New("").Parse(headerFooterTmplText)).Parse(summaryTmplText))
+
+var summaryTmplText = `
+{{ define "SUMMARY_HEAD" }}
+	<th>Requirements</th>
+	<th>Implemented</th>
+	<th>Tested</th>
+	<th>With parents</th>
+	<th>Deleted</th>
+	<th>Open issues</th>
+{{ end }}
+
+{{ define "SUMMARY_CELLS" }}
+	<td>{{ .Requirements }}</td>
+	<td>{{ printf "%.1f%%" .Implemented }}</td>
+	<td>{{ printf "%.1f%%" .Tested }}</td>
+	<td>{{ printf "%.1f%%" .WithParents }}</td>
+	<td>{{ .Deleted }}</td>
+	<td>{{ .Issues }}</td>
+{{ end }}
+
+{{ define "SUMMARY" }}
+	{{ template "HEADER" }}
+	<h1>Coverage Summary</h1>
+
+	<h2>Documents</h2>
+	{{ if .Documents }}
+	<table class="table table-condensed">
+		<thead>
+			<tr>
+				<th>Document</th>
+				<th>Level</th>
+				{{ template "SUMMARY_HEAD" }}
+			</tr>
+		</thead>
+		<tbody>
+		{{ range .Documents }}
+			<tr>
+				<td>{{ .RepoName }}:{{ .Path }}</td>
+				<td>{{ .Level }}</td>
+				{{ template "SUMMARY_CELLS" . }}
+			</tr>
+		{{ end }}
+			<tr>
+				<td colspan="2"><strong>Total</strong></td>
+				{{ template "SUMMARY_CELLS" .Total }}
+			</tr>
+		</tbody>
+	</table>
+
+	<h2>Requirement levels</h2>
+	<table class="table table-condensed">
+		<thead>
+			<tr>
+				<th>Level</th>
+				{{ template "SUMMARY_HEAD" }}
+			</tr>
+		</thead>
+		<tbody>
+		{{ range .Levels }}
+			<tr>
+				<td>{{ .Level }}</td>
+				{{ template "SUMMARY_CELLS" . }}
+			</tr>
+		{{ end }}
+		</tbody>
+	</table>
+	{{ else }}
+	<p class="text-warning">No documents configured.</p>
+	{{ end }}
+	{{ template "FOOTER" }}
+{{ end }}
+`
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-190
+func TestReport_Summary(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Level: "SWH"}}
+	sdd := config.Document{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Level: "SWL"},
+		Implementation: []config.Implementation{{ArchImplementation: config.ArchImplementation{CodeFiles: []string{"a.go"}}}}}
+	implementation := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go", Type: code.CodeTypeImplementation}, Tag: "f"}
+	test := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a_test.go", Type: code.CodeTypeTests}, Tag: "TestF"}
+
+	swh1 := &reqs.Req{ID: "REQ-TEST-SWH-1", Title: "Logging", RepoName: "repo", Document: &srd, Position: 1}
+	swh2 := &reqs.Req{ID: "REQ-TEST-SWH-2", Title: "DELETED", RepoName: "repo", Document: &srd, Position: 8}
+	asm := &reqs.Req{ID: "ASM-TEST-SWH-1", Variant: reqs.ReqVariantAssumption, Title: "Disk", RepoName: "repo", Document: &srd, Position: 10}
+	swl1 := &reqs.Req{ID: "REQ-TEST-SWL-1", Title: "Log file", RepoName: "repo", Document: &sdd, Position: 1,
+		Parents: []*reqs.Req{swh1}, Tags: []*code.Code{implementation, test}}
+	swl2 := &reqs.Req{ID: "REQ-TEST-SWL-2", Title: "Log rotation", RepoName: "repo", Document: &sdd, Position: 9,
+		Parents: []*reqs.Req{swh1}, Tags: []*code.Code{implementation}}
+	swl3 := &reqs.Req{ID: "REQ-TEST-SWL-3", Title: "Log level", RepoName: "repo", Document: &sdd, Position: 12}
+	swh1.Children = []*reqs.Req{swl1, swl2}
+
+	rg := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{swh1.ID: swh1, swh2.ID: swh2, asm.ID: asm, swl1.ID: swl1, swl2.ID: swl2, swl3.ID: swl3},
+		Issues: []diagnostics.Issue{
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 12, Severity: diagnostics.IssueSeverityMajor},
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 20, Severity: diagnostics.IssueSeverityNote},
+		},
+		ReqtraqConfig: &config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+			"repo": {Documents: []config.Document{srd, sdd}},
+		}},
+	}
+
+	summary := ComputeSummary(rg)
+	assert.Equal(t, []SummaryRow{
+		{RepoName: "repo", Path: "TEST-137-SRD.md", Level: "SWH", Requirements: 2, Implemented: 100, Tested: 0, WithParents: 0, Deleted: 1,
+			assumptions: 1, implemented: 1},
+		{RepoName: "repo", Path: "TEST-138-SDD.md", Level: "SWL", Requirements: 3, Implemented: 66.7, Tested: 33.3, WithParents: 66.7, Issues: 2,
+			implemented: 2, tested: 1, withParents: 2},
+	}, summary.Documents)
+	if assert.Len(t, summary.Levels, 2) {
+		assert.Equal(t, config.ReqLevel("SWH"), summary.Levels[0].Level)
+		assert.Equal(t, config.ReqLevel("SWL"), summary.Levels[1].Level)
+		assert.Equal(t, 66.7, summary.Levels[1].Implemented)
+	}
+	assert.Equal(t, 5, summary.Total.Requirements)
+	assert.Equal(t, 75.0, summary.Total.Implemented)
+	assert.Equal(t, 25.0, summary.Total.Tested)
+	assert.Equal(t, 50.0, summary.Total.WithParents)
+	assert.Equal(t, 1, summary.Total.Deleted)
+	assert.Equal(t, 2, summary.Total.Issues)
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteSummaryJSON(summary, &out))
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, map[string]interface{}{"requirements": 5.0, "implementedPercent": 75.0, "testedPercent": 25.0,
+		"withParentsPercent": 50.0, "deleted": 1.0, "openIssues": 2.0}, decoded["total"])
+
+	out.Reset()
+	assert.NoError(t, ReportSummary(summary, &out))
+	assert.Contains(t, out.String(), "<td>repo:TEST-138-SDD.md</td>")
+	assert.Contains(t, out.String(), "<td>66.7%</td>")
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// ScoreRecord holds the completeness scores computed by a validation, as recorded in the score history
+type ScoreRecord struct {
+	// Date of the validation, formatted as YYYY-MM-DD
+	Date string
+	// The commit of the validated repository, if known
+	Commit    string `json:",omitempty"`
+	Overall   reqs.Score
+	Documents []reqs.Score
+}
+
+// trendRow holds the scores of a record, as shown in the trend report
+type trendRow struct {
+	Date    string
+	Commit  string
+	Overall string
+	// The scores of the documents, in the order of the columns of the report
+	Documents []string
+}
+
+// trendData holds the data shown in the trend report
+type trendData struct {
+	// The documents with a score in any of the records, as `repo:path`
+	Documents []string
+	Rows      []trendRow
+}
+
+// AppendScoreHistory appends a record to the score history file at the given path, one JSON object per line.
+// The file is created if it does not exist.
+// @llr REQ-TRAQ-SWL-111
+func AppendScoreHistory(path string, record ScoreRecord) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(record); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadScoreHistory reads the records of a score history, in the order in which they were appended
+// @llr REQ-TRAQ-SWL-111
+func ReadScoreHistory(r io.Reader) ([]ScoreRecord, error) {
+	var records []ScoreRecord
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var record ScoreRecord
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("Invalid score history record %d: %v", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ReportTrend generates a HTML report showing how the completeness scores evolved over the given records.
+// @llr REQ-TRAQ-SWL-111
+func ReportTrend(records []ScoreRecord, w io.Writer) error {
+	documentName := func(score reqs.Score) string {
+		return fmt.Sprintf("%s:%s", score.RepoName, score.Path)
+	}
+
+	columns := make(map[string]int)
+	data := trendData{}
+	for _, record := range records {
+		for _, score := range record.Documents {
+			if _, ok := columns[documentName(score)]; !ok {
+				columns[documentName(score)] = 0
+				data.Documents = append(data.Documents, documentName(score))
+			}
+		}
+	}
+	sort.Strings(data.Documents)
+	for idx, name := range data.Documents {
+		columns[name] = idx
+	}
+
+	for _, record := range records {
+		row := trendRow{
+			Date:      record.Date,
+			Commit:    record.Commit,
+			Overall:   fmt.Sprintf("%.1f%%", record.Overall.Value),
+			Documents: make([]string, len(data.Documents)),
+		}
+		for idx := range row.Documents {
+			row.Documents[idx] = "-"
+		}
+		for _, score := range record.Documents {
+			row.Documents[columns[documentName(score)]] = fmt.Sprintf("%.1f%%", score.Value)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+
+	return trendTmpl.ExecuteTemplate(w, "TREND", data)
+}
+
+var trendTmpl = template.Must(template.Must(template.New("").Parse(headerFooterTmplText)).Parse(trendTmplText))
+
+var trendTmplText = `
+{{ define "TREND" }}
+	{{ template "HEADER" }}
+	<h1>Completeness Score Trend</h1>
+
+	{{ if .Rows }}
+	<table class="table table-condensed">
+		<thead>
+			<tr>
+				<th>Date</th>
+				<th>Commit</th>
+				<th>Overall</th>
+				{{ range .Documents }}<th>{{ . }}</th>{{ end }}
+			</tr>
+		</thead>
+		<tbody>
+		{{ range .Rows }}
+			<tr>
+				<td>{{ .Date }}</td>
+				<td>{{ .Commit }}</td>
+				<td><strong>{{ .Overall }}</strong></td>
+				{{ range .Documents }}<td>{{ . }}</td>{{ end }}
+			</tr>
+		{{ end }}
+		</tbody>
+	</table>
+	{{ else }}
+	<p class="text-warning">No scores recorded.</p>
+	{{ end }}
+	{{ template "FOOTER" }}
+{{ end }}
+`
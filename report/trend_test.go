@@ -0,0 +1,43 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-111
+func TestScoreHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.jsonl")
+	first := ScoreRecord{Date: "2026-01-05", Commit: "abc1234", Overall: reqs.Score{Requirements: 2, Value: 50},
+		Documents: []reqs.Score{{RepoName: "repo", Path: "TEST-138-SDD.md", Requirements: 2, Value: 50}}}
+	second := ScoreRecord{Date: "2026-02-05", Overall: reqs.Score{Requirements: 3, Value: 75},
+		Documents: []reqs.Score{
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Requirements: 2, Value: 100},
+			{RepoName: "repo", Path: "TEST-137-SRD.md", Requirements: 1, Value: 25},
+		}}
+	assert.NoError(t, AppendScoreHistory(path, first))
+	assert.NoError(t, AppendScoreHistory(path, second))
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	records, err := ReadScoreHistory(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoreRecord{first, second}, records)
+
+	var out bytes.Buffer
+	assert.NoError(t, ReportTrend(records, &out))
+	html := strings.Join(strings.Fields(out.String()), " ")
+	assert.Contains(t, html, "<th>repo:TEST-137-SRD.md</th><th>repo:TEST-138-SDD.md</th>")
+	assert.Contains(t, html, "<td>2026-01-05</td> <td>abc1234</td> <td><strong>50.0%</strong></td> <td>-</td><td>50.0%</td>")
+	assert.Contains(t, html, "<td>2026-02-05</td> <td></td> <td><strong>75.0%</strong></td> <td>25.0%</td><td>100.0%</td>")
+
+	_, err = ReadScoreHistory(strings.NewReader("{\"Date\": \"2026-01-05\"}\nnot json\n"))
+	assert.EqualError(t, err, "Invalid score history record 2: invalid character 'o' in literal null (expecting 'u')")
+}
@@ -0,0 +1,197 @@
+package repos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/linepipes"
+)
+
+// The suffix of the lock file of a cached clone, created next to it and locked by the process using it
+const lockSuffix = ".lock"
+
+// The open lock files of the cached clones used by this process, by path, released by CleanupTemporaryDirectories
+var heldLocks = map[string]*os.File{}
+
+// Returns the directory where the clones of the remote repositories are cached across runs: the directory given in
+// the REQTRAQ_CACHE_DIR environment variable, or `.reqtraq-cache` in the temporary directory.
+// @llr REQ-TRAQ-SWL-149
+func CacheRoot() string {
+	if dir := os.Getenv("REQTRAQ_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), ".reqtraq-cache")
+}
+
+// Returns the name of the cached clone of a remote repository at a git reference. Local remote paths are made
+// absolute, as they are relative to the base repository.
+// @llr REQ-TRAQ-SWL-149
+func cacheKey(repoName RepoName, remotePath RemotePath, gitReference string) string {
+	remote := string(remotePath)
+	if info, err := os.Stat(remote); err == nil && info.IsDir() {
+		if absPath, err := filepath.Abs(remote); err == nil {
+			remote = absPath
+		}
+	}
+	sum := sha256.Sum256([]byte(remote + "\n" + gitReference))
+	return fmt.Sprintf("%s-%s", repoName, hex.EncodeToString(sum[:])[:12])
+}
+
+// Locks the cached clone in the given directory for this process with an exclusive lock on its lock file, which the
+// system releases when the process exits, even if it crashed. Returns false if another process holds the lock.
+// The lock is taken by lockFile, which depends on the system.
+// @llr REQ-TRAQ-SWL-149
+func lockClone(dir string) (bool, error) {
+	lockPath := dir + lockSuffix
+	if _, held := heldLocks[lockPath]; held {
+		return true, nil
+	}
+	for {
+		file, locked, err := lockFile(lockPath)
+		if err != nil || !locked {
+			return false, err
+		}
+		// The process releasing the lock removes the lock file, possibly after it was opened here, in which case
+		// another process can lock a new file at the same path, so the lock is only held if the file is still there
+		opened, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return false, err
+		}
+		if current, err := os.Stat(lockPath); err == nil && os.SameFile(opened, current) {
+			heldLocks[lockPath] = file
+			return true, nil
+		}
+		file.Close()
+	}
+}
+
+// Releases a lock held by this process, removing the lock file before unlocking it
+// @llr REQ-TRAQ-SWL-149
+func releaseLock(lockPath string, file *os.File) {
+	os.Remove(lockPath)
+	file.Close()
+	delete(heldLocks, lockPath)
+}
+
+// Releases the lock of the cached clone in the given directory held by this process
+// @llr REQ-TRAQ-SWL-149
+func unlockClone(dir string) {
+	lockPath := dir + lockSuffix
+	if file, held := heldLocks[lockPath]; held {
+		releaseLock(lockPath, file)
+	}
+}
+
+// Releases the locks of all the cached clones held by this process
+// @llr REQ-TRAQ-SWL-149
+func releaseLocks() {
+	for lockPath, file := range heldLocks {
+		releaseLock(lockPath, file)
+	}
+}
+
+// Updates an existing clone to the latest state of the remote repository at the git reference, or at its default
+// branch, discarding any local change
+// @llr REQ-TRAQ-SWL-149
+func updateClone(repoPath RepoPath, gitReference string) error {
+	git := func(args ...string) (string, error) {
+		return linepipes.All(linepipes.Run("git", append([]string{"-C", string(repoPath)}, args...)...))
+	}
+	if _, err := git("fetch", "--quiet", "--tags", "--force", "origin"); err != nil {
+		return err
+	}
+	target := "origin/HEAD"
+	if gitReference != "" {
+		// Branches are checked out at their latest remote state, tags and commits as they are
+		target = gitReference
+		if _, err := git("rev-parse", "--verify", "--quiet", "origin/"+gitReference+"^{commit}"); err == nil {
+			target = "origin/" + gitReference
+		}
+	}
+	if _, err := git("checkout", "--quiet", "--force", "--detach", target); err != nil {
+		return err
+	}
+	_, err := git("clean", "--quiet", "--force", "-d", "-x")
+	return err
+}
+
+// Returns the cached clone of the remote repository at the git reference, cloning it or updating it as needed.
+// Returns false if another process is using the cached clone.
+// @llr REQ-TRAQ-SWL-149
+func cachedClone(repoName RepoName, remotePath RemotePath, gitReference string) (RepoPath, bool, error) {
+	root := CacheRoot()
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", false, err
+	}
+	dir := filepath.Join(root, cacheKey(repoName, remotePath, gitReference))
+	locked, err := lockClone(dir)
+	if err != nil || !locked {
+		return "", false, err
+	}
+
+	repoPath := RepoPath(dir)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		if err := updateClone(repoPath, gitReference); err == nil {
+			return repoPath, true, nil
+		}
+		// A clone which cannot be updated, e.g. after an interrupted clone, is cloned again
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", false, err
+	}
+	if err := clone(remotePath, repoPath, gitReference); err != nil {
+		os.RemoveAll(dir)
+		return "", false, err
+	}
+	return repoPath, true, nil
+}
+
+// Removes the cached clones which are not used by a running process. Returns the removed clones and the clones
+// skipped because they are in use, sorted.
+// @llr REQ-TRAQ-SWL-149
+func CleanCache() ([]string, []string, error) {
+	root := CacheRoot()
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The lock files left without their clone are collected with the clones
+	dirs := make(map[string]bool)
+	for _, entry := range entries {
+		dirs[filepath.Join(root, strings.TrimSuffix(entry.Name(), lockSuffix))] = true
+	}
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	var removed, inUse []string
+	for _, dir := range sorted {
+		locked, err := lockClone(dir)
+		if err != nil {
+			return removed, inUse, err
+		}
+		if !locked {
+			inUse = append(inUse, dir)
+			continue
+		}
+		err = os.RemoveAll(dir)
+		unlockClone(dir)
+		if err != nil {
+			return removed, inUse, err
+		}
+		removed = append(removed, dir)
+	}
+	return removed, inUse, nil
+}
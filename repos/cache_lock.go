@@ -0,0 +1,26 @@
+//go:build !windows
+
+package repos
+
+import (
+	"os"
+	"syscall"
+)
+
+// Opens the lock file at the given path, creating it if needed, and takes an exclusive lock on it, which the system
+// releases when the process exits. Returns false if another process holds the lock.
+// @llr REQ-TRAQ-SWL-149
+func lockFile(lockPath string) (*os.File, bool, error) {
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return file, true, nil
+}
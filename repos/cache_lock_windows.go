@@ -0,0 +1,31 @@
+//go:build windows
+
+package repos
+
+import (
+	"os"
+	"syscall"
+)
+
+// The error returned when opening a file another process opened without sharing it
+const errorSharingViolation syscall.Errno = 32
+
+// Opens the lock file at the given path, creating it if needed, without sharing it with other processes, which is
+// undone by the system when the process exits. Only its deletion is shared, so the lock file can be removed when the
+// lock is released. Returns false if another process holds the lock.
+// @llr REQ-TRAQ-SWL-149
+func lockFile(lockPath string) (*os.File, bool, error) {
+	name, err := syscall.UTF16PtrFromString(lockPath)
+	if err != nil {
+		return nil, false, err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, syscall.FILE_SHARE_DELETE, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if err == errorSharingViolation {
+			return nil, false, nil
+		}
+		return nil, false, &os.PathError{Op: "open", Path: lockPath, Err: err}
+	}
+	return os.NewFile(uintptr(handle), lockPath), true, nil
+}
@@ -14,9 +14,11 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/daedaleanai/reqtraq/linepipes"
+	"github.com/daedaleanai/reqtraq/profile"
 	"github.com/pkg/errors"
 )
 
@@ -46,8 +48,24 @@ var (
 	tempDirs []string = make([]string, 0)
 	// Maps from name to path
 	repositories map[RepoName]RepoPath = make(map[RepoName]RepoPath)
+	// Maps from name to the remote each cloned repository was cloned from
+	clones map[RepoName]Clone = make(map[RepoName]Clone)
 )
 
+// Set to operate on plain directory trees instead of git repositories, e.g. on source bundles received from
+// suppliers. The other repositories must then be local directories and the features depending on git are disabled.
+var NoGit bool = false
+
+// ErrNoGit is returned by the functions depending on git when NoGit is set
+var ErrNoGit = errors.New("git is disabled with --no-git")
+
+// Clone describes where a registered repository was cloned from
+type Clone struct {
+	Remote RemotePath
+	// The git reference checked out after cloning, empty for the default branch
+	Reference string
+}
+
 // Collects the information about the base repository (the repository where the reqtraq command is run)
 // @llr REQ-TRAQ-SWL-49
 func SetBaseRepoInfo(repoPath RepoPath, repoName RepoName) {
@@ -82,17 +100,38 @@ func BaseRepoName() RepoName {
 // @llr REQ-TRAQ-SWL-49
 func RegisterRepository(name RepoName, path RepoPath) {
 	repositories[name] = path
+	delete(clones, name)
 }
 
 // Unregisters all repositories from the registry, leaving it empty
 // @llr REQ-TRAQ-SWL-49
 func ClearAllRepositories() {
 	repositories = make(map[RepoName]RepoPath)
+	clones = make(map[RepoName]Clone)
+}
+
+// Returns the names of the registered repositories, sorted
+// @llr REQ-TRAQ-SWL-144
+func RegisteredRepositories() []RepoName {
+	names := make([]RepoName, 0, len(repositories))
+	for name := range repositories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Returns where the registered repository was cloned from. The returned flag is false if the repository was
+// registered with a local path instead.
+// @llr REQ-TRAQ-SWL-144
+func ClonedFrom(name RepoName) (Clone, bool) {
+	clone, ok := clones[name]
+	return clone, ok
 }
 
 // Gets the local path to a repository by name. The remotePath will be used to create a local
 // repository copy if the repository is not registered or the override flag is set.
-// @llr REQ-TRAQ-SWL-49, REQ-TRAQ-SWL-50
+// @llr REQ-TRAQ-SWL-49, REQ-TRAQ-SWL-50, REQ-TRAQ-SWL-108, REQ-TRAQ-SWL-153
 func GetRepo(repoName RepoName, remotePath RemotePath, gitReference string, override bool) (RepoPath, error) {
 	if !override {
 		// Check if it is already registered, if so just return it
@@ -102,17 +141,44 @@ func GetRepo(repoName RepoName, remotePath RemotePath, gitReference string, over
 		}
 	}
 
+	if NoGit {
+		path, err := localDirectory(repoName, remotePath)
+		if err != nil {
+			return "", err
+		}
+		repositories[repoName] = path
+		delete(clones, repoName)
+		return path, nil
+	}
+
 	// Clone the repo
+	stopProfile := profile.Start("repo clone", string(repoName), "")
 	path, err := cloneFromRemote(repoName, remotePath, gitReference)
+	stopProfile()
 	if err != nil {
 		return "", err
 	}
 
 	// Now let's store it
 	repositories[repoName] = path
+	clones[repoName] = Clone{Remote: remotePath, Reference: gitReference}
 	return path, nil
 }
 
+// Returns the directory of a repository given by a local path, relative to the base repository unless absolute,
+// for when git is disabled and the repository cannot be cloned
+// @llr REQ-TRAQ-SWL-153
+func localDirectory(repoName RepoName, remotePath RemotePath) (RepoPath, error) {
+	path := string(remotePath)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(string(basePath), path)
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("Repository `%s` must be a local directory when git is disabled, `%s` is not", repoName, remotePath)
+	}
+	return RepoPath(path), nil
+}
+
 // Obtains the local path to a repository from its name, if the repository is registered
 // @llr REQ-TRAQ-SWL-49
 func GetRepoPathByName(name RepoName) (RepoPath, error) {
@@ -122,17 +188,11 @@ func GetRepoPathByName(name RepoName) (RepoPath, error) {
 	return "", fmt.Errorf("Could not find path for repository with name `%s`", name)
 }
 
-// Creates a local copy of the given remote repository in a temporary folder and registers it for
-// deletion when CleanupTemporaryDirectories is called.
-// @llr REQ-TRAQ-SWL-49, REQ-TRAQ-SWL-16
+// Creates a local copy of the given remote repository. The copy is kept in the cache of clones to be reused by
+// the next runs, unless another process is using it, in which case a temporary folder is registered for deletion
+// when CleanupTemporaryDirectories is called.
+// @llr REQ-TRAQ-SWL-49, REQ-TRAQ-SWL-16, REQ-TRAQ-SWL-149
 func cloneFromRemote(repoName RepoName, remotePath RemotePath, gitReference string) (RepoPath, error) {
-	cloneDir, err := ioutil.TempDir("", ".reqtraq")
-	if err != nil {
-		return "", err
-	}
-
-	repoPath := RepoPath(filepath.Join(cloneDir, string(repoName)))
-
 	// Use the baseRepoPath when checking out repositories in case remotePath is a local path
 	originalDir, err := os.Getwd()
 	if err != nil {
@@ -144,27 +204,48 @@ func cloneFromRemote(repoName RepoName, remotePath RemotePath, gitReference stri
 	}
 	defer os.Chdir(originalDir)
 
-	if _, err := linepipes.All(linepipes.Run("git", "clone", string(remotePath), string(repoPath))); err != nil {
+	repoPath, cached, err := cachedClone(repoName, remotePath, gitReference)
+	if err != nil || cached {
+		return repoPath, err
+	}
+
+	cloneDir, err := ioutil.TempDir("", ".reqtraq")
+	if err != nil {
 		return "", err
 	}
+	// Save the temp dir for cleanup when we exit
+	tempDirs = append(tempDirs, cloneDir)
+
+	repoPath = RepoPath(filepath.Join(cloneDir, string(repoName)))
+	if err := clone(remotePath, repoPath, gitReference); err != nil {
+		return "", err
+	}
+	return repoPath, nil
+}
+
+// Clones the remote repository into the given path and checks out the git reference, if any
+// @llr REQ-TRAQ-SWL-49, REQ-TRAQ-SWL-149
+func clone(remotePath RemotePath, repoPath RepoPath, gitReference string) error {
+	if _, err := linepipes.All(linepipes.Run("git", "clone", string(remotePath), string(repoPath))); err != nil {
+		return err
+	}
 
 	if gitReference != "" {
 		if _, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "checkout", gitReference)); err != nil {
-			return "", err
+			return err
 		}
 	}
-
-	// Save the  temp dir for cleanup when we exit
-	tempDirs = append(tempDirs, string(repoPath))
-	return repoPath, nil
+	return nil
 }
 
-// Removes any temporary directories where repositories have been cloned
-// @llr REQ-TRAQ-SWL-49
+// Removes any temporary directories where repositories have been cloned and releases the cached clones
+// @llr REQ-TRAQ-SWL-49, REQ-TRAQ-SWL-149
 func CleanupTemporaryDirectories() {
 	for _, dir := range tempDirs {
 		os.RemoveAll(dir)
 	}
+	tempDirs = nil
+	releaseLocks()
 }
 
 // Finds files in the given repository, returning an array of paths to each matched file
@@ -244,8 +325,11 @@ func PathInRepo(repoName RepoName, path string) (string, error) {
 var emptyLineMatcher = regexp.MustCompile("^\\s*$")
 
 // AllCommits returns the list of commits formatted as "ID DATE".
-// @llr REQ-TRAQ-SWL-16
+// @llr REQ-TRAQ-SWL-16, REQ-TRAQ-SWL-153
 func AllCommits(repoName RepoName) ([]string, error) {
+	if NoGit {
+		return []string{}, ErrNoGit
+	}
 	repoPath, err := GetRepoPathByName(repoName)
 	if err != nil {
 		return []string{}, err
@@ -265,3 +349,186 @@ func AllCommits(repoName RepoName) ([]string, error) {
 
 	return commits, nil
 }
+
+// Commit holds the details of a commit changing a file
+type Commit struct {
+	ID      string
+	Date    string
+	Author  string
+	Subject string
+}
+
+// FileCommits returns the commits which changed the given file after the given git reference, oldest first.
+// All the commits which changed the file are returned when the reference is empty.
+// @llr REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-153
+func FileCommits(repoName RepoName, path string, since string) ([]Commit, error) {
+	if NoGit {
+		return nil, ErrNoGit
+	}
+	repoPath, err := GetRepoPathByName(repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := "HEAD"
+	if since != "" {
+		revisions = since + "..HEAD"
+	}
+	lines, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "log", "--reverse",
+		"--pretty=format:%h%x09%ad%x09%an%x09%s", "--date=short", revisions, "--", path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get the commits changing `%s`", path)
+	}
+
+	commits := make([]Commit, 0)
+	for _, line := range strings.Split(lines, "\n") {
+		if emptyLineMatcher.MatchString(line) {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("Unexpected git log output: %q", line)
+		}
+		commits = append(commits, Commit{ID: parts[0], Date: parts[1], Author: parts[2], Subject: parts[3]})
+	}
+	return commits, nil
+}
+
+// LatestCommit returns the last commit which changed the given file. The returned flag is false if the file was never
+// committed.
+// @llr REQ-TRAQ-SWL-139, REQ-TRAQ-SWL-153
+func LatestCommit(repoName RepoName, path string) (Commit, bool, error) {
+	if NoGit {
+		return Commit{}, false, ErrNoGit
+	}
+	repoPath, err := GetRepoPathByName(repoName)
+	if err != nil {
+		return Commit{}, false, err
+	}
+
+	line, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "log", "-1",
+		"--pretty=format:%h%x09%ad%x09%an%x09%s", "--date=short", "--", path))
+	if err != nil {
+		return Commit{}, false, errors.Wrapf(err, "Failed to get the last commit changing `%s`", path)
+	}
+	if emptyLineMatcher.MatchString(line) {
+		return Commit{}, false, nil
+	}
+	parts := strings.SplitN(strings.TrimRight(line, "\n"), "\t", 4)
+	if len(parts) != 4 {
+		return Commit{}, false, fmt.Errorf("Unexpected git log output: %q", line)
+	}
+	return Commit{ID: parts[0], Date: parts[1], Author: parts[2], Subject: parts[3]}, true, nil
+}
+
+// CommitMessage holds a commit with its full message
+type CommitMessage struct {
+	Commit
+	Message string
+}
+
+// CommitMessages returns the commits of the given repository after the given git reference, newest first, with their
+// full message. All the commits are returned when the reference is empty.
+// @llr REQ-TRAQ-SWL-158
+func CommitMessages(repoName RepoName, since string) ([]CommitMessage, error) {
+	if NoGit {
+		return nil, ErrNoGit
+	}
+	repoPath, err := GetRepoPathByName(repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := "HEAD"
+	if since != "" {
+		revisions = since + "..HEAD"
+	}
+	// The records are separated by the record separator character, as the messages span several lines
+	output, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "log",
+		"--pretty=format:%h%x09%ad%x09%an%x09%s%x09%B%x1e", "--date=short", revisions))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get the commit messages of repository `%s`", repoName)
+	}
+
+	commits := make([]CommitMessage, 0)
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if emptyLineMatcher.MatchString(record) {
+			continue
+		}
+		parts := strings.SplitN(record, "\t", 5)
+		if len(parts) != 5 {
+			return nil, fmt.Errorf("Unexpected git log output: %q", record)
+		}
+		commits = append(commits, CommitMessage{
+			Commit:  Commit{ID: parts[0], Date: parts[1], Author: parts[2], Subject: parts[3]},
+			Message: strings.TrimSpace(parts[4]),
+		})
+	}
+	return commits, nil
+}
+
+// Revision returns the commit checked out in the given repository
+// @llr REQ-TRAQ-SWL-144, REQ-TRAQ-SWL-153
+func Revision(repoName RepoName) (string, error) {
+	if NoGit {
+		return "", ErrNoGit
+	}
+	repoPath, err := GetRepoPathByName(repoName)
+	if err != nil {
+		return "", err
+	}
+
+	revision, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "rev-parse", "HEAD"))
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to get the revision of repository `%s`", repoName)
+	}
+	return strings.TrimSpace(revision), nil
+}
+
+// CommitMessageAndNotes returns the message of a commit followed by its notes under the given git notes reference,
+// if any
+// @llr REQ-TRAQ-SWL-139, REQ-TRAQ-SWL-153
+func CommitMessageAndNotes(repoName RepoName, commitID string, notesRef string) (string, error) {
+	if NoGit {
+		return "", ErrNoGit
+	}
+	repoPath, err := GetRepoPathByName(repoName)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "show", "-s", "--notes="+notesRef,
+		"--format=%B%n%N", commitID))
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to read the message and notes of commit `%s`", commitID)
+	}
+	return text, nil
+}
+
+// FileAtRevision returns the content of the given file at a git revision. The returned flag is false if the
+// file does not exist at that revision.
+// @llr REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-153
+func FileAtRevision(repoName RepoName, path string, revision string) (string, bool, error) {
+	if NoGit {
+		return "", false, ErrNoGit
+	}
+	repoPath, err := GetRepoPathByName(repoName)
+	if err != nil {
+		return "", false, err
+	}
+
+	// git paths are always relative to the root of the repository and use forward slashes
+	object := revision + ":" + filepath.ToSlash(filepath.Clean(path))
+	if _, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "cat-file", "-e", object)); err != nil {
+		if _, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "rev-parse", "--verify", "--quiet", revision+"^{commit}")); err != nil {
+			return "", false, fmt.Errorf("Unknown git revision `%s`", revision)
+		}
+		return "", false, nil
+	}
+	content, err := linepipes.All(linepipes.Run("git", "-C", string(repoPath), "show", object))
+	if err != nil {
+		return "", false, errors.Wrapf(err, "Failed to read `%s` at revision `%s`", path, revision)
+	}
+	return content, true, nil
+}
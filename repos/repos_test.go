@@ -178,3 +178,102 @@ func TestRepos_AllCommits(t *testing.T) {
 		assert.True(t, commitLineMatcher.MatchString(commit))
 	}
 }
+
+// @llr REQ-TRAQ-SWL-144
+func TestRepos_ClonedFrom(t *testing.T) {
+	baseRepoPath := BaseRepoPath()
+	baseRepoName := BaseRepoName()
+	ClearAllRepositories()
+	RegisterRepository(baseRepoName, baseRepoPath)
+
+	_, ok := ClonedFrom(baseRepoName)
+	assert.False(t, ok)
+
+	otherName := RepoName("other")
+	path, err := GetRepo(otherName, RemotePath(baseRepoPath), "", false)
+	assert.Nil(t, err)
+	clone, ok := ClonedFrom(otherName)
+	assert.True(t, ok)
+	assert.Equal(t, Clone{Remote: RemotePath(baseRepoPath)}, clone)
+	assert.Equal(t, []RepoName{otherName, baseRepoName}, RegisteredRepositories())
+
+	revision, err := Revision(otherName)
+	assert.Nil(t, err)
+	baseRevision, err := Revision(baseRepoName)
+	assert.Nil(t, err)
+	assert.Equal(t, baseRevision, revision)
+	assert.True(t, strings.HasPrefix(string(path), filepath.Join(os.TempDir(), ".reqtraq")))
+}
+
+// @llr REQ-TRAQ-SWL-149
+func TestRepos_CachedClone(t *testing.T) {
+	cacheDir := t.TempDir()
+	os.Setenv("REQTRAQ_CACHE_DIR", cacheDir)
+	defer os.Unsetenv("REQTRAQ_CACHE_DIR")
+	defer CleanupTemporaryDirectories()
+
+	ClearAllRepositories()
+	path, err := GetRepo("cached", RemotePath(BaseRepoPath()), "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Dir(string(path)), cacheDir)
+	_, err = os.Stat(string(path) + ".lock")
+	assert.Nil(t, err)
+
+	// The clone is reused by the next runs once released
+	CleanupTemporaryDirectories()
+	_, err = os.Stat(string(path) + ".lock")
+	assert.True(t, os.IsNotExist(err))
+	ClearAllRepositories()
+	again, err := GetRepo("cached", RemotePath(BaseRepoPath()), "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, path, again)
+
+	// A clone locked by another process is not cleaned, nor used. Locks of separately opened files conflict
+	// like the locks of different processes.
+	CleanupTemporaryDirectories()
+	heldLock, locked, err := lockFile(string(path) + ".lock")
+	assert.Nil(t, err)
+	assert.True(t, locked)
+	removed, inUse, err := CleanCache()
+	assert.Nil(t, err)
+	assert.Empty(t, removed)
+	assert.Equal(t, []string{string(path)}, inUse)
+	ClearAllRepositories()
+	other, err := GetRepo("cached", RemotePath(BaseRepoPath()), "", false)
+	assert.Nil(t, err)
+	assert.NotEqual(t, path, other)
+
+	// The lock file left by a process which is not running anymore is not locked
+	assert.Nil(t, heldLock.Close())
+	removed, inUse, err = CleanCache()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{string(path)}, removed)
+	assert.Empty(t, inUse)
+	_, err = os.Stat(string(path))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// @llr REQ-TRAQ-SWL-153
+func TestRepos_NoGit(t *testing.T) {
+	NoGit = true
+	defer func() { NoGit = false }()
+	ClearAllRepositories()
+	RegisterRepository(BaseRepoName(), BaseRepoPath())
+
+	// Other repositories are used where they are, relative to the base repository
+	path, err := GetRepo("bundle", RemotePath("testdata"), "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, RepoPath(filepath.Join(string(BaseRepoPath()), "testdata")), path)
+	_, ok := ClonedFrom("bundle")
+	assert.False(t, ok)
+
+	_, err = GetRepo("missing", RemotePath("https://example.com/missing.git"), "", false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must be a local directory when git is disabled")
+	}
+
+	_, err = Revision(BaseRepoName())
+	assert.ErrorIs(t, err, ErrNoGit)
+	_, _, err = LatestCommit(BaseRepoName(), "README.md")
+	assert.ErrorIs(t, err, ErrNoGit)
+}
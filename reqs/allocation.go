@@ -0,0 +1,118 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// Allocation returns the architectures the requirement is allocated to in the given attribute, empty if it is not
+// allocated
+// @llr REQ-TRAQ-SWL-146
+func (r *Req) Allocation(attribute string) []config.Arch {
+	if attribute == "" {
+		return nil
+	}
+	var archs []config.Arch
+	for _, value := range strings.Split(r.Attributes[attribute], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			archs = append(archs, config.Arch(value))
+		}
+	}
+	return archs
+}
+
+// checkAllocationValue reports the architectures the requirement is allocated to which are not configured in the
+// implementation of its document
+// @llr REQ-TRAQ-SWL-146
+func (r *Req) checkAllocationValue(attribute string) []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	for _, arch := range r.Allocation(attribute) {
+		known := false
+		for _, impl := range r.Document.Implementation {
+			if _, ok := impl.Archs[arch]; ok {
+				known = true
+				break
+			}
+		}
+		if !known {
+			issues = append(issues, diagnostics.Issue{
+				Line:        r.Position,
+				Path:        r.Document.Path,
+				RepoName:    r.RepoName,
+				Description: fmt.Sprintf("Requirement '%s' is allocated to '%s', which is not an architecture of the implementation of document '%s'.", r.ID, arch, r.Document.Path),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidAttributeValue,
+			})
+		}
+	}
+	return issues
+}
+
+// checkCodeAllocation checks that the code referencing a requirement belongs to the document of the requirement and,
+// when the requirement is allocated to architectures, that the code was parsed for one of them, i.e. it is in a file
+// matched by the patterns of the architecture
+// @llr REQ-TRAQ-SWL-146
+func (rg *ReqGraph) checkCodeAllocation(tag *code.Code, parent *Req) []diagnostics.Issue {
+	if parent.Document == nil || tag.Document == nil {
+		return nil
+	}
+	// References which do not match the format of the document are already reported as such
+	if re := tag.Document.Schema.Requirements; re != nil && !re.MatchString(parent.ID) {
+		return nil
+	}
+	if parent.RepoName != tag.CodeFile.RepoName || parent.Document.Path != tag.Document.Path {
+		return []diagnostics.Issue{{
+			Line:     tag.Line,
+			Path:     tag.CodeFile.Path,
+			RepoName: tag.CodeFile.RepoName,
+			Description: fmt.Sprintf("Invalid reference in function %s@%s:%d in repo `%s`, %s belongs to document `%s` instead of `%s`.",
+				tag.Tag, tag.CodeFile.Path, tag.Line, tag.CodeFile.RepoName, parent.ID, parent.Document.Path, tag.Document.Path),
+			Severity: diagnostics.IssueSeverityMajor,
+			Type:     diagnostics.IssueTypeInvalidRequirementInCode,
+		}}
+	}
+
+	if rg.ReqtraqConfig == nil {
+		return nil
+	}
+	allocation := parent.Allocation(rg.ReqtraqConfig.AllocationAttribute)
+	if len(allocation) == 0 {
+		return nil
+	}
+	for _, arch := range allocation {
+		for _, tagArch := range tag.Archs {
+			if tagArch == arch {
+				return nil
+			}
+		}
+	}
+	names := make([]string, 0, len(allocation))
+	for _, arch := range allocation {
+		names = append(names, string(arch))
+	}
+	sort.Strings(names)
+	where := "code common to all the architectures"
+	if len(tag.Archs) == 1 {
+		where = fmt.Sprintf("code of architecture `%s`", tag.Archs[0])
+	} else if len(tag.Archs) > 1 {
+		archs := make([]string, 0, len(tag.Archs))
+		for _, arch := range tag.Archs {
+			archs = append(archs, string(arch))
+		}
+		where = fmt.Sprintf("code of architectures `%s`", strings.Join(archs, ", "))
+	}
+	return []diagnostics.Issue{{
+		Line:     tag.Line,
+		Path:     tag.CodeFile.Path,
+		RepoName: tag.CodeFile.RepoName,
+		Description: fmt.Sprintf("Function %s@%s:%d in repo `%s` is %s, but %s is allocated to `%s`.",
+			tag.Tag, tag.CodeFile.Path, tag.Line, tag.CodeFile.RepoName, where, parent.ID, strings.Join(names, ", ")),
+		Severity: diagnostics.IssueSeverityMajor,
+		Type:     diagnostics.IssueTypeMisallocatedCode,
+	}}
+}
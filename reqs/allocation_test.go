@@ -0,0 +1,90 @@
+package reqs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-146
+func TestReq_CheckAllocationValue(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md", Implementation: []config.Implementation{{
+		Archs: map[config.Arch]config.ArchImplementation{"arm": {}, "x86": {}},
+	}}}
+	r := &Req{ID: "REQ-TEST-SWL-1", Document: doc, RepoName: "repo", Position: 3,
+		Attributes: map[string]string{"ALLOCATION": "arm, riscv"}}
+
+	assert.Equal(t, []config.Arch{"arm", "riscv"}, r.Allocation("ALLOCATION"))
+	assert.Empty(t, r.Allocation(""))
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        3,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Requirement 'REQ-TEST-SWL-1' is allocated to 'riscv', which is not an architecture of the implementation of document 'TEST-138-SDD.md'.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInvalidAttributeValue,
+	}}, r.checkAllocationValue("ALLOCATION"))
+	assert.Empty(t, r.checkAllocationValue(""))
+}
+
+// @llr REQ-TRAQ-SWL-146
+func TestReqGraph_CheckCodeAllocation(t *testing.T) {
+	sdd := &config.Document{Path: "TEST-138-SDD.md"}
+	other := &config.Document{Path: "TEST-139-SDD.md"}
+	rg := &ReqGraph{ReqtraqConfig: &config.Config{AllocationAttribute: "ALLOCATION"}}
+	allocated := &Req{ID: "REQ-TEST-SWL-1", Document: sdd, RepoName: "repo",
+		Attributes: map[string]string{"ALLOCATION": "x86, arm"}}
+	common := &Req{ID: "REQ-TEST-SWL-2", Document: sdd, RepoName: "repo", Attributes: map[string]string{}}
+	tag := func(doc *config.Document, archs ...config.Arch) *code.Code {
+		return &code.Code{Tag: "logInit", Line: 12, Document: doc, Archs: archs,
+			CodeFile: code.CodeFile{RepoName: "repo", Path: "log.cc", Type: code.CodeTypeImplementation}}
+	}
+
+	assert.Empty(t, rg.checkCodeAllocation(tag(sdd, "arm"), allocated))
+	assert.Empty(t, rg.checkCodeAllocation(tag(sdd), common))
+	assert.Empty(t, rg.checkCodeAllocation(tag(sdd, "ppc"), common))
+	assert.Empty(t, rg.checkCodeAllocation(tag(sdd, "arm", "ppc"), allocated))
+
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        12,
+		Path:        "log.cc",
+		RepoName:    "repo",
+		Description: "Function logInit@log.cc:12 in repo `repo` is code of architecture `ppc`, but REQ-TEST-SWL-1 is allocated to `arm, x86`.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeMisallocatedCode,
+	}}, rg.checkCodeAllocation(tag(sdd, "ppc"), allocated))
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        12,
+		Path:        "log.cc",
+		RepoName:    "repo",
+		Description: "Function logInit@log.cc:12 in repo `repo` is code common to all the architectures, but REQ-TEST-SWL-1 is allocated to `arm, x86`.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeMisallocatedCode,
+	}}, rg.checkCodeAllocation(tag(sdd), allocated))
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        12,
+		Path:        "log.cc",
+		RepoName:    "repo",
+		Description: "Function logInit@log.cc:12 in repo `repo` is code of architectures `ppc, riscv`, but REQ-TEST-SWL-1 is allocated to `arm, x86`.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeMisallocatedCode,
+	}}, rg.checkCodeAllocation(tag(sdd, "ppc", "riscv"), allocated))
+
+	// The code must reference the requirements of its document
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        12,
+		Path:        "log.cc",
+		RepoName:    "repo",
+		Description: "Invalid reference in function logInit@log.cc:12 in repo `repo`, REQ-TEST-SWL-2 belongs to document `TEST-138-SDD.md` instead of `TEST-139-SDD.md`.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInvalidRequirementInCode,
+	}}, rg.checkCodeAllocation(tag(other), common))
+
+	// Unless the reference does not match the format of the document, which is reported on its own
+	other.Schema.Requirements = regexp.MustCompile(`REQ-OTHER-SWL-(\d+)`)
+	assert.Empty(t, rg.checkCodeAllocation(tag(other), common))
+}
@@ -0,0 +1,46 @@
+package reqs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// The attribute recording the hash of the text of a requirement when it was approved
+const ApprovedHashAttribute = "APPROVED-HASH"
+
+// The number of hexadecimal digits of the hash of the text of a requirement
+const textHashLength = 8
+
+// TextHash returns the hash of the title and body of the requirement, ignoring differences in whitespace.
+// @llr REQ-TRAQ-SWL-115
+func (r *Req) TextHash() string {
+	text := strings.Join(strings.Fields(r.Title), " ") + "\n" + strings.Join(strings.Fields(r.Body), " ")
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])[:textHashLength]
+}
+
+// checkApproval checks that the text of an approved requirement still has the hash recorded at its approval.
+// @llr REQ-TRAQ-SWL-115
+func (r *Req) checkApproval() []diagnostics.Issue {
+	approved, ok := r.Attributes[ApprovedHashAttribute]
+	if !ok || r.IsDeleted() {
+		return nil
+	}
+	hash := r.TextHash()
+	if strings.EqualFold(strings.TrimSpace(approved), hash) {
+		return nil
+	}
+	return []diagnostics.Issue{{
+		Line:     r.Position,
+		Path:     r.Document.Path,
+		RepoName: r.RepoName,
+		Description: fmt.Sprintf("Requirement '%s' was modified after its approval with hash '%s', the hash of its text is now '%s'.",
+			r.ID, approved, hash),
+		Severity: diagnostics.IssueSeverityMajor,
+		Type:     diagnostics.IssueTypeApprovedTextChanged,
+	}}
+}
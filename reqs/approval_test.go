@@ -0,0 +1,47 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-115
+func TestReq_TextHash(t *testing.T) {
+	r := Req{Title: "Log file", Body: "The logs SHALL be written\nto a file."}
+	hash := r.TextHash()
+	assert.Len(t, hash, 8)
+
+	// Whitespace does not matter
+	assert.Equal(t, hash, (&Req{Title: " Log  file", Body: "\nThe logs SHALL be written to a file.\n\n"}).TextHash())
+	assert.NotEqual(t, hash, (&Req{Title: "Log file", Body: "The logs SHALL be written to two files."}).TextHash())
+	assert.NotEqual(t, hash, (&Req{Title: "Log files", Body: "The logs SHALL be written to a file."}).TextHash())
+}
+
+// @llr REQ-TRAQ-SWL-115
+func TestReq_CheckApproval(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	r := &Req{ID: "REQ-TEST-SWL-1", Title: "Log file", Body: "The logs SHALL be written to a file.", Position: 5,
+		Document: doc, RepoName: "repo", Attributes: map[string]string{}}
+	assert.Empty(t, r.checkApproval())
+
+	hash := r.TextHash()
+	r.Attributes[ApprovedHashAttribute] = hash
+	assert.Empty(t, r.checkApproval())
+
+	r.Body = "The logs SHALL be written to a remote server."
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:     5,
+		Path:     "TEST-138-SDD.md",
+		RepoName: "repo",
+		Description: "Requirement 'REQ-TEST-SWL-1' was modified after its approval with hash '" + hash +
+			"', the hash of its text is now '" + r.TextHash() + "'.",
+		Severity: diagnostics.IssueSeverityMajor,
+		Type:     diagnostics.IssueTypeApprovedTextChanged,
+	}}, r.checkApproval())
+
+	// The approval hash is not an unknown attribute
+	assert.Empty(t, r.checkAttributes())
+}
@@ -0,0 +1,146 @@
+package reqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// BuildArtifact is a file produced by the build, e.g. a binary, whose sources implement a requirement
+type BuildArtifact struct {
+	Name string
+	// Whether the artifact is part of the delivered product
+	Shipped bool `json:",omitempty"`
+}
+
+// The manifest produced by the build, listing the artifacts and the files they are built from. The sources are
+// relative to the root of the repository of the artifact, which is the repository declaring the manifest by default.
+type jsonBuildManifest struct {
+	Artifacts []struct {
+		Name     string         `json:"name"`
+		Shipped  bool           `json:"shipped"`
+		RepoName repos.RepoName `json:"repoName"`
+		Sources  []string       `json:"sources"`
+	} `json:"artifacts"`
+}
+
+// sourceFile identifies a source file of an artifact
+type sourceFile struct {
+	repoName repos.RepoName
+	path     string
+}
+
+// readBuildManifest returns the artifacts built from each source file listed in the manifest
+// @llr REQ-TRAQ-SWL-171
+func readBuildManifest(settings *config.BuildArtifacts) (map[sourceFile][]BuildArtifact, error) {
+	path := settings.Manifest
+	if !filepath.IsAbs(path) {
+		var err error
+		if path, err = repos.PathInRepo(settings.RepoName, settings.Manifest); err != nil {
+			return nil, errors.Wrapf(err, "Build manifest `%s` declared in config for repo `%s` cannot be found", settings.Manifest, settings.RepoName)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Build manifest `%s` declared in config for repo `%s` cannot be read", settings.Manifest, settings.RepoName)
+	}
+	var manifest jsonBuildManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "Build manifest `%s` declared in config for repo `%s` is not valid", settings.Manifest, settings.RepoName)
+	}
+
+	artifacts := make(map[sourceFile][]BuildArtifact)
+	for _, a := range manifest.Artifacts {
+		if a.Name == "" {
+			return nil, fmt.Errorf("Build manifest `%s` lists an artifact without name", settings.Manifest)
+		}
+		repoName := a.RepoName
+		if repoName == "" {
+			repoName = settings.RepoName
+		}
+		for _, source := range a.Sources {
+			key := sourceFile{repoName, filepath.ToSlash(filepath.Clean(source))}
+			artifacts[key] = append(artifacts[key], BuildArtifact{Name: a.Name, Shipped: a.Shipped})
+		}
+	}
+	return artifacts, nil
+}
+
+// LoadBuildArtifacts attaches to the requirements the artifacts of the build manifest whose sources contain the
+// implementation of the requirement, ordered by name. Tests do not count as implementation.
+// @llr REQ-TRAQ-SWL-171
+func (rg *ReqGraph) LoadBuildArtifacts(settings *config.BuildArtifacts) error {
+	artifacts, err := readBuildManifest(settings)
+	if err != nil {
+		return err
+	}
+	for _, r := range rg.Reqs {
+		r.Artifacts = nil
+		seen := make(map[BuildArtifact]bool)
+		for _, tag := range r.Tags {
+			if !tag.CodeFile.Type.Matches(code.CodeTypeImplementation) {
+				continue
+			}
+			for _, artifact := range artifacts[sourceFile{tag.CodeFile.RepoName, tag.CodeFile.Path}] {
+				if !seen[artifact] {
+					seen[artifact] = true
+					r.Artifacts = append(r.Artifacts, artifact)
+				}
+			}
+		}
+		sort.Slice(r.Artifacts, func(i, j int) bool { return r.Artifacts[i].Name < r.Artifacts[j].Name })
+	}
+	return nil
+}
+
+// checkUnshippedImplementations reports the requirements which are implemented but whose implementation is not
+// part of any shipped artifact, e.g. because it is not linked into the delivered binaries. Assumptions are ignored,
+// as their code only checks them. The severity of the issues is configured by the lint policy and defaults to a
+// warning.
+// @llr REQ-TRAQ-SWL-172
+func (rg *ReqGraph) checkUnshippedImplementations() []diagnostics.Issue {
+	severity, enabled := rg.lintSeverity(config.LintCheckUnshippedImplementation, diagnostics.IssueSeverityMinor)
+	if !enabled {
+		return nil
+	}
+
+	var issues []diagnostics.Issue
+	for _, r := range rg.Reqs {
+		if r.IsDeleted() || r.IsAssumption() || r.Document == nil {
+			continue
+		}
+		implemented, shipped := false, false
+		for _, tag := range r.Tags {
+			implemented = implemented || tag.CodeFile.Type.Matches(code.CodeTypeImplementation)
+		}
+		for _, artifact := range r.Artifacts {
+			shipped = shipped || artifact.Shipped
+		}
+		if !implemented || shipped {
+			continue
+		}
+		issues = append(issues, diagnostics.Issue{
+			Line:        r.Position,
+			Path:        r.Document.Path,
+			RepoName:    r.RepoName,
+			Description: fmt.Sprintf("Requirement `%s` is implemented by code which is not part of any shipped build artifact.", r.ID),
+			Severity:    severity,
+			Type:        diagnostics.IssueTypeUnshippedImplementation,
+		})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
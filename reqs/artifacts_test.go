@@ -0,0 +1,69 @@
+package reqs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-171, REQ-TRAQ-SWL-172
+func TestReqGraph_LoadBuildArtifacts(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "artifacts.json")
+	assert.NoError(t, os.WriteFile(manifest, []byte(`{"artifacts": [
+		{"name": "//app:server", "shipped": true, "sources": ["src/log.cc", "./src/net.cc"]},
+		{"name": "//tools:replay", "sources": ["src/log.cc", "src/replay.cc"]},
+		{"name": "//lib:crypto", "shipped": true, "repoName": "crypto", "sources": ["aes.cc"]}
+	]}`), 0644))
+
+	sdd := &config.Document{Path: "TEST-138-SDD.md"}
+	file := func(repoName, path string, codeType code.CodeType) *code.Code {
+		return &code.Code{CodeFile: code.CodeFile{RepoName: repos.RepoName(repoName), Path: path, Type: codeType}}
+	}
+	shipped := &Req{ID: "REQ-TEST-SWL-1", Document: sdd, RepoName: "repo", Position: 3,
+		Tags: []*code.Code{file("repo", "src/log.cc", code.CodeTypeImplementation), file("repo", "src/net.cc", code.CodeTypeImplementation)}}
+	unshipped := &Req{ID: "REQ-TEST-SWL-2", Document: sdd, RepoName: "repo", Position: 9,
+		Tags: []*code.Code{file("repo", "src/replay.cc", code.CodeTypeImplementation), file("repo", "src/log_test.cc", code.CodeTypeTests)}}
+	unbuilt := &Req{ID: "REQ-TEST-SWL-3", Document: sdd, RepoName: "repo", Position: 15,
+		Tags: []*code.Code{file("repo", "scripts/deploy.cc", code.CodeTypeImplementation)}}
+	// Tested but not implemented, or without code at all
+	tested := &Req{ID: "REQ-TEST-SWL-4", Document: sdd, RepoName: "repo", Position: 21,
+		Tags: []*code.Code{file("repo", "src/log.cc", code.CodeTypeTests)}}
+	assumption := &Req{ID: "ASM-TEST-SWL-1", Variant: ReqVariantAssumption, Document: sdd, RepoName: "repo", Position: 27,
+		Tags: []*code.Code{file("repo", "src/check.cc", code.CodeTypeImplementation)}}
+	rg := &ReqGraph{Reqs: map[string]*Req{}}
+	for _, r := range []*Req{shipped, unshipped, unbuilt, tested, assumption} {
+		rg.Reqs[r.ID] = r
+	}
+
+	assert.NoError(t, rg.LoadBuildArtifacts(&config.BuildArtifacts{Manifest: manifest, RepoName: "repo"}))
+	assert.Equal(t, []BuildArtifact{{Name: "//app:server", Shipped: true}, {Name: "//tools:replay"}}, shipped.Artifacts)
+	assert.Equal(t, []BuildArtifact{{Name: "//tools:replay"}}, unshipped.Artifacts)
+	assert.Empty(t, unbuilt.Artifacts)
+	assert.Empty(t, tested.Artifacts)
+
+	issue := func(r *Req) diagnostics.Issue {
+		return diagnostics.Issue{
+			Line:        r.Position,
+			Path:        "TEST-138-SDD.md",
+			RepoName:    "repo",
+			Description: "Requirement `" + r.ID + "` is implemented by code which is not part of any shipped build artifact.",
+			Severity:    diagnostics.IssueSeverityMinor,
+			Type:        diagnostics.IssueTypeUnshippedImplementation,
+		}
+	}
+	assert.Equal(t, []diagnostics.Issue{issue(unshipped), issue(unbuilt)}, rg.checkUnshippedImplementations())
+
+	// The lint policy disables the check
+	rg.ReqtraqConfig = &config.Config{LintPolicy: map[string]config.LintSeverity{config.LintCheckUnshippedImplementation: config.LintSeverityOff}}
+	assert.Empty(t, rg.checkUnshippedImplementations())
+
+	assert.NoError(t, os.WriteFile(manifest, []byte(`{"artifacts": [{"sources": ["src/log.cc"]}]}`), 0644))
+	err := rg.LoadBuildArtifacts(&config.BuildArtifacts{Manifest: manifest, RepoName: "repo"})
+	assert.EqualError(t, err, "Build manifest `"+manifest+"` lists an artifact without name")
+}
@@ -0,0 +1,90 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// The attribute holding the validation plan of an assumption
+const ValidationAttribute = "VALIDATION"
+
+// AssumptionValidation holds how an assumption is validated and which requirements rely on it
+type AssumptionValidation struct {
+	Assumption *Req
+	// The requirements owning the assumption, i.e. its parents
+	Owners []*Req
+	// The value of the Validation attribute of the assumption, empty if it has none
+	Plan string
+	// The code checking the assumption
+	Evidence []*code.Code
+}
+
+// IsValidated returns whether the assumption has a validation plan or code checking it
+// @llr REQ-TRAQ-SWL-166
+func (v AssumptionValidation) IsValidated() bool {
+	return v.Plan != "" || len(v.Evidence) > 0
+}
+
+// AssumptionValidations returns the validation of the assumptions of the graph which are not deleted, ordered by
+// repository, document and position
+// @llr REQ-TRAQ-SWL-167
+func (rg *ReqGraph) AssumptionValidations() []AssumptionValidation {
+	var validations []AssumptionValidation
+	for _, r := range rg.Reqs {
+		if !r.IsAssumption() || r.IsDeleted() || r.Document == nil {
+			continue
+		}
+		owners := append([]*Req(nil), r.Parents...)
+		sort.Slice(owners, func(i, j int) bool { return owners[i].ID < owners[j].ID })
+		validations = append(validations, AssumptionValidation{
+			Assumption: r,
+			Owners:     owners,
+			Plan:       r.Attributes[ValidationAttribute],
+			Evidence:   r.Tags,
+		})
+	}
+	sort.Slice(validations, func(i, j int) bool {
+		a, b := validations[i].Assumption, validations[j].Assumption
+		if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+		if a.Document.Path != b.Document.Path {
+			return a.Document.Path < b.Document.Path
+		}
+		return a.Position < b.Position
+	})
+	return validations
+}
+
+// checkAssumptionValidations reports the assumptions without validation plan nor code checking them, in the
+// documents whose assumptions have an optional Validation attribute. The assumptions whose schema requires it are
+// already reported as missing it, and the other schemas have no validation plans. The severity of the issues is
+// configured by the lint policy and defaults to a warning.
+// @llr REQ-TRAQ-SWL-166
+func (rg *ReqGraph) checkAssumptionValidations() []diagnostics.Issue {
+	severity, enabled := rg.lintSeverity(config.LintCheckUnvalidatedAssumption, diagnostics.IssueSeverityMinor)
+	if !enabled {
+		return nil
+	}
+
+	var issues []diagnostics.Issue
+	for _, v := range rg.AssumptionValidations() {
+		r := v.Assumption
+		if attribute, ok := r.Document.Schema.AsmAttributes[ValidationAttribute]; v.IsValidated() || !ok || attribute.Type == config.AttributeRequired {
+			continue
+		}
+		issues = append(issues, diagnostics.Issue{
+			Line:        r.Position,
+			Path:        r.Document.Path,
+			RepoName:    r.RepoName,
+			Description: fmt.Sprintf("Assumption `%s` has no validation plan: it has no Validation attribute and no code checks it.", r.ID),
+			Severity:    severity,
+			Type:        diagnostics.IssueTypeUnvalidatedAssumption,
+		})
+	}
+	return issues
+}
@@ -0,0 +1,55 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-166, REQ-TRAQ-SWL-167
+func TestReqGraph_AssumptionValidations(t *testing.T) {
+	sdd := &config.Document{Path: "TEST-138-SDD.md", Schema: config.Schema{AsmAttributes: map[string]*config.Attribute{
+		ValidationAttribute: {Type: config.AttributeOptional}}}}
+	swl1 := &Req{ID: "REQ-TEST-SWL-1", Document: sdd, RepoName: "repo", Position: 3}
+	swl2 := &Req{ID: "REQ-TEST-SWL-2", Document: sdd, RepoName: "repo", Position: 9}
+	check := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a_test.go", Type: code.CodeTypeTests}, Tag: "TestRange"}
+	planned := &Req{ID: "ASM-TEST-SWL-1", Variant: ReqVariantAssumption, Document: sdd, RepoName: "repo", Position: 20,
+		Parents: []*Req{swl2, swl1}, Attributes: map[string]string{ValidationAttribute: "Flight tests"}}
+	checked := &Req{ID: "ASM-TEST-SWL-2", Variant: ReqVariantAssumption, Document: sdd, RepoName: "repo", Position: 25,
+		Parents: []*Req{swl1}, Tags: []*code.Code{check}}
+	unvalidated := &Req{ID: "ASM-TEST-SWL-3", Variant: ReqVariantAssumption, Document: sdd, RepoName: "repo", Position: 30,
+		Parents: []*Req{swl2}}
+	deleted := &Req{ID: "ASM-TEST-SWL-4", Variant: ReqVariantAssumption, Title: "DELETED", Document: sdd, RepoName: "repo", Position: 35}
+	rg := &ReqGraph{Reqs: map[string]*Req{}}
+	for _, r := range []*Req{swl1, swl2, planned, checked, unvalidated, deleted} {
+		rg.Reqs[r.ID] = r
+	}
+
+	assert.Equal(t, []AssumptionValidation{
+		{Assumption: planned, Owners: []*Req{swl1, swl2}, Plan: "Flight tests"},
+		{Assumption: checked, Owners: []*Req{swl1}, Evidence: []*code.Code{check}},
+		{Assumption: unvalidated, Owners: []*Req{swl2}},
+	}, rg.AssumptionValidations())
+
+	expected := []diagnostics.Issue{{
+		Line:        30,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Assumption `ASM-TEST-SWL-3` has no validation plan: it has no Validation attribute and no code checks it.",
+		Severity:    diagnostics.IssueSeverityMinor,
+		Type:        diagnostics.IssueTypeUnvalidatedAssumption,
+	}}
+	assert.Equal(t, expected, rg.checkAssumptionValidations())
+
+	// The lint policy disables the check
+	rg.ReqtraqConfig = &config.Config{LintPolicy: map[string]config.LintSeverity{config.LintCheckUnvalidatedAssumption: config.LintSeverityOff}}
+	assert.Empty(t, rg.checkAssumptionValidations())
+
+	// Without a Validation attribute in the schema, the assumptions have no validation plans to check
+	rg.ReqtraqConfig = nil
+	delete(sdd.Schema.AsmAttributes, ValidationAttribute)
+	assert.Empty(t, rg.checkAssumptionValidations())
+}
@@ -0,0 +1,149 @@
+package reqs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+var (
+	// The heading of the attributes section of a requirement
+	reAttributesHeading = regexp.MustCompile(`^#{2,6} Attributes:$`)
+	// An attribute line, capturing the name of the attribute
+	reAttributeLine = regexp.MustCompile(`^- (.+?):`)
+)
+
+// attributeRank returns the position of the attribute in the canonical order, the attributes which are not listed
+// coming after the listed ones
+// @llr REQ-TRAQ-SWL-143
+func attributeRank(order []string, key string) int {
+	for i, name := range order {
+		if name == key {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// checkAttributeLayout reports lint issues if the attributes of the requirement are not in the canonical order or
+// if other sections follow its attributes section. An empty order disables the checks.
+// @llr REQ-TRAQ-SWL-143
+func (r *Req) checkAttributeLayout(order []string) []diagnostics.Issue {
+	if len(order) == 0 {
+		return nil
+	}
+	var issues []diagnostics.Issue
+	for i := 1; i < len(r.AttributeKeys); i++ {
+		if attributeRank(order, r.AttributeKeys[i-1]) > attributeRank(order, r.AttributeKeys[i]) {
+			issues = append(issues, diagnostics.Issue{
+				Line:     r.Position,
+				Path:     r.Document.Path,
+				RepoName: r.RepoName,
+				Description: fmt.Sprintf("Attribute `%s` of requirement `%s` must come before attribute `%s`. "+
+					"Run reqtraq fmt to order the attributes.", r.AttributeKeys[i], r.ID, r.AttributeKeys[i-1]),
+				Severity: diagnostics.IssueSeverityNote,
+				Type:     diagnostics.IssueTypeAttributeLayout,
+			})
+			break
+		}
+	}
+	if r.SectionsAfterAttributes {
+		issues = append(issues, diagnostics.Issue{
+			Line:     r.Position,
+			Path:     r.Document.Path,
+			RepoName: r.RepoName,
+			Description: fmt.Sprintf("The attributes section of requirement `%s` must be its last section. "+
+				"Run reqtraq fmt to move it.", r.ID),
+			Severity: diagnostics.IssueSeverityNote,
+			Type:     diagnostics.IssueTypeAttributeLayout,
+		})
+	}
+	return issues
+}
+
+// trimBlankLines returns the lines without the empty lines at their end
+// @llr REQ-TRAQ-SWL-143
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// FormatAttributes returns the content of a file of the given document with the attributes of the requirements
+// defined in headings sorted in the given canonical order, and with the sections following their attributes section
+// moved before it. Attributes with the same rank keep their relative order.
+// @llr REQ-TRAQ-SWL-143
+func FormatAttributes(content string, doc *config.Document, order []string) string {
+	reID := doc.ReqSpec.Format().RequirementsRegexp(doc.ReqSpec.Prefix, doc.ReqSpec.Level)
+	lines := strings.Split(content, "\n")
+
+	type heading struct{ index, level int }
+	var headings []heading
+	for i, line := range lines {
+		if parts := reATXHeading.FindStringSubmatch(line); parts != nil {
+			if loc := reID.FindStringIndex(parts[3]); loc != nil && loc[0] == 0 {
+				headings = append(headings, heading{i, len(parts[1])})
+			}
+		}
+	}
+
+	// The requirements are formatted from the last one, so the indices of the previous ones do not change
+	for h := len(headings) - 1; h >= 0; h-- {
+		start, level := headings[h].index, headings[h].level
+		end := reqEnd(lines, start, level)
+		attrs := -1
+		for i := start + 1; i < end; i++ {
+			if reAttributesHeading.MatchString(lines[i]) {
+				attrs = i
+				break
+			}
+		}
+		if attrs < 0 {
+			continue
+		}
+		sections := end
+		for i := attrs + 1; i < end; i++ {
+			if reATXHeading.MatchString(lines[i]) {
+				sections = i
+				break
+			}
+		}
+
+		// Each attribute is made of its line and of the lines continuing its value
+		var prefix []string
+		var entries [][]string
+		for _, line := range trimBlankLines(lines[attrs+1 : sections]) {
+			if reAttributeLine.MatchString(line) {
+				entries = append(entries, []string{line})
+			} else if len(entries) > 0 {
+				entries[len(entries)-1] = append(entries[len(entries)-1], line)
+			} else {
+				prefix = append(prefix, line)
+			}
+		}
+		rank := func(entry []string) int {
+			key := strings.ToUpper(reAttributeLine.FindStringSubmatch(entry[0])[1])
+			if key == "PARENT" {
+				key = "PARENTS"
+			}
+			return attributeRank(order, key)
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return rank(entries[i]) < rank(entries[j]) })
+
+		formatted := append([]string{}, trimBlankLines(lines[start:attrs])...)
+		if sections < end {
+			formatted = append(append(formatted, ""), trimBlankLines(lines[sections:end])...)
+		}
+		formatted = append(append(formatted, "", lines[attrs]), prefix...)
+		for _, entry := range entries {
+			formatted = append(formatted, entry...)
+		}
+		lines = append(append(append([]string{}, lines[:start]...), formatted...), lines[end:]...)
+	}
+	return strings.Join(lines, "\n")
+}
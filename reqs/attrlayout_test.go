@@ -0,0 +1,127 @@
+package reqs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-143
+func TestReq_CheckAttributeLayout(t *testing.T) {
+	doc := config.Document{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	content := `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Safety Impact: None
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+
+#### Attributes:
+- Safety Impact: None
+- Notes: Daily
+- Parents: REQ-TEST-SWH-2
+
+#### Notes
+
+Rotated by logrotate.
+`
+	requirements, _, err := parseMarkdownContent("repo", &doc, strings.NewReader(content))
+	if !assert.NoError(t, err) || !assert.Len(t, requirements, 2) {
+		return
+	}
+	assert.Equal(t, []string{"SAFETY IMPACT", "NOTES", "PARENTS"}, requirements[1].AttributeKeys)
+	assert.False(t, requirements[0].SectionsAfterAttributes)
+	assert.True(t, requirements[1].SectionsAfterAttributes)
+	// The sections following the attributes section are part of the body, as once it is formatted
+	assert.Equal(t, "\nThe logs SHALL be rotated.\n\n#### Notes\n\nRotated by logrotate.\n", requirements[1].Body)
+	assert.Equal(t, "REQ-TEST-SWH-2", requirements[1].Attributes["PARENTS"])
+	formatted, _, err := parseMarkdownContent("repo", &doc, strings.NewReader(FormatAttributes(content, &doc, nil)))
+	if assert.NoError(t, err) && assert.Len(t, formatted, 2) {
+		assert.Equal(t, requirements[1].Body, formatted[1].Body)
+		assert.False(t, formatted[1].SectionsAfterAttributes)
+	}
+
+	order := []string{"PARENTS", "SAFETY IMPACT"}
+	assert.Empty(t, requirements[0].checkAttributeLayout(order))
+	issues := requirements[1].checkAttributeLayout(order)
+	if assert.Len(t, issues, 2) {
+		assert.Equal(t, "Attribute `PARENTS` of requirement `REQ-TEST-SWL-2` must come before attribute `NOTES`. Run reqtraq fmt to order the attributes.", issues[0].Description)
+		assert.Equal(t, "The attributes section of requirement `REQ-TEST-SWL-2` must be its last section. Run reqtraq fmt to move it.", issues[1].Description)
+		assert.Equal(t, diagnostics.IssueSeverityNote, issues[1].Severity)
+		assert.Equal(t, 11, issues[1].Line)
+	}
+	// The checks are disabled without a canonical order
+	assert.Empty(t, requirements[1].checkAttributeLayout(nil))
+}
+
+// @llr REQ-TRAQ-SWL-143
+func TestFormatAttributes(t *testing.T) {
+	doc := config.Document{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	content := `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Safety Impact: None
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+
+#### Attributes:
+- Safety Impact: None
+- Notes: Daily,
+  at midnight
+- Parent: REQ-TEST-SWH-2
+
+#### Notes
+
+Rotated by logrotate.
+
+## Appendix
+`
+	order := []string{"PARENTS", "SAFETY IMPACT"}
+	formatted := FormatAttributes(content, &doc, order)
+	assert.Equal(t, `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Safety Impact: None
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+
+#### Notes
+
+Rotated by logrotate.
+
+#### Attributes:
+- Parent: REQ-TEST-SWH-2
+- Safety Impact: None
+- Notes: Daily,
+  at midnight
+
+## Appendix
+`, formatted)
+
+	// Formatting is idempotent
+	assert.Equal(t, formatted, FormatAttributes(formatted, &doc, order))
+}
@@ -0,0 +1,162 @@
+package reqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The version of the format of the baseline files, increased when a change of the format prevents reading the
+// baselines created before
+const BaselineVersion = 1
+
+// Baseline is a snapshot of the requirements of the graph, recorded for the change control between releases. Unlike
+// the raw graph, its format is versioned, so baselines created by older versions of reqtraq can be compared.
+type Baseline struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// The time the baseline was created, in RFC 3339 format
+	Created string `json:"created"`
+	// The commit checked out in each repository, empty when running without git
+	Revisions    map[repos.RepoName]string `json:"revisions,omitempty"`
+	Requirements []BaselineReq             `json:"requirements"`
+}
+
+// BaselineReq is a requirement recorded in a baseline
+type BaselineReq struct {
+	ID         string            `json:"id"`
+	RepoName   repos.RepoName    `json:"repoName"`
+	Document   string            `json:"document"`
+	Title      string            `json:"title"`
+	Body       string            `json:"body,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	ParentIds  []string          `json:"parents,omitempty"`
+}
+
+// AttributeDiff is a change of the value of an attribute of a requirement since a baseline
+type AttributeDiff struct {
+	ID        string
+	Attribute string
+	// The values in the baseline and in the graph, empty when the attribute is not set
+	Old string
+	New string
+}
+
+// BaselineDiff holds the changes of the requirements graph since a baseline: the added, modified and deleted
+// requirements, the changes of the attributes of the modified requirements and the added and removed links
+type BaselineDiff struct {
+	GraphDiff
+	// The attribute changes ordered by requirement ID number and attribute name
+	AttributeChanges []AttributeDiff
+}
+
+// NewBaseline returns a baseline of the requirements of the graph, ordered by ID
+// @llr REQ-TRAQ-SWL-193
+func NewBaseline(rg *ReqGraph, name, created string, revisions map[repos.RepoName]string) *Baseline {
+	baseline := &Baseline{Version: BaselineVersion, Name: name, Created: created, Revisions: revisions, Requirements: []BaselineReq{}}
+	for _, r := range rg.Reqs {
+		if r.Stub {
+			continue
+		}
+		recorded := BaselineReq{ID: r.ID, RepoName: r.RepoName, Title: r.Title, Body: strings.TrimSpace(r.Body), ParentIds: r.ParentIds}
+		if r.Document != nil {
+			recorded.Document = r.Document.Path
+		}
+		if len(r.Attributes) > 0 {
+			recorded.Attributes = r.Attributes
+		}
+		baseline.Requirements = append(baseline.Requirements, recorded)
+	}
+	sort.Slice(baseline.Requirements, func(i, j int) bool { return baseline.Requirements[i].ID < baseline.Requirements[j].ID })
+	return baseline
+}
+
+// WriteBaseline writes the baseline as indented JSON
+// @llr REQ-TRAQ-SWL-193
+func WriteBaseline(w io.Writer, baseline *Baseline) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(baseline)
+}
+
+// ReadBaseline reads a baseline, which must have a version this version of reqtraq can read
+// @llr REQ-TRAQ-SWL-193
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var baseline Baseline
+	if err := json.NewDecoder(r).Decode(&baseline); err != nil {
+		return nil, fmt.Errorf("Invalid baseline: %v", err)
+	}
+	if baseline.Version < 1 || baseline.Version > BaselineVersion {
+		return nil, fmt.Errorf("Baseline `%s` has version %d, expected at most %d", baseline.Name, baseline.Version, BaselineVersion)
+	}
+	return &baseline, nil
+}
+
+// graph returns a graph of the requirements of the baseline, to be compared with the given graph. The requirements
+// are numbered as in the given graph, or else as in the default ID format, so the changes are ordered the same way.
+// @llr REQ-TRAQ-SWL-193
+func (baseline *Baseline) graph(current *ReqGraph) *ReqGraph {
+	rg := &ReqGraph{Reqs: make(map[string]*Req, len(baseline.Requirements))}
+	for _, recorded := range baseline.Requirements {
+		r := &Req{ID: recorded.ID, RepoName: recorded.RepoName, Title: recorded.Title, Body: recorded.Body,
+			Attributes: recorded.Attributes, ParentIds: recorded.ParentIds}
+		if r.Attributes == nil {
+			r.Attributes = map[string]string{}
+		}
+		if existing, ok := current.Reqs[r.ID]; ok {
+			r.IDNumber = existing.IDNumber
+		} else if _, _, number, err := extractIDParts(r.ID, config.DefaultIDFormat); err == nil {
+			r.IDNumber = number
+		}
+		rg.Reqs[r.ID] = r
+	}
+	return rg
+}
+
+// Compare returns the changes of the requirements of the graph since the baseline
+// @llr REQ-TRAQ-SWL-193
+func (baseline *Baseline) Compare(rg *ReqGraph) BaselineDiff {
+	current := &ReqGraph{Reqs: make(map[string]*Req, len(rg.Reqs))}
+	for id, r := range rg.Reqs {
+		if r.Stub {
+			continue
+		}
+		if r.Attributes == nil {
+			// Compared with the requirements of the baseline, which have no attributes recorded when empty
+			copied := *r
+			copied.Attributes = map[string]string{}
+			r = &copied
+		}
+		current.Reqs[id] = r
+	}
+	before := baseline.graph(current)
+	diff := BaselineDiff{GraphDiff: DiffGraphs(before, current)}
+	for _, change := range diff.Changes {
+		if change.Kind != ChangeModified {
+			continue
+		}
+		oldAttributes, newAttributes := before.Reqs[change.ID].Attributes, current.Reqs[change.ID].Attributes
+		var names []string
+		for name := range oldAttributes {
+			if _, ok := newAttributes[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		for name, value := range newAttributes {
+			if oldAttributes[name] != value {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			diff.AttributeChanges = append(diff.AttributeChanges,
+				AttributeDiff{ID: change.ID, Attribute: name, Old: oldAttributes[name], New: newAttributes[name]})
+		}
+	}
+	return diff
+}
@@ -0,0 +1,90 @@
+package reqs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// @llr REQ-TRAQ-SWL-193
+func TestBaseline_WriteRead(t *testing.T) {
+	doc := &config.Document{Path: "TEST-100-ORD.md"}
+	rg := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SYS-1": {ID: "REQ-TEST-SYS-1", IDNumber: 1, RepoName: "test", Document: doc, Title: "One",
+			Body: "Body one\n", Attributes: map[string]string{"SAFETY IMPACT": "None"}},
+		"REQ-TEST-SYS-2": {ID: "REQ-TEST-SYS-2", IDNumber: 2, RepoName: "test", Document: doc, Title: "Two",
+			ParentIds: []string{"REQ-TEST-SYS-1"}},
+		"REQ-OTHER-SYS-1": {ID: "REQ-OTHER-SYS-1", IDNumber: 1, Stub: true},
+	}}
+
+	baseline := NewBaseline(rg, "v1.0", "2024-01-02T03:04:05Z", map[repos.RepoName]string{"test": "abc123"})
+	var buf bytes.Buffer
+	require.NoError(t, WriteBaseline(&buf, baseline))
+	read, err := ReadBaseline(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, baseline, read)
+	assert.Equal(t, BaselineVersion, read.Version)
+	assert.Equal(t, []BaselineReq{
+		{ID: "REQ-TEST-SYS-1", RepoName: "test", Document: "TEST-100-ORD.md", Title: "One", Body: "Body one",
+			Attributes: map[string]string{"SAFETY IMPACT": "None"}},
+		{ID: "REQ-TEST-SYS-2", RepoName: "test", Document: "TEST-100-ORD.md", Title: "Two",
+			ParentIds: []string{"REQ-TEST-SYS-1"}},
+	}, read.Requirements)
+
+	assert.Empty(t, baseline.Compare(rg).Changes)
+}
+
+// @llr REQ-TRAQ-SWL-193
+func TestBaseline_ReadUnknownVersion(t *testing.T) {
+	_, err := ReadBaseline(strings.NewReader(`{"version": 99, "name": "future", "requirements": []}`))
+	assert.EqualError(t, err, "Baseline `future` has version 99, expected at most 1")
+
+	_, err = ReadBaseline(strings.NewReader(`{"name": "unversioned"}`))
+	assert.EqualError(t, err, "Baseline `unversioned` has version 0, expected at most 1")
+
+	_, err = ReadBaseline(strings.NewReader(`[`))
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-193
+func TestBaseline_Compare(t *testing.T) {
+	baseline := &Baseline{Version: BaselineVersion, Name: "v1.0", Requirements: []BaselineReq{
+		{ID: "REQ-TEST-SWH-1", Title: "One"},
+		{ID: "REQ-TEST-SWH-2", Title: "Two", Attributes: map[string]string{"STATUS": "Draft", "OWNER": "Team"}},
+		{ID: "REQ-TEST-SWL-1", Title: "Low one", ParentIds: []string{"REQ-TEST-SWH-1"}},
+		{ID: "REQ-TEST-SWL-2", Title: "Low two", ParentIds: []string{"REQ-TEST-SWH-2"}},
+	}}
+	rg := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One"},
+		"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two",
+			Attributes: map[string]string{"STATUS": "Approved", "VERIFICATION": "Test"}},
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Low one",
+			ParentIds: []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2"}},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", IDNumber: 3, Title: "Low three", ParentIds: []string{"REQ-TEST-SWH-1"}},
+	}}
+
+	diff := baseline.Compare(rg)
+	assert.Equal(t, []ReqChange{
+		{ID: "REQ-TEST-SWH-2", Kind: ChangeModified},
+		{ID: "REQ-TEST-SWL-2", Kind: ChangeDeleted},
+		{ID: "REQ-TEST-SWL-3", Kind: ChangeAdded},
+	}, diff.Changes)
+	assert.Equal(t, []AttributeDiff{
+		{ID: "REQ-TEST-SWH-2", Attribute: "OWNER", Old: "Team", New: ""},
+		{ID: "REQ-TEST-SWH-2", Attribute: "STATUS", Old: "Draft", New: "Approved"},
+		{ID: "REQ-TEST-SWH-2", Attribute: "VERIFICATION", Old: "", New: "Test"},
+	}, diff.AttributeChanges)
+	assert.Equal(t, []Link{
+		{Parent: "REQ-TEST-SWH-2", Child: "REQ-TEST-SWL-1"},
+		{Parent: "REQ-TEST-SWH-1", Child: "REQ-TEST-SWL-3"},
+	}, diff.AddedLinks)
+	assert.Equal(t, []Link{
+		{Parent: "REQ-TEST-SWH-2", Child: "REQ-TEST-SWL-2"},
+	}, diff.RemovedLinks)
+}
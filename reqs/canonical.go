@@ -0,0 +1,88 @@
+package reqs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// codeLess orders code by repository, path, line, name and architectures
+// @llr REQ-TRAQ-SWL-183
+func codeLess(a, b *code.Code) bool {
+	if a.CodeFile.RepoName != b.CodeFile.RepoName {
+		return a.CodeFile.RepoName < b.CodeFile.RepoName
+	}
+	if a.CodeFile.Path != b.CodeFile.Path {
+		return a.CodeFile.Path < b.CodeFile.Path
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	if a.Tag != b.Tag {
+		return a.Tag < b.Tag
+	}
+	for i := 0; i < len(a.Archs) && i < len(b.Archs); i++ {
+		if a.Archs[i] != b.Archs[i] {
+			return a.Archs[i] < b.Archs[i]
+		}
+	}
+	return len(a.Archs) < len(b.Archs)
+}
+
+// issueLess orders issues by repository, path, line, type, severity and description
+// @llr REQ-TRAQ-SWL-183
+func issueLess(a, b diagnostics.Issue) bool {
+	if a.RepoName != b.RepoName {
+		return a.RepoName < b.RepoName
+	}
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Severity != b.Severity {
+		return a.Severity < b.Severity
+	}
+	return a.Description < b.Description
+}
+
+// Canonicalize orders the lists of the graph whose order depends on the order its maps are walked in while building
+// it: the issues, the code of each repository and of each requirement and the requirements of each flow tag. The
+// maps are serialized with ordered keys, so graphs built from the same inputs are serialized identically once
+// canonicalized. The order of the parents and of the links written in the documents and code is kept.
+// @llr REQ-TRAQ-SWL-183
+func (rg *ReqGraph) Canonicalize() {
+	for i := range rg.Issues {
+		sort.Strings(rg.Issues[i].Archs)
+	}
+	sort.SliceStable(rg.Issues, func(i, j int) bool { return issueLess(rg.Issues[i], rg.Issues[j]) })
+	for _, tags := range rg.CodeTags {
+		sort.SliceStable(tags, func(i, j int) bool { return codeLess(tags[i], tags[j]) })
+	}
+	for _, r := range rg.Reqs {
+		sort.SliceStable(r.Tags, func(i, j int) bool { return codeLess(r.Tags[i], r.Tags[j]) })
+	}
+	for _, f := range rg.FlowTags {
+		sort.SliceStable(f.Reqs, func(i, j int) bool { return f.Reqs[i].ID < f.Reqs[j].ID })
+	}
+}
+
+// ContentHash returns the SHA-256 digest of the JSON serialization of the given value, which identifies the content
+// of a canonicalized graph, e.g. to detect that an export did not change
+// @llr REQ-TRAQ-SWL-183
+func ContentHash(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(digest[:]), nil
+}
@@ -0,0 +1,59 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-183
+func TestReqGraph_Canonicalize(t *testing.T) {
+	newGraph := func(reversed bool) *ReqGraph {
+		f := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Tag: "f", Line: 3}
+		g := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Tag: "g", Line: 9}
+		h := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "b.go"}, Tag: "h", Line: 1}
+		first := &Req{ID: "REQ-TEST-SWL-1", Tags: []*code.Code{g, f}}
+		second := &Req{ID: "REQ-TEST-SWL-2", Tags: []*code.Code{h}}
+		rg := &ReqGraph{
+			Reqs:     map[string]*Req{first.ID: first, second.ID: second},
+			CodeTags: map[repos.RepoName][]*code.Code{"repo": {h, g, f}},
+			FlowTags: map[string]*Flow{"TEST-CF-1": {ID: "TEST-CF-1", Reqs: []*Req{second, first}}},
+			Issues: []diagnostics.Issue{
+				{RepoName: "repo", Path: "b.go", Line: 1, Description: "Second", Archs: []string{"x86", "arm"}},
+				{RepoName: "repo", Path: "a.go", Line: 9, Description: "First"},
+			},
+		}
+		if reversed {
+			rg.Issues[0], rg.Issues[1] = rg.Issues[1], rg.Issues[0]
+			rg.CodeTags["repo"] = []*code.Code{f, h, g}
+			first.Tags = []*code.Code{f, g}
+			rg.FlowTags["TEST-CF-1"].Reqs = []*Req{first, second}
+		}
+		return rg
+	}
+
+	rg := newGraph(false)
+	rg.Canonicalize()
+	assert.Equal(t, []string{"First", "Second"}, []string{rg.Issues[0].Description, rg.Issues[1].Description})
+	assert.Equal(t, []string{"arm", "x86"}, rg.Issues[1].Archs)
+	tags := rg.CodeTags["repo"]
+	assert.Equal(t, []string{"f", "g", "h"}, []string{tags[0].Tag, tags[1].Tag, tags[2].Tag})
+	assert.Equal(t, []string{"f", "g"}, []string{rg.Reqs["REQ-TEST-SWL-1"].Tags[0].Tag, rg.Reqs["REQ-TEST-SWL-1"].Tags[1].Tag})
+	assert.Equal(t, "REQ-TEST-SWL-1", rg.FlowTags["TEST-CF-1"].Reqs[0].ID)
+
+	// Graphs holding the same content in a different order have the same hash once canonicalized
+	other := newGraph(true)
+	before, err := ContentHash(other)
+	assert.NoError(t, err)
+	other.Canonicalize()
+	after, err := ContentHash(other)
+	assert.NoError(t, err)
+	expected, err := ContentHash(rg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, expected, before)
+	assert.Equal(t, expected, after)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", after)
+}
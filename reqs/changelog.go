@@ -0,0 +1,284 @@
+package reqs
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// ChangeKind describes how a requirement changed in a commit
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeModified
+	ChangeDeleted
+)
+
+// String returns the description of the kind of change used in changelogs
+// @llr REQ-TRAQ-SWL-104
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "Added"
+	case ChangeModified:
+		return "Modified"
+	case ChangeDeleted:
+		return "Deleted"
+	}
+	return "Unknown"
+}
+
+// ReqChange is a change of a single requirement
+type ReqChange struct {
+	ID   string
+	Kind ChangeKind
+}
+
+// ChangelogEntry holds the requirements changed by a commit
+type ChangelogEntry struct {
+	Commit  repos.Commit
+	Changes []ReqChange
+}
+
+// AttributeChange is a change of the value of an attribute of a requirement in a commit
+type AttributeChange struct {
+	Commit    repos.Commit
+	Attribute string
+	// The values before and after the commit, empty when the requirement or the attribute was not defined
+	Old string
+	New string
+}
+
+// parseRevision parses the requirements of a document at the given git revision. No requirements are returned if
+// the document does not exist at that revision. The fragments of a document split in several files which do not
+// exist at that revision are skipped.
+// @llr REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-130
+func parseRevision(repoName repos.RepoName, doc *config.Document, revision string) ([]*Req, error) {
+	contents := []string{}
+	for _, file := range doc.Files() {
+		content, ok, err := repos.FileAtRevision(repoName, file, revision)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			contents = append(contents, content)
+		}
+	}
+	if len(contents) == 0 {
+		return nil, nil
+	}
+	reqs, _, err := parseMarkdownContent(repoName, doc, strings.NewReader(config.JoinFragments(contents)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse `%s` at revision `%s`", doc.Path, revision)
+	}
+	return reqs, nil
+}
+
+// diffRequirements returns the changes between two versions of the requirements of a document, ordered by
+// requirement ID number. Requirements marked as deleted count as deleted.
+// @llr REQ-TRAQ-SWL-104
+func diffRequirements(before, after []*Req) []ReqChange {
+	beforeByID := make(map[string]*Req, len(before))
+	for _, r := range before {
+		if !r.IsDeleted() {
+			beforeByID[r.ID] = r
+		}
+	}
+
+	var changed []*Req
+	kinds := make(map[string]ChangeKind)
+	for _, r := range after {
+		old, existed := beforeByID[r.ID]
+		delete(beforeByID, r.ID)
+		switch {
+		case r.IsDeleted():
+			if existed {
+				changed = append(changed, r)
+				kinds[r.ID] = ChangeDeleted
+			}
+		case !existed:
+			changed = append(changed, r)
+			kinds[r.ID] = ChangeAdded
+		case old.Title != r.Title || strings.TrimSpace(old.Body) != strings.TrimSpace(r.Body) ||
+			!reflect.DeepEqual(old.Attributes, r.Attributes):
+			changed = append(changed, r)
+			kinds[r.ID] = ChangeModified
+		}
+	}
+	// Requirements removed from the document instead of being marked as deleted
+	for _, r := range beforeByID {
+		changed = append(changed, r)
+		kinds[r.ID] = ChangeDeleted
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].IDNumber != changed[j].IDNumber {
+			return changed[i].IDNumber < changed[j].IDNumber
+		}
+		return changed[i].ID < changed[j].ID
+	})
+	changes := make([]ReqChange, 0, len(changed))
+	for _, r := range changed {
+		changes = append(changes, ReqChange{ID: r.ID, Kind: kinds[r.ID]})
+	}
+	return changes
+}
+
+// Changelog returns the history of the requirements of a document after the given git reference, one entry per
+// commit which added, modified or deleted requirements, oldest first. The whole history is returned if the
+// reference is empty.
+// @llr REQ-TRAQ-SWL-104
+func Changelog(repoName repos.RepoName, doc *config.Document, since string) ([]ChangelogEntry, error) {
+	commits, err := repos.FileCommits(repoName, doc.Path, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []*Req
+	if since != "" {
+		if previous, err = parseRevision(repoName, doc, since); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]ChangelogEntry, 0)
+	for _, commit := range commits {
+		current, err := parseRevision(repoName, doc, commit.ID)
+		if err != nil {
+			return nil, err
+		}
+		if changes := diffRequirements(previous, current); len(changes) > 0 {
+			entries = append(entries, ChangelogEntry{Commit: commit, Changes: changes})
+		}
+		previous = current
+	}
+	return entries, nil
+}
+
+// AttributeHistory returns the changes of the values of the given attributes of the requirements of a document over
+// its whole git history, by requirement ID, oldest first. The value a requirement is added with counts as a change.
+// Requirements removed from the document keep their history.
+// @llr REQ-TRAQ-SWL-137
+func AttributeHistory(repoName repos.RepoName, doc *config.Document, attributes []string) (map[string][]AttributeChange, error) {
+	commits, err := repos.FileCommits(repoName, doc.Path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[string][]AttributeChange)
+	previous := make(map[string]map[string]string)
+	for _, commit := range commits {
+		current, err := parseRevision(repoName, doc, commit.ID)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]map[string]string, len(current))
+		for _, r := range current {
+			values[r.ID] = r.Attributes
+			for _, attribute := range attributes {
+				name := strings.ToUpper(attribute)
+				before, after := previous[r.ID][name], r.Attributes[name]
+				if before != after {
+					history[r.ID] = append(history[r.ID], AttributeChange{Commit: commit, Attribute: name, Old: before, New: after})
+				}
+			}
+		}
+		previous = values
+	}
+	return history, nil
+}
+
+// LoadAttributeHistory sets the history of the given attributes of the requirements of the documents of all the
+// repositories, to be included in the exports and reports
+// @llr REQ-TRAQ-SWL-137
+func (rg *ReqGraph) LoadAttributeHistory(attributes []string) error {
+	for repoName, repo := range rg.ReqtraqConfig.Repos {
+		for i := range repo.Documents {
+			doc := &repo.Documents[i]
+			history, err := AttributeHistory(repoName, doc, attributes)
+			if err != nil {
+				return errors.Wrapf(err, "history of `%s`", doc.Path)
+			}
+			for id, changes := range history {
+				if r, ok := rg.Reqs[id]; ok && r.RepoName == repoName && r.Document != nil && r.Document.Path == doc.Path {
+					r.AttributeHistory = changes
+					if r.Redacted {
+						r.AttributeHistory = r.redactedHistory(changes)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WriteAttributeHistory writes the changes of the attributes of the requirements as a markdown table, ordered by
+// requirement ID number and by commit
+// @llr REQ-TRAQ-SWL-137
+func WriteAttributeHistory(w io.Writer, history map[string][]AttributeChange) error {
+	if _, err := fmt.Fprintln(w, "| Requirement | Attribute | Old | New | Date | Author | Commit | Description |\n| --- | --- | --- | --- | --- | --- | --- | --- |"); err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(history))
+	for id := range history {
+		ids = append(ids, id)
+	}
+	// The IDs end with the ID number, e.g. REQ-TEST-SWL-12
+	idNumber := func(id string) int {
+		number, _ := strconv.Atoi(id[strings.LastIndex(id, "-")+1:])
+		return number
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ni, nj := idNumber(ids[i]), idNumber(ids[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		for _, change := range history[id] {
+			_, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s | %s |\n", id, change.Attribute,
+				strings.ReplaceAll(change.Old, "|", "\\|"), strings.ReplaceAll(change.New, "|", "\\|"), change.Commit.Date,
+				change.Commit.Author, change.Commit.ID, strings.ReplaceAll(change.Commit.Subject, "|", "\\|"))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteChangelog writes the changelog entries as a markdown table which can be pasted in the revision history
+// of the document
+// @llr REQ-TRAQ-SWL-104
+func WriteChangelog(w io.Writer, entries []ChangelogEntry) error {
+	if _, err := fmt.Fprintln(w, "| Date | Author | Commit | Description | Requirements |\n| --- | --- | --- | --- | --- |"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var byKind [3][]string
+		for _, change := range entry.Changes {
+			byKind[change.Kind] = append(byKind[change.Kind], change.ID)
+		}
+		var changes []string
+		for kind, ids := range byKind {
+			if len(ids) > 0 {
+				changes = append(changes, fmt.Sprintf("%s %s", ChangeKind(kind), strings.Join(ids, ", ")))
+			}
+		}
+		_, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", entry.Commit.Date, entry.Commit.Author, entry.Commit.ID,
+			strings.ReplaceAll(entry.Commit.Subject, "|", "\\|"), strings.Join(changes, "; "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
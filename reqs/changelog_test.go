@@ -0,0 +1,139 @@
+package reqs
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-104
+func TestDiffRequirements(t *testing.T) {
+	before := []*Req{
+		{ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One", Body: "Body"},
+		{ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two", Body: "Body"},
+		{ID: "REQ-TEST-SWH-3", IDNumber: 3, Title: "Three", Body: "Body"},
+		{ID: "REQ-TEST-SWH-4", IDNumber: 4, Title: "Four", Body: "Body"},
+		{ID: "REQ-TEST-SWH-5", IDNumber: 5, Title: "DELETED"},
+	}
+	after := []*Req{
+		{ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One", Body: "Body\n"},
+		{ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two", Body: "Body", Attributes: map[string]string{"RATIONALE": "New"}},
+		{ID: "REQ-TEST-SWH-3", IDNumber: 3, Title: "DELETED"},
+		{ID: "REQ-TEST-SWH-5", IDNumber: 5, Title: "DELETED"},
+		{ID: "ASM-TEST-SWH-1", IDNumber: 1, Title: "Assumption", Body: "Body"},
+	}
+
+	assert.Equal(t, []ReqChange{
+		{ID: "ASM-TEST-SWH-1", Kind: ChangeAdded},
+		{ID: "REQ-TEST-SWH-2", Kind: ChangeModified},
+		{ID: "REQ-TEST-SWH-3", Kind: ChangeDeleted},
+		{ID: "REQ-TEST-SWH-4", Kind: ChangeDeleted},
+	}, diffRequirements(before, after))
+	assert.Empty(t, diffRequirements(after, after))
+}
+
+// @llr REQ-TRAQ-SWL-104
+func TestChangelog(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	commit := func(content, message string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "TEST-137-SRD.md"), []byte(content), 0644))
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+	}
+	git("init", "-q")
+	commit("## REQ-TEST-SWH-1 One\nBody one\n", "First draft")
+	git("tag", "v1")
+	commit("## REQ-TEST-SWH-1 One\nBody one\n## REQ-TEST-SWH-2 Two\nBody two\n", "Add two")
+	commit("## REQ-TEST-SWH-1 One\nBody one\n## REQ-TEST-SWH-2 Two\nBody two\n\nSee the | table\n", "Clarify two | typo")
+	commit("## REQ-TEST-SWH-1 DELETED\n## REQ-TEST-SWH-2 Two\nBody two\n\nSee the | table\n", "Delete one")
+
+	repos.RegisterRepository("changelog", repos.RepoPath(repoPath))
+	doc := config.Document{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+
+	entries, err := Changelog("changelog", &doc, "")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	entries, err = Changelog("changelog", &doc, "v1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "Jane Doe", entries[0].Commit.Author)
+	assert.Equal(t, []ReqChange{{ID: "REQ-TEST-SWH-2", Kind: ChangeAdded}}, entries[0].Changes)
+	assert.Equal(t, []ReqChange{{ID: "REQ-TEST-SWH-2", Kind: ChangeModified}}, entries[1].Changes)
+	assert.Equal(t, []ReqChange{{ID: "REQ-TEST-SWH-1", Kind: ChangeDeleted}}, entries[2].Changes)
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteChangelog(&out, entries[1:2]))
+	assert.Equal(t, "| Date | Author | Commit | Description | Requirements |\n| --- | --- | --- | --- | --- |\n"+
+		"| "+entries[1].Commit.Date+" | Jane Doe | "+entries[1].Commit.ID+" | Clarify two \\| typo | Modified REQ-TEST-SWH-2 |\n",
+		out.String())
+
+	_, err = Changelog("changelog", &doc, "v2")
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-137
+func TestAttributeHistory(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(author string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=" + author, "-c", "user.email=dev@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	commit := func(author, safety2, message string) {
+		content := "## REQ-TEST-SWH-1 One\nBody one\n##### Attributes:\n- Safety Impact: High\n- Rationale: First\n"
+		if safety2 != "" {
+			content += "## REQ-TEST-SWH-2 Two\nBody two\n##### Attributes:\n- Safety Impact: " + safety2 + "\n"
+		}
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "TEST-137-SRD.md"), []byte(content), 0644))
+		git(author, "add", "-A")
+		git(author, "commit", "-q", "-m", message)
+	}
+	git("Jane Doe", "init", "-q")
+	commit("Jane Doe", "", "First draft")
+	commit("Jane Doe", "High", "Add two")
+	commit("John Roe", "None", "Downgrade two")
+
+	repos.RegisterRepository("attributes", repos.RepoPath(repoPath))
+	doc := config.Document{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+
+	history, err := AttributeHistory("attributes", &doc, []string{"Safety Impact"})
+	assert.NoError(t, err)
+	if assert.Len(t, history["REQ-TEST-SWH-1"], 1) {
+		assert.Equal(t, "High", history["REQ-TEST-SWH-1"][0].New)
+		assert.Equal(t, "First draft", history["REQ-TEST-SWH-1"][0].Commit.Subject)
+	}
+	if assert.Len(t, history["REQ-TEST-SWH-2"], 2) {
+		change := history["REQ-TEST-SWH-2"][1]
+		assert.Equal(t, "SAFETY IMPACT", change.Attribute)
+		assert.Equal(t, "High", change.Old)
+		assert.Equal(t, "None", change.New)
+		assert.Equal(t, "John Roe", change.Commit.Author)
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteAttributeHistory(&out, map[string][]AttributeChange{"REQ-TEST-SWH-2": history["REQ-TEST-SWH-2"][1:]}))
+	change := history["REQ-TEST-SWH-2"][1]
+	assert.Equal(t, "| Requirement | Attribute | Old | New | Date | Author | Commit | Description |\n| --- | --- | --- | --- | --- | --- | --- | --- |\n"+
+		"| REQ-TEST-SWH-2 | SAFETY IMPACT | High | None | "+change.Commit.Date+" | John Roe | "+change.Commit.ID+" | Downgrade two |\n",
+		out.String())
+
+	// The history is set on the requirements of the graph
+	r := &Req{ID: "REQ-TEST-SWH-2", RepoName: "attributes", Document: &doc}
+	rg := &ReqGraph{Reqs: map[string]*Req{r.ID: r}, ReqtraqConfig: &config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+		"attributes": {Documents: []config.Document{doc}},
+	}}}
+	assert.NoError(t, rg.LoadAttributeHistory([]string{"SAFETY IMPACT"}))
+	assert.Equal(t, history["REQ-TEST-SWH-2"], r.AttributeHistory)
+}
@@ -0,0 +1,126 @@
+package reqs
+
+import (
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// Clone returns a deep copy of the graph, whose requirements, flow tags, code tags, model elements and issues can be
+// modified without affecting the graph. The configuration and the documents are shared, as they are not modified once
+// the graph is built.
+// @llr REQ-TRAQ-SWL-107
+func (rg *ReqGraph) Clone() *ReqGraph {
+	clone := *rg
+
+	codeCopies := make(map[*code.Code]*code.Code)
+	copyTags := func(tags []*code.Code) []*code.Code {
+		if tags == nil {
+			return nil
+		}
+		copies := make([]*code.Code, len(tags))
+		for i, tag := range tags {
+			tagCopy, ok := codeCopies[tag]
+			if !ok {
+				tagCopy = new(code.Code)
+				*tagCopy = *tag
+				tagCopy.Links = append(tag.Links[:0:0], tag.Links...)
+				codeCopies[tag] = tagCopy
+			}
+			copies[i] = tagCopy
+		}
+		return copies
+	}
+	if rg.CodeTags != nil {
+		clone.CodeTags = make(map[repos.RepoName][]*code.Code, len(rg.CodeTags))
+		for repoName, tags := range rg.CodeTags {
+			clone.CodeTags[repoName] = copyTags(tags)
+		}
+	}
+
+	reqCopies := make(map[*Req]*Req, len(rg.Reqs))
+	if rg.Reqs != nil {
+		clone.Reqs = make(map[string]*Req, len(rg.Reqs))
+		for id, r := range rg.Reqs {
+			reqCopy := *r
+			reqCopy.ParentIds = append(r.ParentIds[:0:0], r.ParentIds...)
+			reqCopy.Tags = copyTags(r.Tags)
+			reqCopy.Attributes = copyAttributes(r.Attributes)
+			reqCopy.AttributeKeys = append(r.AttributeKeys[:0:0], r.AttributeKeys...)
+			reqCopy.ComputedAttributes = copyAttributes(r.ComputedAttributes)
+			if r.Override != nil {
+				override := *r.Override
+				reqCopy.Override = &override
+			}
+			reqCopy.ReviewComments = append(r.ReviewComments[:0:0], r.ReviewComments...)
+			reqCopy.AcceptanceCriteria = append(r.AcceptanceCriteria[:0:0], r.AcceptanceCriteria...)
+			reqCopy.AttributeHistory = append(r.AttributeHistory[:0:0], r.AttributeHistory...)
+			reqCopy.CodeReviews = append(r.CodeReviews[:0:0], r.CodeReviews...)
+			reqCopy.RelatedChanges = append(r.RelatedChanges[:0:0], r.RelatedChanges...)
+			reqCopy.Artifacts = append(r.Artifacts[:0:0], r.Artifacts...)
+			clone.Reqs[id] = &reqCopy
+			reqCopies[r] = &reqCopy
+		}
+	}
+
+	// The links point to the requirements of the graph, they are replaced by their copies
+	relink := func(linked []*Req) []*Req {
+		if linked == nil {
+			return nil
+		}
+		copies := make([]*Req, len(linked))
+		for i, r := range linked {
+			if reqCopy, ok := reqCopies[r]; ok {
+				copies[i] = reqCopy
+			} else {
+				copies[i] = r
+			}
+		}
+		return copies
+	}
+	for _, r := range clone.Reqs {
+		r.Parents = relink(r.Parents)
+		r.Children = relink(r.Children)
+	}
+
+	if rg.FlowTags != nil {
+		clone.FlowTags = make(map[string]*Flow, len(rg.FlowTags))
+		for id, f := range rg.FlowTags {
+			flowCopy := *f
+			flowCopy.Attributes = copyAttributes(f.Attributes)
+			flowCopy.Reqs = relink(f.Reqs)
+			clone.FlowTags[id] = &flowCopy
+		}
+	}
+
+	if rg.Issues != nil {
+		clone.Issues = make([]diagnostics.Issue, len(rg.Issues))
+		for i, issue := range rg.Issues {
+			clone.Issues[i] = issue
+			clone.Issues[i].Archs = append(issue.Archs[:0:0], issue.Archs...)
+		}
+	}
+
+	if rg.ModelElements != nil {
+		clone.ModelElements = make([]*ModelElement, len(rg.ModelElements))
+		for i, element := range rg.ModelElements {
+			elementCopy := *element
+			elementCopy.ReqIds = append(element.ReqIds[:0:0], element.ReqIds...)
+			clone.ModelElements[i] = &elementCopy
+		}
+	}
+	return &clone
+}
+
+// copyAttributes returns a copy of the given attributes, or nil if there are none
+// @llr REQ-TRAQ-SWL-107
+func copyAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	copies := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		copies[name] = value
+	}
+	return copies
+}
@@ -0,0 +1,53 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-107
+func TestReqGraph_Clone(t *testing.T) {
+	tag := &code.Code{Tag: "navigate", Links: []code.ReqLink{{Id: "REQ-TEST-SWL-1"}}}
+	sys := &Req{ID: "REQ-TEST-SYS-1", Title: "Navigation", Attributes: map[string]string{"RATIONALE": "Safety"}}
+	swl := &Req{ID: "REQ-TEST-SWL-1", Title: "Route", ParentIds: []string{sys.ID}, Parents: []*Req{sys},
+		Tags: []*code.Code{tag}, Attributes: map[string]string{"PARENTS": sys.ID}}
+	sys.Children = []*Req{swl}
+	flow := &Flow{ID: "ERR-CF-IN-001", Reqs: []*Req{swl}}
+	rg := &ReqGraph{
+		Reqs:     map[string]*Req{sys.ID: sys, swl.ID: swl},
+		CodeTags: map[repos.RepoName][]*code.Code{"repo": {tag}},
+		FlowTags: map[string]*Flow{flow.ID: flow},
+		Issues:   []diagnostics.Issue{{Description: "Issue"}},
+	}
+
+	clone := rg.Clone()
+	assert.Equal(t, rg, clone)
+
+	// The links lead to the copies
+	cloneSwl := clone.Reqs[swl.ID]
+	assert.NotSame(t, swl, cloneSwl)
+	assert.Same(t, clone.Reqs[sys.ID], cloneSwl.Parents[0])
+	assert.Same(t, cloneSwl, clone.Reqs[sys.ID].Children[0])
+	assert.Same(t, cloneSwl, clone.FlowTags[flow.ID].Reqs[0])
+	assert.Same(t, clone.CodeTags["repo"][0], cloneSwl.Tags[0])
+
+	// Modifying the clone leaves the graph untouched
+	cloneSwl.Title = "Changed"
+	cloneSwl.Attributes["PARENTS"] = "Changed"
+	cloneSwl.ParentIds[0] = "Changed"
+	cloneSwl.Tags[0].Links[0].Id = "Changed"
+	clone.Reqs[sys.ID].Children = nil
+	clone.Issues[0].Description = "Changed"
+	delete(clone.Reqs, sys.ID)
+	assert.Equal(t, "Route", swl.Title)
+	assert.Equal(t, sys.ID, swl.Attributes["PARENTS"])
+	assert.Equal(t, sys.ID, swl.ParentIds[0])
+	assert.Equal(t, "REQ-TEST-SWL-1", tag.Links[0].Id)
+	assert.Same(t, swl, sys.Children[0])
+	assert.Equal(t, "Issue", rg.Issues[0].Description)
+	assert.Contains(t, rg.Reqs, sys.ID)
+}
@@ -0,0 +1,122 @@
+package reqs
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The link to the review of a commit recorded in its message or notes, e.g. by Phabricator or Gerrit
+var reReviewLink = regexp.MustCompile(`(?m)^(?:Differential Revision|Reviewed-on):\s*(\S+)`)
+
+// CodeReview is the review status of the last change of a file implementing a requirement
+type CodeReview struct {
+	// The last commit which changed the file
+	Commit   repos.Commit
+	RepoName repos.RepoName
+	Path     string
+	// Whether the message or the notes of the commit record that the change was reviewed and accepted
+	Accepted bool
+	// The link to the review of the commit, if recorded
+	Review string `json:",omitempty"`
+}
+
+// codeReviewOf returns the review status of the last change of the given file, nil if the file was never committed
+// @llr REQ-TRAQ-SWL-139
+func codeReviewOf(codeFile code.CodeFile, settings *config.CodeReviews) (*CodeReview, error) {
+	commit, ok, err := repos.LatestCommit(codeFile.RepoName, codeFile.Path)
+	if err != nil || !ok {
+		return nil, err
+	}
+	text, err := repos.CommitMessageAndNotes(codeFile.RepoName, commit.ID, settings.NotesRef)
+	if err != nil {
+		return nil, err
+	}
+	review := &CodeReview{
+		Commit:   commit,
+		RepoName: codeFile.RepoName,
+		Path:     codeFile.Path,
+		Accepted: settings.Accepted.MatchString(text),
+	}
+	if match := reReviewLink.FindStringSubmatch(text); match != nil {
+		review.Review = match[1]
+	}
+	return review, nil
+}
+
+// LoadCodeReviews sets the review status of the last changes of the files implementing each requirement, which
+// count as reviewed only if all of them were accepted. The status of the files whose last change was not accepted
+// are listed first, ordered by repository and path.
+// @llr REQ-TRAQ-SWL-139
+func (rg *ReqGraph) LoadCodeReviews(settings *config.CodeReviews) error {
+	reviews := make(map[code.CodeFile]*CodeReview)
+	for _, r := range rg.Reqs {
+		r.CodeReviews = nil
+		seen := make(map[code.CodeFile]bool)
+		for _, tag := range r.Tags {
+			if tag.CodeFile.Type != code.CodeTypeImplementation || seen[tag.CodeFile] {
+				continue
+			}
+			seen[tag.CodeFile] = true
+			review, ok := reviews[tag.CodeFile]
+			if !ok {
+				var err error
+				if review, err = codeReviewOf(tag.CodeFile, settings); err != nil {
+					return err
+				}
+				reviews[tag.CodeFile] = review
+			}
+			if review != nil {
+				r.CodeReviews = append(r.CodeReviews, *review)
+			}
+		}
+		sort.Slice(r.CodeReviews, func(i, j int) bool {
+			a, b := r.CodeReviews[i], r.CodeReviews[j]
+			if a.Accepted != b.Accepted {
+				return !a.Accepted
+			}
+			if a.RepoName != b.RepoName {
+				return a.RepoName < b.RepoName
+			}
+			return a.Path < b.Path
+		})
+	}
+	return nil
+}
+
+// CodeReviewed returns whether the last changes of all the files implementing the requirement were accepted, true
+// if the review status of the code was not loaded
+// @llr REQ-TRAQ-SWL-139
+func (r *Req) CodeReviewed() bool {
+	for _, review := range r.CodeReviews {
+		if !review.Accepted {
+			return false
+		}
+	}
+	return true
+}
+
+// ReqsWithUnreviewedCode returns the requirements whose implementation was changed without being accepted, ordered
+// by repository, document and position
+// @llr REQ-TRAQ-SWL-139
+func (rg ReqGraph) ReqsWithUnreviewedCode() []*Req {
+	var unreviewed []*Req
+	for _, r := range rg.Reqs {
+		if !r.IsDeleted() && r.Document != nil && !r.CodeReviewed() {
+			unreviewed = append(unreviewed, r)
+		}
+	}
+	sort.Slice(unreviewed, func(i, j int) bool {
+		if unreviewed[i].RepoName != unreviewed[j].RepoName {
+			return unreviewed[i].RepoName < unreviewed[j].RepoName
+		}
+		if unreviewed[i].Document.Path != unreviewed[j].Document.Path {
+			return unreviewed[i].Document.Path < unreviewed[j].Document.Path
+		}
+		return unreviewed[i].Position < unreviewed[j].Position
+	})
+	return unreviewed
+}
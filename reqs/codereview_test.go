@@ -0,0 +1,72 @@
+package reqs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-139
+func TestReqGraph_LoadCodeReviews(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	commit := func(file, message string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, file), []byte(message), 0644))
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+	}
+	git("init", "-q")
+	commit("a.cc", "Add a\n\nReviewed By: john\nDifferential Revision: https://phabricator.example.com/D12")
+	commit("b.cc", "Add b")
+	commit("c.cc", "Add c")
+	git("notes", "--ref=refs/notes/review", "add", "-m", "Code-Review+2: John Roe <john@example.com>", "HEAD")
+	repos.RegisterRepository("codereviews", repos.RepoPath(repoPath))
+
+	file := func(path string, codeType code.CodeType) *code.Code {
+		return &code.Code{CodeFile: code.CodeFile{RepoName: "codereviews", Path: path, Type: codeType}}
+	}
+	doc := config.Document{Path: "TEST-138-SDD.md"}
+	reviewed := &Req{ID: "REQ-TEST-SWL-1", Document: &doc, Position: 1,
+		Tags: []*code.Code{file("a.cc", code.CodeTypeImplementation), file("c.cc", code.CodeTypeImplementation), file("b.cc", code.CodeTypeTests)}}
+	unreviewed := &Req{ID: "REQ-TEST-SWL-2", Document: &doc, Position: 2,
+		Tags: []*code.Code{file("a.cc", code.CodeTypeImplementation), file("b.cc", code.CodeTypeImplementation)}}
+	rg := &ReqGraph{Reqs: map[string]*Req{reviewed.ID: reviewed, unreviewed.ID: unreviewed}}
+
+	settings := &config.CodeReviews{NotesRef: config.DefaultReviewNotesRef, Accepted: regexp.MustCompile(config.DefaultReviewAcceptedPattern)}
+	assert.NoError(t, rg.LoadCodeReviews(settings))
+
+	// The test code is not considered
+	if assert.Len(t, reviewed.CodeReviews, 2) {
+		assert.Equal(t, "a.cc", reviewed.CodeReviews[0].Path)
+		assert.Equal(t, "https://phabricator.example.com/D12", reviewed.CodeReviews[0].Review)
+		assert.Equal(t, "Jane Doe", reviewed.CodeReviews[0].Commit.Author)
+		// Accepted in the review notes
+		assert.Equal(t, "c.cc", reviewed.CodeReviews[1].Path)
+		assert.True(t, reviewed.CodeReviews[1].Accepted)
+	}
+	assert.True(t, reviewed.CodeReviewed())
+
+	// The changes which were not accepted come first
+	if assert.Len(t, unreviewed.CodeReviews, 2) {
+		assert.Equal(t, "b.cc", unreviewed.CodeReviews[0].Path)
+		assert.False(t, unreviewed.CodeReviews[0].Accepted)
+		assert.Equal(t, "Add b", unreviewed.CodeReviews[0].Commit.Subject)
+	}
+	assert.False(t, unreviewed.CodeReviewed())
+	assert.Equal(t, []*Req{unreviewed}, rg.ReqsWithUnreviewedCode())
+
+	// The unreviewed code counts against the reviewed criterion of the score
+	overall, _ := rg.Scores()
+	assert.Equal(t, ScoreCriterion{Name: ScoreReviewed, Weight: 1, Applicable: 2, Met: 1}, overall.Criteria[2])
+}
@@ -0,0 +1,139 @@
+package reqs
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/expr"
+)
+
+// reqEnv is the environment used to evaluate expressions over a requirement
+type reqEnv struct {
+	req         *Req
+	hasChildren bool
+}
+
+// Variable returns the value of the given variable for the requirement
+// @llr REQ-TRAQ-SWL-91, REQ-TRAQ-SWL-129
+func (e reqEnv) Variable(name string) (expr.Value, bool) {
+	r := e.req
+	switch name {
+	case "id":
+		return expr.String(r.ID), true
+	case "title":
+		return expr.String(r.Title), true
+	case "body":
+		return expr.String(r.Body), true
+	case "repo":
+		return expr.String(string(r.RepoName)), true
+	case "doc":
+		return expr.String(strings.TrimSuffix(filepath.Base(r.Document.Path), filepath.Ext(r.Document.Path))), true
+	case "prefix":
+		return expr.String(string(r.Document.ReqSpec.Prefix)), true
+	case "level":
+		return expr.String(string(r.Document.ReqSpec.Level)), true
+	case "assumption":
+		return expr.Bool(r.Variant == ReqVariantAssumption), true
+	case "deleted":
+		return expr.Bool(r.IsDeleted()), true
+	case "implemented":
+		implemented, _ := r.implementationStatus()
+		return expr.Bool(implemented), true
+	case "tested":
+		_, tested := r.implementationStatus()
+		return expr.Bool(tested), true
+	case "has_parents":
+		return expr.Bool(len(r.ParentIds) > 0), true
+	case "derived":
+		return expr.Bool(r.Derived), true
+	case "has_children":
+		return expr.Bool(e.hasChildren), true
+	}
+	return expr.Value{}, false
+}
+
+// Call invokes the given function for the requirement
+// @llr REQ-TRAQ-SWL-91
+func (e reqEnv) Call(name string, args []expr.Value) (expr.Value, error) {
+	switch name {
+	case "attr", "has_attr":
+		if len(args) != 1 {
+			return expr.Value{}, fmt.Errorf("`%s` takes exactly one argument", name)
+		}
+		value, ok := e.req.Attribute(strings.ToUpper(args[0].String()))
+		if name == "has_attr" {
+			return expr.Bool(ok && value != ""), nil
+		}
+		return expr.String(value), nil
+	}
+	return expr.Value{}, fmt.Errorf("unknown function `%s`", name)
+}
+
+// Attribute returns the value of the attribute with the given uppercase name, looking first at the
+// attributes written in the document and then at the computed ones
+// @llr REQ-TRAQ-SWL-91
+func (r *Req) Attribute(name string) (string, bool) {
+	if value, ok := r.Attributes[name]; ok {
+		return value, true
+	}
+	value, ok := r.ComputedAttributes[name]
+	return value, ok
+}
+
+// implementationStatus returns whether the requirement is linked to implementation and test code
+// @llr REQ-TRAQ-SWL-91
+func (r *Req) implementationStatus() (implemented bool, tested bool) {
+	for _, tag := range r.Tags {
+		if tag.CodeFile.Type.Matches(code.CodeTypeImplementation) {
+			implemented = true
+		}
+		if tag.CodeFile.Type.Matches(code.CodeTypeTests) {
+			tested = true
+		}
+		if implemented && tested {
+			break
+		}
+	}
+	return
+}
+
+// computeAttributes evaluates the computed attributes from the configuration for every requirement.
+// Attributes are evaluated in the configured order, so they can refer to the ones defined before them.
+// @llr REQ-TRAQ-SWL-91
+func (rg *ReqGraph) computeAttributes() []diagnostics.Issue {
+	if rg.ReqtraqConfig == nil || len(rg.ReqtraqConfig.ComputedAttributes) == 0 {
+		return nil
+	}
+
+	hasChildren := make(map[string]bool)
+	for _, req := range rg.Reqs {
+		for _, parentID := range req.ParentIds {
+			hasChildren[parentID] = true
+		}
+	}
+
+	var issues []diagnostics.Issue
+	for _, req := range rg.Reqs {
+		req.ComputedAttributes = make(map[string]string)
+		env := reqEnv{req: req, hasChildren: hasChildren[req.ID]}
+		for _, computedAttr := range rg.ReqtraqConfig.ComputedAttributes {
+			value, err := computedAttr.Expression.Eval(env)
+			if err != nil {
+				issues = append(issues, diagnostics.Issue{
+					Line:        req.Position,
+					Path:        req.Document.Path,
+					RepoName:    req.RepoName,
+					Description: fmt.Sprintf("Unable to compute attribute '%s' of requirement '%s': %s", computedAttr.Name, req.ID, err),
+					Severity:    diagnostics.IssueSeverityMajor,
+					Type:        diagnostics.IssueTypeInvalidAttributeValue,
+				})
+				continue
+			}
+			req.ComputedAttributes[computedAttr.Name] = value.String()
+		}
+	}
+	return issues
+}
@@ -0,0 +1,85 @@
+package reqs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/expr"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-91
+func TestReqGraph_ComputeAttributes(t *testing.T) {
+	doc := config.Document{
+		Path: "path/to/TEST-138-SDD.md",
+		ReqSpec: config.ReqSpec{
+			Prefix: "TEST",
+			Level:  "SWL",
+		},
+	}
+
+	rg := ReqGraph{
+		Reqs: map[string]*Req{
+			"REQ-TEST-SWL-1": {
+				ID:         "REQ-TEST-SWL-1",
+				Document:   &doc,
+				Attributes: map[string]string{"SAFETY IMPACT": "High"},
+				Derived:    true,
+				Tags: []*code.Code{
+					{CodeFile: code.CodeFile{Type: code.CodeTypeImplementation}},
+					{CodeFile: code.CodeFile{Type: code.CodeTypeTests}},
+				},
+			},
+			"REQ-TEST-SWL-2": {
+				ID:         "REQ-TEST-SWL-2",
+				Document:   &doc,
+				ParentIds:  []string{"REQ-TEST-SWL-1"},
+				Attributes: map[string]string{"SAFETY IMPACT": "None"},
+				Tags: []*code.Code{
+					{CodeFile: code.CodeFile{Type: code.CodeTypeImplementation}},
+				},
+			},
+		},
+		ReqtraqConfig: &config.Config{
+			ComputedAttributes: []config.ComputedAttribute{
+				{Name: "TRACED", Expression: expr.MustParse("implemented && tested")},
+				{Name: "CRITICAL", Expression: expr.MustParse(`attr("Safety Impact") == High && attr(TRACED)`)},
+				{Name: "DOC", Expression: expr.MustParse("doc")},
+				{Name: "LEAF", Expression: expr.MustParse("has_parents && !has_children")},
+				{Name: "DERIVED", Expression: expr.MustParse("derived")},
+				{Name: "BROKEN", Expression: expr.MustParse("missing(x)")},
+			},
+		},
+	}
+
+	issues := rg.computeAttributes()
+	assert.Len(t, issues, 2)
+	for _, issue := range issues {
+		assert.Contains(t, issue.Description, "Unable to compute attribute 'BROKEN'")
+	}
+
+	assert.Equal(t, map[string]string{
+		"TRACED":   "true",
+		"CRITICAL": "true",
+		"DOC":      "TEST-138-SDD",
+		"LEAF":     "false",
+		"DERIVED":  "true",
+	}, rg.Reqs["REQ-TEST-SWL-1"].ComputedAttributes)
+	assert.Equal(t, map[string]string{
+		"TRACED":   "false",
+		"CRITICAL": "false",
+		"DOC":      "TEST-138-SDD",
+		"LEAF":     "true",
+		"DERIVED":  "false",
+	}, rg.Reqs["REQ-TEST-SWL-2"].ComputedAttributes)
+
+	// Computed attributes can be filtered like any other
+	filter := ReqFilter{AttributeRegexp: map[string]*regexp.Regexp{"TRACED": regexp.MustCompile("^false$")}}
+	assert.False(t, rg.Reqs["REQ-TEST-SWL-1"].Matches(&filter))
+	assert.True(t, rg.Reqs["REQ-TEST-SWL-2"].Matches(&filter))
+
+	filter = ReqFilter{AnyAttributeRegexp: regexp.MustCompile("^TEST-138-SDD$")}
+	assert.True(t, rg.Reqs["REQ-TEST-SWL-1"].Matches(&filter))
+}
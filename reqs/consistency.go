@@ -0,0 +1,118 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// inconsistency returns a major issue describing a discrepancy between the parts of a merged graph
+// @llr REQ-TRAQ-SWL-124
+func inconsistency(r *Req, description string) diagnostics.Issue {
+	issue := diagnostics.Issue{
+		Description: "Inconsistent graph: " + description,
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInconsistentGraph,
+	}
+	if r != nil {
+		issue.RepoName = r.RepoName
+		issue.Line = r.Position
+		if r.Document != nil {
+			issue.Path = r.Document.Path
+		}
+	}
+	return issue
+}
+
+// containsReq returns whether the list contains the given requirement
+// @llr REQ-TRAQ-SWL-124
+func containsReq(list []*Req, r *Req) bool {
+	for _, other := range list {
+		if other == r {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConsistency verifies that the requirements, code tags and flow tags of a graph merged from exported graphs
+// reference each other consistently, so the reports can rely on it. Requirements without a document are given an
+// empty one. Returns the discrepancies found as issues, ordered by description.
+// @llr REQ-TRAQ-SWL-124, REQ-TRAQ-SWL-136
+func (rg *ReqGraph) checkConsistency() []diagnostics.Issue {
+	var issues []diagnostics.Issue
+
+	for id, r := range rg.Reqs {
+		if r.ID != id {
+			issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s is stored as %s.", r.ID, id)))
+		}
+		if r.Stub {
+			issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s is only a stub, the partition of its document is missing.", r.ID)))
+		}
+		if r.Document == nil {
+			issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s has no document.", r.ID)))
+			r.Document = &config.Document{}
+		}
+		if rg.ReqtraqConfig != nil {
+			if _, ok := rg.ReqtraqConfig.Repos[r.RepoName]; !ok {
+				issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s belongs to repository `%s`, which is not configured.", r.ID, r.RepoName)))
+			}
+		}
+		for _, parent := range r.Parents {
+			if !containsReq(parent.Children, r) {
+				issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s has parent %s, which does not have it as child.", r.ID, parent.ID)))
+			}
+		}
+		for _, child := range r.Children {
+			if !containsReq(child.Parents, r) {
+				issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s has child %s, which does not have it as parent.", r.ID, child.ID)))
+			}
+		}
+		for _, tag := range r.Tags {
+			linked := false
+			for _, link := range tag.Links {
+				linked = linked || link.Id == r.ID
+			}
+			if !linked {
+				issues = append(issues, inconsistency(r, fmt.Sprintf("requirement %s has code %s@%s, which does not reference it.", r.ID, tag.Tag, tag.CodeFile.Path)))
+			}
+		}
+	}
+
+	for _, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			for _, link := range tag.Links {
+				if _, ok := rg.Reqs[link.Id]; !ok {
+					issues = append(issues, diagnostics.Issue{
+						Line:        tag.Line,
+						Path:        tag.CodeFile.Path,
+						RepoName:    tag.CodeFile.RepoName,
+						Description: fmt.Sprintf("Inconsistent graph: code %s@%s references requirement %s, which is not in the graph.", tag.Tag, tag.CodeFile.Path, link.Id),
+						Severity:    diagnostics.IssueSeverityMajor,
+						Type:        diagnostics.IssueTypeInconsistentGraph,
+					})
+				}
+			}
+		}
+	}
+
+	for id, flow := range rg.FlowTags {
+		for _, r := range flow.Reqs {
+			if r == nil || rg.Reqs[r.ID] != r {
+				issue := inconsistency(nil, fmt.Sprintf("flow tag %s references a requirement which is not in the graph.", id))
+				issue.RepoName = flow.RepoName
+				issue.Line = flow.Position
+				if flow.Document != nil {
+					issue.Path = flow.Document.Path
+				}
+				issues = append(issues, issue)
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Description < issues[j].Description })
+	return issues
+}
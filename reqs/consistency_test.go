@@ -0,0 +1,66 @@
+package reqs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-124
+func TestReqGraph_CheckConsistency(t *testing.T) {
+	doc := &config.Document{Path: "TEST-100-ORD.md"}
+	sys := &Req{ID: "REQ-TEST-SYS-1", RepoName: "repo", Document: doc, Position: 3}
+	swh := &Req{ID: "REQ-TEST-SWH-1", RepoName: "other", ParentIds: []string{"REQ-TEST-SYS-1"}}
+	orphan := &Req{ID: "REQ-TEST-SWH-2", RepoName: "repo", Document: doc, Position: 9}
+	tag := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Tag: "Log", Line: 4,
+		Links: []code.ReqLink{{Id: "REQ-TEST-SWH-2"}, {Id: "REQ-TEST-SWL-1"}}}
+	sys.Tags = []*code.Code{tag}
+
+	rg := ReqGraph{
+		Reqs:          map[string]*Req{sys.ID: sys, swh.ID: swh, "REQ-TEST-SWH-3": orphan},
+		CodeTags:      map[repos.RepoName][]*code.Code{"repo": {tag}},
+		FlowTags:      map[string]*Flow{"DF-TEST-1": {ID: "DF-TEST-1", Reqs: []*Req{{ID: "REQ-TEST-SWH-4"}}}},
+		ReqtraqConfig: &config.Config{Repos: map[repos.RepoName]config.RepoConfig{"repo": {}}},
+	}
+	rg.PrepareForUsage()
+	sys.Children = append(sys.Children, orphan)
+
+	var descriptions []string
+	for _, issue := range rg.checkConsistency() {
+		assert.Equal(t, diagnostics.IssueSeverityMajor, issue.Severity)
+		assert.Equal(t, diagnostics.IssueTypeInconsistentGraph, issue.Type)
+		descriptions = append(descriptions, issue.Description)
+	}
+	assert.Equal(t, []string{
+		"Inconsistent graph: code Log@a.go references requirement REQ-TEST-SWH-2, which is not in the graph.",
+		"Inconsistent graph: code Log@a.go references requirement REQ-TEST-SWL-1, which is not in the graph.",
+		"Inconsistent graph: flow tag DF-TEST-1 references a requirement which is not in the graph.",
+		"Inconsistent graph: requirement REQ-TEST-SWH-1 belongs to repository `other`, which is not configured.",
+		"Inconsistent graph: requirement REQ-TEST-SWH-1 has no document.",
+		"Inconsistent graph: requirement REQ-TEST-SWH-2 is stored as REQ-TEST-SWH-3.",
+		"Inconsistent graph: requirement REQ-TEST-SYS-1 has child REQ-TEST-SWH-2, which does not have it as parent.",
+		"Inconsistent graph: requirement REQ-TEST-SYS-1 has code Log@a.go, which does not reference it.",
+	}, descriptions)
+
+	// The requirement without document can be used by the reports
+	assert.NotNil(t, swh.Document)
+}
+
+// @llr REQ-TRAQ-SWL-124
+func TestLoadGraphs_Inconsistent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Reqs": {"REQ-TEST-SYS-1": {"ID": "REQ-TEST-SYS-1", "RepoName": "repo"}},
+		"ReqtraqConfig": {"Repos": {"repo": {}}}}`), 0644))
+
+	rg, err := LoadGraphs([]string{path})
+	assert.NoError(t, err)
+	assert.Len(t, rg.Issues, 1)
+	assert.Equal(t, "Inconsistent graph: requirement REQ-TEST-SYS-1 has no document.", rg.Issues[0].Description)
+	assert.Len(t, rg.OrdsByPosition(), 1)
+}
@@ -0,0 +1,184 @@
+package reqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// The coverage report produced by running the tests, listing the functions with the percentage of their lines
+// covered and the tests covering them. The paths are relative to the root of the repository of the function or of
+// the test, which is the repository declaring the report by default.
+type jsonCoverageReport struct {
+	Functions []struct {
+		RepoName repos.RepoName `json:"repoName"`
+		Path     string         `json:"path"`
+		Function string         `json:"function"`
+		Coverage float64        `json:"coverage"`
+		Tests    []struct {
+			RepoName repos.RepoName `json:"repoName"`
+			Path     string         `json:"path"`
+			Function string         `json:"function"`
+		} `json:"tests"`
+	} `json:"functions"`
+}
+
+// codeFunction identifies a function of the code, implementation or test, by the name of its code tag
+type codeFunction struct {
+	repoName repos.RepoName
+	path     string
+	name     string
+}
+
+// reqLocation identifies a requirement by the line of its document it is defined at, like its issues
+type reqLocation struct {
+	repoName repos.RepoName
+	path     string
+	line     int
+}
+
+// readCoverageReport returns the tests fully covering each function listed in the coverage report. The functions
+// which are not fully covered are left out.
+// @llr REQ-TRAQ-SWL-186
+func readCoverageReport(settings *config.TestCoverage) (map[codeFunction][]codeFunction, error) {
+	path := settings.Report
+	if !filepath.IsAbs(path) {
+		var err error
+		if path, err = repos.PathInRepo(settings.RepoName, settings.Report); err != nil {
+			return nil, errors.Wrapf(err, "Coverage report `%s` declared in config for repo `%s` cannot be found", settings.Report, settings.RepoName)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Coverage report `%s` declared in config for repo `%s` cannot be read", settings.Report, settings.RepoName)
+	}
+	var report jsonCoverageReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, errors.Wrapf(err, "Coverage report `%s` declared in config for repo `%s` is not valid", settings.Report, settings.RepoName)
+	}
+
+	function := func(repoName repos.RepoName, path, name string) codeFunction {
+		if repoName == "" {
+			repoName = settings.RepoName
+		}
+		return codeFunction{repoName, filepath.ToSlash(filepath.Clean(path)), name}
+	}
+	covered := make(map[codeFunction][]codeFunction)
+	for _, f := range report.Functions {
+		if f.Path == "" || f.Function == "" {
+			return nil, fmt.Errorf("Coverage report `%s` lists a function without path or name", settings.Report)
+		}
+		if f.Coverage < 100 {
+			continue
+		}
+		key := function(f.RepoName, f.Path, f.Function)
+		for _, test := range f.Tests {
+			covered[key] = append(covered[key], function(test.RepoName, test.Path, test.Function))
+		}
+	}
+	return covered, nil
+}
+
+// siblings returns the requirements which are not deleted and share a parent with the requirement
+// @llr REQ-TRAQ-SWL-186
+func (r *Req) siblings() map[string]bool {
+	siblings := make(map[string]bool)
+	for _, parent := range r.Parents {
+		for _, child := range parent.Children {
+			if child != r && !child.IsDeleted() {
+				siblings[child.ID] = true
+			}
+		}
+	}
+	return siblings
+}
+
+// LoadTestCoverage replaces the issues of the requirements which are implemented but not tested, whose
+// implementation functions are all fully covered by tests which are all linked to sibling requirements, with notes
+// explaining that the tests of the requirements are inferred from the coverage. This is common in table-driven test
+// suites, where a single test checks the implementation of several requirements.
+// @llr REQ-TRAQ-SWL-186
+func (rg *ReqGraph) LoadTestCoverage(settings *config.TestCoverage) error {
+	covered, err := readCoverageReport(settings)
+	if err != nil {
+		return err
+	}
+	tests := make(map[codeFunction]*code.Code)
+	for _, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			if tag.CodeFile.Type.Matches(code.CodeTypeTests) {
+				tests[codeFunction{tag.CodeFile.RepoName, tag.CodeFile.Path, tag.Tag}] = tag
+			}
+		}
+	}
+	reqsByLocation := make(map[reqLocation]*Req)
+	for _, r := range rg.Reqs {
+		if r.Document != nil {
+			reqsByLocation[reqLocation{r.RepoName, r.Document.Path, r.Position}] = r
+		}
+	}
+
+	for i, issue := range rg.Issues {
+		if issue.Type != diagnostics.IssueTypeReqNotTested {
+			continue
+		}
+		r := reqsByLocation[reqLocation{issue.RepoName, issue.Path, issue.Line}]
+		if r == nil {
+			continue
+		}
+		if coveringTests, ok := r.inferredTests(covered, tests); ok {
+			rg.Issues[i].Description = fmt.Sprintf("Requirement %s is not tested, but its implementation is fully covered by the tests of its siblings: %s.",
+				r.ID, strings.Join(coveringTests, ", "))
+			rg.Issues[i].Severity = diagnostics.IssueSeverityNote
+			rg.Issues[i].Type = diagnostics.IssueTypeTestInferredFromCoverage
+		}
+	}
+	return nil
+}
+
+// inferredTests returns the tests covering the implementation of a requirement, sorted, if all its implementation
+// functions are fully covered and all the tests covering them are linked to sibling requirements
+// @llr REQ-TRAQ-SWL-186
+func (r *Req) inferredTests(covered map[codeFunction][]codeFunction, tests map[codeFunction]*code.Code) ([]string, bool) {
+	siblings := r.siblings()
+	seen := make(map[string]bool)
+	var names []string
+	for _, tag := range r.Tags {
+		if !tag.CodeFile.Type.Matches(code.CodeTypeImplementation) {
+			continue
+		}
+		coveringTests := covered[codeFunction{tag.CodeFile.RepoName, tag.CodeFile.Path, tag.Tag}]
+		if len(coveringTests) == 0 {
+			return nil, false
+		}
+		for _, function := range coveringTests {
+			test, ok := tests[function]
+			if !ok {
+				return nil, false
+			}
+			linked := false
+			for _, link := range test.Links {
+				linked = linked || siblings[link.Id]
+			}
+			if !linked {
+				return nil, false
+			}
+			name := fmt.Sprintf("%s:%s", test.CodeFile.Path, test.Tag)
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, len(names) > 0
+}
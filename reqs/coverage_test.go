@@ -0,0 +1,83 @@
+package reqs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-186
+func TestReqGraph_LoadTestCoverage(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "coverage.json")
+	assert.NoError(t, os.WriteFile(report, []byte(`{"functions": [
+		{"path": "src/parse.c", "function": "parse_row", "coverage": 100,
+			"tests": [{"path": "test/parse_test.c", "function": "test_parse_table"}]},
+		{"path": "./src/parse.c", "function": "parse_cell", "coverage": 100,
+			"tests": [{"path": "test/parse_test.c", "function": "test_parse_table"}, {"path": "test/fuzz.c", "function": "fuzz"}]},
+		{"path": "src/parse.c", "function": "parse_header", "coverage": 80,
+			"tests": [{"path": "test/parse_test.c", "function": "test_parse_table"}]}
+	]}`), 0644))
+
+	sdd := &config.Document{Path: "TEST-138-SDD.md"}
+	function := func(path, name string, codeType code.CodeType, links ...string) *code.Code {
+		tag := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: path, Type: codeType}, Tag: name}
+		for _, link := range links {
+			tag.Links = append(tag.Links, code.ReqLink{Id: link})
+		}
+		return tag
+	}
+	parseTable := function("test/parse_test.c", "test_parse_table", code.CodeTypeTests, "REQ-TEST-SWL-1")
+	fuzz := function("test/fuzz.c", "fuzz", code.CodeTypeTests)
+	parseRow := function("src/parse.c", "parse_row", code.CodeTypeImplementation, "REQ-TEST-SWL-2")
+	parseCell := function("src/parse.c", "parse_cell", code.CodeTypeImplementation, "REQ-TEST-SWL-3")
+	parseHeader := function("src/parse.c", "parse_header", code.CodeTypeImplementation, "REQ-TEST-SWL-4")
+
+	parent := &Req{ID: "REQ-TEST-SWH-1"}
+	newReq := func(id string, position int, tags ...*code.Code) *Req {
+		r := &Req{ID: id, Document: sdd, RepoName: "repo", Position: position, Parents: []*Req{parent}, Tags: tags}
+		parent.Children = append(parent.Children, r)
+		return r
+	}
+	tested := newReq("REQ-TEST-SWL-1", 3, parseTable)
+	row := newReq("REQ-TEST-SWL-2", 9, parseRow)
+	cell := newReq("REQ-TEST-SWL-3", 15, parseCell)
+	header := newReq("REQ-TEST-SWL-4", 21, parseHeader)
+	// Covered by the tests of a requirement which is not a sibling
+	orphan := &Req{ID: "REQ-TEST-SWL-5", Document: sdd, RepoName: "repo", Position: 27, Tags: []*code.Code{parseRow}}
+	rg := &ReqGraph{Reqs: map[string]*Req{}, CodeTags: map[repos.RepoName][]*code.Code{
+		"repo": {parseTable, fuzz, parseRow, parseCell, parseHeader},
+	}}
+	notTested := func(r *Req) diagnostics.Issue {
+		return diagnostics.Issue{
+			Line:        r.Position,
+			Path:        "TEST-138-SDD.md",
+			RepoName:    "repo",
+			Description: "Requirement " + r.ID + " is not tested.",
+			Severity:    diagnostics.IssueSeverityNote,
+			Type:        diagnostics.IssueTypeReqNotTested,
+		}
+	}
+	for _, r := range []*Req{tested, row, cell, header, orphan} {
+		rg.Reqs[r.ID] = r
+		if r != tested {
+			rg.Issues = append(rg.Issues, notTested(r))
+		}
+	}
+
+	assert.NoError(t, rg.LoadTestCoverage(&config.TestCoverage{Report: report, RepoName: "repo"}))
+	inferred := notTested(row)
+	inferred.Description = "Requirement REQ-TEST-SWL-2 is not tested, but its implementation is fully covered by the tests of its siblings: test/parse_test.c:test_parse_table."
+	inferred.Type = diagnostics.IssueTypeTestInferredFromCoverage
+	// The cell is also covered by a test which is not linked to its siblings and the header is not fully covered
+	assert.Equal(t, []diagnostics.Issue{inferred, notTested(cell), notTested(header), notTested(orphan)}, rg.Issues)
+
+	assert.NoError(t, os.WriteFile(report, []byte(`{"functions": [{"path": "src/parse.c", "coverage": 100}]}`), 0644))
+	err := rg.LoadTestCoverage(&config.TestCoverage{Report: report, RepoName: "repo"})
+	assert.EqualError(t, err, "Coverage report `"+report+"` lists a function without path or name")
+}
@@ -0,0 +1,147 @@
+package reqs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+var (
+	// For detecting the line introducing the acceptance criteria in the body of a requirement, e.g.
+	// `Acceptance Criteria:`, `**Acceptance Criteria:**` or `#### Acceptance Criteria:`
+	reAcceptanceCriteriaHeading = regexp.MustCompile(`^(?:#{2,6} +)?(?:\*\*)?Acceptance Criteria:(?:\*\*)?\s*$`)
+	// For detecting an acceptance criterion, e.g. `- AC1: The log level is read at startup.`
+	reAcceptanceCriterion = regexp.MustCompile(`^[-*] +(AC\d+): *(.*)$`)
+)
+
+// AcceptanceCriterion is an item of the acceptance criteria of a requirement, which tests can link to individually
+type AcceptanceCriterion struct {
+	// ID of the criterion within the requirement, e.g. AC1
+	ID   string
+	Text string
+}
+
+// CriterionStatus holds the tests verifying an acceptance criterion of a requirement
+type CriterionStatus struct {
+	Criterion AcceptanceCriterion
+	// Tests linking to the criterion
+	Tests []*code.Code
+}
+
+// Verified returns whether at least one test is linked to the criterion
+// @llr REQ-TRAQ-SWL-126
+func (s CriterionStatus) Verified() bool {
+	return len(s.Tests) > 0
+}
+
+// parseAcceptanceCriteria extracts the acceptance criteria listed in the body of a requirement. The list starts
+// after an `Acceptance Criteria:` line and ends at the first line which is neither an item nor the indented
+// continuation of an item. The IDs of the criteria must be unique within the requirement.
+// @llr REQ-TRAQ-SWL-126
+func parseAcceptanceCriteria(reqID, body string) ([]AcceptanceCriterion, error) {
+	lines := strings.Split(body, "\n")
+	start := -1
+	for i, line := range lines {
+		if reAcceptanceCriteriaHeading.MatchString(strings.TrimSpace(line)) {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil, nil
+	}
+
+	var criteria []AcceptanceCriterion
+	seen := make(map[string]bool)
+	for _, line := range lines[start:] {
+		if strings.TrimSpace(line) == "" {
+			if len(criteria) == 0 {
+				// Blank lines between the heading and the list
+				continue
+			}
+			break
+		}
+		if parts := reAcceptanceCriterion.FindStringSubmatch(line); parts != nil {
+			if seen[parts[1]] {
+				return nil, fmt.Errorf("Requirement %s defines acceptance criterion %s more than once", reqID, parts[1])
+			}
+			seen[parts[1]] = true
+			criteria = append(criteria, AcceptanceCriterion{ID: parts[1], Text: strings.TrimSpace(parts[2])})
+			continue
+		}
+		if len(criteria) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			last := &criteria[len(criteria)-1]
+			last.Text = strings.TrimSpace(last.Text + " " + strings.TrimSpace(line))
+			continue
+		}
+		break
+	}
+
+	if len(criteria) == 0 {
+		return nil, fmt.Errorf("Requirement %s contains an acceptance criteria section but no criteria of the form `- AC1: text`", reqID)
+	}
+	return criteria, nil
+}
+
+// Criterion returns the acceptance criterion of the requirement with the given ID, nil if there is none
+// @llr REQ-TRAQ-SWL-126
+func (r *Req) Criterion(id string) *AcceptanceCriterion {
+	for i := range r.AcceptanceCriteria {
+		if r.AcceptanceCriteria[i].ID == id {
+			return &r.AcceptanceCriteria[i]
+		}
+	}
+	return nil
+}
+
+// CriteriaStatus returns the acceptance criteria of the requirement in the order they are defined, along with the
+// tests verifying each of them
+// @llr REQ-TRAQ-SWL-126
+func (r *Req) CriteriaStatus() []CriterionStatus {
+	status := make([]CriterionStatus, 0, len(r.AcceptanceCriteria))
+	for _, criterion := range r.AcceptanceCriteria {
+		s := CriterionStatus{Criterion: criterion}
+		for _, tag := range r.Tags {
+			if !tag.CodeFile.Type.Matches(code.CodeTypeTests) {
+				continue
+			}
+			for _, link := range tag.Links {
+				if link.Id == r.ID && link.Criterion == criterion.ID {
+					s.Tests = append(s.Tests, tag)
+					break
+				}
+			}
+		}
+		status = append(status, s)
+	}
+	return status
+}
+
+// checkCriterionLinks checks that the acceptance criteria referenced by the code exist in the linked requirements
+// @llr REQ-TRAQ-SWL-126
+func (rg *ReqGraph) checkCriterionLinks(tag *code.Code) []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	for _, link := range tag.Links {
+		if link.Criterion == "" {
+			continue
+		}
+		parent := rg.Reqs[link.Id]
+		if parent == nil || parent.Criterion(link.Criterion) != nil {
+			// Missing requirements are reported separately
+			continue
+		}
+		issues = append(issues, diagnostics.Issue{
+			Line:     tag.Line,
+			Path:     tag.CodeFile.Path,
+			RepoName: tag.CodeFile.RepoName,
+			Description: fmt.Sprintf("Invalid reference in function %s@%s:%d in repo `%s`, %s has no acceptance criterion %s.",
+				tag.Tag, tag.CodeFile.Path, tag.Line, tag.CodeFile.RepoName, link.Id, link.Criterion),
+			Severity: diagnostics.IssueSeverityMajor,
+			Type:     diagnostics.IssueTypeInvalidRequirementInCode,
+		})
+	}
+	return issues
+}
@@ -0,0 +1,71 @@
+package reqs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-126
+func TestParseAcceptanceCriteria(t *testing.T) {
+	criteria, err := parseAcceptanceCriteria("REQ-TEST-SWL-1", "The log level shall be configurable.\n")
+	assert.NoError(t, err)
+	assert.Nil(t, criteria)
+
+	criteria, err = parseAcceptanceCriteria("REQ-TEST-SWL-1", `The log level shall be configurable.
+
+**Acceptance Criteria:**
+
+- AC1: The log level defaults to info.
+- AC2: The log level is read from
+  the configuration file.
+
+Other text.
+- AC3: Not a criterion.
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []AcceptanceCriterion{
+		{ID: "AC1", Text: "The log level defaults to info."},
+		{ID: "AC2", Text: "The log level is read from the configuration file."},
+	}, criteria)
+
+	_, err = parseAcceptanceCriteria("REQ-TEST-SWL-1", "Text.\n\n#### Acceptance Criteria:\n- AC1: One.\n- AC1: Two.\n")
+	assert.EqualError(t, err, "Requirement REQ-TEST-SWL-1 defines acceptance criterion AC1 more than once")
+
+	_, err = parseAcceptanceCriteria("REQ-TEST-SWL-1", "Text.\n\nAcceptance Criteria:\n\nNone yet.\n")
+	assert.EqualError(t, err, "Requirement REQ-TEST-SWL-1 contains an acceptance criteria section but no criteria of the form `- AC1: text`")
+}
+
+// @llr REQ-TRAQ-SWL-126
+func TestBuildGraph_AcceptanceCriteria(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/criteria"))
+	repos.RegisterRepository(repos.RepoName("criteria"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := rg.Reqs["REQ-TEST-SWL-1"]
+	assert.Equal(t, "AC2", req.Criterion("AC2").ID)
+	assert.Nil(t, req.Criterion("AC3"))
+
+	status := req.CriteriaStatus()
+	if assert.Len(t, status, 2) {
+		assert.True(t, status[0].Verified())
+		assert.Equal(t, "default_level.sh", status[0].Tests[0].Tag)
+		assert.False(t, status[1].Verified())
+	}
+
+	if assert.Len(t, rg.Issues, 1) {
+		assert.Equal(t, "Invalid reference in function unknown.sh@tests/unknown.sh:1 in repo `criteria`, REQ-TEST-SWL-1 has no acceptance criterion AC3.",
+			rg.Issues[0].Description)
+	}
+}
@@ -0,0 +1,126 @@
+package reqs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The body of the DELETED placeholders recording the rationale of the deletion
+const deletedReqBody = "This requirement is deleted."
+
+// TombstoneReq replaces the requirement whose heading is at the given line of the content of a document, starting
+// at 1, with a DELETED placeholder keeping its ID, so the sequence of the IDs of the document has no gaps. The
+// rationale of the deletion is recorded as an attribute of the placeholder, unless empty.
+// @llr REQ-TRAQ-SWL-140
+func TombstoneReq(content string, line int, id string, rationale string) (string, error) {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return "", fmt.Errorf("Requirement %s not found at line %d", id, line)
+	}
+	parts := reATXHeading.FindStringSubmatch(lines[line-1])
+	if parts == nil || !strings.Contains(lines[line-1], id) {
+		return "", fmt.Errorf("Requirement %s is not defined in a heading at line %d, delete it from its table instead", id, line)
+	}
+	level := len(parts[1])
+
+	tombstone := []string{fmt.Sprintf("%s %s DELETED", strings.Repeat("#", level), id)}
+	if rationale != "" {
+		tombstone = append(tombstone, "", deletedReqBody, "", strings.Repeat("#", level+1)+" Attributes:", "- Rationale: "+rationale)
+	}
+	end := reqEnd(lines, line-1, level)
+	newLines := append(append(append([]string{}, lines[:line-1]...), tombstone...), lines[end:]...)
+	return strings.Join(newLines, "\n"), nil
+}
+
+// CheckReplacementParents returns an error if the given child of a deleted requirement cannot get the given
+// replacement parents instead: when it would have no parents left, or when a replacement is deleted or not allowed by
+// the hierarchy or the parent links of the document of the child. The parents which are not in the graph, such as
+// the ones of repositories which were not parsed, are not checked.
+// @llr REQ-TRAQ-SWL-140
+func (rg *ReqGraph) CheckReplacementParents(child *Req, deletedID string, replacements []string) error {
+	remaining := len(replacements)
+	for _, parentID := range child.ParentIds {
+		if parentID != deletedID {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		return fmt.Errorf("Requirement %s would have no parents left", child.ID)
+	}
+	for _, parentID := range replacements {
+		parent, ok := rg.Reqs[parentID]
+		if !ok {
+			continue
+		}
+		if parent.IsDeleted() {
+			return fmt.Errorf("Parent %s of requirement %s is deleted", parentID, child.ID)
+		}
+		if child.Variant != ReqVariantRequirement || child.Document == nil {
+			continue
+		}
+		description := rg.validateLinkDirection(child, parent)
+		if description == "" {
+			description = child.validateLink(parent)
+		}
+		if description != "" {
+			return errors.New(description)
+		}
+	}
+	return nil
+}
+
+// ReplaceParent replaces the given parent in the Parents attribute of the requirement whose heading is at the given
+// line of the content of a document, starting at 1, with the given requirements, which are not added again when they
+// are already parents. The other parents are kept as they are, including their links. Fails if the requirement would
+// have no parents left.
+// @llr REQ-TRAQ-SWL-140
+func ReplaceParent(content string, line int, id string, parentID string, replacements []string) (string, error) {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return "", fmt.Errorf("Requirement %s not found at line %d", id, line)
+	}
+	parts := reATXHeading.FindStringSubmatch(lines[line-1])
+	if parts == nil || !strings.Contains(lines[line-1], id) {
+		return "", fmt.Errorf("Requirement %s is not defined in a heading at line %d, update its parents in its table instead", id, line)
+	}
+
+	end := reqEnd(lines, line-1, len(parts[1]))
+	for i := line; i < end; i++ {
+		m := reParentsAttribute.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		present := make(map[string]bool)
+		var kept []string
+		found := false
+		for _, parent := range strings.Split(m[2], ",") {
+			parent = strings.TrimSpace(parent)
+			if parent == "" {
+				continue
+			}
+			plain := strings.TrimSpace(stripMarkdownLinks(parent))
+			if plain == parentID {
+				found = true
+				continue
+			}
+			present[plain] = true
+			kept = append(kept, parent)
+		}
+		if !found {
+			break
+		}
+		for _, replacement := range replacements {
+			if !present[replacement] {
+				present[replacement] = true
+				kept = append(kept, replacement)
+			}
+		}
+		if len(kept) == 0 {
+			return "", fmt.Errorf("Requirement %s would have no parents left", id)
+		}
+		lines[i] = m[1] + " " + strings.Join(kept, ", ")
+		return strings.Join(lines, "\n"), nil
+	}
+	return "", fmt.Errorf("Requirement %s has no parent %s in its attributes", id, parentID)
+}
@@ -0,0 +1,119 @@
+package reqs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-140
+func TestTombstoneReq(t *testing.T) {
+	doc := config.Document{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	content := `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-2
+`
+
+	// The rewritten documents still parse, with the placeholder in place of the requirement
+	updated, err := TombstoneReq(content, 3, "REQ-TEST-SWL-1", "Logs are sent to the system journal")
+	assert.NoError(t, err)
+	requirements, _, err := parseMarkdownContent("repo", &doc, strings.NewReader(updated))
+	if assert.NoError(t, err) && assert.Len(t, requirements, 2) {
+		assert.Equal(t, "REQ-TEST-SWL-1", requirements[0].ID)
+		assert.True(t, requirements[0].IsDeleted())
+		assert.Equal(t, "Logs are sent to the system journal", requirements[0].Attributes["RATIONALE"])
+		assert.Empty(t, requirements[0].ParentIds)
+		assert.Equal(t, "REQ-TEST-SWL-2", requirements[1].ID)
+		assert.Equal(t, []string{"REQ-TEST-SWH-2"}, requirements[1].ParentIds)
+	}
+
+	updated, err = TombstoneReq(content, 10, "REQ-TEST-SWL-2", "")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(updated, "\n### REQ-TEST-SWL-2 DELETED\n"))
+	requirements, _, err = parseMarkdownContent("repo", &doc, strings.NewReader(updated))
+	if assert.NoError(t, err) && assert.Len(t, requirements, 2) {
+		assert.False(t, requirements[0].IsDeleted())
+		assert.True(t, requirements[1].IsDeleted())
+		assert.Empty(t, requirements[1].Attributes)
+	}
+
+	_, err = TombstoneReq(content, 5, "REQ-TEST-SWL-1", "")
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-140
+func TestReplaceParent(t *testing.T) {
+	content := `### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: [REQ-TEST-SWH-1](TEST-137-SRD.md#req-test-swh-1-logging), REQ-TEST-SWH-2
+- Safety Impact: None
+`
+
+	updated, err := ReplaceParent(content, 1, "REQ-TEST-SWL-1", "REQ-TEST-SWH-2", []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-3"})
+	assert.NoError(t, err)
+	assert.Equal(t, `### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: [REQ-TEST-SWH-1](TEST-137-SRD.md#req-test-swh-1-logging), REQ-TEST-SWH-3
+- Safety Impact: None
+`, updated)
+
+	updated, err = ReplaceParent(content, 1, "REQ-TEST-SWL-1", "REQ-TEST-SWH-1", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, updated, "\n- Parents: REQ-TEST-SWH-2\n")
+
+	// The last parent is not removed without replacement
+	_, err = ReplaceParent(updated, 1, "REQ-TEST-SWL-1", "REQ-TEST-SWH-2", nil)
+	assert.EqualError(t, err, "Requirement REQ-TEST-SWL-1 would have no parents left")
+
+	_, err = ReplaceParent(content, 1, "REQ-TEST-SWL-1", "REQ-TEST-SWH-4", nil)
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-140
+func TestReqGraph_CheckReplacementParents(t *testing.T) {
+	sysDoc := config.Document{Path: "TEST-100-ORD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SYS"}}
+	swhDoc := config.Document{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+	swlDoc := config.Document{
+		Path:    "TEST-138-SDD.md",
+		ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"},
+		LinkSpecs: []config.LinkSpec{{
+			Child:  config.ReqSpec{Prefix: "TEST", Level: "SWL", Re: regexp.MustCompile(`REQ-TEST-SWL-(\d+)`)},
+			Parent: config.ReqSpec{Prefix: "TEST", Level: "SWH", Re: regexp.MustCompile(`REQ-TEST-SWH-(\d+)`)},
+		}},
+	}
+	sys := &Req{ID: "REQ-TEST-SYS-1", Title: "System", Document: &sysDoc}
+	swh := &Req{ID: "REQ-TEST-SWH-1", Title: "Logging", Document: &swhDoc}
+	deleted := &Req{ID: "REQ-TEST-SWH-2", Title: "DELETED", Document: &swhDoc}
+	child := &Req{ID: "REQ-TEST-SWL-1", Title: "Log file", Document: &swlDoc, ParentIds: []string{"REQ-TEST-SWH-3"}}
+	rg := &ReqGraph{Reqs: map[string]*Req{sys.ID: sys, swh.ID: swh, deleted.ID: deleted, child.ID: child}}
+
+	assert.NoError(t, rg.CheckReplacementParents(child, "REQ-TEST-SWH-3", []string{swh.ID}))
+	assert.EqualError(t, rg.CheckReplacementParents(child, "REQ-TEST-SWH-3", nil),
+		"Requirement REQ-TEST-SWL-1 would have no parents left")
+	assert.EqualError(t, rg.CheckReplacementParents(child, "REQ-TEST-SWH-3", []string{deleted.ID}),
+		"Parent REQ-TEST-SWH-2 of requirement REQ-TEST-SWL-1 is deleted")
+	// The parents from the wrong level are rejected according to the links of the document of the child
+	assert.EqualError(t, rg.CheckReplacementParents(child, "REQ-TEST-SWH-3", []string{sys.ID}),
+		"Requirement 'REQ-TEST-SWL-1' has invalid parent link ID 'REQ-TEST-SYS-1'.")
+}
@@ -0,0 +1,139 @@
+package reqs
+
+import (
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// DocumentStats summarizes the health of a document: the status of its requirements, the issues reported in it and
+// in its code, and its last change
+type DocumentStats struct {
+	RepoName repos.RepoName
+	Path     string
+	// The requirements which are not deleted, assumptions included
+	Requirements int
+	Assumptions  int
+	Deleted      int
+	Implemented  int
+	Tested       int
+	// The requirements linked to at least one parent, assumptions excluded
+	WithParents int
+	// The number of issues by severity
+	Errors   int
+	Warnings int
+	Lint     int
+	// The completeness score of the document
+	Score float64
+	// The last commit which changed the document, nil when not available, e.g. for graphs loaded from exports
+	LastCommit *repos.Commit
+}
+
+// DocumentStats returns the statistics of each configured document, ordered by repository and path. The issues
+// reported at a requirement or at code linked to requirements count for the document of the first one, the others
+// for the document they were found in. The last commit is only looked up in the repositories available locally.
+// @llr REQ-TRAQ-SWL-142, REQ-TRAQ-SWL-190
+func (rg *ReqGraph) DocumentStats() []DocumentStats {
+	byDocument := make(map[IssueLocation]*DocumentStats)
+	files := make(map[IssueLocation]*DocumentStats)
+	if rg.ReqtraqConfig != nil {
+		for repoName, repo := range rg.ReqtraqConfig.Repos {
+			for i := range repo.Documents {
+				doc := &repo.Documents[i]
+				stats := &DocumentStats{RepoName: repoName, Path: doc.Path}
+				byDocument[IssueLocation{RepoName: repoName, Path: doc.Path}] = stats
+				for _, file := range doc.Files() {
+					files[IssueLocation{RepoName: repoName, Path: file}] = stats
+					if commit, ok, err := repos.LatestCommit(repoName, file); err == nil && ok {
+						if stats.LastCommit == nil || commit.Date > stats.LastCommit.Date {
+							stats.LastCommit = &commit
+						}
+					}
+				}
+			}
+		}
+	}
+	statsOf := func(r *Req) *DocumentStats {
+		if r.Document == nil {
+			return nil
+		}
+		return byDocument[IssueLocation{RepoName: r.RepoName, Path: r.Document.Path}]
+	}
+
+	for _, r := range rg.Reqs {
+		stats := statsOf(r)
+		if stats == nil {
+			continue
+		}
+		if r.IsDeleted() {
+			stats.Deleted++
+			continue
+		}
+		stats.Requirements++
+		if r.IsAssumption() {
+			stats.Assumptions++
+			continue
+		}
+		// Implemented as for the completeness score
+		implemented, tested := r.implementationStatus()
+		if !r.Document.HasImplementation() {
+			implemented = false
+			for _, child := range r.Children {
+				if !child.IsDeleted() {
+					implemented = true
+					break
+				}
+			}
+		}
+		if implemented {
+			stats.Implemented++
+		}
+		if tested {
+			stats.Tested++
+		}
+		if len(r.Parents) > 0 {
+			stats.WithParents++
+		}
+	}
+
+	index := rg.IssueRequirements()
+	for _, issue := range rg.Issues {
+		var stats *DocumentStats
+		if affected := index[LocationOf(issue)]; len(affected) > 0 {
+			stats = statsOf(affected[0])
+		} else {
+			stats = files[IssueLocation{RepoName: issue.RepoName, Path: issue.Path}]
+		}
+		if stats == nil {
+			continue
+		}
+		switch issue.Severity {
+		case diagnostics.IssueSeverityMajor:
+			stats.Errors++
+		case diagnostics.IssueSeverityMinor:
+			stats.Warnings++
+		default:
+			stats.Lint++
+		}
+	}
+
+	_, scores := rg.Scores()
+	for _, score := range scores {
+		if stats, ok := byDocument[IssueLocation{RepoName: score.RepoName, Path: score.Path}]; ok {
+			stats.Score = score.Value
+		}
+	}
+
+	result := make([]DocumentStats, 0, len(byDocument))
+	for _, stats := range byDocument {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RepoName != result[j].RepoName {
+			return result[i].RepoName < result[j].RepoName
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
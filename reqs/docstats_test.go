@@ -0,0 +1,54 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-142
+func TestReqGraph_DocumentStats(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md", Implementation: []config.Implementation{{ArchImplementation: config.ArchImplementation{CodeFiles: []string{"a.go"}}}}}
+	implementation := &code.Code{CodeFile: code.CodeFile{RepoName: "unknown", Path: "a.go", Type: code.CodeTypeImplementation}, Tag: "f", Line: 3}
+	test := &code.Code{CodeFile: code.CodeFile{RepoName: "unknown", Path: "a_test.go", Type: code.CodeTypeTests}, Tag: "TestF", Line: 5}
+
+	rg := &ReqGraph{
+		Reqs: map[string]*Req{
+			"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Title: "Logging", RepoName: "unknown", Document: &srd, Position: 1},
+			"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", Title: "DELETED", RepoName: "unknown", Document: &srd, Position: 8},
+			"ASM-TEST-SWH-1": {ID: "ASM-TEST-SWH-1", Variant: ReqVariantAssumption, Title: "Disk", RepoName: "unknown", Document: &srd, Position: 10},
+			"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Title: "Log file", RepoName: "unknown", Document: &sdd, Position: 1,
+				ParentIds: []string{"REQ-TEST-SWH-1"}, Tags: []*code.Code{implementation, test}},
+			"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Title: "Log rotation", RepoName: "unknown", Document: &sdd, Position: 9,
+				ParentIds: []string{"REQ-TEST-SWH-1"}},
+		},
+		Issues: []diagnostics.Issue{
+			{RepoName: "unknown", Path: "TEST-137-SRD.md", Line: 1, Severity: diagnostics.IssueSeverityMajor},
+			{RepoName: "unknown", Path: "TEST-137-SRD.md", Line: 20, Severity: diagnostics.IssueSeverityNote},
+			{RepoName: "unknown", Path: "TEST-138-SDD.md", Line: 9, Severity: diagnostics.IssueSeverityMinor},
+			{RepoName: "unknown", Path: "b.go", Line: 1, Severity: diagnostics.IssueSeverityMajor},
+		},
+		ReqtraqConfig: &config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+			"unknown": {Documents: []config.Document{sdd, srd}},
+		}},
+	}
+	rg.Reqs["REQ-TEST-SWH-1"].Children = []*Req{rg.Reqs["REQ-TEST-SWL-1"], rg.Reqs["REQ-TEST-SWL-2"]}
+
+	stats := rg.DocumentStats()
+	if !assert.Len(t, stats, 2) {
+		return
+	}
+	// The requirements refer to other instances of the documents, matched by path
+	assert.Equal(t, DocumentStats{RepoName: "unknown", Path: "TEST-137-SRD.md", Requirements: 2, Assumptions: 1, Deleted: 1,
+		Implemented: 1, Errors: 1, Lint: 1, Score: stats[0].Score}, stats[0])
+	assert.Equal(t, DocumentStats{RepoName: "unknown", Path: "TEST-138-SDD.md", Requirements: 2, Implemented: 1, Tested: 1,
+		Warnings: 1, Score: stats[1].Score}, stats[1])
+	assert.Greater(t, stats[0].Score, 0.0)
+	// The last commit is not available for repositories which are not registered
+	assert.Nil(t, stats[1].LastCommit)
+}
@@ -0,0 +1,272 @@
+package reqs
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// The title of the cross-reference sections holding requirements, e.g. produced by the alias
+// `requirement{1}="\xrefitem requirements \"Requirement\" \"Requirements\" \1"`
+const doxygenRequirementTitle = "Requirement"
+
+// The parents of a requirement, listed at the end of its section
+var reDoxygenParents = regexp.MustCompile(`(?i)\s*\bParents:\s*(.*)$`)
+
+// doxygenText is the text of an element of the Doxygen XML output, without the markup of its children and with the
+// white space collapsed, the paragraphs being separated by a space
+type doxygenText string
+
+// UnmarshalXML collects the text found in the element and in its children
+// @llr REQ-TRAQ-SWL-188
+func (t *doxygenText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text strings.Builder
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			if token.Name.Local == "para" {
+				text.WriteString(" ")
+			}
+		case xml.EndElement:
+			depth--
+			if token.Name.Local == "para" {
+				text.WriteString(" ")
+			}
+		case xml.CharData:
+			text.Write(token)
+		}
+	}
+	*t = doxygenText(strings.Join(strings.Fields(text.String()), " "))
+	return nil
+}
+
+// doxygenSection is a cross-reference section of the documentation of a member
+type doxygenSection struct {
+	Title       doxygenText `xml:"xreftitle"`
+	Description doxygenText `xml:"xrefdescription"`
+}
+
+// doxygenMember is a member documented in a compound file of the Doxygen XML output, e.g. a function
+type doxygenMember struct {
+	Kind     string `xml:"kind,attr"`
+	Name     string `xml:"name"`
+	Location struct {
+		File string `xml:"file,attr"`
+		Line int    `xml:"line,attr"`
+	} `xml:"location"`
+	BriefSections    []doxygenSection `xml:"briefdescription>para>xrefsect"`
+	DetailedSections []doxygenSection `xml:"detaileddescription>para>xrefsect"`
+}
+
+// doxygenCompound is a compound file of the Doxygen XML output, documenting a file, a class, a namespace, etc.
+type doxygenCompound struct {
+	Members []doxygenMember `xml:"compounddef>sectiondef>memberdef"`
+}
+
+// doxygenFunction is a function documented with requirements, and the texts of its requirement sections
+type doxygenFunction struct {
+	Path         string
+	Name         string
+	Line         int
+	Requirements []string
+}
+
+// readDoxygenFunctions returns the functions of the compound files of the Doxygen XML output found in the given
+// directory which have requirement sections, ordered by path and line. The paths of the functions are made relative
+// to the root of the repository.
+// @llr REQ-TRAQ-SWL-188
+func readDoxygenFunctions(repoName repos.RepoName, xmlDir string) ([]doxygenFunction, error) {
+	repoPath, err := repos.GetRepoPathByName(repoName)
+	if err != nil {
+		return nil, err
+	}
+	dir := xmlDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(string(repoPath), xmlDir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("Doxygen XML output `%s` in repo `%s` has no XML files", xmlDir, repoName)
+	}
+
+	var functions []doxygenFunction
+	for _, file := range files {
+		if filepath.Base(file) == "index.xml" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var compound doxygenCompound
+		if err := xml.Unmarshal(content, &compound); err != nil {
+			return nil, errors.Wrapf(err, "Doxygen XML file `%s` is not valid", file)
+		}
+		for _, member := range compound.Members {
+			if member.Kind != "function" {
+				continue
+			}
+			function := doxygenFunction{Name: member.Name, Line: member.Location.Line}
+			for _, section := range append(member.BriefSections, member.DetailedSections...) {
+				if strings.EqualFold(string(section.Title), doxygenRequirementTitle) && section.Description != "" {
+					function.Requirements = append(function.Requirements, string(section.Description))
+				}
+			}
+			if len(function.Requirements) == 0 {
+				continue
+			}
+			function.Path = filepath.ToSlash(member.Location.File)
+			if filepath.IsAbs(member.Location.File) {
+				if rel, err := filepath.Rel(string(repoPath), member.Location.File); err == nil {
+					function.Path = filepath.ToSlash(rel)
+				}
+			}
+			functions = append(functions, function)
+		}
+	}
+	sort.SliceStable(functions, func(i, j int) bool {
+		if functions[i].Path != functions[j].Path {
+			return functions[i].Path < functions[j].Path
+		}
+		return functions[i].Line < functions[j].Line
+	})
+	return functions, nil
+}
+
+// doxygenTag returns the code tag of a function among the tags of the code of the document, matched by path and
+// line or else by name, adding a tag for the function if the code of the document does not have it
+// @llr REQ-TRAQ-SWL-188
+func doxygenTag(repoName repos.RepoName, documentConfig *config.Document, function doxygenFunction, codeTags map[code.CodeFile][]*code.Code) *code.Code {
+	codeFile := code.CodeFile{RepoName: repoName, Path: function.Path, Type: code.CodeTypeImplementation}
+	for file, tags := range codeTags {
+		if file.RepoName != repoName || file.Path != function.Path {
+			continue
+		}
+		codeFile = file
+		for _, tag := range tags {
+			if tag.Line == function.Line {
+				return tag
+			}
+		}
+		for _, tag := range tags {
+			if tag.Tag == function.Name {
+				return tag
+			}
+		}
+	}
+	tag := &code.Code{CodeFile: codeFile, Tag: function.Name, Line: function.Line, Document: documentConfig}
+	codeTags[codeFile] = append(codeTags[codeFile], tag)
+	return tag
+}
+
+// parseDoxygen reads the requirement sections of the functions documented in the Doxygen XML output of a
+// document. Each section starts with the ID of a requirement, followed by its text, whose first sentence is the
+// title of the requirement, and optionally ends with its parents, e.g. `Parents: REQ-TEST-SWH-1`. The requirements
+// of the document which are not defined in its markdown file are returned, positioned at the line of their
+// function, while the sections of the requirements defined there or in other documents only link the functions.
+// The functions are linked to the requirements of their sections, adding them to the given code tags when their
+// code is not parsed. The sections which cannot be parsed are reported as issues, as is a document without
+// markdown file.
+// @llr REQ-TRAQ-SWL-188
+func parseDoxygen(repoName repos.RepoName, documentConfig *config.Document, parsedReqs []*Req, codeTags map[code.CodeFile][]*code.Code) ([]*Req, []diagnostics.Issue, error) {
+	functions, err := readDoxygenFunctions(repoName, documentConfig.Doxygen)
+	if err != nil {
+		return nil, nil, err
+	}
+	defined := make(map[string]bool)
+	for _, r := range parsedReqs {
+		defined[r.ID] = true
+	}
+	grammar := newIDGrammar(documentConfig)
+
+	var issues []diagnostics.Issue
+	if documentConfig.Virtual {
+		issues = append(issues, diagnostics.Issue{
+			Path:     documentConfig.Path,
+			RepoName: repoName,
+			Description: fmt.Sprintf("Document `%s` does not exist, its requirements are only read from the Doxygen XML output `%s`",
+				documentConfig.Path, documentConfig.Doxygen),
+			Severity: diagnostics.IssueSeverityNote,
+			Type:     diagnostics.IssueTypeVirtualDocument,
+		})
+	}
+	sectionIssue := func(function doxygenFunction, err error) diagnostics.Issue {
+		return diagnostics.Issue{
+			Line:     function.Line,
+			Path:     function.Path,
+			RepoName: repoName,
+			Description: fmt.Sprintf("Invalid requirement section of function `%s` in Doxygen XML output `%s`: %v",
+				function.Name, documentConfig.Doxygen, err),
+			Severity: diagnostics.IssueSeverityMajor,
+			Type:     diagnostics.IssueTypeInvalidRequirementId,
+		}
+	}
+
+	var doxygenReqs []*Req
+	for _, function := range functions {
+		// The function is only tagged once one of its sections is valid
+		var tag *code.Code
+		for _, text := range function.Requirements {
+			id, variant, idNumber, err := extractIDParts(text, grammar.ids)
+			if err != nil {
+				issues = append(issues, sectionIssue(function, err))
+				continue
+			}
+			if tag == nil {
+				tag = doxygenTag(repoName, documentConfig, function, codeTags)
+			}
+			linked := false
+			for _, link := range tag.Links {
+				linked = linked || link.Id == id
+			}
+			if !linked {
+				tag.Links = append(tag.Links, code.ReqLink{Id: id})
+			}
+
+			body := strings.TrimLeftFunc(strings.TrimPrefix(text, id), isPunctOrSpace)
+			if defined[id] || body == "" || !documentConfig.Schema.Requirements.MatchString(id) {
+				continue
+			}
+			defined[id] = true
+			r := &Req{
+				ID:         id,
+				Variant:    variant,
+				IDNumber:   idNumber,
+				Attributes: map[string]string{},
+				Position:   function.Line,
+				Document:   documentConfig,
+				RepoName:   repoName,
+			}
+			if m := reDoxygenParents.FindStringSubmatchIndex(body); m != nil {
+				r.Attributes["PARENTS"] = body[m[2]:m[3]]
+				body = body[:m[0]]
+				if err := parseParents(r, grammar.parents); err != nil {
+					issues = append(issues, sectionIssue(function, err))
+					continue
+				}
+			}
+			r.Title = strings.TrimSuffix(strings.SplitN(body, ". ", 2)[0], ".")
+			r.Body = body
+			doxygenReqs = append(doxygenReqs, r)
+		}
+	}
+	return doxygenReqs, issues, nil
+}
@@ -0,0 +1,75 @@
+package reqs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-188
+func TestBuildGraph_Doxygen(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/doxygen"))
+	repos.RegisterRepository(repos.RepoName("doxygen"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The document has no markdown file
+	sdd := &reqtraqConfig.Repos["doxygen"].Documents[1]
+	assert.True(t, sdd.Virtual)
+	assert.Empty(t, sdd.Files())
+
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swl1 := rg.Reqs["REQ-TEST-SWL-1"]
+	if assert.NotNil(t, swl1) {
+		assert.Equal(t, "The parser shall parse the rows of the tables", swl1.Title)
+		assert.Equal(t, "The parser shall parse the rows of the tables. Rows are separated by newlines.", swl1.Body)
+		assert.Equal(t, []string{"REQ-TEST-SWH-1"}, swl1.ParentIds)
+		assert.Same(t, sdd, swl1.Document)
+		assert.Equal(t, 12, swl1.Position)
+	}
+	assert.Contains(t, rg.Reqs, "REQ-TEST-SWL-2")
+	assert.NotContains(t, rg.Reqs, "REQ-TEST-SWL-9")
+
+	tags := map[string]*code.Code{}
+	for _, tag := range rg.CodeTags["doxygen"] {
+		tags[tag.Tag] = tag
+	}
+	assert.Len(t, tags, 2)
+	if assert.Contains(t, tags, "parse_cell") {
+		assert.Equal(t, code.CodeFile{RepoName: "doxygen", Path: "src/parse.c", Type: code.CodeTypeImplementation}, tags["parse_cell"].CodeFile)
+		assert.Equal(t, 25, tags["parse_cell"].Line)
+		assert.Equal(t, []code.ReqLink{{Id: "REQ-TEST-SWL-1"}, {Id: "REQ-TEST-SWL-2"}}, tags["parse_cell"].Links)
+	}
+	assert.ElementsMatch(t, []*code.Code{tags["parse_row"], tags["parse_cell"]}, swl1.Tags)
+
+	// The missing document is noted and the section without ID of parse_footer is reported
+	assert.ElementsMatch(t, []diagnostics.Issue{
+		{
+			RepoName:    "doxygen",
+			Path:        "TEST-138-SDD.md",
+			Description: "Document `TEST-138-SDD.md` does not exist, its requirements are only read from the Doxygen XML output `doxygen/xml`",
+			Severity:    diagnostics.IssueSeverityNote,
+			Type:        diagnostics.IssueTypeVirtualDocument,
+		},
+		{
+			RepoName: "doxygen",
+			Path:     "src/parse.c",
+			Line:     40,
+			Description: "Invalid requirement section of function `parse_footer` in Doxygen XML output `doxygen/xml`: " +
+				"malformed requirement: missing ID in first 40 characters: \"The parser shall parse the footer.\"",
+			Severity: diagnostics.IssueSeverityMajor,
+			Type:     diagnostics.IssueTypeInvalidRequirementId,
+		},
+	}, rg.Issues)
+}
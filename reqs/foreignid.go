@@ -0,0 +1,76 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The attribute recording the ID of a requirement in the tool of the customer it was imported from
+const ForeignIDAttribute = "FOREIGN-ID"
+
+// ForeignID maps a requirement to the ID it has in the tool of the customer
+type ForeignID struct {
+	ID        string
+	ForeignID string
+	RepoName  repos.RepoName
+	Document  string
+}
+
+// ForeignIDs returns the foreign IDs of the requirements which are not deleted, ordered by requirement ID
+// @llr REQ-TRAQ-SWL-145
+func (rg *ReqGraph) ForeignIDs() []ForeignID {
+	mappings := make([]ForeignID, 0)
+	for _, r := range rg.Reqs {
+		foreignID := strings.TrimSpace(r.Attributes[ForeignIDAttribute])
+		if r.IsDeleted() || foreignID == "" {
+			continue
+		}
+		mapping := ForeignID{ID: r.ID, ForeignID: foreignID, RepoName: r.RepoName}
+		if r.Document != nil {
+			mapping.Document = r.Document.Path
+		}
+		mappings = append(mappings, mapping)
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].ID < mappings[j].ID })
+	return mappings
+}
+
+// checkForeignIDs reports the requirements with an empty foreign ID or with the foreign ID of another requirement,
+// as a foreign ID must identify a single requirement when exchanging them with the customer
+// @llr REQ-TRAQ-SWL-145
+func (rg *ReqGraph) checkForeignIDs() []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	owners := make(map[string]string)
+	for _, mapping := range rg.ForeignIDs() {
+		if owner, ok := owners[mapping.ForeignID]; ok {
+			r := rg.Reqs[mapping.ID]
+			issues = append(issues, diagnostics.Issue{
+				Line:        r.Position,
+				Path:        mapping.Document,
+				RepoName:    r.RepoName,
+				Description: fmt.Sprintf("Requirement '%s' has the foreign ID '%s' of requirement '%s'.", r.ID, mapping.ForeignID, owner),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeDuplicateForeignID,
+			})
+			continue
+		}
+		owners[mapping.ForeignID] = mapping.ID
+	}
+	for _, r := range rg.Reqs {
+		if value, ok := r.Attributes[ForeignIDAttribute]; ok && !r.IsDeleted() && strings.TrimSpace(value) == "" {
+			issues = append(issues, diagnostics.Issue{
+				Line:        r.Position,
+				Path:        r.Document.Path,
+				RepoName:    r.RepoName,
+				Description: fmt.Sprintf("Requirement '%s' has an empty foreign ID.", r.ID),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidAttributeValue,
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,53 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-145
+func TestReqGraph_ForeignIDs(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	rg := &ReqGraph{Reqs: map[string]*Req{}}
+	for _, r := range []*Req{
+		{ID: "REQ-TEST-SWL-2", Title: "Rotation", Position: 9, Attributes: map[string]string{ForeignIDAttribute: " CUST-12 "}},
+		{ID: "REQ-TEST-SWL-1", Title: "Format", Position: 3, Attributes: map[string]string{ForeignIDAttribute: "CUST-7"}},
+		{ID: "REQ-TEST-SWL-3", Title: "Level", Position: 15, Attributes: map[string]string{ForeignIDAttribute: "CUST-7"}},
+		{ID: "REQ-TEST-SWL-4", Title: "DELETED", Position: 20, Attributes: map[string]string{ForeignIDAttribute: "CUST-12"}},
+		{ID: "REQ-TEST-SWL-5", Title: "Output", Position: 25, Attributes: map[string]string{ForeignIDAttribute: ""}},
+		{ID: "REQ-TEST-SWL-6", Title: "Color", Position: 30, Attributes: map[string]string{}},
+	} {
+		r.Document = doc
+		r.RepoName = "repo"
+		rg.Reqs[r.ID] = r
+	}
+
+	assert.Equal(t, []ForeignID{
+		{ID: "REQ-TEST-SWL-1", ForeignID: "CUST-7", RepoName: "repo", Document: "TEST-138-SDD.md"},
+		{ID: "REQ-TEST-SWL-2", ForeignID: "CUST-12", RepoName: "repo", Document: "TEST-138-SDD.md"},
+		{ID: "REQ-TEST-SWL-3", ForeignID: "CUST-7", RepoName: "repo", Document: "TEST-138-SDD.md"},
+	}, rg.ForeignIDs())
+
+	// The deleted requirements do not own their foreign ID anymore
+	assert.ElementsMatch(t, []diagnostics.Issue{{
+		Line:        15,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Requirement 'REQ-TEST-SWL-3' has the foreign ID 'CUST-7' of requirement 'REQ-TEST-SWL-1'.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeDuplicateForeignID,
+	}, {
+		Line:        25,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Requirement 'REQ-TEST-SWL-5' has an empty foreign ID.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInvalidAttributeValue,
+	}}, rg.checkForeignIDs())
+
+	// The foreign ID is not an unknown attribute
+	assert.Empty(t, rg.Reqs["REQ-TEST-SWL-1"].checkAttributes())
+}
@@ -0,0 +1,94 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The languages test skeletons can be generated for
+var TestSkeletonLanguages = []string{"cpp", "go"}
+
+// UntestedReqs returns the requirements of the given repository which are expected to be tested but aren't linked
+// to any test, ordered by document and position
+// @llr REQ-TRAQ-SWL-118
+func (rg ReqGraph) UntestedReqs(repoName string) []*Req {
+	var r []*Req
+	for _, req := range rg.Reqs {
+		if string(req.RepoName) != repoName || req.Document == nil || !req.Document.HasImplementation() ||
+			req.IsDeleted() || req.IsAssumption() {
+			continue
+		}
+		if _, tested := req.implementationStatus(); !tested {
+			r = append(r, req)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Document.Path != r[j].Document.Path {
+			return r[i].Document.Path < r[j].Document.Path
+		}
+		return r[i].Position < r[j].Position
+	})
+	return r
+}
+
+// camelCase joins the words of the text, made of letters and digits, capitalizing each of them
+// @llr REQ-TRAQ-SWL-118
+func camelCase(text string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(text, func(c rune) bool { return !unicode.IsLetter(c) && !unicode.IsDigit(c) })
+	for _, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// testNames returns the name of the test of each requirement, derived from its title. Tests of requirements
+// without a usable title or with the same title as another one are named after the requirement ID instead.
+// @llr REQ-TRAQ-SWL-118
+func testNames(requirements []*Req) []string {
+	names := make([]string, len(requirements))
+	count := make(map[string]int)
+	for i, r := range requirements {
+		names[i] = camelCase(r.Title)
+		if names[i] == "" || unicode.IsDigit([]rune(names[i])[0]) {
+			names[i] = camelCase(r.ID)
+		}
+		count[names[i]]++
+	}
+	for i, r := range requirements {
+		if count[names[i]] > 1 {
+			names[i] = camelCase(r.ID) + names[i]
+		}
+	}
+	return names
+}
+
+// TestSkeletons returns the source of a test file with an empty test for each of the requirements, annotated with
+// the requirement it tests. Go tests are generated in the given package and C++ tests use GoogleTest.
+// @llr REQ-TRAQ-SWL-118
+func TestSkeletons(requirements []*Req, lang string, goPackage string) (string, error) {
+	var b strings.Builder
+	names := testNames(requirements)
+	switch lang {
+	case "go":
+		fmt.Fprintf(&b, "package %s\n\nimport \"testing\"\n", goPackage)
+		for i, r := range requirements {
+			fmt.Fprintf(&b, "\n// Test%s checks %s %s\n// @llr %s\nfunc Test%s(t *testing.T) {\n", names[i], r.ID, r.Title, r.ID, names[i])
+			fmt.Fprintf(&b, "\t// %s: %s\n\tt.Skip(\"%s\")\n}\n", NewReqPlaceholder, strings.Join(strings.Fields(r.Body), " "), NewReqPlaceholder)
+		}
+	case "cpp":
+		b.WriteString("#include <gtest/gtest.h>\n")
+		for i, r := range requirements {
+			suite := camelCase(fmt.Sprintf("%s %s", r.Document.ReqSpec.Prefix, r.Document.ReqSpec.Level))
+			fmt.Fprintf(&b, "\n// Checks %s %s\n// @llr %s\nTEST(%s, %s) {\n", r.ID, r.Title, r.ID, suite, names[i])
+			fmt.Fprintf(&b, "  // %s: %s\n  GTEST_SKIP() << \"%s\";\n}\n", NewReqPlaceholder, strings.Join(strings.Fields(r.Body), " "), NewReqPlaceholder)
+		}
+	default:
+		return "", fmt.Errorf("Unsupported test language `%s`, expected one of: %s", lang, strings.Join(TestSkeletonLanguages, ", "))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,94 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-118
+func TestReqGraph_UntestedReqs(t *testing.T) {
+	withCode := &config.Document{Path: "TEST-138-SDD.md", Implementation: []config.Implementation{
+		{ArchImplementation: config.ArchImplementation{CodeFiles: []string{"a.go"}}}}}
+	withoutCode := &config.Document{Path: "TEST-137-SRD.md"}
+	test := &code.Code{CodeFile: code.CodeFile{Type: code.CodeTypeTests}}
+	rg := ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWL-1":  {ID: "REQ-TEST-SWL-1", RepoName: "repo", Document: withCode, Position: 9},
+		"REQ-TEST-SWL-2":  {ID: "REQ-TEST-SWL-2", RepoName: "repo", Document: withCode, Position: 3},
+		"REQ-TEST-SWL-3":  {ID: "REQ-TEST-SWL-3", RepoName: "repo", Document: withCode, Tags: []*code.Code{test}},
+		"REQ-TEST-SWL-4":  {ID: "REQ-TEST-SWL-4", RepoName: "repo", Document: withCode, Title: "DELETED"},
+		"ASM-TEST-SWL-1":  {ID: "ASM-TEST-SWL-1", RepoName: "repo", Document: withCode, Variant: ReqVariantAssumption},
+		"REQ-TEST-SWH-1":  {ID: "REQ-TEST-SWH-1", RepoName: "repo", Document: withoutCode},
+		"REQ-OTHER-SWL-1": {ID: "REQ-OTHER-SWL-1", RepoName: "other", Document: withCode},
+	}}
+
+	var ids []string
+	for _, r := range rg.UntestedReqs("repo") {
+		ids = append(ids, r.ID)
+	}
+	assert.Equal(t, []string{"REQ-TEST-SWL-2", "REQ-TEST-SWL-1"}, ids)
+}
+
+// @llr REQ-TRAQ-SWL-118
+func TestTestSkeletons(t *testing.T) {
+	doc := &config.Document{ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	requirements := []*Req{
+		{ID: "REQ-TEST-SWL-1", Title: "Log file (rotating)", Body: "The logs SHALL be written\nto a file.", Document: doc},
+		{ID: "REQ-TEST-SWL-2", Title: "Log level", Body: "The level SHALL be configurable.", Document: doc},
+		{ID: "REQ-TEST-SWL-3", Title: "Log level", Body: "The level SHALL default to info.", Document: doc},
+		{ID: "REQ-TEST-SWL-4", Title: "1 Hz", Body: "The logs SHALL be flushed every second.", Document: doc},
+	}
+
+	tests, err := TestSkeletons(requirements[:2], "go", "logging")
+	assert.NoError(t, err)
+	// Built line by line, so the expected functions are not taken for tagged code
+	assert.Equal(t, ""+
+		"package logging\n"+
+		"\n"+
+		"import \"testing\"\n"+
+		"\n"+
+		"// TestLogFileRotating checks REQ-TEST-SWL-1 Log file (rotating)\n"+
+		"// @llr REQ-TEST-SWL-1\n"+
+		"func TestLogFileRotating(t *testing.T) {\n"+
+		"\t// TODO: The logs SHALL be written to a file.\n"+
+		"\tt.Skip(\"TODO\")\n"+
+		"}\n"+
+		"\n"+
+		"// TestLogLevel checks REQ-TEST-SWL-2 Log level\n"+
+		"// @llr REQ-TEST-SWL-2\n"+
+		"func TestLogLevel(t *testing.T) {\n"+
+		"\t// TODO: The level SHALL be configurable.\n"+
+		"\tt.Skip(\"TODO\")\n"+
+		"}\n", tests)
+
+	tests, err = TestSkeletons(requirements[1:], "cpp", "")
+	assert.NoError(t, err)
+	assert.Equal(t, `#include <gtest/gtest.h>
+
+// Checks REQ-TEST-SWL-2 Log level
+// @llr REQ-TEST-SWL-2
+TEST(TestSwl, ReqTestSwl2LogLevel) {
+  // TODO: The level SHALL be configurable.
+  GTEST_SKIP() << "TODO";
+}
+
+// Checks REQ-TEST-SWL-3 Log level
+// @llr REQ-TEST-SWL-3
+TEST(TestSwl, ReqTestSwl3LogLevel) {
+  // TODO: The level SHALL default to info.
+  GTEST_SKIP() << "TODO";
+}
+
+// Checks REQ-TEST-SWL-4 1 Hz
+// @llr REQ-TEST-SWL-4
+TEST(TestSwl, ReqTestSwl4) {
+  // TODO: The logs SHALL be flushed every second.
+  GTEST_SKIP() << "TODO";
+}
+`, tests)
+
+	_, err = TestSkeletons(requirements, "rust", "")
+	assert.EqualError(t, err, "Unsupported test language `rust`, expected one of: cpp, go")
+}
@@ -0,0 +1,96 @@
+package reqs
+
+import (
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// Link is a link from a child requirement to one of its parents
+type Link struct {
+	Parent string
+	Child  string
+}
+
+// GraphDiff holds the differences between two versions of the requirements graph
+type GraphDiff struct {
+	// The added, modified and deleted requirements, ordered by requirement ID number
+	Changes []ReqChange
+	// The links between requirements which are not deleted, ordered by child and parent
+	AddedLinks   []Link
+	RemovedLinks []Link
+}
+
+// ReqsAtRevision parses the requirements of the documents of a repository at the given git revision. Documents
+// which do not exist at that revision have no requirements.
+// @llr REQ-TRAQ-SWL-112
+func ReqsAtRevision(cfg *config.Config, repoName repos.RepoName, revision string) (*ReqGraph, error) {
+	rg := &ReqGraph{Reqs: make(map[string]*Req), ReqtraqConfig: cfg}
+	repoConfig := cfg.Repos[repoName]
+	for idx := range repoConfig.Documents {
+		reqs, err := parseRevision(repoName, &repoConfig.Documents[idx], revision)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reqs {
+			rg.Reqs[r.ID] = r
+		}
+	}
+	return rg, nil
+}
+
+// Links returns the links from the requirements which are not deleted to their parents
+// @llr REQ-TRAQ-SWL-112
+func (rg *ReqGraph) Links() map[Link]bool {
+	links := make(map[Link]bool)
+	for _, r := range rg.Reqs {
+		if r.IsDeleted() {
+			continue
+		}
+		for _, parentID := range r.ParentIds {
+			links[Link{Parent: parentID, Child: r.ID}] = true
+		}
+	}
+	return links
+}
+
+// sortLinks orders the links by child and parent
+// @llr REQ-TRAQ-SWL-112
+func sortLinks(links []Link) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Child != links[j].Child {
+			return links[i].Child < links[j].Child
+		}
+		return links[i].Parent < links[j].Parent
+	})
+}
+
+// DiffGraphs returns the requirements and the links between requirements which were added, modified or deleted
+// between two versions of the requirements graph. Requirements marked as deleted count as deleted.
+// @llr REQ-TRAQ-SWL-112
+func DiffGraphs(before, after *ReqGraph) GraphDiff {
+	var beforeReqs, afterReqs []*Req
+	for _, r := range before.Reqs {
+		beforeReqs = append(beforeReqs, r)
+	}
+	for _, r := range after.Reqs {
+		afterReqs = append(afterReqs, r)
+	}
+	diff := GraphDiff{Changes: diffRequirements(beforeReqs, afterReqs)}
+
+	beforeLinks, afterLinks := before.Links(), after.Links()
+	for link := range afterLinks {
+		if !beforeLinks[link] {
+			diff.AddedLinks = append(diff.AddedLinks, link)
+		}
+	}
+	for link := range beforeLinks {
+		if !afterLinks[link] {
+			diff.RemovedLinks = append(diff.RemovedLinks, link)
+		}
+	}
+	sortLinks(diff.AddedLinks)
+	sortLinks(diff.RemovedLinks)
+	return diff
+}
@@ -0,0 +1,74 @@
+package reqs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-112
+func TestDiffGraphs(t *testing.T) {
+	before := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One"},
+		"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two"},
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Low one", ParentIds: []string{"REQ-TEST-SWH-1"}},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", IDNumber: 2, Title: "Low two", ParentIds: []string{"REQ-TEST-SWH-2"}},
+	}}
+	after := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One"},
+		"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two renamed"},
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Low one",
+			ParentIds: []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2"}},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", IDNumber: 2, Title: "DELETED", ParentIds: []string{"REQ-TEST-SWH-2"}},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", IDNumber: 3, Title: "Low three", ParentIds: []string{"REQ-TEST-SWH-1"}},
+	}}
+
+	diff := DiffGraphs(before, after)
+	assert.Equal(t, []ReqChange{
+		{ID: "REQ-TEST-SWH-2", Kind: ChangeModified},
+		{ID: "REQ-TEST-SWL-2", Kind: ChangeDeleted},
+		{ID: "REQ-TEST-SWL-3", Kind: ChangeAdded},
+	}, diff.Changes)
+	assert.Equal(t, []Link{
+		{Parent: "REQ-TEST-SWH-2", Child: "REQ-TEST-SWL-1"},
+		{Parent: "REQ-TEST-SWH-1", Child: "REQ-TEST-SWL-3"},
+	}, diff.AddedLinks)
+	// The links of deleted requirements count as removed
+	assert.Equal(t, []Link{{Parent: "REQ-TEST-SWH-2", Child: "REQ-TEST-SWL-2"}}, diff.RemovedLinks)
+
+	assert.Equal(t, GraphDiff{Changes: []ReqChange{}}, DiffGraphs(after, after))
+}
+
+// @llr REQ-TRAQ-SWL-112
+func TestReqsAtRevision(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "TEST-137-SRD.md"), []byte("## REQ-TEST-SWH-1 One\nBody one\n"), 0644))
+	git("add", "-A")
+	git("commit", "-q", "-m", "First draft")
+	git("tag", "v1")
+
+	repos.RegisterRepository("graphdiff", repos.RepoPath(repoPath))
+	cfg := config.Config{Repos: map[repos.RepoName]config.RepoConfig{"graphdiff": {Documents: []config.Document{
+		{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}},
+		{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}},
+	}}}}
+
+	rg, err := ReqsAtRevision(&cfg, "graphdiff", "v1")
+	assert.NoError(t, err)
+	assert.Len(t, rg.Reqs, 1)
+	assert.Equal(t, "One", rg.Reqs["REQ-TEST-SWH-1"].Title)
+
+	_, err = ReqsAtRevision(&cfg, "graphdiff", "v2")
+	assert.Error(t, err)
+}
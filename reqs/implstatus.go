@@ -0,0 +1,91 @@
+package reqs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// The attribute recording how far the implementation of a requirement is, which tempers the issues of the
+// requirements which are not implemented or not tested yet
+const ImplementationStatusAttribute = "IMPLEMENTATION STATUS"
+
+// The values of the implementation status
+const (
+	ImplementationNotStarted = "Not started"
+	ImplementationPartial    = "Partial"
+	ImplementationComplete   = "Complete"
+)
+
+// ImplementationStatus returns the implementation status of the requirement in its canonical spelling, or an empty
+// string if it has none or an invalid one
+// @llr REQ-TRAQ-SWL-180
+func (r *Req) ImplementationStatus() string {
+	value := strings.Join(strings.Fields(r.Attributes[ImplementationStatusAttribute]), " ")
+	for _, status := range []string{ImplementationNotStarted, ImplementationPartial, ImplementationComplete} {
+		if strings.EqualFold(value, status) {
+			return status
+		}
+	}
+	return ""
+}
+
+// checkImplementationStatus reports an implementation status which is not one of the known values
+// @llr REQ-TRAQ-SWL-180
+func (r *Req) checkImplementationStatus() []diagnostics.Issue {
+	value, ok := r.Attributes[ImplementationStatusAttribute]
+	if !ok || r.ImplementationStatus() != "" {
+		return nil
+	}
+	return []diagnostics.Issue{{
+		Line:     r.Position,
+		Path:     r.Document.Path,
+		RepoName: r.RepoName,
+		Description: fmt.Sprintf("Requirement '%s' has invalid value '%s' in attribute '%s', expected one of '%s', '%s' or '%s'.",
+			r.ID, value, ImplementationStatusAttribute, ImplementationNotStarted, ImplementationPartial, ImplementationComplete),
+		Severity: diagnostics.IssueSeverityMajor,
+		Type:     diagnostics.IssueTypeInvalidAttributeValue,
+	}}
+}
+
+// implementationProgressIssues returns the informational issues replacing the issues of a requirement which is not
+// implemented or not tested, when its implementation has not started or is partial. A requirement whose
+// implementation has not started has no issue until code links to it. The second result is false when the
+// implementation status doesn't temper the issues of the requirement.
+// @llr REQ-TRAQ-SWL-180
+func (r *Req) implementationProgressIssues(implemented, tested bool) ([]diagnostics.Issue, bool) {
+	var description string
+	switch r.ImplementationStatus() {
+	case ImplementationNotStarted:
+		if !implemented && !tested {
+			return nil, true
+		}
+		description = fmt.Sprintf("Requirement %s is marked as '%s' but code links to it, update its implementation status.",
+			r.ID, ImplementationNotStarted)
+	case ImplementationPartial:
+		var missing []string
+		if !implemented {
+			missing = append(missing, "implementation")
+		}
+		if !tested {
+			missing = append(missing, "tests")
+		}
+		if len(missing) > 0 {
+			description = fmt.Sprintf("Requirement %s is partially implemented, missing: %s.", r.ID, strings.Join(missing, ", "))
+		} else {
+			description = fmt.Sprintf("Requirement %s is partially implemented and tested, mark it as '%s' once it is done.",
+				r.ID, ImplementationComplete)
+		}
+	default:
+		return nil, false
+	}
+	return []diagnostics.Issue{{
+		Line:        r.Position,
+		Path:        r.Document.Path,
+		RepoName:    r.RepoName,
+		Description: description,
+		Severity:    diagnostics.IssueSeverityNote,
+		Type:        diagnostics.IssueTypeImplementationInProgress,
+	}}, true
+}
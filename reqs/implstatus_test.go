@@ -0,0 +1,48 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-180
+func TestReq_ImplementationProgressIssues(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	withStatus := func(status string) *Req {
+		return &Req{ID: "REQ-TEST-SWL-1", Position: 5, Document: doc, RepoName: "repo",
+			Attributes: map[string]string{ImplementationStatusAttribute: status}}
+	}
+	note := func(description string) []diagnostics.Issue {
+		return []diagnostics.Issue{{Line: 5, Path: "TEST-138-SDD.md", RepoName: "repo", Description: description,
+			Severity: diagnostics.IssueSeverityNote, Type: diagnostics.IssueTypeImplementationInProgress}}
+	}
+
+	issues, tempered := withStatus("not  Started").implementationProgressIssues(false, false)
+	assert.True(t, tempered)
+	assert.Empty(t, issues)
+	issues, tempered = withStatus("Not started").implementationProgressIssues(true, false)
+	assert.True(t, tempered)
+	assert.Equal(t, note("Requirement REQ-TEST-SWL-1 is marked as 'Not started' but code links to it, update its implementation status."), issues)
+
+	issues, tempered = withStatus("Partial").implementationProgressIssues(false, false)
+	assert.True(t, tempered)
+	assert.Equal(t, note("Requirement REQ-TEST-SWL-1 is partially implemented, missing: implementation, tests."), issues)
+	issues, _ = withStatus("partial").implementationProgressIssues(true, false)
+	assert.Equal(t, note("Requirement REQ-TEST-SWL-1 is partially implemented, missing: tests."), issues)
+	issues, _ = withStatus("Partial").implementationProgressIssues(true, true)
+	assert.Equal(t, note("Requirement REQ-TEST-SWL-1 is partially implemented and tested, mark it as 'Complete' once it is done."), issues)
+
+	// Complete requirements and requirements without status are checked as usual
+	_, tempered = withStatus("Complete").implementationProgressIssues(false, false)
+	assert.False(t, tempered)
+	_, tempered = (&Req{ID: "REQ-TEST-SWL-2", Document: doc}).implementationProgressIssues(false, false)
+	assert.False(t, tempered)
+
+	assert.Empty(t, withStatus("Complete").checkImplementationStatus())
+	assert.Equal(t, []diagnostics.Issue{{Line: 5, Path: "TEST-138-SDD.md", RepoName: "repo",
+		Description: "Requirement 'REQ-TEST-SWL-1' has invalid value 'Halfway' in attribute 'IMPLEMENTATION STATUS', expected one of 'Not started', 'Partial' or 'Complete'.",
+		Severity:    diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeInvalidAttributeValue}}, withStatus("Halfway").checkImplementationStatus())
+}
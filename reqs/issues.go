@@ -0,0 +1,148 @@
+package reqs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// DocumentIssues holds the issues found in a single file of a repository
+type DocumentIssues struct {
+	Path   string
+	Issues []diagnostics.Issue
+}
+
+// RepoIssues holds the issues found in a repository, grouped by file
+type RepoIssues struct {
+	RepoName  repos.RepoName
+	Count     int
+	Documents []DocumentIssues
+}
+
+// IssuesOfRepo returns the issues found in the given repository, in their original order
+// @llr REQ-TRAQ-SWL-116
+func IssuesOfRepo(issues []diagnostics.Issue, repoName repos.RepoName) []diagnostics.Issue {
+	var r []diagnostics.Issue
+	for _, issue := range issues {
+		if issue.RepoName == repoName {
+			r = append(r, issue)
+		}
+	}
+	return r
+}
+
+// GroupIssues groups the issues by repository and by file, both ordered by name. The issues of a file are
+// ordered by line, issues found at the same line keep their original order.
+// @llr REQ-TRAQ-SWL-116
+func GroupIssues(issues []diagnostics.Issue) []RepoIssues {
+	sorted := append([]diagnostics.Issue{}, issues...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].RepoName != sorted[j].RepoName {
+			return sorted[i].RepoName < sorted[j].RepoName
+		}
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+
+	var groups []RepoIssues
+	for _, issue := range sorted {
+		if len(groups) == 0 || groups[len(groups)-1].RepoName != issue.RepoName {
+			groups = append(groups, RepoIssues{RepoName: issue.RepoName})
+		}
+		group := &groups[len(groups)-1]
+		group.Count++
+		if len(group.Documents) == 0 || group.Documents[len(group.Documents)-1].Path != issue.Path {
+			group.Documents = append(group.Documents, DocumentIssues{Path: issue.Path})
+		}
+		document := &group.Documents[len(group.Documents)-1]
+		document.Issues = append(document.Issues, issue)
+	}
+	return groups
+}
+
+// IssuesByRepo returns the issues of the graph grouped by repository and by file
+// @llr REQ-TRAQ-SWL-116
+func (rg ReqGraph) IssuesByRepo() []RepoIssues {
+	return GroupIssues(rg.Issues)
+}
+
+// issueFingerprint identifies the issues reported for the same problem, e.g. in code parsed for several
+// architectures
+type issueFingerprint struct {
+	RepoName    repos.RepoName
+	Path        string
+	Line        int
+	Description string
+	Severity    diagnostics.IssueSeverity
+	Type        diagnostics.IssueType
+}
+
+// deduplicateIssues merges the issues with the same fingerprint, keeping the first one in its original position.
+// The architectures of the merged issues are collected, and the descriptions of the issues found for specific
+// architectures are annotated with them. An issue found in code which does not depend on the architecture applies
+// to all of them, so it is not annotated.
+// @llr REQ-TRAQ-SWL-133
+func deduplicateIssues(issues []diagnostics.Issue) []diagnostics.Issue {
+	var deduplicated []diagnostics.Issue
+	// The index of the deduplicated issue of each fingerprint, and whether it was found independently of the
+	// architecture
+	index := make(map[issueFingerprint]int)
+	archIndependent := make(map[issueFingerprint]bool)
+	for _, issue := range issues {
+		fingerprint := issueFingerprint{issue.RepoName, issue.Path, issue.Line, issue.Description, issue.Severity, issue.Type}
+		i, found := index[fingerprint]
+		if !found {
+			i = len(deduplicated)
+			index[fingerprint] = i
+			issue.Archs = append([]string(nil), issue.Archs...)
+			deduplicated = append(deduplicated, issue)
+		} else {
+			deduplicated[i].Archs = append(deduplicated[i].Archs, issue.Archs...)
+		}
+		if len(issue.Archs) == 0 {
+			archIndependent[fingerprint] = true
+		}
+	}
+
+	for fingerprint, i := range index {
+		issue := &deduplicated[i]
+		if archIndependent[fingerprint] {
+			issue.Archs = nil
+			continue
+		}
+		if len(issue.Archs) == 0 {
+			continue
+		}
+		sort.Strings(issue.Archs)
+		archs := issue.Archs[:1]
+		for _, arch := range issue.Archs[1:] {
+			if arch != archs[len(archs)-1] {
+				archs = append(archs, arch)
+			}
+		}
+		issue.Archs = archs
+		issue.Description = fmt.Sprintf("%s (archs: %s)", issue.Description, strings.Join(archs, ", "))
+	}
+	return deduplicated
+}
+
+// The number of hexadecimal digits of the fingerprint of an issue
+const issueFingerprintLength = 12
+
+// IssueFingerprint returns a short hash identifying the issue across runs. The line is left out, so the fingerprint
+// of an issue whose description does not mention its line does not change when lines are added above it. The type is
+// hashed by its code, which unlike its value does not change when types are added.
+// @llr REQ-TRAQ-SWL-135
+func IssueFingerprint(issue diagnostics.Issue) string {
+	_, code := diagnostics.TypeInfo(issue.Type)
+	text := fmt.Sprintf("%s\x00%s\x00%s\x00%s", issue.RepoName, issue.Path, code, issue.Description)
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])[:issueFingerprintLength]
+}
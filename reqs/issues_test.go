@@ -0,0 +1,79 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-116
+func TestGroupIssues(t *testing.T) {
+	issues := []diagnostics.Issue{
+		{RepoName: "projectB", Path: "TEST-137-SRD.md", Line: 12, Description: "b1"},
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 30, Description: "a1"},
+		{RepoName: "projectA", Path: "TEST-137-SRD.md", Line: 8, Description: "a2"},
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 4, Description: "a3"},
+		{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 30, Description: "a4"},
+	}
+
+	assert.Equal(t, []RepoIssues{
+		{RepoName: "projectA", Count: 4, Documents: []DocumentIssues{
+			{Path: "TEST-137-SRD.md", Issues: []diagnostics.Issue{issues[2]}},
+			{Path: "TEST-138-SDD.md", Issues: []diagnostics.Issue{issues[3], issues[1], issues[4]}},
+		}},
+		{RepoName: "projectB", Count: 1, Documents: []DocumentIssues{
+			{Path: "TEST-137-SRD.md", Issues: []diagnostics.Issue{issues[0]}},
+		}},
+	}, GroupIssues(issues))
+	assert.Empty(t, GroupIssues(nil))
+
+	// The original order is kept
+	assert.Equal(t, "b1", issues[0].Description)
+
+	assert.Equal(t, []diagnostics.Issue{issues[1], issues[2], issues[3], issues[4]}, IssuesOfRepo(issues, "projectA"))
+	assert.Empty(t, IssuesOfRepo(issues, "projectC"))
+}
+
+// @llr REQ-TRAQ-SWL-133
+func TestDeduplicateIssues(t *testing.T) {
+	missing := diagnostics.Issue{RepoName: "projectA", Path: "a.cc", Line: 3, Description: "Function f@projectA: a.cc:3 has no parents.",
+		Type: diagnostics.IssueTypeMissingRequirementInCode}
+	archIssue := func(issue diagnostics.Issue, archs ...string) diagnostics.Issue {
+		issue.Archs = archs
+		return issue
+	}
+	other := diagnostics.Issue{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 5, Description: "Requirement REQ-TEST-SWL-1 is not tested."}
+	generic := diagnostics.Issue{RepoName: "projectA", Path: "b.cc", Line: 8, Description: "Function g@projectA: b.cc:8 has no parents."}
+
+	assert.Equal(t, []diagnostics.Issue{
+		{RepoName: "projectA", Path: "a.cc", Line: 3, Description: "Function f@projectA: a.cc:3 has no parents. (archs: arm, x86)",
+			Type: diagnostics.IssueTypeMissingRequirementInCode, Archs: []string{"arm", "x86"}},
+		other,
+		generic,
+	}, deduplicateIssues([]diagnostics.Issue{
+		archIssue(missing, "x86"), other, archIssue(missing, "arm"), archIssue(generic, "arm"), generic, archIssue(missing, "x86"),
+	}))
+
+	// Issues found for a single architecture are annotated too
+	assert.Equal(t, "Function f@projectA: a.cc:3 has no parents. (archs: arm)",
+		deduplicateIssues([]diagnostics.Issue{archIssue(missing, "arm")})[0].Description)
+}
+
+// @llr REQ-TRAQ-SWL-135
+func TestIssueFingerprint(t *testing.T) {
+	issue := diagnostics.Issue{RepoName: "projectA", Path: "TEST-138-SDD.md", Line: 5, Description: "Requirement REQ-TEST-SWL-1 is not tested.",
+		Type: diagnostics.IssueTypeReqNotTested}
+	fingerprint := IssueFingerprint(issue)
+	assert.Len(t, fingerprint, 12)
+
+	moved := issue
+	moved.Line = 9
+	assert.Equal(t, fingerprint, IssueFingerprint(moved))
+	other := issue
+	other.Description = "Requirement REQ-TEST-SWL-2 is not tested."
+	assert.NotEqual(t, fingerprint, IssueFingerprint(other))
+
+	// The fingerprint is stable across releases
+	assert.Equal(t, "8aae5c99aff4", IssueFingerprint(issue))
+}
@@ -0,0 +1,78 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// checkDocumentLimits reports the documents with more requirements which are not deleted than the configured limit,
+// and the requirements whose body is longer than the configured limit, recommending to split them so they stay
+// reviewable. The severity of the issues is configured by the lint policy and defaults to a note.
+// @llr REQ-TRAQ-SWL-191
+func (rg *ReqGraph) checkDocumentLimits() []diagnostics.Issue {
+	if rg.ReqtraqConfig == nil {
+		return nil
+	}
+	limits := rg.ReqtraqConfig.DocumentLimits
+	if limits.MaxRequirements == 0 && limits.MaxBodyLength == 0 {
+		return nil
+	}
+	severity, enabled := rg.lintSeverity(config.LintCheckDocumentLimits, diagnostics.IssueSeverityNote)
+	if !enabled {
+		return nil
+	}
+
+	var issues []diagnostics.Issue
+	counts := make(map[IssueLocation]int)
+	for _, r := range rg.Reqs {
+		if r.IsDeleted() || r.Document == nil {
+			continue
+		}
+		counts[IssueLocation{RepoName: r.RepoName, Path: r.Document.Path}]++
+
+		length := utf8.RuneCountInString(strings.TrimSpace(r.Body))
+		if limits.MaxBodyLength > 0 && length > limits.MaxBodyLength {
+			issues = append(issues, diagnostics.Issue{
+				Line:     r.Position,
+				Path:     r.Document.Path,
+				RepoName: r.RepoName,
+				Description: fmt.Sprintf("Requirement `%s` has a body of %d characters, more than the limit of %d: consider splitting it into several requirements.",
+					r.ID, length, limits.MaxBodyLength),
+				Severity: severity,
+				Type:     diagnostics.IssueTypeBodyTooLong,
+			})
+		}
+	}
+
+	if limits.MaxRequirements > 0 {
+		for document, count := range counts {
+			if count <= limits.MaxRequirements {
+				continue
+			}
+			issues = append(issues, diagnostics.Issue{
+				Path:     document.Path,
+				RepoName: document.RepoName,
+				Description: fmt.Sprintf("Document `%s` has %d requirements, more than the limit of %d: consider splitting it into several files, as a directory of fragments.",
+					document.Path, count, limits.MaxRequirements),
+				Severity: severity,
+				Type:     diagnostics.IssueTypeDocumentTooLarge,
+			})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].RepoName != issues[j].RepoName {
+			return issues[i].RepoName < issues[j].RepoName
+		}
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
@@ -0,0 +1,55 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-191
+func TestReqGraph_CheckDocumentLimits(t *testing.T) {
+	srd := &config.Document{Path: "TEST-137-SRD.md"}
+	sdd := &config.Document{Path: "TEST-138-SDD.md"}
+	rg := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Title: "Log file", Body: "The log shall be written.", Document: sdd, RepoName: "repo", Position: 3},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Title: "Log rotation", Body: "The log shall be rotated daily.", Document: sdd, RepoName: "repo", Position: 9},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", Title: "Log level", Body: "The level shall be set.", Document: sdd, RepoName: "repo", Position: 15},
+		"REQ-TEST-SWL-4": {ID: "REQ-TEST-SWL-4", Title: "DELETED", Body: "The log shall be compressed with a very long body.", Document: sdd, RepoName: "repo", Position: 21},
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Title: "Logging", Body: "Logs shall be kept.", Document: srd, RepoName: "repo", Position: 5},
+	}}
+
+	// No limits are configured by default
+	assert.Empty(t, rg.checkDocumentLimits())
+	rg.ReqtraqConfig = &config.Config{}
+	assert.Empty(t, rg.checkDocumentLimits())
+
+	rg.ReqtraqConfig.DocumentLimits = config.DocumentLimits{MaxRequirements: 2, MaxBodyLength: 30}
+	expected := []diagnostics.Issue{
+		{
+			Path:        "TEST-138-SDD.md",
+			RepoName:    "repo",
+			Description: "Document `TEST-138-SDD.md` has 3 requirements, more than the limit of 2: consider splitting it into several files, as a directory of fragments.",
+			Severity:    diagnostics.IssueSeverityNote,
+			Type:        diagnostics.IssueTypeDocumentTooLarge,
+		},
+		{
+			Line:        9,
+			Path:        "TEST-138-SDD.md",
+			RepoName:    "repo",
+			Description: "Requirement `REQ-TEST-SWL-2` has a body of 31 characters, more than the limit of 30: consider splitting it into several requirements.",
+			Severity:    diagnostics.IssueSeverityNote,
+			Type:        diagnostics.IssueTypeBodyTooLong,
+		},
+	}
+	assert.Equal(t, expected, rg.checkDocumentLimits())
+
+	// The lint policy changes the severity or disables the check
+	rg.ReqtraqConfig.LintPolicy = map[string]config.LintSeverity{config.LintCheckDocumentLimits: config.LintSeverityWarning}
+	expected[0].Severity = diagnostics.IssueSeverityMinor
+	expected[1].Severity = diagnostics.IssueSeverityMinor
+	assert.Equal(t, expected, rg.checkDocumentLimits())
+	rg.ReqtraqConfig.LintPolicy[config.LintCheckDocumentLimits] = config.LintSeverityOff
+	assert.Empty(t, rg.checkDocumentLimits())
+}
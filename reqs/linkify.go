@@ -0,0 +1,181 @@
+package reqs
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+var (
+	// A markdown inline link, capturing its text
+	reMarkdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// An attribute line declaring the parents of a requirement, capturing the key and the value
+	reParentsAttribute = regexp.MustCompile(`(?i)^(- parents?:)(.*)$`)
+)
+
+// stripMarkdownLinks replaces the markdown links in the given text with their text
+// @llr REQ-TRAQ-SWL-102
+func stripMarkdownLinks(txt string) string {
+	return reMarkdownLink.ReplaceAllString(txt, "$1")
+}
+
+// headingAnchor returns the anchor that git web interfaces generate for a markdown heading: the text in
+// lowercase, without punctuation and with spaces replaced by hyphens.
+// @llr REQ-TRAQ-SWL-102
+func headingAnchor(heading string) string {
+	heading = strings.TrimSpace(strings.Trim(strings.TrimSpace(heading), "#"))
+	var anchor strings.Builder
+	for _, c := range strings.ToLower(heading) {
+		switch {
+		case c == ' ':
+			anchor.WriteRune('-')
+		case c == '-' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			anchor.WriteRune(c)
+		}
+	}
+	return anchor.String()
+}
+
+// linkifier rewrites the parents of the requirements in documents as links to their definitions
+type linkifier struct {
+	rg *ReqGraph
+	// The lines of the documents read so far, by repository and path
+	lines map[repos.RepoName]map[string][]string
+}
+
+// documentLines returns the lines of the given document of a repository
+// @llr REQ-TRAQ-SWL-102
+func (l *linkifier) documentLines(repoName repos.RepoName, path string) ([]string, error) {
+	if lines, ok := l.lines[repoName][path]; ok {
+		return lines, nil
+	}
+	fullPath, err := repos.PathInRepo(repoName, path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if l.lines[repoName] == nil {
+		l.lines[repoName] = make(map[string][]string)
+	}
+	l.lines[repoName][path] = strings.Split(string(content), "\n")
+	return l.lines[repoName][path], nil
+}
+
+// target returns the relative link to the definition of the given requirement from the given file,
+// or an empty string if the requirement is not defined in the same repository. Requirements defined in
+// headings are linked to the anchor of the heading, those defined in tables to their document.
+// @llr REQ-TRAQ-SWL-102, REQ-TRAQ-SWL-130
+func (l *linkifier) target(id string, repoName repos.RepoName, fromPath string) (string, error) {
+	r, ok := l.rg.Reqs[id]
+	if !ok || r.Document == nil || r.RepoName != repoName {
+		return "", nil
+	}
+
+	// The file defining the requirement, which is a fragment for documents split in several files
+	path, line := r.Document.Locate(r.Position)
+	lines, err := l.documentLines(repoName, path)
+	if err != nil {
+		return "", err
+	}
+	anchor := ""
+	if line > 0 && line <= len(lines) && strings.HasPrefix(lines[line-1], "#") {
+		anchor = "#" + headingAnchor(lines[line-1])
+	}
+
+	if path == fromPath {
+		return anchor, nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(fromPath), path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel) + anchor, nil
+}
+
+// linkifyParents returns the given parents with each requirement ID linked to its definition
+// @llr REQ-TRAQ-SWL-102
+func (l *linkifier) linkifyParents(parents string, reParentID *regexp.Regexp, repoName repos.RepoName, fromPath string) (string, error) {
+	var err error
+	linked := reParentID.ReplaceAllStringFunc(stripMarkdownLinks(parents), func(id string) string {
+		target, targetErr := l.target(id, repoName, fromPath)
+		if targetErr != nil {
+			err = targetErr
+		}
+		if target == "" {
+			return id
+		}
+		return "[" + id + "](" + target + ")"
+	})
+	return linked, err
+}
+
+// Linkify returns the content of the given file of a document, the document itself or one of its fragments, with
+// the parents of its requirements linked to the definition of the parent requirements in the same repository, so
+// the documents can be browsed in git web interfaces. Existing links are replaced, so documents can be linkified
+// again after changes.
+// @llr REQ-TRAQ-SWL-102, REQ-TRAQ-SWL-130
+func (rg *ReqGraph) Linkify(repoName repos.RepoName, doc *config.Document, path string) (string, error) {
+	l := &linkifier{rg: rg, lines: make(map[repos.RepoName]map[string][]string)}
+	reParentID := newIDGrammar(doc).parents
+
+	original, err := l.documentLines(repoName, path)
+	if err != nil {
+		return "", err
+	}
+	lines := make([]string, len(original))
+	copy(lines, original)
+
+	parentsColumn := -1
+	for i, line := range lines {
+		if m := reParentsAttribute.FindStringSubmatch(line); m != nil {
+			linked, err := l.linkifyParents(m[2], reParentID, repoName, path)
+			if err != nil {
+				return "", err
+			}
+			lines[i] = m[1] + linked
+			continue
+		}
+
+		// Tables of requirements, where parents are in the column named Parents
+		if !strings.HasPrefix(line, "|") {
+			parentsColumn = -1
+			continue
+		}
+		if reTableHeader.MatchString(line) {
+			parentsColumn = -1
+			for column, name := range splitTableLine(line) {
+				if k := strings.ToUpper(name); k == "PARENTS" || k == "PARENT" {
+					parentsColumn = column
+				}
+			}
+			continue
+		}
+		if parentsColumn < 0 || reTableDelimiter.MatchString(line) {
+			continue
+		}
+		// The cells are preceded by the `|` at the beginning of the line
+		cells := strings.Split(line, "|")
+		if parentsColumn+1 >= len(cells) {
+			continue
+		}
+		cell := cells[parentsColumn+1]
+		linked, err := l.linkifyParents(strings.TrimSpace(cell), reParentID, repoName, path)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(cell) != "" {
+			cells[parentsColumn+1] = " " + linked + " "
+		}
+		lines[i] = strings.Join(cells, "|")
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,67 @@
+package reqs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-102
+func TestHeadingAnchor(t *testing.T) {
+	assert.Equal(t, "req-test-swh-1-boot-time", headingAnchor("## REQ-TEST-SWH-1 Boot time"))
+	assert.Equal(t, "req-test-swh-2-parsing-markdown_files", headingAnchor("#### REQ-TEST-SWH-2 Parsing (markdown_files)!"))
+}
+
+// @llr REQ-TRAQ-SWL-102
+func TestReqGraph_Linkify(t *testing.T) {
+	repoPath := t.TempDir()
+	repos.RegisterRepository("linkify", repos.RepoPath(repoPath))
+	files := map[string]string{
+		"sys/TEST-100-ORD.md": "| ID | Title | Body |\n| --- | --- | --- |\n| REQ-TEST-SYS-1 | Boot | The system shall boot. |\n",
+		"sw/TEST-137-SRD.md":  "# Software\n\n## REQ-TEST-SWH-1 Boot time\n\nThe software shall boot.\n\n### Attributes:\n- Parents: REQ-TEST-SYS-1\n",
+		"sw/TEST-138-SDD.md": "# Design\n\n## REQ-TEST-SWL-1 Boot loader\n\nThe loader shall boot.\n\n### Attributes:\n" +
+			"- Parents: [REQ-TEST-SWH-1](old.md#outdated), REQ-OTHER-SWH-1\n\n" +
+			"| ID | Title | Body | Parents |\n| --- | --- | --- | --- |\n| REQ-TEST-SWL-2 | Log | The loader shall log. | REQ-TEST-SWL-1 |\n",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, path), []byte(content), 0644))
+	}
+
+	sysDoc := config.Document{Path: "sys/TEST-100-ORD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SYS"}}
+	swhDoc := config.Document{Path: "sw/TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+	swlDoc := config.Document{Path: "sw/TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	rg := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SYS-1":  {ID: "REQ-TEST-SYS-1", Document: &sysDoc, RepoName: "linkify", Position: 3},
+		"REQ-TEST-SWH-1":  {ID: "REQ-TEST-SWH-1", Document: &swhDoc, RepoName: "linkify", Position: 3},
+		"REQ-TEST-SWL-1":  {ID: "REQ-TEST-SWL-1", Document: &swlDoc, RepoName: "linkify", Position: 3},
+		"REQ-OTHER-SWH-1": {ID: "REQ-OTHER-SWH-1", Document: &swhDoc, RepoName: "other", Position: 3},
+	}}
+
+	linked, err := rg.Linkify("linkify", &swhDoc, swhDoc.Path)
+	assert.NoError(t, err)
+	assert.Contains(t, linked, "- Parents: [REQ-TEST-SYS-1](../sys/TEST-100-ORD.md)\n")
+
+	linked, err = rg.Linkify("linkify", &swlDoc, swlDoc.Path)
+	assert.NoError(t, err)
+	// Existing links are updated and requirements from other repositories are not linked
+	assert.Contains(t, linked, "- Parents: [REQ-TEST-SWH-1](TEST-137-SRD.md#req-test-swh-1-boot-time), REQ-OTHER-SWH-1\n")
+	// Requirements in the same document are linked by their anchor
+	assert.Contains(t, linked, "| REQ-TEST-SWL-2 | Log | The loader shall log. | [REQ-TEST-SWL-1](#req-test-swl-1-boot-loader) |\n")
+
+	// Linkified parents are parsed as plain identifiers
+	r := &Req{ID: "REQ-TEST-SWL-1", Attributes: map[string]string{
+		"PARENTS": "[REQ-TEST-SWH-1](TEST-137-SRD.md#req-test-swh-1-boot-time), REQ-OTHER-SWH-1"}}
+	assert.NoError(t, parseParents(r, reReqID))
+	assert.Equal(t, []string{"REQ-TEST-SWH-1", "REQ-OTHER-SWH-1"}, r.ParentIds)
+
+	// Linkifying again does not change the document
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, swlDoc.Path), []byte(linked), 0644))
+	relinked, err := rg.Linkify("linkify", &swlDoc, swlDoc.Path)
+	assert.NoError(t, err)
+	assert.Equal(t, linked, relinked)
+}
@@ -0,0 +1,92 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// SchemaVersionMarker returns the marker recording in a document that it was migrated to the given schema version
+// @llr REQ-TRAQ-SWL-164
+func SchemaVersionMarker(version int) string {
+	return fmt.Sprintf("<!-- reqtraq:schema-version %d -->", version)
+}
+
+// StubMissingAttributes returns the content of a file of the given document with a placeholder value for each
+// required attribute missing from its requirements defined in headings, appended to their attributes section,
+// which is created if needed. The schema version of the document, if any, is recorded in the marker of the file,
+// which is added at its top if needed. Also returns the requirements missing attributes which are defined in tables
+// and cannot be completed.
+// @llr REQ-TRAQ-SWL-164
+func StubMissingAttributes(repoName repos.RepoName, doc *config.Document, content string) (string, []*Req, error) {
+	requirements, _, err := parseMarkdownContent(repoName, doc, strings.NewReader(content))
+	if err != nil {
+		return "", nil, err
+	}
+	// The requirements are completed from the last one, so the positions of the previous ones do not change
+	sort.Slice(requirements, func(i, j int) bool { return requirements[i].Position > requirements[j].Position })
+
+	lines := strings.Split(content, "\n")
+	var tables []*Req
+	for _, r := range requirements {
+		missing := r.MissingAttributes()
+		if len(missing) == 0 {
+			continue
+		}
+		parts := reATXHeading.FindStringSubmatch(lines[r.Position-1])
+		if parts == nil {
+			tables = append(tables, r)
+			continue
+		}
+		level := len(parts[1])
+		start := r.Position - 1
+		end := reqEnd(lines, start, level)
+
+		var stubs []string
+		attrs := -1
+		for i := start + 1; i < end; i++ {
+			if reAttributesHeading.MatchString(lines[i]) {
+				attrs = i
+				break
+			}
+		}
+		insertAt := end
+		if attrs < 0 {
+			attrsLevel := level + 1
+			if attrsLevel > 6 {
+				attrsLevel = 6
+			}
+			stubs = append(stubs, "", strings.Repeat("#", attrsLevel)+" Attributes:")
+		} else {
+			// After the last line of the attributes section
+			insertAt = attrs + 1
+			for i := attrs + 1; i < end && !reATXHeading.MatchString(lines[i]); i++ {
+				if strings.TrimSpace(lines[i]) != "" {
+					insertAt = i + 1
+				}
+			}
+		}
+		for _, name := range missing {
+			stubs = append(stubs, fmt.Sprintf("- %s: %s", strings.Title(strings.ToLower(name)), NewReqPlaceholder))
+		}
+		lines = append(append(append([]string{}, lines[:insertAt]...), stubs...), lines[insertAt:]...)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Position < tables[j].Position })
+
+	if doc.Schema.Version > 0 {
+		marker := SchemaVersionMarker(doc.Schema.Version)
+		found := false
+		for i, line := range lines {
+			if reSchemaVersionMarker.MatchString(line) {
+				lines[i], found = marker, true
+			}
+		}
+		if !found {
+			lines = append([]string{marker, ""}, lines...)
+		}
+	}
+	return strings.Join(lines, "\n"), tables, nil
+}
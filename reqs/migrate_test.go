@@ -0,0 +1,140 @@
+package reqs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// migrateTestDocument returns a document whose schema version 2 introduced the required Verification attribute
+// @llr REQ-TRAQ-SWL-164
+func migrateTestDocument() config.Document {
+	return config.Document{
+		Path:    "TEST-138-SDD.md",
+		ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"},
+		Schema: config.Schema{Version: 2, Attributes: map[string]*config.Attribute{
+			"SAFETY IMPACT": {Type: config.AttributeRequired, Value: regexp.MustCompile(".*")},
+			"VERIFICATION":  {Type: config.AttributeRequired, Value: regexp.MustCompile(".*"), Since: 2},
+			"NOTES":         {Type: config.AttributeOptional, Value: regexp.MustCompile(".*")},
+		}},
+	}
+}
+
+// @llr REQ-TRAQ-SWL-164
+func TestStubMissingAttributes(t *testing.T) {
+	doc := migrateTestDocument()
+	content := `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Safety Impact: None
+
+#### Notes
+
+Rotated by logrotate.
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+
+### REQ-TEST-SWL-3 Log level
+
+The log level SHALL be configurable.
+
+#### Attributes:
+- Safety Impact: None
+- Verification: Test
+`
+	stubbed, tables, err := StubMissingAttributes("repo", &doc, content)
+	assert.NoError(t, err)
+	assert.Empty(t, tables)
+	assert.Equal(t, `<!-- reqtraq:schema-version 2 -->
+
+# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Safety Impact: None
+- Verification: TODO
+
+#### Notes
+
+Rotated by logrotate.
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+
+#### Attributes:
+- Safety Impact: TODO
+- Verification: TODO
+
+### REQ-TEST-SWL-3 Log level
+
+The log level SHALL be configurable.
+
+#### Attributes:
+- Safety Impact: None
+- Verification: Test
+`, stubbed)
+
+	// Migrating again only updates the marker
+	doc.Schema.Version = 3
+	again, _, err := StubMissingAttributes("repo", &doc, stubbed)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Replace(stubbed, "schema-version 2", "schema-version 3", 1), again)
+
+	// Requirements defined in tables cannot be completed
+	table := `| ID | Title | Body | Safety Impact |
+| --- | --- | --- | --- |
+| REQ-TEST-SWL-1 | Log file | The logs SHALL be written to a file. | None |
+`
+	_, tables, err = StubMissingAttributes("repo", &doc, table)
+	assert.NoError(t, err)
+	if assert.Len(t, tables, 1) {
+		assert.Equal(t, "REQ-TEST-SWL-1", tables[0].ID)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-165
+func TestReq_CheckAttributes_SchemaVersion(t *testing.T) {
+	doc := migrateTestDocument()
+	content := `<!-- reqtraq:schema-version 1 -->
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Safety Impact: None
+`
+	requirements, _, err := parseMarkdownContent("repo", &doc, strings.NewReader(content))
+	if !assert.NoError(t, err) || !assert.Len(t, requirements, 1) {
+		return
+	}
+	r := requirements[0]
+	assert.Equal(t, 1, r.SchemaVersion)
+	assert.Equal(t, []string{"VERIFICATION"}, r.MissingAttributes())
+	issues := r.checkAttributes()
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, diagnostics.IssueSeverityNote, issues[0].Severity)
+		assert.Equal(t, "Requirement 'REQ-TEST-SWL-1' is missing attribute 'VERIFICATION' of schema version 2. Run reqtraq migrate to add it.", issues[0].Description)
+	}
+
+	// Once the document is migrated, the missing attribute fails the validation
+	r.SchemaVersion = 2
+	issues = r.checkAttributes()
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, diagnostics.IssueSeverityMajor, issues[0].Severity)
+	}
+}
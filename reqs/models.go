@@ -0,0 +1,124 @@
+package reqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// ModelElement is a block of a model-based design, e.g. of a Simulink or SCADE model, implementing requirements
+type ModelElement struct {
+	// The tool the model is designed with, e.g. Simulink
+	Tool string `json:",omitempty"`
+	// The model file containing the block and the path of the block in the model
+	Model string
+	Path  string
+	// The IDs of the requirements implemented by the block
+	ReqIds []string
+	// The repository declaring the manifest listing the block and the path of the manifest
+	RepoName repos.RepoName
+	Manifest string
+}
+
+// Name returns the name identifying the model element in the matrices
+// @llr REQ-TRAQ-SWL-176
+func (m *ModelElement) Name() string {
+	return fmt.Sprintf("%s: %s - %s", m.RepoName, m.Model, m.Path)
+}
+
+// The manifest generated by the model tooling, listing the blocks of a model with the requirements they implement
+type jsonModelManifest struct {
+	Tool   string `json:"tool"`
+	Model  string `json:"model"`
+	Blocks []struct {
+		Path         string   `json:"path"`
+		Requirements []string `json:"requirements"`
+	} `json:"blocks"`
+}
+
+// readModelManifest returns the model elements listed in the manifest
+// @llr REQ-TRAQ-SWL-176
+func readModelManifest(settings config.ModelManifest) ([]*ModelElement, error) {
+	path := settings.Path
+	if !filepath.IsAbs(path) {
+		var err error
+		if path, err = repos.PathInRepo(settings.RepoName, settings.Path); err != nil {
+			return nil, errors.Wrapf(err, "Model manifest `%s` declared in config for repo `%s` cannot be found", settings.Path, settings.RepoName)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Model manifest `%s` declared in config for repo `%s` cannot be read", settings.Path, settings.RepoName)
+	}
+	var manifest jsonModelManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "Model manifest `%s` declared in config for repo `%s` is not valid", settings.Path, settings.RepoName)
+	}
+	if manifest.Model == "" {
+		return nil, fmt.Errorf("Model manifest `%s` does not name its model", settings.Path)
+	}
+
+	elements := make([]*ModelElement, 0, len(manifest.Blocks))
+	for _, block := range manifest.Blocks {
+		if block.Path == "" {
+			return nil, fmt.Errorf("Model manifest `%s` lists a block without path", settings.Path)
+		}
+		elements = append(elements, &ModelElement{
+			Tool:     manifest.Tool,
+			Model:    manifest.Model,
+			Path:     block.Path,
+			ReqIds:   block.Requirements,
+			RepoName: settings.RepoName,
+			Manifest: settings.Path,
+		})
+	}
+	return elements, nil
+}
+
+// LoadModelElements adds to the graph the model elements listed in the given manifests, ordered by repository,
+// model and path, and returns the issues of the elements referencing requirements which do not exist
+// @llr REQ-TRAQ-SWL-176
+func (rg *ReqGraph) LoadModelElements(manifests []config.ModelManifest) ([]diagnostics.Issue, error) {
+	rg.ModelElements = nil
+	for _, manifest := range manifests {
+		elements, err := readModelManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+		rg.ModelElements = append(rg.ModelElements, elements...)
+	}
+	sort.SliceStable(rg.ModelElements, func(i, j int) bool {
+		a, b := rg.ModelElements[i], rg.ModelElements[j]
+		if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Path < b.Path
+	})
+
+	var issues []diagnostics.Issue
+	for _, element := range rg.ModelElements {
+		for _, id := range element.ReqIds {
+			if _, ok := rg.Reqs[id]; ok {
+				continue
+			}
+			issues = append(issues, diagnostics.Issue{
+				Path:        element.Manifest,
+				RepoName:    element.RepoName,
+				Description: fmt.Sprintf("Invalid reference in block %s of model %s in repo `%s`, %s does not exist.", element.Path, element.Model, element.RepoName, id),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidRequirementInModel,
+			})
+		}
+	}
+	return issues, nil
+}
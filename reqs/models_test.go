@@ -0,0 +1,47 @@
+package reqs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-176
+func TestReqGraph_LoadModelElements(t *testing.T) {
+	dir := t.TempDir()
+	autopilot := filepath.Join(dir, "autopilot.json")
+	assert.NoError(t, os.WriteFile(autopilot, []byte(`{"tool": "Simulink", "model": "autopilot.slx", "blocks": [
+		{"path": "autopilot/Controller/Saturation", "requirements": ["REQ-TEST-SWL-1", "REQ-TEST-SWL-9"]},
+		{"path": "autopilot/Controller/PID", "requirements": ["REQ-TEST-SWL-1"]}
+	]}`), 0644))
+	display := filepath.Join(dir, "display.json")
+	assert.NoError(t, os.WriteFile(display, []byte(`{"tool": "SCADE", "model": "display.etp", "blocks": [
+		{"path": "display::Alert"}
+	]}`), 0644))
+
+	rg := &ReqGraph{Reqs: map[string]*Req{"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1"}}}
+	issues, err := rg.LoadModelElements([]config.ModelManifest{{Path: display, RepoName: "repo"}, {Path: autopilot, RepoName: "repo"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*ModelElement{
+		{Tool: "Simulink", Model: "autopilot.slx", Path: "autopilot/Controller/PID", ReqIds: []string{"REQ-TEST-SWL-1"}, RepoName: "repo", Manifest: autopilot},
+		{Tool: "Simulink", Model: "autopilot.slx", Path: "autopilot/Controller/Saturation", ReqIds: []string{"REQ-TEST-SWL-1", "REQ-TEST-SWL-9"}, RepoName: "repo", Manifest: autopilot},
+		{Tool: "SCADE", Model: "display.etp", Path: "display::Alert", RepoName: "repo", Manifest: display},
+	}, rg.ModelElements)
+	assert.Equal(t, "repo: autopilot.slx - autopilot/Controller/PID", rg.ModelElements[0].Name())
+	assert.Equal(t, []diagnostics.Issue{{
+		Path:        autopilot,
+		RepoName:    "repo",
+		Description: "Invalid reference in block autopilot/Controller/Saturation of model autopilot.slx in repo `repo`, REQ-TEST-SWL-9 does not exist.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInvalidRequirementInModel,
+	}}, issues)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`{"blocks": [{"path": "a/b"}]}`), 0644))
+	_, err = rg.LoadModelElements([]config.ModelManifest{{Path: invalid, RepoName: "repo"}})
+	assert.EqualError(t, err, "Model manifest `"+invalid+"` does not name its model")
+}
@@ -0,0 +1,140 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The placeholder of the title, body and attributes of a new requirement, to be replaced by its author
+const NewReqPlaceholder = "TODO"
+
+// NextIDNumber returns the number following the greatest ID number of the requirements of the given variant
+// @llr REQ-TRAQ-SWL-34, REQ-TRAQ-SWL-113
+func NextIDNumber(requirements []*Req, variant ReqVariant) int {
+	greatest := 0
+	for _, r := range requirements {
+		if r.Variant == variant && r.IDNumber > greatest {
+			greatest = r.IDNumber
+		}
+	}
+	return greatest + 1
+}
+
+// skeletonAttributes returns the names of the attributes pre-filled in a new requirement of the document: the
+// parents first, followed by the other attributes which are required, or of which one is required, sorted by name
+// @llr REQ-TRAQ-SWL-113
+func skeletonAttributes(doc *config.Document) []string {
+	var names []string
+	for name, attribute := range doc.Schema.Attributes {
+		if name != "PARENTS" && attribute.Type != config.AttributeOptional {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := doc.Schema.Attributes["PARENTS"]; ok {
+		names = append([]string{"PARENTS"}, names...)
+	}
+	return names
+}
+
+// reqEnd returns the index of the line following the last non-empty line of the requirement whose heading is at
+// the given index
+// @llr REQ-TRAQ-SWL-113
+func reqEnd(lines []string, headingIdx int, level int) int {
+	end := headingIdx + 1
+	for ; end < len(lines); end++ {
+		line := lines[end]
+		if parts := reATXHeading.FindStringSubmatch(line); parts != nil && len(parts[1]) <= level {
+			break
+		}
+		if reTableHeader.MatchString(line) || dfTableHeader.MatchString(line) || cfTableHeader.MatchString(line) {
+			break
+		}
+	}
+	for end > headingIdx+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return end
+}
+
+// InsertReqSkeleton inserts a new requirement in the content of a document, with the next available ID, the given
+// title and parent and placeholders for its body and mandatory attributes. The requirement is inserted after the
+// last requirement with the same parent or, if there is none, after the last requirement of the document.
+// Returns the new content of the document, the ID of the new requirement and the line of its heading.
+// @llr REQ-TRAQ-SWL-113
+func InsertReqSkeleton(repoName repos.RepoName, doc *config.Document, content string, title string, parent string) (string, string, int, error) {
+	requirements, _, err := parseMarkdownContent(repoName, doc, strings.NewReader(content))
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	if parent != "" {
+		if len(doc.LinkSpecs) == 0 {
+			return "", "", 0, fmt.Errorf("Requirements of document `%s` have no parents", doc.Path)
+		}
+		if newIDGrammar(doc).parents.FindString(parent) != parent {
+			return "", "", 0, fmt.Errorf("Invalid parent ID `%s` for document `%s`", parent, doc.Path)
+		}
+	}
+	if title == "" {
+		title = NewReqPlaceholder
+	}
+
+	// The new requirement goes after its siblings, or at the end of the document
+	var anchor, sibling *Req
+	for _, r := range requirements {
+		if anchor == nil || r.Position > anchor.Position {
+			anchor = r
+		}
+		for _, parentID := range r.ParentIds {
+			if parent != "" && parentID == parent && (sibling == nil || r.Position > sibling.Position) {
+				sibling = r
+			}
+		}
+	}
+	if sibling != nil {
+		anchor = sibling
+	}
+
+	lines := strings.Split(content, "\n")
+	level, insertAt := 2, len(lines)
+	for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	if anchor != nil {
+		parts := reATXHeading.FindStringSubmatch(lines[anchor.Position-1])
+		if parts == nil {
+			return "", "", 0, fmt.Errorf("Requirement %s of document `%s` is defined in a table, add a row to the table instead", anchor.ID, doc.Path)
+		}
+		level = len(parts[1])
+		insertAt = reqEnd(lines, anchor.Position-1, level)
+	}
+
+	id := doc.ReqSpec.Format().Format("REQ", doc.ReqSpec.Prefix, doc.ReqSpec.Level, NextIDNumber(requirements, ReqVariantRequirement))
+	skeleton := []string{
+		"",
+		fmt.Sprintf("%s %s %s", strings.Repeat("#", level), id, title),
+		"",
+		NewReqPlaceholder,
+	}
+	if attributes := skeletonAttributes(doc); len(attributes) > 0 {
+		skeleton = append(skeleton, "", strings.Repeat("#", level+1)+" Attributes:")
+		for _, name := range attributes {
+			value := NewReqPlaceholder
+			if name == "PARENTS" && parent != "" {
+				value = parent
+			}
+			skeleton = append(skeleton, fmt.Sprintf("- %s: %s", strings.Title(strings.ToLower(name)), value))
+		}
+	}
+	if insertAt < len(lines) && strings.TrimSpace(lines[insertAt]) != "" {
+		skeleton = append(skeleton, "")
+	}
+
+	newLines := append(append(append([]string{}, lines[:insertAt]...), skeleton...), lines[insertAt:]...)
+	return strings.Join(newLines, "\n"), id, insertAt + 2, nil
+}
@@ -0,0 +1,106 @@
+package reqs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-113
+func TestInsertReqSkeleton(t *testing.T) {
+	doc := config.Document{
+		Path:      "TEST-138-SDD.md",
+		ReqSpec:   config.ReqSpec{Prefix: "TEST", Level: "SWL"},
+		LinkSpecs: []config.LinkSpec{{Parent: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}},
+		Schema: config.Schema{Attributes: map[string]*config.Attribute{
+			"PARENTS":       {Type: config.AttributeAny, Value: regexp.MustCompile(".*")},
+			"SAFETY IMPACT": {Type: config.AttributeRequired, Value: regexp.MustCompile(".*")},
+			"RATIONALE":     {Type: config.AttributeAny, Value: regexp.MustCompile(".*")},
+			"NOTES":         {Type: config.AttributeOptional, Value: regexp.MustCompile(".*")},
+		}},
+	}
+	content := `# Design
+
+## Logging
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Safety Impact: None
+
+### REQ-TEST-SWL-4 Log rotation
+
+The logs SHALL be rotated.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-2
+
+## Appendix
+`
+
+	updated, id, line, err := InsertReqSkeleton("repo", &doc, content, "Log level", "REQ-TEST-SWH-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "REQ-TEST-SWL-5", id)
+	assert.Equal(t, 13, line)
+	assert.Equal(t, `# Design
+
+## Logging
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Safety Impact: None
+
+### REQ-TEST-SWL-5 Log level
+
+TODO
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Rationale: TODO
+- Safety Impact: TODO
+
+### REQ-TEST-SWL-4 Log rotation
+
+The logs SHALL be rotated.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-2
+
+## Appendix
+`, updated)
+
+	// Without siblings, the requirement goes after the last one of the document
+	updated, id, line, err = InsertReqSkeleton("repo", &doc, content, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "REQ-TEST-SWL-5", id)
+	assert.Equal(t, 20, line)
+	assert.Contains(t, updated, `- Parents: REQ-TEST-SWH-2
+
+### REQ-TEST-SWL-5 TODO
+
+TODO
+
+#### Attributes:
+- Parents: TODO
+- Rationale: TODO
+- Safety Impact: TODO
+
+## Appendix
+`)
+
+	_, _, _, err = InsertReqSkeleton("repo", &doc, content, "", "REQ-OTHER-1")
+	assert.EqualError(t, err, "Invalid parent ID `REQ-OTHER-1` for document `TEST-138-SDD.md`")
+
+	table := "# Design\n\n| ID | Title | Body |\n| --- | --- | --- |\n| REQ-TEST-SWL-1 | Log file | The logs SHALL be written to a file. |\n"
+	_, _, _, err = InsertReqSkeleton("repo", &doc, table, "", "")
+	assert.EqualError(t, err, "Requirement REQ-TEST-SWL-1 of document `TEST-138-SDD.md` is defined in a table, add a row to the table instead")
+}
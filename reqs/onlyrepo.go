@@ -0,0 +1,120 @@
+package reqs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// OnlyRepo is the repository whose documents and code are parsed by BuildGraph, all the repositories are parsed
+// when empty. The requirements, code and issues of the other repositories are loaded from OtherRepoGraphs.
+var OnlyRepo repos.RepoName
+
+// OtherRepoGraphs are the previously exported graphs the other repositories are loaded from when only OnlyRepo is
+// parsed. Without them, the references to the requirements of the other repositories are reported as external.
+var OtherRepoGraphs []string
+
+// checkOnlyRepo returns an error if the repository to parse is not one of the configured repositories
+// @llr REQ-TRAQ-SWL-182
+func checkOnlyRepo(reqtraqConfig *config.Config) error {
+	if OnlyRepo == "" {
+		return nil
+	}
+	if _, ok := reqtraqConfig.Repos[OnlyRepo]; !ok {
+		return fmt.Errorf("Unknown repository `%s`, it is not part of the configuration", OnlyRepo)
+	}
+	return nil
+}
+
+// documentOf returns the configured document of a repository with the given path
+// @llr REQ-TRAQ-SWL-182
+func (rg *ReqGraph) documentOf(repoName repos.RepoName, path string) (*config.Document, bool) {
+	repo, ok := rg.ReqtraqConfig.Repos[repoName]
+	if !ok {
+		return nil, false
+	}
+	for i := range repo.Documents {
+		if repo.Documents[i].Path == path {
+			return &repo.Documents[i], true
+		}
+	}
+	return nil, false
+}
+
+// loadOtherRepos adds to the graph the requirements, flow tags and code of the repositories other than OnlyRepo
+// found in the given exported graphs, and returns the issues the graphs record for those repositories. The loaded
+// requirements and code are linked to the configured documents and their links are reset, so they are resolved
+// along with the parsed ones.
+// @llr REQ-TRAQ-SWL-182
+func (rg *ReqGraph) loadOtherRepos(paths []string) ([]diagnostics.Issue, error) {
+	var issues []diagnostics.Issue
+	for _, path := range paths {
+		jsonFile, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "open")
+		}
+		g, err := ReadGraph(jsonFile)
+		jsonFile.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed reading graph `%s`", path)
+		}
+
+		for id, r := range g.Reqs {
+			if r.RepoName == OnlyRepo || r.Stub || r.Document == nil {
+				continue
+			}
+			if existing, ok := rg.Reqs[id]; ok && !existing.Stub {
+				continue
+			}
+			doc, ok := rg.documentOf(r.RepoName, r.Document.Path)
+			if !ok {
+				return nil, fmt.Errorf("Requirement %s of graph `%s` belongs to document `%s` of repository `%s`, which is not configured", id, path, r.Document.Path, r.RepoName)
+			}
+			r.Document = doc
+			r.Tags = nil
+			rg.Reqs[id] = r
+		}
+		for id, f := range g.FlowTags {
+			if f.RepoName == OnlyRepo || f.Document == nil {
+				continue
+			}
+			if _, ok := rg.FlowTags[id]; ok {
+				continue
+			}
+			doc, ok := rg.documentOf(f.RepoName, f.Document.Path)
+			if !ok {
+				return nil, fmt.Errorf("Flow tag %s of graph `%s` belongs to document `%s` of repository `%s`, which is not configured", id, path, f.Document.Path, f.RepoName)
+			}
+			f.Document = doc
+			f.Reqs = nil
+			rg.FlowTags[id] = f
+		}
+		for repoName, tags := range g.CodeTags {
+			if repoName == OnlyRepo {
+				continue
+			}
+			for _, tag := range tags {
+				var doc *config.Document
+				ok := false
+				if tag.Document != nil {
+					doc, ok = rg.documentOf(repoName, tag.Document.Path)
+				}
+				if !ok {
+					return nil, fmt.Errorf("Code %s@%s of graph `%s` does not implement a configured document", tag.Tag, tag.CodeFile.String(), path)
+				}
+				tag.Document = doc
+			}
+			rg.CodeTags[repoName] = append(rg.CodeTags[repoName], tags...)
+		}
+		for _, issue := range g.Issues {
+			if issue.RepoName != OnlyRepo && issue.RepoName != "" {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues, nil
+}
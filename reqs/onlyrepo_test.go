@@ -0,0 +1,74 @@
+package reqs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-182
+func TestReqGraph_LoadOtherRepos(t *testing.T) {
+	systemDoc := config.Document{Path: "SYSTEM-100-ORD.md", ReqSpec: config.ReqSpec{Prefix: "SYSTEM", Level: "SYS"}}
+	softwareDoc := config.Document{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+	reqtraqConfig := config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+		"system":   {Documents: []config.Document{systemDoc}},
+		"software": {Documents: []config.Document{softwareDoc}},
+	}}
+
+	// The graph exported before the software repository was edited
+	systemCode := &code.Code{CodeFile: code.CodeFile{RepoName: "system", Path: "check.c"}, Tag: "check", Document: &systemDoc}
+	exported := ReqGraph{
+		Reqs: map[string]*Req{
+			"REQ-SYSTEM-SYS-1": {ID: "REQ-SYSTEM-SYS-1", Document: &systemDoc, RepoName: "system", Tags: []*code.Code{systemCode}},
+			"REQ-TEST-SWH-1":   {ID: "REQ-TEST-SWH-1", Document: &softwareDoc, RepoName: "software", Title: "Stale"},
+		},
+		CodeTags: map[repos.RepoName][]*code.Code{"system": {systemCode}},
+		Issues: []diagnostics.Issue{
+			{RepoName: "system", Path: "SYSTEM-100-ORD.md", Description: "System issue"},
+			{RepoName: "software", Path: "TEST-137-SRD.md", Description: "Stale issue"},
+		},
+	}
+	data, err := json.Marshal(exported)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "graph.json")
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+
+	OnlyRepo = "software"
+	t.Cleanup(func() { OnlyRepo = "" })
+	assert.NoError(t, checkOnlyRepo(&reqtraqConfig))
+	parsed := &Req{ID: "REQ-TEST-SWH-1", Document: &reqtraqConfig.Repos["software"].Documents[0], RepoName: "software", Title: "Edited"}
+	rg := ReqGraph{
+		Reqs:          map[string]*Req{parsed.ID: parsed},
+		CodeTags:      make(map[repos.RepoName][]*code.Code),
+		FlowTags:      make(map[string]*Flow),
+		ReqtraqConfig: &reqtraqConfig,
+	}
+
+	issues, err := rg.loadOtherRepos([]string{path})
+	assert.NoError(t, err)
+	assert.Equal(t, []diagnostics.Issue{{RepoName: "system", Path: "SYSTEM-100-ORD.md", Description: "System issue"}}, issues)
+	// The parsed requirements are kept, the loaded ones are linked to the configured documents
+	assert.Same(t, parsed, rg.Reqs["REQ-TEST-SWH-1"])
+	loaded := rg.Reqs["REQ-SYSTEM-SYS-1"]
+	assert.Same(t, &reqtraqConfig.Repos["system"].Documents[0], loaded.Document)
+	assert.Empty(t, loaded.Tags)
+	assert.Len(t, rg.CodeTags["system"], 1)
+	assert.Same(t, &reqtraqConfig.Repos["system"].Documents[0], rg.CodeTags["system"][0].Document)
+
+	// Without the graphs, the requirements of the other repositories are external
+	repoName, ok := rg.externalRepoOf("REQ-SYSTEM-SYS-2")
+	assert.True(t, ok)
+	assert.Equal(t, repos.RepoName("system"), repoName)
+	_, ok = rg.externalRepoOf("REQ-TEST-SWH-2")
+	assert.False(t, ok)
+
+	OnlyRepo = "unknown"
+	assert.EqualError(t, checkOnlyRepo(&reqtraqConfig), "Unknown repository `unknown`, it is not part of the configuration")
+}
@@ -2,7 +2,7 @@
 Functions for parsing requirements out of markdown documents.
 
 The entry point is ParseMarkdown which in turns calls other functions as follows:
-  - parseMarkdown: Scans file one line at a time looking for requirements that either formatted within ATX headings
+  - parseMarkdownContent: Scans the document one line at a time looking for requirements that either formatted within ATX headings
     or held in tables. For each ATX requirement or table calls:
   - parseMarkdownFragment: Depending on the type of requirement calls one of the following functions.
 
@@ -15,13 +15,16 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/repos"
 )
 
@@ -29,9 +32,9 @@ var (
 	// For detecting ATX Headings, see http://spec.commonmark.org/0.27/#atx-headings
 	reATXHeading = regexp.MustCompile(`^ {0,3}(#{1,6})( +(.*)( #* *)?)?$`)
 
-	// For detecting the first row and delimiter of data/control flow table
-	cfTableHeader     = regexp.MustCompile(`^\| *Caller *\| *Flow Tag *\| *Callee *\| *Description *\|?$`)
-	dfTableHeader     = regexp.MustCompile(`^\| *Caller *\| *Flow Tag *\| *Callee *\| *Direction *\| *Description *\|?$`)
+	// For detecting the first row and delimiter of data/control flow table, which may have additional columns
+	cfTableHeader     = regexp.MustCompile(`^\| *Caller *\| *Flow Tag *\| *Callee *\| *Description *(?:\|[^\|]*)*$`)
+	dfTableHeader     = regexp.MustCompile(`^\| *Caller *\| *Flow Tag *\| *Callee *\| *Direction *\| *Description *(?:\|[^\|]*)*$`)
 	dcfTableDelimiter = regexp.MustCompile(`^\|(?: *-+ *\|)* *-+ *\|?$`)
 	dfId              = regexp.MustCompile(`^DF-(\w+)-(\d+)(-DELETED)?$`)
 	cfId              = regexp.MustCompile(`^CF-(\w+)-(\d+)(-DELETED)?$`)
@@ -49,12 +52,169 @@ var (
 	// For detecting attributes sections and attributes
 	reAttributesSectionHeading = regexp.MustCompile(`(?m)\n#{2,6} Attributes:$`)
 	reReqKWD                   = regexp.MustCompile(`(?mU)^- (.+):`)
+
+	// For detecting review comments, e.g. <!-- REVIEW(author): comment -->
+	reReviewComment = regexp.MustCompile(` *<!-- *(REVIEW|RESOLVED)\(([^)]*)\): *(.*?) *-->`)
+
+	// For detecting the markers of the sections excluded from parsing, e.g. <!-- reqtraq:ignore-begin -->
+	reIgnoreMarker = regexp.MustCompile(`^ {0,3}<!-- *reqtraq:ignore-(begin|end) *--> *$`)
+	// For detecting the marker recording the schema version a document was migrated to, e.g.
+	// <!-- reqtraq:schema-version 2 -->
+	reSchemaVersionMarker = regexp.MustCompile(`^ {0,3}<!-- *reqtraq:schema-version +(\d+) *--> *$`)
+
+	// Grammar of documents using the default requirement ID format
+	defaultIDGrammar = idGrammar{ids: config.DefaultIDFormat, parents: reReqID}
 )
 
-// ParseMarkdown parses a certification document and returns the found requirements.
-// @llr REQ-TRAQ-SWL-2, REQ-TRAQ-SWL-4
+// idGrammar holds the requirement ID formats used while parsing a document
+type idGrammar struct {
+	// Format of the requirements defined in the document
+	ids *config.IDFormat
+	// Matches the IDs of the requirements the document can refer to as parents
+	parents *regexp.Regexp
+	// The parent links of the document, which the parents of its requirements must follow
+	links []config.LinkSpec
+}
+
+// newIDGrammar creates the grammar for the given document, out of its own ID format and the ones of the
+// documents it links to
+// @llr REQ-TRAQ-SWL-93
+func newIDGrammar(documentConfig *config.Document) idGrammar {
+	idFormat := documentConfig.ReqSpec.Format()
+
+	// Parents may use the default format, the one of the document (for assumptions) or the one of any linked document
+	patterns := []string{reReqIdStr}
+	seen := map[string]bool{reReqIdStr: true}
+	for _, f := range append([]*config.IDFormat{idFormat}, parentIDFormats(documentConfig)...) {
+		if pattern := f.Regexp().String(); !seen[pattern] {
+			seen[pattern] = true
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 1 {
+		return idGrammar{ids: idFormat, parents: reReqID, links: documentConfig.LinkSpecs}
+	}
+
+	// Longer patterns first, so that the most specific format wins when several of them match
+	sort.SliceStable(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	return idGrammar{ids: idFormat, parents: regexp.MustCompile(strings.Join(patterns, "|")), links: documentConfig.LinkSpecs}
+}
+
+// checkParentLink returns an error if the requirement is covered by the parent links of the document, but none of
+// them allows the given parent. Only the IDs are checked, the attributes of the parent are not known yet.
+// @llr REQ-TRAQ-SWL-129
+func (g idGrammar) checkParentLink(r *Req, parentID string) error {
+	if r.Variant != ReqVariantRequirement {
+		return nil
+	}
+	var expected []string
+	for _, link := range g.links {
+		if link.Child.Re == nil || !link.Child.Re.MatchString(r.ID) {
+			continue
+		}
+		if link.Child.AttrKey != "" {
+			value, present := r.Attributes[link.Child.AttrKey]
+			if !present || !link.Child.AttrVal.MatchString(value) {
+				continue
+			}
+		}
+		if link.Parent.Re == nil || link.Parent.Re.MatchString(parentID) {
+			return nil
+		}
+		expected = append(expected, link.Parent.Re.String())
+	}
+	if len(expected) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s is not a valid parent of %s, expected an ID matching %s", parentID, r.ID, strings.Join(expected, " or "))
+}
+
+// parentIDFormats returns the ID formats of the documents linked as parents of the given document
+// @llr REQ-TRAQ-SWL-93
+func parentIDFormats(documentConfig *config.Document) []*config.IDFormat {
+	var formats []*config.IDFormat
+	for _, link := range documentConfig.LinkSpecs {
+		formats = append(formats, link.Parent.Format())
+	}
+	return formats
+}
+
+// ParseMarkdown parses a certification document and returns the found requirements. The fragments of a document
+// split in several files are parsed as a single file, so the positions are lines of their concatenation. Virtual
+// documents have no markdown file to parse.
+// @llr REQ-TRAQ-SWL-2, REQ-TRAQ-SWL-4, REQ-TRAQ-SWL-130, REQ-TRAQ-SWL-188
 func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]*Req, []*Flow, error) {
+	reqs, flow, _, _, err := parseDocument(repoName, documentConfig)
+	return reqs, flow, err
+}
+
+// parseDocument parses a certification document like ParseMarkdown and also returns the issues found while parsing
+// it and the document the requirements and flow tags link back to, which for documents split in several files is a
+// copy of the configured one with the line counts of the fragments as read.
+// @llr REQ-TRAQ-SWL-2, REQ-TRAQ-SWL-4, REQ-TRAQ-SWL-103, REQ-TRAQ-SWL-130, REQ-TRAQ-SWL-188
+func parseDocument(repoName repos.RepoName, documentConfig *config.Document) ([]*Req, []*Flow, []diagnostics.Issue, *config.Document, error) {
+	if documentConfig.Virtual {
+		return nil, nil, nil, documentConfig, nil
+	}
+	if len(documentConfig.Fragments) > 0 {
+		content, document, err := ReadDocument(repoName, documentConfig)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		reqs, flow, issues, err := parseMarkdownIssues(repoName, document, strings.NewReader(content))
+		return reqs, flow, issues, document, err
+	}
+
+	documentPath, err := repos.PathInRepo(repoName, documentConfig.Path)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	r, err := os.Open(documentPath)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	defer r.Close()
+	reqs, flow, issues, err := parseMarkdownIssues(repoName, documentConfig, r)
+	return reqs, flow, issues, documentConfig, err
+}
+
+// ReadDocument returns the content of the document, the concatenation of its fragments if it is split in several
+// files, together with the document whose fragments have the line counts of the content read.
+// @llr REQ-TRAQ-SWL-130
+func ReadDocument(repoName repos.RepoName, documentConfig *config.Document) (string, *config.Document, error) {
+	contents := []string{}
+	for _, file := range documentConfig.Files() {
+		path, err := repos.PathInRepo(repoName, file)
+		if err != nil {
+			return "", nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		contents = append(contents, string(content))
+	}
+	if len(documentConfig.Fragments) == 0 {
+		return config.JoinFragments(contents), documentConfig, nil
+	}
+	return config.JoinFragments(contents), documentConfig.WithContents(contents), nil
+}
+
+// parseMarkdownContent parses the content of a certification document and returns the found requirements.
+// @llr REQ-TRAQ-SWL-2, REQ-TRAQ-SWL-4, REQ-TRAQ-SWL-103, REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-155, REQ-TRAQ-SWL-165
+func parseMarkdownContent(repoName repos.RepoName, documentConfig *config.Document, r io.Reader) ([]*Req, []*Flow, error) {
+	reqs, flow, _, err := parseMarkdownIssues(repoName, documentConfig, r)
+	return reqs, flow, err
+}
+
+// parseMarkdownIssues parses the content of a certification document like parseMarkdownContent and also returns the
+// issues which do not prevent parsing it, e.g. the review comments which are not within a requirement.
+// @llr REQ-TRAQ-SWL-2, REQ-TRAQ-SWL-4, REQ-TRAQ-SWL-103, REQ-TRAQ-SWL-104, REQ-TRAQ-SWL-155, REQ-TRAQ-SWL-165
+func parseMarkdownIssues(repoName repos.RepoName, documentConfig *config.Document, r io.Reader) ([]*Req, []*Flow, []diagnostics.Issue, error) {
 	var (
+		err error
+
 		reqs []*Req
 
 		lastHeadingLevel int // The level of the last ATX heading.
@@ -64,18 +224,17 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 
 		reqBuf bytes.Buffer  // Temporary buffer for the fragment being read in.
 		inReq  ReqFormatType // The type of fragment being read.
-	)
 
-	documentPath, err := repos.PathInRepo(repoName, documentConfig.Path)
-	if err != nil {
-		return nil, nil, err
-	}
+		reviewComments = make(map[int][]ReviewComment) // The review comments by position of their requirement.
+		strayComments  []ReviewComment                 // The review comments which are not within a requirement.
+
+		ignoredLine int // The line number of the marker starting the section being excluded from parsing, if any.
+
+		schemaVersion int // The schema version the document was migrated to.
+	)
 
-	r, err := os.Open(documentPath)
-	if err != nil {
-		return nil, nil, err
-	}
 	scan := bufio.NewScanner(r)
+	grammar := newIDGrammar(documentConfig)
 
 	flow := []*Flow{}
 	//TODO:
@@ -84,15 +243,46 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 	for lno := 1; scan.Scan(); lno++ {
 		line := scan.Text()
 
+		// sections between ignore markers, e.g. informative examples, are skipped entirely
+		if marker := reIgnoreMarker.FindStringSubmatch(line); marker != nil {
+			switch {
+			case marker[1] == "begin" && ignoredLine != 0:
+				return nil, nil, nil, fmt.Errorf("ignore marker on line %d is within the section ignored on line %d", lno, ignoredLine)
+			case marker[1] == "begin":
+				ignoredLine = lno
+			case ignoredLine == 0:
+				return nil, nil, nil, fmt.Errorf("ignore end marker on line %d without a begin marker", lno)
+			default:
+				ignoredLine = 0
+			}
+			continue
+		}
+		if ignoredLine != 0 {
+			continue
+		}
+
+		if marker := reSchemaVersionMarker.FindStringSubmatch(line); marker != nil {
+			if schemaVersion, err = strconv.Atoi(marker[1]); err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid schema version on line %d: %v", lno, err)
+			}
+			continue
+		}
+
+		// review comments are not part of the requirements, they are collected separately
+		lineComments := parseReviewComments(line, lno)
+		if len(lineComments) > 0 {
+			line = reReviewComment.ReplaceAllString(line, "")
+		}
+
 		// check if we've hit an ATX heading or the first row of a requirements table
 		if reATXHeading.MatchString(line) {
 			// it's an ATX heading
 			ATXparts := reATXHeading.FindStringSubmatch(line)
 			level := len(ATXparts[1])
 			title := ATXparts[3]
-			reqIDs := reReqID.FindAllString(title, -1)
+			reqIDs := grammar.ids.Regexp().FindAllString(title, -1)
 			if len(reqIDs) > 1 {
-				return nil, nil, fmt.Errorf("malformed requirement title: too many IDs on line %d: %q", lno, line)
+				return nil, nil, nil, fmt.Errorf("malformed requirement title: too many IDs on line %d: %q", lno, line)
 			}
 			headingHasReqID := len(reqIDs) == 1
 
@@ -103,7 +293,7 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 					// This is a requirement heading.
 					// The level must be the same as the current requirement.
 					if level != reqLevel {
-						return nil, nil, fmt.Errorf("requirement heading on line %d must be at same level as requirement heading on line %d (%d != %d): %q", lno, reqLine, level, reqLevel, line)
+						return nil, nil, nil, fmt.Errorf("requirement heading on line %d must be at same level as requirement heading on line %d (%d != %d): %q", lno, reqLine, level, reqLevel, line)
 					}
 				} else {
 					// No requirement ID on this heading.
@@ -111,7 +301,7 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 					// requirement's heading level. We don't want to mix requirements
 					// with other headings of the same level, in the same section.
 					if level == reqLevel {
-						return nil, nil, fmt.Errorf("non-requirement heading on line %d at same level as requirement heading on line %d (%d): %q", lno, reqLine, level, line)
+						return nil, nil, nil, fmt.Errorf("non-requirement heading on line %d at same level as requirement heading on line %d (%d): %q", lno, reqLine, level, line)
 					}
 				}
 			} else {
@@ -119,16 +309,16 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 				if headingHasReqID {
 					// Can be the first one or the first one in another section.
 					if level == lastHeadingLevel {
-						return nil, nil, fmt.Errorf("requirement heading on line %d at same level as previous heading on line %d (%d): %q", lno, lastHeadingLine, level, line)
+						return nil, nil, nil, fmt.Errorf("requirement heading on line %d at same level as previous heading on line %d (%d): %q", lno, lastHeadingLine, level, line)
 					}
 				}
 			}
 
 			// If we're currently parsing a requirement, and just read the start of a new requirement (cf rules for ending a requirement), close it
 			if (inReq != None) && (headingHasReqID || level < reqLevel) {
-				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow)
+				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow, grammar)
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 				inReq = None
 			}
@@ -149,9 +339,9 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 			// It's a requirements table
 			// If we're currently parsing a requirement close it
 			if inReq != None {
-				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow)
+				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow, grammar)
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 			}
 			// Start a new requirement table
@@ -162,9 +352,9 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 			// It's a data or control flow table
 			// If we're currently parsing a requirement close it
 			if inReq != None {
-				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow)
+				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow, grammar)
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 			}
 			// Start a new flow table
@@ -175,9 +365,9 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 			// It's a data or control flow table
 			// If we're currently parsing a requirement close it
 			if inReq != None {
-				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow)
+				reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow, grammar)
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 			}
 			// Start a new flow table
@@ -186,26 +376,61 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 			reqBuf.Reset()
 		}
 
+		if len(lineComments) > 0 {
+			// Comments belong to the requirement being parsed, or to the row of a requirements table
+			switch inReq {
+			case Heading:
+				reviewComments[reqLine] = append(reviewComments[reqLine], lineComments...)
+			case Table:
+				reviewComments[lno] = append(reviewComments[lno], lineComments...)
+			default:
+				strayComments = append(strayComments, lineComments...)
+			}
+		}
+
 		if inReq != None {
 			reqBuf.WriteString(line)
 			reqBuf.WriteString("\n")
 		}
 	}
 	if err := scan.Err(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+	if ignoredLine != 0 {
+		return nil, nil, nil, fmt.Errorf("section ignored on line %d has no end marker", ignoredLine)
 	}
 
 	if inReq != None {
 		// Close the current requirement, we're at the end.
-		reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow)
+		reqs, flow, err = parseMarkdownFragment(inReq, reqBuf.String(), reqLine, reqs, flow, grammar)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 	}
 
 	for reqIdx := range reqs {
 		reqs[reqIdx].RepoName = repoName
 		reqs[reqIdx].Document = documentConfig
+		reqs[reqIdx].ReviewComments = reviewComments[reqs[reqIdx].Position]
+		reqs[reqIdx].SchemaVersion = schemaVersion
+		delete(reviewComments, reqs[reqIdx].Position)
+	}
+	// Comments in tables which are not on the row of a requirement
+	for _, comments := range reviewComments {
+		strayComments = append(strayComments, comments...)
+	}
+	sort.SliceStable(strayComments, func(i, j int) bool { return strayComments[i].Line < strayComments[j].Line })
+	issues := make([]diagnostics.Issue, 0, len(strayComments))
+	for _, comment := range strayComments {
+		issues = append(issues, diagnostics.Issue{
+			Line:     comment.Line,
+			Path:     documentConfig.Path,
+			RepoName: repoName,
+			Description: fmt.Sprintf("Review comment by %s in document `%s` is not within a requirement: %s",
+				comment.Author, documentConfig.Path, comment.Comment),
+			Severity: diagnostics.IssueSeverityMinor,
+			Type:     diagnostics.IssueTypeOpenReviewComment,
+		})
 	}
 
 	for flowIdx := range flow {
@@ -213,17 +438,32 @@ func ParseMarkdown(repoName repos.RepoName, documentConfig *config.Document) ([]
 		flow[flowIdx].Document = documentConfig
 	}
 
-	return reqs, flow, nil
+	return reqs, flow, issues, nil
+}
+
+// parseReviewComments returns the review comments found in the given line of a document
+// @llr REQ-TRAQ-SWL-103
+func parseReviewComments(line string, lno int) []ReviewComment {
+	var comments []ReviewComment
+	for _, parts := range reReviewComment.FindAllStringSubmatch(line, -1) {
+		comments = append(comments, ReviewComment{
+			Author:   strings.TrimSpace(parts[2]),
+			Comment:  parts[3],
+			Resolved: parts[1] == "RESOLVED",
+			Line:     lno,
+		})
+	}
+	return comments
 }
 
 // parseMarkdownFragment accepts a string containing either an ATX requirement or a requirements table and calls the
 // appropriate parsing function
 // @llr REQ-TRAQ-SWL-3, REQ-TRAQ-SWL-5
-func parseMarkdownFragment(reqType ReqFormatType, txt string, reqLine int, reqs []*Req, flow []*Flow) ([]*Req, []*Flow, error) {
+func parseMarkdownFragment(reqType ReqFormatType, txt string, reqLine int, reqs []*Req, flow []*Flow, grammar idGrammar) ([]*Req, []*Flow, error) {
 
 	if reqType == Heading {
 		// An ATX requirement
-		newReq, err := parseReq(txt)
+		newReq, err := parseReq(txt, grammar)
 		if err != nil {
 			return reqs, flow, err
 		}
@@ -231,7 +471,7 @@ func parseMarkdownFragment(reqType ReqFormatType, txt string, reqLine int, reqs
 		reqs = append(reqs, newReq)
 	} else if reqType == Table {
 		// A requirements table
-		newReqs, err := parseReqTable(txt, reqLine, reqs)
+		newReqs, err := parseReqTable(txt, reqLine, reqs, grammar)
 		if err != nil {
 			return reqs, flow, err
 		}
@@ -268,10 +508,10 @@ func parseMarkdownFragment(reqType ReqFormatType, txt string, reqLine int, reqs
 // Since the parsing is rather 'soft', ParseReq returns verbose errors indicating problems in
 // a helpful way, meaning they at least provide enough context for the user to find the text.
 //
-// @llr REQ-TRAQ-SWL-3
-func parseReq(txt string) (*Req, error) {
+// @llr REQ-TRAQ-SWL-3, REQ-TRAQ-SWL-126, REQ-TRAQ-SWL-143
+func parseReq(txt string, grammar idGrammar) (*Req, error) {
 
-	ID, Variant, IDNumber, err := extractIDParts(txt)
+	ID, Variant, IDNumber, err := extractIDParts(txt, grammar.ids)
 	if err != nil {
 		return nil, err
 	}
@@ -304,10 +544,22 @@ func parseReq(txt string) (*Req, error) {
 	// Next is the body, until the attributes section.
 	bodyAndAttributes := parts[1]
 	var attributesStart = len(bodyAndAttributes)
+	var trailingSections string
 	ii := reAttributesSectionHeading.FindStringIndex(bodyAndAttributes)
 	if ii != nil {
 		attributesStart = ii[0]
 		attributes := bodyAndAttributes[attributesStart:]
+		// The attributes section, which starts with an empty line and its heading, ends at the next section. The
+		// sections following it belong to the body, as they do once reqtraq fmt moved the attributes section last.
+		lines := strings.SplitAfter(attributes, "\n")
+		for i, offset := 2, len(lines[0])+len(lines[1]); i < len(lines); offset, i = offset+len(lines[i]), i+1 {
+			if reATXHeading.MatchString(strings.TrimRight(lines[i], "\n")) {
+				r.SectionsAfterAttributes = true
+				trailingSections = strings.TrimRight(attributes[offset:], "\n") + "\n"
+				attributes = attributes[:offset]
+				break
+			}
+		}
 		kwdMatches := reReqKWD.FindAllStringSubmatchIndex(attributes, -1)
 		if len(kwdMatches) == 0 {
 			return nil, fmt.Errorf("Requirement %s contains an attribute section but no attributes", r.ID)
@@ -325,17 +577,26 @@ func parseReq(txt string) (*Req, error) {
 				return nil, fmt.Errorf("requirement %s contains duplicate attribute: %q", r.ID, key)
 			}
 			r.Attributes[key] = strings.TrimSpace(attributes[v[1]:e])
+			r.AttributeKeys = append(r.AttributeKeys, key)
 		}
 	}
 
 	r.Body = bodyAndAttributes[:attributesStart]
+	if trailingSections != "" {
+		r.Body = strings.TrimRight(r.Body, "\n") + "\n\n" + trailingSections
+	}
 
 	if strings.TrimSpace(r.Body) == "" {
 		return nil, fmt.Errorf("Requirement body must not be empty: %s", r.ID)
 	}
 
+	r.AcceptanceCriteria, err = parseAcceptanceCriteria(r.ID, r.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	// PARENTS must be punctuation/space separated list of parseable req-ids.
-	err = parseParents(r)
+	err = parseParents(r, grammar.parents)
 	if err != nil {
 		return nil, err
 	}
@@ -353,10 +614,11 @@ func parseReq(txt string) (*Req, error) {
 //
 // The first column must be "ID" and each row must contain a valid ReqID. Other columns are optional.
 //
-// @llr REQ-TRAQ-SWL-5
-func parseReqTable(txt string, reqLine int, reqs []*Req) ([]*Req, error) {
+// @llr REQ-TRAQ-SWL-5, REQ-TRAQ-SWL-129
+func parseReqTable(txt string, reqLine int, reqs []*Req, grammar idGrammar) ([]*Req, error) {
 
 	var attributes []string
+	parentsColumn := -1
 
 	// Split the table into rows and loop through
 	for index, row := range strings.Split(txt, "\n") {
@@ -372,6 +634,9 @@ func parseReqTable(txt string, reqLine int, reqs []*Req) ([]*Req, error) {
 						// make our lives easier, accept both, output only PARENTS
 						k = "PARENTS"
 					}
+					if k == "PARENTS" {
+						parentsColumn = i
+					}
 
 					attributes[i] = k
 				}
@@ -400,7 +665,7 @@ func parseReqTable(txt string, reqLine int, reqs []*Req) ([]*Req, error) {
 			// For each attribute in the first row, read in the associated value on this row
 			for i, k := range attributes {
 				if k == "ID" {
-					ID, Variant, IDNumber, err := extractIDParts(values[i])
+					ID, Variant, IDNumber, err := extractIDParts(values[i], grammar.ids)
 					if err != nil {
 						return reqs, err
 					}
@@ -416,9 +681,13 @@ func parseReqTable(txt string, reqLine int, reqs []*Req) ([]*Req, error) {
 				}
 			}
 
-			err := parseParents(r)
-			if err != nil {
-				return reqs, err
+			if err := parseParents(r, grammar.parents); err != nil {
+				return reqs, tableCellError(index, parentsColumn, attributes, err)
+			}
+			for _, parentID := range r.ParentIds {
+				if err := grammar.checkParentLink(r, parentID); err != nil {
+					return reqs, tableCellError(index, parentsColumn, attributes, err)
+				}
 			}
 
 			r.Position = index + reqLine
@@ -429,6 +698,15 @@ func parseReqTable(txt string, reqLine int, reqs []*Req) ([]*Req, error) {
 	return reqs, nil
 }
 
+// tableCellError locates the error in the given cell of a requirements table, by row and column number
+// @llr REQ-TRAQ-SWL-129
+func tableCellError(index int, column int, attributes []string, err error) error {
+	if column < 0 {
+		return fmt.Errorf("row %d of requirement table: %v", index+1, err)
+	}
+	return fmt.Errorf("row %d, column %d (%s) of requirement table: %v", index+1, column+1, attributes[column], err)
+}
+
 // parseFlowTable reads a table of data/control flow one row at a time and parses the content into Flow structures which are
 // then returned in a slice.
 //
@@ -437,9 +715,11 @@ func parseReqTable(txt string, reqLine int, reqs []*Req) ([]*Req, error) {
 // | --- | --- | --- | --- | --- |
 // | <text> | <flow tag> | <text> | <text> | <text> |
 //
-// Direction column should be present for data flow only.
+// Direction column should be present for data flow only. The columns following the description are stored as
+// attributes of the flow tags, to be checked against the schema of the document, except the optional Diagram column
+// which references a diagram file.
 //
-// @llr REQ-TRAQ-SWL-83
+// @llr REQ-TRAQ-SWL-83, REQ-TRAQ-SWL-114, REQ-TRAQ-SWL-185
 func parseFlowTable(txt string, reqLine int, flow []*Flow, reqType ReqFormatType) ([]*Flow, error) {
 	var attributes []string
 
@@ -509,6 +789,13 @@ func parseFlowTable(txt string, reqLine int, flow []*Flow, reqType ReqFormatType
 					f.Description = values[i]
 				} else if k == "DIRECTION" {
 					f.Direction = values[i]
+				} else if k == "DIAGRAM" {
+					f.Diagram = values[i]
+				} else {
+					if f.Attributes == nil {
+						f.Attributes = make(map[string]string)
+					}
+					f.Attributes[k] = values[i]
 				}
 
 			}
@@ -546,50 +833,60 @@ func splitTableLine(line string) []string {
 	return parts
 }
 
-// extractIDParts parses a requirement identifier string and returns the ID string, variant and sequence number
-// @llr REQ-TRAQ-SWL-3, REQ-TRAQ-SWL-5
-func extractIDParts(reqStr string) (string, ReqVariant, int, error) {
+// extractIDParts parses a requirement identifier string of the given format and returns the ID string,
+// variant and sequence number
+// @llr REQ-TRAQ-SWL-3, REQ-TRAQ-SWL-5, REQ-TRAQ-SWL-93
+func extractIDParts(reqStr string, idFormat *config.IDFormat) (string, ReqVariant, int, error) {
 	var variant ReqVariant
 
 	head := reqStr
 	if len(head) > 40 {
 		head = head[:40]
 	}
-	defid := reReqID.FindStringSubmatchIndex(reqStr)
-	if len(defid) == 0 {
-		if reReqIDBad.MatchString(reqStr) {
+	parts, found := idFormat.Find(reqStr)
+	if !found {
+		if idFormat == config.DefaultIDFormat && reReqIDBad.MatchString(reqStr) {
 			return "", variant, 0, fmt.Errorf("malformed requirement: found only malformed ID: %q (doesn't match %q)", head, reReqID)
 		}
 		return "", variant, 0, fmt.Errorf("malformed requirement: missing ID in first 40 characters: %q", head)
 	}
 
-	if defid[0] > 0 {
+	if parts.Offset > 0 {
 		return "", variant, 0, fmt.Errorf("malformed requirement: ID must be at the start of the title: %q", head)
 	}
 
-	IDNumber, err := strconv.Atoi(reqStr[defid[8]:defid[9]])
+	IDNumber, err := strconv.Atoi(parts.Number)
 	if err != nil {
 		return "", variant, 0, err
 
 	}
 
-	switch reqStr[defid[2]:defid[3]] {
+	switch parts.Variant {
 	case "REQ":
 		variant = ReqVariantRequirement
 	case "ASM":
 		variant = ReqVariantAssumption
 	default:
-		return "", variant, 0, fmt.Errorf("Unknown requirement variant %q", reqStr[defid[2]:defid[3]])
+		return "", variant, 0, fmt.Errorf("Unknown requirement variant %q", parts.Variant)
 	}
-	return reqStr[defid[0]:defid[1]], variant, IDNumber, nil
+	return parts.ID, variant, IDNumber, nil
 }
 
-// parseParents splits the Parents attribute of a requirement into a slice of requirement identifiers and assigns to ParentIds
-// @llr REQ-TRAQ-SWL-3, REQ-TRAQ-SWL-5
-func parseParents(r *Req) error {
+// parseParents splits the Parents attribute of a requirement into a slice of requirement identifiers matching
+// the given expression and assigns to ParentIds. Identifiers may be linked to their definition. A `-` value
+// marks a derived requirement.
+// @llr REQ-TRAQ-SWL-3, REQ-TRAQ-SWL-5, REQ-TRAQ-SWL-93, REQ-TRAQ-SWL-102, REQ-TRAQ-SWL-129
+func parseParents(r *Req, reParentID *regexp.Regexp) error {
 	// PARENTS must be punctuation/space separated list of parseable req-ids.
-	parents := r.Attributes["PARENTS"]
-	parmatch := reReqID.FindAllStringSubmatchIndex(parents, -1)
+	parents := stripMarkdownLinks(r.Attributes["PARENTS"])
+	if strings.TrimSpace(parents) == DerivedParents {
+		// The requirement intentionally has no parents, it must be justified like any requirement without parents
+		delete(r.Attributes, "PARENTS")
+		r.Derived = true
+		r.ParentIds = nil
+		return nil
+	}
+	parmatch := reParentID.FindAllStringSubmatchIndex(parents, -1)
 
 	var parentIDs []string
 
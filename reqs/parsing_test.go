@@ -7,9 +7,12 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/stretchr/testify/assert"
 )
@@ -174,9 +177,136 @@ Assumption body
 	)
 }
 
+// TestParseMarkdown_ReviewComments checks that review comments are collected for the enclosing
+// requirement and are not part of its text.
+// @llr REQ-TRAQ-SWL-103
+func TestParseMarkdown_ReviewComments(t *testing.T) {
+	checkParseOk(t, `
+# Title
+#### REQ-TEST-SYS-5 My First Requirement <!-- REVIEW(alice): Title too vague -->
+Body of the requirement
+<!-- RESOLVED(bob): Typo -->
+| ID | Title | Body |
+| REQ-TEST-SYS-6 | Second | Table body | <!-- REVIEW( carol ): Split in two -->
+`,
+		[]*Flow{},
+		[]*Req{
+			&Req{ID: "REQ-TEST-SYS-5",
+				Variant:    ReqVariantRequirement,
+				IDNumber:   5,
+				Title:      "My First Requirement",
+				Body:       "Body of the requirement",
+				Position:   3,
+				Attributes: map[string]string{},
+				ReviewComments: []ReviewComment{
+					{Author: "alice", Comment: "Title too vague", Line: 3},
+					{Author: "bob", Comment: "Typo", Resolved: true, Line: 5},
+				}},
+			&Req{ID: "REQ-TEST-SYS-6",
+				Variant:    ReqVariantRequirement,
+				IDNumber:   6,
+				Title:      "Second",
+				Body:       "Table body",
+				Position:   7,
+				Attributes: map[string]string{},
+				ReviewComments: []ReviewComment{
+					{Author: "carol", Comment: "Split in two", Line: 7},
+				}},
+		})
+
+	// Review comments outside requirements are reported as warnings
+	doc := config.Document{Path: "path/to/doc.md"}
+	requirements, _, issues, err := parseMarkdownIssues("repo", &doc, strings.NewReader(`
+# Title <!-- REVIEW(alice): Missing introduction -->
+#### REQ-TEST-SYS-5 My First Requirement
+Body of the requirement
+| ID | Title | Body |
+| --- | --- | --- |
+| REQ-TEST-SYS-6 | Second | Table body |
+
+Remarks <!-- REVIEW(bob): Remove -->
+`))
+	assert.NoError(t, err)
+	assert.Len(t, requirements, 2)
+	assert.Equal(t, []diagnostics.Issue{
+		{
+			Line:        2,
+			Path:        "path/to/doc.md",
+			RepoName:    "repo",
+			Description: "Review comment by alice in document `path/to/doc.md` is not within a requirement: Missing introduction",
+			Severity:    diagnostics.IssueSeverityMinor,
+			Type:        diagnostics.IssueTypeOpenReviewComment,
+		},
+		{
+			Line:        9,
+			Path:        "path/to/doc.md",
+			RepoName:    "repo",
+			Description: "Review comment by bob in document `path/to/doc.md` is not within a requirement: Remove",
+			Severity:    diagnostics.IssueSeverityMinor,
+			Type:        diagnostics.IssueTypeOpenReviewComment,
+		},
+	}, issues)
+}
+
+// TestParseMarkdown_IgnoredSections checks that the sections between ignore markers are skipped, and that the
+// markers must be balanced.
+// @llr REQ-TRAQ-SWL-155
+func TestParseMarkdown_IgnoredSections(t *testing.T) {
+	checkParseOk(t, `
+# Title
+#### REQ-TEST-SYS-5 My First Requirement
+Body of the requirement
+<!-- reqtraq:ignore-begin -->
+For example, REQ-TEST-SYS-1 would be written as:
+#### REQ-TEST-SYS-1 Example
+<!-- REVIEW(alice): Not a requirement -->
+<!-- reqtraq:ignore-end -->
+End of the body
+
+## Appendix
+<!-- reqtraq:ignore-begin -->
+| ID | Title | Body |
+| --- | --- | --- |
+| REQ-TEST-SYS-2 | Example | Refers to REQ-TEST-SYS-3 |
+
+| Caller | Flow Tag | Callee | Description |
+| --- | --- | --- | --- |
+| A | CF-EXAMPLE-1 | B | Example |
+<!-- reqtraq:ignore-end -->
+`,
+		[]*Flow{},
+		[]*Req{
+			&Req{ID: "REQ-TEST-SYS-5",
+				Variant:    ReqVariantRequirement,
+				IDNumber:   5,
+				Title:      "My First Requirement",
+				Body:       "Body of the requirement\nEnd of the body",
+				Position:   3,
+				Attributes: map[string]string{}},
+		})
+
+	checkParseError(t, `
+# Title
+<!-- reqtraq:ignore-begin -->
+#### REQ-TEST-SYS-1 Example
+`, "section ignored on line 3 has no end marker")
+
+	checkParseError(t, `
+# Title
+<!-- reqtraq:ignore-end -->
+`, "ignore end marker on line 3 without a begin marker")
+
+	checkParseError(t, `
+# Title
+<!-- reqtraq:ignore-begin -->
+<!-- reqtraq:ignore-begin -->
+<!-- reqtraq:ignore-end -->
+`, "ignore marker on line 4 is within the section ignored on line 3")
+}
+
 // TestParseMarkdown checks that parseMarkdown parse data/control flow tabless
 // correctly.
-// @llr REQ-TRAQ-SWL-83, REQ-TRAQ-SWL-84
+// @llr REQ-TRAQ-SWL-83, REQ-TRAQ-SWL-84, REQ-TRAQ-SWL-114
 func TestParseDataControlFlow(t *testing.T) {
 	// Data/control flow
 	checkParseOk(t, `
@@ -249,13 +379,47 @@ Body
 		},
 		[]*Req{
 			&Req{ID: "REQ-TEST-SYS-5",
-				Variant:    ReqVariantRequirement,
-				IDNumber:   5,
-				Title:      "My First Requirement",
-				Body:       "Body",
-				Position:   14,
-				Attributes: map[string]string{"FLOW": "DF-FLT-2"}},
+				Variant:       ReqVariantRequirement,
+				IDNumber:      5,
+				Title:         "My First Requirement",
+				Body:          "Body",
+				Position:      14,
+				Attributes:    map[string]string{"FLOW": "DF-FLT-2"},
+				AttributeKeys: []string{"FLOW"}},
+		},
+	)
+
+	// Additional columns
+	checkParseOk(t, `
+# Title
+| Caller | Flow Tag | Callee | Direction | Description | Protocol | Rate |
+| --- | --- | --- | --- | --- | --- | --- |
+| Caller Name | DF-FLT-1 | Callee Name | In | Flow description | CAN | 10 Hz |
+| Caller Name | DF-FLT-2 | Callee Name | Out | Flow description | UART | |
+`,
+		[]*Flow{
+			&Flow{
+				ID:          "DF-FLT-1",
+				Callee:      "Callee Name",
+				Caller:      "Caller Name",
+				Description: "Flow description",
+				Position:    5,
+				RepoName:    ".",
+				Direction:   "In",
+				Attributes:  map[string]string{"PROTOCOL": "CAN", "RATE": "10 Hz"},
+			},
+			&Flow{
+				ID:          "DF-FLT-2",
+				Callee:      "Callee Name",
+				Caller:      "Caller Name",
+				Description: "Flow description",
+				Position:    6,
+				RepoName:    ".",
+				Direction:   "Out",
+				Attributes:  map[string]string{"PROTOCOL": "UART", "RATE": ""},
+			},
 		},
+		[]*Req{},
 	)
 
 	checkParseError(t, `
@@ -380,7 +544,7 @@ body
 - Rationale: This is why.
 - Parents: REQ-TEST-SYS-1
 - Attribute which will never exist: exists
-`)
+`, defaultIDGrammar)
 	assert.Nil(t, err)
 	assert.Equal(t, "REQ-TEST-SWL-1", r.ID)
 	assert.Equal(t, "title", r.Title)
@@ -394,7 +558,7 @@ body
 func TestParseReq_Empty(t *testing.T) {
 	_, err := parseReq(`REQ-TEST-SWL-1 title
 
-`)
+`, defaultIDGrammar)
 	assert.NotNil(t, err)
 	assert.EqualError(t, err, "Requirement must not be empty: REQ-TEST-SWL-1")
 }
@@ -402,7 +566,7 @@ func TestParseReq_Empty(t *testing.T) {
 // @llr REQ-TRAQ-SWL-3
 func TestParseReq_Deleted(t *testing.T) {
 	// Make sure it can be parsed even when it has no description.
-	r, err := parseReq(`REQ-T-SYS-1 DELETED`)
+	r, err := parseReq(`REQ-T-SYS-1 DELETED`, defaultIDGrammar)
 	assert.Nil(t, err)
 	assert.True(t, r.IsDeleted())
 
@@ -413,7 +577,7 @@ body
 ###### Attributes:
 - Rationale: This is why.
 - Parents: REQ-TEST-SYS-1
-`)
+`, defaultIDGrammar)
 	assert.Nil(t, err)
 	assert.Equal(t, "REQ-TEST-SWL-1", r.ID)
 	assert.Equal(t, "DELETED Some title", r.Title)
@@ -429,7 +593,7 @@ func TestParseReq_EmptyBody(t *testing.T) {
 
 ## Attributes:
 - A: B
-`)
+`, defaultIDGrammar)
 	assert.NotNil(t, err)
 	assert.EqualError(t, err, "Requirement body must not be empty: REQ-TEST-SWL-1")
 }
@@ -440,7 +604,7 @@ func TestParseReq_FlexibleAttributesHeading(t *testing.T) {
 body
 ## Attributes:
 - Rationale: This is why.
-`)
+`, defaultIDGrammar)
 	assert.Nil(t, err)
 	assert.Equal(t, "This is why.", r.Attributes["RATIONALE"])
 }
@@ -448,7 +612,7 @@ body
 // @llr REQ-TRAQ-SWL-3
 func TestParseReq_NoAttributes(t *testing.T) {
 	r, err := parseReq(`REQ-TEST-SWL-1 title
-body`)
+body`, defaultIDGrammar)
 	assert.Nil(t, err)
 	assert.Equal(t, "body", r.Body)
 }
@@ -458,7 +622,7 @@ func TestParseReq_EmptyAttributesSection(t *testing.T) {
 	_, err := parseReq(`REQ-TEST-SWL-1 title
 body
 ###### Attributes:
-`)
+`, defaultIDGrammar)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "Requirement REQ-TEST-SWL-1 contains an attribute section but no attributes")
 }
@@ -470,7 +634,7 @@ body
 ## Attributes:
 - Rationale: This is why.
 - Rationale: This is why.
-`)
+`, defaultIDGrammar)
 	assert.EqualError(t, err, `requirement REQ-TEST-SWL-1 contains duplicate attribute: "RATIONALE"`)
 }
 
@@ -480,7 +644,7 @@ func TestParseReq_Parents(t *testing.T) {
 body
 ## Attributes:
 - Parent: REQ-T-SWH-1, REQ-T-SWH-1000 REQ-T-SWH-1001
-`)
+`, defaultIDGrammar)
 	assert.Nil(t, err)
 	assert.Equal(t, []string{"REQ-T-SWH-1", "REQ-T-SWH-1000", "REQ-T-SWH-1001"}, r.ParentIds)
 }
@@ -491,7 +655,7 @@ func TestParseReq_InvalidParents(t *testing.T) {
 body
 ## Attributes:
 - Parents: REQ-TEST-SWH-1 and REQ-TEST-SWH-2
-`)
+`, defaultIDGrammar)
 	assert.EqualError(t, err, `requirement REQ-TEST-SWL-1 parents: unparseable as list of requirement ids: " and " in "REQ-TEST-SWH-1 and REQ-TEST-SWH-2"`)
 }
 
@@ -501,7 +665,7 @@ func TestParseReq_InvalidParents2(t *testing.T) {
 body
 ## Attributes:
 - Parents: TODO
-`)
+`, defaultIDGrammar)
 	assert.EqualError(t, err, `requirement REQ-TEST-SWL-1 parents: unparseable as list of requirement ids: "TODO"`)
 }
 
@@ -511,7 +675,7 @@ func TestParseReq_InvalidParents3(t *testing.T) {
 body
 ## Attributes:
 - Parents: REQ-VXS-SYS-123, TODO
-`)
+`, defaultIDGrammar)
 	assert.EqualError(t, err, `requirement REQ-TEST-SWL-1 parents: unparseable as list of requirement ids: ", TODO" in "REQ-VXS-SYS-123, TODO"`)
 }
 
@@ -521,7 +685,7 @@ func TestParseReq_InvalidParents4(t *testing.T) {
 body
 ## Attributes:
 - Parents: REQ-VXS-SYS-123, REQ-VXS-456
-`)
+`, defaultIDGrammar)
 	assert.EqualError(t, err, `requirement REQ-TEST-SWL-1 parents: unparseable as list of requirement ids: ", REQ-VXS-456" in "REQ-VXS-SYS-123, REQ-VXS-456"`)
 }
 
@@ -533,7 +697,7 @@ func TestParseReqTable(t *testing.T) {
 | REQ-TEST-SYS-1 | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 | |
 | REQ-TEST-SYS-2 | Section 2 | Body of requirement 2. | Rationale 2 | Test 2 | Impact 2 | |
 | REQ-TEST-SYS-3 | Section 3 | Body of requirement 3. | Rationale 3 | Test 3 | Impact 3 | REQ-TEST-SYS-1 |
-| REQ-TEST-SYS-4 | Section 4 | Body of requirement 4. | Rationale 4 | Test 4 | Impact 4 | REQ-TEST-SYS-1, REQ-TEST-SYS-2 |`, tableOffset, nil)
+| REQ-TEST-SYS-4 | Section 4 | Body of requirement 4. | Rationale 4 | Test 4 | Impact 4 | REQ-TEST-SYS-1, REQ-TEST-SYS-2 |`, tableOffset, nil, defaultIDGrammar)
 
 	assert.Nil(t, err)
 	assert.Equal(t, 4, len(reqs))
@@ -553,7 +717,7 @@ func TestParseReqTable(t *testing.T) {
 func TestParseReqTable_NoIDCol(t *testing.T) {
 	_, err := parseReqTable(`| Title | Body | Rationale | Verification | Safety impact |
 | ----- | ----- | ----- | ----- | ----- |
-| Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 |`, 0, nil)
+| Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 |`, 0, nil, defaultIDGrammar)
 
 	assert.EqualError(t, err, "requirement table must have at least 2 columns, first column head must be \"ID\"")
 }
@@ -562,7 +726,7 @@ func TestParseReqTable_NoIDCol(t *testing.T) {
 func TestParseReqTable_OneCol(t *testing.T) {
 	_, err := parseReqTable(`| ID |
 | ----- |
-| REQ-TEST-SYS-1 |`, 0, nil)
+| REQ-TEST-SYS-1 |`, 0, nil, defaultIDGrammar)
 
 	assert.EqualError(t, err, "requirement table must have at least 2 columns, first column head must be \"ID\"")
 }
@@ -571,7 +735,7 @@ func TestParseReqTable_OneCol(t *testing.T) {
 func TestParseReqTable_MissingCell(t *testing.T) {
 	_, err := parseReqTable(`| ID | Title | Body | Rationale | Verification | Safety impact |
 | ----- | ----- | ----- | ----- | ----- | ----- |
-| REQ-TEST-SYS-1 | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 |`, 0, nil)
+| REQ-TEST-SYS-1 | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 |`, 0, nil, defaultIDGrammar)
 
 	assert.EqualError(t, err, "too few cells on row 3 of requirement table")
 }
@@ -580,7 +744,7 @@ func TestParseReqTable_MissingCell(t *testing.T) {
 func TestParseReqTable_BadID(t *testing.T) {
 	_, err := parseReqTable(`| ID | Title | Body | Rationale | Verification | Safety impact |
 | ----- | ----- | ----- | ----- | ----- | ----- |
-| REQ-TEST-1 | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 |`, 0, nil)
+| REQ-TEST-1 | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 |`, 0, nil, defaultIDGrammar)
 
 	assert.EqualError(t, err, "malformed requirement: found only malformed ID: \"REQ-TEST-1\" (doesn't match \"(REQ|ASM)-(\\\\w+)-(\\\\w+)-(\\\\d+)\")")
 }
@@ -589,7 +753,40 @@ func TestParseReqTable_BadID(t *testing.T) {
 func TestParseReqTable_MissingID(t *testing.T) {
 	_, err := parseReqTable(`| ID | Title | Body | Rationale | Verification | Safety impact |
 | ----- | ----- | ----- | ----- | ----- | ----- |
-|  | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 |`, 0, nil)
+|  | Section 1 | Body of requirement 1. | Rationale 1 | Test 1 | Impact 1 |`, 0, nil, defaultIDGrammar)
 
 	assert.EqualError(t, err, "malformed requirement: missing ID in first 40 characters: \"\"")
 }
+
+// @llr REQ-TRAQ-SWL-129
+func TestParseReqTable_ParentsCell(t *testing.T) {
+	grammar := defaultIDGrammar
+	grammar.links = []config.LinkSpec{{
+		Child:  config.ReqSpec{Re: regexp.MustCompile(`REQ-TEST-SWH-(\d+)`)},
+		Parent: config.ReqSpec{Re: regexp.MustCompile(`REQ-TEST-SYS-(\d+)`)},
+	}}
+
+	reqs, err := parseReqTable(`| ID | Title | Body | Parents | Rationale |
+| ----- | ----- | ----- | ----- | ----- |
+| REQ-TEST-SWH-1 | Section 1 | Body of requirement 1. | REQ-TEST-SYS-1 | |
+| REQ-TEST-SWH-2 | Section 2 | Body of requirement 2. | - | Needed by the design |`, 0, nil, grammar)
+	assert.NoError(t, err)
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, []string{"REQ-TEST-SYS-1"}, reqs[0].ParentIds)
+		assert.False(t, reqs[0].Derived)
+		assert.Empty(t, reqs[1].ParentIds)
+		assert.True(t, reqs[1].Derived)
+		assert.NotContains(t, reqs[1].Attributes, "PARENTS")
+	}
+
+	_, err = parseReqTable(`| ID | Title | Body | Parents |
+| ----- | ----- | ----- | ----- |
+| REQ-TEST-SWH-1 | Section 1 | Body of requirement 1. | REQ-TEST-SYS-1 |
+| REQ-TEST-SWH-2 | Section 2 | Body of requirement 2. | REQ-TEST-SWH-1 |`, 0, nil, grammar)
+	assert.EqualError(t, err, `row 4, column 4 (PARENTS) of requirement table: REQ-TEST-SWH-1 is not a valid parent of REQ-TEST-SWH-2, expected an ID matching REQ-TEST-SYS-(\d+)`)
+
+	_, err = parseReqTable(`| ID | Title | Body | Parents |
+| ----- | ----- | ----- | ----- |
+| REQ-TEST-SWH-1 | Section 1 | Body of requirement 1. | REQ-TEST-SYS-1; and more |`, 0, nil, grammar)
+	assert.EqualError(t, err, `row 3, column 4 (PARENTS) of requirement table: requirement REQ-TEST-SWH-1 parents: unparseable as list of requirement ids: "; and more" in "REQ-TEST-SYS-1; and more"`)
+}
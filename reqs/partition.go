@@ -0,0 +1,148 @@
+package reqs
+
+import (
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// Partition is the part of a requirements graph holding the requirements of one document
+type Partition struct {
+	RepoName repos.RepoName
+	Document *config.Document
+	Graph    *ReqGraph
+}
+
+// stubOf returns a stub of the requirement, which stands for it in the partitions of the documents of its children
+// @llr REQ-TRAQ-SWL-136
+func stubOf(r *Req) *Req {
+	return &Req{
+		ID:       r.ID,
+		Variant:  r.Variant,
+		IDNumber: r.IDNumber,
+		Title:    r.Title,
+		Position: r.Position,
+		Document: r.Document,
+		RepoName: r.RepoName,
+		Stub:     true,
+	}
+}
+
+// PartitionByDocument splits the graph in one graph per document, ordered by repository and document path. Each
+// one holds the requirements, the flow tags and the code of the document, with stubs for the parents defined in
+// other documents, and the issues reported in the document or in its code. Every requirement, code tag and issue is
+// part of exactly one partition, so merging all the partitions with LoadGraphs results in the complete graph.
+// @llr REQ-TRAQ-SWL-136
+func (rg *ReqGraph) PartitionByDocument() []Partition {
+	type docKey struct {
+		repoName repos.RepoName
+		path     string
+	}
+	partitions := make(map[docKey]*Partition)
+	partitionOf := func(repoName repos.RepoName, doc *config.Document) *Partition {
+		key := docKey{repoName, ""}
+		if doc != nil {
+			key.path = doc.Path
+		}
+		if p, ok := partitions[key]; ok {
+			return p
+		}
+		p := &Partition{RepoName: repoName, Document: doc, Graph: &ReqGraph{
+			Reqs:          make(map[string]*Req),
+			CodeTags:      make(map[repos.RepoName][]*code.Code),
+			FlowTags:      make(map[string]*Flow),
+			Issues:        make([]diagnostics.Issue, 0),
+			ReqtraqConfig: rg.ReqtraqConfig,
+		}}
+		partitions[key] = p
+		return p
+	}
+	if rg.ReqtraqConfig != nil {
+		for repoName, repo := range rg.ReqtraqConfig.Repos {
+			for i := range repo.Documents {
+				partitionOf(repoName, &repo.Documents[i])
+			}
+		}
+	}
+
+	for id, r := range rg.Reqs {
+		partitionOf(r.RepoName, r.Document).Graph.Reqs[id] = r
+	}
+	// The parents defined in other documents are added as stubs, once all the requirements are in their partition
+	for _, p := range partitions {
+		for _, r := range p.Graph.Reqs {
+			if r.Stub {
+				continue
+			}
+			for _, parentID := range r.ParentIds {
+				if parent, ok := rg.Reqs[parentID]; ok {
+					if _, inPartition := p.Graph.Reqs[parentID]; !inPartition {
+						p.Graph.Reqs[parentID] = stubOf(parent)
+					}
+				}
+			}
+		}
+	}
+
+	for repoName, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			var p *Partition
+			if tag.Document != nil {
+				p = partitionOf(repoName, tag.Document)
+			} else if len(tag.Links) > 0 && rg.Reqs[tag.Links[0].Id] != nil {
+				linked := rg.Reqs[tag.Links[0].Id]
+				p = partitionOf(linked.RepoName, linked.Document)
+			} else {
+				p = partitionOf(repoName, nil)
+			}
+			p.Graph.CodeTags[repoName] = append(p.Graph.CodeTags[repoName], tag)
+		}
+	}
+
+	for id, flow := range rg.FlowTags {
+		partitionOf(flow.RepoName, flow.Document).Graph.FlowTags[id] = flow
+	}
+
+	// Issues reported at a requirement or at code linked to requirements belong to the partition of the first one,
+	// the others to the partition of the document they were found in
+	index := rg.IssueRequirements()
+	files := make(map[IssueLocation]*Partition)
+	for _, p := range partitions {
+		if p.Document == nil {
+			continue
+		}
+		for _, file := range p.Document.Files() {
+			files[IssueLocation{RepoName: p.RepoName, Path: file}] = p
+		}
+	}
+	for _, issue := range rg.Issues {
+		var p *Partition
+		if affected := index[LocationOf(issue)]; len(affected) > 0 {
+			p = partitionOf(affected[0].RepoName, affected[0].Document)
+		} else if docPartition, ok := files[IssueLocation{RepoName: issue.RepoName, Path: issue.Path}]; ok {
+			p = docPartition
+		} else {
+			p = partitionOf(issue.RepoName, nil)
+		}
+		p.Graph.Issues = append(p.Graph.Issues, issue)
+	}
+
+	result := make([]Partition, 0, len(partitions))
+	keys := make([]docKey, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].repoName != keys[j].repoName {
+			return keys[i].repoName < keys[j].repoName
+		}
+		return keys[i].path < keys[j].path
+	})
+	for _, key := range keys {
+		result = append(result, *partitions[key])
+	}
+	return result
+}
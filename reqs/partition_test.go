@@ -0,0 +1,117 @@
+package reqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-136
+func TestReqGraph_PartitionByDocument(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	newReq := func(id string, doc *config.Document, position int, parents ...string) *Req {
+		return &Req{ID: id, Title: id, Document: doc, RepoName: "repo", Position: position, ParentIds: parents}
+	}
+
+	tag := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Tag: "f", Line: 1, Document: &sdd,
+		Links: []code.ReqLink{{Id: "REQ-TEST-SWL-1"}}}
+
+	rg := &ReqGraph{
+		Reqs: map[string]*Req{
+			"REQ-TEST-SWH-1": newReq("REQ-TEST-SWH-1", &srd, 1),
+			"REQ-TEST-SWH-2": newReq("REQ-TEST-SWH-2", &srd, 2, "REQ-TEST-SWH-1"),
+			"REQ-TEST-SWL-1": newReq("REQ-TEST-SWL-1", &sdd, 1, "REQ-TEST-SWH-1", "REQ-TEST-SWH-2"),
+		},
+		CodeTags: map[repos.RepoName][]*code.Code{"repo": {tag}},
+		FlowTags: map[string]*Flow{},
+		Issues: []diagnostics.Issue{
+			{RepoName: "repo", Path: "TEST-137-SRD.md", Line: 2, Description: "SWH-2"},
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 10, Description: "SDD"},
+			{RepoName: "repo", Path: "a.go", Line: 1, Description: "code"},
+			{RepoName: "repo", Path: "b.go", Line: 1, Description: "unknown"},
+		},
+		ReqtraqConfig: &config.Config{TargetRepo: "repo", Repos: map[repos.RepoName]config.RepoConfig{
+			"repo": {Documents: []config.Document{srd, sdd}},
+		}},
+	}
+	rg.PrepareForUsage()
+	rg.Reqs["REQ-TEST-SWL-1"].Tags = []*code.Code{tag}
+
+	partitions := rg.PartitionByDocument()
+	if !assert.Len(t, partitions, 3) {
+		return
+	}
+	reqIDs := func(g *ReqGraph) []string {
+		ids := []string{}
+		for id := range g.Reqs {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	descriptions := func(g *ReqGraph) []string {
+		result := []string{}
+		for _, issue := range g.Issues {
+			result = append(result, issue.Description)
+		}
+		return result
+	}
+
+	// The code and issues belonging to no document come first
+	assert.Nil(t, partitions[0].Document)
+	assert.Empty(t, partitions[0].Graph.Reqs)
+	assert.Equal(t, []string{"unknown"}, descriptions(partitions[0].Graph))
+
+	assert.Equal(t, "TEST-137-SRD.md", partitions[1].Document.Path)
+	assert.Equal(t, []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2"}, reqIDs(partitions[1].Graph))
+	assert.Empty(t, partitions[1].Graph.CodeTags)
+	assert.Equal(t, []string{"SWH-2"}, descriptions(partitions[1].Graph))
+
+	// The parents from the other document are stubs
+	assert.Equal(t, "TEST-138-SDD.md", partitions[2].Document.Path)
+	assert.Equal(t, []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2", "REQ-TEST-SWL-1"}, reqIDs(partitions[2].Graph))
+	assert.True(t, partitions[2].Graph.Reqs["REQ-TEST-SWH-1"].Stub)
+	assert.Empty(t, partitions[2].Graph.Reqs["REQ-TEST-SWH-2"].ParentIds)
+	assert.False(t, partitions[2].Graph.Reqs["REQ-TEST-SWL-1"].Stub)
+	assert.Equal(t, []*code.Code{tag}, partitions[2].Graph.CodeTags["repo"])
+	assert.Equal(t, []string{"SDD", "code"}, descriptions(partitions[2].Graph))
+
+	// Merging the partitions in any order replaces the stubs by the requirements they stand for
+	dir := t.TempDir()
+	var paths []string
+	for i := len(partitions) - 1; i >= 0; i-- {
+		data, err := json.Marshal(partitions[i].Graph)
+		assert.NoError(t, err)
+		path := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		assert.NoError(t, os.WriteFile(path, data, 0644))
+		paths = append(paths, path)
+	}
+	merged, err := LoadGraphs(paths)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2", "REQ-TEST-SWL-1"}, reqIDs(merged))
+	for _, r := range merged.Reqs {
+		assert.False(t, r.Stub)
+	}
+	assert.Equal(t, []string{"REQ-TEST-SWH-1"}, merged.Reqs["REQ-TEST-SWH-2"].ParentIds)
+	assert.Len(t, merged.Reqs["REQ-TEST-SWH-1"].Children, 2)
+	assert.Len(t, merged.CodeTags["repo"], 1)
+	assert.Len(t, merged.Issues, 4)
+	assert.Equal(t, repos.RepoName("repo"), merged.ReqtraqConfig.TargetRepo)
+
+	// A missing partition leaves the stubs in place, which is reported
+	incomplete, err := LoadGraphs(paths[:1])
+	assert.NoError(t, err)
+	assert.Contains(t, descriptions(incomplete), "Inconsistent graph: requirement REQ-TEST-SWH-1 is only a stub, the partition of its document is missing.")
+}
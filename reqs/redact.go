@@ -0,0 +1,140 @@
+package reqs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedText replaces the text of the redacted requirements
+const RedactedText = "*The text of this requirement is redacted.*"
+
+// Redaction selects the requirements whose text is redacted, by the value of one of their attributes
+type Redaction struct {
+	// The uppercase name of the attribute
+	Attribute string
+	Value     *regexp.Regexp
+}
+
+// ParseRedactions parses redactions written as `ATTRIBUTE=REGEXP`, e.g. `EXPORT CONTROL=^Restricted$`
+// @llr REQ-TRAQ-SWL-177
+func ParseRedactions(specs []string) ([]Redaction, error) {
+	redactions := make([]Redaction, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("Invalid redaction `%s`, expected ATTRIBUTE=REGEXP", spec)
+		}
+		value, err := regexp.Compile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid regular expression in redaction `%s`: %v", spec, err)
+		}
+		redactions = append(redactions, Redaction{Attribute: strings.ToUpper(strings.TrimSpace(parts[0])), Value: value})
+	}
+	return redactions, nil
+}
+
+// IsRedacted returns whether the value of an attribute of the requirement matches any of the redactions
+// @llr REQ-TRAQ-SWL-177
+func (r *Req) IsRedacted(redactions []Redaction) bool {
+	for _, redaction := range redactions {
+		value, ok := r.Attributes[redaction.Attribute]
+		if !ok {
+			value, ok = r.ComputedAttributes[redaction.Attribute]
+		}
+		if ok && redaction.Value.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFreeText returns whether the given uppercase attribute of the requirement holds free text, i.e. whether the
+// schema of its document does not restrict its values. The attributes missing from the schema, e.g. the rationales
+// of the links to the parents, are free text, unlike the parents themselves.
+// @llr REQ-TRAQ-SWL-177
+func (r *Req) isFreeText(name string) bool {
+	if name == "PARENTS" {
+		return false
+	}
+	if r.Document == nil {
+		return true
+	}
+	attribute, ok := r.schemaAttributes()[name]
+	return !ok || attribute.Value == nil || attribute.Value.String() == ".*"
+}
+
+// redactedHistory returns the changes of the attributes of a redacted requirement, with the values of the free text
+// attributes replaced by the placeholder
+// @llr REQ-TRAQ-SWL-177
+func (r *Req) redactedHistory(changes []AttributeChange) []AttributeChange {
+	if changes == nil {
+		return nil
+	}
+	redacted := make([]AttributeChange, len(changes))
+	for i, change := range changes {
+		redacted[i] = change
+		if r.isFreeText(change.Attribute) {
+			redacted[i].Old = RedactedText
+			redacted[i].New = RedactedText
+		}
+	}
+	return redacted
+}
+
+// Redacted returns a clone of the graph where the body, the free text attributes, the acceptance criteria and the
+// review comments of the requirements matching any of the redactions are replaced by a placeholder. The IDs, titles,
+// attributes restricted by the schema and links of the requirements are preserved, so the clone still shows the
+// trace structure. The graph itself is not modified, as it may be cached for other commands.
+// @llr REQ-TRAQ-SWL-177
+func (rg *ReqGraph) Redacted(redactions []Redaction) *ReqGraph {
+	redacted := *rg
+	redacted.Reqs = make(map[string]*Req, len(rg.Reqs))
+	for id, r := range rg.Reqs {
+		clone := *r
+		if r.IsRedacted(redactions) {
+			clone.Redacted = true
+			clone.Body = RedactedText
+			clone.AcceptanceCriteria = nil
+			clone.ReviewComments = nil
+			clone.Attributes = make(map[string]string, len(r.Attributes))
+			for key, value := range r.Attributes {
+				if r.isFreeText(key) {
+					value = RedactedText
+				}
+				clone.Attributes[key] = value
+			}
+			clone.AttributeHistory = r.redactedHistory(r.AttributeHistory)
+		}
+		redacted.Reqs[id] = &clone
+	}
+
+	// The links point to the requirements of the graph, they are replaced by their copies
+	relink := func(linked []*Req) []*Req {
+		if linked == nil {
+			return nil
+		}
+		copies := make([]*Req, len(linked))
+		for i, r := range linked {
+			if clone, ok := redacted.Reqs[r.ID]; ok && rg.Reqs[r.ID] == r {
+				copies[i] = clone
+			} else {
+				copies[i] = r
+			}
+		}
+		return copies
+	}
+	for _, r := range redacted.Reqs {
+		r.Parents = relink(r.Parents)
+		r.Children = relink(r.Children)
+	}
+	if rg.FlowTags != nil {
+		redacted.FlowTags = make(map[string]*Flow, len(rg.FlowTags))
+		for id, f := range rg.FlowTags {
+			clone := *f
+			clone.Reqs = relink(f.Reqs)
+			redacted.FlowTags[id] = &clone
+		}
+	}
+	return &redacted
+}
@@ -0,0 +1,63 @@
+package reqs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-177
+func TestReqGraph_Redacted(t *testing.T) {
+	doc := &config.Document{Schema: config.Schema{Attributes: map[string]*config.Attribute{
+		"EXPORT CONTROL": {Value: regexp.MustCompile("(Public|Restricted)")},
+		"RATIONALE":      {Value: regexp.MustCompile(".*")},
+		"PARENTS":        {Value: regexp.MustCompile(".*")},
+	}}}
+	sys := &Req{ID: "REQ-TEST-SYS-1", Title: "Navigation", Body: "The system shall navigate.",
+		Attributes: map[string]string{"EXPORT CONTROL": "Public"}, Document: doc}
+	swh := &Req{ID: "REQ-TEST-SWH-1", Title: "Encryption", Body: "The software shall use the secret cipher.",
+		Attributes: map[string]string{"EXPORT CONTROL": "Restricted", "RATIONALE": "The cipher is secret.",
+			"PARENTS": sys.ID, SatisfactionAttributePrefix + sys.ID: "The cipher protects the navigation."},
+		AttributeHistory:   []AttributeChange{{Attribute: "RATIONALE", Old: "", New: "The cipher is secret."}},
+		Document:           doc,
+		ParentIds:          []string{sys.ID},
+		Parents:            []*Req{sys},
+		ReviewComments:     []ReviewComment{{Author: "alice", Comment: "Name the cipher"}},
+		AcceptanceCriteria: []AcceptanceCriterion{{ID: "AC1", Text: "The cipher is used"}}}
+	sys.Children = []*Req{swh}
+	flow := &Flow{ID: "ERR-CF-IN-001", Reqs: []*Req{swh}}
+	rg := &ReqGraph{Reqs: map[string]*Req{sys.ID: sys, swh.ID: swh}, FlowTags: map[string]*Flow{flow.ID: flow}}
+
+	redactions, err := ParseRedactions([]string{"export control=^Restricted$"})
+	assert.NoError(t, err)
+	redacted := rg.Redacted(redactions)
+
+	redactedSwh := redacted.Reqs[swh.ID]
+	assert.Equal(t, "Encryption", redactedSwh.Title)
+	assert.Equal(t, RedactedText, redactedSwh.Body)
+	assert.True(t, redactedSwh.Redacted)
+	assert.Equal(t, RedactedText, redactedSwh.Attributes["RATIONALE"])
+	assert.Equal(t, RedactedText, redactedSwh.Attributes[SatisfactionAttributePrefix+sys.ID])
+	assert.Equal(t, RedactedText, redactedSwh.AttributeHistory[0].New)
+	assert.Equal(t, "Restricted", redactedSwh.Attributes["EXPORT CONTROL"])
+	assert.Equal(t, sys.ID, redactedSwh.Attributes["PARENTS"])
+	assert.Nil(t, redactedSwh.ReviewComments)
+	assert.Nil(t, redactedSwh.AcceptanceCriteria)
+	assert.Equal(t, []string{sys.ID}, redactedSwh.ParentIds)
+	assert.Equal(t, "The system shall navigate.", redacted.Reqs[sys.ID].Body)
+
+	// The links lead to the redacted copies
+	assert.Same(t, redacted.Reqs[sys.ID], redactedSwh.Parents[0])
+	assert.Same(t, redactedSwh, redacted.Reqs[sys.ID].Children[0])
+	assert.Same(t, redactedSwh, redacted.FlowTags[flow.ID].Reqs[0])
+
+	// The graph itself is left untouched
+	assert.Equal(t, "The software shall use the secret cipher.", swh.Body)
+	assert.Equal(t, "The cipher is secret.", swh.Attributes["RATIONALE"])
+	assert.Same(t, swh, sys.Children[0])
+
+	_, err = ParseRedactions([]string{"Restricted"})
+	assert.EqualError(t, err, "Invalid redaction `Restricted`, expected ATTRIBUTE=REGEXP")
+}
@@ -0,0 +1,61 @@
+package reqs
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The words of a commit message which may be requirement IDs, in any of the configurable formats
+var reMessageWord = regexp.MustCompile(`[\w.-]+`)
+
+// RelatedChange is a commit whose message mentions a requirement, e.g. a change of the build scripts or of the
+// documentation which is not traced by code tags
+type RelatedChange struct {
+	Commit   repos.Commit
+	RepoName repos.RepoName
+}
+
+// LoadRelatedChanges attaches to the requirements the commits of the repositories whose message mentions their ID,
+// newest first. The repositories of the configuration and of the requirements are searched, in alphabetical order.
+// @llr REQ-TRAQ-SWL-158
+func (rg *ReqGraph) LoadRelatedChanges(settings *config.RelatedChanges) error {
+	repoNames := make(map[repos.RepoName]bool)
+	if rg.ReqtraqConfig != nil {
+		for repoName := range rg.ReqtraqConfig.Repos {
+			repoNames[repoName] = true
+		}
+	}
+	for _, r := range rg.Reqs {
+		r.RelatedChanges = nil
+		if !r.Stub && r.RepoName != "" {
+			repoNames[r.RepoName] = true
+		}
+	}
+	sortedNames := make([]repos.RepoName, 0, len(repoNames))
+	for repoName := range repoNames {
+		sortedNames = append(sortedNames, repoName)
+	}
+	sort.Slice(sortedNames, func(i, j int) bool { return sortedNames[i] < sortedNames[j] })
+
+	for _, repoName := range sortedNames {
+		commits, err := repos.CommitMessages(repoName, settings.Since)
+		if err != nil {
+			return err
+		}
+		for _, commit := range commits {
+			mentioned := make(map[string]bool)
+			for _, word := range reMessageWord.FindAllString(commit.Message, -1) {
+				id := strings.TrimRight(word, ".-")
+				if r, ok := rg.Reqs[id]; ok && !r.Stub && !mentioned[id] {
+					mentioned[id] = true
+					r.RelatedChanges = append(r.RelatedChanges, RelatedChange{Commit: commit.Commit, RepoName: repoName})
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package reqs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-158
+func TestReqGraph_LoadRelatedChanges(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	commit := func(file, message string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, file), []byte(message), 0644))
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+	}
+	git("init", "-q")
+	commit("Makefile", "Build the logger with optimizations\n\nNeeded by REQ-TEST-SWL-1.")
+	git("tag", "v1")
+	commit("README.md", "Document log rotation\n\nSee REQ-TEST-SWL-1 and REQ-TEST-SWL-2, REQ-TEST-SWL-1 again.")
+	commit("ci.yml", "Run the tests nightly")
+	repos.RegisterRepository("relatedchanges", repos.RepoPath(repoPath))
+
+	doc := config.Document{Path: "TEST-138-SDD.md"}
+	rotation := &Req{ID: "REQ-TEST-SWL-1", Document: &doc, RepoName: "relatedchanges", Position: 1}
+	level := &Req{ID: "REQ-TEST-SWL-2", Document: &doc, RepoName: "relatedchanges", Position: 2}
+	rg := &ReqGraph{Reqs: map[string]*Req{rotation.ID: rotation, level.ID: level}}
+
+	assert.NoError(t, rg.LoadRelatedChanges(&config.RelatedChanges{}))
+	if assert.Len(t, rotation.RelatedChanges, 2) {
+		// Newest first, each commit once
+		assert.Equal(t, "Document log rotation", rotation.RelatedChanges[0].Commit.Subject)
+		assert.Equal(t, "Build the logger with optimizations", rotation.RelatedChanges[1].Commit.Subject)
+		assert.Equal(t, "Jane Doe", rotation.RelatedChanges[1].Commit.Author)
+		assert.Equal(t, repos.RepoName("relatedchanges"), rotation.RelatedChanges[1].RepoName)
+	}
+	assert.Len(t, level.RelatedChanges, 1)
+
+	// Only the commits after the given reference are searched
+	assert.NoError(t, rg.LoadRelatedChanges(&config.RelatedChanges{Since: "v1"}))
+	if assert.Len(t, rotation.RelatedChanges, 1) {
+		assert.Equal(t, "Document log rotation", rotation.RelatedChanges[0].Commit.Subject)
+	}
+}
@@ -0,0 +1,96 @@
+package reqs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/daedaleanai/reqtraq/config"
+)
+
+// idRenaming renames the identifiers of the requirements of one level with the old prefix
+type idRenaming struct {
+	re     *regexp.Regexp
+	format *config.IDFormat
+	level  config.ReqLevel
+}
+
+// PrefixRenaming renames the identifiers of the requirements, assumptions and flow tags with a given prefix
+type PrefixRenaming struct {
+	oldPrefix config.ReqPrefix
+	newPrefix config.ReqPrefix
+	ids       []idRenaming
+	flows     *regexp.Regexp
+	configs   *regexp.Regexp
+}
+
+// NewPrefixRenaming returns the renaming of the prefix of the requirements of the documents of all the configured
+// repositories from oldPrefix to newPrefix. The new prefix must not be used by any document yet.
+// @llr REQ-TRAQ-SWL-141
+func NewPrefixRenaming(cfg *config.Config, oldPrefix, newPrefix config.ReqPrefix) (*PrefixRenaming, error) {
+	if !regexp.MustCompile(`^\w+$`).MatchString(string(newPrefix)) {
+		return nil, fmt.Errorf("Invalid prefix `%s`", newPrefix)
+	}
+	p := &PrefixRenaming{
+		oldPrefix: oldPrefix,
+		newPrefix: newPrefix,
+		flows:     regexp.MustCompile(`\b(DF|CF)-` + regexp.QuoteMeta(string(oldPrefix)) + `-(\d+)\b`),
+		configs:   regexp.MustCompile(`("prefix"\s*:\s*")` + regexp.QuoteMeta(string(oldPrefix)) + `"`),
+	}
+	seen := make(map[string]bool)
+	for _, repo := range cfg.Repos {
+		for _, doc := range repo.Documents {
+			switch doc.ReqSpec.Prefix {
+			case newPrefix:
+				return nil, fmt.Errorf("Prefix `%s` is already used by document `%s`", newPrefix, doc.Path)
+			case oldPrefix:
+				format := doc.ReqSpec.Format()
+				key := format.Template + "\x00" + string(doc.ReqSpec.Level)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				p.ids = append(p.ids, idRenaming{
+					re:     format.RequirementsRegexp(oldPrefix, doc.ReqSpec.Level),
+					format: format,
+					level:  doc.ReqSpec.Level,
+				})
+			}
+		}
+	}
+	if len(p.ids) == 0 {
+		return nil, fmt.Errorf("No document uses prefix `%s`", oldPrefix)
+	}
+	return p, nil
+}
+
+// Rename returns the given text, the content of a document or of a source file, with the identifiers of the
+// requirements, assumptions and flow tags with the old prefix renamed, and the number of renamed identifiers
+// @llr REQ-TRAQ-SWL-141
+func (p *PrefixRenaming) Rename(text string) (string, int) {
+	renamed := 0
+	for _, ids := range p.ids {
+		text = ids.re.ReplaceAllStringFunc(text, func(id string) string {
+			parts, ok := ids.format.Find(id)
+			number, err := strconv.Atoi(parts.Number)
+			if !ok || err != nil {
+				return id
+			}
+			renamed++
+			return ids.format.Format(parts.Variant, p.newPrefix, ids.level, number)
+		})
+	}
+	text = p.flows.ReplaceAllStringFunc(text, func(id string) string {
+		renamed++
+		return p.flows.ReplaceAllString(id, "${1}-"+string(p.newPrefix)+"-${2}")
+	})
+	return text, renamed
+}
+
+// RenameConfig returns the given content of a configuration file with the prefix of its documents and of their
+// parents renamed, and the number of renamed prefixes
+// @llr REQ-TRAQ-SWL-141
+func (p *PrefixRenaming) RenameConfig(content string) (string, int) {
+	renamed := len(p.configs.FindAllStringIndex(content, -1))
+	return p.configs.ReplaceAllString(content, `${1}`+string(p.newPrefix)+`"`), renamed
+}
@@ -0,0 +1,49 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-141
+func TestPrefixRenaming(t *testing.T) {
+	cfg := &config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+		"repo": {Documents: []config.Document{
+			{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}},
+			{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}},
+			{Path: "OTH-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "OTH", Level: "SWL", IDFormat: config.MustParseIDFormat("{PREFIX}_{LEVEL}_{N:3}")}},
+		}},
+	}}
+
+	renaming, err := NewPrefixRenaming(cfg, "TEST", "NEW")
+	if !assert.NoError(t, err) {
+		return
+	}
+	renamed, count := renaming.Rename(`### REQ-TEST-SWL-12 Log file
+- Parents: [REQ-TEST-SWH-1](TEST-137-SRD.md#req-test-swh-1-logging), ASM-TEST-SWH-2, REQ-OTH-SWH-1
+- Flow: DF-TEST-3, CF-TEST-4-DELETED, DF-TESTS-5
+// @llr REQ-TEST-SWL-12, OTH_SWL_001`)
+	assert.Equal(t, `### REQ-NEW-SWL-12 Log file
+- Parents: [REQ-NEW-SWH-1](TEST-137-SRD.md#req-test-swh-1-logging), ASM-NEW-SWH-2, REQ-OTH-SWH-1
+- Flow: DF-NEW-3, CF-NEW-4-DELETED, DF-TESTS-5
+// @llr REQ-NEW-SWL-12, OTH_SWL_001`, renamed)
+	assert.Equal(t, 6, count)
+
+	renaming, err = NewPrefixRenaming(cfg, "OTH", "NEW")
+	assert.NoError(t, err)
+	renamed, count = renaming.Rename("// @llr REQ-TEST-SWL-12, OTH_SWL_001")
+	assert.Equal(t, "// @llr REQ-TEST-SWL-12, NEW_SWL_001", renamed)
+	assert.Equal(t, 1, count)
+
+	renamed, count = renaming.RenameConfig(`{"prefix": "OTH", "parent": {"prefix" : "OTH"}, "path": "OTH-138-SDD.md"}`)
+	assert.Equal(t, `{"prefix": "NEW", "parent": {"prefix" : "NEW"}, "path": "OTH-138-SDD.md"}`, renamed)
+	assert.Equal(t, 2, count)
+
+	_, err = NewPrefixRenaming(cfg, "TEST", "OTH")
+	assert.Error(t, err)
+	_, err = NewPrefixRenaming(cfg, "NONE", "NEW")
+	assert.Error(t, err)
+}
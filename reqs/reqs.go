@@ -15,21 +15,28 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/daedaleanai/reqtraq/code"
 	"github.com/daedaleanai/reqtraq/config"
 	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/profile"
+	"github.com/daedaleanai/reqtraq/progress"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/pkg/errors"
 )
 
-// OrdsByPosition returns the SYSTEM requirements which don't have any parent, ordered by position.
-// @llr REQ-TRAQ-SWL-12, REQ-TRAQ-SWL-20
+// OrdsByPosition returns the SYSTEM requirements which don't have any parent, ordered by repository, by
+// document and by position within the document.
+// @llr REQ-TRAQ-SWL-12, REQ-TRAQ-SWL-20, REQ-TRAQ-SWL-121
 func (rg ReqGraph) OrdsByPosition() []*Req {
 	var r []*Req
 	for _, v := range rg.Reqs {
@@ -37,53 +44,293 @@ func (rg ReqGraph) OrdsByPosition() []*Req {
 			r = append(r, v)
 		}
 	}
-	sort.Sort(byPosition(r))
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].RepoName != r[j].RepoName {
+			return r[i].RepoName < r[j].RepoName
+		}
+		if r[i].Document.Path != r[j].Document.Path {
+			return r[i].Document.Path < r[j].Document.Path
+		}
+		return r[i].Position < r[j].Position
+	})
 	return r
 }
 
+// DocumentOrds holds the SYSTEM requirements without parents of a single document, ordered by position
+type DocumentOrds struct {
+	RepoName repos.RepoName
+	Path     string
+	Reqs     []*Req
+}
+
+// OrdsByDocument returns the SYSTEM requirements which don't have any parent grouped by repository and by
+// document, in the order of OrdsByPosition
+// @llr REQ-TRAQ-SWL-121
+func (rg ReqGraph) OrdsByDocument() []DocumentOrds {
+	var groups []DocumentOrds
+	for _, r := range rg.OrdsByPosition() {
+		if len(groups) == 0 || groups[len(groups)-1].RepoName != r.RepoName || groups[len(groups)-1].Path != r.Document.Path {
+			groups = append(groups, DocumentOrds{RepoName: r.RepoName, Path: r.Document.Path})
+		}
+		group := &groups[len(groups)-1]
+		group.Reqs = append(group.Reqs, r)
+	}
+	return groups
+}
+
+// Selects whether the documents are parsed concurrently while building the graph. The parsed
+// documents are always added to the graph ordered by repository, in the order of their configuration,
+// so the graph does not depend on it once canonicalized, see Canonicalize.
+var ParallelBuild bool = false
+
+// Selects whether building the graph stops after the first document which produced a critical issue.
+// The links of such a partial graph are not resolved.
+var FailFast bool = false
+
+// Receives the messages printed while building the graph, the standard output by default.
+var MessageWriter io.Writer = os.Stdout
+
+// The requirements, flow tags and code tags parsed out of a single document and its implementation
+type parsedDocument struct {
+	repoName repos.RepoName
+	document *config.Document
+	// The formats of the identifiers of the requirements the code can reference
+	idFormats []*config.IDFormat
+	reqs      []*Req
+	flow      []*Flow
+	// The issues found while parsing the document which do not prevent using it
+	issues   []diagnostics.Issue
+	codeTags map[code.CodeFile][]*code.Code
+	// The code files which could not be parsed
+	skipped []code.SkippedFile
+	err     error
+}
+
 // BuildGraph returns a graph resulting from parsing the certdocs. The graph includes a list of
 // errors found while walking the requirements, code, or resolving the graph.
 // The separate returned error indicates if reading the certdocs and code failed.
-// @llr REQ-TRAQ-SWL-1
+// @llr REQ-TRAQ-SWL-1, REQ-TRAQ-SWL-88, REQ-TRAQ-SWL-92, REQ-TRAQ-SWL-101, REQ-TRAQ-SWL-108, REQ-TRAQ-SWL-125, REQ-TRAQ-SWL-130, REQ-TRAQ-SWL-139, REQ-TRAQ-SWL-148, REQ-TRAQ-SWL-153, REQ-TRAQ-SWL-158, REQ-TRAQ-SWL-159, REQ-TRAQ-SWL-171, REQ-TRAQ-SWL-172, REQ-TRAQ-SWL-176, REQ-TRAQ-SWL-182, REQ-TRAQ-SWL-186, REQ-TRAQ-SWL-189
 func BuildGraph(reqtraqConfig *config.Config) (*ReqGraph, error) {
-	fmt.Printf("Building requirements graph..\n")
+	fmt.Fprintf(MessageWriter, "Building requirements graph..\n")
+	progress.Reset()
 	rg := &ReqGraph{
 		make(map[string]*Req, 0),
 		make(map[repos.RepoName][]*code.Code),
 		make(map[string]*Flow),
 		make([]diagnostics.Issue, 0),
-		reqtraqConfig}
+		reqtraqConfig,
+		nil}
 
-	// For each repository, we walk through the documents and parse them
+	// Collect the documents of every repository, so that they can be parsed in any order. They are added to the graph
+	// ordered by repository, so the requirements defined twice are always reported at the same definition.
+	repoNames := make([]repos.RepoName, 0, len(reqtraqConfig.Repos))
 	for repoName := range reqtraqConfig.Repos {
-		fmt.Printf("Processing repo: %s\n", repoName)
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Slice(repoNames, func(i, j int) bool { return repoNames[i] < repoNames[j] })
+	if err := checkOnlyRepo(reqtraqConfig); err != nil {
+		return rg, err
+	}
+	documents := []*parsedDocument{}
+	parsedDocuments := make(map[IssueLocation]*config.Document)
+	idFormats := reqtraqConfig.IDFormats()
+	for _, repoName := range repoNames {
+		if OnlyRepo != "" && repoName != OnlyRepo {
+			continue
+		}
 		for docIdx := range reqtraqConfig.Repos[repoName].Documents {
-			doc := &reqtraqConfig.Repos[repoName].Documents[docIdx]
-			fmt.Printf("Processing doc: %s\n", doc.Path)
-			if err := rg.addCertdocToGraph(repoName, doc); err != nil {
-				return rg, errors.Wrap(err, "Failed parsing certdocs")
-			}
+			documents = append(documents, &parsedDocument{
+				repoName:  repoName,
+				document:  &reqtraqConfig.Repos[repoName].Documents[docIdx],
+				idFormats: idFormats,
+			})
+		}
+	}
 
-			fmt.Printf("Processing code: %s\n", doc.Path)
-			if codeTags, err := code.ParseCode(repoName, doc); err != nil {
-				return rg, errors.Wrap(err, "Failed parsing implementation")
-			} else {
-				rg.mergeTags(&codeTags)
-			}
+	// Parsing the documents and their code is most of the work
+	documentParsed := progress.Steps("parse", 0, 80, len(documents))
+	if ParallelBuild {
+		parseDocumentsConcurrently(documents, documentParsed)
+	}
+
+	for _, parsed := range documents {
+		if !ParallelBuild {
+			parsed.parse()
+			documentParsed(parsed.document.Path)
+		}
+		if parsed.err != nil {
+			return rg, parsed.err
+		}
+		parsedDocuments[IssueLocation{RepoName: parsed.repoName, Path: parsed.document.Path}] = parsed.document
+
+		rg.addParsedCertdocToGraph(parsed.repoName, parsed.document, parsed.reqs, parsed.flow)
+		rg.mergeTags(&parsed.codeTags)
+		rg.Issues = append(rg.Issues, parsed.issues...)
+		rg.Issues = append(rg.Issues, skippedFileIssues(parsed.document, parsed.skipped)...)
+
+		if FailFast && rg.hasCriticalIssues() {
+			fmt.Fprintf(MessageWriter, "Stopping at document %s: critical issues found\n", parsed.document.Path)
+			rg.PrepareForUsage()
+			rg.locateIssues(parsedDocuments)
+			progress.Report("done", 100, "")
+			return rg, nil
+		}
+	}
+
+	progress.Report("overrides", 80, "")
+	overrideIssues, err := rg.applyOverrides(Variant)
+	if err != nil {
+		return rg, err
+	}
+	rg.Issues = append(rg.Issues, overrideIssues...)
+
+	// The other repositories are loaded once the overrides are applied, as their graphs already have them
+	var otherRepoIssues []diagnostics.Issue
+	if OnlyRepo != "" && len(OtherRepoGraphs) > 0 {
+		if otherRepoIssues, err = rg.loadOtherRepos(OtherRepoGraphs); err != nil {
+			return rg, errors.Wrap(err, "Failed loading the other repositories")
 		}
 	}
 
 	// Call Resolve to check links between requirements and code
+	progress.Report("resolve", 85, "")
+	stopProfile := profile.Start("resolve", "", "")
 	rg.Issues = append(rg.Issues, rg.Resolve()...)
+	stopProfile()
+
+	// The reviews are recorded in the git history, which is not available when git is disabled
+	if reqtraqConfig.CodeReviews != nil && !repos.NoGit {
+		progress.Report("code reviews", 90, "")
+		stopProfile = profile.Start("code reviews", "", "")
+		err := rg.LoadCodeReviews(reqtraqConfig.CodeReviews)
+		stopProfile()
+		if err != nil {
+			return rg, errors.Wrap(err, "Failed loading the code reviews")
+		}
+	}
+	if reqtraqConfig.RelatedChanges != nil && !repos.NoGit {
+		progress.Report("related changes", 90, "")
+		stopProfile = profile.Start("related changes", "", "")
+		err := rg.LoadRelatedChanges(reqtraqConfig.RelatedChanges)
+		stopProfile()
+		if err != nil {
+			return rg, errors.Wrap(err, "Failed loading the related changes")
+		}
+	}
+	if reqtraqConfig.BuildArtifacts != nil {
+		progress.Report("build artifacts", 90, "")
+		if err := rg.LoadBuildArtifacts(reqtraqConfig.BuildArtifacts); err != nil {
+			return rg, errors.Wrap(err, "Failed loading the build artifacts")
+		}
+		rg.Issues = append(rg.Issues, rg.checkUnshippedImplementations()...)
+	}
+	if reqtraqConfig.TestCoverage != nil {
+		progress.Report("test coverage", 90, "")
+		if err := rg.LoadTestCoverage(reqtraqConfig.TestCoverage); err != nil {
+			return rg, errors.Wrap(err, "Failed loading the test coverage")
+		}
+	}
+	if len(reqtraqConfig.ModelManifests) > 0 {
+		progress.Report("model elements", 90, "")
+		issues, err := rg.LoadModelElements(reqtraqConfig.ModelManifests)
+		if err != nil {
+			return rg, errors.Wrap(err, "Failed loading the model elements")
+		}
+		rg.Issues = append(rg.Issues, issues...)
+	}
+	if OnlyRepo != "" && len(OtherRepoGraphs) > 0 {
+		// The issues of the other repositories are the ones found when their graphs were built
+		rg.Issues = append(IssuesOfRepo(rg.Issues, OnlyRepo), otherRepoIssues...)
+	}
 
 	rg.PrepareForUsage()
+	rg.locateIssues(parsedDocuments)
+	progress.Report("done", 100, "")
 
 	return rg, nil
 }
 
+// parse reads the requirements and flow tags of the document, followed by the code tags of its
+// implementation. Any error is stored in the parsedDocument.
+// @llr REQ-TRAQ-SWL-1, REQ-TRAQ-SWL-88, REQ-TRAQ-SWL-108, REQ-TRAQ-SWL-148, REQ-TRAQ-SWL-188
+func (parsed *parsedDocument) parse() {
+	fmt.Fprintf(MessageWriter, "Processing doc: %s\n", parsed.document.Path)
+	stopProfile := profile.Start("markdown parse", string(parsed.repoName), parsed.document.Path)
+	reqs, flow, issues, document, err := parseDocument(parsed.repoName, parsed.document)
+	stopProfile()
+	if err != nil {
+		err = errors.Wrapf(err, "Error parsing `%s` in repo `%s`", parsed.document.Path, parsed.repoName)
+		parsed.err = errors.Wrap(err, "Failed parsing certdocs")
+		return
+	}
+	// The requirements link back to the document as parsed, with the line counts of its fragments as read
+	parsed.document = document
+	parsed.reqs = reqs
+	parsed.flow = flow
+	parsed.issues = issues
+
+	fmt.Fprintf(MessageWriter, "Processing code: %s\n", parsed.document.Path)
+	codeTags, skipped, err := code.ParseCode(parsed.repoName, parsed.document, parsed.idFormats)
+	if err != nil {
+		parsed.err = errors.Wrap(err, "Failed parsing implementation")
+		return
+	}
+	parsed.codeTags = codeTags
+	parsed.skipped = skipped
+	ambiguous, err := ambiguousFileIssues(parsed.repoName, parsed.document)
+	if err != nil {
+		parsed.err = errors.Wrap(err, "Failed parsing implementation")
+		return
+	}
+	parsed.issues = append(parsed.issues, ambiguous...)
+
+	if parsed.document.Doxygen != "" {
+		if parsed.codeTags == nil {
+			parsed.codeTags = make(map[code.CodeFile][]*code.Code)
+		}
+		doxygenReqs, doxygenIssues, err := parseDoxygen(parsed.repoName, parsed.document, parsed.reqs, parsed.codeTags)
+		if err != nil {
+			parsed.err = errors.Wrapf(err, "Failed parsing the Doxygen XML output of `%s`", parsed.document.Path)
+			return
+		}
+		parsed.reqs = append(parsed.reqs, doxygenReqs...)
+		parsed.issues = append(parsed.issues, doxygenIssues...)
+	}
+}
+
+// parseDocumentsConcurrently parses the given documents using as many workers as CPUs are available, calling the
+// given function as each document is parsed.
+// @llr REQ-TRAQ-SWL-88, REQ-TRAQ-SWL-189
+func parseDocumentsConcurrently(documents []*parsedDocument, documentParsed func(document string)) {
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, runtime.NumCPU())
+	for _, parsed := range documents {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(parsed *parsedDocument) {
+			defer wg.Done()
+			parsed.parse()
+			documentParsed(parsed.document.Path)
+			<-workers
+		}(parsed)
+	}
+	wg.Wait()
+}
+
+// hasCriticalIssues returns true if any of the issues found so far is not a lint message.
+// @llr REQ-TRAQ-SWL-88
+func (rg *ReqGraph) hasCriticalIssues() bool {
+	for _, issue := range rg.Issues {
+		if issue.Severity != diagnostics.IssueSeverityNote {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadGraphs loads the specified previously exported requirements graphs and
-// merges them into one.
-// @llr REQ-TRAQ-SWL-80
+// merges them into one. The inconsistencies of the merged graph are added to its issues.
+// @llr REQ-TRAQ-SWL-80, REQ-TRAQ-SWL-124, REQ-TRAQ-SWL-130
 func LoadGraphs(graphs_paths []string) (*ReqGraph, error) {
 	var rg *ReqGraph = &ReqGraph{
 		make(map[string]*Req, 0),
@@ -91,21 +338,17 @@ func LoadGraphs(graphs_paths []string) (*ReqGraph, error) {
 		make(map[string]*Flow),
 		make([]diagnostics.Issue, 0),
 		nil,
+		nil,
 	}
 	for _, p := range graphs_paths {
 		jsonFile, err := os.Open(p)
 		if err != nil {
 			return nil, errors.Wrap(err, "open")
 		}
-		data, err := io.ReadAll(jsonFile)
-		if err != nil {
-			return nil, errors.Wrap(err, "reading json file")
-		}
-
-		var g *ReqGraph = &ReqGraph{Reqs: make(map[string]*Req)}
-		err = json.Unmarshal(data, g)
+		g, err := ReadGraph(jsonFile)
+		jsonFile.Close()
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to unmarshal")
+			return nil, err
 		}
 
 		err = rg.mergeGraph(g)
@@ -115,16 +358,65 @@ func LoadGraphs(graphs_paths []string) (*ReqGraph, error) {
 	}
 
 	rg.PrepareForUsage()
+	rg.Issues = append(rg.Issues, rg.checkConsistency()...)
+	rg.locateIssues(nil)
 
 	return rg, nil
 }
 
-// mergeGraph merges the specified graph into this one.
-// @llr REQ-TRAQ-SWL-80
+// locateIssues points the issues found in documents split in several files to the file containing the issue,
+// replacing the line in the concatenation of the fragments by the line in the file. The documents are the ones the
+// requirements link back to and the given parsed documents, by location, which hold the line counts of their
+// fragments as read.
+// @llr REQ-TRAQ-SWL-130
+func (rg *ReqGraph) locateIssues(parsedDocuments map[IssueLocation]*config.Document) {
+	documents := make(map[IssueLocation]*config.Document)
+	for _, r := range rg.Reqs {
+		if r.Document != nil && len(r.Document.Fragments) > 0 {
+			documents[IssueLocation{RepoName: r.RepoName, Path: r.Document.Path}] = r.Document
+		}
+	}
+	for location, doc := range parsedDocuments {
+		if len(doc.Fragments) > 0 {
+			documents[location] = doc
+		}
+	}
+	if len(documents) == 0 {
+		return
+	}
+	for i := range rg.Issues {
+		issue := &rg.Issues[i]
+		if doc, ok := documents[IssueLocation{RepoName: issue.RepoName, Path: issue.Path}]; ok && issue.Line > 0 {
+			issue.Path, issue.Line = doc.Locate(issue.Line)
+		}
+	}
+}
+
+// ReadGraph reads a requirements graph exported as raw JSON, without preparing it for usage.
+// @llr REQ-TRAQ-SWL-80, REQ-TRAQ-SWL-112
+func ReadGraph(r io.Reader) (*ReqGraph, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading json file")
+	}
+
+	var g *ReqGraph = &ReqGraph{Reqs: make(map[string]*Req)}
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+	return g, nil
+}
+
+// mergeGraph merges the specified graph into this one. The stubs of requirements of other partitions are replaced
+// by the requirements they stand for, in any order.
+// @llr REQ-TRAQ-SWL-80, REQ-TRAQ-SWL-124, REQ-TRAQ-SWL-136, REQ-TRAQ-SWL-176
 func (rg *ReqGraph) mergeGraph(other *ReqGraph) error {
 	for reqId, r := range other.Reqs {
 		if existing, ok := rg.Reqs[reqId]; ok {
-			if existing != r {
+			if r.Stub {
+				continue
+			}
+			if !existing.Stub && existing != r {
 				return fmt.Errorf("different version of same requirement found: %s", reqId)
 			}
 		}
@@ -146,10 +438,23 @@ func (rg *ReqGraph) mergeGraph(other *ReqGraph) error {
 		}
 	}
 
+	for _, element := range other.ModelElements {
+		alreadyAdded := false
+		for _, e := range rg.ModelElements {
+			if reflect.DeepEqual(e, element) {
+				alreadyAdded = true
+				break
+			}
+		}
+		if !alreadyAdded {
+			rg.ModelElements = append(rg.ModelElements, element)
+		}
+	}
+
 	for _, issue := range other.Issues {
 		alreadyAdded := false
 		for _, addedIssue := range rg.Issues {
-			if addedIssue == issue {
+			if reflect.DeepEqual(addedIssue, issue) {
 				alreadyAdded = true
 				break
 			}
@@ -161,8 +466,15 @@ func (rg *ReqGraph) mergeGraph(other *ReqGraph) error {
 
 	if rg.ReqtraqConfig == nil {
 		rg.ReqtraqConfig = other.ReqtraqConfig
-	} else {
-		rg.ReqtraqConfig.TargetRepo = repos.RepoName(fmt.Sprintf("%s, %s", rg.ReqtraqConfig.TargetRepo, other.ReqtraqConfig.TargetRepo))
+	} else if other.ReqtraqConfig != nil {
+		// The partitions of a graph share the target repository, which is listed once
+		merged := false
+		for _, name := range strings.Split(string(rg.ReqtraqConfig.TargetRepo), ", ") {
+			merged = merged || repos.RepoName(name) == other.ReqtraqConfig.TargetRepo
+		}
+		if !merged {
+			rg.ReqtraqConfig.TargetRepo = repos.RepoName(fmt.Sprintf("%s, %s", rg.ReqtraqConfig.TargetRepo, other.ReqtraqConfig.TargetRepo))
+		}
 		for name, repoConfig := range other.ReqtraqConfig.Repos {
 			// Overwrite already added repo configs, assuming they are the same.
 			rg.ReqtraqConfig.Repos[name] = repoConfig
@@ -172,6 +484,60 @@ func (rg *ReqGraph) mergeGraph(other *ReqGraph) error {
 	return nil
 }
 
+// ambiguousFileIssues returns a warning for each file of the implementation of the document matched by both the
+// code and the test queries, which is neither marked as test or implementation file nor in a configured path
+// @llr REQ-TRAQ-SWL-125
+func ambiguousFileIssues(repoName repos.RepoName, document *config.Document) ([]diagnostics.Issue, error) {
+	var paths []string
+	for _, impl := range document.Implementation {
+		archImpls := []config.ArchImplementation{impl.ArchImplementation}
+		for _, archImpl := range impl.Archs {
+			archImpls = append(archImpls, archImpl)
+		}
+		for _, archImpl := range archImpls {
+			ambiguous, err := archImpl.AmbiguousFiles(repoName)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, ambiguous...)
+		}
+	}
+	sort.Strings(paths)
+
+	var issues []diagnostics.Issue
+	for i, path := range paths {
+		if i > 0 && paths[i-1] == path {
+			continue
+		}
+		issues = append(issues, diagnostics.Issue{
+			Path:     path,
+			RepoName: repoName,
+			Description: fmt.Sprintf("File `%s` is matched both as code and as test of document `%s`, mark it with `reqtraq:test-file` "+
+				"or `reqtraq:implementation-file` or list it in `testPaths` or `implementationPaths`.", path, document.Path),
+			Severity: diagnostics.IssueSeverityMinor,
+			Type:     diagnostics.IssueTypeAmbiguousCodeFile,
+		})
+	}
+	return issues, nil
+}
+
+// skippedFileIssues returns a warning for each code file of the document which was not parsed, as the requirements
+// it implements are not traced
+// @llr REQ-TRAQ-SWL-148
+func skippedFileIssues(document *config.Document, skipped []code.SkippedFile) []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	for _, file := range skipped {
+		issues = append(issues, diagnostics.Issue{
+			Path:        file.CodeFile.Path,
+			RepoName:    file.CodeFile.RepoName,
+			Description: fmt.Sprintf("File `%s` of the implementation of document `%s` was not parsed because %s.", file.CodeFile.Path, document.Path, file.Reason),
+			Severity:    diagnostics.IssueSeverityMinor,
+			Type:        diagnostics.IssueTypeSkippedCodeFile,
+		})
+	}
+	return issues
+}
+
 // Appends all code tags from the given map into the ReqGraph instance.
 // Duplicates are skipped.
 // @llr REQ-TRAQ-SWL-8, REQ-TRAQ-SWL-9
@@ -193,7 +559,7 @@ func (rg *ReqGraph) mergeTags(tagsByFile *map[code.CodeFile][]*code.Code) {
 }
 
 // processFlow process parsed flow tags and check consistency
-// @llr REQ-TRAQ-SWL-84
+// @llr REQ-TRAQ-SWL-84, REQ-TRAQ-SWL-114, REQ-TRAQ-SWL-185
 func (rg *ReqGraph) processFlow(flow []*Flow, documentConfig *config.Document) {
 	flowIds := map[string][]int{}
 
@@ -221,6 +587,10 @@ func (rg *ReqGraph) processFlow(flow []*Flow, documentConfig *config.Document) {
 				})
 			} else {
 				rg.FlowTags[f.ID] = f
+				if !f.Deleted {
+					rg.Issues = append(rg.Issues, f.checkAttributes(documentConfig.Schema.FlowAttributes)...)
+					rg.Issues = append(rg.Issues, f.checkDiagram()...)
+				}
 				numId, _ := strconv.Atoi(parts[2])
 				prefix := fmt.Sprintf("%s-%s", parts[0], parts[1])
 				flowIds[prefix] = append(flowIds[prefix], numId)
@@ -258,11 +628,20 @@ func (rg *ReqGraph) addCertdocToGraph(repoName repos.RepoName, documentConfig *c
 		return errors.Wrapf(err, "Error parsing `%s` in repo `%s`", documentConfig.Path, repoName)
 	}
 
+	rg.addParsedCertdocToGraph(repoName, documentConfig, reqs, flow)
+	return nil
+}
+
+// addParsedCertdocToGraph checks the validity of the requirements and flow tags parsed from a document
+// and then adds them along with any errors found to the regGraph. The requirements whose ID is already defined in
+// another document are reported and not added.
+// @llr REQ-TRAQ-SWL-27, REQ-TRAQ-SWL-86, REQ-TRAQ-SWL-85, REQ-TRAQ-SWL-159
+func (rg *ReqGraph) addParsedCertdocToGraph(repoName repos.RepoName, documentConfig *config.Document, reqs []*Req, flow []*Flow) {
 	// This needs to be done regardless of if there are requirements or not
 	rg.processFlow(flow, documentConfig)
 
 	if len(reqs) == 0 {
-		return nil
+		return
 	}
 
 	// sort the requirements so we can check the sequence
@@ -289,10 +668,21 @@ func (rg *ReqGraph) addCertdocToGraph(repoName repos.RepoName, documentConfig *c
 		}
 		r.RepoName = repoName
 		r.Document = documentConfig
+		if existing, ok := rg.Reqs[r.ID]; ok && !existing.Stub {
+			// Defined in another document, possibly of another repository. The first definition is kept.
+			rg.Issues = append(rg.Issues, diagnostics.Issue{
+				Line:     r.Position,
+				Path:     r.Document.Path,
+				RepoName: r.RepoName,
+				Description: fmt.Sprintf("Duplicate requirement ID %s: defined in %s:%s:%d and in %s:%s:%d.", r.ID,
+					existing.RepoName, existing.Document.Path, existing.Position, r.RepoName, r.Document.Path, r.Position),
+				Severity: diagnostics.IssueSeverityMajor,
+				Type:     diagnostics.IssueTypeInvalidRequirementId,
+			})
+			continue
+		}
 		rg.Reqs[r.ID] = r
 	}
-
-	return nil
 }
 
 // Code may be declared many times and defined at least once per binary. To avoid having to repeat
@@ -478,15 +868,80 @@ func (r *Req) checkShallViolations() []diagnostics.Issue {
 	return issues
 }
 
+// OpenReviewComments returns the review comments of the requirement which have not been resolved yet
+// @llr REQ-TRAQ-SWL-103
+func (r *Req) OpenReviewComments() []ReviewComment {
+	var open []ReviewComment
+	for _, comment := range r.ReviewComments {
+		if !comment.Resolved {
+			open = append(open, comment)
+		}
+	}
+	return open
+}
+
+// ReqsWithOpenReviewComments returns the requirements with open review comments, ordered by repository,
+// document and position
+// @llr REQ-TRAQ-SWL-103
+func (rg ReqGraph) ReqsWithOpenReviewComments() []*Req {
+	var r []*Req
+	for _, v := range rg.Reqs {
+		if len(v.OpenReviewComments()) > 0 {
+			r = append(r, v)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].RepoName != r[j].RepoName {
+			return r[i].RepoName < r[j].RepoName
+		}
+		if r[i].Document.Path != r[j].Document.Path {
+			return r[i].Document.Path < r[j].Document.Path
+		}
+		return r[i].Position < r[j].Position
+	})
+	return r
+}
+
+// Checks that requirements defined in frozen documents have no open review comments
+// @llr REQ-TRAQ-SWL-103
+func (r *Req) checkReviewComments() []diagnostics.Issue {
+	issues := make([]diagnostics.Issue, 0)
+	if !r.Document.Frozen {
+		return issues
+	}
+	for _, comment := range r.OpenReviewComments() {
+		issues = append(issues, diagnostics.Issue{
+			Line:        comment.Line,
+			Path:        r.Document.Path,
+			RepoName:    r.RepoName,
+			Description: fmt.Sprintf("Requirement `%s` in frozen document `%s` has an open review comment by %s: %s", r.ID, r.Document.Path, comment.Author, comment.Comment),
+			Severity:    diagnostics.IssueSeverityMajor,
+			Type:        diagnostics.IssueTypeOpenReviewComment,
+		})
+	}
+	return issues
+}
+
 // TODO(ja): Make this more modular and resolve diagnostics at multiple levels (we already know some of these diagnostics just by parsing code)
 // Resolve walks the requirements graph and resolves the links between different levels of requirements
 // and with code tags. References to requirements within requirements text is checked as well as validity
 // of attributes against the schema for their document. Any errors encountered such as links to
 // non-existent requirements are returned in a list of issues.
-// @llr REQ-TRAQ-SWL-10, REQ-TRAQ-SWL-11, REQ-TRAQ-SWL-67, REQ-TRAQ-SWL-69
+// @llr REQ-TRAQ-SWL-10, REQ-TRAQ-SWL-11, REQ-TRAQ-SWL-67, REQ-TRAQ-SWL-69, REQ-TRAQ-SWL-91, REQ-TRAQ-SWL-95, REQ-TRAQ-SWL-103, REQ-TRAQ-SWL-106, REQ-TRAQ-SWL-110, REQ-TRAQ-SWL-115, REQ-TRAQ-SWL-120, REQ-TRAQ-SWL-126, REQ-TRAQ-SWL-131, REQ-TRAQ-SWL-133, REQ-TRAQ-SWL-143, REQ-TRAQ-SWL-145, REQ-TRAQ-SWL-146, REQ-TRAQ-SWL-152, REQ-TRAQ-SWL-154, REQ-TRAQ-SWL-166, REQ-TRAQ-SWL-180, REQ-TRAQ-SWL-191
 func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 	issues := make([]diagnostics.Issue, 0)
 
+	duplicateTextThreshold := 0.0
+	var attributeOrder []string
+	allocationAttribute := ""
+	if rg.ReqtraqConfig != nil {
+		duplicateTextThreshold = rg.ReqtraqConfig.DuplicateTextThreshold
+		attributeOrder = rg.ReqtraqConfig.AttributeOrder
+		allocationAttribute = rg.ReqtraqConfig.AllocationAttribute
+	}
+
+	reqIDs := rg.idRegexp()
+
 	// Walk the requirements, resolving links and looking for errors
 	for _, req := range rg.Reqs {
 		if req.IsDeleted() {
@@ -509,6 +964,12 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 		// Validate attributes
 		issues = append(issues, req.checkAttributes()...)
 		issues = append(issues, req.checkShallViolations()...)
+		issues = append(issues, req.checkReviewComments()...)
+		issues = append(issues, req.checkApproval()...)
+		issues = append(issues, req.checkImplementationStatus()...)
+		issues = append(issues, req.checkAttributeLayout(attributeOrder)...)
+		issues = append(issues, req.checkAllocationValue(allocationAttribute)...)
+		issues = append(issues, req.checkSatisfactionRationales()...)
 
 		// Validate parent links of requirements
 		for _, parentID := range req.ParentIds {
@@ -524,9 +985,15 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 						Type:        diagnostics.IssueTypeInvalidParent,
 					}
 					issues = append(issues, issue)
+				} else {
+					issues = append(issues, req.checkDuplicatedParentText(parent, duplicateTextThreshold)...)
 				}
 				if req.Variant == ReqVariantRequirement {
-					if description := req.validateLink(parent); description != "" {
+					description := rg.validateLinkDirection(req, parent)
+					if description == "" {
+						description = req.validateLink(parent)
+					}
+					if description != "" {
 						issue := diagnostics.Issue{
 							Line:        req.Position,
 							Path:        req.Document.Path,
@@ -538,6 +1005,16 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 						issues = append(issues, issue)
 					}
 				}
+			} else if repoName, ok := rg.externalRepoOf(parentID); ok {
+				issue := diagnostics.Issue{
+					Line:        req.Position,
+					Path:        req.Document.Path,
+					RepoName:    req.RepoName,
+					Description: fmt.Sprintf("Parent %s of requirement %s belongs to repository `%s`, which was not parsed.", parentID, req.ID, repoName),
+					Severity:    diagnostics.IssueSeverityNote,
+					Type:        diagnostics.IssueTypeExternalReference,
+				}
+				issues = append(issues, issue)
 			} else {
 				issue := diagnostics.Issue{
 					Line:        req.Position,
@@ -551,7 +1028,7 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 			}
 		}
 		// Validate references to requirements in body text
-		matches := reReqID.FindAllStringSubmatchIndex(req.Body, -1)
+		matches := reqIDs.FindAllStringIndex(req.Body, -1)
 		for _, ids := range matches {
 			reqID := req.Body[ids[0]:ids[1]]
 			v, reqFound := rg.Reqs[reqID]
@@ -620,6 +1097,7 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 
 	for _, tags := range rg.CodeTags {
 		for _, code := range tags {
+			first := len(issues)
 			parentIds := []string{}
 			if code.Symbol != "" {
 				parentIds = getParentIdsForSymbolInDocument(code.Document.Path, code.CodeFile.Type, code.Symbol)
@@ -667,6 +1145,8 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 							Type:     diagnostics.IssueTypeInvalidRequirementInCode,
 						}
 						issues = append(issues, issue)
+					} else {
+						issues = append(issues, rg.checkCodeAllocation(code, parent)...)
 					}
 
 					parent.Tags = append(parent.Tags, code)
@@ -683,28 +1163,32 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 					issues = append(issues, issue)
 				}
 			}
+			issues = append(issues, rg.checkCriterionLinks(code)...)
+
+			if len(code.Archs) > 0 {
+				archs := make([]string, 0, len(code.Archs))
+				for _, arch := range code.Archs {
+					archs = append(archs, string(arch))
+				}
+				for i := first; i < len(issues); i++ {
+					issues[i].Archs = append([]string(nil), archs...)
+				}
+			}
 		}
 	}
 
 	// Walk through the requirements one last time to ensure that if they are tested they are also implemented.
-	// We need to do it at this point, since now the links to the Tags are all set
+	// We need to do it at this point, since now the links to the Tags are all set. Assumptions are not
+	// implemented, the code referencing them checks them.
 	for _, req := range rg.Reqs {
-		if !req.Document.HasImplementation() || req.IsDeleted() {
+		if !req.Document.HasImplementation() || req.IsDeleted() || req.IsAssumption() {
 			continue
 		}
 
-		implemented := false
-		tested := false
-		for _, tag := range req.Tags {
-			if tag.CodeFile.Type.Matches(code.CodeTypeImplementation) {
-				implemented = true
-			}
-			if tag.CodeFile.Type.Matches(code.CodeTypeTests) {
-				tested = true
-			}
-			if implemented && tested {
-				break
-			}
+		implemented, tested := req.implementationStatus()
+		if progressIssues, tempered := req.implementationProgressIssues(implemented, tested); tempered {
+			issues = append(issues, progressIssues...)
+			continue
 		}
 
 		if !implemented {
@@ -771,8 +1255,19 @@ func (rg *ReqGraph) Resolve() []diagnostics.Issue {
 		}
 	}
 
+	// Now that code tags are linked, derive the computed attributes
+	issues = append(issues, rg.computeAttributes()...)
+
+	issues = append(issues, rg.checkForeignIDs()...)
+	issues = append(issues, rg.checkDuplicateTitles()...)
+	issues = append(issues, rg.checkDocumentLimits()...)
+	issues = append(issues, rg.checkAssumptionValidations()...)
+
+	// Finally, the project specific rules can rely on the resolved links and computed attributes
+	issues = append(issues, rg.checkRules()...)
+
 	if len(issues) > 0 {
-		return issues
+		return deduplicateIssues(issues)
 	}
 
 	return nil
@@ -807,23 +1302,180 @@ func (r *Req) Changelists() map[string]string {
 	return m
 }
 
+// IsAssumption checks if the requirement is an assumption, e.g. ASM-TRAQ-SWL-1
+// @llr REQ-TRAQ-SWL-110
+func (r *Req) IsAssumption() bool {
+	return r.Variant == ReqVariantAssumption
+}
+
 // IsDeleted checks if the requirement title starts with 'DELETED'
 // @llr REQ-TRAQ-SWL-23
 func (r *Req) IsDeleted() bool {
 	return strings.HasPrefix(r.Title, "DELETED")
 }
 
-// checkAttributes validates the requirement attributes against the schema from its document,
-// returns a list of issues found.
-// @llr REQ-TRAQ-SWL-10
-func (r *Req) checkAttributes() []diagnostics.Issue {
-	var schemaAttributes map[string]*config.Attribute
+// FlowsByPosition returns the flow tags which are not deleted, ordered by repository, document and position.
+// @llr REQ-TRAQ-SWL-114
+func (rg ReqGraph) FlowsByPosition() []*Flow {
+	var r []*Flow
+	for _, f := range rg.FlowTags {
+		if !f.Deleted {
+			r = append(r, f)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].RepoName != r[j].RepoName {
+			return r[i].RepoName < r[j].RepoName
+		}
+		if r[i].Document.Path != r[j].Document.Path {
+			return r[i].Document.Path < r[j].Document.Path
+		}
+		return r[i].Position < r[j].Position
+	})
+	return r
+}
+
+// FlowColumns returns the sorted names of the additional columns of the flow tags which are not deleted.
+// @llr REQ-TRAQ-SWL-114
+func (rg ReqGraph) FlowColumns() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range rg.FlowTags {
+		if f.Deleted {
+			continue
+		}
+		for name := range f.Attributes {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DiagramPath returns the path of the diagram of a flow tag in the repository of its document, empty if it has none
+// @llr REQ-TRAQ-SWL-185
+func (f *Flow) DiagramPath() string {
+	if f.Diagram == "" {
+		return ""
+	}
+	return path.Join(path.Dir(f.Document.Path), filepath.ToSlash(f.Diagram))
+}
+
+// checkDiagram returns an issue if the diagram referenced by a flow tag does not exist in its repository
+// @llr REQ-TRAQ-SWL-185
+func (f *Flow) checkDiagram() []diagnostics.Issue {
+	diagramPath := f.DiagramPath()
+	if diagramPath == "" {
+		return nil
+	}
+	if _, err := repos.PathInRepo(f.RepoName, diagramPath); err == nil {
+		return nil
+	}
+	return []diagnostics.Issue{{
+		Line:        f.Position,
+		Path:        f.Document.Path,
+		RepoName:    f.RepoName,
+		Description: fmt.Sprintf("Flow tag '%s' references the diagram '%s' which does not exist.", f.ID, diagramPath),
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeMissingFlowDiagram,
+	}}
+}
+
+// checkAttributes validates the values of the additional columns of a flow tag against the flow columns of the
+// schema of its document, returns a list of issues found.
+// @llr REQ-TRAQ-SWL-114
+func (f *Flow) checkAttributes(schemaAttributes map[string]*config.Attribute) []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	var anyAttributes []string
+	anyCount := 0
+	newIssue := func(issueType diagnostics.IssueType, format string, args ...interface{}) {
+		issues = append(issues, diagnostics.Issue{
+			Line:        f.Position,
+			Path:        f.Document.Path,
+			RepoName:    f.RepoName,
+			Description: fmt.Sprintf(format, args...),
+			Severity:    diagnostics.IssueSeverityMajor,
+			Type:        issueType,
+		})
+	}
+
+	names := make([]string, 0, len(schemaAttributes))
+	for name := range schemaAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attribute := schemaAttributes[name]
+		if attribute.Type == config.AttributeAny {
+			anyAttributes = append(anyAttributes, name)
+		}
+
+		value, present := f.Attributes[name]
+		present = present && value != ""
+		if !present && attribute.Type == config.AttributeRequired {
+			newIssue(diagnostics.IssueTypeMissingAttribute, "Flow tag '%s' is missing column '%s'.", f.ID, name)
+		} else if present {
+			if attribute.Type == config.AttributeAny {
+				anyCount++
+			}
+			if !attribute.Value.MatchString(value) {
+				newIssue(diagnostics.IssueTypeInvalidAttributeValue, "Flow tag '%s' has invalid value '%s' in column '%s'.", f.ID, value, name)
+			}
+		}
+	}
+
+	if len(anyAttributes) > 0 && anyCount == 0 {
+		newIssue(diagnostics.IssueTypeMissingAttribute, "Flow tag '%s' is missing at least one of the columns '%s'.", f.ID, strings.Join(anyAttributes, ","))
+	}
+
+	unknown := make([]string, 0)
+	for name := range f.Attributes {
+		if _, present := schemaAttributes[name]; !present {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		newIssue(diagnostics.IssueTypeUnknownAttribute, "Flow tag '%s' has unknown column '%s'.", f.ID, name)
+	}
+
+	return issues
+}
+
+// schemaAttributes returns the attributes of the schema of the document of the requirement, for its variant
+// @llr REQ-TRAQ-SWL-10, REQ-TRAQ-SWL-164
+func (r *Req) schemaAttributes() map[string]*config.Attribute {
 	switch r.Variant {
 	case ReqVariantRequirement:
-		schemaAttributes = r.Document.Schema.Attributes
+		return r.Document.Schema.Attributes
 	case ReqVariantAssumption:
-		schemaAttributes = r.Document.Schema.AsmAttributes
+		return r.Document.Schema.AsmAttributes
 	}
+	return nil
+}
+
+// MissingAttributes returns the uppercase names of the required attributes of the schema which the requirement does
+// not have, sorted
+// @llr REQ-TRAQ-SWL-164
+func (r *Req) MissingAttributes() []string {
+	var missing []string
+	for name, attribute := range r.schemaAttributes() {
+		if attribute.Type == config.AttributeRequired && r.Attributes[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// checkAttributes validates the requirement attributes against the schema from its document,
+// returns a list of issues found.
+// @llr REQ-TRAQ-SWL-10, REQ-TRAQ-SWL-115, REQ-TRAQ-SWL-145, REQ-TRAQ-SWL-154, REQ-TRAQ-SWL-165, REQ-TRAQ-SWL-180
+func (r *Req) checkAttributes() []diagnostics.Issue {
+	schemaAttributes := r.schemaAttributes()
 
 	var issues []diagnostics.Issue
 	var anyAttributes []string
@@ -838,7 +1490,19 @@ func (r *Req) checkAttributes() []diagnostics.Issue {
 		reqValue, reqValuePresent := r.Attributes[strings.ToUpper(name)]
 		reqValuePresent = reqValuePresent && reqValue != ""
 
-		if !reqValuePresent && attribute.Type == config.AttributeRequired {
+		if !reqValuePresent && attribute.Type == config.AttributeRequired && attribute.Since > r.SchemaVersion {
+			// The document was not migrated to the schema version introducing the attribute yet
+			issue := diagnostics.Issue{
+				Line:     r.Position,
+				Path:     r.Document.Path,
+				RepoName: r.RepoName,
+				Description: fmt.Sprintf("Requirement '%s' is missing attribute '%s' of schema version %d. Run reqtraq migrate to add it.",
+					r.ID, name, attribute.Since),
+				Severity: diagnostics.IssueSeverityNote,
+				Type:     diagnostics.IssueTypeMissingAttribute,
+			}
+			issues = append(issues, issue)
+		} else if !reqValuePresent && attribute.Type == config.AttributeRequired {
 			issue := diagnostics.Issue{
 				Line:        r.Position,
 				Path:        r.Document.Path,
@@ -880,9 +1544,11 @@ func (r *Req) checkAttributes() []diagnostics.Issue {
 		issues = append(issues, issue)
 	}
 
-	// Iterate the requirement attributes to check for unknown ones
+	// Iterate the requirement attributes to check for unknown ones, the approval hash, the foreign ID, the
+	// implementation status and the satisfaction rationales are allowed in any document
 	for name := range r.Attributes {
-		if _, present := schemaAttributes[strings.ToUpper(name)]; !present {
+		if _, present := schemaAttributes[strings.ToUpper(name)]; !present && name != ApprovedHashAttribute && name != ForeignIDAttribute &&
+			name != ImplementationStatusAttribute && !isSatisfactionAttribute(name) {
 			issue := diagnostics.Issue{
 				Line:        r.Position,
 				Path:        r.Document.Path,
@@ -898,6 +1564,78 @@ func (r *Req) checkAttributes() []diagnostics.Issue {
 	return issues
 }
 
+// idFormats returns the formats of the identifiers of the requirements of the graph, the default format for graphs
+// without configuration
+// @llr REQ-TRAQ-SWL-92
+func (rg *ReqGraph) idFormats() []*config.IDFormat {
+	if rg.ReqtraqConfig == nil {
+		return []*config.IDFormat{config.DefaultIDFormat}
+	}
+	return rg.ReqtraqConfig.IDFormats()
+}
+
+// idRegexp returns a regular expression matching the identifiers of the requirements following any of the formats of
+// the graph. Longer patterns come first, so that the most specific format wins when several of them match.
+// @llr REQ-TRAQ-SWL-11, REQ-TRAQ-SWL-92
+func (rg *ReqGraph) idRegexp() *regexp.Regexp {
+	var patterns []string
+	for _, format := range rg.idFormats() {
+		patterns = append(patterns, format.Pattern())
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	return regexp.MustCompile(strings.Join(patterns, "|"))
+}
+
+// externalRepoOf returns the repository declaring the prefix of the given requirement ID when it is one of the
+// children repositories which were not parsed, or one of the repositories other than OnlyRepo
+// @llr REQ-TRAQ-SWL-120, REQ-TRAQ-SWL-182
+func (rg *ReqGraph) externalRepoOf(reqID string) (repos.RepoName, bool) {
+	if rg.ReqtraqConfig == nil {
+		return "", false
+	}
+	var prefix config.ReqPrefix
+	for _, format := range rg.idFormats() {
+		if parts, ok := format.Find(reqID); ok && parts.ID == reqID && parts.Prefix != "" {
+			prefix = config.ReqPrefix(parts.Prefix)
+			break
+		}
+	}
+	if prefix == "" {
+		return "", false
+	}
+	if repoName, ok := rg.ReqtraqConfig.ExternalPrefixes[prefix]; ok {
+		return repoName, true
+	}
+	if OnlyRepo == "" {
+		return "", false
+	}
+	for repoName, repo := range rg.ReqtraqConfig.Repos {
+		if repoName == OnlyRepo {
+			continue
+		}
+		for _, doc := range repo.Documents {
+			if doc.ReqSpec.Prefix == prefix {
+				return repoName, true
+			}
+		}
+	}
+	return "", false
+}
+
+// validateLinkDirection checks that the parent of a requirement does not belong to a document below the
+// one of the requirement in the document hierarchy. Returns a description of the issue if it does.
+// @llr REQ-TRAQ-SWL-95
+func (rg *ReqGraph) validateLinkDirection(r *Req, parent *Req) string {
+	if rg.ReqtraqConfig == nil || parent.Document == nil {
+		return ""
+	}
+	if !rg.ReqtraqConfig.IsBelow(parent.Document.ReqSpec, r.Document.ReqSpec) {
+		return ""
+	}
+	return fmt.Sprintf("Requirement '%s' has parent '%s' from a lower level document. The expected hierarchy is `%s`.",
+		r.ID, parent.ID, rg.ReqtraqConfig.DescribeHierarchy(parent.Document.ReqSpec))
+}
+
 // validateLink iterates through the link options for the requirement and checks if the parent ID is valid
 // @llr REQ-TRAQ-SWL-76
 func (r *Req) validateLink(parent *Req) string {
@@ -933,53 +1671,64 @@ func (r *Req) validateLink(parent *Req) string {
 	return fmt.Sprintf("Requirement '%s' has invalid parent link ID '%s'.", r.ID, parent.ID)
 }
 
-// checkID verifies that the requirement is not duplicated
-// @llr REQ-TRAQ-SWL-25, REQ-TRAQ-SWL-26, REQ-TRAQ-SWL-28
+// checkID verifies that the requirement is not duplicated and that its ID follows the format of the document
+// @llr REQ-TRAQ-SWL-25, REQ-TRAQ-SWL-26, REQ-TRAQ-SWL-28, REQ-TRAQ-SWL-93
 func (r *Req) checkID(document *config.Document, expectedIDNumber int, isReqPresent []bool) []diagnostics.Issue {
 	var issues []diagnostics.Issue
-	reqIDComps := strings.Split(r.ID, "-") // results in an array such as [REQ PROJECT REQTYPE 1234]
-	// check requirement name, no need to check prefix because it would not have been parsed otherwise
-	if reqIDComps[1] != string(document.ReqSpec.Prefix) {
+	idFormat := document.ReqSpec.Format()
+	// no need to check the ID is found because it would not have been parsed otherwise
+	reqIDComps, _ := idFormat.Find(r.ID)
+	if idFormat.Has("PREFIX") && reqIDComps.Prefix != string(document.ReqSpec.Prefix) {
 		issue := diagnostics.Issue{
 			Line:        r.Position,
 			Path:        r.Document.Path,
 			RepoName:    r.RepoName,
-			Description: fmt.Sprintf("Incorrect project abbreviation for requirement %s. Expected %s, got %s.", r.ID, document.ReqSpec.Prefix, reqIDComps[1]),
+			Description: fmt.Sprintf("Incorrect project abbreviation for requirement %s. Expected %s, got %s.", r.ID, document.ReqSpec.Prefix, reqIDComps.Prefix),
 			Severity:    diagnostics.IssueSeverityMajor,
 			Type:        diagnostics.IssueTypeInvalidRequirementId,
 		}
 		issues = append(issues, issue)
 	}
-	if reqIDComps[2] != string(document.ReqSpec.Level) {
+	if idFormat.Has("LEVEL") && reqIDComps.Level != string(document.ReqSpec.Level) {
 		issue := diagnostics.Issue{
 			Line:        r.Position,
 			Path:        r.Document.Path,
 			RepoName:    r.RepoName,
-			Description: fmt.Sprintf("Incorrect requirement type for requirement %s. Expected %s, got %s.", r.ID, document.ReqSpec.Level, reqIDComps[2]),
+			Description: fmt.Sprintf("Incorrect requirement type for requirement %s. Expected %s, got %s.", r.ID, document.ReqSpec.Level, reqIDComps.Level),
 			Severity:    diagnostics.IssueSeverityMajor,
 			Type:        diagnostics.IssueTypeInvalidRequirementId,
 		}
 		issues = append(issues, issue)
 	}
-	if reqIDComps[3][0] == '0' {
+	if idFormat.Width() == 0 && reqIDComps.Number[0] == '0' {
+		issue := diagnostics.Issue{
+			Line:        r.Position,
+			Path:        r.Document.Path,
+			RepoName:    r.RepoName,
+			Description: fmt.Sprintf("Requirement number cannot begin with a 0: %s. Got %s.", r.ID, reqIDComps.Number),
+			Severity:    diagnostics.IssueSeverityMajor,
+			Type:        diagnostics.IssueTypeInvalidRequirementId,
+		}
+		issues = append(issues, issue)
+	} else if idFormat.Width() != 0 && len(reqIDComps.Number) != idFormat.Width() {
 		issue := diagnostics.Issue{
 			Line:        r.Position,
 			Path:        r.Document.Path,
 			RepoName:    r.RepoName,
-			Description: fmt.Sprintf("Requirement number cannot begin with a 0: %s. Got %s.", r.ID, reqIDComps[3]),
+			Description: fmt.Sprintf("Requirement number must have %d digits: %s. Got %s.", idFormat.Width(), r.ID, reqIDComps.Number),
 			Severity:    diagnostics.IssueSeverityMajor,
 			Type:        diagnostics.IssueTypeInvalidRequirementId,
 		}
 		issues = append(issues, issue)
 	}
 
-	currentID, err2 := strconv.Atoi(reqIDComps[3])
+	currentID, err2 := strconv.Atoi(reqIDComps.Number)
 	if err2 != nil {
 		issue := diagnostics.Issue{
 			Line:        r.Position,
 			Path:        r.Document.Path,
 			RepoName:    r.RepoName,
-			Description: fmt.Sprintf("Invalid requirement sequence number for %s (failed to parse): %s", r.ID, reqIDComps[3]),
+			Description: fmt.Sprintf("Invalid requirement sequence number for %s (failed to parse): %s", r.ID, reqIDComps.Number),
 			Severity:    diagnostics.IssueSeverityMajor,
 			Type:        diagnostics.IssueTypeInvalidRequirementId,
 		}
@@ -1107,7 +1856,7 @@ func (f ReqFilter) IsEmpty() bool {
 }
 
 // Matches returns true if the requirement matches the filter
-// @llr REQ-TRAQ-SWL-19, REQ-TRAQ-SWL-73
+// @llr REQ-TRAQ-SWL-19, REQ-TRAQ-SWL-73, REQ-TRAQ-SWL-91
 func (r *Req) Matches(filter *ReqFilter) bool {
 	if filter != nil {
 		if filter.IDRegexp != nil {
@@ -1134,13 +1883,19 @@ func (r *Req) Matches(filter *ReqFilter) bool {
 					break
 				}
 			}
+			for _, value := range r.ComputedAttributes {
+				if filter.AnyAttributeRegexp.MatchString(value) {
+					matches = true
+					break
+				}
+			}
 			if !matches {
 				return false
 			}
 		}
 		// Each of the filtered attributes must match.
 		for a, e := range filter.AttributeRegexp {
-			if !e.MatchString(r.Attributes[a]) {
+			if value, _ := r.Attribute(a); !e.MatchString(value) {
 				return false
 			}
 		}
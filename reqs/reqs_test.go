@@ -1,12 +1,17 @@
 package reqs
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/daedaleanai/reqtraq/code"
 	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
 	"github.com/daedaleanai/reqtraq/repos"
 	"github.com/stretchr/testify/assert"
 )
@@ -92,6 +97,37 @@ func TestReqGraph_OrdsByPosition(t *testing.T) {
 	assert.Equal(t, "REQ-TEST-SYS-1", reqs[1].ID)
 }
 
+// @llr REQ-TRAQ-SWL-121
+func TestReqGraph_OrdsByDocument(t *testing.T) {
+	sysA := config.Document{Path: "path/to/a.md"}
+	sysB := config.Document{Path: "path/to/b.md"}
+	rg := ReqGraph{Reqs: make(map[string]*Req)}
+	for _, r := range []*Req{
+		{ID: "REQ-B-SYS-1", RepoName: "repo", Position: 1, Document: &sysB},
+		{ID: "REQ-A-SYS-2", RepoName: "repo", Position: 5, Document: &sysA},
+		{ID: "REQ-A-SYS-1", RepoName: "repo", Position: 9, Document: &sysA},
+		{ID: "REQ-C-SYS-1", RepoName: "child", Position: 2, Document: &sysB},
+		{ID: "REQ-A-SYS-3", RepoName: "repo", Position: 12, Document: &sysA, ParentIds: []string{"REQ-A-SYS-1"}},
+	} {
+		rg.Reqs[r.ID] = r
+	}
+
+	var ids []string
+	for _, r := range rg.OrdsByPosition() {
+		ids = append(ids, r.ID)
+	}
+	assert.Equal(t, []string{"REQ-C-SYS-1", "REQ-A-SYS-2", "REQ-A-SYS-1", "REQ-B-SYS-1"}, ids)
+
+	groups := rg.OrdsByDocument()
+	assert.Len(t, groups, 3)
+	assert.Equal(t, repos.RepoName("child"), groups[0].RepoName)
+	assert.Equal(t, "path/to/b.md", groups[0].Path)
+	assert.Equal(t, "path/to/a.md", groups[1].Path)
+	assert.Equal(t, []*Req{rg.Reqs["REQ-A-SYS-2"], rg.Reqs["REQ-A-SYS-1"]}, groups[1].Reqs)
+	assert.Equal(t, repos.RepoName("repo"), groups[2].RepoName)
+	assert.Equal(t, "path/to/b.md", groups[2].Path)
+}
+
 // @llr REQ-TRAQ-SWL-20, REQ-TRAQ-SWL-21
 func TestReq_Significant(t *testing.T) {
 	tests := []struct {
@@ -246,3 +282,526 @@ func TestReq_IsDeleted(t *testing.T) {
 	req = Req{ID: "REQ-TEST-SYS-2", Title: "Deleted Requirements"}
 	assert.False(t, req.IsDeleted(), "Requirement with title %s should NOT have status DELETED", req.Title)
 }
+
+// @llr REQ-TRAQ-SWL-88
+func TestBuildGraph_ParallelAndFailFast(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata"))
+	repoName := repos.RepoName("testdata")
+	repos.RegisterRepository(repoName, repoPath)
+
+	newDocument := func(path string, prefix config.ReqPrefix) config.Document {
+		return config.Document{
+			Path:    path,
+			ReqSpec: config.ReqSpec{Prefix: prefix, Level: "SYS"},
+			Schema: config.Schema{
+				Requirements: regexp.MustCompile(fmt.Sprintf("REQ-%s-SYS-(\\d+)", prefix)),
+				Attributes:   make(map[string]*config.Attribute),
+			},
+		}
+	}
+	reqtraqConfig := config.Config{
+		Repos: map[repos.RepoName]config.RepoConfig{
+			repoName: {
+				Documents: []config.Document{
+					newDocument("valid_system_requirement/TEST-100-ORD.md", "TEST"),
+					newDocument("invalid_system_requirement/GAP1-100-ORD.md", "GAP1"),
+					newDocument("invalid_system_requirement/NAM1-100-ORD.md", "NAM1"),
+				},
+			},
+		},
+	}
+
+	sequential, err := BuildGraph(&reqtraqConfig)
+	assert.NoError(t, err)
+
+	ParallelBuild = true
+	parallel, err := BuildGraph(&reqtraqConfig)
+	ParallelBuild = false
+	assert.NoError(t, err)
+
+	// The issues found while resolving the graph are listed in the order its maps are walked in
+	sequential.Canonicalize()
+	parallel.Canonicalize()
+	assert.Equal(t, len(sequential.Reqs), len(parallel.Reqs))
+	assert.Equal(t, sequential.Issues, parallel.Issues)
+
+	FailFast = true
+	partial, err := BuildGraph(&reqtraqConfig)
+	FailFast = false
+	assert.NoError(t, err)
+
+	// Stops right after the document with the sequence errors, skipping the last one
+	assert.Equal(t, 2, len(partial.Issues))
+	assert.Contains(t, partial.Reqs, "REQ-GAP1-SYS-1")
+	assert.NotContains(t, partial.Reqs, "REQ-NAM1-SYS-1")
+}
+
+// @llr REQ-TRAQ-SWL-93
+func TestBuildGraph_CustomIDFormat(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/custom_id_format"))
+	repos.RegisterRepository(repos.RepoName("customIdFormat"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, rg.Reqs, "SRS_0001")
+	assert.Contains(t, rg.Reqs, "SRS_0002")
+	assert.NotContains(t, rg.Reqs, "SRS_003")
+
+	assert.Equal(t, []string{"SRS_0002"}, rg.Reqs["REQ-TEST-SWH-1"].ParentIds)
+	assert.Equal(t, []string{"REQ-TEST-SWH-1"}, rg.Reqs["REQ_TEST.SWL.1"].ParentIds)
+	assert.Equal(t, ReqVariantAssumption, rg.Reqs["ASM_TEST.SWL.1"].Variant)
+	assert.Equal(t, []string{"REQ_TEST.SWL.1"}, rg.Reqs["ASM_TEST.SWL.1"].ParentIds)
+
+	descriptions := []string{}
+	for _, issue := range rg.Issues {
+		descriptions = append(descriptions, issue.Description)
+	}
+	assert.ElementsMatch(t, []string{
+		"Requirement number must have 4 digits: SRS_003. Got 003.",
+		"Invalid requirement sequence number for REQ_TEST.SWL.3: missing requirements in between. Expected ID Number 2.",
+		"Invalid reference to non existent requirement REQ_TEST.SWL.2 in body of ASM_TEST.SWL.1.",
+	}, descriptions)
+}
+
+// @llr REQ-TRAQ-SWL-101
+func TestBuildGraph_Variants(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/variants"))
+	repos.RegisterRepository(repos.RepoName("variants"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { Variant = "" }()
+
+	// Without a variant the base requirements are used
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, rg.Issues)
+	assert.Contains(t, rg.Reqs["REQ-TEST-SWH-1"].Body, "The software shall boot in less than one second.")
+	assert.Nil(t, rg.Reqs["REQ-TEST-SWH-1"].Override)
+
+	Variant = "ACME"
+	rg, err = BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swh1 := rg.Reqs["REQ-TEST-SWH-1"]
+	assert.Contains(t, swh1.Body, "The software shall boot in less than five seconds.")
+	assert.Equal(t, "The ACME hardware is slower.", swh1.Attributes["RATIONALE"])
+	assert.Equal(t, []string{"REQ-TEST-SYS-2"}, swh1.ParentIds)
+	assert.Equal(t, "REQ-TEST-SYS-2", swh1.Parents[0].ID)
+	assert.Equal(t, &ReqOverride{Variant: "ACME", RepoName: "variants", Path: "acme/TEST-137-SRD.md", Position: 3}, swh1.Override)
+
+	// Requirements which are not overridden are left untouched
+	assert.Contains(t, rg.Reqs["REQ-TEST-SWH-2"].Body, "The software shall log the boot time.")
+	assert.Nil(t, rg.Reqs["REQ-TEST-SWH-2"].Override)
+
+	if assert.Len(t, rg.Issues, 1) {
+		assert.Equal(t, "Requirement 'REQ-TEST-SWH-3' overridden for variant 'ACME' does not exist in document 'TEST-137-SRD.md'.", rg.Issues[0].Description)
+		assert.Equal(t, "acme/TEST-137-SRD.md", rg.Issues[0].Path)
+	}
+
+	Variant = "UNKNOWN"
+	_, err = BuildGraph(&reqtraqConfig)
+	assert.EqualError(t, err, "Unknown variant `UNKNOWN`, the configured variants are [ACME]")
+}
+
+// @llr REQ-TRAQ-SWL-101
+func TestBuildGraph_VariantsOfFragmentedDocument(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/variants_fragments"))
+	repos.RegisterRepository(repos.RepoName("variants_fragments"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { Variant = "" }()
+
+	// The override is a single file, its requirements are not read from the fragments of the base document
+	Variant = "ACME"
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, rg.Reqs["REQ-TEST-SWL-2"].Body, "The logs shall be written to the flash memory.")
+	assert.Equal(t, &ReqOverride{Variant: "ACME", RepoName: "variants_fragments", Path: "acme/TEST-138-SDD.md", Position: 5},
+		rg.Reqs["REQ-TEST-SWL-2"].Override)
+	assert.Contains(t, rg.Reqs["REQ-TEST-SWL-1"].Body, "The configuration shall be logged as JSON.")
+	assert.Nil(t, rg.Reqs["REQ-TEST-SWL-1"].Override)
+
+	// The issues found while parsing the override are reported
+	if assert.Len(t, rg.Issues, 1) {
+		assert.Equal(t, "Review comment by jane in document `acme/TEST-138-SDD.md` is not within a requirement: Confirm the storage of the ACME hardware",
+			rg.Issues[0].Description)
+		assert.Equal(t, "acme/TEST-138-SDD.md", rg.Issues[0].Path)
+		assert.Equal(t, 3, rg.Issues[0].Line)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-95
+func TestReqGraph_ValidateLinkDirection(t *testing.T) {
+	newDocument := func(level config.ReqLevel, parent config.ReqLevel) config.Document {
+		doc := config.Document{
+			Path:    fmt.Sprintf("path/to/%s.md", level),
+			ReqSpec: config.ReqSpec{Prefix: "TEST", Level: level},
+			Schema: config.Schema{
+				Requirements: config.DefaultIDFormat.RequirementsRegexp("TEST", level),
+				Attributes:   map[string]*config.Attribute{"PARENTS": {Type: config.AttributeOptional, Value: regexp.MustCompile(".*")}},
+			},
+		}
+		if parent != "" {
+			doc.LinkSpecs = []config.LinkSpec{{
+				Child:  config.ReqSpec{Prefix: "TEST", Level: level, Re: config.DefaultIDFormat.LinkRegexp("TEST", level)},
+				Parent: config.ReqSpec{Prefix: "TEST", Level: parent, Re: config.DefaultIDFormat.LinkRegexp("TEST", parent)},
+			}}
+		}
+		return doc
+	}
+
+	reqtraqConfig := config.Config{Repos: map[repos.RepoName]config.RepoConfig{
+		"repo": {Documents: []config.Document{newDocument("SYS", ""), newDocument("SWH", "SYS"), newDocument("SWL", "SWH")}},
+	}}
+	docs := reqtraqConfig.Repos["repo"].Documents
+
+	rg := ReqGraph{Reqs: make(map[string]*Req), ReqtraqConfig: &reqtraqConfig}
+	for _, r := range []*Req{
+		{ID: "REQ-TEST-SWL-1", Document: &docs[2], Body: "Shall", ParentIds: []string{"REQ-TEST-SWH-1"}},
+		{ID: "REQ-TEST-SWH-1", Document: &docs[1], Body: "Shall", ParentIds: []string{"REQ-TEST-SYS-1"}},
+		{ID: "REQ-TEST-SYS-1", Document: &docs[0], Body: "Shall", ParentIds: []string{"REQ-TEST-SWL-1"}},
+	} {
+		r.Attributes = map[string]string{"PARENTS": strings.Join(r.ParentIds, ", ")}
+		rg.Reqs[r.ID] = r
+	}
+
+	issues := rg.Resolve()
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "Requirement 'REQ-TEST-SYS-1' has parent 'REQ-TEST-SWL-1' from a lower level document. "+
+		"The expected hierarchy is `TEST-SYS > TEST-SWH > TEST-SWL`.", issues[0].Description)
+}
+
+// @llr REQ-TRAQ-SWL-120
+func TestReqGraph_ExternalParents(t *testing.T) {
+	doc := config.Document{
+		Path:    "path/to/SWH.md",
+		ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"},
+		Schema: config.Schema{
+			Requirements: config.DefaultIDFormat.RequirementsRegexp("TEST", "SWH"),
+			Attributes:   map[string]*config.Attribute{"PARENTS": {Type: config.AttributeOptional, Value: regexp.MustCompile(".*")}},
+		},
+	}
+	reqtraqConfig := config.Config{
+		Repos:            map[repos.RepoName]config.RepoConfig{"repo": {Documents: []config.Document{doc}}},
+		ExternalPrefixes: map[config.ReqPrefix]repos.RepoName{"EXT": "child"},
+	}
+
+	r := &Req{ID: "REQ-TEST-SWH-1", Document: &doc, RepoName: "repo", Position: 3, Body: "Shall",
+		ParentIds: []string{"REQ-EXT-SYS-1", "REQ-MISSING-SYS-1"}}
+	r.Attributes = map[string]string{"PARENTS": strings.Join(r.ParentIds, ", ")}
+	rg := ReqGraph{Reqs: map[string]*Req{r.ID: r}, ReqtraqConfig: &reqtraqConfig}
+
+	assert.Equal(t, []diagnostics.Issue{
+		{
+			Line:        3,
+			Path:        "path/to/SWH.md",
+			RepoName:    "repo",
+			Description: "Parent REQ-EXT-SYS-1 of requirement REQ-TEST-SWH-1 belongs to repository `child`, which was not parsed.",
+			Severity:    diagnostics.IssueSeverityNote,
+			Type:        diagnostics.IssueTypeExternalReference,
+		},
+		{
+			Line:        3,
+			Path:        "path/to/SWH.md",
+			RepoName:    "repo",
+			Description: "Invalid parent of requirement REQ-TEST-SWH-1: REQ-MISSING-SYS-1 does not exist.",
+			Severity:    diagnostics.IssueSeverityMajor,
+			Type:        diagnostics.IssueTypeInvalidParent,
+		},
+	}, rg.Resolve())
+}
+
+// @llr REQ-TRAQ-SWL-159
+func TestReqGraph_DuplicateIDsAcrossRepos(t *testing.T) {
+	newDoc := func(path string) *config.Document {
+		return &config.Document{Path: path, ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+	}
+	newReq := func(title string, position int) *Req {
+		return &Req{ID: "REQ-TEST-SWH-1", IDNumber: 1, Variant: ReqVariantRequirement, Title: title, Position: position}
+	}
+	first, second := newReq("First", 3), newReq("Second", 7)
+	rg := &ReqGraph{Reqs: make(map[string]*Req)}
+	rg.addParsedCertdocToGraph("system", newDoc("TEST-137-SRD.md"), []*Req{first}, nil)
+	rg.addParsedCertdocToGraph("software", newDoc("path/to/TEST-137-SRD.md"), []*Req{second}, nil)
+
+	// The first definition is kept
+	assert.Same(t, first, rg.Reqs["REQ-TEST-SWH-1"])
+	assert.Equal(t, []diagnostics.Issue{
+		{
+			Line:        7,
+			Path:        "path/to/TEST-137-SRD.md",
+			RepoName:    "software",
+			Description: "Duplicate requirement ID REQ-TEST-SWH-1: defined in system:TEST-137-SRD.md:3 and in software:path/to/TEST-137-SRD.md:7.",
+			Severity:    diagnostics.IssueSeverityMajor,
+			Type:        diagnostics.IssueTypeInvalidRequirementId,
+		},
+	}, rg.Issues)
+}
+
+// @llr REQ-TRAQ-SWL-125
+func TestAmbiguousFileIssues(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"src/mixed.c":     "int mixed(void);\n",
+		"src/arm/board.c": "int board(void);\n",
+		"src/arm/test.c":  "// reqtraq:test-file\nint main(void);\n",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+	repos.RegisterRepository("ambiguous", repos.RepoPath(dir))
+
+	doc := config.Document{Path: "TEST-138-SDD.md", Implementation: []config.Implementation{{
+		ArchImplementation: config.ArchImplementation{CodeFiles: []string{"src/mixed.c"}, TestFiles: []string{"src/mixed.c"}},
+		Archs: map[config.Arch]config.ArchImplementation{
+			"arm": {CodeFiles: []string{"src/arm/board.c", "src/arm/test.c", "src/mixed.c"},
+				TestFiles: []string{"src/arm/board.c", "src/arm/test.c", "src/mixed.c"}},
+			"linux": {},
+		},
+	}}}
+
+	issues, err := ambiguousFileIssues("ambiguous", &doc)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "src/arm/board.c", issues[0].Path)
+	assert.Equal(t, diagnostics.IssueSeverityMinor, issues[0].Severity)
+	assert.Equal(t, diagnostics.IssueTypeAmbiguousCodeFile, issues[0].Type)
+	assert.Equal(t, "File `src/mixed.c` is matched both as code and as test of document `TEST-138-SDD.md`, mark it with "+
+		"`reqtraq:test-file` or `reqtraq:implementation-file` or list it in `testPaths` or `implementationPaths`.", issues[1].Description)
+	issues, err = ambiguousFileIssues("ambiguous", &config.Document{})
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+// @llr REQ-TRAQ-SWL-103
+func TestReq_CheckReviewComments(t *testing.T) {
+	doc := config.Document{Path: "path/to/SRD.md"}
+	comments := []ReviewComment{
+		{Author: "alice", Comment: "Too vague", Line: 12},
+		{Author: "bob", Comment: "Typo", Resolved: true, Line: 14},
+	}
+	r := Req{ID: "REQ-TEST-SWH-1", Document: &doc, RepoName: "repo", Position: 10, ReviewComments: comments}
+	other := Req{ID: "REQ-TEST-SWH-2", Document: &doc, RepoName: "repo", Position: 20, ReviewComments: comments[1:]}
+
+	assert.Equal(t, comments[:1], r.OpenReviewComments())
+	assert.Empty(t, other.OpenReviewComments())
+	rg := ReqGraph{Reqs: map[string]*Req{r.ID: &r, other.ID: &other}}
+	assert.Equal(t, []*Req{&r}, rg.ReqsWithOpenReviewComments())
+
+	// Open review comments are only issues in frozen documents
+	assert.Empty(t, r.checkReviewComments())
+	doc.Frozen = true
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        12,
+		Path:        "path/to/SRD.md",
+		RepoName:    "repo",
+		Description: "Requirement `REQ-TEST-SWH-1` in frozen document `path/to/SRD.md` has an open review comment by alice: Too vague",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeOpenReviewComment,
+	}}, r.checkReviewComments())
+	assert.Empty(t, other.checkReviewComments())
+}
+
+// @llr REQ-TRAQ-SWL-114
+func TestFlow_CheckAttributes(t *testing.T) {
+	doc := config.Document{Path: "path/to/SDD.md"}
+	schema := map[string]*config.Attribute{
+		"PROTOCOL": {Type: config.AttributeRequired, Value: regexp.MustCompile(`^(CAN|UART)$`)},
+		"RATE":     {Type: config.AttributeOptional, Value: regexp.MustCompile(`^\d+ Hz$`)},
+	}
+	valid := Flow{ID: "DF-TEST-1", Document: &doc, RepoName: "repo", Position: 5,
+		Attributes: map[string]string{"PROTOCOL": "CAN", "RATE": ""}}
+	assert.Empty(t, valid.checkAttributes(schema))
+
+	invalid := Flow{ID: "DF-TEST-2", Document: &doc, RepoName: "repo", Position: 6,
+		Attributes: map[string]string{"PROTOCOL": "", "RATE": "fast", "LATENCY": "1 ms"}}
+	var descriptions []string
+	for _, issue := range invalid.checkAttributes(schema) {
+		assert.Equal(t, 6, issue.Line)
+		assert.Equal(t, "path/to/SDD.md", issue.Path)
+		descriptions = append(descriptions, issue.Description)
+	}
+	assert.Equal(t, []string{
+		"Flow tag 'DF-TEST-2' is missing column 'PROTOCOL'.",
+		"Flow tag 'DF-TEST-2' has invalid value 'fast' in column 'RATE'.",
+		"Flow tag 'DF-TEST-2' has unknown column 'LATENCY'.",
+	}, descriptions)
+}
+
+// @llr REQ-TRAQ-SWL-185
+func TestFlow_CheckDiagram(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "diagrams"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "diagrams", "telemetry.puml"), []byte("@startuml\n@enduml\n"), 0644))
+	repos.RegisterRepository("diagrams", repos.RepoPath(dir))
+
+	flows, err := parseFlowTable(`| Caller | Flow Tag | Callee | Description | Diagram |
+| --- | --- | --- | --- | --- |
+| Sensor | CF-TEST-1 | Radio | Samples | diagrams/telemetry.puml |
+| Radio | CF-TEST-2 | Logger | Packets | diagrams/logging.drawio |
+| Logger | CF-TEST-3 | Disk | Records | |`, 10, nil, ControlFlowTable)
+	assert.NoError(t, err)
+	doc := config.Document{Path: "docs/TEST-138-SDD.md"}
+	for _, f := range flows {
+		f.Document = &doc
+		f.RepoName = "diagrams"
+		assert.Empty(t, f.Attributes)
+	}
+	assert.Equal(t, "docs/diagrams/telemetry.puml", flows[0].DiagramPath())
+	assert.Empty(t, flows[0].checkDiagram())
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        13,
+		Path:        "docs/TEST-138-SDD.md",
+		RepoName:    "diagrams",
+		Description: "Flow tag 'CF-TEST-2' references the diagram 'docs/diagrams/logging.drawio' which does not exist.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeMissingFlowDiagram,
+	}}, flows[1].checkDiagram())
+	assert.Empty(t, flows[2].DiagramPath())
+	assert.Empty(t, flows[2].checkDiagram())
+}
+
+// @llr REQ-TRAQ-SWL-105, REQ-TRAQ-SWL-110
+func TestBuildGraph_FileTags(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/filetags"))
+	repos.RegisterRepository(repos.RepoName("filetags"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The code files are not merged in a stable order
+	tags := map[string]*code.Code{}
+	for _, tag := range rg.CodeTags["filetags"] {
+		tags[tag.Tag] = tag
+	}
+	if assert.Len(t, tags, 3) {
+		assert.Equal(t, "config/logging.yaml", tags["logging.yaml"].Symbol)
+		assert.Equal(t, 1, tags["logging.yaml"].Line)
+		assert.False(t, tags["logging.yaml"].Optional)
+		assert.True(t, tags["logging.sh"].Optional)
+	}
+
+	swl1 := rg.Reqs["REQ-TEST-SWL-1"]
+	if assert.Len(t, swl1.Tags, 2) {
+		assert.ElementsMatch(t, []*code.Code{tags["logging.yaml"], tags["logging.sh"]}, swl1.Tags)
+		assert.Equal(t, code.ReqLink{Id: "REQ-TEST-SWL-1", Range: code.Range{
+			Start: code.Position{Line: 1, Character: 7},
+			End:   code.Position{Line: 1, Character: 21},
+		}}, tags["logging.yaml"].Links[0])
+	}
+	assert.Len(t, rg.Reqs["REQ-TEST-SWL-2"].Tags, 1)
+	// The assumption is checked by a test, without being reported as not implemented
+	assert.Equal(t, []*code.Code{tags["logdir.sh"]}, rg.Reqs["ASM-TEST-SWL-1"].Tags)
+	if assert.Len(t, rg.Issues, 1) {
+		assert.Equal(t, "Requirement REQ-TEST-SWL-2 is not tested.", rg.Issues[0].Description)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-130
+func TestBuildGraph_Fragments(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/fragments"))
+	repos.RegisterRepository(repos.RepoName("fragments"), repoPath)
+
+	reqtraqConfig, err := config.ParseConfig(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rg, err := BuildGraph(&reqtraqConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The requirements are numbered across the fragments of the documents
+	assert.Len(t, rg.Reqs, 5)
+	swh2 := rg.Reqs["REQ-TEST-SWH-2"]
+	assert.Equal(t, 6, swh2.Position)
+	path, line := swh2.Document.Locate(swh2.Position)
+	assert.Equal(t, "TEST-137-SRD/2-storage.md", path)
+	assert.Equal(t, 1, line)
+	assert.Equal(t, []string{"REQ-TEST-SWH-2"}, rg.Reqs["REQ-TEST-SWL-2"].ParentIds)
+
+	// The issues point to the fragment containing the requirement
+	if assert.Len(t, rg.Issues, 1) {
+		assert.Equal(t, "TEST-137-SRD/2-storage.md", rg.Issues[0].Path)
+		assert.Equal(t, 5, rg.Issues[0].Line)
+		assert.Equal(t, "Requirement `REQ-TEST-SWH-3` in document `TEST-137-SRD` does not contain a SHALL statement in its body", rg.Issues[0].Description)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-130
+func TestBuildGraph_FragmentsEdited(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, file := range []string{"reqtraq_config.json", "TEST-137-SRD/1-introduction.md", "TEST-137-SRD/2-storage.md",
+		"TEST-138-SDD/storage.md", "TEST-138-SDD/logging.md"} {
+		content, err := os.ReadFile(filepath.Join(string(repos.BaseRepoPath()), "testdata/fragments", file))
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repoPath, file)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, file), content, 0644))
+	}
+	repos.RegisterRepository(repos.RepoName("fragments"), repos.RepoPath(repoPath))
+	reqtraqConfig, err := config.ParseConfig(repos.RepoPath(repoPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = BuildGraph(&reqtraqConfig)
+	assert.NoError(t, err)
+
+	// The graph rebuilt with the same configuration after editing the first fragment counts its new lines
+	introduction := filepath.Join(repoPath, "TEST-137-SRD/1-introduction.md")
+	content, err := os.ReadFile(introduction)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(introduction, append([]byte("<!-- Draft -->\n\n"), content...), 0644))
+	rg, err := BuildGraph(&reqtraqConfig)
+	assert.NoError(t, err)
+	swh2 := rg.Reqs["REQ-TEST-SWH-2"]
+	path, line := swh2.Document.Locate(swh2.Position)
+	assert.Equal(t, "TEST-137-SRD/2-storage.md", path)
+	assert.Equal(t, 1, line)
+	if assert.Len(t, rg.Issues, 1) {
+		assert.Equal(t, "TEST-137-SRD/2-storage.md", rg.Issues[0].Path)
+		assert.Equal(t, 5, rg.Issues[0].Line)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-148
+func TestSkippedFileIssues(t *testing.T) {
+	doc := config.Document{Path: "TEST-138-SDD.md"}
+	skipped := []code.SkippedFile{{
+		CodeFile: code.CodeFile{RepoName: "repo", Path: "src/table.c", Type: code.CodeTypeImplementation},
+		Reason:   "it is larger than 4194304 bytes",
+	}}
+
+	assert.Equal(t, []diagnostics.Issue{{
+		Path:        "src/table.c",
+		RepoName:    "repo",
+		Description: "File `src/table.c` of the implementation of document `TEST-138-SDD.md` was not parsed because it is larger than 4194304 bytes.",
+		Severity:    diagnostics.IssueSeverityMinor,
+		Type:        diagnostics.IssueTypeSkippedCodeFile,
+	}}, skippedFileIssues(&doc, skipped))
+	assert.Empty(t, skippedFileIssues(&doc, nil))
+}
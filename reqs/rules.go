@@ -0,0 +1,71 @@
+package reqs
+
+import (
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// A project specific validation rule, e.g. a naming convention or forbidden parent pairs. The rules are checked
+// after the built-in checks of the graph, and the issues they return are reported as rule violations.
+type Rule interface {
+	Check(rg *ReqGraph) []diagnostics.Issue
+}
+
+// The validation rules by name. Rules are registered by the init functions of packages compiled in with build tags
+// or of the plugins listed in the configuration.
+var rules = map[string]Rule{}
+
+// RegisterRule registers a validation rule with the given name, replacing any rule with the same name
+// @llr REQ-TRAQ-SWL-131
+func RegisterRule(name string, rule Rule) {
+	rules[name] = rule
+}
+
+// RegisteredRules returns the names of the registered validation rules, sorted
+// @llr REQ-TRAQ-SWL-131
+func RegisteredRules() []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadRulePlugins opens the Go plugins declared in the configuration, which register their rules when loaded.
+// Relative paths are resolved in the repository declaring the plugin.
+// @llr REQ-TRAQ-SWL-131
+func LoadRulePlugins(plugins []config.RulePlugin) error {
+	for _, p := range plugins {
+		path := p.Path
+		if !filepath.IsAbs(path) {
+			var err error
+			if path, err = repos.PathInRepo(p.RepoName, p.Path); err != nil {
+				return errors.Wrapf(err, "Rule plugin `%s` declared in config for repo `%s` cannot be found", p.Path, p.RepoName)
+			}
+		}
+		if _, err := plugin.Open(path); err != nil {
+			return errors.Wrapf(err, "Rule plugin `%s` declared in config for repo `%s` cannot be loaded", p.Path, p.RepoName)
+		}
+	}
+	return nil
+}
+
+// checkRules checks the graph with the registered validation rules, in the order of their names
+// @llr REQ-TRAQ-SWL-131
+func (rg *ReqGraph) checkRules() []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	for _, name := range RegisteredRules() {
+		for _, issue := range rules[name].Check(rg) {
+			issue.Type = diagnostics.IssueTypeRuleViolation
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,68 @@
+package reqs
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// titleRule reports the requirements whose title is not capitalized
+type titleRule struct{}
+
+// @llr REQ-TRAQ-SWL-131
+func (titleRule) Check(rg *ReqGraph) []diagnostics.Issue {
+	var issues []diagnostics.Issue
+	for _, r := range rg.Reqs {
+		if r.Title != "" && strings.ToUpper(r.Title[:1]) != r.Title[:1] {
+			issues = append(issues, diagnostics.Issue{
+				Line:        r.Position,
+				Path:        r.Document.Path,
+				RepoName:    r.RepoName,
+				Description: fmt.Sprintf("Title of requirement %s is not capitalized", r.ID),
+				Severity:    diagnostics.IssueSeverityMinor,
+			})
+		}
+	}
+	return issues
+}
+
+// @llr REQ-TRAQ-SWL-131
+func TestReqGraph_CheckRules(t *testing.T) {
+	RegisterRule("title", titleRule{})
+	defer delete(rules, "title")
+	assert.Contains(t, RegisteredRules(), "title")
+
+	rg := ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Title: "Logging", Document: &config.Document{Path: "TEST-137-SRD.md"}},
+		"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", Title: "storage", Position: 7, Document: &config.Document{Path: "TEST-137-SRD.md"}},
+	}}
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        7,
+		Path:        "TEST-137-SRD.md",
+		Description: "Title of requirement REQ-TEST-SWH-2 is not capitalized",
+		Severity:    diagnostics.IssueSeverityMinor,
+		Type:        diagnostics.IssueTypeRuleViolation,
+	}}, rg.checkRules())
+}
+
+// @llr REQ-TRAQ-SWL-131
+func TestLoadRulePlugins(t *testing.T) {
+	repoPath := repos.RepoPath(filepath.Join(string(repos.BaseRepoPath()), "testdata/criteria"))
+	repos.RegisterRepository(repos.RepoName("criteria"), repoPath)
+
+	assert.NoError(t, LoadRulePlugins(nil))
+	err := LoadRulePlugins([]config.RulePlugin{{Path: "rules/naming.so", RepoName: "criteria"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Rule plugin `rules/naming.so` declared in config for repo `criteria` cannot be found")
+	}
+	err = LoadRulePlugins([]config.RulePlugin{{Path: "TEST-137-SRD.md", RepoName: "criteria"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Rule plugin `TEST-137-SRD.md` declared in config for repo `criteria` cannot be loaded")
+	}
+}
@@ -0,0 +1,163 @@
+package reqs
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SampleStratifyByDocument stratifies a sample by the document of the requirements, rather than by an attribute
+const SampleStratifyByDocument = "document"
+
+// SampleWeight is the weight given in a sample to the requirements with an attribute matching a pattern, e.g. to
+// draw the requirements with a high safety impact more often
+type SampleWeight struct {
+	// The uppercase name of the attribute
+	Attribute string
+	// The pattern the value of the attribute must match
+	Value  *regexp.Regexp
+	Weight float64
+}
+
+// ParseSampleWeight parses a weight written as `ATTRIBUTE=REGEXP:WEIGHT`, e.g. `Safety Impact=High:3`
+// @llr REQ-TRAQ-SWL-157
+func ParseSampleWeight(s string) (SampleWeight, error) {
+	separator := strings.LastIndex(s, ":")
+	equal := strings.Index(s, "=")
+	if equal <= 0 || separator < equal {
+		return SampleWeight{}, fmt.Errorf("invalid weight `%s`, expected `ATTRIBUTE=REGEXP:WEIGHT`", s)
+	}
+	weight, err := strconv.ParseFloat(s[separator+1:], 64)
+	if err != nil || weight <= 0 || math.IsInf(weight, 0) {
+		return SampleWeight{}, fmt.Errorf("invalid weight `%s`, expected a positive number after the last `:`", s)
+	}
+	value, err := regexp.Compile(s[equal+1 : separator])
+	if err != nil {
+		return SampleWeight{}, fmt.Errorf("invalid weight `%s`: %v", s, err)
+	}
+	return SampleWeight{Attribute: strings.ToUpper(s[:equal]), Value: value, Weight: weight}, nil
+}
+
+// SampleOptions configures the sampling of the requirements
+type SampleOptions struct {
+	// The number of requirements to draw
+	Size int
+	// The seed of the random draw, the same seed drawing the same sample out of the same requirements
+	Seed int64
+	// The requirements are drawn separately from each document with SampleStratifyByDocument, or from each value of
+	// the attribute with this name otherwise. The sample is not stratified when empty.
+	StratifyBy string
+	// The weights of the requirements, which is 1 for the requirements matching none of them
+	Weights []SampleWeight
+}
+
+// SampleStratum holds the requirements drawn from a stratum of the requirements
+type SampleStratum struct {
+	// The document or the value of the attribute of the requirements of the stratum, empty if not stratified
+	Name string
+	// The number of requirements of the stratum
+	Population int
+	// The requirements drawn, ordered by ID
+	Reqs []*Req
+}
+
+// weight returns the weight of the requirement in a sample, the largest of the weights it matches
+// @llr REQ-TRAQ-SWL-157
+func (o SampleOptions) weight(r *Req) float64 {
+	weight := 0.0
+	for _, w := range o.Weights {
+		if value, ok := r.Attribute(w.Attribute); ok && w.Value.MatchString(value) && w.Weight > weight {
+			weight = w.Weight
+		}
+	}
+	if weight == 0 {
+		return 1
+	}
+	return weight
+}
+
+// stratum returns the name of the stratum of the requirement
+// @llr REQ-TRAQ-SWL-157
+func (o SampleOptions) stratum(r *Req) string {
+	switch o.StratifyBy {
+	case "":
+		return ""
+	case SampleStratifyByDocument:
+		if r.Document == nil {
+			return ""
+		}
+		return r.Document.Path
+	}
+	value, _ := r.Attribute(strings.ToUpper(o.StratifyBy))
+	return value
+}
+
+// Sample draws a reproducible random sample of the requirements matching the filter, excluding the deleted ones, for
+// spot checks of the trace. Each stratum receives a share of the sample proportional to its population, with the
+// largest remainders rounded up, and its requirements are drawn without replacement with a probability proportional
+// to their weight. The strata are returned ordered by name.
+// @llr REQ-TRAQ-SWL-157
+func (rg *ReqGraph) Sample(filter *ReqFilter, options SampleOptions) []SampleStratum {
+	population := make(map[string][]*Req)
+	total := 0
+	for _, r := range rg.Reqs {
+		if r.Stub || r.IsDeleted() || (filter != nil && !r.Matches(filter)) {
+			continue
+		}
+		name := options.stratum(r)
+		population[name] = append(population[name], r)
+		total++
+	}
+
+	strata := make([]SampleStratum, 0, len(population))
+	for name, reqs := range population {
+		sort.Slice(reqs, func(i, j int) bool { return reqs[i].ID < reqs[j].ID })
+		strata = append(strata, SampleStratum{Name: name, Population: len(reqs)})
+	}
+	sort.Slice(strata, func(i, j int) bool { return strata[i].Name < strata[j].Name })
+	if total == 0 {
+		return strata
+	}
+
+	// Largest remainder allocation of the sample to the strata
+	size := options.Size
+	if size > total {
+		size = total
+	}
+	shares := make([]int, len(strata))
+	remainders := make([]int, len(strata))
+	allocated := 0
+	for i, stratum := range strata {
+		shares[i] = size * stratum.Population / total
+		remainders[i] = size * stratum.Population % total
+		allocated += shares[i]
+	}
+	order := make([]int, len(strata))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return remainders[order[i]] > remainders[order[j]] })
+	for _, i := range order[:size-allocated] {
+		shares[i]++
+	}
+
+	// Weighted sampling without replacement, keeping the requirements with the largest keys u^(1/weight)
+	random := rand.New(rand.NewSource(options.Seed))
+	for i := range strata {
+		reqs := population[strata[i].Name]
+		keys := make(map[*Req]float64, len(reqs))
+		for _, r := range reqs {
+			keys[r] = math.Pow(random.Float64(), 1/options.weight(r))
+		}
+		drawn := append([]*Req(nil), reqs...)
+		sort.SliceStable(drawn, func(a, b int) bool { return keys[drawn[a]] > keys[drawn[b]] })
+		drawn = drawn[:shares[i]]
+		sort.Slice(drawn, func(a, b int) bool { return drawn[a].ID < drawn[b].ID })
+		strata[i].Reqs = drawn
+	}
+	return strata
+}
@@ -0,0 +1,101 @@
+package reqs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleTestGraph returns a graph of 10 requirements in a first document and 30 in a second one, the first
+// requirements of each document having a high safety impact, and a deleted requirement
+// @llr REQ-TRAQ-SWL-157
+func sampleTestGraph() *ReqGraph {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md"}
+	rg := &ReqGraph{Reqs: make(map[string]*Req)}
+	add := func(doc *config.Document, prefix string, count int) {
+		for i := 1; i <= count; i++ {
+			impact := "Low"
+			if i <= 2 {
+				impact = "High"
+			}
+			id := fmt.Sprintf("%s-%02d", prefix, i)
+			rg.Reqs[id] = &Req{ID: id, Title: id, Document: doc, Attributes: map[string]string{"SAFETY IMPACT": impact}}
+		}
+	}
+	add(&srd, "REQ-TEST-SWH", 10)
+	add(&sdd, "REQ-TEST-SWL", 30)
+	rg.Reqs["REQ-TEST-SWL-99"] = &Req{ID: "REQ-TEST-SWL-99", Title: "DELETED", Document: &sdd}
+	return rg
+}
+
+// @llr REQ-TRAQ-SWL-157
+func TestReqGraph_Sample(t *testing.T) {
+	rg := sampleTestGraph()
+	ids := func(strata []SampleStratum) []string {
+		var ids []string
+		for _, stratum := range strata {
+			for _, r := range stratum.Reqs {
+				ids = append(ids, r.ID)
+			}
+		}
+		return ids
+	}
+
+	// The same seed draws the same sample
+	sample := rg.Sample(nil, SampleOptions{Size: 8, Seed: 42})
+	if assert.Len(t, sample, 1) {
+		assert.Equal(t, 40, sample[0].Population)
+		assert.Len(t, sample[0].Reqs, 8)
+	}
+	assert.Equal(t, ids(sample), ids(rg.Sample(nil, SampleOptions{Size: 8, Seed: 42})))
+	assert.NotEqual(t, ids(sample), ids(rg.Sample(nil, SampleOptions{Size: 8, Seed: 43})))
+	assert.NotContains(t, ids(rg.Sample(nil, SampleOptions{Size: 100, Seed: 42})), "REQ-TEST-SWL-99")
+
+	// The sample is shared between the documents in proportion to their requirements
+	sample = rg.Sample(nil, SampleOptions{Size: 6, Seed: 42, StratifyBy: SampleStratifyByDocument})
+	if assert.Len(t, sample, 2) {
+		assert.Equal(t, "TEST-137-SRD.md", sample[0].Name)
+		assert.Equal(t, 10, sample[0].Population)
+		assert.Len(t, sample[0].Reqs, 2)
+		assert.Equal(t, "TEST-138-SDD.md", sample[1].Name)
+		assert.Len(t, sample[1].Reqs, 4)
+	}
+	sample = rg.Sample(nil, SampleOptions{Size: 5, Seed: 42, StratifyBy: "Safety Impact"})
+	if assert.Len(t, sample, 2) {
+		assert.Equal(t, "High", sample[0].Name)
+		assert.Len(t, sample[0].Reqs, 1)
+		assert.Equal(t, "Low", sample[1].Name)
+		assert.Len(t, sample[1].Reqs, 4)
+	}
+
+	// Heavily weighted requirements are drawn first, the filter restricts the population
+	weight, err := ParseSampleWeight("Safety Impact=High:1000000")
+	assert.NoError(t, err)
+	sample = rg.Sample(nil, SampleOptions{Size: 4, Seed: 42, Weights: []SampleWeight{weight}})
+	assert.Equal(t, []string{"REQ-TEST-SWH-01", "REQ-TEST-SWH-02", "REQ-TEST-SWL-01", "REQ-TEST-SWL-02"}, ids(sample))
+
+	filter, err := CreateFilter("SWH", "", "", nil)
+	assert.NoError(t, err)
+	sample = rg.Sample(&filter, SampleOptions{Size: 20, Seed: 42})
+	if assert.Len(t, sample, 1) {
+		assert.Equal(t, 10, sample[0].Population)
+		assert.Len(t, sample[0].Reqs, 10)
+	}
+}
+
+// @llr REQ-TRAQ-SWL-157
+func TestParseSampleWeight(t *testing.T) {
+	weight, err := ParseSampleWeight("Safety Impact=High|Catastrophic:2.5")
+	assert.NoError(t, err)
+	assert.Equal(t, "SAFETY IMPACT", weight.Attribute)
+	assert.Equal(t, "High|Catastrophic", weight.Value.String())
+	assert.Equal(t, 2.5, weight.Weight)
+
+	for _, invalid := range []string{"Safety Impact:2", "=High:2", "Safety Impact=High", "Safety Impact=High:0", "Safety Impact=(:2"} {
+		_, err := ParseSampleWeight(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,72 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// The prefix of the attributes of a requirement explaining how it satisfies one of its parents, followed by the ID
+// of the parent, e.g. `Satisfies REQ-TEST-SWH-1`
+const SatisfactionAttributePrefix = "SATISFIES "
+
+// isSatisfactionAttribute returns true if the given uppercase attribute name is the satisfaction rationale of a
+// link to a parent
+// @llr REQ-TRAQ-SWL-154
+func isSatisfactionAttribute(name string) bool {
+	return strings.HasPrefix(name, SatisfactionAttributePrefix)
+}
+
+// SatisfactionRationale returns how the requirement satisfies the given parent, empty if it is not explained
+// @llr REQ-TRAQ-SWL-154
+func (r *Req) SatisfactionRationale(parentID string) string {
+	return strings.TrimSpace(r.Attributes[SatisfactionAttributePrefix+parentID])
+}
+
+// checkSatisfactionRationales reports the satisfaction rationales which are empty or explain the link to a
+// requirement which is not a parent of the requirement
+// @llr REQ-TRAQ-SWL-154
+func (r *Req) checkSatisfactionRationales() []diagnostics.Issue {
+	var names []string
+	for name := range r.Attributes {
+		if isSatisfactionAttribute(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var issues []diagnostics.Issue
+	for _, name := range names {
+		parentID := strings.TrimSpace(strings.TrimPrefix(name, SatisfactionAttributePrefix))
+		description := ""
+		if !r.hasParent(parentID) {
+			description = fmt.Sprintf("Requirement '%s' explains how it satisfies '%s', which is not one of its parents.", r.ID, parentID)
+		} else if r.SatisfactionRationale(parentID) == "" {
+			description = fmt.Sprintf("Requirement '%s' has an empty rationale for satisfying '%s'.", r.ID, parentID)
+		}
+		if description != "" {
+			issues = append(issues, diagnostics.Issue{
+				Line:        r.Position,
+				Path:        r.Document.Path,
+				RepoName:    r.RepoName,
+				Description: description,
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidAttributeValue,
+			})
+		}
+	}
+	return issues
+}
+
+// hasParent returns true if the given requirement is listed in the parents of the requirement
+// @llr REQ-TRAQ-SWL-154
+func (r *Req) hasParent(parentID string) bool {
+	for _, id := range r.ParentIds {
+		if id == parentID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-154
+func TestReq_SatisfactionRationales(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	r := &Req{ID: "REQ-TEST-SWL-1", Document: doc, RepoName: "repo", Position: 3,
+		ParentIds: []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2"},
+		Attributes: map[string]string{
+			"SATISFIES REQ-TEST-SWH-1": " Rotates the logs daily. ",
+			"SATISFIES REQ-TEST-SWH-2": "",
+			"SATISFIES REQ-TEST-SWH-3": "Not a parent.",
+		}}
+
+	assert.Equal(t, "Rotates the logs daily.", r.SatisfactionRationale("REQ-TEST-SWH-1"))
+	assert.Empty(t, r.SatisfactionRationale("REQ-TEST-SWH-2"))
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:        3,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Requirement 'REQ-TEST-SWL-1' has an empty rationale for satisfying 'REQ-TEST-SWH-2'.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInvalidAttributeValue,
+	}, {
+		Line:        3,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Requirement 'REQ-TEST-SWL-1' explains how it satisfies 'REQ-TEST-SWH-3', which is not one of its parents.",
+		Severity:    diagnostics.IssueSeverityMajor,
+		Type:        diagnostics.IssueTypeInvalidAttributeValue,
+	}}, r.checkSatisfactionRationales())
+
+	// The rationales are allowed in any document
+	r.Attributes = map[string]string{"SATISFIES REQ-TEST-SWH-1": "Rotates the logs daily."}
+	assert.Empty(t, r.checkAttributes())
+}
@@ -0,0 +1,182 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// The criteria making up the completeness score, in the order in which they are listed
+const (
+	ScoreImplemented = "implemented"
+	ScoreTested      = "tested"
+	ScoreReviewed    = "reviewed"
+	ScoreNoIssues    = "no issues"
+)
+
+// ScoreCriterion is the contribution of a criterion to a completeness score
+type ScoreCriterion struct {
+	Name   string
+	Weight float64
+	// Number of requirements the criterion applies to
+	Applicable int
+	// Number of the applicable requirements meeting the criterion
+	Met int
+}
+
+// Score is the weighted completeness of the requirements of a document, or of all the documents
+type Score struct {
+	// The document the score is computed for, empty for the score of all the documents
+	RepoName repos.RepoName `json:",omitempty"`
+	Path     string         `json:",omitempty"`
+	// Number of requirements which are not deleted
+	Requirements int
+	Criteria     []ScoreCriterion
+	// Average of the ratios of requirements meeting each criterion weighted by the criteria weights, in percent
+	Value float64
+}
+
+// Ratio returns the ratio of the applicable requirements meeting the criterion, 1 when it applies to none
+// @llr REQ-TRAQ-SWL-111
+func (c ScoreCriterion) Ratio() float64 {
+	if c.Applicable == 0 {
+		return 1
+	}
+	return float64(c.Met) / float64(c.Applicable)
+}
+
+// newScore returns an empty score with the criteria having the given weights
+// @llr REQ-TRAQ-SWL-111
+func newScore(repoName repos.RepoName, path string, weights config.ScoreWeights) *Score {
+	return &Score{
+		RepoName: repoName,
+		Path:     path,
+		Criteria: []ScoreCriterion{
+			{Name: ScoreImplemented, Weight: weights.Implemented},
+			{Name: ScoreTested, Weight: weights.Tested},
+			{Name: ScoreReviewed, Weight: weights.Reviewed},
+			{Name: ScoreNoIssues, Weight: weights.NoIssues},
+		},
+	}
+}
+
+// count records whether a requirement meets the criterion with the given index, if the criterion applies to it
+// @llr REQ-TRAQ-SWL-111
+func (s *Score) count(criterion int, applicable, met bool) {
+	if applicable {
+		s.Criteria[criterion].Applicable++
+		if met {
+			s.Criteria[criterion].Met++
+		}
+	}
+}
+
+// computeValue computes the weighted average of the criteria which apply to at least one requirement
+// @llr REQ-TRAQ-SWL-111
+func (s *Score) computeValue() {
+	weightedRatios, weights := 0.0, 0.0
+	for _, criterion := range s.Criteria {
+		if criterion.Weight > 0 && criterion.Applicable > 0 {
+			weightedRatios += criterion.Weight * criterion.Ratio()
+			weights += criterion.Weight
+		}
+	}
+	s.Value = 100
+	if weights > 0 {
+		s.Value = 100 * weightedRatios / weights
+	}
+}
+
+// String returns the value of the score followed by its composition, e.g.
+// `75.0% (implemented 100.0% x1, tested 50.0% x1, ...)`
+// @llr REQ-TRAQ-SWL-111
+func (s Score) String() string {
+	var criteria []string
+	for _, criterion := range s.Criteria {
+		if criterion.Weight > 0 && criterion.Applicable > 0 {
+			criteria = append(criteria, fmt.Sprintf("%s %.1f%% x%g", criterion.Name, 100*criterion.Ratio(), criterion.Weight))
+		}
+	}
+	return fmt.Sprintf("%.1f%% (%s)", s.Value, strings.Join(criteria, ", "))
+}
+
+// Scores computes the completeness score of each document with requirements, ordered by repository and path, and
+// the score of all the documents together. The requirements which are not deleted count for each criterion which
+// applies to them:
+//   - implemented: requirements with children requirements or, in documents with implementation, with
+//     implementation code. It does not apply to assumptions.
+//   - tested: requirements of documents with implementation which have test code. It does not apply to
+//     assumptions.
+//   - reviewed: requirements without open review comments and, when the code reviews are checked, whose
+//     implementation was last changed by accepted commits.
+//   - no issues: requirements without critical issues, lint messages are not counted.
+//
+// The weights of the criteria are configured in the target repository.
+// @llr REQ-TRAQ-SWL-111, REQ-TRAQ-SWL-139
+func (rg *ReqGraph) Scores() (Score, []Score) {
+	weights := config.DefaultScoreWeights
+	if rg.ReqtraqConfig != nil {
+		weights = rg.ReqtraqConfig.ScoreWeights
+	}
+
+	withIssues := make(map[string]bool)
+	index := rg.IssueRequirements()
+	for _, issue := range rg.Issues {
+		if issue.Severity == diagnostics.IssueSeverityNote {
+			continue
+		}
+		for _, r := range index[LocationOf(issue)] {
+			withIssues[r.ID] = true
+		}
+	}
+
+	overall := newScore("", "", weights)
+	byDocument := make(map[IssueLocation]*Score)
+	for _, r := range rg.Reqs {
+		if r.IsDeleted() || r.Document == nil {
+			continue
+		}
+		key := IssueLocation{RepoName: r.RepoName, Path: r.Document.Path}
+		if _, ok := byDocument[key]; !ok {
+			byDocument[key] = newScore(r.RepoName, r.Document.Path, weights)
+		}
+
+		hasImplementation := r.Document.HasImplementation()
+		implemented, tested := r.implementationStatus()
+		if !hasImplementation {
+			implemented = false
+			for _, child := range r.Children {
+				if !child.IsDeleted() {
+					implemented = true
+					break
+				}
+			}
+		}
+
+		for _, score := range []*Score{overall, byDocument[key]} {
+			score.Requirements++
+			score.count(0, !r.IsAssumption(), implemented)
+			score.count(1, !r.IsAssumption() && hasImplementation, tested)
+			score.count(2, true, len(r.OpenReviewComments()) == 0 && r.CodeReviewed())
+			score.count(3, true, !withIssues[r.ID])
+		}
+	}
+
+	documents := make([]Score, 0, len(byDocument))
+	for _, score := range byDocument {
+		score.computeValue()
+		documents = append(documents, *score)
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		if documents[i].RepoName != documents[j].RepoName {
+			return documents[i].RepoName < documents[j].RepoName
+		}
+		return documents[i].Path < documents[j].Path
+	})
+	overall.computeValue()
+	return *overall, documents
+}
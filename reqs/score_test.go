@@ -0,0 +1,70 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-111
+func TestReqGraph_Scores(t *testing.T) {
+	srd := config.Document{Path: "TEST-137-SRD.md"}
+	sdd := config.Document{Path: "TEST-138-SDD.md", Implementation: []config.Implementation{
+		{ArchImplementation: config.ArchImplementation{CodeFiles: []string{"a.go"}}}}}
+	impl := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go", Type: code.CodeTypeImplementation}}
+	test := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a_test.go", Type: code.CodeTypeTests}}
+
+	swh1 := &Req{ID: "REQ-TEST-SWH-1", Document: &srd, RepoName: "repo", Position: 1}
+	swh2 := &Req{ID: "REQ-TEST-SWH-2", Document: &srd, RepoName: "repo", Position: 2,
+		ReviewComments: []ReviewComment{{Author: "rev", Comment: "Unclear"}}}
+	swh3 := &Req{ID: "REQ-TEST-SWH-3", Title: "DELETED", Document: &srd, RepoName: "repo", Position: 3}
+	swl1 := &Req{ID: "REQ-TEST-SWL-1", Document: &sdd, RepoName: "repo", Position: 1, Tags: []*code.Code{impl, test}}
+	swl2 := &Req{ID: "REQ-TEST-SWL-2", Document: &sdd, RepoName: "repo", Position: 2, Tags: []*code.Code{impl}}
+	asm1 := &Req{ID: "ASM-TEST-SWL-1", Document: &sdd, RepoName: "repo", Position: 3, Variant: ReqVariantAssumption}
+	swh1.Children = []*Req{swl1, swl2}
+	swh2.Children = []*Req{swh3}
+
+	rg := &ReqGraph{
+		Reqs: map[string]*Req{swh1.ID: swh1, swh2.ID: swh2, swh3.ID: swh3, swl1.ID: swl1, swl2.ID: swl2, asm1.ID: asm1},
+		Issues: []diagnostics.Issue{
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 2, Severity: diagnostics.IssueSeverityMajor},
+			{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 3, Severity: diagnostics.IssueSeverityNote},
+		},
+	}
+
+	overall, documents := rg.Scores()
+	assert.Equal(t, 5, overall.Requirements)
+	assert.Equal(t, []ScoreCriterion{
+		{Name: ScoreImplemented, Weight: 1, Applicable: 4, Met: 3},
+		{Name: ScoreTested, Weight: 1, Applicable: 2, Met: 1},
+		{Name: ScoreReviewed, Weight: 1, Applicable: 5, Met: 4},
+		{Name: ScoreNoIssues, Weight: 1, Applicable: 5, Met: 4},
+	}, overall.Criteria)
+	assert.InDelta(t, 100*(0.75+0.5+0.8+0.8)/4, overall.Value, 1e-9)
+	assert.Equal(t, "71.2% (implemented 75.0% x1, tested 50.0% x1, reviewed 80.0% x1, no issues 80.0% x1)",
+		overall.String())
+
+	assert.Len(t, documents, 2)
+	assert.Equal(t, "TEST-137-SRD.md", documents[0].Path)
+	assert.Equal(t, 2, documents[0].Requirements)
+	// Tests are not expected in documents without implementation
+	assert.Equal(t, 0, documents[0].Criteria[1].Applicable)
+	assert.InDelta(t, 100*(0.5+0.5+1)/3, documents[0].Value, 1e-9)
+	assert.Equal(t, "TEST-138-SDD.md", documents[1].Path)
+	assert.Equal(t, 3, documents[1].Requirements)
+	assert.InDelta(t, 100*(1+0.5+1+2.0/3)/4, documents[1].Value, 1e-9)
+
+	// Criteria with no weight are not counted
+	rg.ReqtraqConfig = &config.Config{ScoreWeights: config.ScoreWeights{Implemented: 3, Reviewed: 1}}
+	overall, _ = rg.Scores()
+	assert.InDelta(t, 100*(3*0.75+0.8)/4, overall.Value, 1e-9)
+	assert.Equal(t, "76.2% (implemented 75.0% x3, reviewed 80.0% x1)", overall.String())
+
+	// Without requirements, nothing is missing
+	overall, documents = (&ReqGraph{Reqs: map[string]*Req{}}).Scores()
+	assert.Equal(t, 100.0, overall.Value)
+	assert.Empty(t, documents)
+}
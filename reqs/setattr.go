@@ -0,0 +1,136 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/expr"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// Query returns the requirements of the given repository which are not deleted and match the filter expression, as
+// the expressions of the computed attributes are evaluated, ordered by ID
+// @llr REQ-TRAQ-SWL-194
+func (rg *ReqGraph) Query(repoName repos.RepoName, filter *expr.Expression) ([]*Req, error) {
+	var matched []*Req
+	for _, r := range rg.Reqs {
+		if r.RepoName != repoName || r.Document == nil || r.Stub || r.IsDeleted() {
+			continue
+		}
+		value, err := filter.Eval(reqEnv{req: r, hasChildren: len(r.Children) > 0})
+		if err != nil {
+			return nil, fmt.Errorf("Unable to evaluate filter `%s` for requirement %s: %v", filter, r.ID, err)
+		}
+		if value.Truthy() {
+			matched = append(matched, r)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched, nil
+}
+
+// SchemaAttribute returns the definition of the attribute with the given name, in any case, in the schema of the
+// document of the requirement, or nil if the schema does not define it
+// @llr REQ-TRAQ-SWL-194
+func (r *Req) SchemaAttribute(name string) *config.Attribute {
+	for schemaName, attribute := range r.schemaAttributes() {
+		if strings.EqualFold(schemaName, name) {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// CheckAttributeValue returns an error if the value cannot be set for the attribute with the given name, in any case,
+// of the schema of the document of the requirement: the value must match the schema, and the required attributes
+// cannot be emptied. The attributes the schema does not define are not checked.
+// @llr REQ-TRAQ-SWL-194
+func (r *Req) CheckAttributeValue(name string, value string) error {
+	attribute := r.SchemaAttribute(name)
+	if attribute == nil {
+		return nil
+	}
+	if value == "" {
+		if attribute.Type == config.AttributeRequired {
+			return fmt.Errorf("Attribute `%s` of requirement %s is required and cannot be empty", name, r.ID)
+		}
+		return nil
+	}
+	if !attribute.Value.MatchString(value) {
+		return fmt.Errorf("Invalid value `%s` for attribute `%s` of requirement %s", value, name, r.ID)
+	}
+	return nil
+}
+
+// SetAttribute returns the content of a document with the value of the given attribute of the requirement whose
+// heading is at the given line, starting at 1, replaced by the given value, including its continuation lines. The
+// attribute is appended in title case to the attributes section of the requirement when missing, and the section is
+// created if needed. The other lines of the document are kept as they are.
+// @llr REQ-TRAQ-SWL-194
+func SetAttribute(content string, line int, id string, name string, value string) (string, error) {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return "", fmt.Errorf("Requirement %s not found at line %d", id, line)
+	}
+	parts := reATXHeading.FindStringSubmatch(lines[line-1])
+	if parts == nil || !strings.Contains(lines[line-1], id) {
+		return "", fmt.Errorf("Requirement %s is not defined in a heading at line %d, update its attributes in its table instead", id, line)
+	}
+	level := len(parts[1])
+	start := line - 1
+	end := reqEnd(lines, start, level)
+
+	attrs := -1
+	for i := start + 1; i < end; i++ {
+		if reAttributesHeading.MatchString(lines[i]) {
+			attrs = i
+			break
+		}
+	}
+	attribute := fmt.Sprintf("- %s: %s", strings.Title(strings.ToLower(name)), value)
+	if attrs < 0 {
+		attrsLevel := level + 1
+		if attrsLevel > 6 {
+			attrsLevel = 6
+		}
+		inserted := []string{"", strings.Repeat("#", attrsLevel) + " Attributes:", attribute}
+		lines = append(append(append([]string{}, lines[:end]...), inserted...), lines[end:]...)
+		return strings.Join(lines, "\n"), nil
+	}
+
+	// The attributes section ends at the next heading of the requirement, if any
+	sectionEnd := end
+	for i := attrs + 1; i < end; i++ {
+		if reATXHeading.MatchString(lines[i]) {
+			sectionEnd = i
+			break
+		}
+	}
+	last := attrs
+	for i := attrs + 1; i < sectionEnd; i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			last = i
+		}
+	}
+	for i := attrs + 1; i <= last; i++ {
+		m := reAttributeLine.FindStringSubmatch(lines[i])
+		if m == nil || !strings.EqualFold(strings.TrimSpace(m[1]), name) {
+			continue
+		}
+		// The value ends before the next attribute, keeping the blank lines in between
+		next := i + 1
+		for next <= last && !reAttributeLine.MatchString(lines[next]) {
+			next++
+		}
+		for next-1 > i && strings.TrimSpace(lines[next-1]) == "" {
+			next--
+		}
+		replaced := fmt.Sprintf("- %s: %s", m[1], value)
+		lines = append(append(append([]string{}, lines[:i]...), replaced), lines[next:]...)
+		return strings.Join(lines, "\n"), nil
+	}
+	lines = append(append(append([]string{}, lines[:last+1]...), attribute), lines[last+1:]...)
+	return strings.Join(lines, "\n"), nil
+}
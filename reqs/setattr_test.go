@@ -0,0 +1,122 @@
+package reqs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/expr"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-194
+func TestSetAttribute(t *testing.T) {
+	content := `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Status: Draft
+  pending review
+- Verification: Test
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+`
+
+	updated, err := SetAttribute(content, 3, "REQ-TEST-SWL-1", "STATUS", "Reviewed")
+	assert.NoError(t, err)
+	assert.Equal(t, `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Status: Reviewed
+- Verification: Test
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+`, updated)
+
+	updated, err = SetAttribute(content, 3, "REQ-TEST-SWL-1", "OWNER", "Team")
+	assert.NoError(t, err)
+	assert.Equal(t, `# Design
+
+### REQ-TEST-SWL-1 Log file
+
+The logs SHALL be written to a file.
+
+#### Attributes:
+- Parents: REQ-TEST-SWH-1
+- Status: Draft
+  pending review
+- Verification: Test
+- Owner: Team
+
+### REQ-TEST-SWL-2 Log rotation
+
+The logs SHALL be rotated.
+`, updated)
+
+	updated, err = SetAttribute(content, 13, "REQ-TEST-SWL-2", "Status", "Reviewed")
+	assert.NoError(t, err)
+	assert.Equal(t, content+`
+#### Attributes:
+- Status: Reviewed
+`, updated)
+
+	_, err = SetAttribute(content, 5, "REQ-TEST-SWL-1", "Status", "Reviewed")
+	assert.EqualError(t, err, "Requirement REQ-TEST-SWL-1 is not defined in a heading at line 5, update its attributes in its table instead")
+}
+
+// @llr REQ-TRAQ-SWL-194
+func TestReqGraph_Query(t *testing.T) {
+	doc := &config.Document{Path: "certdocs/TEST-138-SDD.md"}
+	other := &config.Document{Path: "certdocs/TEST-137-SRD.md"}
+	rg := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWL-2":  {ID: "REQ-TEST-SWL-2", RepoName: "test", Document: doc, Title: "Two", Attributes: map[string]string{"STATUS": "Draft"}},
+		"REQ-TEST-SWL-1":  {ID: "REQ-TEST-SWL-1", RepoName: "test", Document: doc, Title: "One", Attributes: map[string]string{"STATUS": "Draft"}},
+		"REQ-TEST-SWL-3":  {ID: "REQ-TEST-SWL-3", RepoName: "test", Document: doc, Title: "Three", Attributes: map[string]string{"STATUS": "Reviewed"}},
+		"REQ-TEST-SWL-4":  {ID: "REQ-TEST-SWL-4", RepoName: "test", Document: doc, Title: "DELETED", Attributes: map[string]string{"STATUS": "Draft"}},
+		"REQ-TEST-SWH-1":  {ID: "REQ-TEST-SWH-1", RepoName: "test", Document: other, Title: "High", Attributes: map[string]string{"STATUS": "Draft"}},
+		"REQ-OTHER-SWL-1": {ID: "REQ-OTHER-SWL-1", RepoName: "other", Document: doc, Title: "Other", Attributes: map[string]string{"STATUS": "Draft"}},
+	}}
+
+	matched, err := rg.Query("test", expr.MustParse(`doc == TEST-138-SDD && attr(STATUS) == Draft`))
+	assert.NoError(t, err)
+	var ids []string
+	for _, r := range matched {
+		ids = append(ids, r.ID)
+	}
+	assert.Equal(t, []string{"REQ-TEST-SWL-1", "REQ-TEST-SWL-2"}, ids)
+
+	_, err = rg.Query("test", expr.MustParse(`unknown(STATUS)`))
+	assert.Error(t, err)
+}
+
+// @llr REQ-TRAQ-SWL-194
+func TestReq_CheckAttributeValue(t *testing.T) {
+	doc := &config.Document{Path: "certdocs/TEST-138-SDD.md", Schema: config.Schema{Attributes: map[string]*config.Attribute{
+		"STATUS": {Type: config.AttributeRequired, Value: regexp.MustCompile("^(Draft|Reviewed)$")},
+		"OWNER":  {Type: config.AttributeOptional, Value: regexp.MustCompile("^Team .+$")},
+	}}}
+	r := &Req{ID: "REQ-TEST-SWL-1", Document: doc}
+
+	assert.NotNil(t, r.SchemaAttribute("status"))
+	assert.Nil(t, r.SchemaAttribute("Component"))
+	assert.NoError(t, r.CheckAttributeValue("Status", "Reviewed"))
+	assert.EqualError(t, r.CheckAttributeValue("Status", "Done"), "Invalid value `Done` for attribute `Status` of requirement REQ-TEST-SWL-1")
+	assert.EqualError(t, r.CheckAttributeValue("Status", ""), "Attribute `Status` of requirement REQ-TEST-SWL-1 is required and cannot be empty")
+	assert.EqualError(t, r.CheckAttributeValue("Owner", "Nobody"), "Invalid value `Nobody` for attribute `Owner` of requirement REQ-TEST-SWL-1")
+	// The optional attributes can be emptied, the unknown ones are not checked
+	assert.NoError(t, r.CheckAttributeValue("Owner", ""))
+	assert.NoError(t, r.CheckAttributeValue("Component", "Anything"))
+}
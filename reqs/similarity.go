@@ -0,0 +1,66 @@
+package reqs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+)
+
+// normalizedWords splits a text into lowercase words, ignoring punctuation, markup and whitespace
+// @llr REQ-TRAQ-SWL-106
+func normalizedWords(txt string) []string {
+	return strings.FieldsFunc(strings.ToLower(txt), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
+
+// textSimilarity returns how similar two texts are, from 0 for texts without common words to 1 for texts with
+// the same words. It is the ratio of the words of the longest common subsequence to the words of both texts.
+// @llr REQ-TRAQ-SWL-106
+func textSimilarity(a, b string) float64 {
+	wordsA, wordsB := normalizedWords(a), normalizedWords(b)
+	if len(wordsA)+len(wordsB) == 0 {
+		return 0
+	}
+
+	// Length of the longest common subsequence, computed one row at a time
+	previous := make([]int, len(wordsB)+1)
+	current := make([]int, len(wordsB)+1)
+	for i := range wordsA {
+		for j := range wordsB {
+			if wordsA[i] == wordsB[j] {
+				current[j+1] = previous[j] + 1
+			} else if previous[j+1] > current[j] {
+				current[j+1] = previous[j+1]
+			} else {
+				current[j+1] = current[j]
+			}
+		}
+		previous, current = current, previous
+	}
+	return 2 * float64(previous[len(wordsB)]) / float64(len(wordsA)+len(wordsB))
+}
+
+// checkDuplicatedParentText reports a lint issue if the body of the requirement is a copy of the body of the given
+// parent, i.e. their similarity reaches the threshold. A threshold of 0 disables the check.
+// @llr REQ-TRAQ-SWL-106
+func (r *Req) checkDuplicatedParentText(parent *Req, threshold float64) []diagnostics.Issue {
+	if threshold <= 0 || strings.TrimSpace(r.Body) == "" {
+		return nil
+	}
+	similarity := textSimilarity(r.Body, parent.Body)
+	if similarity < threshold {
+		return nil
+	}
+	return []diagnostics.Issue{{
+		Line:     r.Position,
+		Path:     r.Document.Path,
+		RepoName: r.RepoName,
+		Description: fmt.Sprintf("Requirement `%s` repeats the text of its parent `%s` (%.0f%% similar). "+
+			"Consider refining it to the level of its document.", r.ID, parent.ID, similarity*100),
+		Severity: diagnostics.IssueSeverityNote,
+		Type:     diagnostics.IssueTypeDuplicatedParentText,
+	}}
+}
@@ -0,0 +1,42 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-106
+func TestTextSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, textSimilarity("The system SHALL boot.", "the  system shall\n*boot*"))
+	assert.Equal(t, 0.0, textSimilarity("The system shall boot.", "Logs are rotated daily"))
+	assert.Equal(t, 0.0, textSimilarity("", ""))
+	// 3 common words out of 4 + 4
+	assert.Equal(t, 0.75, textSimilarity("The system shall boot", "The software shall boot"))
+}
+
+// @llr REQ-TRAQ-SWL-106
+func TestReq_CheckDuplicatedParentText(t *testing.T) {
+	doc := config.Document{Path: "path/to/SRD.md"}
+	parent := &Req{ID: "REQ-TEST-SYS-1", Body: "The system shall boot in less than one second."}
+	copied := &Req{ID: "REQ-TEST-SWH-1", Document: &doc, RepoName: "repo", Position: 4,
+		Body: "The system shall boot in less than one second!"}
+	refined := &Req{ID: "REQ-TEST-SWH-2", Document: &doc, RepoName: "repo", Position: 9,
+		Body: "The software shall load the kernel from flash in less than 500 milliseconds."}
+
+	assert.Equal(t, []diagnostics.Issue{{
+		Line:     4,
+		Path:     "path/to/SRD.md",
+		RepoName: "repo",
+		Description: "Requirement `REQ-TEST-SWH-1` repeats the text of its parent `REQ-TEST-SYS-1` (100% similar). " +
+			"Consider refining it to the level of its document.",
+		Severity: diagnostics.IssueSeverityNote,
+		Type:     diagnostics.IssueTypeDuplicatedParentText,
+	}}, copied.checkDuplicatedParentText(parent, config.DefaultDuplicateTextThreshold))
+	assert.Empty(t, refined.checkDuplicatedParentText(parent, config.DefaultDuplicateTextThreshold))
+	assert.NotEmpty(t, refined.checkDuplicatedParentText(parent, 0.3))
+	// The check is disabled with a threshold of 0
+	assert.Empty(t, copied.checkDuplicatedParentText(parent, 0))
+}
@@ -0,0 +1,146 @@
+package reqs
+
+import (
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// IssueLocation identifies the place an issue was reported at
+type IssueLocation struct {
+	RepoName repos.RepoName
+	Path     string
+	Line     int
+}
+
+// LocationOf returns the location the given issue was reported at
+// @llr REQ-TRAQ-SWL-98
+func LocationOf(issue diagnostics.Issue) IssueLocation {
+	return IssueLocation{issue.RepoName, issue.Path, issue.Line}
+}
+
+// IssueRequirements returns an index of the requirements affected by issues reported at a given location,
+// which is either the position of a requirement or the position of a function linked to requirements.
+// @llr REQ-TRAQ-SWL-98, REQ-TRAQ-SWL-100
+func (rg *ReqGraph) IssueRequirements() map[IssueLocation][]*Req {
+	index := make(map[IssueLocation][]*Req)
+	for _, r := range rg.Reqs {
+		if r.Document == nil {
+			continue
+		}
+		location := IssueLocation{r.RepoName, r.Document.Path, r.Position}
+		index[location] = append(index[location], r)
+	}
+	for _, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			location := IssueLocation{tag.CodeFile.RepoName, tag.CodeFile.Path, tag.Line}
+			for _, link := range tag.Links {
+				if r, ok := rg.Reqs[link.Id]; ok {
+					index[location] = append(index[location], r)
+				}
+			}
+		}
+	}
+	return index
+}
+
+// Subset returns a new graph with the requirements matching the filter and their trace context: all the
+// requirements below them and all the requirements above any of these. The code linked to the selected
+// requirements and the issues affecting them are kept as well.
+// @llr REQ-TRAQ-SWL-100
+func (rg *ReqGraph) Subset(filter *ReqFilter) *ReqGraph {
+	selected := make(map[string]bool)
+
+	// Collect the matching requirements and everything below them
+	var collectDown func(r *Req)
+	collectDown = func(r *Req) {
+		if selected[r.ID] {
+			return
+		}
+		selected[r.ID] = true
+		for _, child := range r.Children {
+			collectDown(child)
+		}
+	}
+	for _, r := range rg.Reqs {
+		if r.Matches(filter) {
+			collectDown(r)
+		}
+	}
+
+	// Add everything above them, so all the parents of the selected requirements are part of the subset
+	visitedUp := make(map[string]bool)
+	var collectUp func(r *Req)
+	collectUp = func(r *Req) {
+		if visitedUp[r.ID] {
+			return
+		}
+		visitedUp[r.ID] = true
+		selected[r.ID] = true
+		for _, parent := range r.Parents {
+			collectUp(parent)
+		}
+	}
+	below := make([]string, 0, len(selected))
+	for id := range selected {
+		below = append(below, id)
+	}
+	for _, id := range below {
+		collectUp(rg.Reqs[id])
+	}
+
+	subset := &ReqGraph{
+		Reqs:          make(map[string]*Req, len(selected)),
+		CodeTags:      make(map[repos.RepoName][]*code.Code),
+		FlowTags:      make(map[string]*Flow),
+		Issues:        make([]diagnostics.Issue, 0),
+		ReqtraqConfig: rg.ReqtraqConfig,
+	}
+	// Code is kept with its links to the requirements of the subset only, so the subset is self-contained
+	subsetTags := make(map[*code.Code]*code.Code)
+	for repoName, tags := range rg.CodeTags {
+		for _, tag := range tags {
+			var links []code.ReqLink
+			for _, link := range tag.Links {
+				if selected[link.Id] {
+					links = append(links, link)
+				}
+			}
+			if len(links) == 0 {
+				continue
+			}
+			subsetTag := *tag
+			subsetTag.Links = links
+			subsetTags[tag] = &subsetTag
+			subset.CodeTags[repoName] = append(subset.CodeTags[repoName], &subsetTag)
+		}
+	}
+
+	for id := range selected {
+		r := *rg.Reqs[id]
+		r.Parents = nil
+		r.Children = nil
+		r.Tags = make([]*code.Code, 0, len(r.Tags))
+		for _, tag := range rg.Reqs[id].Tags {
+			if subsetTag, ok := subsetTags[tag]; ok {
+				r.Tags = append(r.Tags, subsetTag)
+			} else {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+		subset.Reqs[id] = &r
+	}
+
+	index := rg.IssueRequirements()
+	for _, issue := range rg.Issues {
+		for _, r := range index[LocationOf(issue)] {
+			if selected[r.ID] {
+				subset.Issues = append(subset.Issues, issue)
+				break
+			}
+		}
+	}
+
+	subset.PrepareForUsage()
+	return subset
+}
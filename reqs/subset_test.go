@@ -0,0 +1,77 @@
+package reqs
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-100
+func TestReqGraph_Subset(t *testing.T) {
+	doc := config.Document{Path: "TEST-137-SRD.md"}
+	newReq := func(id string, position int, safety string, parents ...string) *Req {
+		return &Req{ID: id, Document: &doc, RepoName: "repo", Position: position, ParentIds: parents,
+			Attributes: map[string]string{"SAFETY IMPACT": safety}}
+	}
+
+	shared := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Line: 1,
+		Links: []code.ReqLink{{Id: "REQ-TEST-SWL-1"}, {Id: "REQ-TEST-SWL-3"}}}
+	other := &code.Code{CodeFile: code.CodeFile{RepoName: "repo", Path: "a.go"}, Line: 9,
+		Links: []code.ReqLink{{Id: "REQ-TEST-SWL-3"}}}
+
+	rg := &ReqGraph{
+		Reqs: map[string]*Req{
+			"REQ-TEST-SYS-1": newReq("REQ-TEST-SYS-1", 1, "None"),
+			"REQ-TEST-SYS-2": newReq("REQ-TEST-SYS-2", 2, "None"),
+			"REQ-TEST-SWH-1": newReq("REQ-TEST-SWH-1", 3, "High", "REQ-TEST-SYS-1"),
+			"REQ-TEST-SWH-2": newReq("REQ-TEST-SWH-2", 4, "None", "REQ-TEST-SYS-2"),
+			"REQ-TEST-SWH-3": newReq("REQ-TEST-SWH-3", 5, "None"),
+			"REQ-TEST-SWL-1": newReq("REQ-TEST-SWL-1", 6, "None", "REQ-TEST-SWH-1", "REQ-TEST-SWH-2"),
+			"REQ-TEST-SWL-2": newReq("REQ-TEST-SWL-2", 7, "None", "REQ-TEST-SWH-2"),
+			"REQ-TEST-SWL-3": newReq("REQ-TEST-SWL-3", 8, "None", "REQ-TEST-SWH-3"),
+		},
+		CodeTags: map[repos.RepoName][]*code.Code{"repo": {shared, other}},
+		Issues: []diagnostics.Issue{
+			{RepoName: "repo", Path: "TEST-137-SRD.md", Line: 3, Description: "SWH-1"},
+			{RepoName: "repo", Path: "TEST-137-SRD.md", Line: 5, Description: "SWH-3"},
+			{RepoName: "repo", Path: "a.go", Line: 1, Description: "shared code"},
+			{RepoName: "repo", Path: "a.go", Line: 9, Description: "other code"},
+		},
+	}
+	rg.PrepareForUsage()
+	rg.Reqs["REQ-TEST-SWL-1"].Tags = []*code.Code{shared}
+
+	filter := ReqFilter{AttributeRegexp: map[string]*regexp.Regexp{"SAFETY IMPACT": regexp.MustCompile("^High$")}}
+	subset := rg.Subset(&filter)
+
+	var ids []string
+	for id := range subset.Reqs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	// SWH-1 matches, SWL-1 is below it and SWH-2 and SYS-2 are above SWL-1
+	assert.Equal(t, []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2", "REQ-TEST-SWL-1", "REQ-TEST-SYS-1", "REQ-TEST-SYS-2"}, ids)
+
+	// Links are resolved within the subset, without modifying the original graph
+	assert.Len(t, subset.Reqs["REQ-TEST-SWH-2"].Children, 1)
+	assert.Len(t, rg.Reqs["REQ-TEST-SWH-2"].Children, 2)
+
+	// Code is kept with its links to the subset only
+	if assert.Len(t, subset.CodeTags["repo"], 1) {
+		assert.Equal(t, []code.ReqLink{{Id: "REQ-TEST-SWL-1"}}, subset.CodeTags["repo"][0].Links)
+		assert.Same(t, subset.CodeTags["repo"][0], subset.Reqs["REQ-TEST-SWL-1"].Tags[0])
+	}
+	assert.Len(t, shared.Links, 2)
+
+	var descriptions []string
+	for _, issue := range subset.Issues {
+		descriptions = append(descriptions, issue.Description)
+	}
+	assert.Equal(t, []string{"SWH-1", "shared code"}, descriptions)
+}
@@ -0,0 +1,83 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+)
+
+// lintSeverity returns the severity of the issues of the given lint check configured in the lint policy, or the
+// given default severity if it is not configured. Returns false if the check is disabled.
+// @llr REQ-TRAQ-SWL-152
+func (rg *ReqGraph) lintSeverity(check string, defaultSeverity diagnostics.IssueSeverity) (diagnostics.IssueSeverity, bool) {
+	if rg.ReqtraqConfig == nil {
+		return defaultSeverity, true
+	}
+	switch rg.ReqtraqConfig.LintPolicy[check] {
+	case config.LintSeverityError:
+		return diagnostics.IssueSeverityMajor, true
+	case config.LintSeverityWarning:
+		return diagnostics.IssueSeverityMinor, true
+	case config.LintSeverityNote:
+		return diagnostics.IssueSeverityNote, true
+	case config.LintSeverityOff:
+		return defaultSeverity, false
+	}
+	return defaultSeverity, true
+}
+
+// checkDuplicateTitles reports the requirements which are not deleted and have the same title as a requirement
+// defined before them in the same document, ignoring case, as the title was most likely copied and pasted. The
+// severity of the issues is configured by the lint policy and defaults to a warning.
+// @llr REQ-TRAQ-SWL-152
+func (rg *ReqGraph) checkDuplicateTitles() []diagnostics.Issue {
+	severity, enabled := rg.lintSeverity(config.LintCheckDuplicateTitle, diagnostics.IssueSeverityMinor)
+	if !enabled {
+		return nil
+	}
+
+	type titleKey struct {
+		RepoName repos.RepoName
+		Path     string
+		Title    string
+	}
+	var requirements []*Req
+	for _, r := range rg.Reqs {
+		if !r.IsDeleted() && r.Document != nil && strings.TrimSpace(r.Title) != "" {
+			requirements = append(requirements, r)
+		}
+	}
+	sort.Slice(requirements, func(i, j int) bool {
+		if requirements[i].RepoName != requirements[j].RepoName {
+			return requirements[i].RepoName < requirements[j].RepoName
+		}
+		if requirements[i].Document.Path != requirements[j].Document.Path {
+			return requirements[i].Document.Path < requirements[j].Document.Path
+		}
+		return requirements[i].Position < requirements[j].Position
+	})
+
+	var issues []diagnostics.Issue
+	first := make(map[titleKey]*Req)
+	for _, r := range requirements {
+		key := titleKey{r.RepoName, r.Document.Path, strings.ToLower(strings.TrimSpace(r.Title))}
+		original, ok := first[key]
+		if !ok {
+			first[key] = r
+			continue
+		}
+		issues = append(issues, diagnostics.Issue{
+			Line:        r.Position,
+			Path:        r.Document.Path,
+			RepoName:    r.RepoName,
+			Description: fmt.Sprintf("Requirement `%s` has the same title as requirement `%s` of the same document: `%s`.", r.ID, original.ID, strings.TrimSpace(r.Title)),
+			Severity:    severity,
+			Type:        diagnostics.IssueTypeDuplicateTitle,
+		})
+	}
+	return issues
+}
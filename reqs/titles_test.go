@@ -0,0 +1,39 @@
+package reqs
+
+import (
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-152
+func TestReqGraph_CheckDuplicateTitles(t *testing.T) {
+	srd := &config.Document{Path: "TEST-137-SRD.md"}
+	sdd := &config.Document{Path: "TEST-138-SDD.md"}
+	rg := &ReqGraph{Reqs: map[string]*Req{
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", Title: "Log rotation", Document: sdd, RepoName: "repo", Position: 3},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", Title: "Log Rotation ", Document: sdd, RepoName: "repo", Position: 9},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", Title: "DELETED", Document: sdd, RepoName: "repo", Position: 15},
+		"REQ-TEST-SWL-4": {ID: "REQ-TEST-SWL-4", Title: "DELETED", Document: sdd, RepoName: "repo", Position: 21},
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", Title: "Log rotation", Document: srd, RepoName: "repo", Position: 5},
+	}}
+
+	expected := []diagnostics.Issue{{
+		Line:        9,
+		Path:        "TEST-138-SDD.md",
+		RepoName:    "repo",
+		Description: "Requirement `REQ-TEST-SWL-2` has the same title as requirement `REQ-TEST-SWL-1` of the same document: `Log Rotation`.",
+		Severity:    diagnostics.IssueSeverityMinor,
+		Type:        diagnostics.IssueTypeDuplicateTitle,
+	}}
+	assert.Equal(t, expected, rg.checkDuplicateTitles())
+
+	// The lint policy changes the severity or disables the check
+	rg.ReqtraqConfig = &config.Config{LintPolicy: map[string]config.LintSeverity{config.LintCheckDuplicateTitle: config.LintSeverityError}}
+	expected[0].Severity = diagnostics.IssueSeverityMajor
+	assert.Equal(t, expected, rg.checkDuplicateTitles())
+	rg.ReqtraqConfig.LintPolicy[config.LintCheckDuplicateTitle] = config.LintSeverityOff
+	assert.Empty(t, rg.checkDuplicateTitles())
+}
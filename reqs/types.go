@@ -19,7 +19,11 @@ type Flow struct {
 	Callee      string
 	Direction   string
 	Description string
-	Deleted     bool
+	// The path of the diagram file of the flow, relative to the directory of its document
+	Diagram string `json:",omitempty"`
+	Deleted bool
+	// The values of the additional columns of the table, by upper case column name
+	Attributes map[string]string `json:",omitempty"`
 	// Reqs contains list of requirements linked to tag
 	Reqs []*Req
 
@@ -42,6 +46,8 @@ type ReqGraph struct {
 	Issues []diagnostics.Issue
 	// Holds configuration of reqtraq for all associated repositories
 	ReqtraqConfig *config.Config
+	// ModelElements contains the blocks of the model-based designs, see LoadModelElements
+	ModelElements []*ModelElement `json:",omitempty"`
 }
 
 // Represents the type of requirement (assumption or requirement)
@@ -70,10 +76,62 @@ type Req struct {
 	Body  string
 	// Attributes of the requirement by uppercase name.
 	Attributes map[string]string
-	Position   int
+	// The uppercase names of the attributes in the order they are written, for requirements defined in headings
+	AttributeKeys []string `json:",omitempty"`
+	// Whether other sections follow the attributes section of a requirement defined in a heading
+	SectionsAfterAttributes bool `json:",omitempty"`
+	// The schema version recorded in the document of the requirement with a reqtraq:schema-version marker
+	SchemaVersion int `json:",omitempty"`
+	// Attributes computed from the configuration expressions, by uppercase name.
+	ComputedAttributes map[string]string `json:",omitempty"`
+	Position           int
 	// Link back to the document where the requirement is defined and the name of the repository
 	Document *config.Document
 	RepoName repos.RepoName
+	// Where the requirement was overridden for the selected variant, nil if it was not
+	Override *ReqOverride `json:",omitempty"`
+	// Review comments annotated in the definition of the requirement
+	ReviewComments []ReviewComment `json:",omitempty"`
+	// Acceptance criteria listed in the body of the requirement
+	AcceptanceCriteria []AcceptanceCriterion `json:",omitempty"`
+	// Whether the requirement is marked as intentionally having no parents, see DerivedParents
+	Derived bool `json:",omitempty"`
+	// Whether the requirement only stands for a requirement of another partition of the graph, see
+	// PartitionByDocument. Stubs are replaced by the requirements they stand for when merging the partitions.
+	Stub bool `json:",omitempty"`
+	// Whether the text of the requirement is replaced by a placeholder, see Redacted
+	Redacted bool `json:",omitempty"`
+	// The changes of the tracked attributes over the git history of the document, oldest first, see
+	// LoadAttributeHistory
+	AttributeHistory []AttributeChange `json:",omitempty"`
+	// The review status of the last changes of the files implementing the requirement, see LoadCodeReviews
+	CodeReviews []CodeReview `json:",omitempty"`
+	// The commits whose message mentions the requirement, newest first, see LoadRelatedChanges
+	RelatedChanges []RelatedChange `json:",omitempty"`
+	// The build artifacts whose sources contain the implementation of the requirement, see LoadBuildArtifacts
+	Artifacts []BuildArtifact `json:",omitempty"`
+}
+
+// DerivedParents is the value of the Parents attribute, or cell, of a derived requirement, which intentionally has
+// no parents
+const DerivedParents = "-"
+
+// ReviewComment is a review comment annotation in a document, e.g. `<!-- REVIEW(author): comment -->`
+type ReviewComment struct {
+	Author  string
+	Comment string
+	// Whether the comment has been addressed, i.e. annotated as `<!-- RESOLVED(author): comment -->`
+	Resolved bool
+	// Line in the document where the comment is
+	Line int
+}
+
+// ReqOverride records the override document which replaced parts of a requirement for a variant
+type ReqOverride struct {
+	Variant  string
+	RepoName repos.RepoName
+	Path     string
+	Position int
 }
 
 // ReqFilter holds the different parameters used to filter the requirements set.
@@ -0,0 +1,130 @@
+package reqs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/pkg/errors"
+)
+
+// The variant of the product whose overrides are applied when building the graph, none if empty
+var Variant string
+
+// applyOverrides parses the override documents of the given variant and replaces the title, body and
+// attributes of the overridden requirements with the ones found in them. The parents are replaced only
+// if the override specifies them. Returns the issues found in the overrides.
+// @llr REQ-TRAQ-SWL-101
+func (rg *ReqGraph) applyOverrides(variant string) ([]diagnostics.Issue, error) {
+	if variant == "" {
+		return nil, nil
+	}
+
+	found := false
+	for _, v := range rg.ReqtraqConfig.Variants() {
+		found = found || v == variant
+	}
+	if !found {
+		return nil, fmt.Errorf("Unknown variant `%s`, the configured variants are %v", variant, rg.ReqtraqConfig.Variants())
+	}
+
+	// The repositories are sorted, so the issues are always reported in the same order
+	repoNames := make([]repos.RepoName, 0, len(rg.ReqtraqConfig.Repos))
+	for repoName := range rg.ReqtraqConfig.Repos {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Slice(repoNames, func(i, j int) bool { return repoNames[i] < repoNames[j] })
+
+	var issues []diagnostics.Issue
+	for _, repoName := range repoNames {
+		for _, override := range rg.ReqtraqConfig.Repos[repoName].Overrides {
+			if override.Variant != variant {
+				continue
+			}
+			newIssues, err := rg.applyOverride(repoName, override)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, newIssues...)
+		}
+	}
+	return issues, nil
+}
+
+// applyOverride parses a single override document and applies it to the requirements of its base document. Returns
+// the issues found while parsing the override and applying it.
+// @llr REQ-TRAQ-SWL-101, REQ-TRAQ-SWL-129
+func (rg *ReqGraph) applyOverride(repoName repos.RepoName, override config.Override) ([]diagnostics.Issue, error) {
+	fmt.Fprintf(MessageWriter, "Processing override: %s\n", override.Path)
+
+	// The override is parsed with the schema of the base document, so requirement IDs and parents
+	// are recognized in the same way. The override is a single markdown file, whatever the files of the base
+	// document are.
+	baseDoc := rg.ReqtraqConfig.FindDocumentBySpec(override.Base)
+	overrideDoc := *baseDoc
+	overrideDoc.Path = override.Path
+	overrideDoc.Fragments = nil
+	overrideDoc.Doxygen = ""
+	overrideDoc.Virtual = false
+	overrides, _, issues, _, err := parseDocument(repoName, &overrideDoc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing override `%s` in repo `%s`", override.Path, repoName)
+	}
+
+	for _, o := range overrides {
+		r, ok := rg.Reqs[o.ID]
+		if !ok || !r.Document.MatchesSpec(override.Base) {
+			issues = append(issues, diagnostics.Issue{
+				Line:        o.Position,
+				Path:        override.Path,
+				RepoName:    repoName,
+				Description: fmt.Sprintf("Requirement '%s' overridden for variant '%s' does not exist in document '%s'.", o.ID, override.Variant, baseDoc.Path),
+				Severity:    diagnostics.IssueSeverityMajor,
+				Type:        diagnostics.IssueTypeInvalidRequirementId,
+			})
+			continue
+		}
+		if r.Override != nil {
+			issues = append(issues, diagnostics.Issue{
+				Line:     o.Position,
+				Path:     override.Path,
+				RepoName: repoName,
+				Description: fmt.Sprintf("Requirement '%s' is overridden for variant '%s' more than once, it was already overridden in '%s'.",
+					o.ID, override.Variant, r.Override.Path),
+				Severity: diagnostics.IssueSeverityMajor,
+				Type:     diagnostics.IssueTypeInvalidRequirementId,
+			})
+			continue
+		}
+
+		if o.Title != "" {
+			r.Title = o.Title
+		}
+		if o.Body != "" {
+			r.Body = o.Body
+			r.AcceptanceCriteria = o.AcceptanceCriteria
+		}
+		if r.Attributes == nil {
+			r.Attributes = make(map[string]string)
+		}
+		for name, value := range o.Attributes {
+			r.Attributes[name] = value
+		}
+		if _, ok := o.Attributes["PARENTS"]; ok || o.Derived {
+			r.ParentIds = o.ParentIds
+			r.Derived = o.Derived
+			if o.Derived {
+				delete(r.Attributes, "PARENTS")
+			}
+		}
+		r.Override = &ReqOverride{
+			Variant:  override.Variant,
+			RepoName: repoName,
+			Path:     override.Path,
+			Position: o.Position,
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,72 @@
+package reqs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// The reason of the waiver stubs, which have to be completed before they waive their issue
+const WaiverStubReason = "TODO"
+
+// Waiver accepts an issue, identified by its fingerprint, so it is no longer reported by the validation
+type Waiver struct {
+	Fingerprint string `yaml:"fingerprint"`
+	// The location and the description of the issue when it was waived, for the readers of the waivers file
+	Issue string `yaml:"issue"`
+	// Why the issue is accepted
+	Reason string `yaml:"reason"`
+}
+
+// WaiverStub returns the stub of a waiver for the issue as an item of the waivers file, to be completed with the
+// reason the issue is accepted
+// @llr REQ-TRAQ-SWL-135
+func WaiverStub(issue diagnostics.Issue) (string, error) {
+	stub, err := yaml.Marshal([]Waiver{{
+		Fingerprint: IssueFingerprint(issue),
+		Issue:       fmt.Sprintf("%s:%s:%d %s", issue.RepoName, issue.Path, issue.Line, issue.Description),
+		Reason:      WaiverStubReason,
+	}})
+	return string(stub), err
+}
+
+// ReadWaivers reads the waivers file, a YAML list of waivers
+// @llr REQ-TRAQ-SWL-135
+func ReadWaivers(path string) ([]Waiver, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var waivers []Waiver
+	if err := yaml.Unmarshal(content, &waivers); err != nil {
+		return nil, errors.Wrapf(err, "invalid waivers file `%s`", path)
+	}
+	for i, waiver := range waivers {
+		if waiver.Fingerprint == "" {
+			return nil, fmt.Errorf("waiver %d in `%s` has no fingerprint", i+1, path)
+		}
+	}
+	return waivers, nil
+}
+
+// WaiveIssues returns the issues which are not waived and the count of waived issues. The stubs whose reason was not
+// completed do not waive their issue.
+// @llr REQ-TRAQ-SWL-135
+func WaiveIssues(issues []diagnostics.Issue, waivers []Waiver) ([]diagnostics.Issue, int) {
+	waived := make(map[string]bool, len(waivers))
+	for _, waiver := range waivers {
+		if waiver.Reason != "" && waiver.Reason != WaiverStubReason {
+			waived[waiver.Fingerprint] = true
+		}
+	}
+	remaining := make([]diagnostics.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if !waived[IssueFingerprint(issue)] {
+			remaining = append(remaining, issue)
+		}
+	}
+	return remaining, len(issues) - len(remaining)
+}
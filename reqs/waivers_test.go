@@ -0,0 +1,43 @@
+package reqs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-135
+func TestWaivers(t *testing.T) {
+	notTested := diagnostics.Issue{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 12, Description: "Requirement REQ-TEST-SWL-2 is not tested.",
+		Type: diagnostics.IssueTypeReqNotTested}
+	noParents := diagnostics.Issue{RepoName: "repo", Path: "log.go", Line: 4, Description: "Function rotate@repo: log.go:4 has no parents.",
+		Type: diagnostics.IssueTypeMissingRequirementInCode}
+	stub, err := WaiverStub(noParents)
+	assert.NoError(t, err)
+	assert.Equal(t, "- fingerprint: "+IssueFingerprint(noParents)+"\n"+
+		"  issue: 'repo:log.go:4 Function rotate@repo: log.go:4 has no parents.'\n"+
+		"  reason: TODO\n", stub)
+
+	// The stubs are read back, but only waive their issue once their reason is given
+	path := filepath.Join(t.TempDir(), "waivers.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(stub), 0644))
+	waivers, err := ReadWaivers(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Waiver{{Fingerprint: IssueFingerprint(noParents),
+		Issue: "repo:log.go:4 Function rotate@repo: log.go:4 has no parents.", Reason: "TODO"}}, waivers)
+	remaining, waived := WaiveIssues([]diagnostics.Issue{notTested, noParents}, waivers)
+	assert.Equal(t, []diagnostics.Issue{notTested, noParents}, remaining)
+	assert.Equal(t, 0, waived)
+
+	waivers[0].Reason = "Logging helper, traced by its callers."
+	remaining, waived = WaiveIssues([]diagnostics.Issue{notTested, noParents}, waivers)
+	assert.Equal(t, []diagnostics.Issue{notTested}, remaining)
+	assert.Equal(t, 1, waived)
+
+	assert.NoError(t, os.WriteFile(path, []byte("- issue: anonymous\n  reason: Accepted\n"), 0644))
+	_, err = ReadWaivers(path)
+	assert.EqualError(t, err, "waiver 1 in `"+path+"` has no fingerprint")
+}
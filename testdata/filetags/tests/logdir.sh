@@ -0,0 +1,5 @@
+#!/bin/sh
+# Checks the assumption on the log directory
+# @llr ASM-TEST-SWL-1
+
+test -w /var/log
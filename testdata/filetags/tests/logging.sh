@@ -0,0 +1,4 @@
+#!/bin/sh
+# @llr REQ-TEST-SWL-1
+
+grep -q "level: info" config/logging.yaml
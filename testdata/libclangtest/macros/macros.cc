@@ -0,0 +1,16 @@
+#define TEST(suite, name)        \
+    class suite##_##name##_Test { \
+       private:                   \
+        void TestBody();          \
+    };                            \
+    void suite##_##name##_Test::TestBody()
+
+/**
+ * \llr REQ-TEST-SWL-1
+ */
+TEST(Suite, DoesThings) {}
+
+/**
+ * \llr REQ-TEST-SWL-2
+ */
+void plainFunction() {}
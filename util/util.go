@@ -1,5 +1,7 @@
 package util
 
+import "fmt"
+
 type VersionType struct {
 	Major    uint
 	Minor    uint
@@ -11,3 +13,21 @@ var Version = VersionType{
 	Minor:    1,
 	Revision: 0,
 }
+
+// String returns the version as `MAJOR.MINOR.REVISION`
+// @llr REQ-TRAQ-SWL-173
+func (v VersionType) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Revision)
+}
+
+// Before returns whether the version is older than the given one
+// @llr REQ-TRAQ-SWL-173
+func (v VersionType) Before(other VersionType) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Revision < other.Revision
+}
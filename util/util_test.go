@@ -0,0 +1,35 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-173
+func TestVersionType_String(t *testing.T) {
+	assert.Equal(t, "0.0.0", VersionType{}.String())
+	assert.Equal(t, "1.2.3", VersionType{Major: 1, Minor: 2, Revision: 3}.String())
+	assert.Equal(t, "10.0.12", VersionType{Major: 10, Revision: 12}.String())
+}
+
+// @llr REQ-TRAQ-SWL-173
+func TestVersionType_Before(t *testing.T) {
+	for _, tc := range []struct {
+		version  VersionType
+		other    VersionType
+		expected bool
+	}{
+		{VersionType{0, 1, 0}, VersionType{0, 1, 0}, false},
+		{VersionType{0, 1, 0}, VersionType{1, 0, 0}, true},
+		{VersionType{1, 0, 0}, VersionType{0, 1, 0}, false},
+		{VersionType{0, 9, 9}, VersionType{0, 10, 0}, true},
+		{VersionType{0, 10, 0}, VersionType{0, 9, 9}, false},
+		{VersionType{1, 2, 3}, VersionType{1, 2, 4}, true},
+		{VersionType{1, 2, 4}, VersionType{1, 2, 3}, false},
+		{VersionType{1, 0, 9}, VersionType{2, 0, 0}, true},
+		{VersionType{2, 0, 0}, VersionType{1, 9, 9}, false},
+	} {
+		assert.Equal(t, tc.expected, tc.version.Before(tc.other), "%s before %s", tc.version, tc.other)
+	}
+}
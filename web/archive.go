@@ -0,0 +1,302 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/matrix"
+	"github.com/daedaleanai/reqtraq/report"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+// The path of the top down report in the archives, which the issues report links the requirements of the issues to
+const archiveTopDownPath = "report-down.html"
+
+var (
+	// Stylesheets and scripts loaded from the network, which are inlined in the pages of the archives
+	reRemoteStylesheet = regexp.MustCompile(`(?s)<link rel="stylesheet" href="(https?://[^"]*)"[^>]*>`)
+	reRemoteScript     = regexp.MustCompile(`(?s)<script[^>]*src="(https?://[^"]*)"[^>]*>\s*</script>`)
+	// Characters which are not kept in the names of the files of the archives
+	reArchiveNameSeparators = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+)
+
+// The client downloading the stylesheets and scripts inlined in the archives
+var assetClient = &http.Client{Timeout: 30 * time.Second}
+
+// fetchAsset returns the content of a stylesheet or script loaded from the network by the pages of the reports
+// @llr REQ-TRAQ-SWL-147
+func fetchAsset(url string) ([]byte, error) {
+	resp, err := assetClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// The stylesheets and scripts downloaded so far, by URL, as they are the same for all the pages of the archives
+var (
+	assetCache      = make(map[string][]byte)
+	assetCacheMutex sync.Mutex
+)
+
+// asset returns the content of a stylesheet or script loaded from the network, downloading it once
+// @llr REQ-TRAQ-SWL-147
+func asset(url string) ([]byte, error) {
+	assetCacheMutex.Lock()
+	defer assetCacheMutex.Unlock()
+	if content, ok := assetCache[url]; ok {
+		return content, nil
+	}
+	content, err := fetchAsset(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "download `%s` to inline it in the archive", url)
+	}
+	assetCache[url] = content
+	return content, nil
+}
+
+// inlineAssets replaces the stylesheets and scripts of an HTML page loaded from the network with their content, so
+// the page keeps its styling and its scripts when opened offline
+// @llr REQ-TRAQ-SWL-147
+func inlineAssets(page []byte) ([]byte, error) {
+	var err error
+	inline := func(re *regexp.Regexp, open, close string) {
+		page = re.ReplaceAllFunc(page, func(element []byte) []byte {
+			url := string(re.FindSubmatch(element)[1])
+			content, fetchErr := asset(url)
+			if fetchErr != nil {
+				if err == nil {
+					err = fetchErr
+				}
+				return element
+			}
+			// The content must not end the element it is inlined in
+			content = bytes.ReplaceAll(content, []byte("</"), []byte(`<\/`))
+			return append(append([]byte(open+"\n"), content...), []byte("\n"+close)...)
+		})
+	}
+	inline(reRemoteStylesheet, "<style>", "</style>")
+	inline(reRemoteScript, `<script type="text/javascript">`, "</script>")
+	return page, err
+}
+
+// archiveFile is a file of an archive of reports, as listed in its manifest
+type archiveFile struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	SHA256 string `json:"sha256"`
+}
+
+// archiveManifest describes the content of an archive of reports and how it was generated
+type archiveManifest struct {
+	Repository string            `json:"repository"`
+	Revision   string            `json:"revision"`
+	Generated  string            `json:"generated"`
+	Filters    map[string]string `json:"filters,omitempty"`
+	Files      []archiveFile     `json:"files"`
+}
+
+// archiveWriter adds files to an archive of reports and records them for the manifest
+type archiveWriter struct {
+	zip   *zip.Writer
+	files []archiveFile
+}
+
+// add adds a file to the archive. HTML files are made to work offline: the stylesheets and scripts loaded from the
+// network are inlined and the links to the code point to the copies of the code files in the archive.
+// @llr REQ-TRAQ-SWL-147
+func (a *archiveWriter) add(path, kind string, content []byte) error {
+	if strings.HasSuffix(path, ".html") {
+		var err error
+		if content, err = inlineAssets(content); err != nil {
+			return errors.Wrapf(err, "add `%s`", path)
+		}
+		content = bytes.ReplaceAll(content, []byte(`href="/code/`), []byte(`href="code/`))
+	}
+	w, err := a.zip.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	a.files = append(a.files, archiveFile{Path: path, Type: kind, SHA256: hex.EncodeToString(sum[:])})
+	return nil
+}
+
+// generate adds the file generated by the given function to the archive
+// @llr REQ-TRAQ-SWL-147
+func (a *archiveWriter) generate(path, kind string, gen func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := gen(&buf); err != nil {
+		return errors.Wrapf(err, "generate `%s`", path)
+	}
+	return a.add(path, kind, buf.Bytes())
+}
+
+// archiveName returns the part of the name of the matrix files of the archive identifying the given requirements
+// @llr REQ-TRAQ-SWL-147
+func archiveName(spec config.ReqSpec) string {
+	name := fmt.Sprintf("%s-%s", spec.Prefix, spec.Level)
+	if spec.AttrKey != "" && spec.AttrVal != nil {
+		name += fmt.Sprintf("-%s-%s", spec.AttrKey, spec.AttrVal)
+	}
+	return strings.Trim(reArchiveNameSeparators.ReplaceAllString(name, "_"), "_")
+}
+
+// archiveFilters returns the non empty requirement filters of the report form, by field name
+// @llr REQ-TRAQ-SWL-147
+func archiveFilters(r *http.Request) map[string]string {
+	filters := make(map[string]string)
+	for field := range r.Form {
+		if value := r.FormValue(field); value != "" && (strings.HasSuffix(field, "_filter") || strings.HasPrefix(field, "attribute_filter_")) {
+			filters[field] = value
+		}
+	}
+	return filters
+}
+
+// writeArchive writes a zip archive with the reports of the requirements matching the filter, the trace matrices
+// listed in the index page, the code files linked from them and a manifest describing the archive. With a filter, the
+// matrices and the code files are the ones of the subset of the graph selected by the filter.
+// @llr REQ-TRAQ-SWL-147, REQ-TRAQ-SWL-153, REQ-TRAQ-SWL-192
+func writeArchive(w io.Writer, filter *reqs.ReqFilter, filters map[string]string) error {
+	archive := archiveWriter{zip: zip.NewWriter(w)}
+	selected := rg
+	if !filter.IsEmpty() {
+		selected = rg.Subset(filter)
+	}
+
+	reports := []struct {
+		path, kind string
+		all        func(*reqs.ReqGraph, io.Writer) error
+		filtered   func(*reqs.ReqGraph, io.Writer, *reqs.ReqFilter) error
+	}{
+		{archiveTopDownPath, "report-down", report.ReportDown, report.ReportDownFiltered},
+		{"report-up.html", "report-up", report.ReportUp, report.ReportUpFiltered},
+		{"report-issues.html", "report-issues",
+			func(rg *reqs.ReqGraph, w io.Writer) error {
+				return report.ReportIssues(rg, w, archiveTopDownPath)
+			},
+			func(rg *reqs.ReqGraph, w io.Writer, filter *reqs.ReqFilter) error {
+				return report.ReportIssuesFiltered(rg, w, filter, archiveTopDownPath)
+			}},
+	}
+	for _, rep := range reports {
+		rep := rep
+		err := archive.generate(rep.path, rep.kind, func(w io.Writer) error {
+			if filter.IsEmpty() {
+				return rep.all(rg, w)
+			}
+			return rep.filtered(rg, w, filter)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, linkSpec := range reqLinks {
+		parent, child := withLinkRegexp(linkSpec.Parent), withLinkRegexp(linkSpec.Child)
+		path := fmt.Sprintf("matrix-%s-%s.html", archiveName(linkSpec.Parent), archiveName(linkSpec.Child))
+		if err := archive.generate(path, "matrix", func(w io.Writer) error {
+			return matrix.GenerateTraceTables(selected, w, matrix.FormatHTML, parent, child)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, reqSpec := range codeLinks {
+		reqSpec := withLinkRegexp(reqSpec)
+		path := fmt.Sprintf("matrix-%s-code.html", archiveName(reqSpec))
+		if err := archive.generate(path, "matrix", func(w io.Writer) error {
+			return matrix.GenerateCodeTraceTables(selected, w, matrix.FormatHTML, reqSpec, code.CodeTypeAny)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// The code files are copied so the links of the reports to the code keep working
+	codeFiles := make(map[code.CodeFile]bool)
+	for _, tags := range selected.CodeTags {
+		for _, tag := range tags {
+			codeFiles[tag.CodeFile] = true
+		}
+	}
+	sortedFiles := make([]code.CodeFile, 0, len(codeFiles))
+	for codeFile := range codeFiles {
+		sortedFiles = append(sortedFiles, codeFile)
+	}
+	sort.Slice(sortedFiles, func(i, j int) bool { return sortedFiles[i].String() < sortedFiles[j].String() })
+	for _, codeFile := range sortedFiles {
+		path, err := repos.PathInRepo(codeFile.RepoName, codeFile.Path)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := archive.add(fmt.Sprintf("code/%s/%s", codeFile.RepoName, codeFile.Path), "code", content); err != nil {
+			return err
+		}
+	}
+
+	repoName := repos.BaseRepoName()
+	revision, err := repos.Revision(repoName)
+	if err != nil && !errors.Is(err, repos.ErrNoGit) {
+		return err
+	}
+	manifest := archiveManifest{
+		Repository: string(repoName),
+		Revision:   revision,
+		Generated:  time.Now().UTC().Format(time.RFC3339),
+		Filters:    filters,
+		Files:      archive.files,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	entry, err := archive.zip.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return archive.zip.Close()
+}
+
+// getArchive responds with the archive of the reports of the requirements matching the filter of the report form.
+// The archive is generated before it is sent, so errors are shown instead of a truncated archive.
+// @llr REQ-TRAQ-SWL-147
+func getArchive(w http.ResponseWriter, r *http.Request, filter *reqs.ReqFilter) error {
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, filter, archiveFilters(r)); err != nil {
+		return errors.Wrap(err, "failed to generate the archive")
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", string(repos.BaseRepoName())+"-reports.zip"))
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/code"
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-49
+func TestMain(m *testing.M) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		log.Fatal("Could not get current directory")
+	}
+
+	repos.SetBaseRepoInfo(repos.RepoPath(filepath.Dir(workingDir)), repos.RepoName("reqtraq"))
+	os.Exit(m.Run())
+}
+
+// assetTransport serves the stylesheets and scripts inlined in the archives instead of the network
+type assetTransport map[string]string
+
+// @llr REQ-TRAQ-SWL-147
+func (t assetTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	content, ok := t[r.URL.Host]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: ioutil.NopCloser(strings.NewReader(content)), Request: r}, nil
+}
+
+// @llr REQ-TRAQ-SWL-147
+func TestWriteArchive(t *testing.T) {
+	repos.ClearAllRepositories()
+	repos.RegisterRepository(repos.BaseRepoName(), repos.BaseRepoPath())
+	repos.NoGit = true
+	assetClient.Transport = assetTransport{
+		"maxcdn.bootstrapcdn.com": ".requirement { color: black; }",
+		"cdnjs.cloudflare.com":    "var mathjax = '</script>';",
+	}
+	defer func() {
+		repos.NoGit = false
+		assetClient.Transport = nil
+	}()
+
+	swh := config.Document{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}}
+	swl := config.Document{Path: "TEST-138-SDD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWL"}}
+	reqtraqConfig = config.Config{Repos: map[repos.RepoName]config.RepoConfig{"reqtraq": {Documents: []config.Document{swh, swl}}}}
+	parent := &reqs.Req{ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "Archive", Body: "Archives SHALL be written.",
+		Document: &swh, RepoName: "reqtraq", Attributes: map[string]string{}}
+	archived := &reqs.Req{ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Archive writer", Body: "Archives SHALL be zipped.",
+		Document: &swl, RepoName: "reqtraq", ParentIds: []string{parent.ID}, Attributes: map[string]string{}}
+	compared := &reqs.Req{ID: "REQ-TEST-SWL-2", IDNumber: 2, Title: "Diff", Body: "Graphs SHALL be compared.",
+		Document: &swl, RepoName: "reqtraq", Attributes: map[string]string{}}
+	archived.Parents = []*reqs.Req{parent}
+	parent.Children = []*reqs.Req{archived}
+	writerTag := &code.Code{CodeFile: code.CodeFile{RepoName: "reqtraq", Path: "web/archive.go", Type: code.CodeTypeImplementation},
+		Tag: "writeArchive", Line: 10, Links: []code.ReqLink{{Id: archived.ID}}}
+	diffTag := &code.Code{CodeFile: code.CodeFile{RepoName: "reqtraq", Path: "web/diff.go", Type: code.CodeTypeImplementation},
+		Tag: "newDiffData", Line: 10, Links: []code.ReqLink{{Id: compared.ID}}}
+	archived.Tags = []*code.Code{writerTag}
+	compared.Tags = []*code.Code{diffTag}
+	rg = &reqs.ReqGraph{
+		Reqs:          map[string]*reqs.Req{parent.ID: parent, archived.ID: archived, compared.ID: compared},
+		CodeTags:      map[repos.RepoName][]*code.Code{"reqtraq": {writerTag, diffTag}},
+		ReqtraqConfig: &reqtraqConfig,
+	}
+	reqLinks = []config.LinkSpec{{Parent: swh.ReqSpec, Child: swl.ReqSpec}}
+	codeLinks = []config.ReqSpec{swl.ReqSpec}
+
+	var buf bytes.Buffer
+	filter := &reqs.ReqFilter{IDRegexp: regexp.MustCompile("REQ-TEST-SWL-1")}
+	assert.NoError(t, writeArchive(&buf, filter, map[string]string{"id_filter": "REQ-TEST-SWL-1"}))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	files := make(map[string]string)
+	var names []string
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(reader)
+		assert.NoError(t, err)
+		files[file.Name] = string(content)
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+
+	// Only the code of the requirements selected by the filter is copied
+	assert.Equal(t, []string{"code/reqtraq/web/archive.go", "manifest.json", "matrix-TEST-SWH-TEST-SWL.html",
+		"matrix-TEST-SWL-code.html", "report-down.html", "report-issues.html", "report-up.html"}, names)
+
+	// The pages load nothing from the network
+	for _, name := range names {
+		if strings.HasSuffix(name, ".html") {
+			assert.NotRegexp(t, `(href|src)="https?://`, files[name], name)
+			assert.Contains(t, files[name], "<style>\n.requirement { color: black; }\n</style>", name)
+			assert.Contains(t, files[name], `var mathjax = '<\/script>';`, name)
+		}
+	}
+	assert.Contains(t, files["report-down.html"], "REQ-TEST-SWL-1")
+	assert.NotContains(t, files["report-down.html"], "REQ-TEST-SWL-2")
+	assert.Contains(t, files["matrix-TEST-SWL-code.html"], "writeArchive")
+	assert.NotContains(t, files["matrix-TEST-SWL-code.html"], "newDiffData")
+
+	var manifest archiveManifest
+	if assert.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest)) {
+		assert.Equal(t, "reqtraq", manifest.Repository)
+		assert.Empty(t, manifest.Revision)
+		assert.Equal(t, map[string]string{"id_filter": "REQ-TEST-SWL-1"}, manifest.Filters)
+		assert.Len(t, manifest.Files, len(names)-1)
+		for _, file := range manifest.Files {
+			assert.Contains(t, files, file.Path)
+			assert.Len(t, file.SHA256, 64)
+		}
+	}
+
+	// The archive is not written when the assets cannot be inlined
+	assetCache = make(map[string][]byte)
+	assetClient.Transport = assetTransport{}
+	err = writeArchive(&bytes.Buffer{}, filter, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "404 Not Found")
+	}
+	assetCache = make(map[string][]byte)
+}
@@ -0,0 +1,254 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/pkg/errors"
+)
+
+// The colors of the requirements and links in the diff graph, by kind of change
+const (
+	diffColorAdded     = "#5cb85c"
+	diffColorModified  = "#f0ad4e"
+	diffColorDeleted   = "#d9534f"
+	diffColorUnchanged = "#d3d3d3"
+)
+
+// diffChange is a changed requirement, as listed in the diff page
+type diffChange struct {
+	ID    string
+	Title string
+	Kind  string
+}
+
+// diffNode is a requirement shown in the diff graph
+type diffNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Title string `json:"title"`
+	Color string `json:"color"`
+}
+
+// diffEdge is a link from a parent to a child requirement shown in the diff graph
+type diffEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Color  string `json:"color"`
+	Dashes bool   `json:"dashes"`
+	Arrows string `json:"arrows"`
+}
+
+type diffData struct {
+	Before       string
+	After        string
+	Changes      []diffChange
+	AddedLinks   []reqs.Link
+	RemovedLinks []reqs.Link
+	Nodes        []diffNode
+	Edges        []diffEdge
+}
+
+// newDiffData computes the differences between two versions of the requirements graph and the graph showing
+// them. The graph holds the changed requirements, the requirements linked to them and the links between them.
+// @llr REQ-TRAQ-SWL-112
+func newDiffData(before, after *reqs.ReqGraph, beforeName, afterName string) diffData {
+	diff := reqs.DiffGraphs(before, after)
+	data := diffData{Before: beforeName, After: afterName, AddedLinks: diff.AddedLinks, RemovedLinks: diff.RemovedLinks}
+
+	title := func(id string) string {
+		if r, ok := after.Reqs[id]; ok && !r.IsDeleted() {
+			return r.Title
+		}
+		if r, ok := before.Reqs[id]; ok {
+			return r.Title
+		}
+		return ""
+	}
+
+	colors := make(map[string]string)
+	for _, change := range diff.Changes {
+		data.Changes = append(data.Changes, diffChange{ID: change.ID, Title: title(change.ID), Kind: change.Kind.String()})
+		switch change.Kind {
+		case reqs.ChangeAdded:
+			colors[change.ID] = diffColorAdded
+		case reqs.ChangeModified:
+			colors[change.ID] = diffColorModified
+		case reqs.ChangeDeleted:
+			colors[change.ID] = diffColorDeleted
+		}
+	}
+
+	added := make(map[reqs.Link]bool)
+	for _, link := range diff.AddedLinks {
+		added[link] = true
+	}
+	linked := make(map[string]bool)
+	addEdge := func(link reqs.Link, color string, dashes bool) {
+		data.Edges = append(data.Edges, diffEdge{From: link.Parent, To: link.Child, Color: color, Dashes: dashes, Arrows: "to"})
+		linked[link.Parent] = true
+		linked[link.Child] = true
+	}
+	// The unchanged links of the changed requirements give the context of the changes
+	var unchanged []reqs.Link
+	for link := range after.Links() {
+		_, parentChanged := colors[link.Parent]
+		_, childChanged := colors[link.Child]
+		if !added[link] && (parentChanged || childChanged) {
+			unchanged = append(unchanged, link)
+		}
+	}
+	sort.Slice(unchanged, func(i, j int) bool {
+		if unchanged[i].Child != unchanged[j].Child {
+			return unchanged[i].Child < unchanged[j].Child
+		}
+		return unchanged[i].Parent < unchanged[j].Parent
+	})
+	for _, link := range unchanged {
+		addEdge(link, diffColorUnchanged, false)
+	}
+	for _, link := range diff.AddedLinks {
+		addEdge(link, diffColorAdded, false)
+	}
+	for _, link := range diff.RemovedLinks {
+		addEdge(link, diffColorDeleted, true)
+	}
+	for id := range colors {
+		linked[id] = true
+	}
+
+	ids := make([]string, 0, len(linked))
+	for id := range linked {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		color, ok := colors[id]
+		if !ok {
+			color = diffColorUnchanged
+		}
+		data.Nodes = append(data.Nodes, diffNode{ID: id, Label: id, Title: title(id), Color: color})
+	}
+	return data
+}
+
+// getDiff compares the requirements of the base repository at two git revisions
+// @llr REQ-TRAQ-SWL-112
+func getDiff(w http.ResponseWriter, r *http.Request) error {
+	from, to := strings.TrimSpace(r.FormValue("from")), strings.TrimSpace(r.FormValue("to"))
+	if from == "" || to == "" {
+		return errors.New("Both revisions to compare are required")
+	}
+	before, err := reqs.ReqsAtRevision(&reqtraqConfig, repos.BaseRepoName(), from)
+	if err != nil {
+		return err
+	}
+	after, err := reqs.ReqsAtRevision(&reqtraqConfig, repos.BaseRepoName(), to)
+	if err != nil {
+		return err
+	}
+	return diffTemplate.Execute(w, newDiffData(before, after, from, to))
+}
+
+// postDiff compares two requirements graphs exported as raw JSON, uploaded as the `before` and `after` files
+// @llr REQ-TRAQ-SWL-112
+func postDiff(w http.ResponseWriter, r *http.Request) error {
+	var graphs []*reqs.ReqGraph
+	var names []string
+	for _, field := range []string{"before", "after"} {
+		file, header, err := r.FormFile(field)
+		if err != nil {
+			return errors.Wrapf(err, "missing `%s` graph", field)
+		}
+		rg, err := reqs.ReadGraph(file)
+		file.Close()
+		if err != nil {
+			return errors.Wrapf(err, "invalid `%s` graph", field)
+		}
+		graphs = append(graphs, rg)
+		names = append(names, header.Filename)
+	}
+	return diffTemplate.Execute(w, newDiffData(graphs[0], graphs[1], names[0], names[1]))
+}
+
+var diffTemplate = template.Must(template.New("diff").Parse(fmt.Sprintf(
+	`<!DOCTYPE html>
+<html lang="en">
+<head>
+<title>{{ .Before }} .. {{ .After }}</title>
+<script type="text/javascript" src="https://unpkg.com/vis-network@9.1.2/standalone/umd/vis-network.min.js"></script>
+<style>
+#graph {
+	width: 100%%;
+	height: 600px;
+	border: 1px solid lightgray;
+}
+.legend span {
+	padding: 2px 8px;
+	margin-right: 1em;
+}
+td {
+	padding: 3px 10px;
+}
+:target {
+	font-weight: bold;
+}
+</style>
+</head>
+
+<body>
+<h1>Changes from {{ .Before }} to {{ .After }}</h1>
+
+{{ if .Nodes }}
+<p class="legend">
+<span style="background: %[1]s">Added</span>
+<span style="background: %[2]s">Modified</span>
+<span style="background: %[3]s">Deleted</span>
+<span style="background: %[4]s">Unchanged</span>
+</p>
+<div id="graph"></div>
+<script type="text/javascript">
+var network = new vis.Network(document.getElementById("graph"), {
+	nodes: new vis.DataSet({{ .Nodes }}),
+	edges: new vis.DataSet({{ .Edges }})
+}, {
+	layout: { hierarchical: { direction: "UD", sortMethod: "directed" } },
+	physics: false
+});
+network.on("click", function (params) {
+	if (params.nodes.length > 0) {
+		window.location.hash = params.nodes[0];
+	}
+});
+</script>
+{{ else }}
+<p>No changes to the requirements or the links between them.</p>
+{{ end }}
+
+{{ if .Changes }}
+<h2>Requirements</h2>
+<table>
+{{ range .Changes }}
+<tr id="{{ .ID }}"><td>{{ .Kind }}</td><td>{{ .ID }}</td><td>{{ .Title }}</td></tr>
+{{ end }}
+</table>
+{{ end }}
+
+{{ if or .AddedLinks .RemovedLinks }}
+<h2>Links</h2>
+<table>
+{{ range .AddedLinks }}
+<tr><td>Added</td><td>{{ .Child }} -> {{ .Parent }}</td></tr>
+{{ end }}
+{{ range .RemovedLinks }}
+<tr><td>Removed</td><td>{{ .Child }} -> {{ .Parent }}</td></tr>
+{{ end }}
+</table>
+{{ end }}
+</body>
+</html>`, diffColorAdded, diffColorModified, diffColorDeleted, diffColorUnchanged)))
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// The graphs compared by the diff tests: SWH-2 is renamed, SWL-2 deleted and SWL-3 added, and SWL-1 is linked to
+// SWH-2 in addition to SWH-1
+var (
+	diffBefore = &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One"},
+		"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two"},
+		"REQ-TEST-SWH-3": {ID: "REQ-TEST-SWH-3", IDNumber: 3, Title: "Three"},
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Low one", ParentIds: []string{"REQ-TEST-SWH-1"}},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", IDNumber: 2, Title: "Low two", ParentIds: []string{"REQ-TEST-SWH-2"}},
+		"REQ-TEST-SWL-4": {ID: "REQ-TEST-SWL-4", IDNumber: 4, Title: "Low four", ParentIds: []string{"REQ-TEST-SWH-3"}},
+	}}
+	diffAfter = &reqs.ReqGraph{Reqs: map[string]*reqs.Req{
+		"REQ-TEST-SWH-1": {ID: "REQ-TEST-SWH-1", IDNumber: 1, Title: "One"},
+		"REQ-TEST-SWH-2": {ID: "REQ-TEST-SWH-2", IDNumber: 2, Title: "Two renamed"},
+		"REQ-TEST-SWH-3": {ID: "REQ-TEST-SWH-3", IDNumber: 3, Title: "Three"},
+		"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Low one",
+			ParentIds: []string{"REQ-TEST-SWH-1", "REQ-TEST-SWH-2"}},
+		"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", IDNumber: 2, Title: "DELETED", ParentIds: []string{"REQ-TEST-SWH-2"}},
+		"REQ-TEST-SWL-3": {ID: "REQ-TEST-SWL-3", IDNumber: 3, Title: "Low three", ParentIds: []string{"REQ-TEST-SWH-1"}},
+		"REQ-TEST-SWL-4": {ID: "REQ-TEST-SWL-4", IDNumber: 4, Title: "Low four", ParentIds: []string{"REQ-TEST-SWH-3"}},
+	}}
+)
+
+// @llr REQ-TRAQ-SWL-112
+func TestNewDiffData(t *testing.T) {
+	data := newDiffData(diffBefore, diffAfter, "v1", "v2")
+	assert.Equal(t, "v1", data.Before)
+	assert.Equal(t, "v2", data.After)
+	// The deleted requirements keep their title from before
+	assert.Equal(t, []diffChange{
+		{ID: "REQ-TEST-SWH-2", Title: "Two renamed", Kind: "Modified"},
+		{ID: "REQ-TEST-SWL-2", Title: "Low two", Kind: "Deleted"},
+		{ID: "REQ-TEST-SWL-3", Title: "Low three", Kind: "Added"},
+	}, data.Changes)
+	assert.Equal(t, []reqs.Link{
+		{Parent: "REQ-TEST-SWH-2", Child: "REQ-TEST-SWL-1"},
+		{Parent: "REQ-TEST-SWH-1", Child: "REQ-TEST-SWL-3"},
+	}, data.AddedLinks)
+	assert.Equal(t, []reqs.Link{{Parent: "REQ-TEST-SWH-2", Child: "REQ-TEST-SWL-2"}}, data.RemovedLinks)
+
+	// The graph shows the changed requirements along with the requirements linked to them, but not the unrelated
+	// ones
+	assert.Equal(t, []diffNode{
+		{ID: "REQ-TEST-SWH-1", Label: "REQ-TEST-SWH-1", Title: "One", Color: diffColorUnchanged},
+		{ID: "REQ-TEST-SWH-2", Label: "REQ-TEST-SWH-2", Title: "Two renamed", Color: diffColorModified},
+		{ID: "REQ-TEST-SWL-1", Label: "REQ-TEST-SWL-1", Title: "Low one", Color: diffColorUnchanged},
+		{ID: "REQ-TEST-SWL-2", Label: "REQ-TEST-SWL-2", Title: "Low two", Color: diffColorDeleted},
+		{ID: "REQ-TEST-SWL-3", Label: "REQ-TEST-SWL-3", Title: "Low three", Color: diffColorAdded},
+	}, data.Nodes)
+	assert.Equal(t, []diffEdge{
+		{From: "REQ-TEST-SWH-2", To: "REQ-TEST-SWL-1", Color: diffColorAdded, Arrows: "to"},
+		{From: "REQ-TEST-SWH-1", To: "REQ-TEST-SWL-3", Color: diffColorAdded, Arrows: "to"},
+		{From: "REQ-TEST-SWH-2", To: "REQ-TEST-SWL-2", Color: diffColorDeleted, Dashes: true, Arrows: "to"},
+	}, data.Edges)
+
+	// Nothing is shown without changes
+	data = newDiffData(diffAfter, diffAfter, "v2", "v2")
+	assert.Empty(t, data.Changes)
+	assert.Empty(t, data.Nodes)
+	assert.Empty(t, data.Edges)
+}
+
+// @llr REQ-TRAQ-SWL-112
+func TestWeb_GetDiff(t *testing.T) {
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "TEST-137-SRD.md"), []byte("## REQ-TEST-SWH-1 One\nBody one\n"), 0644))
+	git("add", "-A")
+	git("commit", "-q", "-m", "First draft")
+	git("tag", "v1")
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "TEST-137-SRD.md"),
+		[]byte("## REQ-TEST-SWH-1 One\nBody one\n\n## REQ-TEST-SWH-2 Two <b>bold</b>\nBody two\n"), 0644))
+	git("commit", "-q", "-a", "-m", "Second draft")
+	git("tag", "v2")
+
+	// The base repository is replaced by the test repository
+	repos.RegisterRepository(repos.BaseRepoName(), repos.RepoPath(repoPath))
+	defer repos.RegisterRepository(repos.BaseRepoName(), repos.BaseRepoPath())
+	reqtraqConfig = config.Config{Repos: map[repos.RepoName]config.RepoConfig{repos.BaseRepoName(): {Documents: []config.Document{
+		{Path: "TEST-137-SRD.md", ReqSpec: config.ReqSpec{Prefix: "TEST", Level: "SWH"}},
+	}}}}
+	defer func() { reqtraqConfig = config.Config{} }()
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/diff?from=v1&to=v2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<h1>Changes from v1 to v2</h1>")
+	assert.Contains(t, body, `<tr id="REQ-TEST-SWH-2"><td>Added</td><td>REQ-TEST-SWH-2</td><td>Two &lt;b&gt;bold&lt;/b&gt;</td></tr>`)
+	assert.NotContains(t, body, `<tr id="REQ-TEST-SWH-1">`)
+
+	// Both revisions must be given and exist
+	for _, query := range []string{"from=v1", "from=v1&to=v3"} {
+		w = httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "/diff?"+query, nil))
+		assert.Contains(t, w.Body.String(), "OOPS!")
+	}
+}
+
+// @llr REQ-TRAQ-SWL-112
+func TestWeb_PostDiff(t *testing.T) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	for field, graph := range map[string]*reqs.ReqGraph{"before": diffBefore, "after": diffAfter} {
+		part, err := writer.CreateFormFile(field, field+".json")
+		assert.NoError(t, err)
+		assert.NoError(t, json.NewEncoder(part).Encode(graph))
+	}
+	assert.NoError(t, writer.Close())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/diff", &form)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	handler(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<h1>Changes from before.json to after.json</h1>")
+	assert.Contains(t, body, `<tr id="REQ-TEST-SWL-3"><td>Added</td><td>REQ-TEST-SWL-3</td><td>Low three</td></tr>`)
+	assert.Contains(t, body, "<tr><td>Added</td><td>REQ-TEST-SWL-1 -> REQ-TEST-SWH-2</td></tr>")
+	assert.Contains(t, body, "<tr><td>Removed</td><td>REQ-TEST-SWL-2 -> REQ-TEST-SWH-2</td></tr>")
+	assert.Contains(t, body, `new vis.DataSet([{"id":"REQ-TEST-SWH-1"`)
+
+	// Both graphs must be uploaded
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/diff", strings.NewReader("--none--\r\n"))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=none")
+	handler(w, r)
+	assert.Contains(t, w.Body.String(), "missing `before` graph")
+}
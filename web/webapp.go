@@ -31,8 +31,11 @@ var attributes map[string]*config.Attribute
 var codeLinks []config.ReqSpec
 var reqLinks []config.LinkSpec
 
+// The top down report the issues report links the requirements of the issues to
+const topDownURL = "/report?report-type=Top+Down"
+
 // Serve starts the web server listening on the supplied address:port
-// @llr REQ-TRAQ-SWL-37
+// @llr REQ-TRAQ-SWL-37, REQ-TRAQ-SWL-91
 func Serve(cfg *config.Config, rg_ *reqs.ReqGraph, addr string) error {
 	reqtraqConfig = *cfg
 	rg = rg_
@@ -52,6 +55,13 @@ func Serve(cfg *config.Config, rg_ *reqs.ReqGraph, addr string) error {
 			}
 		}
 	}
+	// Computed attributes can be filtered as any other attribute
+	for _, computedAttr := range reqtraqConfig.ComputedAttributes {
+		attributes[computedAttr.Name] = &config.Attribute{
+			Type:  config.AttributeOptional,
+			Value: regexp.MustCompile(".*"),
+		}
+	}
 	reqLinks = reqtraqConfig.GetLinkedSpecs()
 
 	if strings.HasPrefix(addr, ":") {
@@ -66,13 +76,18 @@ var errorTemplate = template.Must(template.New("error").Parse(
 <pre>{{.Error}}</pre>`))
 
 // handler responds to requests on the web server
-// @llr REQ-TRAQ-SWL-37
+// @llr REQ-TRAQ-SWL-37, REQ-TRAQ-SWL-112, REQ-TRAQ-SWL-168
 func handler(w http.ResponseWriter, r *http.Request) {
 	log.Print(r.Method, r.URL)
+	// The graph is not replaced by a rebuild while the request is handled
+	graphLock.RLock()
+	defer graphLock.RUnlock()
 	var err error
-	switch r.Method {
-	case "GET":
+	switch {
+	case r.Method == "GET":
 		err = get(w, r)
+	case r.Method == "POST" && r.URL.Path == "/diff":
+		err = postDiff(w, r)
 	default:
 		err = fmt.Errorf("Unknown HTTP method: %s", r.Method)
 	}
@@ -96,7 +111,7 @@ func ReqUrl(req config.ReqSpec) string {
 	return url.QueryEscape(fmt.Sprintf("%s-%s", req.Prefix, req.Level))
 }
 
-var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{"title": Title, "requrl": ReqUrl}).Parse(
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{"title": Title, "requrl": ReqUrl, "fields": strings.Fields}).Parse(
 	`<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -166,6 +181,29 @@ var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{"
 <input type="submit" name="report-type" value="Bottom Up"/>
 <input type="submit" name="report-type" value="Top Down"/>
 <input type="submit" name="report-type" value="Issues"/>
+<input type="submit" name="report-type" value="Download"/>
+</p>
+</form>
+
+<h2>Changes</h2>
+<form action="/diff" method="get">
+<p>Compare the requirements at
+<select name="from">
+{{ range $idx, $commit := .Commits }}<option value="{{ index (fields $commit) 0 }}"{{ if eq $idx 1 }} selected{{ end }}>{{ $commit }}</option>{{ end }}
+</select>
+with
+<select name="to">
+{{ range .Commits }}<option value="{{ index (fields .) 0 }}">{{ . }}</option>{{ end }}
+</select>
+<input type="submit" value="Compare"/>
+</p>
+</form>
+<form action="/diff" method="post" enctype="multipart/form-data">
+<p>Compare the exported graphs
+<input name="before" type="file" accept=".json">
+with
+<input name="after" type="file" accept=".json">
+<input type="submit" value="Compare"/>
 </p>
 </form>
 
@@ -218,6 +256,17 @@ type indexData struct {
 	CodeLinks  []config.ReqSpec
 }
 
+// withLinkRegexp returns the requirement specifier with the ID format of its document and the regexp matching the
+// references to its requirements, as expected by the trace matrices
+// @llr REQ-TRAQ-SWL-37
+func withLinkRegexp(reqSpec config.ReqSpec) config.ReqSpec {
+	if doc := reqtraqConfig.FindDocumentBySpec(reqSpec); doc != nil {
+		reqSpec.IDFormat = doc.ReqSpec.IDFormat
+	}
+	reqSpec.Re = reqSpec.Format().LinkRegexp(reqSpec.Prefix, reqSpec.Level)
+	return reqSpec
+}
+
 // Gets the requirement specifier from the http request string
 // @llr REQ-TRAQ-SWL-37
 func parseReqSpecFromRequest(specString string) (config.ReqSpec, error) {
@@ -229,11 +278,10 @@ func parseReqSpecFromRequest(specString string) (config.ReqSpec, error) {
 	if len(parts) < 2 {
 		return config.ReqSpec{}, fmt.Errorf("Invalid requirement specification `%s`", specString)
 	}
-	reqSpec := config.ReqSpec{
+	reqSpec := withLinkRegexp(config.ReqSpec{
 		Prefix: config.ReqPrefix(parts[0]),
 		Level:  config.ReqLevel(parts[1]),
-		Re:     regexp.MustCompile(fmt.Sprintf("REQ-%s-%s-(\\d+)", parts[0], parts[1])),
-	}
+	})
 	if len(parts) == 4 {
 		reqSpec.AttrKey = parts[2]
 		reqSpec.AttrVal = regexp.MustCompile(parts[3])
@@ -257,7 +305,7 @@ func getCodeType(request *http.Request) code.CodeType {
 }
 
 // get provides the page information for a given request
-// @llr REQ-TRAQ-SWL-37
+// @llr REQ-TRAQ-SWL-37, REQ-TRAQ-SWL-112, REQ-TRAQ-SWL-147, REQ-TRAQ-SWL-153, REQ-TRAQ-SWL-192
 func get(w http.ResponseWriter, r *http.Request) error {
 	repoName := repos.BaseRepoName()
 	reqPath := r.URL.Path
@@ -265,7 +313,7 @@ func get(w http.ResponseWriter, r *http.Request) error {
 	// root page
 	if reqPath == "/" {
 		commits, err := repos.AllCommits(repoName)
-		if err != nil {
+		if err != nil && !errors.Is(err, repos.ErrNoGit) {
 			return err
 		}
 		return indexTemplate.Execute(w, indexData{string(repoName), attributes, commits, reqLinks, codeLinks})
@@ -298,6 +346,8 @@ func get(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	switch {
+	case reqPath == "/diff":
+		return getDiff(w, r)
 	case reqPath == "/report":
 		filter, err := createFilterFromHttpRequest(r)
 		if err != nil {
@@ -316,9 +366,11 @@ func get(w http.ResponseWriter, r *http.Request) error {
 			return report.ReportDown(rg, w)
 		case "Issues":
 			if !filter.IsEmpty() {
-				return report.ReportIssuesFiltered(rg, w, filter)
+				return report.ReportIssuesFiltered(rg, w, filter, topDownURL)
 			}
-			return report.ReportIssues(rg, w)
+			return report.ReportIssues(rg, w, topDownURL)
+		case "Download":
+			return getArchive(w, r, filter)
 		}
 	case reqPath == "/matrix":
 		fromSpec, err := parseReqSpecFromRequest(r.FormValue("from"))
@@ -328,14 +380,14 @@ func get(w http.ResponseWriter, r *http.Request) error {
 
 		to := r.FormValue("to")
 		if to == "CODE" {
-			return matrix.GenerateCodeTraceTables(rg, w, fromSpec, getCodeType(r))
+			return matrix.GenerateCodeTraceTables(rg, w, matrix.FormatHTML, fromSpec, getCodeType(r))
 		}
 
 		toSpec, err := parseReqSpecFromRequest(to)
 		if err != nil {
 			return err
 		}
-		return matrix.GenerateTraceTables(rg, w, fromSpec, toSpec)
+		return matrix.GenerateTraceTables(rg, w, matrix.FormatHTML, fromSpec, toSpec)
 	}
 	return nil
 }
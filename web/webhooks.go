@@ -0,0 +1,174 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/repos"
+	"github.com/daedaleanai/reqtraq/reqs"
+)
+
+// Guards the served graph, which is replaced when it is rebuilt while the requests are handled
+var graphLock sync.RWMutex
+
+// The client posting to the webhooks, with a timeout so an unresponsive endpoint doesn't stall the rebuilds
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// rebuildSummary is the payload posted to the webhooks when the served graph is rebuilt
+type rebuildSummary struct {
+	RepoName repos.RepoName `json:"repoName"`
+	// The added, modified and deleted requirements, ordered by requirement ID number
+	Changes []changeSummary `json:"changes"`
+	// The issues of the rebuilt graph which the previous graph did not report, and the other way around
+	NewIssues      []issueSummary `json:"newIssues"`
+	ResolvedIssues []issueSummary `json:"resolvedIssues"`
+	// The number of issues of the rebuilt graph
+	IssueCount int `json:"issueCount"`
+}
+
+type changeSummary struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+type issueSummary struct {
+	Name        string         `json:"name"`
+	Code        string         `json:"code"`
+	Severity    string         `json:"severity"`
+	RepoName    repos.RepoName `json:"repoName"`
+	Path        string         `json:"path"`
+	Line        int            `json:"line"`
+	Description string         `json:"description"`
+}
+
+// issueKey identifies an issue across rebuilds. The line is ignored, as editing a document moves the issues
+// following the edit.
+type issueKey struct {
+	RepoName    repos.RepoName
+	Path        string
+	Type        diagnostics.IssueType
+	Description string
+}
+
+// newIssueSummaries returns the summaries of the issues which are not part of the other issues
+// @llr REQ-TRAQ-SWL-169
+func newIssueSummaries(issues, others []diagnostics.Issue) []issueSummary {
+	known := make(map[issueKey]bool)
+	for _, issue := range others {
+		known[issueKey{issue.RepoName, issue.Path, issue.Type, issue.Description}] = true
+	}
+	summaries := []issueSummary{}
+	for _, issue := range issues {
+		if known[issueKey{issue.RepoName, issue.Path, issue.Type, issue.Description}] {
+			continue
+		}
+		name, code := diagnostics.TypeInfo(issue.Type)
+		summaries = append(summaries, issueSummary{
+			Name:        name,
+			Code:        code,
+			Severity:    issue.Severity.String(),
+			RepoName:    issue.RepoName,
+			Path:        issue.Path,
+			Line:        issue.Line,
+			Description: issue.Description,
+		})
+	}
+	return summaries
+}
+
+// newRebuildSummary returns the changes of the requirements and of the issues between two versions of the graph
+// @llr REQ-TRAQ-SWL-169
+func newRebuildSummary(before, after *reqs.ReqGraph) rebuildSummary {
+	summary := rebuildSummary{
+		RepoName:       reqtraqConfig.TargetRepo,
+		Changes:        []changeSummary{},
+		NewIssues:      newIssueSummaries(after.Issues, before.Issues),
+		ResolvedIssues: newIssueSummaries(before.Issues, after.Issues),
+		IssueCount:     len(after.Issues),
+	}
+	for _, change := range reqs.DiffGraphs(before, after).Changes {
+		summary.Changes = append(summary.Changes, changeSummary{ID: change.ID, Kind: change.Kind.String()})
+	}
+	return summary
+}
+
+// isEmpty returns whether neither the requirements nor the issues changed
+// @llr REQ-TRAQ-SWL-169
+func (s rebuildSummary) isEmpty() bool {
+	return len(s.Changes) == 0 && len(s.NewIssues) == 0 && len(s.ResolvedIssues) == 0
+}
+
+// notifyWebhooks posts the summary as JSON to each webhook, returning the errors of the webhooks which could not be
+// notified
+// @llr REQ-TRAQ-SWL-169
+func notifyWebhooks(webhooks []config.Webhook, summary rebuildSummary) []error {
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, webhook := range webhooks {
+		request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		request.Header.Set("Content-Type", "application/json")
+		for name, value := range webhook.Headers {
+			request.Header.Set(name, value)
+		}
+		response, err := webhookClient.Do(request)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			errs = append(errs, fmt.Errorf("webhook %s responded with %s", webhook.URL, response.Status))
+		}
+	}
+	return errs
+}
+
+// rebuildGraph builds the graph again and serves it instead of the current one. When the requirements or the issues
+// changed, the configured webhooks are notified with a summary of the changes.
+// @llr REQ-TRAQ-SWL-168, REQ-TRAQ-SWL-169
+func rebuildGraph(build func() (*reqs.ReqGraph, error)) error {
+	rebuilt, err := build()
+	if err != nil {
+		return err
+	}
+	graphLock.Lock()
+	previous := rg
+	rg = rebuilt
+	graphLock.Unlock()
+
+	summary := newRebuildSummary(previous, rebuilt)
+	if summary.isEmpty() {
+		return nil
+	}
+	log.Printf("Rebuilt the graph: %d changed requirements, %d new issues, %d resolved issues",
+		len(summary.Changes), len(summary.NewIssues), len(summary.ResolvedIssues))
+	for _, err := range notifyWebhooks(reqtraqConfig.Webhooks, summary) {
+		log.Printf("Failed to notify webhook: %v", err)
+	}
+	return nil
+}
+
+// Watch rebuilds the served graph with the given function at the given interval, forever. Failed rebuilds are
+// logged and the previous graph keeps being served.
+// @llr REQ-TRAQ-SWL-168
+func Watch(interval time.Duration, build func() (*reqs.ReqGraph, error)) {
+	for range time.Tick(interval) {
+		if err := rebuildGraph(build); err != nil {
+			log.Printf("Failed to rebuild the graph: %v", err)
+		}
+	}
+}
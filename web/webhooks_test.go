@@ -0,0 +1,79 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daedaleanai/reqtraq/config"
+	"github.com/daedaleanai/reqtraq/diagnostics"
+	"github.com/daedaleanai/reqtraq/reqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// @llr REQ-TRAQ-SWL-168, REQ-TRAQ-SWL-169
+func TestWeb_RebuildGraph(t *testing.T) {
+	doc := &config.Document{Path: "TEST-138-SDD.md"}
+	kept := diagnostics.Issue{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 3, Description: "kept",
+		Severity: diagnostics.IssueSeverityMinor, Type: diagnostics.IssueTypeDuplicateTitle}
+	fixed := diagnostics.Issue{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 5, Description: "fixed",
+		Severity: diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeMissingAttribute}
+	added := diagnostics.Issue{RepoName: "repo", Path: "TEST-138-SDD.md", Line: 9, Description: "added",
+		Severity: diagnostics.IssueSeverityMajor, Type: diagnostics.IssueTypeNoShallInBody}
+	before := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{
+			"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Logging", Document: doc, RepoName: "repo"},
+		},
+		Issues: []diagnostics.Issue{kept, fixed},
+	}
+	// The lines of the issues which are kept move with the edits
+	moved := kept
+	moved.Line = 4
+	after := &reqs.ReqGraph{
+		Reqs: map[string]*reqs.Req{
+			"REQ-TEST-SWL-1": {ID: "REQ-TEST-SWL-1", IDNumber: 1, Title: "Logging", Document: doc, RepoName: "repo"},
+			"REQ-TEST-SWL-2": {ID: "REQ-TEST-SWL-2", IDNumber: 2, Title: "Rotation", Document: doc, RepoName: "repo"},
+		},
+		Issues: []diagnostics.Issue{moved, added},
+	}
+
+	var payloads []rebuildSummary
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var summary rebuildSummary
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&summary))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		payloads = append(payloads, summary)
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	rg = before
+	reqtraqConfig = config.Config{TargetRepo: "repo", Webhooks: []config.Webhook{{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}}}
+	assert.NoError(t, rebuildGraph(func() (*reqs.ReqGraph, error) { return after, nil }))
+	assert.Same(t, after, rg)
+	if assert.Len(t, payloads, 1) {
+		summary := payloads[0]
+		assert.Equal(t, []changeSummary{{ID: "REQ-TEST-SWL-2", Kind: "Added"}}, summary.Changes)
+		assert.Equal(t, []issueSummary{{Name: "No shall statement in body", Code: "REQ12", Severity: "error", RepoName: "repo",
+			Path: "TEST-138-SDD.md", Line: 9, Description: "added"}}, summary.NewIssues)
+		if assert.Len(t, summary.ResolvedIssues, 1) {
+			assert.Equal(t, "fixed", summary.ResolvedIssues[0].Description)
+		}
+		assert.Equal(t, 2, summary.IssueCount)
+		assert.Equal(t, "repo", string(summary.RepoName))
+	}
+	assert.Equal(t, []string{"Bearer token"}, authorizations)
+
+	// Rebuilding without changes doesn't notify the webhooks
+	assert.NoError(t, rebuildGraph(func() (*reqs.ReqGraph, error) { return after, nil }))
+	assert.Len(t, payloads, 1)
+
+	// Failing webhooks are reported
+	server.Config.Handler = http.NotFoundHandler()
+	errs := notifyWebhooks([]config.Webhook{{URL: server.URL}}, rebuildSummary{})
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "404 Not Found")
+	}
+}